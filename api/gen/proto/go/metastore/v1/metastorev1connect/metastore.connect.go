@@ -23,6 +23,8 @@ const _ = connect.IsAtLeastVersion1_13_0
 const (
 	// MetastoreServiceName is the fully-qualified name of the MetastoreService service.
 	MetastoreServiceName = "metastore.v1.MetastoreService"
+	// MetastoreAdminServiceName is the fully-qualified name of the MetastoreAdminService service.
+	MetastoreAdminServiceName = "metastore.v1.MetastoreAdminService"
 )
 
 // These constants are the fully-qualified names of the RPCs defined in this package. They're
@@ -42,14 +44,23 @@ const (
 	// MetastoreServiceReadIndexProcedure is the fully-qualified name of the MetastoreService's
 	// ReadIndex RPC.
 	MetastoreServiceReadIndexProcedure = "/metastore.v1.MetastoreService/ReadIndex"
+	// MetastoreAdminServiceAddLearnerProcedure is the fully-qualified name of the
+	// MetastoreAdminService's AddLearner RPC.
+	MetastoreAdminServiceAddLearnerProcedure = "/metastore.v1.MetastoreAdminService/AddLearner"
+	// MetastoreAdminServicePromoteLearnerProcedure is the fully-qualified name of the
+	// MetastoreAdminService's PromoteLearner RPC.
+	MetastoreAdminServicePromoteLearnerProcedure = "/metastore.v1.MetastoreAdminService/PromoteLearner"
 )
 
 // These variables are the protoreflect.Descriptor objects for the RPCs defined in this package.
 var (
-	metastoreServiceServiceDescriptor             = v1.File_metastore_v1_metastore_proto.Services().ByName("MetastoreService")
-	metastoreServiceAddBlockMethodDescriptor      = metastoreServiceServiceDescriptor.Methods().ByName("AddBlock")
-	metastoreServiceQueryMetadataMethodDescriptor = metastoreServiceServiceDescriptor.Methods().ByName("QueryMetadata")
-	metastoreServiceReadIndexMethodDescriptor     = metastoreServiceServiceDescriptor.Methods().ByName("ReadIndex")
+	metastoreServiceServiceDescriptor                   = v1.File_metastore_v1_metastore_proto.Services().ByName("MetastoreService")
+	metastoreServiceAddBlockMethodDescriptor            = metastoreServiceServiceDescriptor.Methods().ByName("AddBlock")
+	metastoreServiceQueryMetadataMethodDescriptor       = metastoreServiceServiceDescriptor.Methods().ByName("QueryMetadata")
+	metastoreServiceReadIndexMethodDescriptor           = metastoreServiceServiceDescriptor.Methods().ByName("ReadIndex")
+	metastoreAdminServiceServiceDescriptor              = v1.File_metastore_v1_metastore_proto.Services().ByName("MetastoreAdminService")
+	metastoreAdminServiceAddLearnerMethodDescriptor     = metastoreAdminServiceServiceDescriptor.Methods().ByName("AddLearner")
+	metastoreAdminServicePromoteLearnerMethodDescriptor = metastoreAdminServiceServiceDescriptor.Methods().ByName("PromoteLearner")
 )
 
 // MetastoreServiceClient is a client for the metastore.v1.MetastoreService service.
@@ -171,3 +182,98 @@ func (UnimplementedMetastoreServiceHandler) QueryMetadata(context.Context, *conn
 func (UnimplementedMetastoreServiceHandler) ReadIndex(context.Context, *connect.Request[v1.ReadIndexRequest]) (*connect.Response[v1.ReadIndexResponse], error) {
 	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("metastore.v1.MetastoreService.ReadIndex is not implemented"))
 }
+
+// MetastoreAdminServiceClient is a client for the metastore.v1.MetastoreAdminService service.
+type MetastoreAdminServiceClient interface {
+	AddLearner(context.Context, *connect.Request[v1.AddLearnerRequest]) (*connect.Response[v1.AddLearnerResponse], error)
+	PromoteLearner(context.Context, *connect.Request[v1.PromoteLearnerRequest]) (*connect.Response[v1.PromoteLearnerResponse], error)
+}
+
+// NewMetastoreAdminServiceClient constructs a client for the metastore.v1.MetastoreAdminService
+// service. By default, it uses the Connect protocol with the binary Protobuf Codec, asks for
+// gzipped responses, and sends uncompressed requests. To use the gRPC or gRPC-Web protocols, supply
+// the connect.WithGRPC() or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewMetastoreAdminServiceClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) MetastoreAdminServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	return &metastoreAdminServiceClient{
+		addLearner: connect.NewClient[v1.AddLearnerRequest, v1.AddLearnerResponse](
+			httpClient,
+			baseURL+MetastoreAdminServiceAddLearnerProcedure,
+			connect.WithSchema(metastoreAdminServiceAddLearnerMethodDescriptor),
+			connect.WithClientOptions(opts...),
+		),
+		promoteLearner: connect.NewClient[v1.PromoteLearnerRequest, v1.PromoteLearnerResponse](
+			httpClient,
+			baseURL+MetastoreAdminServicePromoteLearnerProcedure,
+			connect.WithSchema(metastoreAdminServicePromoteLearnerMethodDescriptor),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// metastoreAdminServiceClient implements MetastoreAdminServiceClient.
+type metastoreAdminServiceClient struct {
+	addLearner     *connect.Client[v1.AddLearnerRequest, v1.AddLearnerResponse]
+	promoteLearner *connect.Client[v1.PromoteLearnerRequest, v1.PromoteLearnerResponse]
+}
+
+// AddLearner calls metastore.v1.MetastoreAdminService.AddLearner.
+func (c *metastoreAdminServiceClient) AddLearner(ctx context.Context, req *connect.Request[v1.AddLearnerRequest]) (*connect.Response[v1.AddLearnerResponse], error) {
+	return c.addLearner.CallUnary(ctx, req)
+}
+
+// PromoteLearner calls metastore.v1.MetastoreAdminService.PromoteLearner.
+func (c *metastoreAdminServiceClient) PromoteLearner(ctx context.Context, req *connect.Request[v1.PromoteLearnerRequest]) (*connect.Response[v1.PromoteLearnerResponse], error) {
+	return c.promoteLearner.CallUnary(ctx, req)
+}
+
+// MetastoreAdminServiceHandler is an implementation of the metastore.v1.MetastoreAdminService
+// service.
+type MetastoreAdminServiceHandler interface {
+	AddLearner(context.Context, *connect.Request[v1.AddLearnerRequest]) (*connect.Response[v1.AddLearnerResponse], error)
+	PromoteLearner(context.Context, *connect.Request[v1.PromoteLearnerRequest]) (*connect.Response[v1.PromoteLearnerResponse], error)
+}
+
+// NewMetastoreAdminServiceHandler builds an HTTP handler from the service implementation. It
+// returns the path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewMetastoreAdminServiceHandler(svc MetastoreAdminServiceHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	metastoreAdminServiceAddLearnerHandler := connect.NewUnaryHandler(
+		MetastoreAdminServiceAddLearnerProcedure,
+		svc.AddLearner,
+		connect.WithSchema(metastoreAdminServiceAddLearnerMethodDescriptor),
+		connect.WithHandlerOptions(opts...),
+	)
+	metastoreAdminServicePromoteLearnerHandler := connect.NewUnaryHandler(
+		MetastoreAdminServicePromoteLearnerProcedure,
+		svc.PromoteLearner,
+		connect.WithSchema(metastoreAdminServicePromoteLearnerMethodDescriptor),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/metastore.v1.MetastoreAdminService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case MetastoreAdminServiceAddLearnerProcedure:
+			metastoreAdminServiceAddLearnerHandler.ServeHTTP(w, r)
+		case MetastoreAdminServicePromoteLearnerProcedure:
+			metastoreAdminServicePromoteLearnerHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedMetastoreAdminServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedMetastoreAdminServiceHandler struct{}
+
+func (UnimplementedMetastoreAdminServiceHandler) AddLearner(context.Context, *connect.Request[v1.AddLearnerRequest]) (*connect.Response[v1.AddLearnerResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("metastore.v1.MetastoreAdminService.AddLearner is not implemented"))
+}
+
+func (UnimplementedMetastoreAdminServiceHandler) PromoteLearner(context.Context, *connect.Request[v1.PromoteLearnerRequest]) (*connect.Response[v1.PromoteLearnerResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("metastore.v1.MetastoreAdminService.PromoteLearner is not implemented"))
+}
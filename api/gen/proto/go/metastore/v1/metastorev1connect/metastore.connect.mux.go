@@ -35,3 +35,18 @@ func RegisterMetastoreServiceHandler(mux *mux.Router, svc MetastoreServiceHandle
 		opts...,
 	))
 }
+
+// RegisterMetastoreAdminServiceHandler register an HTTP handler to a mux.Router from the service
+// implementation.
+func RegisterMetastoreAdminServiceHandler(mux *mux.Router, svc MetastoreAdminServiceHandler, opts ...connect.HandlerOption) {
+	mux.Handle("/metastore.v1.MetastoreAdminService/AddLearner", connect.NewUnaryHandler(
+		"/metastore.v1.MetastoreAdminService/AddLearner",
+		svc.AddLearner,
+		opts...,
+	))
+	mux.Handle("/metastore.v1.MetastoreAdminService/PromoteLearner", connect.NewUnaryHandler(
+		"/metastore.v1.MetastoreAdminService/PromoteLearner",
+		svc.PromoteLearner,
+		opts...,
+	))
+}
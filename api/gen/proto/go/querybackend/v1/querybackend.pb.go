@@ -9,6 +9,7 @@ package querybackendv1
 import (
 	_ "github.com/grafana/pyroscope/api/gen/proto/go/google/v1"
 	v1 "github.com/grafana/pyroscope/api/gen/proto/go/metastore/v1"
+	v12 "github.com/grafana/pyroscope/api/gen/proto/go/querier/v1"
 	v11 "github.com/grafana/pyroscope/api/gen/proto/go/types/v1"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
@@ -26,31 +27,73 @@ const (
 type QueryType int32
 
 const (
-	QueryType_QUERY_UNSPECIFIED   QueryType = 0
-	QueryType_QUERY_LABEL_NAMES   QueryType = 1
-	QueryType_QUERY_LABEL_VALUES  QueryType = 2
-	QueryType_QUERY_SERIES_LABELS QueryType = 3
-	QueryType_QUERY_TIME_SERIES   QueryType = 4
-	QueryType_QUERY_TREE          QueryType = 5
+	QueryType_QUERY_UNSPECIFIED        QueryType = 0
+	QueryType_QUERY_LABEL_NAMES        QueryType = 1
+	QueryType_QUERY_LABEL_VALUES       QueryType = 2
+	QueryType_QUERY_SERIES_LABELS      QueryType = 3
+	QueryType_QUERY_TIME_SERIES        QueryType = 4
+	QueryType_QUERY_TREE               QueryType = 5
+	QueryType_QUERY_TIME_RANGE         QueryType = 6
+	QueryType_QUERY_CALLGRAPH          QueryType = 7
+	QueryType_QUERY_SELFTEST           QueryType = 8
+	QueryType_QUERY_MANIFEST           QueryType = 9
+	QueryType_QUERY_SYMBOL_TABLE       QueryType = 10
+	QueryType_QUERY_FUNCTION_CHANGES   QueryType = 11
+	QueryType_QUERY_FLAMEGRAPH_DIFF    QueryType = 12
+	QueryType_QUERY_STACKTRACES        QueryType = 13
+	QueryType_QUERY_STACK_DEPTH        QueryType = 14
+	QueryType_QUERY_PROFILE_DEVIATION  QueryType = 15
+	QueryType_QUERY_PROFILE_SIMILARITY QueryType = 16
+	QueryType_QUERY_TOP_CALLERS        QueryType = 17
+	QueryType_QUERY_TOP_PROFILES       QueryType = 18
+	QueryType_QUERY_TREE_DIFF          QueryType = 19
 )
 
 // Enum value maps for QueryType.
 var (
 	QueryType_name = map[int32]string{
-		0: "QUERY_UNSPECIFIED",
-		1: "QUERY_LABEL_NAMES",
-		2: "QUERY_LABEL_VALUES",
-		3: "QUERY_SERIES_LABELS",
-		4: "QUERY_TIME_SERIES",
-		5: "QUERY_TREE",
+		0:  "QUERY_UNSPECIFIED",
+		1:  "QUERY_LABEL_NAMES",
+		2:  "QUERY_LABEL_VALUES",
+		3:  "QUERY_SERIES_LABELS",
+		4:  "QUERY_TIME_SERIES",
+		5:  "QUERY_TREE",
+		6:  "QUERY_TIME_RANGE",
+		7:  "QUERY_CALLGRAPH",
+		8:  "QUERY_SELFTEST",
+		9:  "QUERY_MANIFEST",
+		10: "QUERY_SYMBOL_TABLE",
+		11: "QUERY_FUNCTION_CHANGES",
+		12: "QUERY_FLAMEGRAPH_DIFF",
+		13: "QUERY_STACKTRACES",
+		14: "QUERY_STACK_DEPTH",
+		15: "QUERY_PROFILE_DEVIATION",
+		16: "QUERY_PROFILE_SIMILARITY",
+		17: "QUERY_TOP_CALLERS",
+		18: "QUERY_TOP_PROFILES",
+		19: "QUERY_TREE_DIFF",
 	}
 	QueryType_value = map[string]int32{
-		"QUERY_UNSPECIFIED":   0,
-		"QUERY_LABEL_NAMES":   1,
-		"QUERY_LABEL_VALUES":  2,
-		"QUERY_SERIES_LABELS": 3,
-		"QUERY_TIME_SERIES":   4,
-		"QUERY_TREE":          5,
+		"QUERY_UNSPECIFIED":        0,
+		"QUERY_LABEL_NAMES":        1,
+		"QUERY_LABEL_VALUES":       2,
+		"QUERY_SERIES_LABELS":      3,
+		"QUERY_TIME_SERIES":        4,
+		"QUERY_TREE":               5,
+		"QUERY_TIME_RANGE":         6,
+		"QUERY_CALLGRAPH":          7,
+		"QUERY_SELFTEST":           8,
+		"QUERY_MANIFEST":           9,
+		"QUERY_SYMBOL_TABLE":       10,
+		"QUERY_FUNCTION_CHANGES":   11,
+		"QUERY_FLAMEGRAPH_DIFF":    12,
+		"QUERY_STACKTRACES":        13,
+		"QUERY_STACK_DEPTH":        14,
+		"QUERY_PROFILE_DEVIATION":  15,
+		"QUERY_PROFILE_SIMILARITY": 16,
+		"QUERY_TOP_CALLERS":        17,
+		"QUERY_TOP_PROFILES":       18,
+		"QUERY_TREE_DIFF":          19,
 	}
 )
 
@@ -81,34 +124,129 @@ func (QueryType) EnumDescriptor() ([]byte, []int) {
 	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{0}
 }
 
+type SkipReason int32
+
+const (
+	SkipReason_SKIP_REASON_UNSPECIFIED SkipReason = 0
+	// The block was not queried in time: the fan-out
+	// deadline was exceeded.
+	SkipReason_SKIP_REASON_DEADLINE_EXCEEDED SkipReason = 1
+	// The block metadata does not include a section
+	// required to query the block.
+	SkipReason_SKIP_REASON_SECTION_MISSING SkipReason = 2
+)
+
+// Enum value maps for SkipReason.
+var (
+	SkipReason_name = map[int32]string{
+		0: "SKIP_REASON_UNSPECIFIED",
+		1: "SKIP_REASON_DEADLINE_EXCEEDED",
+		2: "SKIP_REASON_SECTION_MISSING",
+	}
+	SkipReason_value = map[string]int32{
+		"SKIP_REASON_UNSPECIFIED":       0,
+		"SKIP_REASON_DEADLINE_EXCEEDED": 1,
+		"SKIP_REASON_SECTION_MISSING":   2,
+	}
+)
+
+func (x SkipReason) Enum() *SkipReason {
+	p := new(SkipReason)
+	*p = x
+	return p
+}
+
+func (x SkipReason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SkipReason) Descriptor() protoreflect.EnumDescriptor {
+	return file_querybackend_v1_querybackend_proto_enumTypes[1].Descriptor()
+}
+
+func (SkipReason) Type() protoreflect.EnumType {
+	return &file_querybackend_v1_querybackend_proto_enumTypes[1]
+}
+
+func (x SkipReason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SkipReason.Descriptor instead.
+func (SkipReason) EnumDescriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{1}
+}
+
 type ReportType int32
 
 const (
-	ReportType_REPORT_UNSPECIFIED   ReportType = 0
-	ReportType_REPORT_LABEL_NAMES   ReportType = 1
-	ReportType_REPORT_LABEL_VALUES  ReportType = 2
-	ReportType_REPORT_SERIES_LABELS ReportType = 3
-	ReportType_REPORT_TIME_SERIES   ReportType = 4
-	ReportType_REPORT_TREE          ReportType = 5
+	ReportType_REPORT_UNSPECIFIED        ReportType = 0
+	ReportType_REPORT_LABEL_NAMES        ReportType = 1
+	ReportType_REPORT_LABEL_VALUES       ReportType = 2
+	ReportType_REPORT_SERIES_LABELS      ReportType = 3
+	ReportType_REPORT_TIME_SERIES        ReportType = 4
+	ReportType_REPORT_TREE               ReportType = 5
+	ReportType_REPORT_TIME_RANGE         ReportType = 6
+	ReportType_REPORT_CALLGRAPH          ReportType = 7
+	ReportType_REPORT_SELFTEST           ReportType = 8
+	ReportType_REPORT_MANIFEST           ReportType = 9
+	ReportType_REPORT_SYMBOL_TABLE       ReportType = 10
+	ReportType_REPORT_FUNCTION_CHANGES   ReportType = 11
+	ReportType_REPORT_FLAMEGRAPH_DIFF    ReportType = 12
+	ReportType_REPORT_STACKTRACES        ReportType = 13
+	ReportType_REPORT_STACK_DEPTH        ReportType = 14
+	ReportType_REPORT_PROFILE_DEVIATION  ReportType = 15
+	ReportType_REPORT_PROFILE_SIMILARITY ReportType = 16
+	ReportType_REPORT_TOP_CALLERS        ReportType = 17
+	ReportType_REPORT_TOP_PROFILES       ReportType = 18
+	ReportType_REPORT_TREE_DIFF          ReportType = 19
 )
 
 // Enum value maps for ReportType.
 var (
 	ReportType_name = map[int32]string{
-		0: "REPORT_UNSPECIFIED",
-		1: "REPORT_LABEL_NAMES",
-		2: "REPORT_LABEL_VALUES",
-		3: "REPORT_SERIES_LABELS",
-		4: "REPORT_TIME_SERIES",
-		5: "REPORT_TREE",
+		0:  "REPORT_UNSPECIFIED",
+		1:  "REPORT_LABEL_NAMES",
+		2:  "REPORT_LABEL_VALUES",
+		3:  "REPORT_SERIES_LABELS",
+		4:  "REPORT_TIME_SERIES",
+		5:  "REPORT_TREE",
+		6:  "REPORT_TIME_RANGE",
+		7:  "REPORT_CALLGRAPH",
+		8:  "REPORT_SELFTEST",
+		9:  "REPORT_MANIFEST",
+		10: "REPORT_SYMBOL_TABLE",
+		11: "REPORT_FUNCTION_CHANGES",
+		12: "REPORT_FLAMEGRAPH_DIFF",
+		13: "REPORT_STACKTRACES",
+		14: "REPORT_STACK_DEPTH",
+		15: "REPORT_PROFILE_DEVIATION",
+		16: "REPORT_PROFILE_SIMILARITY",
+		17: "REPORT_TOP_CALLERS",
+		18: "REPORT_TOP_PROFILES",
+		19: "REPORT_TREE_DIFF",
 	}
 	ReportType_value = map[string]int32{
-		"REPORT_UNSPECIFIED":   0,
-		"REPORT_LABEL_NAMES":   1,
-		"REPORT_LABEL_VALUES":  2,
-		"REPORT_SERIES_LABELS": 3,
-		"REPORT_TIME_SERIES":   4,
-		"REPORT_TREE":          5,
+		"REPORT_UNSPECIFIED":        0,
+		"REPORT_LABEL_NAMES":        1,
+		"REPORT_LABEL_VALUES":       2,
+		"REPORT_SERIES_LABELS":      3,
+		"REPORT_TIME_SERIES":        4,
+		"REPORT_TREE":               5,
+		"REPORT_TIME_RANGE":         6,
+		"REPORT_CALLGRAPH":          7,
+		"REPORT_SELFTEST":           8,
+		"REPORT_MANIFEST":           9,
+		"REPORT_SYMBOL_TABLE":       10,
+		"REPORT_FUNCTION_CHANGES":   11,
+		"REPORT_FLAMEGRAPH_DIFF":    12,
+		"REPORT_STACKTRACES":        13,
+		"REPORT_STACK_DEPTH":        14,
+		"REPORT_PROFILE_DEVIATION":  15,
+		"REPORT_PROFILE_SIMILARITY": 16,
+		"REPORT_TOP_CALLERS":        17,
+		"REPORT_TOP_PROFILES":       18,
+		"REPORT_TREE_DIFF":          19,
 	}
 )
 
@@ -123,11 +261,11 @@ func (x ReportType) String() string {
 }
 
 func (ReportType) Descriptor() protoreflect.EnumDescriptor {
-	return file_querybackend_v1_querybackend_proto_enumTypes[1].Descriptor()
+	return file_querybackend_v1_querybackend_proto_enumTypes[2].Descriptor()
 }
 
 func (ReportType) Type() protoreflect.EnumType {
-	return &file_querybackend_v1_querybackend_proto_enumTypes[1]
+	return &file_querybackend_v1_querybackend_proto_enumTypes[2]
 }
 
 func (x ReportType) Number() protoreflect.EnumNumber {
@@ -136,13 +274,292 @@ func (x ReportType) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use ReportType.Descriptor instead.
 func (ReportType) EnumDescriptor() ([]byte, []int) {
-	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{1}
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{2}
+}
+
+type TreeValueOperation int32
+
+const (
+	TreeValueOperation_TREE_VALUE_OPERATION_UNSPECIFIED TreeValueOperation = 0
+	TreeValueOperation_TREE_VALUE_OPERATION_ADD         TreeValueOperation = 1
+	TreeValueOperation_TREE_VALUE_OPERATION_SUBTRACT    TreeValueOperation = 2
+	TreeValueOperation_TREE_VALUE_OPERATION_MULTIPLY    TreeValueOperation = 3
+	// Stack traces the right operand is zero for are not included.
+	TreeValueOperation_TREE_VALUE_OPERATION_DIVIDE TreeValueOperation = 4
+)
+
+// Enum value maps for TreeValueOperation.
+var (
+	TreeValueOperation_name = map[int32]string{
+		0: "TREE_VALUE_OPERATION_UNSPECIFIED",
+		1: "TREE_VALUE_OPERATION_ADD",
+		2: "TREE_VALUE_OPERATION_SUBTRACT",
+		3: "TREE_VALUE_OPERATION_MULTIPLY",
+		4: "TREE_VALUE_OPERATION_DIVIDE",
+	}
+	TreeValueOperation_value = map[string]int32{
+		"TREE_VALUE_OPERATION_UNSPECIFIED": 0,
+		"TREE_VALUE_OPERATION_ADD":         1,
+		"TREE_VALUE_OPERATION_SUBTRACT":    2,
+		"TREE_VALUE_OPERATION_MULTIPLY":    3,
+		"TREE_VALUE_OPERATION_DIVIDE":      4,
+	}
+)
+
+func (x TreeValueOperation) Enum() *TreeValueOperation {
+	p := new(TreeValueOperation)
+	*p = x
+	return p
+}
+
+func (x TreeValueOperation) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TreeValueOperation) Descriptor() protoreflect.EnumDescriptor {
+	return file_querybackend_v1_querybackend_proto_enumTypes[3].Descriptor()
+}
+
+func (TreeValueOperation) Type() protoreflect.EnumType {
+	return &file_querybackend_v1_querybackend_proto_enumTypes[3]
+}
+
+func (x TreeValueOperation) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TreeValueOperation.Descriptor instead.
+func (TreeValueOperation) EnumDescriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{3}
+}
+
+type FunctionChangeRanking int32
+
+const (
+	// Functions are ranked by the magnitude of the self value change.
+	FunctionChangeRanking_FUNCTION_CHANGE_RANKING_ABSOLUTE FunctionChangeRanking = 0
+	// Functions are ranked by the magnitude of the self value change
+	// relative to the baseline. Functions absent in the baseline rank
+	// first, ordered by the absolute change.
+	FunctionChangeRanking_FUNCTION_CHANGE_RANKING_PERCENT FunctionChangeRanking = 1
+)
+
+// Enum value maps for FunctionChangeRanking.
+var (
+	FunctionChangeRanking_name = map[int32]string{
+		0: "FUNCTION_CHANGE_RANKING_ABSOLUTE",
+		1: "FUNCTION_CHANGE_RANKING_PERCENT",
+	}
+	FunctionChangeRanking_value = map[string]int32{
+		"FUNCTION_CHANGE_RANKING_ABSOLUTE": 0,
+		"FUNCTION_CHANGE_RANKING_PERCENT":  1,
+	}
+)
+
+func (x FunctionChangeRanking) Enum() *FunctionChangeRanking {
+	p := new(FunctionChangeRanking)
+	*p = x
+	return p
+}
+
+func (x FunctionChangeRanking) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FunctionChangeRanking) Descriptor() protoreflect.EnumDescriptor {
+	return file_querybackend_v1_querybackend_proto_enumTypes[4].Descriptor()
+}
+
+func (FunctionChangeRanking) Type() protoreflect.EnumType {
+	return &file_querybackend_v1_querybackend_proto_enumTypes[4]
+}
+
+func (x FunctionChangeRanking) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FunctionChangeRanking.Descriptor instead.
+func (FunctionChangeRanking) EnumDescriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{4}
+}
+
+// DiffMode is the representation of the difference of the values of a
+// node. The ratio of a value to zero, e.g. of a function only present in
+// the comparison, is an infinity of the sign of the value; the ratio of
+// two zeros is one. The log-ratio of values of different signs is NaN.
+type DiffMode int32
+
+const (
+	// Comparison value minus the baseline value.
+	DiffMode_DIFF_MODE_DELTA DiffMode = 0
+	// Comparison value divided by the baseline value.
+	DiffMode_DIFF_MODE_RATIO DiffMode = 1
+	// Natural logarithm of the ratio.
+	DiffMode_DIFF_MODE_LOG_RATIO DiffMode = 2
+)
+
+// Enum value maps for DiffMode.
+var (
+	DiffMode_name = map[int32]string{
+		0: "DIFF_MODE_DELTA",
+		1: "DIFF_MODE_RATIO",
+		2: "DIFF_MODE_LOG_RATIO",
+	}
+	DiffMode_value = map[string]int32{
+		"DIFF_MODE_DELTA":     0,
+		"DIFF_MODE_RATIO":     1,
+		"DIFF_MODE_LOG_RATIO": 2,
+	}
+)
+
+func (x DiffMode) Enum() *DiffMode {
+	p := new(DiffMode)
+	*p = x
+	return p
+}
+
+func (x DiffMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DiffMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_querybackend_v1_querybackend_proto_enumTypes[5].Descriptor()
+}
+
+func (DiffMode) Type() protoreflect.EnumType {
+	return &file_querybackend_v1_querybackend_proto_enumTypes[5]
+}
+
+func (x DiffMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DiffMode.Descriptor instead.
+func (DiffMode) EnumDescriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{5}
+}
+
+type ProfileSimilarityMetric int32
+
+const (
+	// Size of the intersection of the hot sets divided
+	// by the size of their union.
+	ProfileSimilarityMetric_PROFILE_SIMILARITY_METRIC_JACCARD ProfileSimilarityMetric = 0
+	// Cosine of the angle between the vectors of the self
+	// values of the functions of the union of the hot sets.
+	ProfileSimilarityMetric_PROFILE_SIMILARITY_METRIC_COSINE ProfileSimilarityMetric = 1
+)
+
+// Enum value maps for ProfileSimilarityMetric.
+var (
+	ProfileSimilarityMetric_name = map[int32]string{
+		0: "PROFILE_SIMILARITY_METRIC_JACCARD",
+		1: "PROFILE_SIMILARITY_METRIC_COSINE",
+	}
+	ProfileSimilarityMetric_value = map[string]int32{
+		"PROFILE_SIMILARITY_METRIC_JACCARD": 0,
+		"PROFILE_SIMILARITY_METRIC_COSINE":  1,
+	}
+)
+
+func (x ProfileSimilarityMetric) Enum() *ProfileSimilarityMetric {
+	p := new(ProfileSimilarityMetric)
+	*p = x
+	return p
+}
+
+func (x ProfileSimilarityMetric) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ProfileSimilarityMetric) Descriptor() protoreflect.EnumDescriptor {
+	return file_querybackend_v1_querybackend_proto_enumTypes[6].Descriptor()
+}
+
+func (ProfileSimilarityMetric) Type() protoreflect.EnumType {
+	return &file_querybackend_v1_querybackend_proto_enumTypes[6]
+}
+
+func (x ProfileSimilarityMetric) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ProfileSimilarityMetric.Descriptor instead.
+func (ProfileSimilarityMetric) EnumDescriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{6}
 }
 
 type InvokeOptions struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	// Maximum number of tree reports a single aggregator accepts.
+	// The query fails once the limit is exceeded. If not set,
+	// the query backend default limit applies.
+	MaxTreeReports int64 `protobuf:"varint,1,opt,name=max_tree_reports,json=maxTreeReports,proto3" json:"max_tree_reports,omitempty"`
+	// If set, the query fails if any of the blocks can't be read,
+	// instead of skipping it. Queries against an explicit list of
+	// blocks set the option.
+	FailOnSkippedBlocks bool `protobuf:"varint,2,opt,name=fail_on_skipped_blocks,json=failOnSkippedBlocks,proto3" json:"fail_on_skipped_blocks,omitempty"`
+	// Maximum size in bytes of the serialized tree of a tree report. If the
+	// tree exceeds the size, the number of the nodes is reduced until it
+	// fits. If not set, the query backend default limit applies.
+	MaxTreeReportSize int64 `protobuf:"varint,3,opt,name=max_tree_report_size,json=maxTreeReportSize,proto3" json:"max_tree_report_size,omitempty"`
+	// If set, the queries of the request are executed as a batch: the
+	// sections each of the queries depends on are loaded once per dataset
+	// and shared by all the queries, and a report is returned for each of
+	// the queries, even if several queries have the same type. The reports
+	// refer to the queries with Report.query_index.
+	Batch bool `protobuf:"varint,4,opt,name=batch,proto3" json:"batch,omitempty"`
+	// ID of the query, correlating its log lines, trace spans, and metric
+	// exemplars across the query backends. If not set, an ID is generated
+	// by the query backend the request is sent to: the sub-queries share
+	// it with the request.
+	QueryId string `protobuf:"bytes,5,opt,name=query_id,json=queryId,proto3" json:"query_id,omitempty"`
+	// If set, the checksums of the sections of each dataset are verified
+	// when the dataset is opened, and the query fails if the data is
+	// corrupted. The verification reads the sections entirely, therefore
+	// it is only enabled on demand, or in the query backend configuration.
+	VerifyChecksums bool `protobuf:"varint,6,opt,name=verify_checksums,json=verifyChecksums,proto3" json:"verify_checksums,omitempty"`
+	// Approximate maximum size in bytes of a chunk of the InvokeStream
+	// response. The reports are sent in as few chunks as possible; a tree
+	// that exceeds the size is split into parts, sent in the subsequent
+	// chunks as the continuations of the report. If not set, the response
+	// is sent in a single chunk.
+	StreamChunkSize int64 `protobuf:"varint,7,opt,name=stream_chunk_size,json=streamChunkSize,proto3" json:"stream_chunk_size,omitempty"`
+	// If set, the sections the queries depend on are pinned in the section
+	// cache of the query backends that read the blocks, or the pins are
+	// renewed, so that the subsequent queries against the blocks do not
+	// load the sections from the object storage. The pins expire after
+	// the section cache TTL. Ignored, if the section cache is disabled.
+	PinBlocks bool `protobuf:"varint,8,opt,name=pin_blocks,json=pinBlocks,proto3" json:"pin_blocks,omitempty"`
+	// Set by the query backend in the sub-query requests: the reports are
+	// aggregated by the query backend that sent the request. The reports
+	// of a partial request may be built in an intermediate form, e.g. the
+	// value expressions are evaluated once the operands are merged.
+	Partial bool `protobuf:"varint,9,opt,name=partial,proto3" json:"partial,omitempty"`
+	// If set, the in-memory heads of the segments that have not been
+	// flushed yet are queried along with the blocks, so that the most
+	// recent data is visible. Ignored, if the query backend is not
+	// configured to read the heads. The heads already flushed to the
+	// blocks of the query plan are skipped.
+	ReadHeads bool `protobuf:"varint,10,opt,name=read_heads,json=readHeads,proto3" json:"read_heads,omitempty"`
+	// Set by the query backend in the sub-query requests that read the
+	// heads: the IDs of all the blocks of the query plan. The heads are
+	// only read by one of the sub-queries.
+	PlanBlockIds []string `protobuf:"bytes,11,rep,name=plan_block_ids,json=planBlockIds,proto3" json:"plan_block_ids,omitempty"`
+	// If set, the node names of the tree reports are interned as the
+	// reports are aggregated: identical names share the storage, which
+	// reduces the heap of the aggregations of many trees with the same
+	// functions. The reports are then decoded one at a time. If not set,
+	// the query backend default applies.
+	TreeStringInterning bool `protobuf:"varint,12,opt,name=tree_string_interning,json=treeStringInterning,proto3" json:"tree_string_interning,omitempty"`
+	// Sequence number of the last chunk of the response the client has
+	// received, if the stream is resumed after a failure. The response is
+	// built again, and only the chunks that follow are sent. Only applies
+	// to the InvokeStream RPC.
+	StreamResumeAfter int64 `protobuf:"varint,13,opt,name=stream_resume_after,json=streamResumeAfter,proto3" json:"stream_resume_after,omitempty"`
 }
 
 func (x *InvokeOptions) Reset() {
@@ -177,6 +594,97 @@ func (*InvokeOptions) Descriptor() ([]byte, []int) {
 	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{0}
 }
 
+func (x *InvokeOptions) GetMaxTreeReports() int64 {
+	if x != nil {
+		return x.MaxTreeReports
+	}
+	return 0
+}
+
+func (x *InvokeOptions) GetFailOnSkippedBlocks() bool {
+	if x != nil {
+		return x.FailOnSkippedBlocks
+	}
+	return false
+}
+
+func (x *InvokeOptions) GetMaxTreeReportSize() int64 {
+	if x != nil {
+		return x.MaxTreeReportSize
+	}
+	return 0
+}
+
+func (x *InvokeOptions) GetBatch() bool {
+	if x != nil {
+		return x.Batch
+	}
+	return false
+}
+
+func (x *InvokeOptions) GetQueryId() string {
+	if x != nil {
+		return x.QueryId
+	}
+	return ""
+}
+
+func (x *InvokeOptions) GetVerifyChecksums() bool {
+	if x != nil {
+		return x.VerifyChecksums
+	}
+	return false
+}
+
+func (x *InvokeOptions) GetStreamChunkSize() int64 {
+	if x != nil {
+		return x.StreamChunkSize
+	}
+	return 0
+}
+
+func (x *InvokeOptions) GetPinBlocks() bool {
+	if x != nil {
+		return x.PinBlocks
+	}
+	return false
+}
+
+func (x *InvokeOptions) GetPartial() bool {
+	if x != nil {
+		return x.Partial
+	}
+	return false
+}
+
+func (x *InvokeOptions) GetReadHeads() bool {
+	if x != nil {
+		return x.ReadHeads
+	}
+	return false
+}
+
+func (x *InvokeOptions) GetPlanBlockIds() []string {
+	if x != nil {
+		return x.PlanBlockIds
+	}
+	return nil
+}
+
+func (x *InvokeOptions) GetTreeStringInterning() bool {
+	if x != nil {
+		return x.TreeStringInterning
+	}
+	return false
+}
+
+func (x *InvokeOptions) GetStreamResumeAfter() int64 {
+	if x != nil {
+		return x.StreamResumeAfter
+	}
+	return 0
+}
+
 type InvokeRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -189,6 +697,12 @@ type InvokeRequest struct {
 	Query         []*Query       `protobuf:"bytes,5,rep,name=query,proto3" json:"query,omitempty"`
 	QueryPlan     *QueryPlan     `protobuf:"bytes,6,opt,name=query_plan,json=queryPlan,proto3" json:"query_plan,omitempty"`
 	Options       *InvokeOptions `protobuf:"bytes,7,opt,name=options,proto3" json:"options,omitempty"`
+	// If set, the query is run against the blocks listed instead of the
+	// query plan: the blocks are looked up in the metastore, bypassing the
+	// selection by the time range and the label selector. The query fails
+	// if any of the blocks is not found or can't be read. If no time range
+	// is specified, the time range of the blocks is queried.
+	BlockIds []string `protobuf:"bytes,8,rep,name=block_ids,json=blockIds,proto3" json:"block_ids,omitempty"`
 }
 
 func (x *InvokeRequest) Reset() {
@@ -272,6 +786,13 @@ func (x *InvokeRequest) GetOptions() *InvokeOptions {
 	return nil
 }
 
+func (x *InvokeRequest) GetBlockIds() []string {
+	if x != nil {
+		return x.BlockIds
+	}
+	return nil
+}
+
 // Query plan is represented by a DAG, where each node
 // might be either "merge" or "read" (leaves). Each node
 // references a range: merge nodes refer to other nodes,
@@ -282,9 +803,9 @@ type QueryPlan struct {
 	unknownFields protoimpl.UnknownFields
 
 	// Each node is encoded with 3 values:
-	//   - node type: 0 - read, 1 - merge;
-	//   - range offset;
-	//   - range length.
+	//  - node type: 0 - read, 1 - merge;
+	//  - range offset;
+	//  - range length.
 	Graph []uint32 `protobuf:"varint,1,rep,packed,name=graph,proto3" json:"graph,omitempty"`
 	// The blocks matching the query.
 	Blocks []*v1.BlockMeta `protobuf:"bytes,2,rep,name=blocks,proto3" json:"blocks,omitempty"`
@@ -349,6 +870,25 @@ type Query struct {
 	SeriesLabels *SeriesLabelsQuery `protobuf:"bytes,4,opt,name=series_labels,json=seriesLabels,proto3" json:"series_labels,omitempty"`
 	TimeSeries   *TimeSeriesQuery   `protobuf:"bytes,5,opt,name=time_series,json=timeSeries,proto3" json:"time_series,omitempty"`
 	Tree         *TreeQuery         `protobuf:"bytes,6,opt,name=tree,proto3" json:"tree,omitempty"`
+	TimeRange    *TimeRangeQuery    `protobuf:"bytes,7,opt,name=time_range,json=timeRange,proto3" json:"time_range,omitempty"`
+	// Opaque query of an externally registered query type.
+	Custom    []byte          `protobuf:"bytes,8,opt,name=custom,proto3" json:"custom,omitempty"`
+	CallGraph *CallGraphQuery `protobuf:"bytes,9,opt,name=call_graph,json=callGraph,proto3" json:"call_graph,omitempty"`
+	// Timeout of the query, in milliseconds. If the query type
+	// has a shorter default timeout, the latter applies.
+	TimeoutMs         int64                   `protobuf:"varint,10,opt,name=timeout_ms,json=timeoutMs,proto3" json:"timeout_ms,omitempty"`
+	SelfTest          *SelfTestQuery          `protobuf:"bytes,11,opt,name=self_test,json=selfTest,proto3" json:"self_test,omitempty"`
+	Manifest          *ManifestQuery          `protobuf:"bytes,12,opt,name=manifest,proto3" json:"manifest,omitempty"`
+	SymbolTable       *SymbolTableQuery       `protobuf:"bytes,13,opt,name=symbol_table,json=symbolTable,proto3" json:"symbol_table,omitempty"`
+	FunctionChanges   *FunctionChangesQuery   `protobuf:"bytes,14,opt,name=function_changes,json=functionChanges,proto3" json:"function_changes,omitempty"`
+	FlamegraphDiff    *FlameGraphDiffQuery    `protobuf:"bytes,15,opt,name=flamegraph_diff,json=flamegraphDiff,proto3" json:"flamegraph_diff,omitempty"`
+	Stacktraces       *StacktracesQuery       `protobuf:"bytes,16,opt,name=stacktraces,proto3" json:"stacktraces,omitempty"`
+	StackDepth        *StackDepthQuery        `protobuf:"bytes,17,opt,name=stack_depth,json=stackDepth,proto3" json:"stack_depth,omitempty"`
+	ProfileDeviation  *ProfileDeviationQuery  `protobuf:"bytes,18,opt,name=profile_deviation,json=profileDeviation,proto3" json:"profile_deviation,omitempty"`
+	ProfileSimilarity *ProfileSimilarityQuery `protobuf:"bytes,19,opt,name=profile_similarity,json=profileSimilarity,proto3" json:"profile_similarity,omitempty"`
+	TopCallers        *TopCallersQuery        `protobuf:"bytes,20,opt,name=top_callers,json=topCallers,proto3" json:"top_callers,omitempty"`
+	TopProfiles       *TopProfilesQuery       `protobuf:"bytes,21,opt,name=top_profiles,json=topProfiles,proto3" json:"top_profiles,omitempty"`
+	TreeDiff          *TreeDiffQuery          `protobuf:"bytes,22,opt,name=tree_diff,json=treeDiff,proto3" json:"tree_diff,omitempty"`
 }
 
 func (x *Query) Reset() {
@@ -425,212 +965,149 @@ func (x *Query) GetTree() *TreeQuery {
 	return nil
 }
 
-type InvokeResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-
-	Reports     []*Report    `protobuf:"bytes,1,rep,name=reports,proto3" json:"reports,omitempty"`
-	Diagnostics *Diagnostics `protobuf:"bytes,2,opt,name=diagnostics,proto3" json:"diagnostics,omitempty"`
+func (x *Query) GetTimeRange() *TimeRangeQuery {
+	if x != nil {
+		return x.TimeRange
+	}
+	return nil
 }
 
-func (x *InvokeResponse) Reset() {
-	*x = InvokeResponse{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_querybackend_v1_querybackend_proto_msgTypes[4]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
+func (x *Query) GetCustom() []byte {
+	if x != nil {
+		return x.Custom
 	}
+	return nil
 }
 
-func (x *InvokeResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *Query) GetCallGraph() *CallGraphQuery {
+	if x != nil {
+		return x.CallGraph
+	}
+	return nil
 }
 
-func (*InvokeResponse) ProtoMessage() {}
-
-func (x *InvokeResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_querybackend_v1_querybackend_proto_msgTypes[4]
-	if protoimpl.UnsafeEnabled && x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+func (x *Query) GetTimeoutMs() int64 {
+	if x != nil {
+		return x.TimeoutMs
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use InvokeResponse.ProtoReflect.Descriptor instead.
-func (*InvokeResponse) Descriptor() ([]byte, []int) {
-	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{4}
+func (x *Query) GetSelfTest() *SelfTestQuery {
+	if x != nil {
+		return x.SelfTest
+	}
+	return nil
 }
 
-func (x *InvokeResponse) GetReports() []*Report {
+func (x *Query) GetManifest() *ManifestQuery {
 	if x != nil {
-		return x.Reports
+		return x.Manifest
 	}
 	return nil
 }
 
-func (x *InvokeResponse) GetDiagnostics() *Diagnostics {
+func (x *Query) GetSymbolTable() *SymbolTableQuery {
 	if x != nil {
-		return x.Diagnostics
+		return x.SymbolTable
 	}
 	return nil
 }
 
-// Diagnostic messages, events, statistics, analytics, etc.
-type Diagnostics struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+func (x *Query) GetFunctionChanges() *FunctionChangesQuery {
+	if x != nil {
+		return x.FunctionChanges
+	}
+	return nil
 }
 
-func (x *Diagnostics) Reset() {
-	*x = Diagnostics{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_querybackend_v1_querybackend_proto_msgTypes[5]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
-}
-
-func (x *Diagnostics) String() string {
-	return protoimpl.X.MessageStringOf(x)
-}
-
-func (*Diagnostics) ProtoMessage() {}
-
-func (x *Diagnostics) ProtoReflect() protoreflect.Message {
-	mi := &file_querybackend_v1_querybackend_proto_msgTypes[5]
-	if protoimpl.UnsafeEnabled && x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
-	}
-	return mi.MessageOf(x)
-}
-
-// Deprecated: Use Diagnostics.ProtoReflect.Descriptor instead.
-func (*Diagnostics) Descriptor() ([]byte, []int) {
-	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{5}
-}
-
-type Report struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-
-	ReportType ReportType `protobuf:"varint,1,opt,name=report_type,json=reportType,proto3,enum=querybackend.v1.ReportType" json:"report_type,omitempty"`
-	// Exactly one of the following fields should be set,
-	// depending on the report type.
-	LabelNames   *LabelNamesReport   `protobuf:"bytes,2,opt,name=label_names,json=labelNames,proto3" json:"label_names,omitempty"`
-	LabelValues  *LabelValuesReport  `protobuf:"bytes,3,opt,name=label_values,json=labelValues,proto3" json:"label_values,omitempty"`
-	SeriesLabels *SeriesLabelsReport `protobuf:"bytes,4,opt,name=series_labels,json=seriesLabels,proto3" json:"series_labels,omitempty"`
-	TimeSeries   *TimeSeriesReport   `protobuf:"bytes,5,opt,name=time_series,json=timeSeries,proto3" json:"time_series,omitempty"`
-	Tree         *TreeReport         `protobuf:"bytes,6,opt,name=tree,proto3" json:"tree,omitempty"`
-}
-
-func (x *Report) Reset() {
-	*x = Report{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_querybackend_v1_querybackend_proto_msgTypes[6]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
+func (x *Query) GetFlamegraphDiff() *FlameGraphDiffQuery {
+	if x != nil {
+		return x.FlamegraphDiff
 	}
+	return nil
 }
 
-func (x *Report) String() string {
-	return protoimpl.X.MessageStringOf(x)
-}
-
-func (*Report) ProtoMessage() {}
-
-func (x *Report) ProtoReflect() protoreflect.Message {
-	mi := &file_querybackend_v1_querybackend_proto_msgTypes[6]
-	if protoimpl.UnsafeEnabled && x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+func (x *Query) GetStacktraces() *StacktracesQuery {
+	if x != nil {
+		return x.Stacktraces
 	}
-	return mi.MessageOf(x)
-}
-
-// Deprecated: Use Report.ProtoReflect.Descriptor instead.
-func (*Report) Descriptor() ([]byte, []int) {
-	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{6}
+	return nil
 }
 
-func (x *Report) GetReportType() ReportType {
+func (x *Query) GetStackDepth() *StackDepthQuery {
 	if x != nil {
-		return x.ReportType
+		return x.StackDepth
 	}
-	return ReportType_REPORT_UNSPECIFIED
+	return nil
 }
 
-func (x *Report) GetLabelNames() *LabelNamesReport {
+func (x *Query) GetProfileDeviation() *ProfileDeviationQuery {
 	if x != nil {
-		return x.LabelNames
+		return x.ProfileDeviation
 	}
 	return nil
 }
 
-func (x *Report) GetLabelValues() *LabelValuesReport {
+func (x *Query) GetProfileSimilarity() *ProfileSimilarityQuery {
 	if x != nil {
-		return x.LabelValues
+		return x.ProfileSimilarity
 	}
 	return nil
 }
 
-func (x *Report) GetSeriesLabels() *SeriesLabelsReport {
+func (x *Query) GetTopCallers() *TopCallersQuery {
 	if x != nil {
-		return x.SeriesLabels
+		return x.TopCallers
 	}
 	return nil
 }
 
-func (x *Report) GetTimeSeries() *TimeSeriesReport {
+func (x *Query) GetTopProfiles() *TopProfilesQuery {
 	if x != nil {
-		return x.TimeSeries
+		return x.TopProfiles
 	}
 	return nil
 }
 
-func (x *Report) GetTree() *TreeReport {
+func (x *Query) GetTreeDiff() *TreeDiffQuery {
 	if x != nil {
-		return x.Tree
+		return x.TreeDiff
 	}
 	return nil
 }
 
-type LabelNamesQuery struct {
+type InvokeResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Reports     []*Report    `protobuf:"bytes,1,rep,name=reports,proto3" json:"reports,omitempty"`
+	Diagnostics *Diagnostics `protobuf:"bytes,2,opt,name=diagnostics,proto3" json:"diagnostics,omitempty"`
+	// Sequence number of the chunk of the streamed response, starting from
+	// one. The chunks of the same request are identical across the streams,
+	// which allows the client to skip the ones it has already received when
+	// it resumes the stream. Zero if the response is not streamed.
+	Sequence int64 `protobuf:"varint,3,opt,name=sequence,proto3" json:"sequence,omitempty"`
 }
 
-func (x *LabelNamesQuery) Reset() {
-	*x = LabelNamesQuery{}
+func (x *InvokeResponse) Reset() {
+	*x = InvokeResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_querybackend_v1_querybackend_proto_msgTypes[7]
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *LabelNamesQuery) String() string {
+func (x *InvokeResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LabelNamesQuery) ProtoMessage() {}
+func (*InvokeResponse) ProtoMessage() {}
 
-func (x *LabelNamesQuery) ProtoReflect() protoreflect.Message {
-	mi := &file_querybackend_v1_querybackend_proto_msgTypes[7]
+func (x *InvokeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -641,37 +1118,65 @@ func (x *LabelNamesQuery) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LabelNamesQuery.ProtoReflect.Descriptor instead.
-func (*LabelNamesQuery) Descriptor() ([]byte, []int) {
-	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use InvokeResponse.ProtoReflect.Descriptor instead.
+func (*InvokeResponse) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{4}
 }
 
-type LabelNamesReport struct {
+func (x *InvokeResponse) GetReports() []*Report {
+	if x != nil {
+		return x.Reports
+	}
+	return nil
+}
+
+func (x *InvokeResponse) GetDiagnostics() *Diagnostics {
+	if x != nil {
+		return x.Diagnostics
+	}
+	return nil
+}
+
+func (x *InvokeResponse) GetSequence() int64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+// Diagnostic messages, events, statistics, analytics, etc.
+type Diagnostics struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Query      *LabelNamesQuery `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
-	LabelNames []string         `protobuf:"bytes,2,rep,name=label_names,json=labelNames,proto3" json:"label_names,omitempty"`
+	// Blocks that were not queried. If not empty, the results
+	// may be incomplete. The list is capped: see skipped_blocks_count.
+	SkippedBlocks []*SkippedBlock `protobuf:"bytes,1,rep,name=skipped_blocks,json=skippedBlocks,proto3" json:"skipped_blocks,omitempty"`
+	// Total number of the skipped blocks.
+	SkippedBlocksCount uint64 `protobuf:"varint,2,opt,name=skipped_blocks_count,json=skippedBlocksCount,proto3" json:"skipped_blocks_count,omitempty"`
+	// Regions of a federated query that failed to respond. If not
+	// empty, the results only include the data of the other regions.
+	FailedRegions []*FailedRegion `protobuf:"bytes,3,rep,name=failed_regions,json=failedRegions,proto3" json:"failed_regions,omitempty"`
 }
 
-func (x *LabelNamesReport) Reset() {
-	*x = LabelNamesReport{}
+func (x *Diagnostics) Reset() {
+	*x = Diagnostics{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_querybackend_v1_querybackend_proto_msgTypes[8]
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *LabelNamesReport) String() string {
+func (x *Diagnostics) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LabelNamesReport) ProtoMessage() {}
+func (*Diagnostics) ProtoMessage() {}
 
-func (x *LabelNamesReport) ProtoReflect() protoreflect.Message {
-	mi := &file_querybackend_v1_querybackend_proto_msgTypes[8]
+func (x *Diagnostics) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -682,50 +1187,59 @@ func (x *LabelNamesReport) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LabelNamesReport.ProtoReflect.Descriptor instead.
-func (*LabelNamesReport) Descriptor() ([]byte, []int) {
-	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use Diagnostics.ProtoReflect.Descriptor instead.
+func (*Diagnostics) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *LabelNamesReport) GetQuery() *LabelNamesQuery {
+func (x *Diagnostics) GetSkippedBlocks() []*SkippedBlock {
 	if x != nil {
-		return x.Query
+		return x.SkippedBlocks
 	}
 	return nil
 }
 
-func (x *LabelNamesReport) GetLabelNames() []string {
+func (x *Diagnostics) GetSkippedBlocksCount() uint64 {
 	if x != nil {
-		return x.LabelNames
+		return x.SkippedBlocksCount
+	}
+	return 0
+}
+
+func (x *Diagnostics) GetFailedRegions() []*FailedRegion {
+	if x != nil {
+		return x.FailedRegions
 	}
 	return nil
 }
 
-type LabelValuesQuery struct {
+type FailedRegion struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	LabelName string `protobuf:"bytes,1,opt,name=label_name,json=labelName,proto3" json:"label_name,omitempty"`
+	Region string `protobuf:"bytes,1,opt,name=region,proto3" json:"region,omitempty"`
+	// Human-readable reason of the failure.
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 }
 
-func (x *LabelValuesQuery) Reset() {
-	*x = LabelValuesQuery{}
+func (x *FailedRegion) Reset() {
+	*x = FailedRegion{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_querybackend_v1_querybackend_proto_msgTypes[9]
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *LabelValuesQuery) String() string {
+func (x *FailedRegion) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LabelValuesQuery) ProtoMessage() {}
+func (*FailedRegion) ProtoMessage() {}
 
-func (x *LabelValuesQuery) ProtoReflect() protoreflect.Message {
-	mi := &file_querybackend_v1_querybackend_proto_msgTypes[9]
+func (x *FailedRegion) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -736,44 +1250,53 @@ func (x *LabelValuesQuery) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LabelValuesQuery.ProtoReflect.Descriptor instead.
-func (*LabelValuesQuery) Descriptor() ([]byte, []int) {
-	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use FailedRegion.ProtoReflect.Descriptor instead.
+func (*FailedRegion) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *LabelValuesQuery) GetLabelName() string {
+func (x *FailedRegion) GetRegion() string {
 	if x != nil {
-		return x.LabelName
+		return x.Region
 	}
 	return ""
 }
 
-type LabelValuesReport struct {
+func (x *FailedRegion) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type SkippedBlock struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Query       *LabelValuesQuery `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
-	LabelValues []string          `protobuf:"bytes,2,rep,name=label_values,json=labelValues,proto3" json:"label_values,omitempty"`
+	BlockId string     `protobuf:"bytes,1,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+	Reason  SkipReason `protobuf:"varint,2,opt,name=reason,proto3,enum=querybackend.v1.SkipReason" json:"reason,omitempty"`
+	// Human-readable details, if any.
+	Message string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
 }
 
-func (x *LabelValuesReport) Reset() {
-	*x = LabelValuesReport{}
+func (x *SkippedBlock) Reset() {
+	*x = SkippedBlock{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_querybackend_v1_querybackend_proto_msgTypes[10]
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *LabelValuesReport) String() string {
+func (x *SkippedBlock) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LabelValuesReport) ProtoMessage() {}
+func (*SkippedBlock) ProtoMessage() {}
 
-func (x *LabelValuesReport) ProtoReflect() protoreflect.Message {
-	mi := &file_querybackend_v1_querybackend_proto_msgTypes[10]
+func (x *SkippedBlock) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -784,50 +1307,86 @@ func (x *LabelValuesReport) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LabelValuesReport.ProtoReflect.Descriptor instead.
-func (*LabelValuesReport) Descriptor() ([]byte, []int) {
-	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use SkippedBlock.ProtoReflect.Descriptor instead.
+func (*SkippedBlock) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *LabelValuesReport) GetQuery() *LabelValuesQuery {
+func (x *SkippedBlock) GetBlockId() string {
 	if x != nil {
-		return x.Query
+		return x.BlockId
 	}
-	return nil
+	return ""
 }
 
-func (x *LabelValuesReport) GetLabelValues() []string {
+func (x *SkippedBlock) GetReason() SkipReason {
 	if x != nil {
-		return x.LabelValues
+		return x.Reason
 	}
-	return nil
+	return SkipReason_SKIP_REASON_UNSPECIFIED
 }
 
-type SeriesLabelsQuery struct {
+func (x *SkippedBlock) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type Report struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	LabelNames []string `protobuf:"bytes,1,rep,name=label_names,json=labelNames,proto3" json:"label_names,omitempty"`
+	ReportType ReportType `protobuf:"varint,1,opt,name=report_type,json=reportType,proto3,enum=querybackend.v1.ReportType" json:"report_type,omitempty"`
+	// Exactly one of the following fields should be set,
+	// depending on the report type.
+	LabelNames   *LabelNamesReport   `protobuf:"bytes,2,opt,name=label_names,json=labelNames,proto3" json:"label_names,omitempty"`
+	LabelValues  *LabelValuesReport  `protobuf:"bytes,3,opt,name=label_values,json=labelValues,proto3" json:"label_values,omitempty"`
+	SeriesLabels *SeriesLabelsReport `protobuf:"bytes,4,opt,name=series_labels,json=seriesLabels,proto3" json:"series_labels,omitempty"`
+	TimeSeries   *TimeSeriesReport   `protobuf:"bytes,5,opt,name=time_series,json=timeSeries,proto3" json:"time_series,omitempty"`
+	Tree         *TreeReport         `protobuf:"bytes,6,opt,name=tree,proto3" json:"tree,omitempty"`
+	TimeRange    *TimeRangeReport    `protobuf:"bytes,7,opt,name=time_range,json=timeRange,proto3" json:"time_range,omitempty"`
+	// Opaque report of an externally registered report type.
+	Custom            []byte                   `protobuf:"bytes,8,opt,name=custom,proto3" json:"custom,omitempty"`
+	CallGraph         *CallGraphReport         `protobuf:"bytes,9,opt,name=call_graph,json=callGraph,proto3" json:"call_graph,omitempty"`
+	SelfTest          *SelfTestReport          `protobuf:"bytes,10,opt,name=self_test,json=selfTest,proto3" json:"self_test,omitempty"`
+	Manifest          *ManifestReport          `protobuf:"bytes,11,opt,name=manifest,proto3" json:"manifest,omitempty"`
+	SymbolTable       *SymbolTableReport       `protobuf:"bytes,12,opt,name=symbol_table,json=symbolTable,proto3" json:"symbol_table,omitempty"`
+	FunctionChanges   *FunctionChangesReport   `protobuf:"bytes,13,opt,name=function_changes,json=functionChanges,proto3" json:"function_changes,omitempty"`
+	FlamegraphDiff    *FlameGraphDiffReport    `protobuf:"bytes,14,opt,name=flamegraph_diff,json=flamegraphDiff,proto3" json:"flamegraph_diff,omitempty"`
+	Stacktraces       *StacktracesReport       `protobuf:"bytes,15,opt,name=stacktraces,proto3" json:"stacktraces,omitempty"`
+	StackDepth        *StackDepthReport        `protobuf:"bytes,16,opt,name=stack_depth,json=stackDepth,proto3" json:"stack_depth,omitempty"`
+	ProfileDeviation  *ProfileDeviationReport  `protobuf:"bytes,18,opt,name=profile_deviation,json=profileDeviation,proto3" json:"profile_deviation,omitempty"`
+	ProfileSimilarity *ProfileSimilarityReport `protobuf:"bytes,20,opt,name=profile_similarity,json=profileSimilarity,proto3" json:"profile_similarity,omitempty"`
+	TopCallers        *TopCallersReport        `protobuf:"bytes,21,opt,name=top_callers,json=topCallers,proto3" json:"top_callers,omitempty"`
+	TopProfiles       *TopProfilesReport       `protobuf:"bytes,22,opt,name=top_profiles,json=topProfiles,proto3" json:"top_profiles,omitempty"`
+	TreeDiff          *TreeDiffReport          `protobuf:"bytes,23,opt,name=tree_diff,json=treeDiff,proto3" json:"tree_diff,omitempty"`
+	// Index of the query of the batch the report belongs to.
+	// Only set if the request is executed as a batch.
+	QueryIndex uint32 `protobuf:"varint,17,opt,name=query_index,json=queryIndex,proto3" json:"query_index,omitempty"`
+	// Type of the query that produced the report. Several query types
+	// may share the same report type.
+	QueryType QueryType `protobuf:"varint,19,opt,name=query_type,json=queryType,proto3,enum=querybackend.v1.QueryType" json:"query_type,omitempty"`
 }
 
-func (x *SeriesLabelsQuery) Reset() {
-	*x = SeriesLabelsQuery{}
+func (x *Report) Reset() {
+	*x = Report{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_querybackend_v1_querybackend_proto_msgTypes[11]
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *SeriesLabelsQuery) String() string {
+func (x *Report) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SeriesLabelsQuery) ProtoMessage() {}
+func (*Report) ProtoMessage() {}
 
-func (x *SeriesLabelsQuery) ProtoReflect() protoreflect.Message {
-	mi := &file_querybackend_v1_querybackend_proto_msgTypes[11]
+func (x *Report) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -838,44 +1397,236 @@ func (x *SeriesLabelsQuery) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SeriesLabelsQuery.ProtoReflect.Descriptor instead.
-func (*SeriesLabelsQuery) Descriptor() ([]byte, []int) {
-	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use Report.ProtoReflect.Descriptor instead.
+func (*Report) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *SeriesLabelsQuery) GetLabelNames() []string {
+func (x *Report) GetReportType() ReportType {
 	if x != nil {
-		return x.LabelNames
+		return x.ReportType
 	}
-	return nil
+	return ReportType_REPORT_UNSPECIFIED
 }
 
-type SeriesLabelsReport struct {
-	state         protoimpl.MessageState
+func (x *Report) GetLabelNames() *LabelNamesReport {
+	if x != nil {
+		return x.LabelNames
+	}
+	return nil
+}
+
+func (x *Report) GetLabelValues() *LabelValuesReport {
+	if x != nil {
+		return x.LabelValues
+	}
+	return nil
+}
+
+func (x *Report) GetSeriesLabels() *SeriesLabelsReport {
+	if x != nil {
+		return x.SeriesLabels
+	}
+	return nil
+}
+
+func (x *Report) GetTimeSeries() *TimeSeriesReport {
+	if x != nil {
+		return x.TimeSeries
+	}
+	return nil
+}
+
+func (x *Report) GetTree() *TreeReport {
+	if x != nil {
+		return x.Tree
+	}
+	return nil
+}
+
+func (x *Report) GetTimeRange() *TimeRangeReport {
+	if x != nil {
+		return x.TimeRange
+	}
+	return nil
+}
+
+func (x *Report) GetCustom() []byte {
+	if x != nil {
+		return x.Custom
+	}
+	return nil
+}
+
+func (x *Report) GetCallGraph() *CallGraphReport {
+	if x != nil {
+		return x.CallGraph
+	}
+	return nil
+}
+
+func (x *Report) GetSelfTest() *SelfTestReport {
+	if x != nil {
+		return x.SelfTest
+	}
+	return nil
+}
+
+func (x *Report) GetManifest() *ManifestReport {
+	if x != nil {
+		return x.Manifest
+	}
+	return nil
+}
+
+func (x *Report) GetSymbolTable() *SymbolTableReport {
+	if x != nil {
+		return x.SymbolTable
+	}
+	return nil
+}
+
+func (x *Report) GetFunctionChanges() *FunctionChangesReport {
+	if x != nil {
+		return x.FunctionChanges
+	}
+	return nil
+}
+
+func (x *Report) GetFlamegraphDiff() *FlameGraphDiffReport {
+	if x != nil {
+		return x.FlamegraphDiff
+	}
+	return nil
+}
+
+func (x *Report) GetStacktraces() *StacktracesReport {
+	if x != nil {
+		return x.Stacktraces
+	}
+	return nil
+}
+
+func (x *Report) GetStackDepth() *StackDepthReport {
+	if x != nil {
+		return x.StackDepth
+	}
+	return nil
+}
+
+func (x *Report) GetProfileDeviation() *ProfileDeviationReport {
+	if x != nil {
+		return x.ProfileDeviation
+	}
+	return nil
+}
+
+func (x *Report) GetProfileSimilarity() *ProfileSimilarityReport {
+	if x != nil {
+		return x.ProfileSimilarity
+	}
+	return nil
+}
+
+func (x *Report) GetTopCallers() *TopCallersReport {
+	if x != nil {
+		return x.TopCallers
+	}
+	return nil
+}
+
+func (x *Report) GetTopProfiles() *TopProfilesReport {
+	if x != nil {
+		return x.TopProfiles
+	}
+	return nil
+}
+
+func (x *Report) GetTreeDiff() *TreeDiffReport {
+	if x != nil {
+		return x.TreeDiff
+	}
+	return nil
+}
+
+func (x *Report) GetQueryIndex() uint32 {
+	if x != nil {
+		return x.QueryIndex
+	}
+	return 0
+}
+
+func (x *Report) GetQueryType() QueryType {
+	if x != nil {
+		return x.QueryType
+	}
+	return QueryType_QUERY_UNSPECIFIED
+}
+
+type LabelNamesQuery struct {
+	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+}
 
-	Query        *SeriesLabelsQuery `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
-	SeriesLabels []*v11.Labels      `protobuf:"bytes,2,rep,name=series_labels,json=seriesLabels,proto3" json:"series_labels,omitempty"`
+func (x *LabelNamesQuery) Reset() {
+	*x = LabelNamesQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
 }
 
-func (x *SeriesLabelsReport) Reset() {
-	*x = SeriesLabelsReport{}
+func (x *LabelNamesQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LabelNamesQuery) ProtoMessage() {}
+
+func (x *LabelNamesQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LabelNamesQuery.ProtoReflect.Descriptor instead.
+func (*LabelNamesQuery) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{9}
+}
+
+type LabelNamesReport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Query      *LabelNamesQuery `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	LabelNames []string         `protobuf:"bytes,2,rep,name=label_names,json=labelNames,proto3" json:"label_names,omitempty"`
+}
+
+func (x *LabelNamesReport) Reset() {
+	*x = LabelNamesReport{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_querybackend_v1_querybackend_proto_msgTypes[12]
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *SeriesLabelsReport) String() string {
+func (x *LabelNamesReport) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SeriesLabelsReport) ProtoMessage() {}
+func (*LabelNamesReport) ProtoMessage() {}
 
-func (x *SeriesLabelsReport) ProtoReflect() protoreflect.Message {
-	mi := &file_querybackend_v1_querybackend_proto_msgTypes[12]
+func (x *LabelNamesReport) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -886,52 +1637,50 @@ func (x *SeriesLabelsReport) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SeriesLabelsReport.ProtoReflect.Descriptor instead.
-func (*SeriesLabelsReport) Descriptor() ([]byte, []int) {
-	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use LabelNamesReport.ProtoReflect.Descriptor instead.
+func (*LabelNamesReport) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *SeriesLabelsReport) GetQuery() *SeriesLabelsQuery {
+func (x *LabelNamesReport) GetQuery() *LabelNamesQuery {
 	if x != nil {
 		return x.Query
 	}
 	return nil
 }
 
-func (x *SeriesLabelsReport) GetSeriesLabels() []*v11.Labels {
+func (x *LabelNamesReport) GetLabelNames() []string {
 	if x != nil {
-		return x.SeriesLabels
+		return x.LabelNames
 	}
 	return nil
 }
 
-type TimeSeriesQuery struct {
+type LabelValuesQuery struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Step        float64                        `protobuf:"fixed64,1,opt,name=step,proto3" json:"step,omitempty"`
-	GroupBy     []string                       `protobuf:"bytes,2,rep,name=group_by,json=groupBy,proto3" json:"group_by,omitempty"`
-	Aggregation *v11.TimeSeriesAggregationType `protobuf:"varint,3,opt,name=aggregation,proto3,enum=types.v1.TimeSeriesAggregationType,oneof" json:"aggregation,omitempty"`
+	LabelName string `protobuf:"bytes,1,opt,name=label_name,json=labelName,proto3" json:"label_name,omitempty"`
 }
 
-func (x *TimeSeriesQuery) Reset() {
-	*x = TimeSeriesQuery{}
+func (x *LabelValuesQuery) Reset() {
+	*x = LabelValuesQuery{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_querybackend_v1_querybackend_proto_msgTypes[13]
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *TimeSeriesQuery) String() string {
+func (x *LabelValuesQuery) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TimeSeriesQuery) ProtoMessage() {}
+func (*LabelValuesQuery) ProtoMessage() {}
 
-func (x *TimeSeriesQuery) ProtoReflect() protoreflect.Message {
-	mi := &file_querybackend_v1_querybackend_proto_msgTypes[13]
+func (x *LabelValuesQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -942,465 +1691,7684 @@ func (x *TimeSeriesQuery) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TimeSeriesQuery.ProtoReflect.Descriptor instead.
-func (*TimeSeriesQuery) Descriptor() ([]byte, []int) {
-	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{13}
-}
-
-func (x *TimeSeriesQuery) GetStep() float64 {
-	if x != nil {
-		return x.Step
-	}
-	return 0
+// Deprecated: Use LabelValuesQuery.ProtoReflect.Descriptor instead.
+func (*LabelValuesQuery) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *TimeSeriesQuery) GetGroupBy() []string {
+func (x *LabelValuesQuery) GetLabelName() string {
 	if x != nil {
-		return x.GroupBy
-	}
-	return nil
-}
-
-func (x *TimeSeriesQuery) GetAggregation() v11.TimeSeriesAggregationType {
-	if x != nil && x.Aggregation != nil {
-		return *x.Aggregation
+		return x.LabelName
 	}
-	return v11.TimeSeriesAggregationType(0)
+	return ""
 }
 
-type TimeSeriesReport struct {
+type LabelValuesReport struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Query      *TimeSeriesQuery `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
-	TimeSeries []*v11.Series    `protobuf:"bytes,2,rep,name=time_series,json=timeSeries,proto3" json:"time_series,omitempty"`
+	Query       *LabelValuesQuery `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	LabelValues []string          `protobuf:"bytes,2,rep,name=label_values,json=labelValues,proto3" json:"label_values,omitempty"`
 }
 
-func (x *TimeSeriesReport) Reset() {
-	*x = TimeSeriesReport{}
+func (x *LabelValuesReport) Reset() {
+	*x = LabelValuesReport{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_querybackend_v1_querybackend_proto_msgTypes[14]
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *TimeSeriesReport) String() string {
+func (x *LabelValuesReport) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TimeSeriesReport) ProtoMessage() {}
+func (*LabelValuesReport) ProtoMessage() {}
 
-func (x *TimeSeriesReport) ProtoReflect() protoreflect.Message {
-	mi := &file_querybackend_v1_querybackend_proto_msgTypes[14]
-	if protoimpl.UnsafeEnabled && x != nil {
+func (x *LabelValuesReport) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LabelValuesReport.ProtoReflect.Descriptor instead.
+func (*LabelValuesReport) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *LabelValuesReport) GetQuery() *LabelValuesQuery {
+	if x != nil {
+		return x.Query
+	}
+	return nil
+}
+
+func (x *LabelValuesReport) GetLabelValues() []string {
+	if x != nil {
+		return x.LabelValues
+	}
+	return nil
+}
+
+type SeriesLabelsQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	LabelNames []string `protobuf:"bytes,1,rep,name=label_names,json=labelNames,proto3" json:"label_names,omitempty"`
+}
+
+func (x *SeriesLabelsQuery) Reset() {
+	*x = SeriesLabelsQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SeriesLabelsQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SeriesLabelsQuery) ProtoMessage() {}
+
+func (x *SeriesLabelsQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SeriesLabelsQuery.ProtoReflect.Descriptor instead.
+func (*SeriesLabelsQuery) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SeriesLabelsQuery) GetLabelNames() []string {
+	if x != nil {
+		return x.LabelNames
+	}
+	return nil
+}
+
+type SeriesLabelsReport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Query        *SeriesLabelsQuery `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	SeriesLabels []*v11.Labels      `protobuf:"bytes,2,rep,name=series_labels,json=seriesLabels,proto3" json:"series_labels,omitempty"`
+}
+
+func (x *SeriesLabelsReport) Reset() {
+	*x = SeriesLabelsReport{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SeriesLabelsReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SeriesLabelsReport) ProtoMessage() {}
+
+func (x *SeriesLabelsReport) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SeriesLabelsReport.ProtoReflect.Descriptor instead.
+func (*SeriesLabelsReport) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SeriesLabelsReport) GetQuery() *SeriesLabelsQuery {
+	if x != nil {
+		return x.Query
+	}
+	return nil
+}
+
+func (x *SeriesLabelsReport) GetSeriesLabels() []*v11.Labels {
+	if x != nil {
+		return x.SeriesLabels
+	}
+	return nil
+}
+
+type TimeSeriesQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Step        float64                        `protobuf:"fixed64,1,opt,name=step,proto3" json:"step,omitempty"`
+	GroupBy     []string                       `protobuf:"bytes,2,rep,name=group_by,json=groupBy,proto3" json:"group_by,omitempty"`
+	Aggregation *v11.TimeSeriesAggregationType `protobuf:"varint,3,opt,name=aggregation,proto3,enum=types.v1.TimeSeriesAggregationType,oneof" json:"aggregation,omitempty"`
+}
+
+func (x *TimeSeriesQuery) Reset() {
+	*x = TimeSeriesQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TimeSeriesQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TimeSeriesQuery) ProtoMessage() {}
+
+func (x *TimeSeriesQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TimeSeriesQuery.ProtoReflect.Descriptor instead.
+func (*TimeSeriesQuery) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *TimeSeriesQuery) GetStep() float64 {
+	if x != nil {
+		return x.Step
+	}
+	return 0
+}
+
+func (x *TimeSeriesQuery) GetGroupBy() []string {
+	if x != nil {
+		return x.GroupBy
+	}
+	return nil
+}
+
+func (x *TimeSeriesQuery) GetAggregation() v11.TimeSeriesAggregationType {
+	if x != nil && x.Aggregation != nil {
+		return *x.Aggregation
+	}
+	return v11.TimeSeriesAggregationType(0)
+}
+
+type TimeSeriesReport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Query      *TimeSeriesQuery `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	TimeSeries []*v11.Series    `protobuf:"bytes,2,rep,name=time_series,json=timeSeries,proto3" json:"time_series,omitempty"`
+}
+
+func (x *TimeSeriesReport) Reset() {
+	*x = TimeSeriesReport{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TimeSeriesReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TimeSeriesReport) ProtoMessage() {}
+
+func (x *TimeSeriesReport) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
 		}
-		return ms
-	}
-	return mi.MessageOf(x)
-}
-
-// Deprecated: Use TimeSeriesReport.ProtoReflect.Descriptor instead.
-func (*TimeSeriesReport) Descriptor() ([]byte, []int) {
-	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{14}
-}
-
-func (x *TimeSeriesReport) GetQuery() *TimeSeriesQuery {
-	if x != nil {
-		return x.Query
-	}
-	return nil
-}
-
-func (x *TimeSeriesReport) GetTimeSeries() []*v11.Series {
-	if x != nil {
-		return x.TimeSeries
-	}
-	return nil
-}
-
-type TreeQuery struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-
-	MaxNodes int64 `protobuf:"varint,1,opt,name=max_nodes,json=maxNodes,proto3" json:"max_nodes,omitempty"`
-}
-
-func (x *TreeQuery) Reset() {
-	*x = TreeQuery{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_querybackend_v1_querybackend_proto_msgTypes[15]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
-}
-
-func (x *TreeQuery) String() string {
-	return protoimpl.X.MessageStringOf(x)
-}
-
-func (*TreeQuery) ProtoMessage() {}
-
-func (x *TreeQuery) ProtoReflect() protoreflect.Message {
-	mi := &file_querybackend_v1_querybackend_proto_msgTypes[15]
-	if protoimpl.UnsafeEnabled && x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TimeSeriesReport.ProtoReflect.Descriptor instead.
+func (*TimeSeriesReport) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *TimeSeriesReport) GetQuery() *TimeSeriesQuery {
+	if x != nil {
+		return x.Query
+	}
+	return nil
+}
+
+func (x *TimeSeriesReport) GetTimeSeries() []*v11.Series {
+	if x != nil {
+		return x.TimeSeries
+	}
+	return nil
+}
+
+type TreeQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MaxNodes int64 `protobuf:"varint,1,opt,name=max_nodes,json=maxNodes,proto3" json:"max_nodes,omitempty"`
+	// Name of the server-side registered function applied
+	// to every frame name before it is inserted into the tree.
+	// Frames that are sanitized to the same name are merged.
+	NameSanitizer string `protobuf:"bytes,2,opt,name=name_sanitizer,json=nameSanitizer,proto3" json:"name_sanitizer,omitempty"`
+	// If set, the report includes the list of sources (blocks)
+	// that contributed to each of the tree nodes. Note that this
+	// substantially increases the memory footprint of the query.
+	Attribution bool `protobuf:"varint,3,opt,name=attribution,proto3" json:"attribution,omitempty"`
+	// Relabeling rules applied to the series labels of the
+	// matching profiles. Profiles of the series dropped by
+	// the rules are not included into the tree.
+	Relabel []*RelabelRule `protobuf:"bytes,4,rep,name=relabel,proto3" json:"relabel,omitempty"`
+	// If set, a tree is built for each of the profile types,
+	// and the report includes a multi-value tree, where each
+	// node carries the values of all the profile types, in the
+	// order specified. The profile types that are not present
+	// in the data have zero values.
+	ProfileTypes []string `protobuf:"bytes,5,rep,name=profile_types,json=profileTypes,proto3" json:"profile_types,omitempty"`
+	// The latest tree serialization format version the client
+	// supports. The server uses the latest version supported by
+	// both sides. If not set, version 1 is used.
+	FormatVersion uint32 `protobuf:"varint,6,opt,name=format_version,json=formatVersion,proto3" json:"format_version,omitempty"`
+	// If set, the tree is built leaf-first: the roots are
+	// the functions the samples were collected in, and the
+	// children of a node are its callers.
+	Inverted bool `protobuf:"varint,7,opt,name=inverted,proto3" json:"inverted,omitempty"`
+	// If set, only the nodes that are likely to be among the top
+	// max_nodes nodes are retained while the tree is being built,
+	// which reduces the cost of queries over large profiles at the
+	// expense of accuracy: some of the top nodes may be missing.
+	// Has no effect if max_nodes is not set.
+	Approximate bool `protobuf:"varint,8,opt,name=approximate,proto3" json:"approximate,omitempty"`
+	// The error bound of the approximation, relative to the tree
+	// total: the node values are overestimated by no more than
+	// approximation_error * total, with high probability. Must be
+	// in the range (0, 1), defaults to 0.001.
+	ApproximationError float64 `protobuf:"fixed64,9,opt,name=approximation_error,json=approximationError,proto3" json:"approximation_error,omitempty"`
+	// If set, the nodes with total values outside of the range
+	// [min_value, max_value] are removed from the tree, unless
+	// they have descendants within the range. The values of the
+	// removed nodes are accounted in the "other" node of the
+	// parent. Zero max_value means no upper bound. The filter
+	// is applied before the tree is truncated to max_nodes, and
+	// does not apply to multi-value trees. The bounds are only
+	// applied to the merged tree, as a node out of the range in a
+	// partial tree may be within it once the partials are merged:
+	// they do not reduce the size of the partial trees exchanged by
+	// the query backends, which is only bounded by max_nodes.
+	MinValue int64 `protobuf:"varint,10,opt,name=min_value,json=minValue,proto3" json:"min_value,omitempty"`
+	MaxValue int64 `protobuf:"varint,11,opt,name=max_value,json=maxValue,proto3" json:"max_value,omitempty"`
+	// If set, the nodes below the given depth are removed from
+	// the tree, and their values are accounted in the self values
+	// of the ancestors at the maximum depth. The roots are at
+	// depth 1. The limit is applied before any other filter.
+	MaxDepth int64 `protobuf:"varint,13,opt,name=max_depth,json=maxDepth,proto3" json:"max_depth,omitempty"`
+	// If set, the nodes with zero self values and no descendants
+	// with non-zero values are removed from the aggregated tree.
+	DropZeroNodes bool `protobuf:"varint,14,opt,name=drop_zero_nodes,json=dropZeroNodes,proto3" json:"drop_zero_nodes,omitempty"`
+	// If positive, only the given number of functions with the highest
+	// total values are retained in the aggregated tree; the frames of
+	// the remaining functions are folded into the "other" nodes. The
+	// tree total does not change. The limit is applied before max_nodes.
+	MaxFunctions int64 `protobuf:"varint,15,opt,name=max_functions,json=maxFunctions,proto3" json:"max_functions,omitempty"`
+	// Arithmetic expression over the sample values of the profile
+	// types, referred to by the sample type, e.g. "alloc_space /
+	// alloc_objects". Supported are +, -, *, / operators, parentheses,
+	// and numeric constants. The expression is evaluated per stack
+	// trace of the aggregated sample values, and the results, rounded
+	// to integers, are the self values of the tree nodes: the operands
+	// are merged before the expression is evaluated, as the values of
+	// the expression are not additive. Stack traces the expression is
+	// undefined for, e.g. because of division by zero, are not
+	// included. Can't be used along with profile_types.
+	ValueExpression string `protobuf:"bytes,16,opt,name=value_expression,json=valueExpression,proto3" json:"value_expression,omitempty"`
+	// If set, only the top-level calls are included into the tree:
+	// stack traces are truncated below the first call out of the
+	// entry point, and the values of the deeper frames are accounted
+	// in the self value of the call. The tree total does not change.
+	// Stack traces are truncated as they are resolved, which is much
+	// cheaper than building the full tree and limiting its depth.
+	Shallow bool `protobuf:"varint,17,opt,name=shallow,proto3" json:"shallow,omitempty"`
+	// Regular expression (RE2, unanchored) matching the name of the
+	// entry point function in shallow mode, e.g. "^main\\.main$". The
+	// entry point is the first frame of the stack trace that matches;
+	// if none of the frames match, the entry point is the root.
+	ShallowEntryPoint string `protobuf:"bytes,18,opt,name=shallow_entry_point,json=shallowEntryPoint,proto3" json:"shallow_entry_point,omitempty"`
+	// If set, the tree of a single representative profile is returned
+	// instead of the sum of all the matching profiles: the profile with
+	// the median total value. The median is chosen per block, and then
+	// among the blocks, therefore it is approximate. Can't be used along
+	// with profile_types, value_expression, and attribution.
+	Representative bool `protobuf:"varint,19,opt,name=representative,proto3" json:"representative,omitempty"`
+	// Seed of the hash functions used in approximate tree building: the
+	// result of an approximate query only depends on the data and the
+	// seed. If not set, a fixed seed is used.
+	Seed uint64 `protobuf:"varint,20,opt,name=seed,proto3" json:"seed,omitempty"`
+	// If not empty, only the profiles of the given stack
+	// trace partitions are included into the tree.
+	Partitions []uint64 `protobuf:"varint,21,rep,packed,name=partitions,proto3" json:"partitions,omitempty"`
+	// If set, the report includes a multi-value tree with two values
+	// per node: the value, and the sample count – the value of the
+	// "samples" sample type of the same profiles. Profiles that lack
+	// the sample counts have zero counts, which is indicated in the
+	// report. Can't be used along with profile_types, value_expression,
+	// and representative.
+	SampleCounts bool `protobuf:"varint,22,opt,name=sample_counts,json=sampleCounts,proto3" json:"sample_counts,omitempty"`
+	// If set, the report includes stable IDs of the tree nodes.
+	NodeIds bool `protobuf:"varint,23,opt,name=node_ids,json=nodeIds,proto3" json:"node_ids,omitempty"`
+	// If set, the report tree holds the differences between the
+	// resulting tree and the baseline, instead of the resulting tree.
+	// The baseline only applies to the tree of the final report: the
+	// multi-value tree and the attribution are not affected.
+	Baseline *TreeBaseline `protobuf:"bytes,24,opt,name=baseline,proto3" json:"baseline,omitempty"`
+	// If set, a profile stored in more than one of the blocks read,
+	// e.g. in both the source and the compacted block, is accounted
+	// once. Profiles are identified by the ID and the series. Only
+	// the duplicates read by the same query backend request are
+	// detected. Can't be used along with profile_types and
+	// representative.
+	DeduplicateProfiles bool `protobuf:"varint,25,opt,name=deduplicate_profiles,json=deduplicateProfiles,proto3" json:"deduplicate_profiles,omitempty"`
+	// Name of the function that combines the values of the same stack
+	// trace, e.g. "max": the values of a profile are added up per path,
+	// as in the tree, the values of the profiles are combined within a
+	// block, and the node self values are combined across the partial
+	// trees. Node totals are the sums of the self value and the totals
+	// of the children. Built-in functions are "sum" (default), "max",
+	// and "min"; custom ones are registered with the query backend.
+	// Can't be used along with profile_types, value_expression,
+	// representative, and sample_counts.
+	ValueMerge string `protobuf:"bytes,26,opt,name=value_merge,json=valueMerge,proto3" json:"value_merge,omitempty"`
+	// If set, the report includes the source locations of the tree
+	// nodes: the file and the start line of the function. This notably
+	// increases the report size. Can't be used along with profile_types.
+	IncludeSourceLocations bool `protobuf:"varint,27,opt,name=include_source_locations,json=includeSourceLocations,proto3" json:"include_source_locations,omitempty"`
+	// If set, the profiles which total value is below the threshold
+	// are not included into the tree. Can't be used along with
+	// profile_types, value_expression, representative, and
+	// sample_counts.
+	// The threshold is absolute: a fraction of the largest profile
+	// can't be applied consistently, as the datasets are queried
+	// independently.
+	MinProfileValue int64 `protobuf:"varint,28,opt,name=min_profile_value,json=minProfileValue,proto3" json:"min_profile_value,omitempty"`
+	// If set, the report includes the contributions of the values of
+	// the label to the tree nodes, and the dominant value of each node:
+	// the one that contributes the most to the node total. Note that a
+	// tree is built for each of the label values, which substantially
+	// increases the cost of the query. Can't be used along with
+	// profile_types, value_expression, representative, sample_counts,
+	// and value_merge.
+	DominantLabel string `protobuf:"bytes,30,opt,name=dominant_label,json=dominantLabel,proto3" json:"dominant_label,omitempty"`
+	// If set, the tree values are the values of two sample types combined
+	// with the operation, e.g. inuse_space subtracted from alloc_space.
+	// The sample types must have the same unit, if they are added or
+	// subtracted. The combination is evaluated like value_expression,
+	// and can't be used along with it, or with profile_types.
+	ValueCombination *TreeValueCombination `protobuf:"bytes,31,opt,name=value_combination,json=valueCombination,proto3" json:"value_combination,omitempty"`
+	// If set, the report includes the coverage of the tree nodes: the
+	// fraction of the datasets planned for the query that contributed
+	// to the node. The coverage of the nodes is lower, if some of the
+	// blocks are skipped, e.g. because of the deadline, and the node
+	// values are likely to be underestimated.
+	Coverage bool `protobuf:"varint,32,opt,name=coverage,proto3" json:"coverage,omitempty"`
+	// If set, the profiles are grouped by the values of the label, e.g.
+	// thread_name, and the tree of each group is grafted under a synthetic
+	// root node named "<label>=<value>", so that the tree has a subtree per
+	// label value. The profiles without the label are grouped under
+	// "<label>=". The synthetic nodes count towards max_nodes and max_depth
+	// is applied to the group trees. Can't be used along with profile_types,
+	// value_expression, representative, sample_counts, and dominant_label.
+	GroupRootLabel string `protobuf:"bytes,33,opt,name=group_root_label,json=groupRootLabel,proto3" json:"group_root_label,omitempty"`
+	// If set, the profiles are also split into buckets by the numeric value
+	// of the label, e.g. the request size, and the report includes the tree
+	// of each bucket. Can't be used along with profile_types,
+	// value_expression, representative, sample_counts, value_merge,
+	// dominant_label, and group_root_label.
+	LabelBuckets *TreeLabelBuckets `protobuf:"bytes,34,opt,name=label_buckets,json=labelBuckets,proto3" json:"label_buckets,omitempty"`
+	// If set, only the hottest path of the tree is returned: the root with
+	// the highest value, and, at each level, its child with the highest
+	// value. The node totals are retained. The path is extracted after the
+	// partial trees are merged. Can't be used along with profile_types.
+	HottestPath bool `protobuf:"varint,35,opt,name=hottest_path,json=hottestPath,proto3" json:"hottest_path,omitempty"`
+	// Name of the function that maps every frame to its group, e.g.
+	// "database" or "serialization", as defined in the stack groupings of
+	// the query backend configuration, or registered server-side: the tree
+	// is built of the groups instead of the frames, and consecutive frames
+	// of the same group are collapsed into a single node. The function is
+	// applied after name_sanitizer. Can't be used along with profile_types.
+	StackGrouping string `protobuf:"bytes,36,opt,name=stack_grouping,json=stackGrouping,proto3" json:"stack_grouping,omitempty"`
+	// The reports of the same sample type, e.g. cpu, are expected to have
+	// the same unit: the query fails otherwise, since the values are not
+	// comparable. This may be the case during schema migrations. If set,
+	// the reports are merged regardless.
+	ForceSampleTypeMerge bool `protobuf:"varint,37,opt,name=force_sample_type_merge,json=forceSampleTypeMerge,proto3" json:"force_sample_type_merge,omitempty"`
+	// If set, only the subtree of the node with the ID, see node_ids, is
+	// returned, along with the path from the root to the node: the other
+	// nodes are removed. The ancestors of the node have no self values.
+	// The max_depth is then counted from the node, which is at depth 1.
+	// This allows for expanding large trees on demand: the first query
+	// returns the top levels of the tree, and the follow-up queries the
+	// subtrees of the nodes expanded. The tree is empty, if there is no
+	// such node. Can't be used along with profile_types, representative,
+	// dominant_label, group_root_label, and label_buckets.
+	SubtreeNodeId uint64 `protobuf:"varint,38,opt,name=subtree_node_id,json=subtreeNodeId,proto3" json:"subtree_node_id,omitempty"`
+	// If set, the report of a goroutine profile tree includes the
+	// contributions of the goroutine states, e.g. "chan receive" or
+	// "IO wait", to the tree nodes. The state of a goroutine is inferred
+	// from the runtime functions of its stack trace: the goroutines not
+	// blocked in any of the known functions are reported as "running".
+	// The option has no effect on the other profile types. Can't be used
+	// along with profile_types.
+	GoroutineStates bool `protobuf:"varint,39,opt,name=goroutine_states,json=goroutineStates,proto3" json:"goroutine_states,omitempty"`
+	// If set, the report includes the interval values of the tree nodes:
+	// the node totals per second of the query time range. The values are
+	// only derived for the final report. Can't be used along with
+	// profile_types and representative.
+	IntervalValues bool `protobuf:"varint,40,opt,name=interval_values,json=intervalValues,proto3" json:"interval_values,omitempty"`
+	// Names of the server-side registered functions applied, in order, to
+	// every frame name after name_sanitizer: the frames of the same logical
+	// function that differ across builds, e.g. in casing, mangling, or the
+	// version suffix, are merged. The built-in normalizers are "lowercase",
+	// "demangle", and "strip_version". The partial trees and the baseline
+	// are normalized as well before they are merged.
+	NameNormalizers []string `protobuf:"bytes,41,rep,name=name_normalizers,json=nameNormalizers,proto3" json:"name_normalizers,omitempty"`
+	// If set, the report includes the legend of the tree: the packages
+	// of the frames with the highest values, and their color indices.
+	// The legend is only computed for the final report, after the tree
+	// is truncated. Can't be used along with profile_types.
+	Legend *TreeLegendQuery `protobuf:"bytes,42,opt,name=legend,proto3" json:"legend,omitempty"`
+	// Lower bound of the node total values, in percent of the tree total.
+	// The bound is computed against the total of the merged tree, and the
+	// higher one of min_value and min_value_percent applies. As min_value,
+	// it is only applied to the merged tree, and does not reduce the size
+	// of the partial trees. The total of the tree is kept, even if the
+	// bound exceeds it. Must be in [0, 100].
+	MinValuePercent float64 `protobuf:"fixed64,43,opt,name=min_value_percent,json=minValuePercent,proto3" json:"min_value_percent,omitempty"`
+}
+
+func (x *TreeQuery) Reset() {
+	*x = TreeQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TreeQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeQuery) ProtoMessage() {}
+
+func (x *TreeQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeQuery.ProtoReflect.Descriptor instead.
+func (*TreeQuery) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *TreeQuery) GetMaxNodes() int64 {
+	if x != nil {
+		return x.MaxNodes
+	}
+	return 0
+}
+
+func (x *TreeQuery) GetNameSanitizer() string {
+	if x != nil {
+		return x.NameSanitizer
+	}
+	return ""
+}
+
+func (x *TreeQuery) GetAttribution() bool {
+	if x != nil {
+		return x.Attribution
+	}
+	return false
+}
+
+func (x *TreeQuery) GetRelabel() []*RelabelRule {
+	if x != nil {
+		return x.Relabel
+	}
+	return nil
+}
+
+func (x *TreeQuery) GetProfileTypes() []string {
+	if x != nil {
+		return x.ProfileTypes
+	}
+	return nil
+}
+
+func (x *TreeQuery) GetFormatVersion() uint32 {
+	if x != nil {
+		return x.FormatVersion
+	}
+	return 0
+}
+
+func (x *TreeQuery) GetInverted() bool {
+	if x != nil {
+		return x.Inverted
+	}
+	return false
+}
+
+func (x *TreeQuery) GetApproximate() bool {
+	if x != nil {
+		return x.Approximate
+	}
+	return false
+}
+
+func (x *TreeQuery) GetApproximationError() float64 {
+	if x != nil {
+		return x.ApproximationError
+	}
+	return 0
+}
+
+func (x *TreeQuery) GetMinValue() int64 {
+	if x != nil {
+		return x.MinValue
+	}
+	return 0
+}
+
+func (x *TreeQuery) GetMaxValue() int64 {
+	if x != nil {
+		return x.MaxValue
+	}
+	return 0
+}
+
+func (x *TreeQuery) GetMaxDepth() int64 {
+	if x != nil {
+		return x.MaxDepth
+	}
+	return 0
+}
+
+func (x *TreeQuery) GetDropZeroNodes() bool {
+	if x != nil {
+		return x.DropZeroNodes
+	}
+	return false
+}
+
+func (x *TreeQuery) GetMaxFunctions() int64 {
+	if x != nil {
+		return x.MaxFunctions
+	}
+	return 0
+}
+
+func (x *TreeQuery) GetValueExpression() string {
+	if x != nil {
+		return x.ValueExpression
+	}
+	return ""
+}
+
+func (x *TreeQuery) GetShallow() bool {
+	if x != nil {
+		return x.Shallow
+	}
+	return false
+}
+
+func (x *TreeQuery) GetShallowEntryPoint() string {
+	if x != nil {
+		return x.ShallowEntryPoint
+	}
+	return ""
+}
+
+func (x *TreeQuery) GetRepresentative() bool {
+	if x != nil {
+		return x.Representative
+	}
+	return false
+}
+
+func (x *TreeQuery) GetSeed() uint64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+func (x *TreeQuery) GetPartitions() []uint64 {
+	if x != nil {
+		return x.Partitions
+	}
+	return nil
+}
+
+func (x *TreeQuery) GetSampleCounts() bool {
+	if x != nil {
+		return x.SampleCounts
+	}
+	return false
+}
+
+func (x *TreeQuery) GetNodeIds() bool {
+	if x != nil {
+		return x.NodeIds
+	}
+	return false
+}
+
+func (x *TreeQuery) GetBaseline() *TreeBaseline {
+	if x != nil {
+		return x.Baseline
+	}
+	return nil
+}
+
+func (x *TreeQuery) GetDeduplicateProfiles() bool {
+	if x != nil {
+		return x.DeduplicateProfiles
+	}
+	return false
+}
+
+func (x *TreeQuery) GetValueMerge() string {
+	if x != nil {
+		return x.ValueMerge
+	}
+	return ""
+}
+
+func (x *TreeQuery) GetIncludeSourceLocations() bool {
+	if x != nil {
+		return x.IncludeSourceLocations
+	}
+	return false
+}
+
+func (x *TreeQuery) GetMinProfileValue() int64 {
+	if x != nil {
+		return x.MinProfileValue
+	}
+	return 0
+}
+
+func (x *TreeQuery) GetDominantLabel() string {
+	if x != nil {
+		return x.DominantLabel
+	}
+	return ""
+}
+
+func (x *TreeQuery) GetValueCombination() *TreeValueCombination {
+	if x != nil {
+		return x.ValueCombination
+	}
+	return nil
+}
+
+func (x *TreeQuery) GetCoverage() bool {
+	if x != nil {
+		return x.Coverage
+	}
+	return false
+}
+
+func (x *TreeQuery) GetGroupRootLabel() string {
+	if x != nil {
+		return x.GroupRootLabel
+	}
+	return ""
+}
+
+func (x *TreeQuery) GetLabelBuckets() *TreeLabelBuckets {
+	if x != nil {
+		return x.LabelBuckets
+	}
+	return nil
+}
+
+func (x *TreeQuery) GetHottestPath() bool {
+	if x != nil {
+		return x.HottestPath
+	}
+	return false
+}
+
+func (x *TreeQuery) GetStackGrouping() string {
+	if x != nil {
+		return x.StackGrouping
+	}
+	return ""
+}
+
+func (x *TreeQuery) GetForceSampleTypeMerge() bool {
+	if x != nil {
+		return x.ForceSampleTypeMerge
+	}
+	return false
+}
+
+func (x *TreeQuery) GetSubtreeNodeId() uint64 {
+	if x != nil {
+		return x.SubtreeNodeId
+	}
+	return 0
+}
+
+func (x *TreeQuery) GetGoroutineStates() bool {
+	if x != nil {
+		return x.GoroutineStates
+	}
+	return false
+}
+
+func (x *TreeQuery) GetIntervalValues() bool {
+	if x != nil {
+		return x.IntervalValues
+	}
+	return false
+}
+
+func (x *TreeQuery) GetNameNormalizers() []string {
+	if x != nil {
+		return x.NameNormalizers
+	}
+	return nil
+}
+
+func (x *TreeQuery) GetLegend() *TreeLegendQuery {
+	if x != nil {
+		return x.Legend
+	}
+	return nil
+}
+
+func (x *TreeQuery) GetMinValuePercent() float64 {
+	if x != nil {
+		return x.MinValuePercent
+	}
+	return 0
+}
+
+type TreeLegendQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Maximum number of the packages in the legend.
+	// Zero means no limit.
+	Limit int64 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Number of the colors of the palette. Must be positive.
+	Colors uint32 `protobuf:"varint,2,opt,name=colors,proto3" json:"colors,omitempty"`
+}
+
+func (x *TreeLegendQuery) Reset() {
+	*x = TreeLegendQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TreeLegendQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeLegendQuery) ProtoMessage() {}
+
+func (x *TreeLegendQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeLegendQuery.ProtoReflect.Descriptor instead.
+func (*TreeLegendQuery) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *TreeLegendQuery) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *TreeLegendQuery) GetColors() uint32 {
+	if x != nil {
+		return x.Colors
+	}
+	return 0
+}
+
+type TreeLabelBuckets struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Label string `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
+	// Inclusive upper bounds of the buckets, in ascending order. The values
+	// above the last bound fall into the overflow bucket.
+	Bounds []float64 `protobuf:"fixed64,2,rep,packed,name=bounds,proto3" json:"bounds,omitempty"`
+}
+
+func (x *TreeLabelBuckets) Reset() {
+	*x = TreeLabelBuckets{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TreeLabelBuckets) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeLabelBuckets) ProtoMessage() {}
+
+func (x *TreeLabelBuckets) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeLabelBuckets.ProtoReflect.Descriptor instead.
+func (*TreeLabelBuckets) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *TreeLabelBuckets) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *TreeLabelBuckets) GetBounds() []float64 {
+	if x != nil {
+		return x.Bounds
+	}
+	return nil
+}
+
+type TreeBucket struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Tree []byte `protobuf:"bytes,1,opt,name=tree,proto3" json:"tree,omitempty"`
+	// Set if the tree of the bucket is truncated
+	// to the maximum number of nodes of the query.
+	Truncated bool `protobuf:"varint,2,opt,name=truncated,proto3" json:"truncated,omitempty"`
+}
+
+func (x *TreeBucket) Reset() {
+	*x = TreeBucket{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TreeBucket) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeBucket) ProtoMessage() {}
+
+func (x *TreeBucket) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeBucket.ProtoReflect.Descriptor instead.
+func (*TreeBucket) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *TreeBucket) GetTree() []byte {
+	if x != nil {
+		return x.Tree
+	}
+	return nil
+}
+
+func (x *TreeBucket) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+type TreeValueCombination struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Sample types of the operands, e.g. "alloc_space".
+	Lhs       string             `protobuf:"bytes,1,opt,name=lhs,proto3" json:"lhs,omitempty"`
+	Rhs       string             `protobuf:"bytes,2,opt,name=rhs,proto3" json:"rhs,omitempty"`
+	Operation TreeValueOperation `protobuf:"varint,3,opt,name=operation,proto3,enum=querybackend.v1.TreeValueOperation" json:"operation,omitempty"`
+}
+
+func (x *TreeValueCombination) Reset() {
+	*x = TreeValueCombination{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TreeValueCombination) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeValueCombination) ProtoMessage() {}
+
+func (x *TreeValueCombination) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeValueCombination.ProtoReflect.Descriptor instead.
+func (*TreeValueCombination) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *TreeValueCombination) GetLhs() string {
+	if x != nil {
+		return x.Lhs
+	}
+	return ""
+}
+
+func (x *TreeValueCombination) GetRhs() string {
+	if x != nil {
+		return x.Rhs
+	}
+	return ""
+}
+
+func (x *TreeValueCombination) GetOperation() TreeValueOperation {
+	if x != nil {
+		return x.Operation
+	}
+	return TreeValueOperation_TREE_VALUE_OPERATION_UNSPECIFIED
+}
+
+type TreeBaseline struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Serialized baseline tree.
+	Tree []byte `protobuf:"bytes,1,opt,name=tree,proto3" json:"tree,omitempty"`
+	// Serialization format version of the baseline tree.
+	// Defaults to the version of the report tree.
+	FormatVersion uint32 `protobuf:"varint,2,opt,name=format_version,json=formatVersion,proto3" json:"format_version,omitempty"`
+	// Nodes which total value changed by less than min_delta,
+	// or less than min_delta_percent percent of the baseline
+	// node value, are omitted: the differences are accounted
+	// in the parent node.
+	MinDelta        int64   `protobuf:"varint,3,opt,name=min_delta,json=minDelta,proto3" json:"min_delta,omitempty"`
+	MinDeltaPercent float64 `protobuf:"fixed64,4,opt,name=min_delta_percent,json=minDeltaPercent,proto3" json:"min_delta_percent,omitempty"`
+	// If set, the report tree holds the cold paths of the baseline
+	// instead of the differences: the nodes absent in the resulting
+	// tree, or which total value is at most max_cold_ratio of the
+	// baseline node total, and their ancestors. The value of a node is
+	// the value of the baseline missing in the resulting tree. Nodes
+	// which value decreased by less than min_delta are not cold.
+	//
+	// The resulting tree is merged entirely before the comparison: the
+	// partial trees of the sub-queries are not truncated, regardless
+	// of max_nodes, which only applies to the tree of the report.
+	// The baseline is expected to be produced by the same query.
+	ColdPaths    bool    `protobuf:"varint,5,opt,name=cold_paths,json=coldPaths,proto3" json:"cold_paths,omitempty"`
+	MaxColdRatio float64 `protobuf:"fixed64,6,opt,name=max_cold_ratio,json=maxColdRatio,proto3" json:"max_cold_ratio,omitempty"`
+}
+
+func (x *TreeBaseline) Reset() {
+	*x = TreeBaseline{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TreeBaseline) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeBaseline) ProtoMessage() {}
+
+func (x *TreeBaseline) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeBaseline.ProtoReflect.Descriptor instead.
+func (*TreeBaseline) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *TreeBaseline) GetTree() []byte {
+	if x != nil {
+		return x.Tree
+	}
+	return nil
+}
+
+func (x *TreeBaseline) GetFormatVersion() uint32 {
+	if x != nil {
+		return x.FormatVersion
+	}
+	return 0
+}
+
+func (x *TreeBaseline) GetMinDelta() int64 {
+	if x != nil {
+		return x.MinDelta
+	}
+	return 0
+}
+
+func (x *TreeBaseline) GetMinDeltaPercent() float64 {
+	if x != nil {
+		return x.MinDeltaPercent
+	}
+	return 0
+}
+
+func (x *TreeBaseline) GetColdPaths() bool {
+	if x != nil {
+		return x.ColdPaths
+	}
+	return false
+}
+
+func (x *TreeBaseline) GetMaxColdRatio() float64 {
+	if x != nil {
+		return x.MaxColdRatio
+	}
+	return 0
+}
+
+// Relabeling rule, as in Prometheus relabel_config.
+// Fields that are not set default to the Prometheus
+// defaults, e.g. the "replace" action.
+type RelabelRule struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SourceLabels []string `protobuf:"bytes,1,rep,name=source_labels,json=sourceLabels,proto3" json:"source_labels,omitempty"`
+	Separator    string   `protobuf:"bytes,2,opt,name=separator,proto3" json:"separator,omitempty"`
+	Regex        string   `protobuf:"bytes,3,opt,name=regex,proto3" json:"regex,omitempty"`
+	TargetLabel  string   `protobuf:"bytes,4,opt,name=target_label,json=targetLabel,proto3" json:"target_label,omitempty"`
+	Replacement  string   `protobuf:"bytes,5,opt,name=replacement,proto3" json:"replacement,omitempty"`
+	Action       string   `protobuf:"bytes,6,opt,name=action,proto3" json:"action,omitempty"`
+}
+
+func (x *RelabelRule) Reset() {
+	*x = RelabelRule{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RelabelRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RelabelRule) ProtoMessage() {}
+
+func (x *RelabelRule) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RelabelRule.ProtoReflect.Descriptor instead.
+func (*RelabelRule) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *RelabelRule) GetSourceLabels() []string {
+	if x != nil {
+		return x.SourceLabels
+	}
+	return nil
+}
+
+func (x *RelabelRule) GetSeparator() string {
+	if x != nil {
+		return x.Separator
+	}
+	return ""
+}
+
+func (x *RelabelRule) GetRegex() string {
+	if x != nil {
+		return x.Regex
+	}
+	return ""
+}
+
+func (x *RelabelRule) GetTargetLabel() string {
+	if x != nil {
+		return x.TargetLabel
+	}
+	return ""
+}
+
+func (x *RelabelRule) GetReplacement() string {
+	if x != nil {
+		return x.Replacement
+	}
+	return ""
+}
+
+func (x *RelabelRule) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+type TreeReport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Query *TreeQuery `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Tree  []byte     `protobuf:"bytes,2,opt,name=tree,proto3" json:"tree,omitempty"`
+	// Indicates that some of the profiles have not been
+	// symbolized: their frames are named after addresses.
+	Unsymbolized   bool             `protobuf:"varint,3,opt,name=unsymbolized,proto3" json:"unsymbolized,omitempty"`
+	Attribution    *TreeAttribution `protobuf:"bytes,4,opt,name=attribution,proto3" json:"attribution,omitempty"`
+	MultiValueTree *MultiValueTree  `protobuf:"bytes,5,opt,name=multi_value_tree,json=multiValueTree,proto3" json:"multi_value_tree,omitempty"`
+	// Serialization format version of the tree.
+	// If not set, version 1 is assumed.
+	FormatVersion uint32 `protobuf:"varint,6,opt,name=format_version,json=formatVersion,proto3" json:"format_version,omitempty"`
+	// Number of nodes of the tree before it was truncated to
+	// max_nodes. Not set for multi-value trees.
+	Nodes int64 `protobuf:"varint,7,opt,name=nodes,proto3" json:"nodes,omitempty"`
+	// Indicates that the number of nodes exceeded max_nodes, and
+	// the tree might have been truncated. If not set, the tree is
+	// guaranteed to be complete.
+	Truncated bool `protobuf:"varint,8,opt,name=truncated,proto3" json:"truncated,omitempty"`
+	// Indicates that sample counts were requested, but some of
+	// the profiles lack them: their counts are zero.
+	SampleCountsMissing bool `protobuf:"varint,9,opt,name=sample_counts_missing,json=sampleCountsMissing,proto3" json:"sample_counts_missing,omitempty"`
+	// IDs of the tree nodes in the order of serialization, including
+	// the virtual root. The ID is derived from the path of the node,
+	// and does not depend on the order of the siblings or truncation:
+	// the same node has the same ID in the results of different queries.
+	NodeIds []uint64 `protobuf:"varint,10,rep,packed,name=node_ids,json=nodeIds,proto3" json:"node_ids,omitempty"`
+	// Set if the symbols of some of the blocks exceed the size limit of
+	// the query backend: the stack traces of those blocks are resolved into
+	// the location addresses, as if the blocks had not been symbolized.
+	SymbolsOverLimit bool `protobuf:"varint,11,opt,name=symbols_over_limit,json=symbolsOverLimit,proto3" json:"symbols_over_limit,omitempty"`
+	// Source locations of the tree nodes, if requested.
+	SourceLocations *TreeSourceLocations `protobuf:"bytes,12,opt,name=source_locations,json=sourceLocations,proto3" json:"source_locations,omitempty"`
+	// Indicates that the tree has been truncated to fit the maximum
+	// report size: it has fewer nodes than max_nodes allows.
+	SizeCapped bool `protobuf:"varint,13,opt,name=size_capped,json=sizeCapped,proto3" json:"size_capped,omitempty"`
+	// Set if some of the profiles have been skipped, as their
+	// total value is below the min_profile_value threshold.
+	ProfilesBelowMinValue bool `protobuf:"varint,14,opt,name=profiles_below_min_value,json=profilesBelowMinValue,proto3" json:"profiles_below_min_value,omitempty"`
+	// Contributions of the dominant_label values to the tree nodes.
+	LabelValues *TreeLabelValues `protobuf:"bytes,15,opt,name=label_values,json=labelValues,proto3" json:"label_values,omitempty"`
+	// Coverage of the tree nodes, if requested.
+	Coverage *TreeCoverage `protobuf:"bytes,16,opt,name=coverage,proto3" json:"coverage,omitempty"`
+	// Trees of the label buckets, one per bound, followed by the overflow
+	// bucket. The profiles lacking the label, or having a non-numeric value,
+	// are only included into the tree of the report. The trees are built
+	// the same way as the tree of the report, except that the baseline,
+	// min_value, min_value_percent, max_value, max_functions, and
+	// drop_zero_nodes do not apply.
+	Buckets []*TreeBucket `protobuf:"bytes,17,rep,name=buckets,proto3" json:"buckets,omitempty"`
+	// The highest format version of the symbols the tree is resolved
+	// from. Zero, if the symbols have not been read.
+	SymbolsFormatVersion uint32 `protobuf:"varint,18,opt,name=symbols_format_version,json=symbolsFormatVersion,proto3" json:"symbols_format_version,omitempty"`
+	// Set if the report is the last known result of the query served from
+	// the cache of the query frontend, while the fresh result is computed in
+	// the background. The report may not cover the time range requested.
+	Stale bool `protobuf:"varint,19,opt,name=stale,proto3" json:"stale,omitempty"`
+	// Sample types of the profiles the tree is built of, ordered by
+	// type and unit. Not reported for multi-value trees.
+	SampleTypes []*TreeSampleType `protobuf:"bytes,20,rep,name=sample_types,json=sampleTypes,proto3" json:"sample_types,omitempty"`
+	// Contributions of the goroutine states to the tree nodes, if
+	// requested and the tree is built of goroutine profiles: the
+	// label values are the states.
+	GoroutineStates *TreeLabelValues `protobuf:"bytes,21,opt,name=goroutine_states,json=goroutineStates,proto3" json:"goroutine_states,omitempty"`
+	// Interval values of the tree nodes, if requested: the totals divided
+	// by the duration of the query time range, in seconds. The values
+	// follow the order of serialization, including the virtual root, as
+	// the node_ids do. Not included in the partial reports.
+	IntervalValues []float64 `protobuf:"fixed64,22,rep,packed,name=interval_values,json=intervalValues,proto3" json:"interval_values,omitempty"`
+	// Legend of the tree, if requested.
+	Legend *TreeLegend `protobuf:"bytes,23,opt,name=legend,proto3" json:"legend,omitempty"`
+	// If set, the report only carries a part of the tree of the report
+	// preceding it in the InvokeStream response: the rest of the fields
+	// are not set, besides the query and the format version. The parts
+	// give the tree, once merged.
+	Continuation bool `protobuf:"varint,24,opt,name=continuation,proto3" json:"continuation,omitempty"`
+}
+
+func (x *TreeReport) Reset() {
+	*x = TreeReport{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TreeReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeReport) ProtoMessage() {}
+
+func (x *TreeReport) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeReport.ProtoReflect.Descriptor instead.
+func (*TreeReport) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *TreeReport) GetQuery() *TreeQuery {
+	if x != nil {
+		return x.Query
+	}
+	return nil
+}
+
+func (x *TreeReport) GetTree() []byte {
+	if x != nil {
+		return x.Tree
+	}
+	return nil
+}
+
+func (x *TreeReport) GetUnsymbolized() bool {
+	if x != nil {
+		return x.Unsymbolized
+	}
+	return false
+}
+
+func (x *TreeReport) GetAttribution() *TreeAttribution {
+	if x != nil {
+		return x.Attribution
+	}
+	return nil
+}
+
+func (x *TreeReport) GetMultiValueTree() *MultiValueTree {
+	if x != nil {
+		return x.MultiValueTree
+	}
+	return nil
+}
+
+func (x *TreeReport) GetFormatVersion() uint32 {
+	if x != nil {
+		return x.FormatVersion
+	}
+	return 0
+}
+
+func (x *TreeReport) GetNodes() int64 {
+	if x != nil {
+		return x.Nodes
+	}
+	return 0
+}
+
+func (x *TreeReport) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+func (x *TreeReport) GetSampleCountsMissing() bool {
+	if x != nil {
+		return x.SampleCountsMissing
+	}
+	return false
+}
+
+func (x *TreeReport) GetNodeIds() []uint64 {
+	if x != nil {
+		return x.NodeIds
+	}
+	return nil
+}
+
+func (x *TreeReport) GetSymbolsOverLimit() bool {
+	if x != nil {
+		return x.SymbolsOverLimit
+	}
+	return false
+}
+
+func (x *TreeReport) GetSourceLocations() *TreeSourceLocations {
+	if x != nil {
+		return x.SourceLocations
+	}
+	return nil
+}
+
+func (x *TreeReport) GetSizeCapped() bool {
+	if x != nil {
+		return x.SizeCapped
+	}
+	return false
+}
+
+func (x *TreeReport) GetProfilesBelowMinValue() bool {
+	if x != nil {
+		return x.ProfilesBelowMinValue
+	}
+	return false
+}
+
+func (x *TreeReport) GetLabelValues() *TreeLabelValues {
+	if x != nil {
+		return x.LabelValues
+	}
+	return nil
+}
+
+func (x *TreeReport) GetCoverage() *TreeCoverage {
+	if x != nil {
+		return x.Coverage
+	}
+	return nil
+}
+
+func (x *TreeReport) GetBuckets() []*TreeBucket {
+	if x != nil {
+		return x.Buckets
+	}
+	return nil
+}
+
+func (x *TreeReport) GetSymbolsFormatVersion() uint32 {
+	if x != nil {
+		return x.SymbolsFormatVersion
+	}
+	return 0
+}
+
+func (x *TreeReport) GetStale() bool {
+	if x != nil {
+		return x.Stale
+	}
+	return false
+}
+
+func (x *TreeReport) GetSampleTypes() []*TreeSampleType {
+	if x != nil {
+		return x.SampleTypes
+	}
+	return nil
+}
+
+func (x *TreeReport) GetGoroutineStates() *TreeLabelValues {
+	if x != nil {
+		return x.GoroutineStates
+	}
+	return nil
+}
+
+func (x *TreeReport) GetIntervalValues() []float64 {
+	if x != nil {
+		return x.IntervalValues
+	}
+	return nil
+}
+
+func (x *TreeReport) GetLegend() *TreeLegend {
+	if x != nil {
+		return x.Legend
+	}
+	return nil
+}
+
+func (x *TreeReport) GetContinuation() bool {
+	if x != nil {
+		return x.Continuation
+	}
+	return false
+}
+
+type TreeLegend struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Packages ordered by value, descending, and by name.
+	Entries []*TreeLegendEntry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	// Sum of the values of the packages not included,
+	// and of the nodes truncated.
+	Other int64 `protobuf:"varint,2,opt,name=other,proto3" json:"other,omitempty"`
+}
+
+func (x *TreeLegend) Reset() {
+	*x = TreeLegend{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TreeLegend) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeLegend) ProtoMessage() {}
+
+func (x *TreeLegend) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeLegend.ProtoReflect.Descriptor instead.
+func (*TreeLegend) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *TreeLegend) GetEntries() []*TreeLegendEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *TreeLegend) GetOther() int64 {
+	if x != nil {
+		return x.Other
+	}
+	return 0
+}
+
+type TreeLegendEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Package of the frames, e.g. "github.com/grafana/pyroscope/pkg/model"
+	// or "std::collections"; the name of the frame itself, if the package
+	// can't be told.
+	Package string `protobuf:"bytes,1,opt,name=package,proto3" json:"package,omitempty"`
+	// Sum of the self values of the nodes of the package.
+	Value int64 `protobuf:"varint,2,opt,name=value,proto3" json:"value,omitempty"`
+	// Color index in the palette, in the range [0, colors). The index only
+	// depends on the package name: it is the same in different queries,
+	// regardless of the rank. Different packages may share the index.
+	Color uint32 `protobuf:"varint,3,opt,name=color,proto3" json:"color,omitempty"`
+}
+
+func (x *TreeLegendEntry) Reset() {
+	*x = TreeLegendEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TreeLegendEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeLegendEntry) ProtoMessage() {}
+
+func (x *TreeLegendEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeLegendEntry.ProtoReflect.Descriptor instead.
+func (*TreeLegendEntry) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *TreeLegendEntry) GetPackage() string {
+	if x != nil {
+		return x.Package
+	}
+	return ""
+}
+
+func (x *TreeLegendEntry) GetValue() int64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *TreeLegendEntry) GetColor() uint32 {
+	if x != nil {
+		return x.Color
+	}
+	return 0
+}
+
+type TreeSampleType struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Unit string `protobuf:"bytes,2,opt,name=unit,proto3" json:"unit,omitempty"`
+}
+
+func (x *TreeSampleType) Reset() {
+	*x = TreeSampleType{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TreeSampleType) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeSampleType) ProtoMessage() {}
+
+func (x *TreeSampleType) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeSampleType.ProtoReflect.Descriptor instead.
+func (*TreeSampleType) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *TreeSampleType) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *TreeSampleType) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+type TreeCoverage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Number of the datasets planned for the query.
+	Expected uint64 `protobuf:"varint,1,opt,name=expected,proto3" json:"expected,omitempty"`
+	// Number of the datasets the reports were received for.
+	Arrived uint64 `protobuf:"varint,2,opt,name=arrived,proto3" json:"arrived,omitempty"`
+	// Tree nodes in the order of traversal: a parent
+	// node always precedes its children.
+	Nodes []*TreeNodeCoverage `protobuf:"bytes,3,rep,name=nodes,proto3" json:"nodes,omitempty"`
+}
+
+func (x *TreeCoverage) Reset() {
+	*x = TreeCoverage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TreeCoverage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeCoverage) ProtoMessage() {}
+
+func (x *TreeCoverage) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeCoverage.ProtoReflect.Descriptor instead.
+func (*TreeCoverage) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *TreeCoverage) GetExpected() uint64 {
+	if x != nil {
+		return x.Expected
+	}
+	return 0
+}
+
+func (x *TreeCoverage) GetArrived() uint64 {
+	if x != nil {
+		return x.Arrived
+	}
+	return 0
+}
+
+func (x *TreeCoverage) GetNodes() []*TreeNodeCoverage {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+type TreeNodeCoverage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Index of the parent node; -1 for the root nodes.
+	Parent int32  `protobuf:"varint,1,opt,name=parent,proto3" json:"parent,omitempty"`
+	Name   string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Number of the datasets that contributed to the node.
+	Datasets uint64 `protobuf:"varint,3,opt,name=datasets,proto3" json:"datasets,omitempty"`
+	// Fraction of the expected datasets that
+	// contributed to the node, in the range [0, 1].
+	Coverage float64 `protobuf:"fixed64,4,opt,name=coverage,proto3" json:"coverage,omitempty"`
+}
+
+func (x *TreeNodeCoverage) Reset() {
+	*x = TreeNodeCoverage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TreeNodeCoverage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeNodeCoverage) ProtoMessage() {}
+
+func (x *TreeNodeCoverage) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeNodeCoverage.ProtoReflect.Descriptor instead.
+func (*TreeNodeCoverage) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *TreeNodeCoverage) GetParent() int32 {
+	if x != nil {
+		return x.Parent
+	}
+	return 0
+}
+
+func (x *TreeNodeCoverage) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TreeNodeCoverage) GetDatasets() uint64 {
+	if x != nil {
+		return x.Datasets
+	}
+	return 0
+}
+
+func (x *TreeNodeCoverage) GetCoverage() float64 {
+	if x != nil {
+		return x.Coverage
+	}
+	return 0
+}
+
+type TreeLabelValues struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Values of the label that contributed to the tree.
+	Values []string `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	// Tree nodes in the order of traversal: a parent
+	// node always precedes its children.
+	Nodes []*TreeNodeLabelValues `protobuf:"bytes,2,rep,name=nodes,proto3" json:"nodes,omitempty"`
+}
+
+func (x *TreeLabelValues) Reset() {
+	*x = TreeLabelValues{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TreeLabelValues) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeLabelValues) ProtoMessage() {}
+
+func (x *TreeLabelValues) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeLabelValues.ProtoReflect.Descriptor instead.
+func (*TreeLabelValues) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *TreeLabelValues) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+func (x *TreeLabelValues) GetNodes() []*TreeNodeLabelValues {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+type TreeNodeLabelValues struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Index of the parent node; -1 for the root nodes.
+	Parent int32  `protobuf:"varint,1,opt,name=parent,proto3" json:"parent,omitempty"`
+	Name   string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Indices of the label values that contributed to the
+	// node, and their contributions to the node total.
+	Values []uint32 `protobuf:"varint,3,rep,packed,name=values,proto3" json:"values,omitempty"`
+	Totals []int64  `protobuf:"varint,4,rep,packed,name=totals,proto3" json:"totals,omitempty"`
+	// Index of the value with the largest contribution; of
+	// the values that contribute equally, the first one in
+	// the sort order is chosen.
+	Dominant uint32 `protobuf:"varint,5,opt,name=dominant,proto3" json:"dominant,omitempty"`
+}
+
+func (x *TreeNodeLabelValues) Reset() {
+	*x = TreeNodeLabelValues{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TreeNodeLabelValues) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeNodeLabelValues) ProtoMessage() {}
+
+func (x *TreeNodeLabelValues) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeNodeLabelValues.ProtoReflect.Descriptor instead.
+func (*TreeNodeLabelValues) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *TreeNodeLabelValues) GetParent() int32 {
+	if x != nil {
+		return x.Parent
+	}
+	return 0
+}
+
+func (x *TreeNodeLabelValues) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TreeNodeLabelValues) GetValues() []uint32 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+func (x *TreeNodeLabelValues) GetTotals() []int64 {
+	if x != nil {
+		return x.Totals
+	}
+	return nil
+}
+
+func (x *TreeNodeLabelValues) GetDominant() uint32 {
+	if x != nil {
+		return x.Dominant
+	}
+	return 0
+}
+
+type TreeSourceLocations struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Tree nodes in the order of traversal: a parent
+	// node always precedes its children.
+	Nodes []*TreeNodeSourceLocation `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"`
+}
+
+func (x *TreeSourceLocations) Reset() {
+	*x = TreeSourceLocations{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TreeSourceLocations) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeSourceLocations) ProtoMessage() {}
+
+func (x *TreeSourceLocations) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeSourceLocations.ProtoReflect.Descriptor instead.
+func (*TreeSourceLocations) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *TreeSourceLocations) GetNodes() []*TreeNodeSourceLocation {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+type TreeNodeSourceLocation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Index of the parent node; -1 for the root nodes.
+	Parent int32  `protobuf:"varint,1,opt,name=parent,proto3" json:"parent,omitempty"`
+	Name   string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Source file path of the function; empty if unknown.
+	File string `protobuf:"bytes,3,opt,name=file,proto3" json:"file,omitempty"`
+	// Start line of the function in the source file.
+	Line int64 `protobuf:"varint,4,opt,name=line,proto3" json:"line,omitempty"`
+}
+
+func (x *TreeNodeSourceLocation) Reset() {
+	*x = TreeNodeSourceLocation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TreeNodeSourceLocation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeNodeSourceLocation) ProtoMessage() {}
+
+func (x *TreeNodeSourceLocation) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeNodeSourceLocation.ProtoReflect.Descriptor instead.
+func (*TreeNodeSourceLocation) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *TreeNodeSourceLocation) GetParent() int32 {
+	if x != nil {
+		return x.Parent
+	}
+	return 0
+}
+
+func (x *TreeNodeSourceLocation) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TreeNodeSourceLocation) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *TreeNodeSourceLocation) GetLine() int64 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+type MultiValueTree struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Tree nodes in the order of traversal: a parent
+	// node always precedes its children.
+	Nodes []*MultiValueTreeNode `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"`
+}
+
+func (x *MultiValueTree) Reset() {
+	*x = MultiValueTree{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[34]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MultiValueTree) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MultiValueTree) ProtoMessage() {}
+
+func (x *MultiValueTree) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[34]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MultiValueTree.ProtoReflect.Descriptor instead.
+func (*MultiValueTree) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *MultiValueTree) GetNodes() []*MultiValueTreeNode {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+type MultiValueTreeNode struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Index of the parent node; -1 for the root nodes.
+	Parent int32  `protobuf:"varint,1,opt,name=parent,proto3" json:"parent,omitempty"`
+	Name   string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Self values of the node, one per profile type.
+	Self []int64 `protobuf:"varint,3,rep,packed,name=self,proto3" json:"self,omitempty"`
+}
+
+func (x *MultiValueTreeNode) Reset() {
+	*x = MultiValueTreeNode{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[35]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MultiValueTreeNode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MultiValueTreeNode) ProtoMessage() {}
+
+func (x *MultiValueTreeNode) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[35]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MultiValueTreeNode.ProtoReflect.Descriptor instead.
+func (*MultiValueTreeNode) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *MultiValueTreeNode) GetParent() int32 {
+	if x != nil {
+		return x.Parent
+	}
+	return 0
+}
+
+func (x *MultiValueTreeNode) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *MultiValueTreeNode) GetSelf() []int64 {
+	if x != nil {
+		return x.Self
+	}
+	return nil
+}
+
+type TreeAttribution struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// IDs of the contributing sources.
+	Sources []string `protobuf:"bytes,1,rep,name=sources,proto3" json:"sources,omitempty"`
+	// Tree nodes in the order of traversal: a parent
+	// node always precedes its children.
+	Nodes []*TreeNodeAttribution `protobuf:"bytes,2,rep,name=nodes,proto3" json:"nodes,omitempty"`
+}
+
+func (x *TreeAttribution) Reset() {
+	*x = TreeAttribution{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[36]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TreeAttribution) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeAttribution) ProtoMessage() {}
+
+func (x *TreeAttribution) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[36]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeAttribution.ProtoReflect.Descriptor instead.
+func (*TreeAttribution) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *TreeAttribution) GetSources() []string {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
+func (x *TreeAttribution) GetNodes() []*TreeNodeAttribution {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+type TreeNodeAttribution struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Index of the parent node; -1 for the root nodes.
+	Parent int32  `protobuf:"varint,1,opt,name=parent,proto3" json:"parent,omitempty"`
+	Name   string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Indices of the sources that contributed to the node.
+	Sources []uint32 `protobuf:"varint,3,rep,packed,name=sources,proto3" json:"sources,omitempty"`
+}
+
+func (x *TreeNodeAttribution) Reset() {
+	*x = TreeNodeAttribution{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[37]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TreeNodeAttribution) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeNodeAttribution) ProtoMessage() {}
+
+func (x *TreeNodeAttribution) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[37]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeNodeAttribution.ProtoReflect.Descriptor instead.
+func (*TreeNodeAttribution) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *TreeNodeAttribution) GetParent() int32 {
+	if x != nil {
+		return x.Parent
+	}
+	return 0
+}
+
+func (x *TreeNodeAttribution) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TreeNodeAttribution) GetSources() []uint32 {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
+type TimeRangeQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *TimeRangeQuery) Reset() {
+	*x = TimeRangeQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[38]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TimeRangeQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TimeRangeQuery) ProtoMessage() {}
+
+func (x *TimeRangeQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[38]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TimeRangeQuery.ProtoReflect.Descriptor instead.
+func (*TimeRangeQuery) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{38}
+}
+
+type TimeRangeReport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Query *TimeRangeQuery `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// Observed time range of the matching profiles,
+	// in milliseconds since epoch.
+	MinTime int64 `protobuf:"varint,2,opt,name=min_time,json=minTime,proto3" json:"min_time,omitempty"`
+	MaxTime int64 `protobuf:"varint,3,opt,name=max_time,json=maxTime,proto3" json:"max_time,omitempty"`
+}
+
+func (x *TimeRangeReport) Reset() {
+	*x = TimeRangeReport{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[39]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TimeRangeReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TimeRangeReport) ProtoMessage() {}
+
+func (x *TimeRangeReport) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[39]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TimeRangeReport.ProtoReflect.Descriptor instead.
+func (*TimeRangeReport) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *TimeRangeReport) GetQuery() *TimeRangeQuery {
+	if x != nil {
+		return x.Query
+	}
+	return nil
+}
+
+func (x *TimeRangeReport) GetMinTime() int64 {
+	if x != nil {
+		return x.MinTime
+	}
+	return 0
+}
+
+func (x *TimeRangeReport) GetMaxTime() int64 {
+	if x != nil {
+		return x.MaxTime
+	}
+	return 0
+}
+
+type CallGraphQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Maximum number of nodes: only the nodes with the
+	// highest total values, and the edges between them,
+	// are retained. If not set, all nodes are included.
+	MaxNodes int64 `protobuf:"varint,1,opt,name=max_nodes,json=maxNodes,proto3" json:"max_nodes,omitempty"`
+}
+
+func (x *CallGraphQuery) Reset() {
+	*x = CallGraphQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[40]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CallGraphQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CallGraphQuery) ProtoMessage() {}
+
+func (x *CallGraphQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[40]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CallGraphQuery.ProtoReflect.Descriptor instead.
+func (*CallGraphQuery) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *CallGraphQuery) GetMaxNodes() int64 {
+	if x != nil {
+		return x.MaxNodes
+	}
+	return 0
+}
+
+// Call graph, where each function is represented by a single
+// node, regardless of the call path. Recursive calls are merged:
+// a function contributes to the total value of a node and to the
+// weight of an edge once per stack trace.
+type CallGraphReport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Query *CallGraphQuery `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// Nodes, ordered by the total value, descending.
+	Nodes []*CallGraphNode `protobuf:"bytes,2,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	// Edges, ordered by the caller and the callee indices.
+	Edges []*CallGraphEdge `protobuf:"bytes,3,rep,name=edges,proto3" json:"edges,omitempty"`
+}
+
+func (x *CallGraphReport) Reset() {
+	*x = CallGraphReport{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[41]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CallGraphReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CallGraphReport) ProtoMessage() {}
+
+func (x *CallGraphReport) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[41]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CallGraphReport.ProtoReflect.Descriptor instead.
+func (*CallGraphReport) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *CallGraphReport) GetQuery() *CallGraphQuery {
+	if x != nil {
+		return x.Query
+	}
+	return nil
+}
+
+func (x *CallGraphReport) GetNodes() []*CallGraphNode {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+func (x *CallGraphReport) GetEdges() []*CallGraphEdge {
+	if x != nil {
+		return x.Edges
+	}
+	return nil
+}
+
+type CallGraphNode struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name  string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Self  int64  `protobuf:"varint,2,opt,name=self,proto3" json:"self,omitempty"`
+	Total int64  `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
+}
+
+func (x *CallGraphNode) Reset() {
+	*x = CallGraphNode{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[42]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CallGraphNode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CallGraphNode) ProtoMessage() {}
+
+func (x *CallGraphNode) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[42]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CallGraphNode.ProtoReflect.Descriptor instead.
+func (*CallGraphNode) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *CallGraphNode) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CallGraphNode) GetSelf() int64 {
+	if x != nil {
+		return x.Self
+	}
+	return 0
+}
+
+func (x *CallGraphNode) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type CallGraphEdge struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Indices of the caller and callee nodes.
+	Caller uint32 `protobuf:"varint,1,opt,name=caller,proto3" json:"caller,omitempty"`
+	Callee uint32 `protobuf:"varint,2,opt,name=callee,proto3" json:"callee,omitempty"`
+	// The total value of the stack traces that include the call.
+	Value int64 `protobuf:"varint,3,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *CallGraphEdge) Reset() {
+	*x = CallGraphEdge{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[43]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CallGraphEdge) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CallGraphEdge) ProtoMessage() {}
+
+func (x *CallGraphEdge) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[43]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CallGraphEdge.ProtoReflect.Descriptor instead.
+func (*CallGraphEdge) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *CallGraphEdge) GetCaller() uint32 {
+	if x != nil {
+		return x.Caller
+	}
+	return 0
+}
+
+func (x *CallGraphEdge) GetCallee() uint32 {
+	if x != nil {
+		return x.Callee
+	}
+	return 0
+}
+
+func (x *CallGraphEdge) GetValue() int64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+// The self-test query loads all the sections of the datasets
+// of the given block, and resolves a small tree, verifying
+// that the whole query path works.
+type SelfTestQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Identifier of the block to test. Datasets
+	// of other blocks in the query plan are skipped.
+	BlockId string `protobuf:"bytes,1,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+	// Maximum number of nodes of the resolved tree. Defaults to 16.
+	MaxNodes int64 `protobuf:"varint,2,opt,name=max_nodes,json=maxNodes,proto3" json:"max_nodes,omitempty"`
+}
+
+func (x *SelfTestQuery) Reset() {
+	*x = SelfTestQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[44]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SelfTestQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SelfTestQuery) ProtoMessage() {}
+
+func (x *SelfTestQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[44]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SelfTestQuery.ProtoReflect.Descriptor instead.
+func (*SelfTestQuery) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *SelfTestQuery) GetBlockId() string {
+	if x != nil {
+		return x.BlockId
+	}
+	return ""
+}
+
+func (x *SelfTestQuery) GetMaxNodes() int64 {
+	if x != nil {
+		return x.MaxNodes
+	}
+	return 0
+}
+
+type SelfTestReport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Query *SelfTestQuery `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// Datasets of the block, ordered by tenant and name. The report
+	// is not returned, if the block is not found in the query plan.
+	Datasets []*SelfTestDataset `protobuf:"bytes,2,rep,name=datasets,proto3" json:"datasets,omitempty"`
+}
+
+func (x *SelfTestReport) Reset() {
+	*x = SelfTestReport{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[45]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SelfTestReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SelfTestReport) ProtoMessage() {}
+
+func (x *SelfTestReport) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[45]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SelfTestReport.ProtoReflect.Descriptor instead.
+func (*SelfTestReport) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *SelfTestReport) GetQuery() *SelfTestQuery {
+	if x != nil {
+		return x.Query
+	}
+	return nil
+}
+
+func (x *SelfTestReport) GetDatasets() []*SelfTestDataset {
+	if x != nil {
+		return x.Datasets
+	}
+	return nil
+}
+
+type SelfTestDataset struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TenantId string `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	Name     string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Sections in the order they were loaded.
+	Sections []*SelfTestSection `protobuf:"bytes,3,rep,name=sections,proto3" json:"sections,omitempty"`
+	// Resolved tree, truncated to max_nodes.
+	Tree []byte `protobuf:"bytes,4,opt,name=tree,proto3" json:"tree,omitempty"`
+}
+
+func (x *SelfTestDataset) Reset() {
+	*x = SelfTestDataset{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[46]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SelfTestDataset) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SelfTestDataset) ProtoMessage() {}
+
+func (x *SelfTestDataset) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[46]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SelfTestDataset.ProtoReflect.Descriptor instead.
+func (*SelfTestDataset) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *SelfTestDataset) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+func (x *SelfTestDataset) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SelfTestDataset) GetSections() []*SelfTestSection {
+	if x != nil {
+		return x.Sections
+	}
+	return nil
+}
+
+func (x *SelfTestDataset) GetTree() []byte {
+	if x != nil {
+		return x.Tree
+	}
+	return nil
+}
+
+type SelfTestSection struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Time spent loading the section, in nanoseconds.
+	LoadDurationNanos int64 `protobuf:"varint,2,opt,name=load_duration_nanos,json=loadDurationNanos,proto3" json:"load_duration_nanos,omitempty"`
+}
+
+func (x *SelfTestSection) Reset() {
+	*x = SelfTestSection{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[47]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SelfTestSection) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SelfTestSection) ProtoMessage() {}
+
+func (x *SelfTestSection) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[47]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SelfTestSection.ProtoReflect.Descriptor instead.
+func (*SelfTestSection) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *SelfTestSection) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SelfTestSection) GetLoadDurationNanos() int64 {
+	if x != nil {
+		return x.LoadDurationNanos
+	}
+	return 0
+}
+
+type ManifestQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Maximum number of profiles in the report: the earliest
+	// ones are retained. Zero means no limit.
+	Limit int64 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+}
+
+func (x *ManifestQuery) Reset() {
+	*x = ManifestQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[48]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ManifestQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ManifestQuery) ProtoMessage() {}
+
+func (x *ManifestQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[48]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ManifestQuery.ProtoReflect.Descriptor instead.
+func (*ManifestQuery) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *ManifestQuery) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ManifestReport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Query *ManifestQuery `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// Descriptors of the matching profiles, ordered by timestamp.
+	Profiles []*ProfileDescriptor `protobuf:"bytes,2,rep,name=profiles,proto3" json:"profiles,omitempty"`
+}
+
+func (x *ManifestReport) Reset() {
+	*x = ManifestReport{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[49]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ManifestReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ManifestReport) ProtoMessage() {}
+
+func (x *ManifestReport) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[49]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ManifestReport.ProtoReflect.Descriptor instead.
+func (*ManifestReport) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *ManifestReport) GetQuery() *ManifestQuery {
+	if x != nil {
+		return x.Query
+	}
+	return nil
+}
+
+func (x *ManifestReport) GetProfiles() []*ProfileDescriptor {
+	if x != nil {
+		return x.Profiles
+	}
+	return nil
+}
+
+// ProfileDescriptor describes a profile without the stack trace data.
+type ProfileDescriptor struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Milliseconds since epoch.
+	Timestamp int64            `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Labels    []*v11.LabelPair `protobuf:"bytes,2,rep,name=labels,proto3" json:"labels,omitempty"`
+	// Profile type ID, e.g. "memory:alloc_space:bytes:space:bytes".
+	ProfileType string `protobuf:"bytes,3,opt,name=profile_type,json=profileType,proto3" json:"profile_type,omitempty"`
+	// Sum of the sample values of the profile.
+	TotalValue int64 `protobuf:"varint,4,opt,name=total_value,json=totalValue,proto3" json:"total_value,omitempty"`
+	// Identifier of the block the profile is stored in.
+	BlockId string `protobuf:"bytes,5,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+}
+
+func (x *ProfileDescriptor) Reset() {
+	*x = ProfileDescriptor{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[50]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProfileDescriptor) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProfileDescriptor) ProtoMessage() {}
+
+func (x *ProfileDescriptor) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[50]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProfileDescriptor.ProtoReflect.Descriptor instead.
+func (*ProfileDescriptor) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *ProfileDescriptor) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *ProfileDescriptor) GetLabels() []*v11.LabelPair {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *ProfileDescriptor) GetProfileType() string {
+	if x != nil {
+		return x.ProfileType
+	}
+	return ""
+}
+
+func (x *ProfileDescriptor) GetTotalValue() int64 {
+	if x != nil {
+		return x.TotalValue
+	}
+	return 0
+}
+
+func (x *ProfileDescriptor) GetBlockId() string {
+	if x != nil {
+		return x.BlockId
+	}
+	return ""
+}
+
+type SymbolTableQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// If set, only the locations that have no line
+	// information are included into the report.
+	UnsymbolizedOnly bool `protobuf:"varint,1,opt,name=unsymbolized_only,json=unsymbolizedOnly,proto3" json:"unsymbolized_only,omitempty"`
+}
+
+func (x *SymbolTableQuery) Reset() {
+	*x = SymbolTableQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[51]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SymbolTableQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SymbolTableQuery) ProtoMessage() {}
+
+func (x *SymbolTableQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[51]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SymbolTableQuery.ProtoReflect.Descriptor instead.
+func (*SymbolTableQuery) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *SymbolTableQuery) GetUnsymbolizedOnly() bool {
+	if x != nil {
+		return x.UnsymbolizedOnly
+	}
+	return false
+}
+
+// SymbolTableReport lists the addresses of the locations of
+// the stack trace partitions the matching profiles belong to,
+// and the mappings of the locations. The stack traces are not
+// resolved. The report is meant for symbolization performed
+// outside of the query backend.
+type SymbolTableReport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Query *SymbolTableQuery `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// Distinct mappings, ordered by build ID, file name,
+	// and the address range.
+	Mappings []*SymbolMapping `protobuf:"bytes,2,rep,name=mappings,proto3" json:"mappings,omitempty"`
+	// Distinct locations, ordered by mapping and address.
+	Locations []*SymbolLocation `protobuf:"bytes,3,rep,name=locations,proto3" json:"locations,omitempty"`
+}
+
+func (x *SymbolTableReport) Reset() {
+	*x = SymbolTableReport{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[52]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SymbolTableReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SymbolTableReport) ProtoMessage() {}
+
+func (x *SymbolTableReport) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[52]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SymbolTableReport.ProtoReflect.Descriptor instead.
+func (*SymbolTableReport) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *SymbolTableReport) GetQuery() *SymbolTableQuery {
+	if x != nil {
+		return x.Query
+	}
+	return nil
+}
+
+func (x *SymbolTableReport) GetMappings() []*SymbolMapping {
+	if x != nil {
+		return x.Mappings
+	}
+	return nil
+}
+
+func (x *SymbolTableReport) GetLocations() []*SymbolLocation {
+	if x != nil {
+		return x.Locations
+	}
+	return nil
+}
+
+type SymbolMapping struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MemoryStart uint64 `protobuf:"varint,1,opt,name=memory_start,json=memoryStart,proto3" json:"memory_start,omitempty"`
+	MemoryLimit uint64 `protobuf:"varint,2,opt,name=memory_limit,json=memoryLimit,proto3" json:"memory_limit,omitempty"`
+	FileOffset  uint64 `protobuf:"varint,3,opt,name=file_offset,json=fileOffset,proto3" json:"file_offset,omitempty"`
+	Filename    string `protobuf:"bytes,4,opt,name=filename,proto3" json:"filename,omitempty"`
+	BuildId     string `protobuf:"bytes,5,opt,name=build_id,json=buildId,proto3" json:"build_id,omitempty"`
+}
+
+func (x *SymbolMapping) Reset() {
+	*x = SymbolMapping{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[53]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SymbolMapping) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SymbolMapping) ProtoMessage() {}
+
+func (x *SymbolMapping) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[53]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SymbolMapping.ProtoReflect.Descriptor instead.
+func (*SymbolMapping) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *SymbolMapping) GetMemoryStart() uint64 {
+	if x != nil {
+		return x.MemoryStart
+	}
+	return 0
+}
+
+func (x *SymbolMapping) GetMemoryLimit() uint64 {
+	if x != nil {
+		return x.MemoryLimit
+	}
+	return 0
+}
+
+func (x *SymbolMapping) GetFileOffset() uint64 {
+	if x != nil {
+		return x.FileOffset
+	}
+	return 0
+}
+
+func (x *SymbolMapping) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *SymbolMapping) GetBuildId() string {
+	if x != nil {
+		return x.BuildId
+	}
+	return ""
+}
+
+type SymbolLocation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Address uint64 `protobuf:"varint,1,opt,name=address,proto3" json:"address,omitempty"`
+	// Index of the location mapping in the report.
+	Mapping uint32 `protobuf:"varint,2,opt,name=mapping,proto3" json:"mapping,omitempty"`
+	// Set if the location has line information.
+	Symbolized bool `protobuf:"varint,3,opt,name=symbolized,proto3" json:"symbolized,omitempty"`
+}
+
+func (x *SymbolLocation) Reset() {
+	*x = SymbolLocation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[54]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SymbolLocation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SymbolLocation) ProtoMessage() {}
+
+func (x *SymbolLocation) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[54]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SymbolLocation.ProtoReflect.Descriptor instead.
+func (*SymbolLocation) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *SymbolLocation) GetAddress() uint64 {
+	if x != nil {
+		return x.Address
+	}
+	return 0
+}
+
+func (x *SymbolLocation) GetMapping() uint32 {
+	if x != nil {
+		return x.Mapping
+	}
+	return 0
+}
+
+func (x *SymbolLocation) GetSymbolized() bool {
+	if x != nil {
+		return x.Symbolized
+	}
+	return false
+}
+
+// FunctionChangesQuery compares the self values of the functions in two
+// time windows, e.g. to find out what got slower between yesterday and
+// today. The time range of the request must include both of the windows.
+type FunctionChangesQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Milliseconds since epoch, inclusive.
+	BaselineStart int64 `protobuf:"varint,1,opt,name=baseline_start,json=baselineStart,proto3" json:"baseline_start,omitempty"`
+	BaselineEnd   int64 `protobuf:"varint,2,opt,name=baseline_end,json=baselineEnd,proto3" json:"baseline_end,omitempty"`
+	CurrentStart  int64 `protobuf:"varint,3,opt,name=current_start,json=currentStart,proto3" json:"current_start,omitempty"`
+	CurrentEnd    int64 `protobuf:"varint,4,opt,name=current_end,json=currentEnd,proto3" json:"current_end,omitempty"`
+	// Maximum number of functions in the report:
+	// the most changed ones are retained. Zero means no limit.
+	Limit   int64                 `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	Ranking FunctionChangeRanking `protobuf:"varint,6,opt,name=ranking,proto3,enum=querybackend.v1.FunctionChangeRanking" json:"ranking,omitempty"`
+}
+
+func (x *FunctionChangesQuery) Reset() {
+	*x = FunctionChangesQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[55]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FunctionChangesQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FunctionChangesQuery) ProtoMessage() {}
+
+func (x *FunctionChangesQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[55]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FunctionChangesQuery.ProtoReflect.Descriptor instead.
+func (*FunctionChangesQuery) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *FunctionChangesQuery) GetBaselineStart() int64 {
+	if x != nil {
+		return x.BaselineStart
+	}
+	return 0
+}
+
+func (x *FunctionChangesQuery) GetBaselineEnd() int64 {
+	if x != nil {
+		return x.BaselineEnd
+	}
+	return 0
+}
+
+func (x *FunctionChangesQuery) GetCurrentStart() int64 {
+	if x != nil {
+		return x.CurrentStart
+	}
+	return 0
+}
+
+func (x *FunctionChangesQuery) GetCurrentEnd() int64 {
+	if x != nil {
+		return x.CurrentEnd
+	}
+	return 0
+}
+
+func (x *FunctionChangesQuery) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *FunctionChangesQuery) GetRanking() FunctionChangeRanking {
+	if x != nil {
+		return x.Ranking
+	}
+	return FunctionChangeRanking_FUNCTION_CHANGE_RANKING_ABSOLUTE
+}
+
+type FunctionChangesReport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Query *FunctionChangesQuery `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// Functions ordered by rank.
+	Functions []*FunctionChange `protobuf:"bytes,2,rep,name=functions,proto3" json:"functions,omitempty"`
+}
+
+func (x *FunctionChangesReport) Reset() {
+	*x = FunctionChangesReport{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[56]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FunctionChangesReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FunctionChangesReport) ProtoMessage() {}
+
+func (x *FunctionChangesReport) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[56]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FunctionChangesReport.ProtoReflect.Descriptor instead.
+func (*FunctionChangesReport) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *FunctionChangesReport) GetQuery() *FunctionChangesQuery {
+	if x != nil {
+		return x.Query
+	}
+	return nil
+}
+
+func (x *FunctionChangesReport) GetFunctions() []*FunctionChange {
+	if x != nil {
+		return x.Functions
+	}
+	return nil
+}
+
+type FunctionChange struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Self values of the function in the windows. Zero
+	// means that the function is absent in the window.
+	BaselineSelf int64 `protobuf:"varint,2,opt,name=baseline_self,json=baselineSelf,proto3" json:"baseline_self,omitempty"`
+	CurrentSelf  int64 `protobuf:"varint,3,opt,name=current_self,json=currentSelf,proto3" json:"current_self,omitempty"`
+}
+
+func (x *FunctionChange) Reset() {
+	*x = FunctionChange{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[57]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FunctionChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FunctionChange) ProtoMessage() {}
+
+func (x *FunctionChange) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[57]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FunctionChange.ProtoReflect.Descriptor instead.
+func (*FunctionChange) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *FunctionChange) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FunctionChange) GetBaselineSelf() int64 {
+	if x != nil {
+		return x.BaselineSelf
+	}
+	return 0
+}
+
+func (x *FunctionChange) GetCurrentSelf() int64 {
+	if x != nil {
+		return x.CurrentSelf
+	}
+	return 0
+}
+
+// FlameGraphDiffQuery compares the profiles selected by two label
+// selectors, e.g. of two versions of a service. The selectors apply
+// in addition to the label selector of the request.
+type FlameGraphDiffQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Selector of the left side, the baseline of the comparison.
+	LeftSelector string `protobuf:"bytes,1,opt,name=left_selector,json=leftSelector,proto3" json:"left_selector,omitempty"`
+	// Selector of the right side.
+	RightSelector string `protobuf:"bytes,2,opt,name=right_selector,json=rightSelector,proto3" json:"right_selector,omitempty"`
+	MaxNodes      int64  `protobuf:"varint,3,opt,name=max_nodes,json=maxNodes,proto3" json:"max_nodes,omitempty"`
+	// Representation of the differences of the flame graph nodes.
+	DiffMode DiffMode `protobuf:"varint,4,opt,name=diff_mode,json=diffMode,proto3,enum=querybackend.v1.DiffMode" json:"diff_mode,omitempty"`
+}
+
+func (x *FlameGraphDiffQuery) Reset() {
+	*x = FlameGraphDiffQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[58]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FlameGraphDiffQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlameGraphDiffQuery) ProtoMessage() {}
+
+func (x *FlameGraphDiffQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[58]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlameGraphDiffQuery.ProtoReflect.Descriptor instead.
+func (*FlameGraphDiffQuery) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *FlameGraphDiffQuery) GetLeftSelector() string {
+	if x != nil {
+		return x.LeftSelector
+	}
+	return ""
+}
+
+func (x *FlameGraphDiffQuery) GetRightSelector() string {
+	if x != nil {
+		return x.RightSelector
+	}
+	return ""
+}
+
+func (x *FlameGraphDiffQuery) GetMaxNodes() int64 {
+	if x != nil {
+		return x.MaxNodes
+	}
+	return 0
+}
+
+func (x *FlameGraphDiffQuery) GetDiffMode() DiffMode {
+	if x != nil {
+		return x.DiffMode
+	}
+	return DiffMode_DIFF_MODE_DELTA
+}
+
+type FlameGraphDiffReport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Query *FlameGraphDiffQuery `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// Trees of the two sides. Partial reports are merged by the trees:
+	// the flame graph is only built for the merged ones, before they are
+	// truncated. The trees are truncated to max_nodes, to the same nodes
+	// in both of the sides: the ones of the largest totals in either of
+	// them. The values of the nodes removed are accounted in the "other"
+	// node of the parent. The trees must not have negative values.
+	LeftTree  []byte `protobuf:"bytes,2,opt,name=left_tree,json=leftTree,proto3" json:"left_tree,omitempty"`
+	RightTree []byte `protobuf:"bytes,3,opt,name=right_tree,json=rightTree,proto3" json:"right_tree,omitempty"`
+	// Diff flame graph in the format rendered by the UI: each node
+	// carries the values of both sides, which the delta is derived
+	// from. Not set if neither of the sides has any profiles.
+	Flamegraph *v12.FlameGraphDiff `protobuf:"bytes,4,opt,name=flamegraph,proto3" json:"flamegraph,omitempty"`
+	// Differences of the totals of the flame graph nodes in the diff_mode
+	// of the query, by the levels of the flame graph and the nodes of
+	// the levels, in the order of the nodes in the levels.
+	DiffLevels []*FlameGraphDiffLevel `protobuf:"bytes,5,rep,name=diff_levels,json=diffLevels,proto3" json:"diff_levels,omitempty"`
+}
+
+func (x *FlameGraphDiffReport) Reset() {
+	*x = FlameGraphDiffReport{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[59]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FlameGraphDiffReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlameGraphDiffReport) ProtoMessage() {}
+
+func (x *FlameGraphDiffReport) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[59]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlameGraphDiffReport.ProtoReflect.Descriptor instead.
+func (*FlameGraphDiffReport) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *FlameGraphDiffReport) GetQuery() *FlameGraphDiffQuery {
+	if x != nil {
+		return x.Query
+	}
+	return nil
+}
+
+func (x *FlameGraphDiffReport) GetLeftTree() []byte {
+	if x != nil {
+		return x.LeftTree
+	}
+	return nil
+}
+
+func (x *FlameGraphDiffReport) GetRightTree() []byte {
+	if x != nil {
+		return x.RightTree
+	}
+	return nil
+}
+
+func (x *FlameGraphDiffReport) GetFlamegraph() *v12.FlameGraphDiff {
+	if x != nil {
+		return x.Flamegraph
+	}
+	return nil
+}
+
+func (x *FlameGraphDiffReport) GetDiffLevels() []*FlameGraphDiffLevel {
+	if x != nil {
+		return x.DiffLevels
+	}
+	return nil
+}
+
+type FlameGraphDiffLevel struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Values []float64 `protobuf:"fixed64,1,rep,packed,name=values,proto3" json:"values,omitempty"`
+}
+
+func (x *FlameGraphDiffLevel) Reset() {
+	*x = FlameGraphDiffLevel{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[60]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FlameGraphDiffLevel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlameGraphDiffLevel) ProtoMessage() {}
+
+func (x *FlameGraphDiffLevel) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[60]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlameGraphDiffLevel.ProtoReflect.Descriptor instead.
+func (*FlameGraphDiffLevel) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *FlameGraphDiffLevel) GetValues() []float64 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+// TreeDiffQuery compares the profiles of two sets, e.g. the time ranges
+// before and after a deployment. The result is a single tree, which nodes
+// carry the values of both sets. The time range of the request must
+// include the time ranges of both of the sets.
+type TreeDiffQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Baseline   *TreeDiffSet `protobuf:"bytes,1,opt,name=baseline,proto3" json:"baseline,omitempty"`
+	Comparison *TreeDiffSet `protobuf:"bytes,2,opt,name=comparison,proto3" json:"comparison,omitempty"`
+	// Maximum number of the nodes of the tree. The nodes that changed the
+	// most are retained: they are ranked by the difference of the totals,
+	// regardless of the sign. The values of the nodes removed are accounted
+	// in the "other" node of the parent. Zero means no limit. The ranking
+	// does not depend on the diff_mode.
+	MaxNodes int64 `protobuf:"varint,3,opt,name=max_nodes,json=maxNodes,proto3" json:"max_nodes,omitempty"`
+	// Representation of the differences of the nodes, see TreeDiffNode.
+	DiffMode DiffMode `protobuf:"varint,4,opt,name=diff_mode,json=diffMode,proto3,enum=querybackend.v1.DiffMode" json:"diff_mode,omitempty"`
+}
+
+func (x *TreeDiffQuery) Reset() {
+	*x = TreeDiffQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[61]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TreeDiffQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeDiffQuery) ProtoMessage() {}
+
+func (x *TreeDiffQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[61]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeDiffQuery.ProtoReflect.Descriptor instead.
+func (*TreeDiffQuery) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *TreeDiffQuery) GetBaseline() *TreeDiffSet {
+	if x != nil {
+		return x.Baseline
+	}
+	return nil
+}
+
+func (x *TreeDiffQuery) GetComparison() *TreeDiffSet {
+	if x != nil {
+		return x.Comparison
+	}
+	return nil
+}
+
+func (x *TreeDiffQuery) GetMaxNodes() int64 {
+	if x != nil {
+		return x.MaxNodes
+	}
+	return 0
+}
+
+func (x *TreeDiffQuery) GetDiffMode() DiffMode {
+	if x != nil {
+		return x.DiffMode
+	}
+	return DiffMode_DIFF_MODE_DELTA
+}
+
+type TreeDiffSet struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Milliseconds since epoch, inclusive.
+	StartTime int64 `protobuf:"varint,1,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime   int64 `protobuf:"varint,2,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	// Optional selector, applied in addition to
+	// the label selector of the request.
+	LabelSelector string `protobuf:"bytes,3,opt,name=label_selector,json=labelSelector,proto3" json:"label_selector,omitempty"`
+}
+
+func (x *TreeDiffSet) Reset() {
+	*x = TreeDiffSet{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[62]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TreeDiffSet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeDiffSet) ProtoMessage() {}
+
+func (x *TreeDiffSet) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[62]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeDiffSet.ProtoReflect.Descriptor instead.
+func (*TreeDiffSet) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *TreeDiffSet) GetStartTime() int64 {
+	if x != nil {
+		return x.StartTime
+	}
+	return 0
+}
+
+func (x *TreeDiffSet) GetEndTime() int64 {
+	if x != nil {
+		return x.EndTime
+	}
+	return 0
+}
+
+func (x *TreeDiffSet) GetLabelSelector() string {
+	if x != nil {
+		return x.LabelSelector
+	}
+	return ""
+}
+
+type TreeDiffReport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Query *TreeDiffQuery `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// Nodes of the tree, parents preceding their children. The sets are
+	// merged independently, and the nodes are only ranked by the difference
+	// once the reports are merged: the partial reports are truncated to the
+	// nodes of the largest totals in either of the sets instead.
+	Nodes []*TreeDiffNode `protobuf:"bytes,2,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	// Set if the tree has been truncated to max_nodes.
+	Truncated bool `protobuf:"varint,3,opt,name=truncated,proto3" json:"truncated,omitempty"`
+}
+
+func (x *TreeDiffReport) Reset() {
+	*x = TreeDiffReport{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[63]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TreeDiffReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeDiffReport) ProtoMessage() {}
+
+func (x *TreeDiffReport) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[63]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeDiffReport.ProtoReflect.Descriptor instead.
+func (*TreeDiffReport) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *TreeDiffReport) GetQuery() *TreeDiffQuery {
+	if x != nil {
+		return x.Query
+	}
+	return nil
+}
+
+func (x *TreeDiffReport) GetNodes() []*TreeDiffNode {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+func (x *TreeDiffReport) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+type TreeDiffNode struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Index of the parent node, or -1 for the roots.
+	Parent          int32  `protobuf:"varint,1,opt,name=parent,proto3" json:"parent,omitempty"`
+	Name            string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	BaselineSelf    int64  `protobuf:"varint,3,opt,name=baseline_self,json=baselineSelf,proto3" json:"baseline_self,omitempty"`
+	BaselineTotal   int64  `protobuf:"varint,4,opt,name=baseline_total,json=baselineTotal,proto3" json:"baseline_total,omitempty"`
+	ComparisonSelf  int64  `protobuf:"varint,5,opt,name=comparison_self,json=comparisonSelf,proto3" json:"comparison_self,omitempty"`
+	ComparisonTotal int64  `protobuf:"varint,6,opt,name=comparison_total,json=comparisonTotal,proto3" json:"comparison_total,omitempty"`
+	// Difference of the totals in the diff_mode of the query. The values
+	// of the sets are merged independently, and the difference is computed
+	// again for the merged ones.
+	Diff float64 `protobuf:"fixed64,7,opt,name=diff,proto3" json:"diff,omitempty"`
+}
+
+func (x *TreeDiffNode) Reset() {
+	*x = TreeDiffNode{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[64]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TreeDiffNode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeDiffNode) ProtoMessage() {}
+
+func (x *TreeDiffNode) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[64]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeDiffNode.ProtoReflect.Descriptor instead.
+func (*TreeDiffNode) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{64}
+}
+
+func (x *TreeDiffNode) GetParent() int32 {
+	if x != nil {
+		return x.Parent
+	}
+	return 0
+}
+
+func (x *TreeDiffNode) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TreeDiffNode) GetBaselineSelf() int64 {
+	if x != nil {
+		return x.BaselineSelf
+	}
+	return 0
+}
+
+func (x *TreeDiffNode) GetBaselineTotal() int64 {
+	if x != nil {
+		return x.BaselineTotal
+	}
+	return 0
+}
+
+func (x *TreeDiffNode) GetComparisonSelf() int64 {
+	if x != nil {
+		return x.ComparisonSelf
+	}
+	return 0
+}
+
+func (x *TreeDiffNode) GetComparisonTotal() int64 {
+	if x != nil {
+		return x.ComparisonTotal
+	}
+	return 0
+}
+
+func (x *TreeDiffNode) GetDiff() float64 {
+	if x != nil {
+		return x.Diff
+	}
+	return 0
+}
+
+// StacktracesQuery returns the sample values per stack trace ID, along
+// with the frames the stack traces are resolved into. Unlike the tree,
+// the values of the stack traces are not merged by the frames.
+type StacktracesQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *StacktracesQuery) Reset() {
+	*x = StacktracesQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[65]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StacktracesQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StacktracesQuery) ProtoMessage() {}
+
+func (x *StacktracesQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[65]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StacktracesQuery.ProtoReflect.Descriptor instead.
+func (*StacktracesQuery) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{65}
+}
+
+type StacktracesReport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Query      *StacktracesQuery      `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Partitions []*StacktracePartition `protobuf:"bytes,2,rep,name=partitions,proto3" json:"partitions,omitempty"`
+}
+
+func (x *StacktracesReport) Reset() {
+	*x = StacktracesReport{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[66]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StacktracesReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StacktracesReport) ProtoMessage() {}
+
+func (x *StacktracesReport) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[66]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StacktracesReport.ProtoReflect.Descriptor instead.
+func (*StacktracesReport) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{66}
+}
+
+func (x *StacktracesReport) GetQuery() *StacktracesQuery {
+	if x != nil {
+		return x.Query
+	}
+	return nil
+}
+
+func (x *StacktracesReport) GetPartitions() []*StacktracePartition {
+	if x != nil {
+		return x.Partitions
+	}
+	return nil
+}
+
+// StacktracePartition holds the sample values of the stack traces of a
+// stack trace partition. Stack trace IDs are only meaningful within the
+// partition of the dataset, therefore the values are not merged across
+// the partitions, datasets, and blocks.
+type StacktracePartition struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BlockId   string `protobuf:"bytes,1,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+	TenantId  string `protobuf:"bytes,2,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	Dataset   string `protobuf:"bytes,3,opt,name=dataset,proto3" json:"dataset,omitempty"`
+	Partition uint64 `protobuf:"varint,4,opt,name=partition,proto3" json:"partition,omitempty"`
+	// Stack trace IDs in ascending order.
+	StacktraceIds []uint32 `protobuf:"varint,5,rep,packed,name=stacktrace_ids,json=stacktraceIds,proto3" json:"stacktrace_ids,omitempty"`
+	// Total sample values of the stack traces.
+	Values []int64 `protobuf:"varint,6,rep,packed,name=values,proto3" json:"values,omitempty"`
+	// Names of the frames of the stack traces: the function names, or the
+	// location addresses, if the partition has not been symbolized.
+	Names []string `protobuf:"bytes,7,rep,name=names,proto3" json:"names,omitempty"`
+	// Frames of the stack traces, in the order of stacktrace_ids.
+	Stacktraces []*StacktraceFrames `protobuf:"bytes,8,rep,name=stacktraces,proto3" json:"stacktraces,omitempty"`
+}
+
+func (x *StacktracePartition) Reset() {
+	*x = StacktracePartition{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[67]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StacktracePartition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StacktracePartition) ProtoMessage() {}
+
+func (x *StacktracePartition) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[67]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StacktracePartition.ProtoReflect.Descriptor instead.
+func (*StacktracePartition) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{67}
+}
+
+func (x *StacktracePartition) GetBlockId() string {
+	if x != nil {
+		return x.BlockId
+	}
+	return ""
+}
+
+func (x *StacktracePartition) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+func (x *StacktracePartition) GetDataset() string {
+	if x != nil {
+		return x.Dataset
+	}
+	return ""
+}
+
+func (x *StacktracePartition) GetPartition() uint64 {
+	if x != nil {
+		return x.Partition
+	}
+	return 0
+}
+
+func (x *StacktracePartition) GetStacktraceIds() []uint32 {
+	if x != nil {
+		return x.StacktraceIds
+	}
+	return nil
+}
+
+func (x *StacktracePartition) GetValues() []int64 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+func (x *StacktracePartition) GetNames() []string {
+	if x != nil {
+		return x.Names
+	}
+	return nil
+}
+
+func (x *StacktracePartition) GetStacktraces() []*StacktraceFrames {
+	if x != nil {
+		return x.Stacktraces
+	}
+	return nil
+}
+
+type StacktraceFrames struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Indices of the frame names, root first.
+	Frames []int32 `protobuf:"varint,1,rep,packed,name=frames,proto3" json:"frames,omitempty"`
+}
+
+func (x *StacktraceFrames) Reset() {
+	*x = StacktraceFrames{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[68]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StacktraceFrames) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StacktraceFrames) ProtoMessage() {}
+
+func (x *StacktraceFrames) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[68]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StacktraceFrames.ProtoReflect.Descriptor instead.
+func (*StacktraceFrames) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{68}
+}
+
+func (x *StacktraceFrames) GetFrames() []int32 {
+	if x != nil {
+		return x.Frames
+	}
+	return nil
+}
+
+// StackDepthQuery buckets the depths of the stack traces of the matching
+// profiles into a histogram. The depth is the number of the locations of
+// the stack trace as stored: inlined functions are not accounted, and the
+// resolve depth limit of the query backend does not apply.
+type StackDepthQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Inclusive upper bounds of the histogram buckets, in ascending order.
+	// Depths above the last bound fall into the overflow bucket. If not set,
+	// the powers of two from 1 to 8192 are used.
+	Bounds []int64 `protobuf:"varint,1,rep,packed,name=bounds,proto3" json:"bounds,omitempty"`
+}
+
+func (x *StackDepthQuery) Reset() {
+	*x = StackDepthQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[69]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StackDepthQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StackDepthQuery) ProtoMessage() {}
+
+func (x *StackDepthQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[69]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StackDepthQuery.ProtoReflect.Descriptor instead.
+func (*StackDepthQuery) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{69}
+}
+
+func (x *StackDepthQuery) GetBounds() []int64 {
+	if x != nil {
+		return x.Bounds
+	}
+	return nil
+}
+
+type StackDepthReport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Query *StackDepthQuery `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// Bounds of the buckets the histogram is built with.
+	Bounds []int64 `protobuf:"varint,2,rep,packed,name=bounds,proto3" json:"bounds,omitempty"`
+	// Buckets of the histogram, one per bound, followed
+	// by the overflow bucket.
+	Buckets []*StackDepthBucket `protobuf:"bytes,3,rep,name=buckets,proto3" json:"buckets,omitempty"`
+	// The largest stack trace depth observed.
+	MaxDepth int64 `protobuf:"varint,4,opt,name=max_depth,json=maxDepth,proto3" json:"max_depth,omitempty"`
+}
+
+func (x *StackDepthReport) Reset() {
+	*x = StackDepthReport{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[70]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StackDepthReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StackDepthReport) ProtoMessage() {}
+
+func (x *StackDepthReport) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[70]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StackDepthReport.ProtoReflect.Descriptor instead.
+func (*StackDepthReport) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{70}
+}
+
+func (x *StackDepthReport) GetQuery() *StackDepthQuery {
+	if x != nil {
+		return x.Query
+	}
+	return nil
+}
+
+func (x *StackDepthReport) GetBounds() []int64 {
+	if x != nil {
+		return x.Bounds
+	}
+	return nil
+}
+
+func (x *StackDepthReport) GetBuckets() []*StackDepthBucket {
+	if x != nil {
+		return x.Buckets
+	}
+	return nil
+}
+
+func (x *StackDepthReport) GetMaxDepth() int64 {
+	if x != nil {
+		return x.MaxDepth
+	}
+	return 0
+}
+
+type StackDepthBucket struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Number of the distinct stack traces. A stack trace present in
+	// multiple datasets or blocks is accounted in each of them.
+	Stacktraces int64 `protobuf:"varint,1,opt,name=stacktraces,proto3" json:"stacktraces,omitempty"`
+	// Total sample value of the stack traces.
+	Value int64 `protobuf:"varint,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *StackDepthBucket) Reset() {
+	*x = StackDepthBucket{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[71]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StackDepthBucket) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StackDepthBucket) ProtoMessage() {}
+
+func (x *StackDepthBucket) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[71]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StackDepthBucket.ProtoReflect.Descriptor instead.
+func (*StackDepthBucket) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{71}
+}
+
+func (x *StackDepthBucket) GetStacktraces() int64 {
+	if x != nil {
+		return x.Stacktraces
+	}
+	return 0
+}
+
+func (x *StackDepthBucket) GetValue() int64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+// ProfileDeviationQuery compares the profile taken at the given time with
+// the average profile of its cohort: the rest of the profiles selected by
+// the label selector of the request within the time range of the request.
+type ProfileDeviationQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Time of the target profile, in milliseconds since epoch. If several
+	// profiles are taken at the time, e.g. of multiple series, the target
+	// is their average.
+	ProfileTime int64 `protobuf:"varint,1,opt,name=profile_time,json=profileTime,proto3" json:"profile_time,omitempty"`
+	// Maximum number of the nodes. If not positive, all the nodes are
+	// reported.
+	MaxNodes int64 `protobuf:"varint,2,opt,name=max_nodes,json=maxNodes,proto3" json:"max_nodes,omitempty"`
+}
+
+func (x *ProfileDeviationQuery) Reset() {
+	*x = ProfileDeviationQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[72]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProfileDeviationQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProfileDeviationQuery) ProtoMessage() {}
+
+func (x *ProfileDeviationQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[72]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProfileDeviationQuery.ProtoReflect.Descriptor instead.
+func (*ProfileDeviationQuery) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{72}
+}
+
+func (x *ProfileDeviationQuery) GetProfileTime() int64 {
+	if x != nil {
+		return x.ProfileTime
+	}
+	return 0
+}
+
+func (x *ProfileDeviationQuery) GetMaxNodes() int64 {
+	if x != nil {
+		return x.MaxNodes
+	}
+	return 0
+}
+
+type ProfileDeviationReport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Query *ProfileDeviationQuery `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// Tree of the target profiles, and the tree of all the profiles, the
+	// target ones included. Partial reports are merged by the trees and the
+	// numbers of the profiles: the deviation is only computed for the merged
+	// ones. The trees are not truncated.
+	TargetTree     []byte `protobuf:"bytes,2,opt,name=target_tree,json=targetTree,proto3" json:"target_tree,omitempty"`
+	Tree           []byte `protobuf:"bytes,3,opt,name=tree,proto3" json:"tree,omitempty"`
+	TargetProfiles uint64 `protobuf:"varint,4,opt,name=target_profiles,json=targetProfiles,proto3" json:"target_profiles,omitempty"`
+	Profiles       uint64 `protobuf:"varint,5,opt,name=profiles,proto3" json:"profiles,omitempty"`
+	// Deviation of the target from the cohort average, per node of the tree.
+	// A node refers to its parent by the index; the parent always precedes
+	// the children. Only set in the aggregated report.
+	Nodes []*ProfileDeviationNode `protobuf:"bytes,6,rep,name=nodes,proto3" json:"nodes,omitempty"`
+}
+
+func (x *ProfileDeviationReport) Reset() {
+	*x = ProfileDeviationReport{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[73]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProfileDeviationReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProfileDeviationReport) ProtoMessage() {}
+
+func (x *ProfileDeviationReport) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[73]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProfileDeviationReport.ProtoReflect.Descriptor instead.
+func (*ProfileDeviationReport) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{73}
+}
+
+func (x *ProfileDeviationReport) GetQuery() *ProfileDeviationQuery {
+	if x != nil {
+		return x.Query
+	}
+	return nil
+}
+
+func (x *ProfileDeviationReport) GetTargetTree() []byte {
+	if x != nil {
+		return x.TargetTree
+	}
+	return nil
+}
+
+func (x *ProfileDeviationReport) GetTree() []byte {
+	if x != nil {
+		return x.Tree
+	}
+	return nil
+}
+
+func (x *ProfileDeviationReport) GetTargetProfiles() uint64 {
+	if x != nil {
+		return x.TargetProfiles
+	}
+	return 0
+}
+
+func (x *ProfileDeviationReport) GetProfiles() uint64 {
+	if x != nil {
+		return x.Profiles
+	}
+	return 0
+}
+
+func (x *ProfileDeviationReport) GetNodes() []*ProfileDeviationNode {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+type ProfileDeviationNode struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Index of the parent node, -1 for the root nodes.
+	Parent int32  `protobuf:"varint,1,opt,name=parent,proto3" json:"parent,omitempty"`
+	Name   string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Average total values of the node in the target
+	// profiles and in the profiles of the cohort.
+	Target  float64 `protobuf:"fixed64,3,opt,name=target,proto3" json:"target,omitempty"`
+	Average float64 `protobuf:"fixed64,4,opt,name=average,proto3" json:"average,omitempty"`
+	// The target value less the average value.
+	Deviation float64 `protobuf:"fixed64,5,opt,name=deviation,proto3" json:"deviation,omitempty"`
+}
+
+func (x *ProfileDeviationNode) Reset() {
+	*x = ProfileDeviationNode{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[74]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProfileDeviationNode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProfileDeviationNode) ProtoMessage() {}
+
+func (x *ProfileDeviationNode) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[74]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProfileDeviationNode.ProtoReflect.Descriptor instead.
+func (*ProfileDeviationNode) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{74}
+}
+
+func (x *ProfileDeviationNode) GetParent() int32 {
+	if x != nil {
+		return x.Parent
+	}
+	return 0
+}
+
+func (x *ProfileDeviationNode) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ProfileDeviationNode) GetTarget() float64 {
+	if x != nil {
+		return x.Target
+	}
+	return 0
+}
+
+func (x *ProfileDeviationNode) GetAverage() float64 {
+	if x != nil {
+		return x.Average
+	}
+	return 0
+}
+
+func (x *ProfileDeviationNode) GetDeviation() float64 {
+	if x != nil {
+		return x.Deviation
+	}
+	return 0
+}
+
+// ProfileSimilarityQuery scores the similarity of the hot sets of the
+// profiles selected by two label selectors. A hot set is the set of the
+// top_k functions with the highest self values. The selectors apply in
+// addition to the label selector of the request.
+type ProfileSimilarityQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	LeftSelector  string `protobuf:"bytes,1,opt,name=left_selector,json=leftSelector,proto3" json:"left_selector,omitempty"`
+	RightSelector string `protobuf:"bytes,2,opt,name=right_selector,json=rightSelector,proto3" json:"right_selector,omitempty"`
+	// Number of the functions in the hot set of each side. Zero means
+	// that all the functions with non-zero self values are included.
+	TopK   int64                   `protobuf:"varint,3,opt,name=top_k,json=topK,proto3" json:"top_k,omitempty"`
+	Metric ProfileSimilarityMetric `protobuf:"varint,4,opt,name=metric,proto3,enum=querybackend.v1.ProfileSimilarityMetric" json:"metric,omitempty"`
+}
+
+func (x *ProfileSimilarityQuery) Reset() {
+	*x = ProfileSimilarityQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[75]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProfileSimilarityQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProfileSimilarityQuery) ProtoMessage() {}
+
+func (x *ProfileSimilarityQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[75]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProfileSimilarityQuery.ProtoReflect.Descriptor instead.
+func (*ProfileSimilarityQuery) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{75}
+}
+
+func (x *ProfileSimilarityQuery) GetLeftSelector() string {
+	if x != nil {
+		return x.LeftSelector
+	}
+	return ""
+}
+
+func (x *ProfileSimilarityQuery) GetRightSelector() string {
+	if x != nil {
+		return x.RightSelector
+	}
+	return ""
+}
+
+func (x *ProfileSimilarityQuery) GetTopK() int64 {
+	if x != nil {
+		return x.TopK
+	}
+	return 0
+}
+
+func (x *ProfileSimilarityQuery) GetMetric() ProfileSimilarityMetric {
+	if x != nil {
+		return x.Metric
+	}
+	return ProfileSimilarityMetric_PROFILE_SIMILARITY_METRIC_JACCARD
+}
+
+type ProfileSimilarityReport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Query *ProfileSimilarityQuery `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// Self values of the functions on both sides. Partial reports include
+	// all the functions, and are merged by them: the hot sets and the score
+	// are only derived once the reports are merged. The functions of the
+	// union of the hot sets are retained, ordered by name.
+	Functions []*SimilarityFunction `protobuf:"bytes,2,rep,name=functions,proto3" json:"functions,omitempty"`
+	// The score is in the range [0, 1]. Zero, if either
+	// of the sides has no functions.
+	Score float64 `protobuf:"fixed64,3,opt,name=score,proto3" json:"score,omitempty"`
+}
+
+func (x *ProfileSimilarityReport) Reset() {
+	*x = ProfileSimilarityReport{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[76]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProfileSimilarityReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProfileSimilarityReport) ProtoMessage() {}
+
+func (x *ProfileSimilarityReport) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[76]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProfileSimilarityReport.ProtoReflect.Descriptor instead.
+func (*ProfileSimilarityReport) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{76}
+}
+
+func (x *ProfileSimilarityReport) GetQuery() *ProfileSimilarityQuery {
+	if x != nil {
+		return x.Query
+	}
+	return nil
+}
+
+func (x *ProfileSimilarityReport) GetFunctions() []*SimilarityFunction {
+	if x != nil {
+		return x.Functions
+	}
+	return nil
+}
+
+func (x *ProfileSimilarityReport) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+type SimilarityFunction struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name      string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	LeftSelf  int64  `protobuf:"varint,2,opt,name=left_self,json=leftSelf,proto3" json:"left_self,omitempty"`
+	RightSelf int64  `protobuf:"varint,3,opt,name=right_self,json=rightSelf,proto3" json:"right_self,omitempty"`
+	// Whether the function is in the hot set of the side.
+	LeftHot  bool `protobuf:"varint,4,opt,name=left_hot,json=leftHot,proto3" json:"left_hot,omitempty"`
+	RightHot bool `protobuf:"varint,5,opt,name=right_hot,json=rightHot,proto3" json:"right_hot,omitempty"`
+}
+
+func (x *SimilarityFunction) Reset() {
+	*x = SimilarityFunction{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[77]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SimilarityFunction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SimilarityFunction) ProtoMessage() {}
+
+func (x *SimilarityFunction) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[77]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SimilarityFunction.ProtoReflect.Descriptor instead.
+func (*SimilarityFunction) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{77}
+}
+
+func (x *SimilarityFunction) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SimilarityFunction) GetLeftSelf() int64 {
+	if x != nil {
+		return x.LeftSelf
+	}
+	return 0
+}
+
+func (x *SimilarityFunction) GetRightSelf() int64 {
+	if x != nil {
+		return x.RightSelf
+	}
+	return 0
+}
+
+func (x *SimilarityFunction) GetLeftHot() bool {
+	if x != nil {
+		return x.LeftHot
+	}
+	return false
+}
+
+func (x *SimilarityFunction) GetRightHot() bool {
+	if x != nil {
+		return x.RightHot
+	}
+	return false
+}
+
+// TopCallersQuery ranks the callers of the function: the functions
+// of the stack traces passing through it, that call it.
+type TopCallersQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Name of the target function.
+	Function string `protobuf:"bytes,1,opt,name=function,proto3" json:"function,omitempty"`
+	// If set, all the functions the target function is called
+	// from, directly or indirectly, are ranked. Otherwise, only
+	// the immediate callers are.
+	Transitive bool `protobuf:"varint,2,opt,name=transitive,proto3" json:"transitive,omitempty"`
+	// Maximum number of the callers in the report: the ones
+	// with the highest values are retained. Zero means no limit.
+	Limit int64 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+}
+
+func (x *TopCallersQuery) Reset() {
+	*x = TopCallersQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[78]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TopCallersQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopCallersQuery) ProtoMessage() {}
+
+func (x *TopCallersQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[78]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopCallersQuery.ProtoReflect.Descriptor instead.
+func (*TopCallersQuery) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{78}
+}
+
+func (x *TopCallersQuery) GetFunction() string {
+	if x != nil {
+		return x.Function
+	}
+	return ""
+}
+
+func (x *TopCallersQuery) GetTransitive() bool {
+	if x != nil {
+		return x.Transitive
+	}
+	return false
+}
+
+func (x *TopCallersQuery) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type TopCallersReport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Query *TopCallersQuery `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// Callers ordered by value, descending, and by name. Partial
+	// reports include all the callers, and are merged by name.
+	Callers []*TopCaller `protobuf:"bytes,2,rep,name=callers,proto3" json:"callers,omitempty"`
+	// Total value of the stack traces passing through the function.
+	Total int64 `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
+}
+
+func (x *TopCallersReport) Reset() {
+	*x = TopCallersReport{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[79]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TopCallersReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopCallersReport) ProtoMessage() {}
+
+func (x *TopCallersReport) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[79]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopCallersReport.ProtoReflect.Descriptor instead.
+func (*TopCallersReport) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{79}
+}
+
+func (x *TopCallersReport) GetQuery() *TopCallersQuery {
+	if x != nil {
+		return x.Query
+	}
+	return nil
+}
+
+func (x *TopCallersReport) GetCallers() []*TopCaller {
+	if x != nil {
+		return x.Callers
+	}
+	return nil
+}
+
+func (x *TopCallersReport) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type TopCaller struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Total value of the stack traces, in which the function calls the
+	// target function. Recursive calls are merged: a caller contributes
+	// once per stack trace. Self-calls of the target are not included.
+	Value int64 `protobuf:"varint,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *TopCaller) Reset() {
+	*x = TopCaller{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[80]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TopCaller) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopCaller) ProtoMessage() {}
+
+func (x *TopCaller) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[80]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopCaller.ProtoReflect.Descriptor instead.
+func (*TopCaller) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{80}
+}
+
+func (x *TopCaller) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TopCaller) GetValue() int64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+// TopProfilesQuery ranks the individual profiles matching
+// the query by the total value.
+type TopProfilesQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Maximum number of the profiles in the report: the ones
+	// with the highest totals are retained. Must be positive.
+	Limit int64 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	// If set, the trees of the profiles are included.
+	IncludeTrees bool `protobuf:"varint,2,opt,name=include_trees,json=includeTrees,proto3" json:"include_trees,omitempty"`
+	// Maximum number of the nodes of the profile trees.
+	// Zero means no limit.
+	MaxNodes int64 `protobuf:"varint,3,opt,name=max_nodes,json=maxNodes,proto3" json:"max_nodes,omitempty"`
+}
+
+func (x *TopProfilesQuery) Reset() {
+	*x = TopProfilesQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[81]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TopProfilesQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopProfilesQuery) ProtoMessage() {}
+
+func (x *TopProfilesQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[81]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopProfilesQuery.ProtoReflect.Descriptor instead.
+func (*TopProfilesQuery) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{81}
+}
+
+func (x *TopProfilesQuery) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *TopProfilesQuery) GetIncludeTrees() bool {
+	if x != nil {
+		return x.IncludeTrees
+	}
+	return false
+}
+
+func (x *TopProfilesQuery) GetMaxNodes() int64 {
+	if x != nil {
+		return x.MaxNodes
+	}
+	return 0
+}
+
+type TopProfilesReport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Query *TopProfilesQuery `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// Profiles ordered by total, descending, then by
+	// timestamp and ID. At most limit profiles.
+	Profiles []*TopProfile `protobuf:"bytes,2,rep,name=profiles,proto3" json:"profiles,omitempty"`
+}
+
+func (x *TopProfilesReport) Reset() {
+	*x = TopProfilesReport{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[82]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TopProfilesReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopProfilesReport) ProtoMessage() {}
+
+func (x *TopProfilesReport) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[82]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopProfilesReport.ProtoReflect.Descriptor instead.
+func (*TopProfilesReport) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{82}
+}
+
+func (x *TopProfilesReport) GetQuery() *TopProfilesQuery {
+	if x != nil {
+		return x.Query
+	}
+	return nil
+}
+
+func (x *TopProfilesReport) GetProfiles() []*TopProfile {
+	if x != nil {
+		return x.Profiles
+	}
+	return nil
+}
+
+type TopProfile struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Profile ID, if known.
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Profile timestamp, in milliseconds.
+	Timestamp int64            `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Labels    []*v11.LabelPair `protobuf:"bytes,3,rep,name=labels,proto3" json:"labels,omitempty"`
+	Total     int64            `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"`
+	// Only set if include_trees is set.
+	Tree []byte `protobuf:"bytes,5,opt,name=tree,proto3" json:"tree,omitempty"`
+}
+
+func (x *TopProfile) Reset() {
+	*x = TopProfile{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_querybackend_v1_querybackend_proto_msgTypes[83]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TopProfile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopProfile) ProtoMessage() {}
+
+func (x *TopProfile) ProtoReflect() protoreflect.Message {
+	mi := &file_querybackend_v1_querybackend_proto_msgTypes[83]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopProfile.ProtoReflect.Descriptor instead.
+func (*TopProfile) Descriptor() ([]byte, []int) {
+	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{83}
+}
+
+func (x *TopProfile) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TopProfile) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *TopProfile) GetLabels() []*v11.LabelPair {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *TopProfile) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *TopProfile) GetTree() []byte {
+	if x != nil {
+		return x.Tree
+	}
+	return nil
+}
+
+var File_querybackend_v1_querybackend_proto protoreflect.FileDescriptor
+
+var file_querybackend_v1_querybackend_proto_rawDesc = []byte{
+	0x0a, 0x22, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2f, 0x76,
+	0x31, 0x2f, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0f, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65,
+	0x6e, 0x64, 0x2e, 0x76, 0x31, 0x1a, 0x17, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x76, 0x31,
+	0x2f, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1c,
+	0x6d, 0x65, 0x74, 0x61, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x2f, 0x76, 0x31, 0x2f, 0x6d, 0x65, 0x74,
+	0x61, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x18, 0x71, 0x75,
+	0x65, 0x72, 0x69, 0x65, 0x72, 0x2f, 0x76, 0x31, 0x2f, 0x71, 0x75, 0x65, 0x72, 0x69, 0x65, 0x72,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x14, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2f, 0x76, 0x31,
+	0x2f, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x89, 0x04, 0x0a,
+	0x0d, 0x49, 0x6e, 0x76, 0x6f, 0x6b, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x28,
+	0x0a, 0x10, 0x6d, 0x61, 0x78, 0x5f, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x72, 0x65, 0x70, 0x6f, 0x72,
+	0x74, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e, 0x6d, 0x61, 0x78, 0x54, 0x72, 0x65,
+	0x65, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x12, 0x33, 0x0a, 0x16, 0x66, 0x61, 0x69, 0x6c,
+	0x5f, 0x6f, 0x6e, 0x5f, 0x73, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x5f, 0x62, 0x6c, 0x6f, 0x63,
+	0x6b, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x13, 0x66, 0x61, 0x69, 0x6c, 0x4f, 0x6e,
+	0x53, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x12, 0x2f, 0x0a,
+	0x14, 0x6d, 0x61, 0x78, 0x5f, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74,
+	0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x11, 0x6d, 0x61, 0x78,
+	0x54, 0x72, 0x65, 0x65, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x14,
+	0x0a, 0x05, 0x62, 0x61, 0x74, 0x63, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x62,
+	0x61, 0x74, 0x63, 0x68, 0x12, 0x19, 0x0a, 0x08, 0x71, 0x75, 0x65, 0x72, 0x79, 0x5f, 0x69, 0x64,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x71, 0x75, 0x65, 0x72, 0x79, 0x49, 0x64, 0x12,
+	0x29, 0x0a, 0x10, 0x76, 0x65, 0x72, 0x69, 0x66, 0x79, 0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73,
+	0x75, 0x6d, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x76, 0x65, 0x72, 0x69, 0x66,
+	0x79, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x73, 0x12, 0x2a, 0x0a, 0x11, 0x73, 0x74,
+	0x72, 0x65, 0x61, 0x6d, 0x5f, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x43, 0x68, 0x75,
+	0x6e, 0x6b, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x69, 0x6e, 0x5f, 0x62, 0x6c,
+	0x6f, 0x63, 0x6b, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x70, 0x69, 0x6e, 0x42,
+	0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c,
+	0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x70, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x12,
+	0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x61, 0x64, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x73, 0x18, 0x0a, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x09, 0x72, 0x65, 0x61, 0x64, 0x48, 0x65, 0x61, 0x64, 0x73, 0x12, 0x24,
+	0x0a, 0x0e, 0x70, 0x6c, 0x61, 0x6e, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x73,
+	0x18, 0x0b, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x70, 0x6c, 0x61, 0x6e, 0x42, 0x6c, 0x6f, 0x63,
+	0x6b, 0x49, 0x64, 0x73, 0x12, 0x32, 0x0a, 0x15, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x73, 0x74, 0x72,
+	0x69, 0x6e, 0x67, 0x5f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x0c, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x13, 0x74, 0x72, 0x65, 0x65, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x49,
+	0x6e, 0x74, 0x65, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x2e, 0x0a, 0x13, 0x73, 0x74, 0x72, 0x65,
+	0x61, 0x6d, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6d, 0x65, 0x5f, 0x61, 0x66, 0x74, 0x65, 0x72, 0x18,
+	0x0d, 0x20, 0x01, 0x28, 0x03, 0x52, 0x11, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73,
+	0x75, 0x6d, 0x65, 0x41, 0x66, 0x74, 0x65, 0x72, 0x22, 0xc8, 0x02, 0x0a, 0x0d, 0x49, 0x6e, 0x76,
+	0x6f, 0x6b, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x65,
+	0x6e, 0x61, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x74, 0x65, 0x6e, 0x61,
+	0x6e, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d,
+	0x65, 0x12, 0x19, 0x0a, 0x08, 0x65, 0x6e, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x07, 0x65, 0x6e, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x25, 0x0a, 0x0e,
+	0x6c, 0x61, 0x62, 0x65, 0x6c, 0x5f, 0x73, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x53, 0x65, 0x6c, 0x65, 0x63,
+	0x74, 0x6f, 0x72, 0x12, 0x2c, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x05, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x16, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e,
+	0x64, 0x2e, 0x76, 0x31, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72,
+	0x79, 0x12, 0x39, 0x0a, 0x0a, 0x71, 0x75, 0x65, 0x72, 0x79, 0x5f, 0x70, 0x6c, 0x61, 0x6e, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63,
+	0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x50, 0x6c, 0x61,
+	0x6e, 0x52, 0x09, 0x71, 0x75, 0x65, 0x72, 0x79, 0x50, 0x6c, 0x61, 0x6e, 0x12, 0x38, 0x0a, 0x07,
+	0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e,
+	0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e,
+	0x49, 0x6e, 0x76, 0x6f, 0x6b, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x07, 0x6f,
+	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f,
+	0x69, 0x64, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b,
+	0x49, 0x64, 0x73, 0x22, 0x52, 0x0a, 0x09, 0x51, 0x75, 0x65, 0x72, 0x79, 0x50, 0x6c, 0x61, 0x6e,
+	0x12, 0x14, 0x0a, 0x05, 0x67, 0x72, 0x61, 0x70, 0x68, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0d, 0x52,
+	0x05, 0x67, 0x72, 0x61, 0x70, 0x68, 0x12, 0x2f, 0x0a, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x73, 0x74, 0x6f,
+	0x72, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x4d, 0x65, 0x74, 0x61, 0x52,
+	0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x22, 0x99, 0x0b, 0x0a, 0x05, 0x51, 0x75, 0x65, 0x72,
+	0x79, 0x12, 0x39, 0x0a, 0x0a, 0x71, 0x75, 0x65, 0x72, 0x79, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1a, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63,
+	0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x54, 0x79, 0x70,
+	0x65, 0x52, 0x09, 0x71, 0x75, 0x65, 0x72, 0x79, 0x54, 0x79, 0x70, 0x65, 0x12, 0x41, 0x0a, 0x0b,
+	0x6c, 0x61, 0x62, 0x65, 0x6c, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x20, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64,
+	0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x51, 0x75,
+	0x65, 0x72, 0x79, 0x52, 0x0a, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x12,
+	0x44, 0x0a, 0x0c, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63,
+	0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x56, 0x61, 0x6c,
+	0x75, 0x65, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x0b, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x56,
+	0x61, 0x6c, 0x75, 0x65, 0x73, 0x12, 0x47, 0x0a, 0x0d, 0x73, 0x65, 0x72, 0x69, 0x65, 0x73, 0x5f,
+	0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x71,
+	0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x53,
+	0x65, 0x72, 0x69, 0x65, 0x73, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79,
+	0x52, 0x0c, 0x73, 0x65, 0x72, 0x69, 0x65, 0x73, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x12, 0x41,
+	0x0a, 0x0b, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x73, 0x65, 0x72, 0x69, 0x65, 0x73, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65,
+	0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x65, 0x72, 0x69, 0x65, 0x73,
+	0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x0a, 0x74, 0x69, 0x6d, 0x65, 0x53, 0x65, 0x72, 0x69, 0x65,
+	0x73, 0x12, 0x2e, 0x0a, 0x04, 0x74, 0x72, 0x65, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76,
+	0x31, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x04, 0x74, 0x72, 0x65,
+	0x65, 0x12, 0x3e, 0x0a, 0x0a, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63,
+	0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x52, 0x61, 0x6e, 0x67,
+	0x65, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x52, 0x61, 0x6e, 0x67,
+	0x65, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x06, 0x63, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x12, 0x3e, 0x0a, 0x0a, 0x63, 0x61, 0x6c,
+	0x6c, 0x5f, 0x67, 0x72, 0x61, 0x70, 0x68, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e,
+	0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e,
+	0x43, 0x61, 0x6c, 0x6c, 0x47, 0x72, 0x61, 0x70, 0x68, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x09,
+	0x63, 0x61, 0x6c, 0x6c, 0x47, 0x72, 0x61, 0x70, 0x68, 0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x69, 0x6d,
+	0x65, 0x6f, 0x75, 0x74, 0x5f, 0x6d, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x74,
+	0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x4d, 0x73, 0x12, 0x3b, 0x0a, 0x09, 0x73, 0x65, 0x6c, 0x66,
+	0x5f, 0x74, 0x65, 0x73, 0x74, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x71, 0x75,
+	0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65,
+	0x6c, 0x66, 0x54, 0x65, 0x73, 0x74, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x08, 0x73, 0x65, 0x6c,
+	0x66, 0x54, 0x65, 0x73, 0x74, 0x12, 0x3a, 0x0a, 0x08, 0x6d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73,
+	0x74, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62,
+	0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x61, 0x6e, 0x69, 0x66, 0x65,
+	0x73, 0x74, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x08, 0x6d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73,
+	0x74, 0x12, 0x44, 0x0a, 0x0c, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x5f, 0x74, 0x61, 0x62, 0x6c,
+	0x65, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62,
+	0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c,
+	0x54, 0x61, 0x62, 0x6c, 0x65, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x0b, 0x73, 0x79, 0x6d, 0x62,
+	0x6f, 0x6c, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x50, 0x0a, 0x10, 0x66, 0x75, 0x6e, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x5f, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x0e, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x25, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64,
+	0x2e, 0x76, 0x31, 0x2e, 0x46, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x68, 0x61, 0x6e,
+	0x67, 0x65, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x0f, 0x66, 0x75, 0x6e, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x12, 0x4d, 0x0a, 0x0f, 0x66, 0x6c, 0x61,
+	0x6d, 0x65, 0x67, 0x72, 0x61, 0x70, 0x68, 0x5f, 0x64, 0x69, 0x66, 0x66, 0x18, 0x0f, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x24, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e,
+	0x64, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x6c, 0x61, 0x6d, 0x65, 0x47, 0x72, 0x61, 0x70, 0x68, 0x44,
+	0x69, 0x66, 0x66, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x0e, 0x66, 0x6c, 0x61, 0x6d, 0x65, 0x67,
+	0x72, 0x61, 0x70, 0x68, 0x44, 0x69, 0x66, 0x66, 0x12, 0x43, 0x0a, 0x0b, 0x73, 0x74, 0x61, 0x63,
+	0x6b, 0x74, 0x72, 0x61, 0x63, 0x65, 0x73, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e,
+	0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e,
+	0x53, 0x74, 0x61, 0x63, 0x6b, 0x74, 0x72, 0x61, 0x63, 0x65, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79,
+	0x52, 0x0b, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x74, 0x72, 0x61, 0x63, 0x65, 0x73, 0x12, 0x41, 0x0a,
+	0x0b, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x5f, 0x64, 0x65, 0x70, 0x74, 0x68, 0x18, 0x11, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x20, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e,
+	0x64, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x61, 0x63, 0x6b, 0x44, 0x65, 0x70, 0x74, 0x68, 0x51,
+	0x75, 0x65, 0x72, 0x79, 0x52, 0x0a, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x44, 0x65, 0x70, 0x74, 0x68,
+	0x12, 0x53, 0x0a, 0x11, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x64, 0x65, 0x76, 0x69,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x12, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x71, 0x75,
+	0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72,
+	0x6f, 0x66, 0x69, 0x6c, 0x65, 0x44, 0x65, 0x76, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x51, 0x75,
+	0x65, 0x72, 0x79, 0x52, 0x10, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x44, 0x65, 0x76, 0x69,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x56, 0x0a, 0x12, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65,
+	0x5f, 0x73, 0x69, 0x6d, 0x69, 0x6c, 0x61, 0x72, 0x69, 0x74, 0x79, 0x18, 0x13, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x27, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64,
+	0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x53, 0x69, 0x6d, 0x69, 0x6c,
+	0x61, 0x72, 0x69, 0x74, 0x79, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x11, 0x70, 0x72, 0x6f, 0x66,
+	0x69, 0x6c, 0x65, 0x53, 0x69, 0x6d, 0x69, 0x6c, 0x61, 0x72, 0x69, 0x74, 0x79, 0x12, 0x41, 0x0a,
+	0x0b, 0x74, 0x6f, 0x70, 0x5f, 0x63, 0x61, 0x6c, 0x6c, 0x65, 0x72, 0x73, 0x18, 0x14, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x20, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e,
+	0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x6f, 0x70, 0x43, 0x61, 0x6c, 0x6c, 0x65, 0x72, 0x73, 0x51,
+	0x75, 0x65, 0x72, 0x79, 0x52, 0x0a, 0x74, 0x6f, 0x70, 0x43, 0x61, 0x6c, 0x6c, 0x65, 0x72, 0x73,
+	0x12, 0x44, 0x0a, 0x0c, 0x74, 0x6f, 0x70, 0x5f, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73,
+	0x18, 0x15, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61,
+	0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x6f, 0x70, 0x50, 0x72, 0x6f, 0x66,
+	0x69, 0x6c, 0x65, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x0b, 0x74, 0x6f, 0x70, 0x50, 0x72,
+	0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x3b, 0x0a, 0x09, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x64,
+	0x69, 0x66, 0x66, 0x18, 0x16, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x71, 0x75, 0x65, 0x72,
+	0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x65, 0x65,
+	0x44, 0x69, 0x66, 0x66, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x08, 0x74, 0x72, 0x65, 0x65, 0x44,
+	0x69, 0x66, 0x66, 0x22, 0x9f, 0x01, 0x0a, 0x0e, 0x49, 0x6e, 0x76, 0x6f, 0x6b, 0x65, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x31, 0x0a, 0x07, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62,
+	0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74,
+	0x52, 0x07, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x12, 0x3e, 0x0a, 0x0b, 0x64, 0x69, 0x61,
+	0x67, 0x6e, 0x6f, 0x73, 0x74, 0x69, 0x63, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c,
+	0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31,
+	0x2e, 0x44, 0x69, 0x61, 0x67, 0x6e, 0x6f, 0x73, 0x74, 0x69, 0x63, 0x73, 0x52, 0x0b, 0x64, 0x69,
+	0x61, 0x67, 0x6e, 0x6f, 0x73, 0x74, 0x69, 0x63, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x65, 0x71,
+	0x75, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x73, 0x65, 0x71,
+	0x75, 0x65, 0x6e, 0x63, 0x65, 0x22, 0xcb, 0x01, 0x0a, 0x0b, 0x44, 0x69, 0x61, 0x67, 0x6e, 0x6f,
+	0x73, 0x74, 0x69, 0x63, 0x73, 0x12, 0x44, 0x0a, 0x0e, 0x73, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64,
+	0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e,
+	0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e,
+	0x53, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x0d, 0x73, 0x6b,
+	0x69, 0x70, 0x70, 0x65, 0x64, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x12, 0x30, 0x0a, 0x14, 0x73,
+	0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x5f, 0x63, 0x6f,
+	0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x12, 0x73, 0x6b, 0x69, 0x70, 0x70,
+	0x65, 0x64, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x44, 0x0a,
+	0x0e, 0x66, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x5f, 0x72, 0x65, 0x67, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63,
+	0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x52, 0x65,
+	0x67, 0x69, 0x6f, 0x6e, 0x52, 0x0d, 0x66, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x52, 0x65, 0x67, 0x69,
+	0x6f, 0x6e, 0x73, 0x22, 0x40, 0x0a, 0x0c, 0x46, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x52, 0x65, 0x67,
+	0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x67, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x67, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x6d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x78, 0x0a, 0x0c, 0x53, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64,
+	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x19, 0x0a, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x64,
+	0x12, 0x33, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x1b, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e,
+	0x76, 0x31, 0x2e, 0x53, 0x6b, 0x69, 0x70, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x52, 0x06, 0x72,
+	0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22,
+	0xed, 0x0b, 0x0a, 0x06, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x3c, 0x0a, 0x0b, 0x72, 0x65,
+	0x70, 0x6f, 0x72, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x1b, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76,
+	0x31, 0x2e, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x0a, 0x72, 0x65,
+	0x70, 0x6f, 0x72, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x42, 0x0a, 0x0b, 0x6c, 0x61, 0x62, 0x65,
+	0x6c, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e,
+	0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e,
+	0x4c, 0x61, 0x62, 0x65, 0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74,
+	0x52, 0x0a, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x12, 0x45, 0x0a, 0x0c,
+	0x6c, 0x61, 0x62, 0x65, 0x6c, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x22, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e,
+	0x64, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x73,
+	0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x0b, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x56, 0x61, 0x6c,
+	0x75, 0x65, 0x73, 0x12, 0x48, 0x0a, 0x0d, 0x73, 0x65, 0x72, 0x69, 0x65, 0x73, 0x5f, 0x6c, 0x61,
+	0x62, 0x65, 0x6c, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x71, 0x75, 0x65,
+	0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x72,
+	0x69, 0x65, 0x73, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52,
+	0x0c, 0x73, 0x65, 0x72, 0x69, 0x65, 0x73, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x12, 0x42, 0x0a,
+	0x0b, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x73, 0x65, 0x72, 0x69, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x21, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e,
+	0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x65, 0x72, 0x69, 0x65, 0x73, 0x52,
+	0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x0a, 0x74, 0x69, 0x6d, 0x65, 0x53, 0x65, 0x72, 0x69, 0x65,
+	0x73, 0x12, 0x2f, 0x0a, 0x04, 0x74, 0x72, 0x65, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1b, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76,
+	0x31, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x04, 0x74, 0x72,
+	0x65, 0x65, 0x12, 0x3f, 0x0a, 0x0a, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x72, 0x61, 0x6e, 0x67, 0x65,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61,
+	0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x52, 0x61, 0x6e,
+	0x67, 0x65, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x52, 0x61,
+	0x6e, 0x67, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x06, 0x63, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x12, 0x3f, 0x0a, 0x0a, 0x63,
+	0x61, 0x6c, 0x6c, 0x5f, 0x67, 0x72, 0x61, 0x70, 0x68, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x20, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76,
+	0x31, 0x2e, 0x43, 0x61, 0x6c, 0x6c, 0x47, 0x72, 0x61, 0x70, 0x68, 0x52, 0x65, 0x70, 0x6f, 0x72,
+	0x74, 0x52, 0x09, 0x63, 0x61, 0x6c, 0x6c, 0x47, 0x72, 0x61, 0x70, 0x68, 0x12, 0x3c, 0x0a, 0x09,
+	0x73, 0x65, 0x6c, 0x66, 0x5f, 0x74, 0x65, 0x73, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1f, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76,
+	0x31, 0x2e, 0x53, 0x65, 0x6c, 0x66, 0x54, 0x65, 0x73, 0x74, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74,
+	0x52, 0x08, 0x73, 0x65, 0x6c, 0x66, 0x54, 0x65, 0x73, 0x74, 0x12, 0x3b, 0x0a, 0x08, 0x6d, 0x61,
+	0x6e, 0x69, 0x66, 0x65, 0x73, 0x74, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x71,
+	0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x4d,
+	0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x08, 0x6d,
+	0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74, 0x12, 0x45, 0x0a, 0x0c, 0x73, 0x79, 0x6d, 0x62, 0x6f,
+	0x6c, 0x5f, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e,
+	0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e,
+	0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x52, 0x65, 0x70, 0x6f, 0x72,
+	0x74, 0x52, 0x0b, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x51,
+	0x0a, 0x10, 0x66, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x63, 0x68, 0x61, 0x6e, 0x67,
+	0x65, 0x73, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79,
+	0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x75, 0x6e, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74,
+	0x52, 0x0f, 0x66, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65,
+	0x73, 0x12, 0x4e, 0x0a, 0x0f, 0x66, 0x6c, 0x61, 0x6d, 0x65, 0x67, 0x72, 0x61, 0x70, 0x68, 0x5f,
+	0x64, 0x69, 0x66, 0x66, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x71, 0x75, 0x65,
+	0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x6c, 0x61,
+	0x6d, 0x65, 0x47, 0x72, 0x61, 0x70, 0x68, 0x44, 0x69, 0x66, 0x66, 0x52, 0x65, 0x70, 0x6f, 0x72,
+	0x74, 0x52, 0x0e, 0x66, 0x6c, 0x61, 0x6d, 0x65, 0x67, 0x72, 0x61, 0x70, 0x68, 0x44, 0x69, 0x66,
+	0x66, 0x12, 0x44, 0x0a, 0x0b, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x74, 0x72, 0x61, 0x63, 0x65, 0x73,
+	0x18, 0x0f, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61,
+	0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x61, 0x63, 0x6b, 0x74, 0x72,
+	0x61, 0x63, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x0b, 0x73, 0x74, 0x61, 0x63,
+	0x6b, 0x74, 0x72, 0x61, 0x63, 0x65, 0x73, 0x12, 0x42, 0x0a, 0x0b, 0x73, 0x74, 0x61, 0x63, 0x6b,
+	0x5f, 0x64, 0x65, 0x70, 0x74, 0x68, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x71,
+	0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x53,
+	0x74, 0x61, 0x63, 0x6b, 0x44, 0x65, 0x70, 0x74, 0x68, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52,
+	0x0a, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x44, 0x65, 0x70, 0x74, 0x68, 0x12, 0x54, 0x0a, 0x11, 0x70,
+	0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x64, 0x65, 0x76, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x18, 0x12, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61,
+	0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65,
+	0x44, 0x65, 0x76, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52,
+	0x10, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x44, 0x65, 0x76, 0x69, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x57, 0x0a, 0x12, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x73, 0x69, 0x6d,
+	0x69, 0x6c, 0x61, 0x72, 0x69, 0x74, 0x79, 0x18, 0x14, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x28, 0x2e,
+	0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e,
+	0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x53, 0x69, 0x6d, 0x69, 0x6c, 0x61, 0x72, 0x69, 0x74,
+	0x79, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x11, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65,
+	0x53, 0x69, 0x6d, 0x69, 0x6c, 0x61, 0x72, 0x69, 0x74, 0x79, 0x12, 0x42, 0x0a, 0x0b, 0x74, 0x6f,
+	0x70, 0x5f, 0x63, 0x61, 0x6c, 0x6c, 0x65, 0x72, 0x73, 0x18, 0x15, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x21, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76,
+	0x31, 0x2e, 0x54, 0x6f, 0x70, 0x43, 0x61, 0x6c, 0x6c, 0x65, 0x72, 0x73, 0x52, 0x65, 0x70, 0x6f,
+	0x72, 0x74, 0x52, 0x0a, 0x74, 0x6f, 0x70, 0x43, 0x61, 0x6c, 0x6c, 0x65, 0x72, 0x73, 0x12, 0x45,
+	0x0a, 0x0c, 0x74, 0x6f, 0x70, 0x5f, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x16,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b,
+	0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x6f, 0x70, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c,
+	0x65, 0x73, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x0b, 0x74, 0x6f, 0x70, 0x50, 0x72, 0x6f,
+	0x66, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x3c, 0x0a, 0x09, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x64, 0x69,
+	0x66, 0x66, 0x18, 0x17, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79,
+	0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x44,
+	0x69, 0x66, 0x66, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x08, 0x74, 0x72, 0x65, 0x65, 0x44,
+	0x69, 0x66, 0x66, 0x12, 0x1f, 0x0a, 0x0b, 0x71, 0x75, 0x65, 0x72, 0x79, 0x5f, 0x69, 0x6e, 0x64,
+	0x65, 0x78, 0x18, 0x11, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x71, 0x75, 0x65, 0x72, 0x79, 0x49,
+	0x6e, 0x64, 0x65, 0x78, 0x12, 0x39, 0x0a, 0x0a, 0x71, 0x75, 0x65, 0x72, 0x79, 0x5f, 0x74, 0x79,
+	0x70, 0x65, 0x18, 0x13, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1a, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79,
+	0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79,
+	0x54, 0x79, 0x70, 0x65, 0x52, 0x09, 0x71, 0x75, 0x65, 0x72, 0x79, 0x54, 0x79, 0x70, 0x65, 0x22,
+	0x11, 0x0a, 0x0f, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x51, 0x75, 0x65,
+	0x72, 0x79, 0x22, 0x6b, 0x0a, 0x10, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x73,
+	0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x36, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63,
+	0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x4e, 0x61, 0x6d,
+	0x65, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x1f,
+	0x0a, 0x0b, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x0a, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x22,
+	0x31, 0x0a, 0x10, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x51, 0x75,
+	0x65, 0x72, 0x79, 0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x5f, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x4e, 0x61,
+	0x6d, 0x65, 0x22, 0x6f, 0x0a, 0x11, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65,
+	0x73, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x37, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61,
+	0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x56, 0x61,
+	0x6c, 0x75, 0x65, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79,
+	0x12, 0x21, 0x0a, 0x0c, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x56, 0x61, 0x6c,
+	0x75, 0x65, 0x73, 0x22, 0x34, 0x0a, 0x11, 0x53, 0x65, 0x72, 0x69, 0x65, 0x73, 0x4c, 0x61, 0x62,
+	0x65, 0x6c, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x1f, 0x0a, 0x0b, 0x6c, 0x61, 0x62, 0x65,
+	0x6c, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x6c,
+	0x61, 0x62, 0x65, 0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x22, 0x85, 0x01, 0x0a, 0x12, 0x53, 0x65,
+	0x72, 0x69, 0x65, 0x73, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74,
+	0x12, 0x38, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x22, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76,
+	0x31, 0x2e, 0x53, 0x65, 0x72, 0x69, 0x65, 0x73, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x51, 0x75,
+	0x65, 0x72, 0x79, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x35, 0x0a, 0x0d, 0x73, 0x65,
+	0x72, 0x69, 0x65, 0x73, 0x5f, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x10, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x61, 0x62,
+	0x65, 0x6c, 0x73, 0x52, 0x0c, 0x73, 0x65, 0x72, 0x69, 0x65, 0x73, 0x4c, 0x61, 0x62, 0x65, 0x6c,
+	0x73, 0x22, 0x9c, 0x01, 0x0a, 0x0f, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x65, 0x72, 0x69, 0x65, 0x73,
+	0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x74, 0x65, 0x70, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x04, 0x73, 0x74, 0x65, 0x70, 0x12, 0x19, 0x0a, 0x08, 0x67, 0x72, 0x6f,
+	0x75, 0x70, 0x5f, 0x62, 0x79, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x67, 0x72, 0x6f,
+	0x75, 0x70, 0x42, 0x79, 0x12, 0x4a, 0x0a, 0x0b, 0x61, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x23, 0x2e, 0x74, 0x79, 0x70, 0x65,
+	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x65, 0x72, 0x69, 0x65, 0x73, 0x41,
+	0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x48, 0x00,
+	0x52, 0x0b, 0x61, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x88, 0x01, 0x01,
+	0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x61, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x22, 0x7d, 0x0a, 0x10, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x65, 0x72, 0x69, 0x65, 0x73, 0x52, 0x65,
+	0x70, 0x6f, 0x72, 0x74, 0x12, 0x36, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65,
+	0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x65, 0x72, 0x69, 0x65, 0x73,
+	0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x31, 0x0a, 0x0b,
+	0x74, 0x69, 0x6d, 0x65, 0x5f, 0x73, 0x65, 0x72, 0x69, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x10, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x72,
+	0x69, 0x65, 0x73, 0x52, 0x0a, 0x74, 0x69, 0x6d, 0x65, 0x53, 0x65, 0x72, 0x69, 0x65, 0x73, 0x22,
+	0xc7, 0x0d, 0x0a, 0x09, 0x54, 0x72, 0x65, 0x65, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x1b, 0x0a,
+	0x09, 0x6d, 0x61, 0x78, 0x5f, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x08, 0x6d, 0x61, 0x78, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x6e, 0x61,
+	0x6d, 0x65, 0x5f, 0x73, 0x61, 0x6e, 0x69, 0x74, 0x69, 0x7a, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0d, 0x6e, 0x61, 0x6d, 0x65, 0x53, 0x61, 0x6e, 0x69, 0x74, 0x69, 0x7a, 0x65,
+	0x72, 0x12, 0x20, 0x0a, 0x0b, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x69, 0x6f, 0x6e,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x36, 0x0a, 0x07, 0x72, 0x65, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x18, 0x04,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b,
+	0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x52, 0x75,
+	0x6c, 0x65, 0x52, 0x07, 0x72, 0x65, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x12, 0x23, 0x0a, 0x0d, 0x70,
+	0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x73, 0x18, 0x05, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x0c, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x54, 0x79, 0x70, 0x65, 0x73,
+	0x12, 0x25, 0x0a, 0x0e, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74,
+	0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x69, 0x6e, 0x76, 0x65, 0x72,
+	0x74, 0x65, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x69, 0x6e, 0x76, 0x65, 0x72,
+	0x74, 0x65, 0x64, 0x12, 0x20, 0x0a, 0x0b, 0x61, 0x70, 0x70, 0x72, 0x6f, 0x78, 0x69, 0x6d, 0x61,
+	0x74, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x61, 0x70, 0x70, 0x72, 0x6f, 0x78,
+	0x69, 0x6d, 0x61, 0x74, 0x65, 0x12, 0x2f, 0x0a, 0x13, 0x61, 0x70, 0x70, 0x72, 0x6f, 0x78, 0x69,
+	0x6d, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x09, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x12, 0x61, 0x70, 0x70, 0x72, 0x6f, 0x78, 0x69, 0x6d, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x69, 0x6e, 0x5f, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x6d, 0x69, 0x6e, 0x56, 0x61,
+	0x6c, 0x75, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x56, 0x61, 0x6c, 0x75, 0x65,
+	0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f, 0x64, 0x65, 0x70, 0x74, 0x68, 0x18, 0x0d, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x44, 0x65, 0x70, 0x74, 0x68, 0x12, 0x26, 0x0a,
+	0x0f, 0x64, 0x72, 0x6f, 0x70, 0x5f, 0x7a, 0x65, 0x72, 0x6f, 0x5f, 0x6e, 0x6f, 0x64, 0x65, 0x73,
+	0x18, 0x0e, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x64, 0x72, 0x6f, 0x70, 0x5a, 0x65, 0x72, 0x6f,
+	0x4e, 0x6f, 0x64, 0x65, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x6d, 0x61, 0x78, 0x5f, 0x66, 0x75, 0x6e,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x6d, 0x61,
+	0x78, 0x46, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x29, 0x0a, 0x10, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x5f, 0x65, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x10,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x45, 0x78, 0x70, 0x72, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x68, 0x61, 0x6c, 0x6c, 0x6f, 0x77,
+	0x18, 0x11, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x68, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x12,
+	0x2e, 0x0a, 0x13, 0x73, 0x68, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x65, 0x6e, 0x74, 0x72, 0x79,
+	0x5f, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x18, 0x12, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x73, 0x68,
+	0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x12,
+	0x26, 0x0a, 0x0e, 0x72, 0x65, 0x70, 0x72, 0x65, 0x73, 0x65, 0x6e, 0x74, 0x61, 0x74, 0x69, 0x76,
+	0x65, 0x18, 0x13, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x72, 0x65, 0x70, 0x72, 0x65, 0x73, 0x65,
+	0x6e, 0x74, 0x61, 0x74, 0x69, 0x76, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x65, 0x65, 0x64, 0x18,
+	0x14, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x73, 0x65, 0x65, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x70,
+	0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x15, 0x20, 0x03, 0x28, 0x04, 0x52,
+	0x0a, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x73,
+	0x61, 0x6d, 0x70, 0x6c, 0x65, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x18, 0x16, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0c, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x73,
+	0x12, 0x19, 0x0a, 0x08, 0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x17, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x07, 0x6e, 0x6f, 0x64, 0x65, 0x49, 0x64, 0x73, 0x12, 0x39, 0x0a, 0x08, 0x62,
+	0x61, 0x73, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x18, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e,
+	0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e,
+	0x54, 0x72, 0x65, 0x65, 0x42, 0x61, 0x73, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x52, 0x08, 0x62, 0x61,
+	0x73, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x12, 0x31, 0x0a, 0x14, 0x64, 0x65, 0x64, 0x75, 0x70, 0x6c,
+	0x69, 0x63, 0x61, 0x74, 0x65, 0x5f, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x19,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x13, 0x64, 0x65, 0x64, 0x75, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74,
+	0x65, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x5f, 0x6d, 0x65, 0x72, 0x67, 0x65, 0x18, 0x1a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x4d, 0x65, 0x72, 0x67, 0x65, 0x12, 0x38, 0x0a, 0x18, 0x69, 0x6e,
+	0x63, 0x6c, 0x75, 0x64, 0x65, 0x5f, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x6c, 0x6f, 0x63,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x1b, 0x20, 0x01, 0x28, 0x08, 0x52, 0x16, 0x69, 0x6e,
+	0x63, 0x6c, 0x75, 0x64, 0x65, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4c, 0x6f, 0x63, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x12, 0x2a, 0x0a, 0x11, 0x6d, 0x69, 0x6e, 0x5f, 0x70, 0x72, 0x6f, 0x66,
+	0x69, 0x6c, 0x65, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x1c, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0f, 0x6d, 0x69, 0x6e, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x56, 0x61, 0x6c, 0x75, 0x65,
+	0x12, 0x25, 0x0a, 0x0e, 0x64, 0x6f, 0x6d, 0x69, 0x6e, 0x61, 0x6e, 0x74, 0x5f, 0x6c, 0x61, 0x62,
+	0x65, 0x6c, 0x18, 0x1e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x64, 0x6f, 0x6d, 0x69, 0x6e, 0x61,
+	0x6e, 0x74, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x12, 0x52, 0x0a, 0x11, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x5f, 0x63, 0x6f, 0x6d, 0x62, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x1f, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x25, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e,
+	0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x43, 0x6f,
+	0x6d, 0x62, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x10, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x43, 0x6f, 0x6d, 0x62, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x63,
+	0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x18, 0x20, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x63,
+	0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x12, 0x28, 0x0a, 0x10, 0x67, 0x72, 0x6f, 0x75, 0x70,
+	0x5f, 0x72, 0x6f, 0x6f, 0x74, 0x5f, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x18, 0x21, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0e, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x52, 0x6f, 0x6f, 0x74, 0x4c, 0x61, 0x62, 0x65,
+	0x6c, 0x12, 0x46, 0x0a, 0x0d, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x5f, 0x62, 0x75, 0x63, 0x6b, 0x65,
+	0x74, 0x73, 0x18, 0x22, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79,
+	0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x4c,
+	0x61, 0x62, 0x65, 0x6c, 0x42, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x52, 0x0c, 0x6c, 0x61, 0x62,
+	0x65, 0x6c, 0x42, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x68, 0x6f, 0x74,
+	0x74, 0x65, 0x73, 0x74, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x18, 0x23, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x0b, 0x68, 0x6f, 0x74, 0x74, 0x65, 0x73, 0x74, 0x50, 0x61, 0x74, 0x68, 0x12, 0x25, 0x0a, 0x0e,
+	0x73, 0x74, 0x61, 0x63, 0x6b, 0x5f, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x69, 0x6e, 0x67, 0x18, 0x24,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x47, 0x72, 0x6f, 0x75, 0x70,
+	0x69, 0x6e, 0x67, 0x12, 0x35, 0x0a, 0x17, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x5f, 0x73, 0x61, 0x6d,
+	0x70, 0x6c, 0x65, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x5f, 0x6d, 0x65, 0x72, 0x67, 0x65, 0x18, 0x25,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x14, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x53, 0x61, 0x6d, 0x70, 0x6c,
+	0x65, 0x54, 0x79, 0x70, 0x65, 0x4d, 0x65, 0x72, 0x67, 0x65, 0x12, 0x26, 0x0a, 0x0f, 0x73, 0x75,
+	0x62, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x26, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x0d, 0x73, 0x75, 0x62, 0x74, 0x72, 0x65, 0x65, 0x4e, 0x6f, 0x64, 0x65,
+	0x49, 0x64, 0x12, 0x29, 0x0a, 0x10, 0x67, 0x6f, 0x72, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x65, 0x5f,
+	0x73, 0x74, 0x61, 0x74, 0x65, 0x73, 0x18, 0x27, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x67, 0x6f,
+	0x72, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x73, 0x12, 0x27, 0x0a,
+	0x0f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73,
+	0x18, 0x28, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c,
+	0x56, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x12, 0x29, 0x0a, 0x10, 0x6e, 0x61, 0x6d, 0x65, 0x5f, 0x6e,
+	0x6f, 0x72, 0x6d, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x72, 0x73, 0x18, 0x29, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x0f, 0x6e, 0x61, 0x6d, 0x65, 0x4e, 0x6f, 0x72, 0x6d, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x72,
+	0x73, 0x12, 0x38, 0x0a, 0x06, 0x6c, 0x65, 0x67, 0x65, 0x6e, 0x64, 0x18, 0x2a, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x20, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64,
+	0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x4c, 0x65, 0x67, 0x65, 0x6e, 0x64, 0x51, 0x75,
+	0x65, 0x72, 0x79, 0x52, 0x06, 0x6c, 0x65, 0x67, 0x65, 0x6e, 0x64, 0x12, 0x2a, 0x0a, 0x11, 0x6d,
+	0x69, 0x6e, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x5f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74,
+	0x18, 0x2b, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0f, 0x6d, 0x69, 0x6e, 0x56, 0x61, 0x6c, 0x75, 0x65,
+	0x50, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x4a, 0x04, 0x08, 0x1d, 0x10, 0x1e, 0x52, 0x1a, 0x6d,
+	0x69, 0x6e, 0x5f, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x5f, 0x66, 0x72, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x3f, 0x0a, 0x0f, 0x54, 0x72, 0x65,
+	0x65, 0x4c, 0x65, 0x67, 0x65, 0x6e, 0x64, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x14, 0x0a, 0x05,
+	0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6c, 0x69, 0x6d,
+	0x69, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x6f, 0x6c, 0x6f, 0x72, 0x73, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x06, 0x63, 0x6f, 0x6c, 0x6f, 0x72, 0x73, 0x22, 0x40, 0x0a, 0x10, 0x54, 0x72,
+	0x65, 0x65, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x42, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x12, 0x14,
+	0x0a, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c,
+	0x61, 0x62, 0x65, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x01, 0x52, 0x06, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x22, 0x3e, 0x0a, 0x0a,
+	0x54, 0x72, 0x65, 0x65, 0x42, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x72,
+	0x65, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x74, 0x72, 0x65, 0x65, 0x12, 0x1c,
+	0x0a, 0x09, 0x74, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x09, 0x74, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65, 0x64, 0x22, 0x7d, 0x0a, 0x14,
+	0x54, 0x72, 0x65, 0x65, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x43, 0x6f, 0x6d, 0x62, 0x69, 0x6e, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x10, 0x0a, 0x03, 0x6c, 0x68, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x6c, 0x68, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x72, 0x68, 0x73, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x72, 0x68, 0x73, 0x12, 0x41, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x23, 0x2e, 0x71, 0x75,
+	0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72,
+	0x65, 0x65, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xd7, 0x01, 0x0a, 0x0c,
+	0x54, 0x72, 0x65, 0x65, 0x42, 0x61, 0x73, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x12, 0x12, 0x0a, 0x04,
+	0x74, 0x72, 0x65, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x74, 0x72, 0x65, 0x65,
+	0x12, 0x25, 0x0a, 0x0e, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74,
+	0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x69, 0x6e, 0x5f, 0x64,
+	0x65, 0x6c, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x6d, 0x69, 0x6e, 0x44,
+	0x65, 0x6c, 0x74, 0x61, 0x12, 0x2a, 0x0a, 0x11, 0x6d, 0x69, 0x6e, 0x5f, 0x64, 0x65, 0x6c, 0x74,
+	0x61, 0x5f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x0f, 0x6d, 0x69, 0x6e, 0x44, 0x65, 0x6c, 0x74, 0x61, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74,
+	0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x6f, 0x6c, 0x64, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x73, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x63, 0x6f, 0x6c, 0x64, 0x50, 0x61, 0x74, 0x68, 0x73, 0x12,
+	0x24, 0x0a, 0x0e, 0x6d, 0x61, 0x78, 0x5f, 0x63, 0x6f, 0x6c, 0x64, 0x5f, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0c, 0x6d, 0x61, 0x78, 0x43, 0x6f, 0x6c, 0x64,
+	0x52, 0x61, 0x74, 0x69, 0x6f, 0x22, 0xc3, 0x01, 0x0a, 0x0b, 0x52, 0x65, 0x6c, 0x61, 0x62, 0x65,
+	0x6c, 0x52, 0x75, 0x6c, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f,
+	0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x73, 0x6f,
+	0x75, 0x72, 0x63, 0x65, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x65,
+	0x70, 0x61, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73,
+	0x65, 0x70, 0x61, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x65, 0x67, 0x65,
+	0x78, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x72, 0x65, 0x67, 0x65, 0x78, 0x12, 0x21,
+	0x0a, 0x0c, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x5f, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x4c, 0x61, 0x62, 0x65,
+	0x6c, 0x12, 0x20, 0x0a, 0x0b, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x6d, 0x65, 0x6e, 0x74,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x6d,
+	0x65, 0x6e, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x9e, 0x09, 0x0a, 0x0a,
+	0x54, 0x72, 0x65, 0x65, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x30, 0x0a, 0x05, 0x71, 0x75,
+	0x65, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x71, 0x75, 0x65, 0x72,
+	0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x65, 0x65,
+	0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x12, 0x0a, 0x04,
+	0x74, 0x72, 0x65, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x74, 0x72, 0x65, 0x65,
+	0x12, 0x22, 0x0a, 0x0c, 0x75, 0x6e, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x69, 0x7a, 0x65, 0x64,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x75, 0x6e, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c,
+	0x69, 0x7a, 0x65, 0x64, 0x12, 0x42, 0x0a, 0x0b, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x71, 0x75, 0x65, 0x72,
+	0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x65, 0x65,
+	0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x61, 0x74, 0x74,
+	0x72, 0x69, 0x62, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x49, 0x0a, 0x10, 0x6d, 0x75, 0x6c, 0x74,
+	0x69, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x5f, 0x74, 0x72, 0x65, 0x65, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e,
+	0x64, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x75, 0x6c, 0x74, 0x69, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x54,
+	0x72, 0x65, 0x65, 0x52, 0x0e, 0x6d, 0x75, 0x6c, 0x74, 0x69, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x54,
+	0x72, 0x65, 0x65, 0x12, 0x25, 0x0a, 0x0e, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x5f, 0x76, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x66, 0x6f, 0x72,
+	0x6d, 0x61, 0x74, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x6f,
+	0x64, 0x65, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73,
+	0x12, 0x1c, 0x0a, 0x09, 0x74, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65, 0x64, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x09, 0x74, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65, 0x64, 0x12, 0x32,
+	0x0a, 0x15, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x5f,
+	0x6d, 0x69, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x13, 0x73,
+	0x61, 0x6d, 0x70, 0x6c, 0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x4d, 0x69, 0x73, 0x73, 0x69,
+	0x6e, 0x67, 0x12, 0x19, 0x0a, 0x08, 0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x0a,
+	0x20, 0x03, 0x28, 0x04, 0x52, 0x07, 0x6e, 0x6f, 0x64, 0x65, 0x49, 0x64, 0x73, 0x12, 0x2c, 0x0a,
+	0x12, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73, 0x5f, 0x6f, 0x76, 0x65, 0x72, 0x5f, 0x6c, 0x69,
+	0x6d, 0x69, 0x74, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x08, 0x52, 0x10, 0x73, 0x79, 0x6d, 0x62, 0x6f,
+	0x6c, 0x73, 0x4f, 0x76, 0x65, 0x72, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x4f, 0x0a, 0x10, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63,
+	0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x53, 0x6f, 0x75, 0x72,
+	0x63, 0x65, 0x4c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x0f, 0x73, 0x6f, 0x75,
+	0x72, 0x63, 0x65, 0x4c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1f, 0x0a, 0x0b,
+	0x73, 0x69, 0x7a, 0x65, 0x5f, 0x63, 0x61, 0x70, 0x70, 0x65, 0x64, 0x18, 0x0d, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x0a, 0x73, 0x69, 0x7a, 0x65, 0x43, 0x61, 0x70, 0x70, 0x65, 0x64, 0x12, 0x37, 0x0a,
+	0x18, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x5f, 0x62, 0x65, 0x6c, 0x6f, 0x77, 0x5f,
+	0x6d, 0x69, 0x6e, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x15, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x42, 0x65, 0x6c, 0x6f, 0x77, 0x4d, 0x69,
+	0x6e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x43, 0x0a, 0x0c, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x5f,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x71,
+	0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54,
+	0x72, 0x65, 0x65, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x52, 0x0b,
+	0x6c, 0x61, 0x62, 0x65, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x12, 0x39, 0x0a, 0x08, 0x63,
+	0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e,
+	0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e,
+	0x54, 0x72, 0x65, 0x65, 0x43, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x52, 0x08, 0x63, 0x6f,
+	0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x12, 0x35, 0x0a, 0x07, 0x62, 0x75, 0x63, 0x6b, 0x65, 0x74,
+	0x73, 0x18, 0x11, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62,
+	0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x42, 0x75,
+	0x63, 0x6b, 0x65, 0x74, 0x52, 0x07, 0x62, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x12, 0x34, 0x0a,
+	0x16, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73, 0x5f, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x5f,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x12, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x14, 0x73,
+	0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x56, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x6c, 0x65, 0x18, 0x13, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x05, 0x73, 0x74, 0x61, 0x6c, 0x65, 0x12, 0x42, 0x0a, 0x0c, 0x73, 0x61, 0x6d,
+	0x70, 0x6c, 0x65, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x73, 0x18, 0x14, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x1f, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76,
+	0x31, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x54, 0x79, 0x70, 0x65,
+	0x52, 0x0b, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x54, 0x79, 0x70, 0x65, 0x73, 0x12, 0x4b, 0x0a,
+	0x10, 0x67, 0x6f, 0x72, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x65, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x65,
+	0x73, 0x18, 0x15, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62,
+	0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x4c, 0x61,
+	0x62, 0x65, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x52, 0x0f, 0x67, 0x6f, 0x72, 0x6f, 0x75,
+	0x74, 0x69, 0x6e, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x69, 0x6e,
+	0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x16, 0x20,
+	0x03, 0x28, 0x01, 0x52, 0x0e, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x56, 0x61, 0x6c,
+	0x75, 0x65, 0x73, 0x12, 0x33, 0x0a, 0x06, 0x6c, 0x65, 0x67, 0x65, 0x6e, 0x64, 0x18, 0x17, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65,
+	0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x4c, 0x65, 0x67, 0x65, 0x6e, 0x64,
+	0x52, 0x06, 0x6c, 0x65, 0x67, 0x65, 0x6e, 0x64, 0x12, 0x22, 0x0a, 0x0c, 0x63, 0x6f, 0x6e, 0x74,
+	0x69, 0x6e, 0x75, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x18, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c,
+	0x63, 0x6f, 0x6e, 0x74, 0x69, 0x6e, 0x75, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x5e, 0x0a, 0x0a,
+	0x54, 0x72, 0x65, 0x65, 0x4c, 0x65, 0x67, 0x65, 0x6e, 0x64, 0x12, 0x3a, 0x0a, 0x07, 0x65, 0x6e,
+	0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x71, 0x75,
+	0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72,
+	0x65, 0x65, 0x4c, 0x65, 0x67, 0x65, 0x6e, 0x64, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x65,
+	0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x6f, 0x74, 0x68, 0x65, 0x72, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6f, 0x74, 0x68, 0x65, 0x72, 0x22, 0x57, 0x0a, 0x0f,
+	0x54, 0x72, 0x65, 0x65, 0x4c, 0x65, 0x67, 0x65, 0x6e, 0x64, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
+	0x18, 0x0a, 0x07, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12,
+	0x14, 0x0a, 0x05, 0x63, 0x6f, 0x6c, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05,
+	0x63, 0x6f, 0x6c, 0x6f, 0x72, 0x22, 0x38, 0x0a, 0x0e, 0x54, 0x72, 0x65, 0x65, 0x53, 0x61, 0x6d,
+	0x70, 0x6c, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x75,
+	0x6e, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x6e, 0x69, 0x74, 0x22,
+	0x7d, 0x0a, 0x0c, 0x54, 0x72, 0x65, 0x65, 0x43, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x12,
+	0x1a, 0x0a, 0x08, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x08, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x61,
+	0x72, 0x72, 0x69, 0x76, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x61, 0x72,
+	0x72, 0x69, 0x76, 0x65, 0x64, 0x12, 0x37, 0x0a, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x03,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b,
+	0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x43,
+	0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x52, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x22, 0x76,
+	0x0a, 0x10, 0x54, 0x72, 0x65, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x76, 0x65, 0x72, 0x61,
+	0x67, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x06, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1a,
+	0x0a, 0x08, 0x64, 0x61, 0x74, 0x61, 0x73, 0x65, 0x74, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x08, 0x64, 0x61, 0x74, 0x61, 0x73, 0x65, 0x74, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x6f,
+	0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x63, 0x6f,
+	0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x22, 0x65, 0x0a, 0x0f, 0x54, 0x72, 0x65, 0x65, 0x4c, 0x61,
+	0x62, 0x65, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x73, 0x12, 0x3a, 0x0a, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x24, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e,
+	0x76, 0x31, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x4c, 0x61, 0x62, 0x65, 0x6c,
+	0x56, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x52, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x22, 0x8d, 0x01,
+	0x0a, 0x13, 0x54, 0x72, 0x65, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x56,
+	0x61, 0x6c, 0x75, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x0d, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x6f, 0x74,
+	0x61, 0x6c, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x03, 0x52, 0x06, 0x74, 0x6f, 0x74, 0x61, 0x6c,
+	0x73, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x6f, 0x6d, 0x69, 0x6e, 0x61, 0x6e, 0x74, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x08, 0x64, 0x6f, 0x6d, 0x69, 0x6e, 0x61, 0x6e, 0x74, 0x22, 0x54, 0x0a,
+	0x13, 0x54, 0x72, 0x65, 0x65, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4c, 0x6f, 0x63, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x12, 0x3d, 0x0a, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65,
+	0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x53, 0x6f,
+	0x75, 0x72, 0x63, 0x65, 0x4c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x05, 0x6e, 0x6f,
+	0x64, 0x65, 0x73, 0x22, 0x6c, 0x0a, 0x16, 0x54, 0x72, 0x65, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x53,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x4c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a,
+	0x06, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x70,
+	0x61, 0x72, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x69, 0x6c,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x12, 0x0a,
+	0x04, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x6c, 0x69, 0x6e,
+	0x65, 0x22, 0x4b, 0x0a, 0x0e, 0x4d, 0x75, 0x6c, 0x74, 0x69, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x54,
+	0x72, 0x65, 0x65, 0x12, 0x39, 0x0a, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x23, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e,
+	0x64, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x75, 0x6c, 0x74, 0x69, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x54,
+	0x72, 0x65, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x22, 0x54,
+	0x0a, 0x12, 0x4d, 0x75, 0x6c, 0x74, 0x69, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x54, 0x72, 0x65, 0x65,
+	0x4e, 0x6f, 0x64, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04,
+	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x12, 0x12, 0x0a, 0x04, 0x73, 0x65, 0x6c, 0x66, 0x18, 0x03, 0x20, 0x03, 0x28, 0x03, 0x52, 0x04,
+	0x73, 0x65, 0x6c, 0x66, 0x22, 0x67, 0x0a, 0x0f, 0x54, 0x72, 0x65, 0x65, 0x41, 0x74, 0x74, 0x72,
+	0x69, 0x62, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65,
+	0x73, 0x12, 0x3a, 0x0a, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x24, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e,
+	0x76, 0x31, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x41, 0x74, 0x74, 0x72, 0x69,
+	0x62, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x22, 0x5b, 0x0a,
+	0x13, 0x54, 0x72, 0x65, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04,
+	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x12, 0x18, 0x0a, 0x07, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x0d, 0x52, 0x07, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x22, 0x10, 0x0a, 0x0e, 0x54, 0x69,
+	0x6d, 0x65, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x51, 0x75, 0x65, 0x72, 0x79, 0x22, 0x7e, 0x0a, 0x0f,
+	0x54, 0x69, 0x6d, 0x65, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12,
+	0x35, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f,
+	0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52,
+	0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x19, 0x0a, 0x08, 0x6d, 0x69, 0x6e, 0x5f, 0x74, 0x69,
+	0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x6d, 0x69, 0x6e, 0x54, 0x69, 0x6d,
+	0x65, 0x12, 0x19, 0x0a, 0x08, 0x6d, 0x61, 0x78, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x07, 0x6d, 0x61, 0x78, 0x54, 0x69, 0x6d, 0x65, 0x22, 0x2d, 0x0a, 0x0e,
+	0x43, 0x61, 0x6c, 0x6c, 0x47, 0x72, 0x61, 0x70, 0x68, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x1b,
+	0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x22, 0xb4, 0x01, 0x0a, 0x0f,
+	0x43, 0x61, 0x6c, 0x6c, 0x47, 0x72, 0x61, 0x70, 0x68, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12,
+	0x35, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f,
+	0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31,
+	0x2e, 0x43, 0x61, 0x6c, 0x6c, 0x47, 0x72, 0x61, 0x70, 0x68, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52,
+	0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x34, 0x0a, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63,
+	0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6c, 0x6c, 0x47, 0x72, 0x61, 0x70,
+	0x68, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x12, 0x34, 0x0a, 0x05,
+	0x65, 0x64, 0x67, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x71, 0x75,
+	0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61,
+	0x6c, 0x6c, 0x47, 0x72, 0x61, 0x70, 0x68, 0x45, 0x64, 0x67, 0x65, 0x52, 0x05, 0x65, 0x64, 0x67,
+	0x65, 0x73, 0x22, 0x4d, 0x0a, 0x0d, 0x43, 0x61, 0x6c, 0x6c, 0x47, 0x72, 0x61, 0x70, 0x68, 0x4e,
+	0x6f, 0x64, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x65, 0x6c, 0x66, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x65, 0x6c, 0x66, 0x12, 0x14, 0x0a, 0x05, 0x74,
+	0x6f, 0x74, 0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x74, 0x6f, 0x74, 0x61,
+	0x6c, 0x22, 0x55, 0x0a, 0x0d, 0x43, 0x61, 0x6c, 0x6c, 0x47, 0x72, 0x61, 0x70, 0x68, 0x45, 0x64,
+	0x67, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x61, 0x6c, 0x6c, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x06, 0x63, 0x61, 0x6c, 0x6c, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x61,
+	0x6c, 0x6c, 0x65, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x63, 0x61, 0x6c, 0x6c,
+	0x65, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x47, 0x0a, 0x0d, 0x53, 0x65, 0x6c, 0x66,
+	0x54, 0x65, 0x73, 0x74, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x19, 0x0a, 0x08, 0x62, 0x6c, 0x6f,
+	0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x62, 0x6c, 0x6f,
+	0x63, 0x6b, 0x49, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f, 0x6e, 0x6f, 0x64, 0x65,
+	0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x4e, 0x6f, 0x64, 0x65,
+	0x73, 0x22, 0x84, 0x01, 0x0a, 0x0e, 0x53, 0x65, 0x6c, 0x66, 0x54, 0x65, 0x73, 0x74, 0x52, 0x65,
+	0x70, 0x6f, 0x72, 0x74, 0x12, 0x34, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65,
+	0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x6c, 0x66, 0x54, 0x65, 0x73, 0x74, 0x51, 0x75,
+	0x65, 0x72, 0x79, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x3c, 0x0a, 0x08, 0x64, 0x61,
+	0x74, 0x61, 0x73, 0x65, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x71,
+	0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x53,
+	0x65, 0x6c, 0x66, 0x54, 0x65, 0x73, 0x74, 0x44, 0x61, 0x74, 0x61, 0x73, 0x65, 0x74, 0x52, 0x08,
+	0x64, 0x61, 0x74, 0x61, 0x73, 0x65, 0x74, 0x73, 0x22, 0x94, 0x01, 0x0a, 0x0f, 0x53, 0x65, 0x6c,
+	0x66, 0x54, 0x65, 0x73, 0x74, 0x44, 0x61, 0x74, 0x61, 0x73, 0x65, 0x74, 0x12, 0x1b, 0x0a, 0x09,
+	0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x08, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x3c, 0x0a,
+	0x08, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x20, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76,
+	0x31, 0x2e, 0x53, 0x65, 0x6c, 0x66, 0x54, 0x65, 0x73, 0x74, 0x53, 0x65, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x08, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x74,
+	0x72, 0x65, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x74, 0x72, 0x65, 0x65, 0x22,
+	0x55, 0x0a, 0x0f, 0x53, 0x65, 0x6c, 0x66, 0x54, 0x65, 0x73, 0x74, 0x53, 0x65, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x2e, 0x0a, 0x13, 0x6c, 0x6f, 0x61, 0x64, 0x5f, 0x64,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6e, 0x61, 0x6e, 0x6f, 0x73, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x11, 0x6c, 0x6f, 0x61, 0x64, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x4e, 0x61, 0x6e, 0x6f, 0x73, 0x22, 0x25, 0x0a, 0x0d, 0x4d, 0x61, 0x6e, 0x69, 0x66, 0x65,
+	0x73, 0x74, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0x86, 0x01,
+	0x0a, 0x0e, 0x4d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74,
+	0x12, 0x34, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1e, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76,
+	0x31, 0x2e, 0x4d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52,
+	0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x3e, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c,
+	0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79,
+	0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72, 0x6f, 0x66, 0x69,
+	0x6c, 0x65, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x6f, 0x72, 0x52, 0x08, 0x70, 0x72,
+	0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x22, 0xbd, 0x01, 0x0a, 0x11, 0x50, 0x72, 0x6f, 0x66, 0x69,
+	0x6c, 0x65, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x6f, 0x72, 0x12, 0x1c, 0x0a, 0x09,
+	0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x2b, 0x0a, 0x06, 0x6c, 0x61,
+	0x62, 0x65, 0x6c, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x74, 0x79, 0x70,
+	0x65, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x50, 0x61, 0x69, 0x72, 0x52,
+	0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x70, 0x72, 0x6f, 0x66, 0x69,
+	0x6c, 0x65, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x70,
+	0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x6f,
+	0x74, 0x61, 0x6c, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x62,
+	0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x62,
+	0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x64, 0x22, 0x3f, 0x0a, 0x10, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c,
+	0x54, 0x61, 0x62, 0x6c, 0x65, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x2b, 0x0a, 0x11, 0x75, 0x6e,
+	0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x69, 0x7a, 0x65, 0x64, 0x5f, 0x6f, 0x6e, 0x6c, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x10, 0x75, 0x6e, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x69,
+	0x7a, 0x65, 0x64, 0x4f, 0x6e, 0x6c, 0x79, 0x22, 0xc7, 0x01, 0x0a, 0x11, 0x53, 0x79, 0x6d, 0x62,
+	0x6f, 0x6c, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x37, 0x0a,
+	0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x71,
+	0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x53,
+	0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52,
+	0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x3a, 0x0a, 0x08, 0x6d, 0x61, 0x70, 0x70, 0x69, 0x6e,
+	0x67, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79,
+	0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x79, 0x6d, 0x62, 0x6f,
+	0x6c, 0x4d, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x52, 0x08, 0x6d, 0x61, 0x70, 0x70, 0x69, 0x6e,
+	0x67, 0x73, 0x12, 0x3d, 0x0a, 0x09, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63,
+	0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x4c, 0x6f,
+	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x22, 0xad, 0x01, 0x0a, 0x0d, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x4d, 0x61, 0x70, 0x70,
+	0x69, 0x6e, 0x67, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x73, 0x74,
+	0x61, 0x72, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x6d, 0x65, 0x6d, 0x6f, 0x72,
+	0x79, 0x53, 0x74, 0x61, 0x72, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79,
+	0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x6d, 0x65,
+	0x6d, 0x6f, 0x72, 0x79, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x66, 0x69, 0x6c,
+	0x65, 0x5f, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a,
+	0x66, 0x69, 0x6c, 0x65, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x69,
+	0x6c, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x69,
+	0x6c, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x5f,
+	0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x49,
+	0x64, 0x22, 0x64, 0x0a, 0x0e, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x4c, 0x6f, 0x63, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x18, 0x0a,
+	0x07, 0x6d, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07,
+	0x6d, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x79, 0x6d, 0x62, 0x6f,
+	0x6c, 0x69, 0x7a, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x73, 0x79, 0x6d,
+	0x62, 0x6f, 0x6c, 0x69, 0x7a, 0x65, 0x64, 0x22, 0xfe, 0x01, 0x0a, 0x14, 0x46, 0x75, 0x6e, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79,
+	0x12, 0x25, 0x0a, 0x0e, 0x62, 0x61, 0x73, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x5f, 0x73, 0x74, 0x61,
+	0x72, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x62, 0x61, 0x73, 0x65, 0x6c, 0x69,
+	0x6e, 0x65, 0x53, 0x74, 0x61, 0x72, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x62, 0x61, 0x73, 0x65, 0x6c,
+	0x69, 0x6e, 0x65, 0x5f, 0x65, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x62,
+	0x61, 0x73, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x45, 0x6e, 0x64, 0x12, 0x23, 0x0a, 0x0d, 0x63, 0x75,
+	0x72, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0c, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x61, 0x72, 0x74, 0x12,
+	0x1f, 0x0a, 0x0b, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x65, 0x6e, 0x64, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x45, 0x6e, 0x64,
+	0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x40, 0x0a, 0x07, 0x72, 0x61, 0x6e, 0x6b, 0x69, 0x6e,
+	0x67, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x26, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62,
+	0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x75, 0x6e, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x61, 0x6e, 0x6b, 0x69, 0x6e, 0x67, 0x52,
+	0x07, 0x72, 0x61, 0x6e, 0x6b, 0x69, 0x6e, 0x67, 0x22, 0x93, 0x01, 0x0a, 0x15, 0x46, 0x75, 0x6e,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6f,
+	0x72, 0x74, 0x12, 0x3b, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x25, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64,
+	0x2e, 0x76, 0x31, 0x2e, 0x46, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x68, 0x61, 0x6e,
+	0x67, 0x65, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12,
+	0x3d, 0x0a, 0x09, 0x66, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e,
+	0x64, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x68, 0x61,
+	0x6e, 0x67, 0x65, 0x52, 0x09, 0x66, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x6c,
+	0x0a, 0x0e, 0x46, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65,
+	0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x6e, 0x61, 0x6d, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x62, 0x61, 0x73, 0x65, 0x6c, 0x69, 0x6e, 0x65,
+	0x5f, 0x73, 0x65, 0x6c, 0x66, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x62, 0x61, 0x73,
+	0x65, 0x6c, 0x69, 0x6e, 0x65, 0x53, 0x65, 0x6c, 0x66, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x75, 0x72,
+	0x72, 0x65, 0x6e, 0x74, 0x5f, 0x73, 0x65, 0x6c, 0x66, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0b, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x53, 0x65, 0x6c, 0x66, 0x22, 0xb6, 0x01, 0x0a,
+	0x13, 0x46, 0x6c, 0x61, 0x6d, 0x65, 0x47, 0x72, 0x61, 0x70, 0x68, 0x44, 0x69, 0x66, 0x66, 0x51,
+	0x75, 0x65, 0x72, 0x79, 0x12, 0x23, 0x0a, 0x0d, 0x6c, 0x65, 0x66, 0x74, 0x5f, 0x73, 0x65, 0x6c,
+	0x65, 0x63, 0x74, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6c, 0x65, 0x66,
+	0x74, 0x53, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x25, 0x0a, 0x0e, 0x72, 0x69, 0x67,
+	0x68, 0x74, 0x5f, 0x73, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0d, 0x72, 0x69, 0x67, 0x68, 0x74, 0x53, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72,
+	0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x12, 0x36, 0x0a,
+	0x09, 0x64, 0x69, 0x66, 0x66, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x19, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e,
+	0x76, 0x31, 0x2e, 0x44, 0x69, 0x66, 0x66, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x08, 0x64, 0x69, 0x66,
+	0x66, 0x4d, 0x6f, 0x64, 0x65, 0x22, 0x91, 0x02, 0x0a, 0x14, 0x46, 0x6c, 0x61, 0x6d, 0x65, 0x47,
+	0x72, 0x61, 0x70, 0x68, 0x44, 0x69, 0x66, 0x66, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x3a,
+	0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x24, 0x2e,
+	0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e,
+	0x46, 0x6c, 0x61, 0x6d, 0x65, 0x47, 0x72, 0x61, 0x70, 0x68, 0x44, 0x69, 0x66, 0x66, 0x51, 0x75,
+	0x65, 0x72, 0x79, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x1b, 0x0a, 0x09, 0x6c, 0x65,
+	0x66, 0x74, 0x5f, 0x74, 0x72, 0x65, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x6c,
+	0x65, 0x66, 0x74, 0x54, 0x72, 0x65, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x69, 0x67, 0x68, 0x74,
+	0x5f, 0x74, 0x72, 0x65, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x72, 0x69, 0x67,
+	0x68, 0x74, 0x54, 0x72, 0x65, 0x65, 0x12, 0x3a, 0x0a, 0x0a, 0x66, 0x6c, 0x61, 0x6d, 0x65, 0x67,
+	0x72, 0x61, 0x70, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x71, 0x75, 0x65,
+	0x72, 0x69, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x6c, 0x61, 0x6d, 0x65, 0x47, 0x72, 0x61,
+	0x70, 0x68, 0x44, 0x69, 0x66, 0x66, 0x52, 0x0a, 0x66, 0x6c, 0x61, 0x6d, 0x65, 0x67, 0x72, 0x61,
+	0x70, 0x68, 0x12, 0x45, 0x0a, 0x0b, 0x64, 0x69, 0x66, 0x66, 0x5f, 0x6c, 0x65, 0x76, 0x65, 0x6c,
+	0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62,
+	0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x6c, 0x61, 0x6d, 0x65, 0x47,
+	0x72, 0x61, 0x70, 0x68, 0x44, 0x69, 0x66, 0x66, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x52, 0x0a, 0x64,
+	0x69, 0x66, 0x66, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x73, 0x22, 0x2d, 0x0a, 0x13, 0x46, 0x6c, 0x61,
+	0x6d, 0x65, 0x47, 0x72, 0x61, 0x70, 0x68, 0x44, 0x69, 0x66, 0x66, 0x4c, 0x65, 0x76, 0x65, 0x6c,
+	0x12, 0x16, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x01,
+	0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x22, 0xdc, 0x01, 0x0a, 0x0d, 0x54, 0x72, 0x65,
+	0x65, 0x44, 0x69, 0x66, 0x66, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x38, 0x0a, 0x08, 0x62, 0x61,
+	0x73, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x71,
+	0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54,
+	0x72, 0x65, 0x65, 0x44, 0x69, 0x66, 0x66, 0x53, 0x65, 0x74, 0x52, 0x08, 0x62, 0x61, 0x73, 0x65,
+	0x6c, 0x69, 0x6e, 0x65, 0x12, 0x3c, 0x0a, 0x0a, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x72, 0x69, 0x73,
+	0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79,
+	0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x44,
+	0x69, 0x66, 0x66, 0x53, 0x65, 0x74, 0x52, 0x0a, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x72, 0x69, 0x73,
+	0x6f, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x12,
+	0x36, 0x0a, 0x09, 0x64, 0x69, 0x66, 0x66, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x19, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e,
+	0x64, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x69, 0x66, 0x66, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x08, 0x64,
+	0x69, 0x66, 0x66, 0x4d, 0x6f, 0x64, 0x65, 0x22, 0x6e, 0x0a, 0x0b, 0x54, 0x72, 0x65, 0x65, 0x44,
+	0x69, 0x66, 0x66, 0x53, 0x65, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f,
+	0x74, 0x69, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72,
+	0x74, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x65, 0x6e, 0x64, 0x5f, 0x74, 0x69, 0x6d,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x65, 0x6e, 0x64, 0x54, 0x69, 0x6d, 0x65,
+	0x12, 0x25, 0x0a, 0x0e, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x5f, 0x73, 0x65, 0x6c, 0x65, 0x63, 0x74,
+	0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x53,
+	0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x22, 0x99, 0x01, 0x0a, 0x0e, 0x54, 0x72, 0x65, 0x65,
+	0x44, 0x69, 0x66, 0x66, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x34, 0x0a, 0x05, 0x71, 0x75,
+	0x65, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x71, 0x75, 0x65, 0x72,
+	0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x65, 0x65,
+	0x44, 0x69, 0x66, 0x66, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79,
+	0x12, 0x33, 0x0a, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x1d, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76,
+	0x31, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x44, 0x69, 0x66, 0x66, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x05,
+	0x6e, 0x6f, 0x64, 0x65, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74,
+	0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x74, 0x72, 0x75, 0x6e, 0x63, 0x61,
+	0x74, 0x65, 0x64, 0x22, 0xee, 0x01, 0x0a, 0x0c, 0x54, 0x72, 0x65, 0x65, 0x44, 0x69, 0x66, 0x66,
+	0x4e, 0x6f, 0x64, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04,
+	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x12, 0x23, 0x0a, 0x0d, 0x62, 0x61, 0x73, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x5f, 0x73, 0x65, 0x6c,
+	0x66, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x62, 0x61, 0x73, 0x65, 0x6c, 0x69, 0x6e,
+	0x65, 0x53, 0x65, 0x6c, 0x66, 0x12, 0x25, 0x0a, 0x0e, 0x62, 0x61, 0x73, 0x65, 0x6c, 0x69, 0x6e,
+	0x65, 0x5f, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x62,
+	0x61, 0x73, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x12, 0x27, 0x0a, 0x0f,
+	0x63, 0x6f, 0x6d, 0x70, 0x61, 0x72, 0x69, 0x73, 0x6f, 0x6e, 0x5f, 0x73, 0x65, 0x6c, 0x66, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x72, 0x69, 0x73, 0x6f,
+	0x6e, 0x53, 0x65, 0x6c, 0x66, 0x12, 0x29, 0x0a, 0x10, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x72, 0x69,
+	0x73, 0x6f, 0x6e, 0x5f, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0f, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x72, 0x69, 0x73, 0x6f, 0x6e, 0x54, 0x6f, 0x74, 0x61, 0x6c,
+	0x12, 0x12, 0x0a, 0x04, 0x64, 0x69, 0x66, 0x66, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04,
+	0x64, 0x69, 0x66, 0x66, 0x22, 0x12, 0x0a, 0x10, 0x53, 0x74, 0x61, 0x63, 0x6b, 0x74, 0x72, 0x61,
+	0x63, 0x65, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x22, 0x92, 0x01, 0x0a, 0x11, 0x53, 0x74, 0x61,
+	0x63, 0x6b, 0x74, 0x72, 0x61, 0x63, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x37,
+	0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e,
+	0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e,
+	0x53, 0x74, 0x61, 0x63, 0x6b, 0x74, 0x72, 0x61, 0x63, 0x65, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79,
+	0x52, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x44, 0x0a, 0x0a, 0x70, 0x61, 0x72, 0x74, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x71, 0x75,
+	0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74,
+	0x61, 0x63, 0x6b, 0x74, 0x72, 0x61, 0x63, 0x65, 0x50, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x0a, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x9f, 0x02,
+	0x0a, 0x13, 0x53, 0x74, 0x61, 0x63, 0x6b, 0x74, 0x72, 0x61, 0x63, 0x65, 0x50, 0x61, 0x72, 0x74,
+	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x19, 0x0a, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x64,
+	0x12, 0x1b, 0x0a, 0x09, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x18, 0x0a,
+	0x07, 0x64, 0x61, 0x74, 0x61, 0x73, 0x65, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x64, 0x61, 0x74, 0x61, 0x73, 0x65, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x70, 0x61, 0x72, 0x74, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x70, 0x61, 0x72, 0x74,
+	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x25, 0x0a, 0x0e, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x74, 0x72,
+	0x61, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x0d, 0x73,
+	0x74, 0x61, 0x63, 0x6b, 0x74, 0x72, 0x61, 0x63, 0x65, 0x49, 0x64, 0x73, 0x12, 0x16, 0x0a, 0x06,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x03, 0x52, 0x06, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x07, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x05, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x12, 0x43, 0x0a, 0x0b, 0x73, 0x74,
+	0x61, 0x63, 0x6b, 0x74, 0x72, 0x61, 0x63, 0x65, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x21, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76,
+	0x31, 0x2e, 0x53, 0x74, 0x61, 0x63, 0x6b, 0x74, 0x72, 0x61, 0x63, 0x65, 0x46, 0x72, 0x61, 0x6d,
+	0x65, 0x73, 0x52, 0x0b, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x74, 0x72, 0x61, 0x63, 0x65, 0x73, 0x22,
+	0x2a, 0x0a, 0x10, 0x53, 0x74, 0x61, 0x63, 0x6b, 0x74, 0x72, 0x61, 0x63, 0x65, 0x46, 0x72, 0x61,
+	0x6d, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x72, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x05, 0x52, 0x06, 0x66, 0x72, 0x61, 0x6d, 0x65, 0x73, 0x22, 0x29, 0x0a, 0x0f, 0x53,
+	0x74, 0x61, 0x63, 0x6b, 0x44, 0x65, 0x70, 0x74, 0x68, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x16,
+	0x0a, 0x06, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x03, 0x52, 0x06,
+	0x62, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x22, 0xbc, 0x01, 0x0a, 0x10, 0x53, 0x74, 0x61, 0x63, 0x6b,
+	0x44, 0x65, 0x70, 0x74, 0x68, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x36, 0x0a, 0x05, 0x71,
+	0x75, 0x65, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x71, 0x75, 0x65,
+	0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x61,
+	0x63, 0x6b, 0x44, 0x65, 0x70, 0x74, 0x68, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x05, 0x71, 0x75,
+	0x65, 0x72, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x03, 0x52, 0x06, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x12, 0x3b, 0x0a, 0x07, 0x62,
+	0x75, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x71,
+	0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x53,
+	0x74, 0x61, 0x63, 0x6b, 0x44, 0x65, 0x70, 0x74, 0x68, 0x42, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x52,
+	0x07, 0x62, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f,
+	0x64, 0x65, 0x70, 0x74, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x6d, 0x61, 0x78,
+	0x44, 0x65, 0x70, 0x74, 0x68, 0x22, 0x4a, 0x0a, 0x10, 0x53, 0x74, 0x61, 0x63, 0x6b, 0x44, 0x65,
+	0x70, 0x74, 0x68, 0x42, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x20, 0x0a, 0x0b, 0x73, 0x74, 0x61,
+	0x63, 0x6b, 0x74, 0x72, 0x61, 0x63, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b,
+	0x73, 0x74, 0x61, 0x63, 0x6b, 0x74, 0x72, 0x61, 0x63, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x22, 0x57, 0x0a, 0x15, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x44, 0x65, 0x76, 0x69,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x21, 0x0a, 0x0c, 0x70, 0x72,
+	0x6f, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x0b, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x1b, 0x0a,
+	0x09, 0x6d, 0x61, 0x78, 0x5f, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x08, 0x6d, 0x61, 0x78, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x22, 0x8d, 0x02, 0x0a, 0x16, 0x50,
+	0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x44, 0x65, 0x76, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x3c, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b,
+	0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x44, 0x65,
+	0x76, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x05, 0x71, 0x75,
+	0x65, 0x72, 0x79, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x5f, 0x74, 0x72,
+	0x65, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74,
+	0x54, 0x72, 0x65, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x72, 0x65, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x04, 0x74, 0x72, 0x65, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x74, 0x61, 0x72, 0x67,
+	0x65, 0x74, 0x5f, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x0e, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65,
+	0x73, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x3b, 0x0a,
+	0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x71,
+	0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x50,
+	0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x44, 0x65, 0x76, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4e,
+	0x6f, 0x64, 0x65, 0x52, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x22, 0x92, 0x01, 0x0a, 0x14, 0x50,
+	0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x44, 0x65, 0x76, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4e,
+	0x6f, 0x64, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x06, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12,
+	0x16, 0x0a, 0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x76, 0x65, 0x72, 0x61,
+	0x67, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67,
+	0x65, 0x12, 0x1c, 0x0a, 0x09, 0x64, 0x65, 0x76, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x64, 0x65, 0x76, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22,
+	0xbb, 0x01, 0x0a, 0x16, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x53, 0x69, 0x6d, 0x69, 0x6c,
+	0x61, 0x72, 0x69, 0x74, 0x79, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x23, 0x0a, 0x0d, 0x6c, 0x65,
+	0x66, 0x74, 0x5f, 0x73, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0c, 0x6c, 0x65, 0x66, 0x74, 0x53, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x12,
+	0x25, 0x0a, 0x0e, 0x72, 0x69, 0x67, 0x68, 0x74, 0x5f, 0x73, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f,
+	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x72, 0x69, 0x67, 0x68, 0x74, 0x53, 0x65,
+	0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x13, 0x0a, 0x05, 0x74, 0x6f, 0x70, 0x5f, 0x6b, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x6f, 0x70, 0x4b, 0x12, 0x40, 0x0a, 0x06, 0x6d,
+	0x65, 0x74, 0x72, 0x69, 0x63, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x28, 0x2e, 0x71, 0x75,
+	0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72,
+	0x6f, 0x66, 0x69, 0x6c, 0x65, 0x53, 0x69, 0x6d, 0x69, 0x6c, 0x61, 0x72, 0x69, 0x74, 0x79, 0x4d,
+	0x65, 0x74, 0x72, 0x69, 0x63, 0x52, 0x06, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x22, 0xb1, 0x01,
+	0x0a, 0x17, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x53, 0x69, 0x6d, 0x69, 0x6c, 0x61, 0x72,
+	0x69, 0x74, 0x79, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x3d, 0x0a, 0x05, 0x71, 0x75, 0x65,
+	0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79,
+	0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72, 0x6f, 0x66, 0x69,
+	0x6c, 0x65, 0x53, 0x69, 0x6d, 0x69, 0x6c, 0x61, 0x72, 0x69, 0x74, 0x79, 0x51, 0x75, 0x65, 0x72,
+	0x79, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x41, 0x0a, 0x09, 0x66, 0x75, 0x6e, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x71, 0x75,
+	0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x69,
+	0x6d, 0x69, 0x6c, 0x61, 0x72, 0x69, 0x74, 0x79, 0x46, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x09, 0x66, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x73,
+	0x63, 0x6f, 0x72, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x73, 0x63, 0x6f, 0x72,
+	0x65, 0x22, 0x9c, 0x01, 0x0a, 0x12, 0x53, 0x69, 0x6d, 0x69, 0x6c, 0x61, 0x72, 0x69, 0x74, 0x79,
+	0x46, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1b, 0x0a, 0x09,
+	0x6c, 0x65, 0x66, 0x74, 0x5f, 0x73, 0x65, 0x6c, 0x66, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x08, 0x6c, 0x65, 0x66, 0x74, 0x53, 0x65, 0x6c, 0x66, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x69, 0x67,
+	0x68, 0x74, 0x5f, 0x73, 0x65, 0x6c, 0x66, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x72,
+	0x69, 0x67, 0x68, 0x74, 0x53, 0x65, 0x6c, 0x66, 0x12, 0x19, 0x0a, 0x08, 0x6c, 0x65, 0x66, 0x74,
+	0x5f, 0x68, 0x6f, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x6c, 0x65, 0x66, 0x74,
+	0x48, 0x6f, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x72, 0x69, 0x67, 0x68, 0x74, 0x5f, 0x68, 0x6f, 0x74,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x72, 0x69, 0x67, 0x68, 0x74, 0x48, 0x6f, 0x74,
+	0x22, 0x63, 0x0a, 0x0f, 0x54, 0x6f, 0x70, 0x43, 0x61, 0x6c, 0x6c, 0x65, 0x72, 0x73, 0x51, 0x75,
+	0x65, 0x72, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x1e, 0x0a, 0x0a, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x74, 0x69, 0x76, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x0a, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x74, 0x69, 0x76, 0x65, 0x12,
+	0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05,
+	0x6c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0x96, 0x01, 0x0a, 0x10, 0x54, 0x6f, 0x70, 0x43, 0x61, 0x6c,
+	0x6c, 0x65, 0x72, 0x73, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x36, 0x0a, 0x05, 0x71, 0x75,
+	0x65, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x71, 0x75, 0x65, 0x72,
+	0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x6f, 0x70, 0x43,
+	0x61, 0x6c, 0x6c, 0x65, 0x72, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x05, 0x71, 0x75, 0x65,
+	0x72, 0x79, 0x12, 0x34, 0x0a, 0x07, 0x63, 0x61, 0x6c, 0x6c, 0x65, 0x72, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65,
+	0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x6f, 0x70, 0x43, 0x61, 0x6c, 0x6c, 0x65, 0x72, 0x52,
+	0x07, 0x63, 0x61, 0x6c, 0x6c, 0x65, 0x72, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x74, 0x61,
+	0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x22, 0x35,
+	0x0a, 0x09, 0x54, 0x6f, 0x70, 0x43, 0x61, 0x6c, 0x6c, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12,
+	0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x6a, 0x0a, 0x10, 0x54, 0x6f, 0x70, 0x50, 0x72, 0x6f, 0x66,
+	0x69, 0x6c, 0x65, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d,
+	0x69, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12,
+	0x23, 0x0a, 0x0d, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x5f, 0x74, 0x72, 0x65, 0x65, 0x73,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x54,
+	0x72, 0x65, 0x65, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f, 0x6e, 0x6f, 0x64, 0x65,
+	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x4e, 0x6f, 0x64, 0x65,
+	0x73, 0x22, 0x85, 0x01, 0x0a, 0x11, 0x54, 0x6f, 0x70, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65,
+	0x73, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x37, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61,
+	0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x6f, 0x70, 0x50, 0x72, 0x6f, 0x66,
+	0x69, 0x6c, 0x65, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79,
+	0x12, 0x37, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e,
+	0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x6f, 0x70, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x52,
+	0x08, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x22, 0x91, 0x01, 0x0a, 0x0a, 0x54, 0x6f,
+	0x70, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x2b, 0x0a, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x76,
+	0x31, 0x2e, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x50, 0x61, 0x69, 0x72, 0x52, 0x06, 0x6c, 0x61, 0x62,
+	0x65, 0x6c, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x72, 0x65,
+	0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x74, 0x72, 0x65, 0x65, 0x2a, 0xe0, 0x03,
+	0x0a, 0x09, 0x51, 0x75, 0x65, 0x72, 0x79, 0x54, 0x79, 0x70, 0x65, 0x12, 0x15, 0x0a, 0x11, 0x51,
+	0x55, 0x45, 0x52, 0x59, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44,
+	0x10, 0x00, 0x12, 0x15, 0x0a, 0x11, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x4c, 0x41, 0x42, 0x45,
+	0x4c, 0x5f, 0x4e, 0x41, 0x4d, 0x45, 0x53, 0x10, 0x01, 0x12, 0x16, 0x0a, 0x12, 0x51, 0x55, 0x45,
+	0x52, 0x59, 0x5f, 0x4c, 0x41, 0x42, 0x45, 0x4c, 0x5f, 0x56, 0x41, 0x4c, 0x55, 0x45, 0x53, 0x10,
+	0x02, 0x12, 0x17, 0x0a, 0x13, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x53, 0x45, 0x52, 0x49, 0x45,
+	0x53, 0x5f, 0x4c, 0x41, 0x42, 0x45, 0x4c, 0x53, 0x10, 0x03, 0x12, 0x15, 0x0a, 0x11, 0x51, 0x55,
+	0x45, 0x52, 0x59, 0x5f, 0x54, 0x49, 0x4d, 0x45, 0x5f, 0x53, 0x45, 0x52, 0x49, 0x45, 0x53, 0x10,
+	0x04, 0x12, 0x0e, 0x0a, 0x0a, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x52, 0x45, 0x45, 0x10,
+	0x05, 0x12, 0x14, 0x0a, 0x10, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x49, 0x4d, 0x45, 0x5f,
+	0x52, 0x41, 0x4e, 0x47, 0x45, 0x10, 0x06, 0x12, 0x13, 0x0a, 0x0f, 0x51, 0x55, 0x45, 0x52, 0x59,
+	0x5f, 0x43, 0x41, 0x4c, 0x4c, 0x47, 0x52, 0x41, 0x50, 0x48, 0x10, 0x07, 0x12, 0x12, 0x0a, 0x0e,
+	0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x53, 0x45, 0x4c, 0x46, 0x54, 0x45, 0x53, 0x54, 0x10, 0x08,
+	0x12, 0x12, 0x0a, 0x0e, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x4d, 0x41, 0x4e, 0x49, 0x46, 0x45,
+	0x53, 0x54, 0x10, 0x09, 0x12, 0x16, 0x0a, 0x12, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x53, 0x59,
+	0x4d, 0x42, 0x4f, 0x4c, 0x5f, 0x54, 0x41, 0x42, 0x4c, 0x45, 0x10, 0x0a, 0x12, 0x1a, 0x0a, 0x16,
+	0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x46, 0x55, 0x4e, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x43,
+	0x48, 0x41, 0x4e, 0x47, 0x45, 0x53, 0x10, 0x0b, 0x12, 0x19, 0x0a, 0x15, 0x51, 0x55, 0x45, 0x52,
+	0x59, 0x5f, 0x46, 0x4c, 0x41, 0x4d, 0x45, 0x47, 0x52, 0x41, 0x50, 0x48, 0x5f, 0x44, 0x49, 0x46,
+	0x46, 0x10, 0x0c, 0x12, 0x15, 0x0a, 0x11, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x53, 0x54, 0x41,
+	0x43, 0x4b, 0x54, 0x52, 0x41, 0x43, 0x45, 0x53, 0x10, 0x0d, 0x12, 0x15, 0x0a, 0x11, 0x51, 0x55,
+	0x45, 0x52, 0x59, 0x5f, 0x53, 0x54, 0x41, 0x43, 0x4b, 0x5f, 0x44, 0x45, 0x50, 0x54, 0x48, 0x10,
+	0x0e, 0x12, 0x1b, 0x0a, 0x17, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x50, 0x52, 0x4f, 0x46, 0x49,
+	0x4c, 0x45, 0x5f, 0x44, 0x45, 0x56, 0x49, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x10, 0x0f, 0x12, 0x1c,
+	0x0a, 0x18, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x50, 0x52, 0x4f, 0x46, 0x49, 0x4c, 0x45, 0x5f,
+	0x53, 0x49, 0x4d, 0x49, 0x4c, 0x41, 0x52, 0x49, 0x54, 0x59, 0x10, 0x10, 0x12, 0x15, 0x0a, 0x11,
+	0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x4f, 0x50, 0x5f, 0x43, 0x41, 0x4c, 0x4c, 0x45, 0x52,
+	0x53, 0x10, 0x11, 0x12, 0x16, 0x0a, 0x12, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x4f, 0x50,
+	0x5f, 0x50, 0x52, 0x4f, 0x46, 0x49, 0x4c, 0x45, 0x53, 0x10, 0x12, 0x12, 0x13, 0x0a, 0x0f, 0x51,
+	0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x52, 0x45, 0x45, 0x5f, 0x44, 0x49, 0x46, 0x46, 0x10, 0x13,
+	0x2a, 0x6d, 0x0a, 0x0a, 0x53, 0x6b, 0x69, 0x70, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x1b,
+	0x0a, 0x17, 0x53, 0x4b, 0x49, 0x50, 0x5f, 0x52, 0x45, 0x41, 0x53, 0x4f, 0x4e, 0x5f, 0x55, 0x4e,
+	0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x21, 0x0a, 0x1d, 0x53,
+	0x4b, 0x49, 0x50, 0x5f, 0x52, 0x45, 0x41, 0x53, 0x4f, 0x4e, 0x5f, 0x44, 0x45, 0x41, 0x44, 0x4c,
+	0x49, 0x4e, 0x45, 0x5f, 0x45, 0x58, 0x43, 0x45, 0x45, 0x44, 0x45, 0x44, 0x10, 0x01, 0x12, 0x1f,
+	0x0a, 0x1b, 0x53, 0x4b, 0x49, 0x50, 0x5f, 0x52, 0x45, 0x41, 0x53, 0x4f, 0x4e, 0x5f, 0x53, 0x45,
+	0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x4d, 0x49, 0x53, 0x53, 0x49, 0x4e, 0x47, 0x10, 0x02, 0x2a,
+	0xf5, 0x03, 0x0a, 0x0a, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x16,
+	0x0a, 0x12, 0x52, 0x45, 0x50, 0x4f, 0x52, 0x54, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49,
+	0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x16, 0x0a, 0x12, 0x52, 0x45, 0x50, 0x4f, 0x52, 0x54,
+	0x5f, 0x4c, 0x41, 0x42, 0x45, 0x4c, 0x5f, 0x4e, 0x41, 0x4d, 0x45, 0x53, 0x10, 0x01, 0x12, 0x17,
+	0x0a, 0x13, 0x52, 0x45, 0x50, 0x4f, 0x52, 0x54, 0x5f, 0x4c, 0x41, 0x42, 0x45, 0x4c, 0x5f, 0x56,
+	0x41, 0x4c, 0x55, 0x45, 0x53, 0x10, 0x02, 0x12, 0x18, 0x0a, 0x14, 0x52, 0x45, 0x50, 0x4f, 0x52,
+	0x54, 0x5f, 0x53, 0x45, 0x52, 0x49, 0x45, 0x53, 0x5f, 0x4c, 0x41, 0x42, 0x45, 0x4c, 0x53, 0x10,
+	0x03, 0x12, 0x16, 0x0a, 0x12, 0x52, 0x45, 0x50, 0x4f, 0x52, 0x54, 0x5f, 0x54, 0x49, 0x4d, 0x45,
+	0x5f, 0x53, 0x45, 0x52, 0x49, 0x45, 0x53, 0x10, 0x04, 0x12, 0x0f, 0x0a, 0x0b, 0x52, 0x45, 0x50,
+	0x4f, 0x52, 0x54, 0x5f, 0x54, 0x52, 0x45, 0x45, 0x10, 0x05, 0x12, 0x15, 0x0a, 0x11, 0x52, 0x45,
+	0x50, 0x4f, 0x52, 0x54, 0x5f, 0x54, 0x49, 0x4d, 0x45, 0x5f, 0x52, 0x41, 0x4e, 0x47, 0x45, 0x10,
+	0x06, 0x12, 0x14, 0x0a, 0x10, 0x52, 0x45, 0x50, 0x4f, 0x52, 0x54, 0x5f, 0x43, 0x41, 0x4c, 0x4c,
+	0x47, 0x52, 0x41, 0x50, 0x48, 0x10, 0x07, 0x12, 0x13, 0x0a, 0x0f, 0x52, 0x45, 0x50, 0x4f, 0x52,
+	0x54, 0x5f, 0x53, 0x45, 0x4c, 0x46, 0x54, 0x45, 0x53, 0x54, 0x10, 0x08, 0x12, 0x13, 0x0a, 0x0f,
+	0x52, 0x45, 0x50, 0x4f, 0x52, 0x54, 0x5f, 0x4d, 0x41, 0x4e, 0x49, 0x46, 0x45, 0x53, 0x54, 0x10,
+	0x09, 0x12, 0x17, 0x0a, 0x13, 0x52, 0x45, 0x50, 0x4f, 0x52, 0x54, 0x5f, 0x53, 0x59, 0x4d, 0x42,
+	0x4f, 0x4c, 0x5f, 0x54, 0x41, 0x42, 0x4c, 0x45, 0x10, 0x0a, 0x12, 0x1b, 0x0a, 0x17, 0x52, 0x45,
+	0x50, 0x4f, 0x52, 0x54, 0x5f, 0x46, 0x55, 0x4e, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x43, 0x48,
+	0x41, 0x4e, 0x47, 0x45, 0x53, 0x10, 0x0b, 0x12, 0x1a, 0x0a, 0x16, 0x52, 0x45, 0x50, 0x4f, 0x52,
+	0x54, 0x5f, 0x46, 0x4c, 0x41, 0x4d, 0x45, 0x47, 0x52, 0x41, 0x50, 0x48, 0x5f, 0x44, 0x49, 0x46,
+	0x46, 0x10, 0x0c, 0x12, 0x16, 0x0a, 0x12, 0x52, 0x45, 0x50, 0x4f, 0x52, 0x54, 0x5f, 0x53, 0x54,
+	0x41, 0x43, 0x4b, 0x54, 0x52, 0x41, 0x43, 0x45, 0x53, 0x10, 0x0d, 0x12, 0x16, 0x0a, 0x12, 0x52,
+	0x45, 0x50, 0x4f, 0x52, 0x54, 0x5f, 0x53, 0x54, 0x41, 0x43, 0x4b, 0x5f, 0x44, 0x45, 0x50, 0x54,
+	0x48, 0x10, 0x0e, 0x12, 0x1c, 0x0a, 0x18, 0x52, 0x45, 0x50, 0x4f, 0x52, 0x54, 0x5f, 0x50, 0x52,
+	0x4f, 0x46, 0x49, 0x4c, 0x45, 0x5f, 0x44, 0x45, 0x56, 0x49, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x10,
+	0x0f, 0x12, 0x1d, 0x0a, 0x19, 0x52, 0x45, 0x50, 0x4f, 0x52, 0x54, 0x5f, 0x50, 0x52, 0x4f, 0x46,
+	0x49, 0x4c, 0x45, 0x5f, 0x53, 0x49, 0x4d, 0x49, 0x4c, 0x41, 0x52, 0x49, 0x54, 0x59, 0x10, 0x10,
+	0x12, 0x16, 0x0a, 0x12, 0x52, 0x45, 0x50, 0x4f, 0x52, 0x54, 0x5f, 0x54, 0x4f, 0x50, 0x5f, 0x43,
+	0x41, 0x4c, 0x4c, 0x45, 0x52, 0x53, 0x10, 0x11, 0x12, 0x17, 0x0a, 0x13, 0x52, 0x45, 0x50, 0x4f,
+	0x52, 0x54, 0x5f, 0x54, 0x4f, 0x50, 0x5f, 0x50, 0x52, 0x4f, 0x46, 0x49, 0x4c, 0x45, 0x53, 0x10,
+	0x12, 0x12, 0x14, 0x0a, 0x10, 0x52, 0x45, 0x50, 0x4f, 0x52, 0x54, 0x5f, 0x54, 0x52, 0x45, 0x45,
+	0x5f, 0x44, 0x49, 0x46, 0x46, 0x10, 0x13, 0x2a, 0xbf, 0x01, 0x0a, 0x12, 0x54, 0x72, 0x65, 0x65,
+	0x56, 0x61, 0x6c, 0x75, 0x65, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x24,
+	0x0a, 0x20, 0x54, 0x52, 0x45, 0x45, 0x5f, 0x56, 0x41, 0x4c, 0x55, 0x45, 0x5f, 0x4f, 0x50, 0x45,
+	0x52, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49,
+	0x45, 0x44, 0x10, 0x00, 0x12, 0x1c, 0x0a, 0x18, 0x54, 0x52, 0x45, 0x45, 0x5f, 0x56, 0x41, 0x4c,
+	0x55, 0x45, 0x5f, 0x4f, 0x50, 0x45, 0x52, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x41, 0x44, 0x44,
+	0x10, 0x01, 0x12, 0x21, 0x0a, 0x1d, 0x54, 0x52, 0x45, 0x45, 0x5f, 0x56, 0x41, 0x4c, 0x55, 0x45,
+	0x5f, 0x4f, 0x50, 0x45, 0x52, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x53, 0x55, 0x42, 0x54, 0x52,
+	0x41, 0x43, 0x54, 0x10, 0x02, 0x12, 0x21, 0x0a, 0x1d, 0x54, 0x52, 0x45, 0x45, 0x5f, 0x56, 0x41,
+	0x4c, 0x55, 0x45, 0x5f, 0x4f, 0x50, 0x45, 0x52, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x4d, 0x55,
+	0x4c, 0x54, 0x49, 0x50, 0x4c, 0x59, 0x10, 0x03, 0x12, 0x1f, 0x0a, 0x1b, 0x54, 0x52, 0x45, 0x45,
+	0x5f, 0x56, 0x41, 0x4c, 0x55, 0x45, 0x5f, 0x4f, 0x50, 0x45, 0x52, 0x41, 0x54, 0x49, 0x4f, 0x4e,
+	0x5f, 0x44, 0x49, 0x56, 0x49, 0x44, 0x45, 0x10, 0x04, 0x2a, 0x62, 0x0a, 0x15, 0x46, 0x75, 0x6e,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x61, 0x6e, 0x6b, 0x69,
+	0x6e, 0x67, 0x12, 0x24, 0x0a, 0x20, 0x46, 0x55, 0x4e, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x43,
+	0x48, 0x41, 0x4e, 0x47, 0x45, 0x5f, 0x52, 0x41, 0x4e, 0x4b, 0x49, 0x4e, 0x47, 0x5f, 0x41, 0x42,
+	0x53, 0x4f, 0x4c, 0x55, 0x54, 0x45, 0x10, 0x00, 0x12, 0x23, 0x0a, 0x1f, 0x46, 0x55, 0x4e, 0x43,
+	0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x43, 0x48, 0x41, 0x4e, 0x47, 0x45, 0x5f, 0x52, 0x41, 0x4e, 0x4b,
+	0x49, 0x4e, 0x47, 0x5f, 0x50, 0x45, 0x52, 0x43, 0x45, 0x4e, 0x54, 0x10, 0x01, 0x2a, 0x4d, 0x0a,
+	0x08, 0x44, 0x69, 0x66, 0x66, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x13, 0x0a, 0x0f, 0x44, 0x49, 0x46,
+	0x46, 0x5f, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x44, 0x45, 0x4c, 0x54, 0x41, 0x10, 0x00, 0x12, 0x13,
+	0x0a, 0x0f, 0x44, 0x49, 0x46, 0x46, 0x5f, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x52, 0x41, 0x54, 0x49,
+	0x4f, 0x10, 0x01, 0x12, 0x17, 0x0a, 0x13, 0x44, 0x49, 0x46, 0x46, 0x5f, 0x4d, 0x4f, 0x44, 0x45,
+	0x5f, 0x4c, 0x4f, 0x47, 0x5f, 0x52, 0x41, 0x54, 0x49, 0x4f, 0x10, 0x02, 0x2a, 0x66, 0x0a, 0x17,
+	0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x53, 0x69, 0x6d, 0x69, 0x6c, 0x61, 0x72, 0x69, 0x74,
+	0x79, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x12, 0x25, 0x0a, 0x21, 0x50, 0x52, 0x4f, 0x46, 0x49,
+	0x4c, 0x45, 0x5f, 0x53, 0x49, 0x4d, 0x49, 0x4c, 0x41, 0x52, 0x49, 0x54, 0x59, 0x5f, 0x4d, 0x45,
+	0x54, 0x52, 0x49, 0x43, 0x5f, 0x4a, 0x41, 0x43, 0x43, 0x41, 0x52, 0x44, 0x10, 0x00, 0x12, 0x24,
+	0x0a, 0x20, 0x50, 0x52, 0x4f, 0x46, 0x49, 0x4c, 0x45, 0x5f, 0x53, 0x49, 0x4d, 0x49, 0x4c, 0x41,
+	0x52, 0x49, 0x54, 0x59, 0x5f, 0x4d, 0x45, 0x54, 0x52, 0x49, 0x43, 0x5f, 0x43, 0x4f, 0x53, 0x49,
+	0x4e, 0x45, 0x10, 0x01, 0x32, 0xb7, 0x01, 0x0a, 0x13, 0x51, 0x75, 0x65, 0x72, 0x79, 0x42, 0x61,
+	0x63, 0x6b, 0x65, 0x6e, 0x64, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x4b, 0x0a, 0x06,
+	0x49, 0x6e, 0x76, 0x6f, 0x6b, 0x65, 0x12, 0x1e, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61,
+	0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x6e, 0x76, 0x6f, 0x6b, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61,
+	0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x6e, 0x76, 0x6f, 0x6b, 0x65, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x53, 0x0a, 0x0c, 0x49, 0x6e, 0x76,
+	0x6f, 0x6b, 0x65, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x1e, 0x2e, 0x71, 0x75, 0x65, 0x72,
+	0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x6e, 0x76, 0x6f,
+	0x6b, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x71, 0x75, 0x65, 0x72,
+	0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x6e, 0x76, 0x6f,
+	0x6b, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x30, 0x01, 0x42, 0xd3,
+	0x01, 0x0a, 0x13, 0x63, 0x6f, 0x6d, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b,
+	0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x42, 0x11, 0x51, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63,
+	0x6b, 0x65, 0x6e, 0x64, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x50, 0x01, 0x5a, 0x4c, 0x67, 0x69, 0x74,
+	0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x72, 0x61, 0x66, 0x61, 0x6e, 0x61, 0x2f,
+	0x70, 0x79, 0x72, 0x6f, 0x73, 0x63, 0x6f, 0x70, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x67, 0x65,
+	0x6e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x67, 0x6f, 0x2f, 0x71, 0x75, 0x65, 0x72, 0x79,
+	0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2f, 0x76, 0x31, 0x3b, 0x71, 0x75, 0x65, 0x72, 0x79,
+	0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x76, 0x31, 0xa2, 0x02, 0x03, 0x51, 0x58, 0x58, 0xaa,
+	0x02, 0x0f, 0x51, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x56,
+	0x31, 0xca, 0x02, 0x0f, 0x51, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64,
+	0x5c, 0x56, 0x31, 0xe2, 0x02, 0x1b, 0x51, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65,
+	0x6e, 0x64, 0x5c, 0x56, 0x31, 0x5c, 0x47, 0x50, 0x42, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74,
+	0x61, 0xea, 0x02, 0x10, 0x51, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64,
+	0x3a, 0x3a, 0x56, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_querybackend_v1_querybackend_proto_rawDescOnce sync.Once
+	file_querybackend_v1_querybackend_proto_rawDescData = file_querybackend_v1_querybackend_proto_rawDesc
+)
+
+func file_querybackend_v1_querybackend_proto_rawDescGZIP() []byte {
+	file_querybackend_v1_querybackend_proto_rawDescOnce.Do(func() {
+		file_querybackend_v1_querybackend_proto_rawDescData = protoimpl.X.CompressGZIP(file_querybackend_v1_querybackend_proto_rawDescData)
+	})
+	return file_querybackend_v1_querybackend_proto_rawDescData
+}
+
+var file_querybackend_v1_querybackend_proto_enumTypes = make([]protoimpl.EnumInfo, 7)
+var file_querybackend_v1_querybackend_proto_msgTypes = make([]protoimpl.MessageInfo, 84)
+var file_querybackend_v1_querybackend_proto_goTypes = []any{
+	(QueryType)(0),                     // 0: querybackend.v1.QueryType
+	(SkipReason)(0),                    // 1: querybackend.v1.SkipReason
+	(ReportType)(0),                    // 2: querybackend.v1.ReportType
+	(TreeValueOperation)(0),            // 3: querybackend.v1.TreeValueOperation
+	(FunctionChangeRanking)(0),         // 4: querybackend.v1.FunctionChangeRanking
+	(DiffMode)(0),                      // 5: querybackend.v1.DiffMode
+	(ProfileSimilarityMetric)(0),       // 6: querybackend.v1.ProfileSimilarityMetric
+	(*InvokeOptions)(nil),              // 7: querybackend.v1.InvokeOptions
+	(*InvokeRequest)(nil),              // 8: querybackend.v1.InvokeRequest
+	(*QueryPlan)(nil),                  // 9: querybackend.v1.QueryPlan
+	(*Query)(nil),                      // 10: querybackend.v1.Query
+	(*InvokeResponse)(nil),             // 11: querybackend.v1.InvokeResponse
+	(*Diagnostics)(nil),                // 12: querybackend.v1.Diagnostics
+	(*FailedRegion)(nil),               // 13: querybackend.v1.FailedRegion
+	(*SkippedBlock)(nil),               // 14: querybackend.v1.SkippedBlock
+	(*Report)(nil),                     // 15: querybackend.v1.Report
+	(*LabelNamesQuery)(nil),            // 16: querybackend.v1.LabelNamesQuery
+	(*LabelNamesReport)(nil),           // 17: querybackend.v1.LabelNamesReport
+	(*LabelValuesQuery)(nil),           // 18: querybackend.v1.LabelValuesQuery
+	(*LabelValuesReport)(nil),          // 19: querybackend.v1.LabelValuesReport
+	(*SeriesLabelsQuery)(nil),          // 20: querybackend.v1.SeriesLabelsQuery
+	(*SeriesLabelsReport)(nil),         // 21: querybackend.v1.SeriesLabelsReport
+	(*TimeSeriesQuery)(nil),            // 22: querybackend.v1.TimeSeriesQuery
+	(*TimeSeriesReport)(nil),           // 23: querybackend.v1.TimeSeriesReport
+	(*TreeQuery)(nil),                  // 24: querybackend.v1.TreeQuery
+	(*TreeLegendQuery)(nil),            // 25: querybackend.v1.TreeLegendQuery
+	(*TreeLabelBuckets)(nil),           // 26: querybackend.v1.TreeLabelBuckets
+	(*TreeBucket)(nil),                 // 27: querybackend.v1.TreeBucket
+	(*TreeValueCombination)(nil),       // 28: querybackend.v1.TreeValueCombination
+	(*TreeBaseline)(nil),               // 29: querybackend.v1.TreeBaseline
+	(*RelabelRule)(nil),                // 30: querybackend.v1.RelabelRule
+	(*TreeReport)(nil),                 // 31: querybackend.v1.TreeReport
+	(*TreeLegend)(nil),                 // 32: querybackend.v1.TreeLegend
+	(*TreeLegendEntry)(nil),            // 33: querybackend.v1.TreeLegendEntry
+	(*TreeSampleType)(nil),             // 34: querybackend.v1.TreeSampleType
+	(*TreeCoverage)(nil),               // 35: querybackend.v1.TreeCoverage
+	(*TreeNodeCoverage)(nil),           // 36: querybackend.v1.TreeNodeCoverage
+	(*TreeLabelValues)(nil),            // 37: querybackend.v1.TreeLabelValues
+	(*TreeNodeLabelValues)(nil),        // 38: querybackend.v1.TreeNodeLabelValues
+	(*TreeSourceLocations)(nil),        // 39: querybackend.v1.TreeSourceLocations
+	(*TreeNodeSourceLocation)(nil),     // 40: querybackend.v1.TreeNodeSourceLocation
+	(*MultiValueTree)(nil),             // 41: querybackend.v1.MultiValueTree
+	(*MultiValueTreeNode)(nil),         // 42: querybackend.v1.MultiValueTreeNode
+	(*TreeAttribution)(nil),            // 43: querybackend.v1.TreeAttribution
+	(*TreeNodeAttribution)(nil),        // 44: querybackend.v1.TreeNodeAttribution
+	(*TimeRangeQuery)(nil),             // 45: querybackend.v1.TimeRangeQuery
+	(*TimeRangeReport)(nil),            // 46: querybackend.v1.TimeRangeReport
+	(*CallGraphQuery)(nil),             // 47: querybackend.v1.CallGraphQuery
+	(*CallGraphReport)(nil),            // 48: querybackend.v1.CallGraphReport
+	(*CallGraphNode)(nil),              // 49: querybackend.v1.CallGraphNode
+	(*CallGraphEdge)(nil),              // 50: querybackend.v1.CallGraphEdge
+	(*SelfTestQuery)(nil),              // 51: querybackend.v1.SelfTestQuery
+	(*SelfTestReport)(nil),             // 52: querybackend.v1.SelfTestReport
+	(*SelfTestDataset)(nil),            // 53: querybackend.v1.SelfTestDataset
+	(*SelfTestSection)(nil),            // 54: querybackend.v1.SelfTestSection
+	(*ManifestQuery)(nil),              // 55: querybackend.v1.ManifestQuery
+	(*ManifestReport)(nil),             // 56: querybackend.v1.ManifestReport
+	(*ProfileDescriptor)(nil),          // 57: querybackend.v1.ProfileDescriptor
+	(*SymbolTableQuery)(nil),           // 58: querybackend.v1.SymbolTableQuery
+	(*SymbolTableReport)(nil),          // 59: querybackend.v1.SymbolTableReport
+	(*SymbolMapping)(nil),              // 60: querybackend.v1.SymbolMapping
+	(*SymbolLocation)(nil),             // 61: querybackend.v1.SymbolLocation
+	(*FunctionChangesQuery)(nil),       // 62: querybackend.v1.FunctionChangesQuery
+	(*FunctionChangesReport)(nil),      // 63: querybackend.v1.FunctionChangesReport
+	(*FunctionChange)(nil),             // 64: querybackend.v1.FunctionChange
+	(*FlameGraphDiffQuery)(nil),        // 65: querybackend.v1.FlameGraphDiffQuery
+	(*FlameGraphDiffReport)(nil),       // 66: querybackend.v1.FlameGraphDiffReport
+	(*FlameGraphDiffLevel)(nil),        // 67: querybackend.v1.FlameGraphDiffLevel
+	(*TreeDiffQuery)(nil),              // 68: querybackend.v1.TreeDiffQuery
+	(*TreeDiffSet)(nil),                // 69: querybackend.v1.TreeDiffSet
+	(*TreeDiffReport)(nil),             // 70: querybackend.v1.TreeDiffReport
+	(*TreeDiffNode)(nil),               // 71: querybackend.v1.TreeDiffNode
+	(*StacktracesQuery)(nil),           // 72: querybackend.v1.StacktracesQuery
+	(*StacktracesReport)(nil),          // 73: querybackend.v1.StacktracesReport
+	(*StacktracePartition)(nil),        // 74: querybackend.v1.StacktracePartition
+	(*StacktraceFrames)(nil),           // 75: querybackend.v1.StacktraceFrames
+	(*StackDepthQuery)(nil),            // 76: querybackend.v1.StackDepthQuery
+	(*StackDepthReport)(nil),           // 77: querybackend.v1.StackDepthReport
+	(*StackDepthBucket)(nil),           // 78: querybackend.v1.StackDepthBucket
+	(*ProfileDeviationQuery)(nil),      // 79: querybackend.v1.ProfileDeviationQuery
+	(*ProfileDeviationReport)(nil),     // 80: querybackend.v1.ProfileDeviationReport
+	(*ProfileDeviationNode)(nil),       // 81: querybackend.v1.ProfileDeviationNode
+	(*ProfileSimilarityQuery)(nil),     // 82: querybackend.v1.ProfileSimilarityQuery
+	(*ProfileSimilarityReport)(nil),    // 83: querybackend.v1.ProfileSimilarityReport
+	(*SimilarityFunction)(nil),         // 84: querybackend.v1.SimilarityFunction
+	(*TopCallersQuery)(nil),            // 85: querybackend.v1.TopCallersQuery
+	(*TopCallersReport)(nil),           // 86: querybackend.v1.TopCallersReport
+	(*TopCaller)(nil),                  // 87: querybackend.v1.TopCaller
+	(*TopProfilesQuery)(nil),           // 88: querybackend.v1.TopProfilesQuery
+	(*TopProfilesReport)(nil),          // 89: querybackend.v1.TopProfilesReport
+	(*TopProfile)(nil),                 // 90: querybackend.v1.TopProfile
+	(*v1.BlockMeta)(nil),               // 91: metastore.v1.BlockMeta
+	(*v11.Labels)(nil),                 // 92: types.v1.Labels
+	(v11.TimeSeriesAggregationType)(0), // 93: types.v1.TimeSeriesAggregationType
+	(*v11.Series)(nil),                 // 94: types.v1.Series
+	(*v11.LabelPair)(nil),              // 95: types.v1.LabelPair
+	(*v12.FlameGraphDiff)(nil),         // 96: querier.v1.FlameGraphDiff
+}
+var file_querybackend_v1_querybackend_proto_depIdxs = []int32{
+	10,  // 0: querybackend.v1.InvokeRequest.query:type_name -> querybackend.v1.Query
+	9,   // 1: querybackend.v1.InvokeRequest.query_plan:type_name -> querybackend.v1.QueryPlan
+	7,   // 2: querybackend.v1.InvokeRequest.options:type_name -> querybackend.v1.InvokeOptions
+	91,  // 3: querybackend.v1.QueryPlan.blocks:type_name -> metastore.v1.BlockMeta
+	0,   // 4: querybackend.v1.Query.query_type:type_name -> querybackend.v1.QueryType
+	16,  // 5: querybackend.v1.Query.label_names:type_name -> querybackend.v1.LabelNamesQuery
+	18,  // 6: querybackend.v1.Query.label_values:type_name -> querybackend.v1.LabelValuesQuery
+	20,  // 7: querybackend.v1.Query.series_labels:type_name -> querybackend.v1.SeriesLabelsQuery
+	22,  // 8: querybackend.v1.Query.time_series:type_name -> querybackend.v1.TimeSeriesQuery
+	24,  // 9: querybackend.v1.Query.tree:type_name -> querybackend.v1.TreeQuery
+	45,  // 10: querybackend.v1.Query.time_range:type_name -> querybackend.v1.TimeRangeQuery
+	47,  // 11: querybackend.v1.Query.call_graph:type_name -> querybackend.v1.CallGraphQuery
+	51,  // 12: querybackend.v1.Query.self_test:type_name -> querybackend.v1.SelfTestQuery
+	55,  // 13: querybackend.v1.Query.manifest:type_name -> querybackend.v1.ManifestQuery
+	58,  // 14: querybackend.v1.Query.symbol_table:type_name -> querybackend.v1.SymbolTableQuery
+	62,  // 15: querybackend.v1.Query.function_changes:type_name -> querybackend.v1.FunctionChangesQuery
+	65,  // 16: querybackend.v1.Query.flamegraph_diff:type_name -> querybackend.v1.FlameGraphDiffQuery
+	72,  // 17: querybackend.v1.Query.stacktraces:type_name -> querybackend.v1.StacktracesQuery
+	76,  // 18: querybackend.v1.Query.stack_depth:type_name -> querybackend.v1.StackDepthQuery
+	79,  // 19: querybackend.v1.Query.profile_deviation:type_name -> querybackend.v1.ProfileDeviationQuery
+	82,  // 20: querybackend.v1.Query.profile_similarity:type_name -> querybackend.v1.ProfileSimilarityQuery
+	85,  // 21: querybackend.v1.Query.top_callers:type_name -> querybackend.v1.TopCallersQuery
+	88,  // 22: querybackend.v1.Query.top_profiles:type_name -> querybackend.v1.TopProfilesQuery
+	68,  // 23: querybackend.v1.Query.tree_diff:type_name -> querybackend.v1.TreeDiffQuery
+	15,  // 24: querybackend.v1.InvokeResponse.reports:type_name -> querybackend.v1.Report
+	12,  // 25: querybackend.v1.InvokeResponse.diagnostics:type_name -> querybackend.v1.Diagnostics
+	14,  // 26: querybackend.v1.Diagnostics.skipped_blocks:type_name -> querybackend.v1.SkippedBlock
+	13,  // 27: querybackend.v1.Diagnostics.failed_regions:type_name -> querybackend.v1.FailedRegion
+	1,   // 28: querybackend.v1.SkippedBlock.reason:type_name -> querybackend.v1.SkipReason
+	2,   // 29: querybackend.v1.Report.report_type:type_name -> querybackend.v1.ReportType
+	17,  // 30: querybackend.v1.Report.label_names:type_name -> querybackend.v1.LabelNamesReport
+	19,  // 31: querybackend.v1.Report.label_values:type_name -> querybackend.v1.LabelValuesReport
+	21,  // 32: querybackend.v1.Report.series_labels:type_name -> querybackend.v1.SeriesLabelsReport
+	23,  // 33: querybackend.v1.Report.time_series:type_name -> querybackend.v1.TimeSeriesReport
+	31,  // 34: querybackend.v1.Report.tree:type_name -> querybackend.v1.TreeReport
+	46,  // 35: querybackend.v1.Report.time_range:type_name -> querybackend.v1.TimeRangeReport
+	48,  // 36: querybackend.v1.Report.call_graph:type_name -> querybackend.v1.CallGraphReport
+	52,  // 37: querybackend.v1.Report.self_test:type_name -> querybackend.v1.SelfTestReport
+	56,  // 38: querybackend.v1.Report.manifest:type_name -> querybackend.v1.ManifestReport
+	59,  // 39: querybackend.v1.Report.symbol_table:type_name -> querybackend.v1.SymbolTableReport
+	63,  // 40: querybackend.v1.Report.function_changes:type_name -> querybackend.v1.FunctionChangesReport
+	66,  // 41: querybackend.v1.Report.flamegraph_diff:type_name -> querybackend.v1.FlameGraphDiffReport
+	73,  // 42: querybackend.v1.Report.stacktraces:type_name -> querybackend.v1.StacktracesReport
+	77,  // 43: querybackend.v1.Report.stack_depth:type_name -> querybackend.v1.StackDepthReport
+	80,  // 44: querybackend.v1.Report.profile_deviation:type_name -> querybackend.v1.ProfileDeviationReport
+	83,  // 45: querybackend.v1.Report.profile_similarity:type_name -> querybackend.v1.ProfileSimilarityReport
+	86,  // 46: querybackend.v1.Report.top_callers:type_name -> querybackend.v1.TopCallersReport
+	89,  // 47: querybackend.v1.Report.top_profiles:type_name -> querybackend.v1.TopProfilesReport
+	70,  // 48: querybackend.v1.Report.tree_diff:type_name -> querybackend.v1.TreeDiffReport
+	0,   // 49: querybackend.v1.Report.query_type:type_name -> querybackend.v1.QueryType
+	16,  // 50: querybackend.v1.LabelNamesReport.query:type_name -> querybackend.v1.LabelNamesQuery
+	18,  // 51: querybackend.v1.LabelValuesReport.query:type_name -> querybackend.v1.LabelValuesQuery
+	20,  // 52: querybackend.v1.SeriesLabelsReport.query:type_name -> querybackend.v1.SeriesLabelsQuery
+	92,  // 53: querybackend.v1.SeriesLabelsReport.series_labels:type_name -> types.v1.Labels
+	93,  // 54: querybackend.v1.TimeSeriesQuery.aggregation:type_name -> types.v1.TimeSeriesAggregationType
+	22,  // 55: querybackend.v1.TimeSeriesReport.query:type_name -> querybackend.v1.TimeSeriesQuery
+	94,  // 56: querybackend.v1.TimeSeriesReport.time_series:type_name -> types.v1.Series
+	30,  // 57: querybackend.v1.TreeQuery.relabel:type_name -> querybackend.v1.RelabelRule
+	29,  // 58: querybackend.v1.TreeQuery.baseline:type_name -> querybackend.v1.TreeBaseline
+	28,  // 59: querybackend.v1.TreeQuery.value_combination:type_name -> querybackend.v1.TreeValueCombination
+	26,  // 60: querybackend.v1.TreeQuery.label_buckets:type_name -> querybackend.v1.TreeLabelBuckets
+	25,  // 61: querybackend.v1.TreeQuery.legend:type_name -> querybackend.v1.TreeLegendQuery
+	3,   // 62: querybackend.v1.TreeValueCombination.operation:type_name -> querybackend.v1.TreeValueOperation
+	24,  // 63: querybackend.v1.TreeReport.query:type_name -> querybackend.v1.TreeQuery
+	43,  // 64: querybackend.v1.TreeReport.attribution:type_name -> querybackend.v1.TreeAttribution
+	41,  // 65: querybackend.v1.TreeReport.multi_value_tree:type_name -> querybackend.v1.MultiValueTree
+	39,  // 66: querybackend.v1.TreeReport.source_locations:type_name -> querybackend.v1.TreeSourceLocations
+	37,  // 67: querybackend.v1.TreeReport.label_values:type_name -> querybackend.v1.TreeLabelValues
+	35,  // 68: querybackend.v1.TreeReport.coverage:type_name -> querybackend.v1.TreeCoverage
+	27,  // 69: querybackend.v1.TreeReport.buckets:type_name -> querybackend.v1.TreeBucket
+	34,  // 70: querybackend.v1.TreeReport.sample_types:type_name -> querybackend.v1.TreeSampleType
+	37,  // 71: querybackend.v1.TreeReport.goroutine_states:type_name -> querybackend.v1.TreeLabelValues
+	32,  // 72: querybackend.v1.TreeReport.legend:type_name -> querybackend.v1.TreeLegend
+	33,  // 73: querybackend.v1.TreeLegend.entries:type_name -> querybackend.v1.TreeLegendEntry
+	36,  // 74: querybackend.v1.TreeCoverage.nodes:type_name -> querybackend.v1.TreeNodeCoverage
+	38,  // 75: querybackend.v1.TreeLabelValues.nodes:type_name -> querybackend.v1.TreeNodeLabelValues
+	40,  // 76: querybackend.v1.TreeSourceLocations.nodes:type_name -> querybackend.v1.TreeNodeSourceLocation
+	42,  // 77: querybackend.v1.MultiValueTree.nodes:type_name -> querybackend.v1.MultiValueTreeNode
+	44,  // 78: querybackend.v1.TreeAttribution.nodes:type_name -> querybackend.v1.TreeNodeAttribution
+	45,  // 79: querybackend.v1.TimeRangeReport.query:type_name -> querybackend.v1.TimeRangeQuery
+	47,  // 80: querybackend.v1.CallGraphReport.query:type_name -> querybackend.v1.CallGraphQuery
+	49,  // 81: querybackend.v1.CallGraphReport.nodes:type_name -> querybackend.v1.CallGraphNode
+	50,  // 82: querybackend.v1.CallGraphReport.edges:type_name -> querybackend.v1.CallGraphEdge
+	51,  // 83: querybackend.v1.SelfTestReport.query:type_name -> querybackend.v1.SelfTestQuery
+	53,  // 84: querybackend.v1.SelfTestReport.datasets:type_name -> querybackend.v1.SelfTestDataset
+	54,  // 85: querybackend.v1.SelfTestDataset.sections:type_name -> querybackend.v1.SelfTestSection
+	55,  // 86: querybackend.v1.ManifestReport.query:type_name -> querybackend.v1.ManifestQuery
+	57,  // 87: querybackend.v1.ManifestReport.profiles:type_name -> querybackend.v1.ProfileDescriptor
+	95,  // 88: querybackend.v1.ProfileDescriptor.labels:type_name -> types.v1.LabelPair
+	58,  // 89: querybackend.v1.SymbolTableReport.query:type_name -> querybackend.v1.SymbolTableQuery
+	60,  // 90: querybackend.v1.SymbolTableReport.mappings:type_name -> querybackend.v1.SymbolMapping
+	61,  // 91: querybackend.v1.SymbolTableReport.locations:type_name -> querybackend.v1.SymbolLocation
+	4,   // 92: querybackend.v1.FunctionChangesQuery.ranking:type_name -> querybackend.v1.FunctionChangeRanking
+	62,  // 93: querybackend.v1.FunctionChangesReport.query:type_name -> querybackend.v1.FunctionChangesQuery
+	64,  // 94: querybackend.v1.FunctionChangesReport.functions:type_name -> querybackend.v1.FunctionChange
+	5,   // 95: querybackend.v1.FlameGraphDiffQuery.diff_mode:type_name -> querybackend.v1.DiffMode
+	65,  // 96: querybackend.v1.FlameGraphDiffReport.query:type_name -> querybackend.v1.FlameGraphDiffQuery
+	96,  // 97: querybackend.v1.FlameGraphDiffReport.flamegraph:type_name -> querier.v1.FlameGraphDiff
+	67,  // 98: querybackend.v1.FlameGraphDiffReport.diff_levels:type_name -> querybackend.v1.FlameGraphDiffLevel
+	69,  // 99: querybackend.v1.TreeDiffQuery.baseline:type_name -> querybackend.v1.TreeDiffSet
+	69,  // 100: querybackend.v1.TreeDiffQuery.comparison:type_name -> querybackend.v1.TreeDiffSet
+	5,   // 101: querybackend.v1.TreeDiffQuery.diff_mode:type_name -> querybackend.v1.DiffMode
+	68,  // 102: querybackend.v1.TreeDiffReport.query:type_name -> querybackend.v1.TreeDiffQuery
+	71,  // 103: querybackend.v1.TreeDiffReport.nodes:type_name -> querybackend.v1.TreeDiffNode
+	72,  // 104: querybackend.v1.StacktracesReport.query:type_name -> querybackend.v1.StacktracesQuery
+	74,  // 105: querybackend.v1.StacktracesReport.partitions:type_name -> querybackend.v1.StacktracePartition
+	75,  // 106: querybackend.v1.StacktracePartition.stacktraces:type_name -> querybackend.v1.StacktraceFrames
+	76,  // 107: querybackend.v1.StackDepthReport.query:type_name -> querybackend.v1.StackDepthQuery
+	78,  // 108: querybackend.v1.StackDepthReport.buckets:type_name -> querybackend.v1.StackDepthBucket
+	79,  // 109: querybackend.v1.ProfileDeviationReport.query:type_name -> querybackend.v1.ProfileDeviationQuery
+	81,  // 110: querybackend.v1.ProfileDeviationReport.nodes:type_name -> querybackend.v1.ProfileDeviationNode
+	6,   // 111: querybackend.v1.ProfileSimilarityQuery.metric:type_name -> querybackend.v1.ProfileSimilarityMetric
+	82,  // 112: querybackend.v1.ProfileSimilarityReport.query:type_name -> querybackend.v1.ProfileSimilarityQuery
+	84,  // 113: querybackend.v1.ProfileSimilarityReport.functions:type_name -> querybackend.v1.SimilarityFunction
+	85,  // 114: querybackend.v1.TopCallersReport.query:type_name -> querybackend.v1.TopCallersQuery
+	87,  // 115: querybackend.v1.TopCallersReport.callers:type_name -> querybackend.v1.TopCaller
+	88,  // 116: querybackend.v1.TopProfilesReport.query:type_name -> querybackend.v1.TopProfilesQuery
+	90,  // 117: querybackend.v1.TopProfilesReport.profiles:type_name -> querybackend.v1.TopProfile
+	95,  // 118: querybackend.v1.TopProfile.labels:type_name -> types.v1.LabelPair
+	8,   // 119: querybackend.v1.QueryBackendService.Invoke:input_type -> querybackend.v1.InvokeRequest
+	8,   // 120: querybackend.v1.QueryBackendService.InvokeStream:input_type -> querybackend.v1.InvokeRequest
+	11,  // 121: querybackend.v1.QueryBackendService.Invoke:output_type -> querybackend.v1.InvokeResponse
+	11,  // 122: querybackend.v1.QueryBackendService.InvokeStream:output_type -> querybackend.v1.InvokeResponse
+	121, // [121:123] is the sub-list for method output_type
+	119, // [119:121] is the sub-list for method input_type
+	119, // [119:119] is the sub-list for extension type_name
+	119, // [119:119] is the sub-list for extension extendee
+	0,   // [0:119] is the sub-list for field type_name
+}
+
+func init() { file_querybackend_v1_querybackend_proto_init() }
+func file_querybackend_v1_querybackend_proto_init() {
+	if File_querybackend_v1_querybackend_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_querybackend_v1_querybackend_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*InvokeOptions); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*InvokeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*QueryPlan); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*Query); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*InvokeResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*Diagnostics); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*FailedRegion); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*SkippedBlock); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*Report); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*LabelNamesQuery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[10].Exporter = func(v any, i int) any {
+			switch v := v.(*LabelNamesReport); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[11].Exporter = func(v any, i int) any {
+			switch v := v.(*LabelValuesQuery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[12].Exporter = func(v any, i int) any {
+			switch v := v.(*LabelValuesReport); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[13].Exporter = func(v any, i int) any {
+			switch v := v.(*SeriesLabelsQuery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[14].Exporter = func(v any, i int) any {
+			switch v := v.(*SeriesLabelsReport); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[15].Exporter = func(v any, i int) any {
+			switch v := v.(*TimeSeriesQuery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[16].Exporter = func(v any, i int) any {
+			switch v := v.(*TimeSeriesReport); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[17].Exporter = func(v any, i int) any {
+			switch v := v.(*TreeQuery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[18].Exporter = func(v any, i int) any {
+			switch v := v.(*TreeLegendQuery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[19].Exporter = func(v any, i int) any {
+			switch v := v.(*TreeLabelBuckets); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[20].Exporter = func(v any, i int) any {
+			switch v := v.(*TreeBucket); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[21].Exporter = func(v any, i int) any {
+			switch v := v.(*TreeValueCombination); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[22].Exporter = func(v any, i int) any {
+			switch v := v.(*TreeBaseline); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[23].Exporter = func(v any, i int) any {
+			switch v := v.(*RelabelRule); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[24].Exporter = func(v any, i int) any {
+			switch v := v.(*TreeReport); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[25].Exporter = func(v any, i int) any {
+			switch v := v.(*TreeLegend); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[26].Exporter = func(v any, i int) any {
+			switch v := v.(*TreeLegendEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[27].Exporter = func(v any, i int) any {
+			switch v := v.(*TreeSampleType); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[28].Exporter = func(v any, i int) any {
+			switch v := v.(*TreeCoverage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[29].Exporter = func(v any, i int) any {
+			switch v := v.(*TreeNodeCoverage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[30].Exporter = func(v any, i int) any {
+			switch v := v.(*TreeLabelValues); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[31].Exporter = func(v any, i int) any {
+			switch v := v.(*TreeNodeLabelValues); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[32].Exporter = func(v any, i int) any {
+			switch v := v.(*TreeSourceLocations); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[33].Exporter = func(v any, i int) any {
+			switch v := v.(*TreeNodeSourceLocation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[34].Exporter = func(v any, i int) any {
+			switch v := v.(*MultiValueTree); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[35].Exporter = func(v any, i int) any {
+			switch v := v.(*MultiValueTreeNode); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[36].Exporter = func(v any, i int) any {
+			switch v := v.(*TreeAttribution); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[37].Exporter = func(v any, i int) any {
+			switch v := v.(*TreeNodeAttribution); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[38].Exporter = func(v any, i int) any {
+			switch v := v.(*TimeRangeQuery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[39].Exporter = func(v any, i int) any {
+			switch v := v.(*TimeRangeReport); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[40].Exporter = func(v any, i int) any {
+			switch v := v.(*CallGraphQuery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[41].Exporter = func(v any, i int) any {
+			switch v := v.(*CallGraphReport); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[42].Exporter = func(v any, i int) any {
+			switch v := v.(*CallGraphNode); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[43].Exporter = func(v any, i int) any {
+			switch v := v.(*CallGraphEdge); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[44].Exporter = func(v any, i int) any {
+			switch v := v.(*SelfTestQuery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[45].Exporter = func(v any, i int) any {
+			switch v := v.(*SelfTestReport); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[46].Exporter = func(v any, i int) any {
+			switch v := v.(*SelfTestDataset); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[47].Exporter = func(v any, i int) any {
+			switch v := v.(*SelfTestSection); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[48].Exporter = func(v any, i int) any {
+			switch v := v.(*ManifestQuery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[49].Exporter = func(v any, i int) any {
+			switch v := v.(*ManifestReport); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[50].Exporter = func(v any, i int) any {
+			switch v := v.(*ProfileDescriptor); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[51].Exporter = func(v any, i int) any {
+			switch v := v.(*SymbolTableQuery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[52].Exporter = func(v any, i int) any {
+			switch v := v.(*SymbolTableReport); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[53].Exporter = func(v any, i int) any {
+			switch v := v.(*SymbolMapping); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[54].Exporter = func(v any, i int) any {
+			switch v := v.(*SymbolLocation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[55].Exporter = func(v any, i int) any {
+			switch v := v.(*FunctionChangesQuery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[56].Exporter = func(v any, i int) any {
+			switch v := v.(*FunctionChangesReport); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[57].Exporter = func(v any, i int) any {
+			switch v := v.(*FunctionChange); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[58].Exporter = func(v any, i int) any {
+			switch v := v.(*FlameGraphDiffQuery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[59].Exporter = func(v any, i int) any {
+			switch v := v.(*FlameGraphDiffReport); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[60].Exporter = func(v any, i int) any {
+			switch v := v.(*FlameGraphDiffLevel); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[61].Exporter = func(v any, i int) any {
+			switch v := v.(*TreeDiffQuery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[62].Exporter = func(v any, i int) any {
+			switch v := v.(*TreeDiffSet); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
 		}
-		return ms
-	}
-	return mi.MessageOf(x)
-}
-
-// Deprecated: Use TreeQuery.ProtoReflect.Descriptor instead.
-func (*TreeQuery) Descriptor() ([]byte, []int) {
-	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{15}
-}
-
-func (x *TreeQuery) GetMaxNodes() int64 {
-	if x != nil {
-		return x.MaxNodes
-	}
-	return 0
-}
-
-type TreeReport struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-
-	Query *TreeQuery `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
-	Tree  []byte     `protobuf:"bytes,2,opt,name=tree,proto3" json:"tree,omitempty"`
-}
-
-func (x *TreeReport) Reset() {
-	*x = TreeReport{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_querybackend_v1_querybackend_proto_msgTypes[16]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
-}
-
-func (x *TreeReport) String() string {
-	return protoimpl.X.MessageStringOf(x)
-}
-
-func (*TreeReport) ProtoMessage() {}
-
-func (x *TreeReport) ProtoReflect() protoreflect.Message {
-	mi := &file_querybackend_v1_querybackend_proto_msgTypes[16]
-	if protoimpl.UnsafeEnabled && x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
+		file_querybackend_v1_querybackend_proto_msgTypes[63].Exporter = func(v any, i int) any {
+			switch v := v.(*TreeDiffReport); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
 		}
-		return ms
-	}
-	return mi.MessageOf(x)
-}
-
-// Deprecated: Use TreeReport.ProtoReflect.Descriptor instead.
-func (*TreeReport) Descriptor() ([]byte, []int) {
-	return file_querybackend_v1_querybackend_proto_rawDescGZIP(), []int{16}
-}
-
-func (x *TreeReport) GetQuery() *TreeQuery {
-	if x != nil {
-		return x.Query
-	}
-	return nil
-}
-
-func (x *TreeReport) GetTree() []byte {
-	if x != nil {
-		return x.Tree
-	}
-	return nil
-}
-
-var File_querybackend_v1_querybackend_proto protoreflect.FileDescriptor
-
-var file_querybackend_v1_querybackend_proto_rawDesc = []byte{
-	0x0a, 0x22, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2f, 0x76,
-	0x31, 0x2f, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0f, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65,
-	0x6e, 0x64, 0x2e, 0x76, 0x31, 0x1a, 0x17, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x76, 0x31,
-	0x2f, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1c,
-	0x6d, 0x65, 0x74, 0x61, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x2f, 0x76, 0x31, 0x2f, 0x6d, 0x65, 0x74,
-	0x61, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x14, 0x74, 0x79,
-	0x70, 0x65, 0x73, 0x2f, 0x76, 0x31, 0x2f, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x22, 0x0f, 0x0a, 0x0d, 0x49, 0x6e, 0x76, 0x6f, 0x6b, 0x65, 0x4f, 0x70, 0x74, 0x69,
-	0x6f, 0x6e, 0x73, 0x22, 0xab, 0x02, 0x0a, 0x0d, 0x49, 0x6e, 0x76, 0x6f, 0x6b, 0x65, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x18,
-	0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x12, 0x1d, 0x0a,
-	0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x03, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x19, 0x0a, 0x08,
-	0x65, 0x6e, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07,
-	0x65, 0x6e, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x25, 0x0a, 0x0e, 0x6c, 0x61, 0x62, 0x65, 0x6c,
-	0x5f, 0x73, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x0d, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x53, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x2c,
-	0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e,
-	0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e,
-	0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x39, 0x0a, 0x0a,
-	0x71, 0x75, 0x65, 0x72, 0x79, 0x5f, 0x70, 0x6c, 0x61, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x1a, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e,
-	0x76, 0x31, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x50, 0x6c, 0x61, 0x6e, 0x52, 0x09, 0x71, 0x75,
-	0x65, 0x72, 0x79, 0x50, 0x6c, 0x61, 0x6e, 0x12, 0x38, 0x0a, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f,
-	0x6e, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79,
-	0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x6e, 0x76, 0x6f, 0x6b,
-	0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e,
-	0x73, 0x22, 0x52, 0x0a, 0x09, 0x51, 0x75, 0x65, 0x72, 0x79, 0x50, 0x6c, 0x61, 0x6e, 0x12, 0x14,
-	0x0a, 0x05, 0x67, 0x72, 0x61, 0x70, 0x68, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x05, 0x67,
-	0x72, 0x61, 0x70, 0x68, 0x12, 0x2f, 0x0a, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x02,
-	0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x6d, 0x65, 0x74, 0x61, 0x73, 0x74, 0x6f, 0x72, 0x65,
-	0x2e, 0x76, 0x31, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x4d, 0x65, 0x74, 0x61, 0x52, 0x06, 0x62,
-	0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x22, 0x87, 0x03, 0x0a, 0x05, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12,
-	0x39, 0x0a, 0x0a, 0x71, 0x75, 0x65, 0x72, 0x79, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x0e, 0x32, 0x1a, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65,
-	0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x54, 0x79, 0x70, 0x65, 0x52,
-	0x09, 0x71, 0x75, 0x65, 0x72, 0x79, 0x54, 0x79, 0x70, 0x65, 0x12, 0x41, 0x0a, 0x0b, 0x6c, 0x61,
-	0x62, 0x65, 0x6c, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x20, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76,
-	0x31, 0x2e, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x51, 0x75, 0x65, 0x72,
-	0x79, 0x52, 0x0a, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x12, 0x44, 0x0a,
-	0x0c, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x03, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65,
-	0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65,
-	0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x0b, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x56, 0x61, 0x6c,
-	0x75, 0x65, 0x73, 0x12, 0x47, 0x0a, 0x0d, 0x73, 0x65, 0x72, 0x69, 0x65, 0x73, 0x5f, 0x6c, 0x61,
-	0x62, 0x65, 0x6c, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x71, 0x75, 0x65,
-	0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x72,
-	0x69, 0x65, 0x73, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x0c,
-	0x73, 0x65, 0x72, 0x69, 0x65, 0x73, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x12, 0x41, 0x0a, 0x0b,
-	0x74, 0x69, 0x6d, 0x65, 0x5f, 0x73, 0x65, 0x72, 0x69, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x20, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64,
-	0x2e, 0x76, 0x31, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x65, 0x72, 0x69, 0x65, 0x73, 0x51, 0x75,
-	0x65, 0x72, 0x79, 0x52, 0x0a, 0x74, 0x69, 0x6d, 0x65, 0x53, 0x65, 0x72, 0x69, 0x65, 0x73, 0x12,
-	0x2e, 0x0a, 0x04, 0x74, 0x72, 0x65, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
-	0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e,
-	0x54, 0x72, 0x65, 0x65, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x04, 0x74, 0x72, 0x65, 0x65, 0x22,
-	0x83, 0x01, 0x0a, 0x0e, 0x49, 0x6e, 0x76, 0x6f, 0x6b, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x12, 0x31, 0x0a, 0x07, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x18, 0x01, 0x20,
-	0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65,
-	0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x07, 0x72, 0x65,
-	0x70, 0x6f, 0x72, 0x74, 0x73, 0x12, 0x3e, 0x0a, 0x0b, 0x64, 0x69, 0x61, 0x67, 0x6e, 0x6f, 0x73,
-	0x74, 0x69, 0x63, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x71, 0x75, 0x65,
-	0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x69, 0x61,
-	0x67, 0x6e, 0x6f, 0x73, 0x74, 0x69, 0x63, 0x73, 0x52, 0x0b, 0x64, 0x69, 0x61, 0x67, 0x6e, 0x6f,
-	0x73, 0x74, 0x69, 0x63, 0x73, 0x22, 0x0d, 0x0a, 0x0b, 0x44, 0x69, 0x61, 0x67, 0x6e, 0x6f, 0x73,
-	0x74, 0x69, 0x63, 0x73, 0x22, 0x90, 0x03, 0x0a, 0x06, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12,
-	0x3c, 0x0a, 0x0b, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x0e, 0x32, 0x1b, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b,
-	0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x54, 0x79, 0x70,
-	0x65, 0x52, 0x0a, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x42, 0x0a,
-	0x0b, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x21, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e,
-	0x64, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x52,
-	0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x0a, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x4e, 0x61, 0x6d, 0x65,
-	0x73, 0x12, 0x45, 0x0a, 0x0c, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65,
-	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62,
-	0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x56,
-	0x61, 0x6c, 0x75, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x0b, 0x6c, 0x61, 0x62,
-	0x65, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x12, 0x48, 0x0a, 0x0d, 0x73, 0x65, 0x72, 0x69,
-	0x65, 0x73, 0x5f, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x23, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76,
-	0x31, 0x2e, 0x53, 0x65, 0x72, 0x69, 0x65, 0x73, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x52, 0x65,
-	0x70, 0x6f, 0x72, 0x74, 0x52, 0x0c, 0x73, 0x65, 0x72, 0x69, 0x65, 0x73, 0x4c, 0x61, 0x62, 0x65,
-	0x6c, 0x73, 0x12, 0x42, 0x0a, 0x0b, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x73, 0x65, 0x72, 0x69, 0x65,
-	0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62,
-	0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x65,
-	0x72, 0x69, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x0a, 0x74, 0x69, 0x6d, 0x65,
-	0x53, 0x65, 0x72, 0x69, 0x65, 0x73, 0x12, 0x2f, 0x0a, 0x04, 0x74, 0x72, 0x65, 0x65, 0x18, 0x06,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b,
-	0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x52, 0x65, 0x70, 0x6f, 0x72,
-	0x74, 0x52, 0x04, 0x74, 0x72, 0x65, 0x65, 0x22, 0x11, 0x0a, 0x0f, 0x4c, 0x61, 0x62, 0x65, 0x6c,
-	0x4e, 0x61, 0x6d, 0x65, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x22, 0x6b, 0x0a, 0x10, 0x4c, 0x61,
-	0x62, 0x65, 0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x36,
-	0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e,
-	0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e,
-	0x4c, 0x61, 0x62, 0x65, 0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52,
-	0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x1f, 0x0a, 0x0b, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x5f,
-	0x6e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x6c, 0x61, 0x62,
-	0x65, 0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x22, 0x31, 0x0a, 0x10, 0x4c, 0x61, 0x62, 0x65, 0x6c,
-	0x56, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x1d, 0x0a, 0x0a, 0x6c,
-	0x61, 0x62, 0x65, 0x6c, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x09, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x22, 0x6f, 0x0a, 0x11, 0x4c, 0x61,
-	0x62, 0x65, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12,
-	0x37, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21,
-	0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31,
-	0x2e, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x51, 0x75, 0x65, 0x72,
-	0x79, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x21, 0x0a, 0x0c, 0x6c, 0x61, 0x62, 0x65,
-	0x6c, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b,
-	0x6c, 0x61, 0x62, 0x65, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x22, 0x34, 0x0a, 0x11, 0x53,
-	0x65, 0x72, 0x69, 0x65, 0x73, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79,
-	0x12, 0x1f, 0x0a, 0x0b, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x18,
-	0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x4e, 0x61, 0x6d, 0x65,
-	0x73, 0x22, 0x85, 0x01, 0x0a, 0x12, 0x53, 0x65, 0x72, 0x69, 0x65, 0x73, 0x4c, 0x61, 0x62, 0x65,
-	0x6c, 0x73, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x38, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x72,
-	0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62,
-	0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x72, 0x69, 0x65, 0x73,
-	0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x05, 0x71, 0x75, 0x65,
-	0x72, 0x79, 0x12, 0x35, 0x0a, 0x0d, 0x73, 0x65, 0x72, 0x69, 0x65, 0x73, 0x5f, 0x6c, 0x61, 0x62,
-	0x65, 0x6c, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x74, 0x79, 0x70, 0x65,
-	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x52, 0x0c, 0x73, 0x65, 0x72,
-	0x69, 0x65, 0x73, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x22, 0x9c, 0x01, 0x0a, 0x0f, 0x54, 0x69,
-	0x6d, 0x65, 0x53, 0x65, 0x72, 0x69, 0x65, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x12, 0x0a,
-	0x04, 0x73, 0x74, 0x65, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x73, 0x74, 0x65,
-	0x70, 0x12, 0x19, 0x0a, 0x08, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x5f, 0x62, 0x79, 0x18, 0x02, 0x20,
-	0x03, 0x28, 0x09, 0x52, 0x07, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x42, 0x79, 0x12, 0x4a, 0x0a, 0x0b,
-	0x61, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x0e, 0x32, 0x23, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x69, 0x6d,
-	0x65, 0x53, 0x65, 0x72, 0x69, 0x65, 0x73, 0x41, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x69,
-	0x6f, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x48, 0x00, 0x52, 0x0b, 0x61, 0x67, 0x67, 0x72, 0x65, 0x67,
-	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x61, 0x67, 0x67,
-	0x72, 0x65, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x7d, 0x0a, 0x10, 0x54, 0x69, 0x6d, 0x65,
-	0x53, 0x65, 0x72, 0x69, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x36, 0x0a, 0x05,
-	0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x71, 0x75,
-	0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x69,
-	0x6d, 0x65, 0x53, 0x65, 0x72, 0x69, 0x65, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x05, 0x71,
-	0x75, 0x65, 0x72, 0x79, 0x12, 0x31, 0x0a, 0x0b, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x73, 0x65, 0x72,
-	0x69, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x74, 0x79, 0x70, 0x65,
-	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x72, 0x69, 0x65, 0x73, 0x52, 0x0a, 0x74, 0x69, 0x6d,
-	0x65, 0x53, 0x65, 0x72, 0x69, 0x65, 0x73, 0x22, 0x28, 0x0a, 0x09, 0x54, 0x72, 0x65, 0x65, 0x51,
-	0x75, 0x65, 0x72, 0x79, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f, 0x6e, 0x6f, 0x64, 0x65,
-	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x4e, 0x6f, 0x64, 0x65,
-	0x73, 0x22, 0x52, 0x0a, 0x0a, 0x54, 0x72, 0x65, 0x65, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12,
-	0x30, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
-	0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31,
-	0x2e, 0x54, 0x72, 0x65, 0x65, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72,
-	0x79, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x72, 0x65, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52,
-	0x04, 0x74, 0x72, 0x65, 0x65, 0x2a, 0x91, 0x01, 0x0a, 0x09, 0x51, 0x75, 0x65, 0x72, 0x79, 0x54,
-	0x79, 0x70, 0x65, 0x12, 0x15, 0x0a, 0x11, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x55, 0x4e, 0x53,
-	0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x15, 0x0a, 0x11, 0x51, 0x55,
-	0x45, 0x52, 0x59, 0x5f, 0x4c, 0x41, 0x42, 0x45, 0x4c, 0x5f, 0x4e, 0x41, 0x4d, 0x45, 0x53, 0x10,
-	0x01, 0x12, 0x16, 0x0a, 0x12, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x4c, 0x41, 0x42, 0x45, 0x4c,
-	0x5f, 0x56, 0x41, 0x4c, 0x55, 0x45, 0x53, 0x10, 0x02, 0x12, 0x17, 0x0a, 0x13, 0x51, 0x55, 0x45,
-	0x52, 0x59, 0x5f, 0x53, 0x45, 0x52, 0x49, 0x45, 0x53, 0x5f, 0x4c, 0x41, 0x42, 0x45, 0x4c, 0x53,
-	0x10, 0x03, 0x12, 0x15, 0x0a, 0x11, 0x51, 0x55, 0x45, 0x52, 0x59, 0x5f, 0x54, 0x49, 0x4d, 0x45,
-	0x5f, 0x53, 0x45, 0x52, 0x49, 0x45, 0x53, 0x10, 0x04, 0x12, 0x0e, 0x0a, 0x0a, 0x51, 0x55, 0x45,
-	0x52, 0x59, 0x5f, 0x54, 0x52, 0x45, 0x45, 0x10, 0x05, 0x2a, 0x98, 0x01, 0x0a, 0x0a, 0x52, 0x65,
-	0x70, 0x6f, 0x72, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x16, 0x0a, 0x12, 0x52, 0x45, 0x50, 0x4f,
-	0x52, 0x54, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00,
-	0x12, 0x16, 0x0a, 0x12, 0x52, 0x45, 0x50, 0x4f, 0x52, 0x54, 0x5f, 0x4c, 0x41, 0x42, 0x45, 0x4c,
-	0x5f, 0x4e, 0x41, 0x4d, 0x45, 0x53, 0x10, 0x01, 0x12, 0x17, 0x0a, 0x13, 0x52, 0x45, 0x50, 0x4f,
-	0x52, 0x54, 0x5f, 0x4c, 0x41, 0x42, 0x45, 0x4c, 0x5f, 0x56, 0x41, 0x4c, 0x55, 0x45, 0x53, 0x10,
-	0x02, 0x12, 0x18, 0x0a, 0x14, 0x52, 0x45, 0x50, 0x4f, 0x52, 0x54, 0x5f, 0x53, 0x45, 0x52, 0x49,
-	0x45, 0x53, 0x5f, 0x4c, 0x41, 0x42, 0x45, 0x4c, 0x53, 0x10, 0x03, 0x12, 0x16, 0x0a, 0x12, 0x52,
-	0x45, 0x50, 0x4f, 0x52, 0x54, 0x5f, 0x54, 0x49, 0x4d, 0x45, 0x5f, 0x53, 0x45, 0x52, 0x49, 0x45,
-	0x53, 0x10, 0x04, 0x12, 0x0f, 0x0a, 0x0b, 0x52, 0x45, 0x50, 0x4f, 0x52, 0x54, 0x5f, 0x54, 0x52,
-	0x45, 0x45, 0x10, 0x05, 0x32, 0x62, 0x0a, 0x13, 0x51, 0x75, 0x65, 0x72, 0x79, 0x42, 0x61, 0x63,
-	0x6b, 0x65, 0x6e, 0x64, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x4b, 0x0a, 0x06, 0x49,
-	0x6e, 0x76, 0x6f, 0x6b, 0x65, 0x12, 0x1e, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63,
-	0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x6e, 0x76, 0x6f, 0x6b, 0x65, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63,
-	0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x6e, 0x76, 0x6f, 0x6b, 0x65, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0xd3, 0x01, 0x0a, 0x13, 0x63, 0x6f, 0x6d,
-	0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x76, 0x31,
-	0x42, 0x11, 0x51, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x50, 0x72,
-	0x6f, 0x74, 0x6f, 0x50, 0x01, 0x5a, 0x4c, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
-	0x6d, 0x2f, 0x67, 0x72, 0x61, 0x66, 0x61, 0x6e, 0x61, 0x2f, 0x70, 0x79, 0x72, 0x6f, 0x73, 0x63,
-	0x6f, 0x70, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x67, 0x65, 0x6e, 0x2f, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x2f, 0x67, 0x6f, 0x2f, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e,
-	0x64, 0x2f, 0x76, 0x31, 0x3b, 0x71, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e,
-	0x64, 0x76, 0x31, 0xa2, 0x02, 0x03, 0x51, 0x58, 0x58, 0xaa, 0x02, 0x0f, 0x51, 0x75, 0x65, 0x72,
-	0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x56, 0x31, 0xca, 0x02, 0x0f, 0x51, 0x75,
-	0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x5c, 0x56, 0x31, 0xe2, 0x02, 0x1b,
-	0x51, 0x75, 0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x5c, 0x56, 0x31, 0x5c,
-	0x47, 0x50, 0x42, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0xea, 0x02, 0x10, 0x51, 0x75,
-	0x65, 0x72, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x3a, 0x3a, 0x56, 0x31, 0x62, 0x06,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
-}
-
-var (
-	file_querybackend_v1_querybackend_proto_rawDescOnce sync.Once
-	file_querybackend_v1_querybackend_proto_rawDescData = file_querybackend_v1_querybackend_proto_rawDesc
-)
-
-func file_querybackend_v1_querybackend_proto_rawDescGZIP() []byte {
-	file_querybackend_v1_querybackend_proto_rawDescOnce.Do(func() {
-		file_querybackend_v1_querybackend_proto_rawDescData = protoimpl.X.CompressGZIP(file_querybackend_v1_querybackend_proto_rawDescData)
-	})
-	return file_querybackend_v1_querybackend_proto_rawDescData
-}
-
-var file_querybackend_v1_querybackend_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_querybackend_v1_querybackend_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
-var file_querybackend_v1_querybackend_proto_goTypes = []any{
-	(QueryType)(0),                     // 0: querybackend.v1.QueryType
-	(ReportType)(0),                    // 1: querybackend.v1.ReportType
-	(*InvokeOptions)(nil),              // 2: querybackend.v1.InvokeOptions
-	(*InvokeRequest)(nil),              // 3: querybackend.v1.InvokeRequest
-	(*QueryPlan)(nil),                  // 4: querybackend.v1.QueryPlan
-	(*Query)(nil),                      // 5: querybackend.v1.Query
-	(*InvokeResponse)(nil),             // 6: querybackend.v1.InvokeResponse
-	(*Diagnostics)(nil),                // 7: querybackend.v1.Diagnostics
-	(*Report)(nil),                     // 8: querybackend.v1.Report
-	(*LabelNamesQuery)(nil),            // 9: querybackend.v1.LabelNamesQuery
-	(*LabelNamesReport)(nil),           // 10: querybackend.v1.LabelNamesReport
-	(*LabelValuesQuery)(nil),           // 11: querybackend.v1.LabelValuesQuery
-	(*LabelValuesReport)(nil),          // 12: querybackend.v1.LabelValuesReport
-	(*SeriesLabelsQuery)(nil),          // 13: querybackend.v1.SeriesLabelsQuery
-	(*SeriesLabelsReport)(nil),         // 14: querybackend.v1.SeriesLabelsReport
-	(*TimeSeriesQuery)(nil),            // 15: querybackend.v1.TimeSeriesQuery
-	(*TimeSeriesReport)(nil),           // 16: querybackend.v1.TimeSeriesReport
-	(*TreeQuery)(nil),                  // 17: querybackend.v1.TreeQuery
-	(*TreeReport)(nil),                 // 18: querybackend.v1.TreeReport
-	(*v1.BlockMeta)(nil),               // 19: metastore.v1.BlockMeta
-	(*v11.Labels)(nil),                 // 20: types.v1.Labels
-	(v11.TimeSeriesAggregationType)(0), // 21: types.v1.TimeSeriesAggregationType
-	(*v11.Series)(nil),                 // 22: types.v1.Series
-}
-var file_querybackend_v1_querybackend_proto_depIdxs = []int32{
-	5,  // 0: querybackend.v1.InvokeRequest.query:type_name -> querybackend.v1.Query
-	4,  // 1: querybackend.v1.InvokeRequest.query_plan:type_name -> querybackend.v1.QueryPlan
-	2,  // 2: querybackend.v1.InvokeRequest.options:type_name -> querybackend.v1.InvokeOptions
-	19, // 3: querybackend.v1.QueryPlan.blocks:type_name -> metastore.v1.BlockMeta
-	0,  // 4: querybackend.v1.Query.query_type:type_name -> querybackend.v1.QueryType
-	9,  // 5: querybackend.v1.Query.label_names:type_name -> querybackend.v1.LabelNamesQuery
-	11, // 6: querybackend.v1.Query.label_values:type_name -> querybackend.v1.LabelValuesQuery
-	13, // 7: querybackend.v1.Query.series_labels:type_name -> querybackend.v1.SeriesLabelsQuery
-	15, // 8: querybackend.v1.Query.time_series:type_name -> querybackend.v1.TimeSeriesQuery
-	17, // 9: querybackend.v1.Query.tree:type_name -> querybackend.v1.TreeQuery
-	8,  // 10: querybackend.v1.InvokeResponse.reports:type_name -> querybackend.v1.Report
-	7,  // 11: querybackend.v1.InvokeResponse.diagnostics:type_name -> querybackend.v1.Diagnostics
-	1,  // 12: querybackend.v1.Report.report_type:type_name -> querybackend.v1.ReportType
-	10, // 13: querybackend.v1.Report.label_names:type_name -> querybackend.v1.LabelNamesReport
-	12, // 14: querybackend.v1.Report.label_values:type_name -> querybackend.v1.LabelValuesReport
-	14, // 15: querybackend.v1.Report.series_labels:type_name -> querybackend.v1.SeriesLabelsReport
-	16, // 16: querybackend.v1.Report.time_series:type_name -> querybackend.v1.TimeSeriesReport
-	18, // 17: querybackend.v1.Report.tree:type_name -> querybackend.v1.TreeReport
-	9,  // 18: querybackend.v1.LabelNamesReport.query:type_name -> querybackend.v1.LabelNamesQuery
-	11, // 19: querybackend.v1.LabelValuesReport.query:type_name -> querybackend.v1.LabelValuesQuery
-	13, // 20: querybackend.v1.SeriesLabelsReport.query:type_name -> querybackend.v1.SeriesLabelsQuery
-	20, // 21: querybackend.v1.SeriesLabelsReport.series_labels:type_name -> types.v1.Labels
-	21, // 22: querybackend.v1.TimeSeriesQuery.aggregation:type_name -> types.v1.TimeSeriesAggregationType
-	15, // 23: querybackend.v1.TimeSeriesReport.query:type_name -> querybackend.v1.TimeSeriesQuery
-	22, // 24: querybackend.v1.TimeSeriesReport.time_series:type_name -> types.v1.Series
-	17, // 25: querybackend.v1.TreeReport.query:type_name -> querybackend.v1.TreeQuery
-	3,  // 26: querybackend.v1.QueryBackendService.Invoke:input_type -> querybackend.v1.InvokeRequest
-	6,  // 27: querybackend.v1.QueryBackendService.Invoke:output_type -> querybackend.v1.InvokeResponse
-	27, // [27:28] is the sub-list for method output_type
-	26, // [26:27] is the sub-list for method input_type
-	26, // [26:26] is the sub-list for extension type_name
-	26, // [26:26] is the sub-list for extension extendee
-	0,  // [0:26] is the sub-list for field type_name
-}
-
-func init() { file_querybackend_v1_querybackend_proto_init() }
-func file_querybackend_v1_querybackend_proto_init() {
-	if File_querybackend_v1_querybackend_proto != nil {
-		return
-	}
-	if !protoimpl.UnsafeEnabled {
-		file_querybackend_v1_querybackend_proto_msgTypes[0].Exporter = func(v any, i int) any {
-			switch v := v.(*InvokeOptions); i {
+		file_querybackend_v1_querybackend_proto_msgTypes[64].Exporter = func(v any, i int) any {
+			switch v := v.(*TreeDiffNode); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1411,8 +9379,8 @@ func file_querybackend_v1_querybackend_proto_init() {
 				return nil
 			}
 		}
-		file_querybackend_v1_querybackend_proto_msgTypes[1].Exporter = func(v any, i int) any {
-			switch v := v.(*InvokeRequest); i {
+		file_querybackend_v1_querybackend_proto_msgTypes[65].Exporter = func(v any, i int) any {
+			switch v := v.(*StacktracesQuery); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1423,8 +9391,8 @@ func file_querybackend_v1_querybackend_proto_init() {
 				return nil
 			}
 		}
-		file_querybackend_v1_querybackend_proto_msgTypes[2].Exporter = func(v any, i int) any {
-			switch v := v.(*QueryPlan); i {
+		file_querybackend_v1_querybackend_proto_msgTypes[66].Exporter = func(v any, i int) any {
+			switch v := v.(*StacktracesReport); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1435,8 +9403,8 @@ func file_querybackend_v1_querybackend_proto_init() {
 				return nil
 			}
 		}
-		file_querybackend_v1_querybackend_proto_msgTypes[3].Exporter = func(v any, i int) any {
-			switch v := v.(*Query); i {
+		file_querybackend_v1_querybackend_proto_msgTypes[67].Exporter = func(v any, i int) any {
+			switch v := v.(*StacktracePartition); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1447,8 +9415,8 @@ func file_querybackend_v1_querybackend_proto_init() {
 				return nil
 			}
 		}
-		file_querybackend_v1_querybackend_proto_msgTypes[4].Exporter = func(v any, i int) any {
-			switch v := v.(*InvokeResponse); i {
+		file_querybackend_v1_querybackend_proto_msgTypes[68].Exporter = func(v any, i int) any {
+			switch v := v.(*StacktraceFrames); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1459,8 +9427,8 @@ func file_querybackend_v1_querybackend_proto_init() {
 				return nil
 			}
 		}
-		file_querybackend_v1_querybackend_proto_msgTypes[5].Exporter = func(v any, i int) any {
-			switch v := v.(*Diagnostics); i {
+		file_querybackend_v1_querybackend_proto_msgTypes[69].Exporter = func(v any, i int) any {
+			switch v := v.(*StackDepthQuery); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1471,8 +9439,8 @@ func file_querybackend_v1_querybackend_proto_init() {
 				return nil
 			}
 		}
-		file_querybackend_v1_querybackend_proto_msgTypes[6].Exporter = func(v any, i int) any {
-			switch v := v.(*Report); i {
+		file_querybackend_v1_querybackend_proto_msgTypes[70].Exporter = func(v any, i int) any {
+			switch v := v.(*StackDepthReport); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1483,8 +9451,8 @@ func file_querybackend_v1_querybackend_proto_init() {
 				return nil
 			}
 		}
-		file_querybackend_v1_querybackend_proto_msgTypes[7].Exporter = func(v any, i int) any {
-			switch v := v.(*LabelNamesQuery); i {
+		file_querybackend_v1_querybackend_proto_msgTypes[71].Exporter = func(v any, i int) any {
+			switch v := v.(*StackDepthBucket); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1495,8 +9463,8 @@ func file_querybackend_v1_querybackend_proto_init() {
 				return nil
 			}
 		}
-		file_querybackend_v1_querybackend_proto_msgTypes[8].Exporter = func(v any, i int) any {
-			switch v := v.(*LabelNamesReport); i {
+		file_querybackend_v1_querybackend_proto_msgTypes[72].Exporter = func(v any, i int) any {
+			switch v := v.(*ProfileDeviationQuery); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1507,8 +9475,8 @@ func file_querybackend_v1_querybackend_proto_init() {
 				return nil
 			}
 		}
-		file_querybackend_v1_querybackend_proto_msgTypes[9].Exporter = func(v any, i int) any {
-			switch v := v.(*LabelValuesQuery); i {
+		file_querybackend_v1_querybackend_proto_msgTypes[73].Exporter = func(v any, i int) any {
+			switch v := v.(*ProfileDeviationReport); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1519,8 +9487,8 @@ func file_querybackend_v1_querybackend_proto_init() {
 				return nil
 			}
 		}
-		file_querybackend_v1_querybackend_proto_msgTypes[10].Exporter = func(v any, i int) any {
-			switch v := v.(*LabelValuesReport); i {
+		file_querybackend_v1_querybackend_proto_msgTypes[74].Exporter = func(v any, i int) any {
+			switch v := v.(*ProfileDeviationNode); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1531,8 +9499,8 @@ func file_querybackend_v1_querybackend_proto_init() {
 				return nil
 			}
 		}
-		file_querybackend_v1_querybackend_proto_msgTypes[11].Exporter = func(v any, i int) any {
-			switch v := v.(*SeriesLabelsQuery); i {
+		file_querybackend_v1_querybackend_proto_msgTypes[75].Exporter = func(v any, i int) any {
+			switch v := v.(*ProfileSimilarityQuery); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1543,8 +9511,8 @@ func file_querybackend_v1_querybackend_proto_init() {
 				return nil
 			}
 		}
-		file_querybackend_v1_querybackend_proto_msgTypes[12].Exporter = func(v any, i int) any {
-			switch v := v.(*SeriesLabelsReport); i {
+		file_querybackend_v1_querybackend_proto_msgTypes[76].Exporter = func(v any, i int) any {
+			switch v := v.(*ProfileSimilarityReport); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1555,8 +9523,8 @@ func file_querybackend_v1_querybackend_proto_init() {
 				return nil
 			}
 		}
-		file_querybackend_v1_querybackend_proto_msgTypes[13].Exporter = func(v any, i int) any {
-			switch v := v.(*TimeSeriesQuery); i {
+		file_querybackend_v1_querybackend_proto_msgTypes[77].Exporter = func(v any, i int) any {
+			switch v := v.(*SimilarityFunction); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1567,8 +9535,8 @@ func file_querybackend_v1_querybackend_proto_init() {
 				return nil
 			}
 		}
-		file_querybackend_v1_querybackend_proto_msgTypes[14].Exporter = func(v any, i int) any {
-			switch v := v.(*TimeSeriesReport); i {
+		file_querybackend_v1_querybackend_proto_msgTypes[78].Exporter = func(v any, i int) any {
+			switch v := v.(*TopCallersQuery); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1579,8 +9547,8 @@ func file_querybackend_v1_querybackend_proto_init() {
 				return nil
 			}
 		}
-		file_querybackend_v1_querybackend_proto_msgTypes[15].Exporter = func(v any, i int) any {
-			switch v := v.(*TreeQuery); i {
+		file_querybackend_v1_querybackend_proto_msgTypes[79].Exporter = func(v any, i int) any {
+			switch v := v.(*TopCallersReport); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1591,8 +9559,44 @@ func file_querybackend_v1_querybackend_proto_init() {
 				return nil
 			}
 		}
-		file_querybackend_v1_querybackend_proto_msgTypes[16].Exporter = func(v any, i int) any {
-			switch v := v.(*TreeReport); i {
+		file_querybackend_v1_querybackend_proto_msgTypes[80].Exporter = func(v any, i int) any {
+			switch v := v.(*TopCaller); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[81].Exporter = func(v any, i int) any {
+			switch v := v.(*TopProfilesQuery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[82].Exporter = func(v any, i int) any {
+			switch v := v.(*TopProfilesReport); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_querybackend_v1_querybackend_proto_msgTypes[83].Exporter = func(v any, i int) any {
+			switch v := v.(*TopProfile); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1604,14 +9608,14 @@ func file_querybackend_v1_querybackend_proto_init() {
 			}
 		}
 	}
-	file_querybackend_v1_querybackend_proto_msgTypes[13].OneofWrappers = []any{}
+	file_querybackend_v1_querybackend_proto_msgTypes[15].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_querybackend_v1_querybackend_proto_rawDesc,
-			NumEnums:      2,
-			NumMessages:   17,
+			NumEnums:      7,
+			NumMessages:   84,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
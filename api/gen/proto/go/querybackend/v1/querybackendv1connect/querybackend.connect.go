@@ -36,17 +36,26 @@ const (
 	// QueryBackendServiceInvokeProcedure is the fully-qualified name of the QueryBackendService's
 	// Invoke RPC.
 	QueryBackendServiceInvokeProcedure = "/querybackend.v1.QueryBackendService/Invoke"
+	// QueryBackendServiceInvokeStreamProcedure is the fully-qualified name of the QueryBackendService's
+	// InvokeStream RPC.
+	QueryBackendServiceInvokeStreamProcedure = "/querybackend.v1.QueryBackendService/InvokeStream"
 )
 
 // These variables are the protoreflect.Descriptor objects for the RPCs defined in this package.
 var (
-	queryBackendServiceServiceDescriptor      = v1.File_querybackend_v1_querybackend_proto.Services().ByName("QueryBackendService")
-	queryBackendServiceInvokeMethodDescriptor = queryBackendServiceServiceDescriptor.Methods().ByName("Invoke")
+	queryBackendServiceServiceDescriptor            = v1.File_querybackend_v1_querybackend_proto.Services().ByName("QueryBackendService")
+	queryBackendServiceInvokeMethodDescriptor       = queryBackendServiceServiceDescriptor.Methods().ByName("Invoke")
+	queryBackendServiceInvokeStreamMethodDescriptor = queryBackendServiceServiceDescriptor.Methods().ByName("InvokeStream")
 )
 
 // QueryBackendServiceClient is a client for the querybackend.v1.QueryBackendService service.
 type QueryBackendServiceClient interface {
 	Invoke(context.Context, *connect.Request[v1.InvokeRequest]) (*connect.Response[v1.InvokeResponse], error)
+	// InvokeStream is like Invoke, but the response is delivered in chunks:
+	// the chunks are aggregated as if the reports of all of them were
+	// included in a single response. The diagnostics are only included
+	// in the last chunk.
+	InvokeStream(context.Context, *connect.Request[v1.InvokeRequest]) (*connect.ServerStreamForClient[v1.InvokeResponse], error)
 }
 
 // NewQueryBackendServiceClient constructs a client for the querybackend.v1.QueryBackendService
@@ -65,12 +74,19 @@ func NewQueryBackendServiceClient(httpClient connect.HTTPClient, baseURL string,
 			connect.WithSchema(queryBackendServiceInvokeMethodDescriptor),
 			connect.WithClientOptions(opts...),
 		),
+		invokeStream: connect.NewClient[v1.InvokeRequest, v1.InvokeResponse](
+			httpClient,
+			baseURL+QueryBackendServiceInvokeStreamProcedure,
+			connect.WithSchema(queryBackendServiceInvokeStreamMethodDescriptor),
+			connect.WithClientOptions(opts...),
+		),
 	}
 }
 
 // queryBackendServiceClient implements QueryBackendServiceClient.
 type queryBackendServiceClient struct {
-	invoke *connect.Client[v1.InvokeRequest, v1.InvokeResponse]
+	invoke       *connect.Client[v1.InvokeRequest, v1.InvokeResponse]
+	invokeStream *connect.Client[v1.InvokeRequest, v1.InvokeResponse]
 }
 
 // Invoke calls querybackend.v1.QueryBackendService.Invoke.
@@ -78,10 +94,20 @@ func (c *queryBackendServiceClient) Invoke(ctx context.Context, req *connect.Req
 	return c.invoke.CallUnary(ctx, req)
 }
 
+// InvokeStream calls querybackend.v1.QueryBackendService.InvokeStream.
+func (c *queryBackendServiceClient) InvokeStream(ctx context.Context, req *connect.Request[v1.InvokeRequest]) (*connect.ServerStreamForClient[v1.InvokeResponse], error) {
+	return c.invokeStream.CallServerStream(ctx, req)
+}
+
 // QueryBackendServiceHandler is an implementation of the querybackend.v1.QueryBackendService
 // service.
 type QueryBackendServiceHandler interface {
 	Invoke(context.Context, *connect.Request[v1.InvokeRequest]) (*connect.Response[v1.InvokeResponse], error)
+	// InvokeStream is like Invoke, but the response is delivered in chunks:
+	// the chunks are aggregated as if the reports of all of them were
+	// included in a single response. The diagnostics are only included
+	// in the last chunk.
+	InvokeStream(context.Context, *connect.Request[v1.InvokeRequest], *connect.ServerStream[v1.InvokeResponse]) error
 }
 
 // NewQueryBackendServiceHandler builds an HTTP handler from the service implementation. It returns
@@ -96,10 +122,18 @@ func NewQueryBackendServiceHandler(svc QueryBackendServiceHandler, opts ...conne
 		connect.WithSchema(queryBackendServiceInvokeMethodDescriptor),
 		connect.WithHandlerOptions(opts...),
 	)
+	queryBackendServiceInvokeStreamHandler := connect.NewServerStreamHandler(
+		QueryBackendServiceInvokeStreamProcedure,
+		svc.InvokeStream,
+		connect.WithSchema(queryBackendServiceInvokeStreamMethodDescriptor),
+		connect.WithHandlerOptions(opts...),
+	)
 	return "/querybackend.v1.QueryBackendService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
 		case QueryBackendServiceInvokeProcedure:
 			queryBackendServiceInvokeHandler.ServeHTTP(w, r)
+		case QueryBackendServiceInvokeStreamProcedure:
+			queryBackendServiceInvokeStreamHandler.ServeHTTP(w, r)
 		default:
 			http.NotFound(w, r)
 		}
@@ -112,3 +146,7 @@ type UnimplementedQueryBackendServiceHandler struct{}
 func (UnimplementedQueryBackendServiceHandler) Invoke(context.Context, *connect.Request[v1.InvokeRequest]) (*connect.Response[v1.InvokeResponse], error) {
 	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("querybackend.v1.QueryBackendService.Invoke is not implemented"))
 }
+
+func (UnimplementedQueryBackendServiceHandler) InvokeStream(context.Context, *connect.Request[v1.InvokeRequest], *connect.ServerStream[v1.InvokeResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("querybackend.v1.QueryBackendService.InvokeStream is not implemented"))
+}
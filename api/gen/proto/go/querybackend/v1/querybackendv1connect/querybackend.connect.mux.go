@@ -24,4 +24,9 @@ func RegisterQueryBackendServiceHandler(mux *mux.Router, svc QueryBackendService
 		svc.Invoke,
 		opts...,
 	))
+	mux.Handle("/querybackend.v1.QueryBackendService/InvokeStream", connect.NewServerStreamHandler(
+		"/querybackend.v1.QueryBackendService/InvokeStream",
+		svc.InvokeStream,
+		opts...,
+	))
 }
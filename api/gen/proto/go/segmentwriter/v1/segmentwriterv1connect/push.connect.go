@@ -36,17 +36,25 @@ const (
 	// SegmentWriterServicePushProcedure is the fully-qualified name of the SegmentWriterService's Push
 	// RPC.
 	SegmentWriterServicePushProcedure = "/segmentwriter.v1.SegmentWriterService/Push"
+	// SegmentWriterServiceHeadSnapshotsProcedure is the fully-qualified name of the
+	// SegmentWriterService's HeadSnapshots RPC.
+	SegmentWriterServiceHeadSnapshotsProcedure = "/segmentwriter.v1.SegmentWriterService/HeadSnapshots"
 )
 
 // These variables are the protoreflect.Descriptor objects for the RPCs defined in this package.
 var (
-	segmentWriterServiceServiceDescriptor    = v1.File_segmentwriter_v1_push_proto.Services().ByName("SegmentWriterService")
-	segmentWriterServicePushMethodDescriptor = segmentWriterServiceServiceDescriptor.Methods().ByName("Push")
+	segmentWriterServiceServiceDescriptor             = v1.File_segmentwriter_v1_push_proto.Services().ByName("SegmentWriterService")
+	segmentWriterServicePushMethodDescriptor          = segmentWriterServiceServiceDescriptor.Methods().ByName("Push")
+	segmentWriterServiceHeadSnapshotsMethodDescriptor = segmentWriterServiceServiceDescriptor.Methods().ByName("HeadSnapshots")
 )
 
 // SegmentWriterServiceClient is a client for the segmentwriter.v1.SegmentWriterService service.
 type SegmentWriterServiceClient interface {
 	Push(context.Context, *connect.Request[v1.PushRequest]) (*connect.Response[v1.PushResponse], error)
+	// HeadSnapshots returns the snapshots of the in-memory heads of the
+	// segments being written, so that the data can be queried before it
+	// is flushed to the object storage.
+	HeadSnapshots(context.Context, *connect.Request[v1.HeadSnapshotsRequest]) (*connect.Response[v1.HeadSnapshotsResponse], error)
 }
 
 // NewSegmentWriterServiceClient constructs a client for the segmentwriter.v1.SegmentWriterService
@@ -65,12 +73,19 @@ func NewSegmentWriterServiceClient(httpClient connect.HTTPClient, baseURL string
 			connect.WithSchema(segmentWriterServicePushMethodDescriptor),
 			connect.WithClientOptions(opts...),
 		),
+		headSnapshots: connect.NewClient[v1.HeadSnapshotsRequest, v1.HeadSnapshotsResponse](
+			httpClient,
+			baseURL+SegmentWriterServiceHeadSnapshotsProcedure,
+			connect.WithSchema(segmentWriterServiceHeadSnapshotsMethodDescriptor),
+			connect.WithClientOptions(opts...),
+		),
 	}
 }
 
 // segmentWriterServiceClient implements SegmentWriterServiceClient.
 type segmentWriterServiceClient struct {
-	push *connect.Client[v1.PushRequest, v1.PushResponse]
+	push          *connect.Client[v1.PushRequest, v1.PushResponse]
+	headSnapshots *connect.Client[v1.HeadSnapshotsRequest, v1.HeadSnapshotsResponse]
 }
 
 // Push calls segmentwriter.v1.SegmentWriterService.Push.
@@ -78,10 +93,19 @@ func (c *segmentWriterServiceClient) Push(ctx context.Context, req *connect.Requ
 	return c.push.CallUnary(ctx, req)
 }
 
+// HeadSnapshots calls segmentwriter.v1.SegmentWriterService.HeadSnapshots.
+func (c *segmentWriterServiceClient) HeadSnapshots(ctx context.Context, req *connect.Request[v1.HeadSnapshotsRequest]) (*connect.Response[v1.HeadSnapshotsResponse], error) {
+	return c.headSnapshots.CallUnary(ctx, req)
+}
+
 // SegmentWriterServiceHandler is an implementation of the segmentwriter.v1.SegmentWriterService
 // service.
 type SegmentWriterServiceHandler interface {
 	Push(context.Context, *connect.Request[v1.PushRequest]) (*connect.Response[v1.PushResponse], error)
+	// HeadSnapshots returns the snapshots of the in-memory heads of the
+	// segments being written, so that the data can be queried before it
+	// is flushed to the object storage.
+	HeadSnapshots(context.Context, *connect.Request[v1.HeadSnapshotsRequest]) (*connect.Response[v1.HeadSnapshotsResponse], error)
 }
 
 // NewSegmentWriterServiceHandler builds an HTTP handler from the service implementation. It returns
@@ -96,10 +120,18 @@ func NewSegmentWriterServiceHandler(svc SegmentWriterServiceHandler, opts ...con
 		connect.WithSchema(segmentWriterServicePushMethodDescriptor),
 		connect.WithHandlerOptions(opts...),
 	)
+	segmentWriterServiceHeadSnapshotsHandler := connect.NewUnaryHandler(
+		SegmentWriterServiceHeadSnapshotsProcedure,
+		svc.HeadSnapshots,
+		connect.WithSchema(segmentWriterServiceHeadSnapshotsMethodDescriptor),
+		connect.WithHandlerOptions(opts...),
+	)
 	return "/segmentwriter.v1.SegmentWriterService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
 		case SegmentWriterServicePushProcedure:
 			segmentWriterServicePushHandler.ServeHTTP(w, r)
+		case SegmentWriterServiceHeadSnapshotsProcedure:
+			segmentWriterServiceHeadSnapshotsHandler.ServeHTTP(w, r)
 		default:
 			http.NotFound(w, r)
 		}
@@ -112,3 +144,7 @@ type UnimplementedSegmentWriterServiceHandler struct{}
 func (UnimplementedSegmentWriterServiceHandler) Push(context.Context, *connect.Request[v1.PushRequest]) (*connect.Response[v1.PushResponse], error) {
 	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("segmentwriter.v1.SegmentWriterService.Push is not implemented"))
 }
+
+func (UnimplementedSegmentWriterServiceHandler) HeadSnapshots(context.Context, *connect.Request[v1.HeadSnapshotsRequest]) (*connect.Response[v1.HeadSnapshotsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("segmentwriter.v1.SegmentWriterService.HeadSnapshots is not implemented"))
+}
@@ -24,4 +24,9 @@ func RegisterSegmentWriterServiceHandler(mux *mux.Router, svc SegmentWriterServi
 		svc.Push,
 		opts...,
 	))
+	mux.Handle("/segmentwriter.v1.SegmentWriterService/HeadSnapshots", connect.NewUnaryHandler(
+		"/segmentwriter.v1.SegmentWriterService/HeadSnapshots",
+		svc.HeadSnapshots,
+		opts...,
+	))
 }
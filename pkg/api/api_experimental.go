@@ -28,9 +28,16 @@ func (a *API) RegisterSegmentWriterRing(r http.Handler) {
 
 func (a *API) RegisterMetastore(svc *metastore.Metastore) {
 	metastorev1.RegisterMetastoreServiceServer(a.server.GRPC, svc)
+	metastorev1.RegisterMetastoreAdminServiceServer(a.server.GRPC, svc)
 	compactorv1.RegisterCompactionPlannerServer(a.server.GRPC, svc)
 }
 
 func (a *API) RegisterQueryBackend(svc *querybackend.QueryBackend) {
 	querybackendv1.RegisterQueryBackendServiceServer(a.server.GRPC, svc)
+	if h := svc.TreeJSONHandler(); h != nil {
+		a.RegisterRoute("/query-backend/debug/tree", h, true, true, "POST")
+	}
+	if h := svc.TreeTraceHandler(); h != nil {
+		a.RegisterRoute("/query-backend/export/tree-trace", h, true, true, "POST")
+	}
 }
@@ -39,6 +39,7 @@ import (
 	"github.com/grafana/pyroscope/pkg/clientpool"
 	"github.com/grafana/pyroscope/pkg/distributor/aggregator"
 	distributormodel "github.com/grafana/pyroscope/pkg/distributor/model"
+	"github.com/grafana/pyroscope/pkg/distributor/recording"
 	writepath "github.com/grafana/pyroscope/pkg/distributor/write_path"
 	phlaremodel "github.com/grafana/pyroscope/pkg/model"
 	"github.com/grafana/pyroscope/pkg/model/relabel"
@@ -98,6 +99,7 @@ type Distributor struct {
 	healthyInstancesCount  *atomic.Uint32
 	ingestionRateLimiter   *limiter.RateLimiter
 	aggregator             *aggregator.MultiTenantAggregator[*pprof.ProfileMerge]
+	recorder               *recording.Recorder
 	asyncRequests          sync.WaitGroup
 
 	subservices        *services.Manager
@@ -130,6 +132,7 @@ type Limits interface {
 	EnforceLabelsOrder(tenantID string) bool
 	IngestionRelabelingRules(tenantID string) []*relabel.Config
 	DistributorUsageGroups(tenantID string) *validation.UsageGroupConfig
+	RecordingRules(tenantID string) recording.Rules
 	validation.ProfileValidationLimits
 	aggregator.Limits
 	writepath.Overrides
@@ -161,6 +164,7 @@ func New(
 		ingestersRing:           ingesterRing,
 		pool:                    clientpool.NewIngesterPool(config.PoolConfig, ingesterRing, ingesterClientFactory, clients, logger, ingesterClientsOptions...),
 		metrics:                 newMetrics(reg),
+		recorder:                recording.NewRecorder(reg),
 		healthyInstancesCount:   atomic.NewUint32(0),
 		aggregator:              aggregator.NewMultiTenantAggregator[*pprof.ProfileMerge](limits, reg),
 		limits:                  limits,
@@ -360,6 +364,19 @@ func (d *Distributor) PushParsed(ctx context.Context, req *distributormodel.Push
 		_ = level.Warn(d.logger).Log("msg", "failed to inject mapping versions", "err", err)
 	}
 
+	// The recording rules are evaluated against the profiles as received,
+	// but the values are only accounted once the request succeeds: the
+	// request may be retried, and it is modified as it is sent.
+	var recorded *recording.Batch
+	if rules := d.limits.RecordingRules(req.TenantID); len(rules) > 0 {
+		recorded = d.recorder.NewBatch(req.TenantID, rules)
+		for _, series := range req.Series {
+			for _, sample := range series.Samples {
+				recorded.Add(series.Labels, sample.Profile.Profile)
+			}
+		}
+	}
+
 	// Reduce cardinality of the session_id label.
 	maxSessionsPerSeries := d.limits.MaxSessionsPerSeries(req.TenantID)
 	for _, series := range req.Series {
@@ -371,12 +388,15 @@ func (d *Distributor) PushParsed(ctx context.Context, req *distributormodel.Push
 		return nil, err
 	}
 	if aggregated {
+		// The aggregated profiles are not sent again.
+		recorded.Commit()
 		return connect.NewResponse(&pushv1.PushResponse{}), nil
 	}
 
 	if err = d.router.Send(ctx, req); err != nil {
 		return nil, err
 	}
+	recorded.Commit()
 	return connect.NewResponse(&pushv1.PushResponse{}), nil
 }
 
@@ -0,0 +1,185 @@
+package recording
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	profilev1 "github.com/grafana/pyroscope/api/gen/proto/go/google/v1"
+	phlaremodel "github.com/grafana/pyroscope/pkg/model"
+)
+
+// Recorder evaluates the recording rules against the received profiles,
+// and accumulates the values of the matching samples in a counter, per
+// tenant, rule, and sample type. The metric is exported via the /metrics
+// endpoint of the distributor.
+type Recorder struct {
+	values *prometheus.CounterVec
+}
+
+func NewRecorder(reg prometheus.Registerer) *Recorder {
+	r := &Recorder{
+		values: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "pyroscope",
+				Name:      "recording_rule_sample_values_total",
+				Help:      "The total of the sample values matching the profile recording rule, by sample type.",
+			},
+			[]string{"tenant", "rule", "sample_type"},
+		),
+	}
+	if reg != nil {
+		reg.MustRegister(r.values)
+	}
+	return r
+}
+
+// NewBatch creates a batch of the values of the rules of the tenant. The
+// values are only accounted once the batch is committed: the profiles of
+// a request that fails are sent again by the client.
+func (r *Recorder) NewBatch(tenantID string, rules Rules) *Batch {
+	return &Batch{
+		recorder: r,
+		tenantID: tenantID,
+		rules:    rules,
+		values:   make(map[batchKey]int64),
+	}
+}
+
+// Batch accumulates the values of the samples matching the rules,
+// until it is committed.
+type Batch struct {
+	recorder *Recorder
+	tenantID string
+	rules    Rules
+	values   map[batchKey]int64
+}
+
+type batchKey struct {
+	rule       string
+	sampleType string
+}
+
+// Add evaluates the rules against the profile of the series with the
+// given labels. The profile must be symbolized: the samples with no
+// function names never match.
+func (b *Batch) Add(lbls phlaremodel.Labels, p *profilev1.Profile) {
+	var s *symbols
+	values := make([]int64, len(p.SampleType))
+	for _, rule := range b.rules {
+		if !rule.matchesLabels(lbls) {
+			continue
+		}
+		if s == nil {
+			s = newSymbols(p)
+		}
+		locations := s.matchLocations(rule)
+		clear(values)
+		for _, sample := range p.Sample {
+			if !matchesSample(sample, locations, rule.Self) {
+				continue
+			}
+			for i, v := range sample.Value {
+				if i < len(values) {
+					values[i] += v
+				}
+			}
+		}
+		for i, v := range values {
+			if v != 0 {
+				b.values[batchKey{rule: rule.Name, sampleType: s.sampleTypes[i]}] += v
+			}
+		}
+	}
+}
+
+// Commit adds the values of the batch to the counters. The batch
+// may be nil, if there are no rules to evaluate.
+func (b *Batch) Commit() {
+	if b == nil {
+		return
+	}
+	for k, v := range b.values {
+		// The counters can't be decreased: negative
+		// values, e.g. of diff profiles, are ignored.
+		if v > 0 {
+			b.recorder.values.WithLabelValues(b.tenantID, k.rule, k.sampleType).Add(float64(v))
+		}
+	}
+	clear(b.values)
+}
+
+func matchesSample(sample *profilev1.Sample, locations map[uint64]struct{}, self bool) bool {
+	if len(locations) == 0 || len(sample.LocationId) == 0 {
+		return false
+	}
+	if self {
+		// The first location is the leaf of the stack trace.
+		_, ok := locations[sample.LocationId[0]]
+		return ok
+	}
+	for _, id := range sample.LocationId {
+		if _, ok := locations[id]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// symbols provides the look-ups of the function names of the profile
+// locations, shared by the rules evaluated against the profile.
+type symbols struct {
+	p           *profilev1.Profile
+	sampleTypes []string
+	functions   map[uint64]string
+}
+
+func newSymbols(p *profilev1.Profile) *symbols {
+	s := &symbols{
+		p:           p,
+		sampleTypes: make([]string, len(p.SampleType)),
+		functions:   make(map[uint64]string, len(p.Function)),
+	}
+	for i, t := range p.SampleType {
+		s.sampleTypes[i] = s.str(t.Type) + ":" + s.str(t.Unit)
+	}
+	for _, f := range p.Function {
+		s.functions[f.Id] = s.str(f.Name)
+	}
+	return s
+}
+
+func (s *symbols) str(i int64) string {
+	if i < 0 || i >= int64(len(s.p.StringTable)) {
+		return ""
+	}
+	return s.p.StringTable[i]
+}
+
+// matchLocations returns the identifiers of the locations that refer to
+// the functions matching the rule. For self rules, only the innermost
+// line of a location is considered: the rest are the inlined callers.
+func (s *symbols) matchLocations(rule *Rule) map[uint64]struct{} {
+	functions := make(map[uint64]bool)
+	matches := func(id uint64) bool {
+		m, ok := functions[id]
+		if !ok {
+			name, found := s.functions[id]
+			m = found && name != "" && rule.function.MatchString(name)
+			functions[id] = m
+		}
+		return m
+	}
+	locations := make(map[uint64]struct{})
+	for _, loc := range s.p.Location {
+		lines := loc.Line
+		if rule.Self && len(lines) > 0 {
+			lines = lines[:1]
+		}
+		for _, line := range lines {
+			if matches(line.FunctionId) {
+				locations[loc.Id] = struct{}{}
+				break
+			}
+		}
+	}
+	return locations
+}
@@ -0,0 +1,144 @@
+package recording
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	profilev1 "github.com/grafana/pyroscope/api/gen/proto/go/google/v1"
+	phlaremodel "github.com/grafana/pyroscope/pkg/model"
+)
+
+func Test_Rules_Unmarshal(t *testing.T) {
+	const rulesYAML = `
+- name: gc
+  selector: '{service_name="api"}'
+  function: 'runtime\.gc.*'
+- name: handler
+  selector: '{service_name=~"api|web"}'
+  function: 'main\.handle'
+  self: true
+`
+	var fromYAML Rules
+	require.NoError(t, yaml.Unmarshal([]byte(rulesYAML), &fromYAML))
+	require.Len(t, fromYAML, 2)
+	require.True(t, fromYAML[1].Self)
+	require.True(t, fromYAML[0].function.MatchString("runtime.gcBgMarkWorker"))
+	// The expression is anchored.
+	require.False(t, fromYAML[0].function.MatchString("main.runtime.gc"))
+
+	b, err := json.Marshal(fromYAML)
+	require.NoError(t, err)
+	var fromJSON Rules
+	require.NoError(t, json.Unmarshal(b, &fromJSON))
+	require.Equal(t, fromYAML, fromJSON)
+
+	for _, invalid := range []string{
+		`[{name: "", selector: '{service_name="api"}', function: "f"}]`,
+		`[{name: "a-b", selector: '{service_name="api"}', function: "f"}]`,
+		`[{name: "a", function: "f"}]`,
+		`[{name: "a", selector: '{service_name=}', function: "f"}]`,
+		`[{name: "a", selector: '{service_name="api"}'}]`,
+		`[{name: "a", selector: '{service_name="api"}', function: "("}]`,
+		`[{name: "a", selector: '{service_name="api"}', function: "f", unknown: true}]`,
+	} {
+		var rules Rules
+		require.Error(t, yaml.Unmarshal([]byte(invalid), &rules), invalid)
+	}
+}
+
+func Test_Rules_Validate(t *testing.T) {
+	rules, err := NewRules(
+		Rule{Name: "a", Selector: `{service_name="api"}`, Function: "f"},
+		Rule{Name: "b", Selector: `{service_name="api"}`, Function: "g"},
+	)
+	require.NoError(t, err)
+	require.NoError(t, rules.Validate(0))
+	require.NoError(t, rules.Validate(2))
+	require.Error(t, rules.Validate(1))
+
+	_, err = NewRules(
+		Rule{Name: "a", Selector: `{service_name="api"}`, Function: "f"},
+		Rule{Name: "a", Selector: `{service_name="web"}`, Function: "f"},
+	)
+	require.Error(t, err)
+}
+
+// newTestProfile creates a profile with the stack traces (leaf first)
+// and their values of two sample types.
+func newTestProfile(stacks map[string][]string, values map[string][2]int64) *profilev1.Profile {
+	p := &profilev1.Profile{StringTable: []string{"", "cpu", "nanoseconds", "samples", "count"}}
+	p.SampleType = []*profilev1.ValueType{{Type: 3, Unit: 4}, {Type: 1, Unit: 2}}
+	locations := make(map[string]uint64)
+	location := func(name string) uint64 {
+		if id, ok := locations[name]; ok {
+			return id
+		}
+		id := uint64(len(locations) + 1)
+		p.StringTable = append(p.StringTable, name)
+		p.Function = append(p.Function, &profilev1.Function{Id: id, Name: int64(len(p.StringTable) - 1)})
+		p.Location = append(p.Location, &profilev1.Location{Id: id, Line: []*profilev1.Line{{FunctionId: id}}})
+		locations[name] = id
+		return id
+	}
+	for k, stack := range stacks {
+		v := values[k]
+		s := &profilev1.Sample{Value: v[:]}
+		for _, f := range stack {
+			s.LocationId = append(s.LocationId, location(f))
+		}
+		p.Sample = append(p.Sample, s)
+	}
+	return p
+}
+
+func Test_Recorder(t *testing.T) {
+	rules, err := NewRules(
+		Rule{Name: "gc", Selector: `{service_name="api"}`, Function: `runtime\.gc.*`},
+		Rule{Name: "malloc_self", Selector: `{service_name="api"}`, Function: `runtime\.mallocgc`, Self: true},
+		Rule{Name: "web", Selector: `{service_name="web"}`, Function: `.*`},
+		Rule{Name: "none", Selector: `{service_name="api"}`, Function: `main\.none`},
+	)
+	require.NoError(t, err)
+
+	p := newTestProfile(
+		map[string][]string{
+			"a": {"runtime.gcDrain", "runtime.gcBgMarkWorker"},
+			"b": {"runtime.mallocgc", "runtime.gcStart", "main.main"},
+			"c": {"runtime.memmove", "runtime.mallocgc", "main.main"},
+			"d": {"main.work", "main.main"},
+		},
+		map[string][2]int64{
+			"a": {1, 100},
+			"b": {2, 200},
+			"c": {4, 400},
+			"d": {8, 800},
+		},
+	)
+
+	reg := prometheus.NewRegistry()
+	r := NewRecorder(reg)
+	api := phlaremodel.LabelsFromStrings("service_name", "api", "__name__", "process_cpu")
+	b := r.NewBatch("tenant", rules)
+	b.Add(api, p)
+	b.Add(api, p)
+	// The values are only accounted once the batch is committed.
+	require.Equal(t, 0, testutil.CollectAndCount(reg))
+	b.Commit()
+	// The batch is empty once committed.
+	b.Commit()
+
+	value := func(rule, sampleType string) float64 {
+		return testutil.ToFloat64(r.values.WithLabelValues("tenant", rule, sampleType))
+	}
+	require.Equal(t, float64(2*(100+200)), value("gc", "cpu:nanoseconds"))
+	require.Equal(t, float64(2*(1+2)), value("gc", "samples:count"))
+	// Sample "c" includes the function, but it's not the leaf.
+	require.Equal(t, float64(2*200), value("malloc_self", "cpu:nanoseconds"))
+	// The series of the rules that did not match are not created.
+	require.Equal(t, 4, testutil.CollectAndCount(reg))
+}
@@ -0,0 +1,112 @@
+package recording
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+	"gopkg.in/yaml.v3"
+
+	phlaremodel "github.com/grafana/pyroscope/pkg/model"
+)
+
+// Rule is a profile recording rule: the values of the samples of the
+// profiles matching the label selector, which stack traces include a
+// function matching the regular expression, are recorded as a metric.
+type Rule struct {
+	// Name identifies the rule: the value of the rule label of the metric.
+	Name string `yaml:"name" json:"name"`
+	// Selector is the label selector of the profile series,
+	// e.g. {service_name="api", __name__="process_cpu"}.
+	Selector string `yaml:"selector" json:"selector"`
+	// Function is the regular expression the function names are matched
+	// against. The expression is fully anchored.
+	Function string `yaml:"function" json:"function"`
+	// Self specifies that only the samples of which the matching
+	// function is the leaf of the stack trace are recorded. Otherwise,
+	// the function may appear anywhere in the stack trace.
+	Self bool `yaml:"self,omitempty" json:"self,omitempty"`
+
+	matchers []*labels.Matcher
+	function *regexp.Regexp
+}
+
+func (r *Rule) compile() (err error) {
+	if !model.LabelName(r.Name).IsValid() {
+		return fmt.Errorf("invalid recording rule name %q", r.Name)
+	}
+	if strings.TrimSpace(r.Selector) == "" {
+		return fmt.Errorf("recording rule %q: selector is required", r.Name)
+	}
+	if r.matchers, err = parser.ParseMetricSelector(r.Selector); err != nil {
+		return fmt.Errorf("recording rule %q: invalid selector: %w", r.Name, err)
+	}
+	if r.Function == "" {
+		return fmt.Errorf("recording rule %q: function is required", r.Name)
+	}
+	if r.function, err = regexp.Compile("^(?:" + r.Function + ")$"); err != nil {
+		return fmt.Errorf("recording rule %q: invalid function expression: %w", r.Name, err)
+	}
+	return nil
+}
+
+func (r *Rule) matchesLabels(lbls phlaremodel.Labels) bool {
+	for _, m := range r.matchers {
+		if !m.Matches(lbls.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Rule) UnmarshalYAML(value *yaml.Node) error {
+	type plain Rule
+	if err := value.DecodeWithOptions((*plain)(r), yaml.DecodeOptions{KnownFields: true}); err != nil {
+		return fmt.Errorf("malformed recording rule: %w", err)
+	}
+	return r.compile()
+}
+
+func (r *Rule) UnmarshalJSON(b []byte) error {
+	type plain Rule
+	if err := json.Unmarshal(b, (*plain)(r)); err != nil {
+		return fmt.Errorf("malformed recording rule: %w", err)
+	}
+	return r.compile()
+}
+
+// Rules is the list of the recording rules of a tenant.
+type Rules []*Rule
+
+// NewRules compiles the rules.
+func NewRules(rules ...Rule) (Rules, error) {
+	compiled := make(Rules, len(rules))
+	for i := range rules {
+		r := rules[i]
+		if err := r.compile(); err != nil {
+			return nil, err
+		}
+		compiled[i] = &r
+	}
+	return compiled, compiled.Validate(0)
+}
+
+// Validate checks that the rule names are unique and, if maxRules is
+// greater than zero, that the number of rules does not exceed it.
+func (rules Rules) Validate(maxRules int) error {
+	if maxRules > 0 && len(rules) > maxRules {
+		return fmt.Errorf("maximum number of recording rules is %d, got %d", maxRules, len(rules))
+	}
+	names := make(map[string]struct{}, len(rules))
+	for _, r := range rules {
+		if _, ok := names[r.Name]; ok {
+			return fmt.Errorf("duplicate recording rule name %q", r.Name)
+		}
+		names[r.Name] = struct{}{}
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/go-kit/log"
@@ -26,6 +27,7 @@ import (
 	"github.com/grafana/pyroscope/pkg/experiment/ingester/client/connpool"
 	"github.com/grafana/pyroscope/pkg/experiment/ingester/client/distributor"
 	"github.com/grafana/pyroscope/pkg/experiment/ingester/client/distributor/placement"
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend/block"
 	"github.com/grafana/pyroscope/pkg/util/circuitbreaker"
 )
 
@@ -257,6 +259,59 @@ func (c *Client) pushToInstance(
 	return segmentwriterv1.NewSegmentWriterServiceClient(conn).Push(ctx, req)
 }
 
+// HeadSnapshots returns the snapshots of the in-memory heads that have the
+// data of the tenants, collected from all the healthy segment writers. The
+// heads of the instances that fail to respond are not included: the data
+// is queried once it is flushed.
+func (c *Client) HeadSnapshots(ctx context.Context, tenants ...string) ([]*block.Object, error) {
+	instances, err := c.ring.GetAllHealthy(ring.Read)
+	if err != nil {
+		if errors.Is(err, ring.ErrEmptyRing) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	req := &segmentwriterv1.HeadSnapshotsRequest{TenantIds: tenants}
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		objects []*block.Object
+	)
+	for _, instance := range instances.Instances {
+		wg.Add(1)
+		go func(addr string) {
+			defer wg.Done()
+			resp, err := c.headSnapshotsFromInstance(ctx, req, addr)
+			if err != nil {
+				_ = level.Warn(c.logger).Log(
+					"msg", "failed to get head snapshots from segment writer",
+					"instance", addr,
+					"err", err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, s := range resp.Snapshots {
+				objects = append(objects, block.NewObjectFromData(s.Data, s.Block))
+			}
+		}(instance.Addr)
+	}
+	wg.Wait()
+	return objects, ctx.Err()
+}
+
+func (c *Client) headSnapshotsFromInstance(
+	ctx context.Context,
+	req *segmentwriterv1.HeadSnapshotsRequest,
+	addr string,
+) (*segmentwriterv1.HeadSnapshotsResponse, error) {
+	conn, err := c.pool.GetConnFor(addr)
+	if err != nil {
+		return nil, err
+	}
+	return segmentwriterv1.NewSegmentWriterServiceClient(conn).HeadSnapshots(ctx, req)
+}
+
 func newConnPool(
 	rring ring.ReadRing,
 	logger log.Logger,
@@ -16,6 +16,7 @@ import (
 	"go.uber.org/atomic"
 	"math"
 	"sync"
+	"time"
 )
 
 type FlushedHead struct {
@@ -33,6 +34,13 @@ type FlushedHead struct {
 }
 
 type Head struct {
+	// Ingestion is blocked while a snapshot is taken.
+	snapshotLock sync.RWMutex
+	// The latest snapshot, reused while the head has not
+	// changed, or for the max age, see Snapshot.
+	snapshotMu   sync.Mutex
+	snapshot     *FlushedHead
+	snapshotTime time.Time
 	symbols      *symdb.PartitionWriter
 	metaLock     sync.RWMutex
 	minTimeNanos int64
@@ -64,6 +72,8 @@ func (h *Head) Ingest(p *profilev1.Profile, id uuid.UUID, externalLabels []*type
 	if len(p.Sample) == 0 {
 		return
 	}
+	h.snapshotLock.RLock()
+	defer h.snapshotLock.RUnlock()
 
 	// delta not supported
 	externalLabels = phlaremodel.Labels(externalLabels).Delete(phlaremodel.LabelNameDelta)
@@ -132,6 +142,30 @@ func (h *Head) Flush(ctx context.Context) (res *FlushedHead, err error) {
 	return res, nil
 }
 
+// Snapshot returns the data ingested so far in the flushed form. Unlike
+// Flush, the head can be ingested to and snapshotted further. Ingestion
+// is blocked until the snapshot is taken, therefore the latest snapshot
+// is reused if nothing has been ingested since, or if it was taken less
+// than maxAge ago: the snapshot may lack the most recent data then. The
+// snapshot returned is shared and must not be modified.
+func (h *Head) Snapshot(ctx context.Context, maxAge time.Duration) (*FlushedHead, error) {
+	h.snapshotMu.Lock()
+	defer h.snapshotMu.Unlock()
+	if s := h.snapshot; s != nil {
+		if s.Meta.NumSamples == h.totalSamples.Load() || time.Since(h.snapshotTime) < maxAge {
+			return s, nil
+		}
+	}
+	h.snapshotLock.Lock()
+	s, err := h.flush(ctx)
+	h.snapshotLock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	h.snapshot, h.snapshotTime = s, time.Now()
+	return s, nil
+}
+
 func (h *Head) flush(ctx context.Context) (*FlushedHead, error) {
 	var (
 		err      error
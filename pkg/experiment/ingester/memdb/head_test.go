@@ -328,6 +328,33 @@ func TestHead_ProfileOrder(t *testing.T) {
 	}, rows)
 }
 
+func TestHeadSnapshot(t *testing.T) {
+	head := newTestHead()
+	head.Ingest(newProfileFoo(), uuid.New(), []*typesv1.LabelPair{{Name: "job", Value: "foo"}})
+	first, err := head.Snapshot(context.Background(), 0)
+	require.NoError(t, err)
+	// The snapshot is reused, as long as the head has not changed.
+	snapshot, err := head.Snapshot(context.Background(), 0)
+	require.NoError(t, err)
+	require.Same(t, first, snapshot)
+	head.Ingest(newProfileBar(), uuid.New(), []*typesv1.LabelPair{{Name: "job", Value: "bar"}})
+	// Or, until the snapshot gets older than the max age.
+	snapshot, err = head.Snapshot(context.Background(), time.Hour)
+	require.NoError(t, err)
+	require.Same(t, first, snapshot)
+
+	// The snapshot includes the data ingested after the previous one,
+	// and matches the head flushed.
+	snapshot, err = head.Snapshot(context.Background(), 0)
+	require.NoError(t, err)
+	flushed, err := head.Flush(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 2, snapshot.Meta.NumProfiles)
+	require.Equal(t, flushed.Meta, snapshot.Meta)
+	require.Equal(t, flushed.Index, snapshot.Index)
+	require.Equal(t, flushed.Symbols, snapshot.Symbols)
+}
+
 func TestFlushEmptyHead(t *testing.T) {
 	head := newTestHead()
 	flushed, err := head.Flush(context.Background())
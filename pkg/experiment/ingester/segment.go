@@ -37,11 +37,13 @@ var ErrMetastoreDLQFailed = fmt.Errorf("failed to store block metadata in DLQ")
 type shardKey uint32
 
 type segmentWriterConfig struct {
-	segmentDuration time.Duration
+	segmentDuration      time.Duration
+	headSnapshotInterval time.Duration
 }
 
 type segmentsWriter struct {
-	segmentDuration time.Duration
+	segmentDuration      time.Duration
+	headSnapshotInterval time.Duration
 
 	l               log.Logger
 	bucket          objstore.Bucket
@@ -131,6 +133,8 @@ func newSegmentWriter(l log.Logger, metrics *segmentMetrics, hm *memdb.HeadMetri
 		metastoreClient: metastoreClient,
 		cancel:          cancelFunc,
 		cancelCtx:       ctx,
+
+		headSnapshotInterval: cfg.headSnapshotInterval,
 	}
 
 	return sw
@@ -244,6 +248,16 @@ func (s *segment) flush(ctx context.Context) (err error) {
 
 func (s *segment) flushBlock(heads []flushedServiceHead) ([]byte, *metastorev1.BlockMeta, error) {
 	t1 := time.Now()
+	data, meta := s.concatBlock(heads)
+	for _, svc := range meta.Datasets {
+		s.sw.metrics.headSizeBytes.WithLabelValues(s.sshard, svc.TenantId).Observe(float64(svc.Size))
+	}
+	s.debuginfo.flushBlockDuration = time.Since(t1)
+	return data, meta, nil
+}
+
+// concatBlock concatenates the flushed heads into the segment block.
+func (s *segment) concatBlock(heads []flushedServiceHead) ([]byte, *metastorev1.BlockMeta) {
 	meta := &metastorev1.BlockMeta{
 		FormatVersion:   1,
 		Id:              s.ulid.String(),
@@ -273,13 +287,11 @@ func (s *segment) flushBlock(heads []flushedServiceHead) ([]byte, *metastorev1.B
 			meta.MinTime = math.Min(meta.MinTime, svc.MinTime)
 			meta.MaxTime = math.Max(meta.MaxTime, svc.MaxTime)
 		}
-		s.sw.metrics.headSizeBytes.WithLabelValues(s.sshard, e.key.tenant).Observe(float64(svc.Size))
 		meta.Datasets = append(meta.Datasets, svc)
 	}
 
 	meta.Size = uint64(w.offset)
-	s.debuginfo.flushBlockDuration = time.Since(t1)
-	return blockFile.Bytes(), meta, nil
+	return blockFile.Bytes(), meta
 }
 
 func concatSegmentHead(e flushedServiceHead, w *writerOffset) (*metastorev1.Dataset, error) {
@@ -347,14 +359,18 @@ func (s *segment) flushHeads(ctx context.Context) (moved []flushedServiceHead) {
 	}
 	wg.Wait()
 
-	slices.SortFunc(moved, func(i, j flushedServiceHead) int {
+	sortFlushedHeads(moved)
+	return moved
+}
+
+func sortFlushedHeads(heads []flushedServiceHead) {
+	slices.SortFunc(heads, func(i, j flushedServiceHead) int {
 		c := strings.Compare(i.key.tenant, j.key.tenant)
 		if c != 0 {
 			return c
 		}
 		return strings.Compare(i.key.service, j.key.service)
 	})
-	return moved
 }
 
 func (s *segment) flushHead(ctx context.Context, e serviceHead) (*memdb.FlushedHead, error) {
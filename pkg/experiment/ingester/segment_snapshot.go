@@ -0,0 +1,75 @@
+package ingester
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	segmentwriterv1 "github.com/grafana/pyroscope/api/gen/proto/go/segmentwriter/v1"
+	"github.com/grafana/pyroscope/pkg/tenant"
+)
+
+// HeadSnapshots returns the snapshots of the heads of the segments being
+// written that have the data of the tenants. A snapshot has the layout
+// and ID of the block the segment is flushed to. The segments that are
+// being flushed are not included.
+func (i *SegmentWriterService) HeadSnapshots(ctx context.Context, req *segmentwriterv1.HeadSnapshotsRequest) (*segmentwriterv1.HeadSnapshotsResponse, error) {
+	if len(req.TenantIds) == 0 {
+		return nil, status.Error(codes.InvalidArgument, tenant.ErrNoTenantID.Error())
+	}
+	snapshots, err := i.segmentWriter.headSnapshots(ctx, req.TenantIds)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &segmentwriterv1.HeadSnapshotsResponse{Snapshots: snapshots}, nil
+}
+
+func (sw *segmentsWriter) headSnapshots(ctx context.Context, tenants []string) ([]*segmentwriterv1.HeadSnapshot, error) {
+	sw.shardsLock.RLock()
+	segments := make([]*segment, 0, len(sw.shards))
+	for _, sh := range sw.shards {
+		sh.currentLock.RLock()
+		segments = append(segments, sh.current)
+		sh.currentLock.RUnlock()
+	}
+	sw.shardsLock.RUnlock()
+	snapshots := make([]*segmentwriterv1.HeadSnapshot, 0, len(segments))
+	for _, s := range segments {
+		heads, err := s.snapshotHeads(ctx, tenants)
+		if err != nil {
+			return nil, err
+		}
+		if len(heads) == 0 {
+			continue
+		}
+		data, meta := s.concatBlock(heads)
+		snapshots = append(snapshots, &segmentwriterv1.HeadSnapshot{Block: meta, Data: data})
+	}
+	return snapshots, nil
+}
+
+func (s *segment) snapshotHeads(ctx context.Context, tenants []string) ([]flushedServiceHead, error) {
+	s.headsLock.RLock()
+	heads := make([]serviceHead, 0, len(s.heads))
+	for k, e := range s.heads {
+		if slices.Contains(tenants, k.tenant) {
+			heads = append(heads, e)
+		}
+	}
+	s.headsLock.RUnlock()
+	snapshots := make([]flushedServiceHead, 0, len(heads))
+	for _, e := range heads {
+		snapshot, err := e.head.Snapshot(ctx, s.sw.headSnapshotInterval)
+		if err != nil {
+			return nil, fmt.Errorf("failed to snapshot head: %w", err)
+		}
+		if snapshot.Meta.NumSamples > 0 {
+			snapshots = append(snapshots, flushedServiceHead{e.key, snapshot})
+		}
+	}
+	sortFlushedHeads(snapshots)
+	return snapshots, nil
+}
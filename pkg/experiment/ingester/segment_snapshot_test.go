@@ -0,0 +1,97 @@
+package ingester
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	metastorev1 "github.com/grafana/pyroscope/api/gen/proto/go/metastore/v1"
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend"
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend/block"
+	"github.com/grafana/pyroscope/pkg/model"
+	"github.com/grafana/pyroscope/pkg/objstore"
+)
+
+type headSourceFunc func(context.Context, ...string) ([]*block.Object, error)
+
+func (fn headSourceFunc) HeadSnapshots(ctx context.Context, tenants ...string) ([]*block.Object, error) {
+	return fn(ctx, tenants...)
+}
+
+func TestHeadSnapshots(t *testing.T) {
+	sw := newTestSegmentWriter(t, segmentWriterConfig{segmentDuration: time.Hour})
+	sw.client.On("AddBlock", mock.Anything, mock.Anything, mock.Anything).
+		Return(new(metastorev1.AddBlockResponse), nil)
+	var await segmentWaitFlushed
+	defer func() {
+		// The segment is flushed on stop.
+		require.NoError(t, sw.Stop())
+		require.NoError(t, await.waitFlushed(context.Background()))
+	}()
+	ingest := func(tenant string, value int) {
+		await = sw.ingest(1, func(head segmentIngest) {
+			p := cpuProfile(value, 480, "svc1", "foo", "bar")
+			head.ingest(context.Background(), tenant, p.Profile, p.UUID, p.Labels)
+		})
+	}
+
+	reader := querybackend.NewBlockReader(log.NewNopLogger(), objstore.NewBucket(sw.bucket), nil, querybackend.Config{})
+	reader.SetHeadSource(headSourceFunc(func(ctx context.Context, tenants ...string) ([]*block.Object, error) {
+		snapshots, err := sw.headSnapshots(ctx, tenants)
+		if err != nil {
+			return nil, err
+		}
+		objects := make([]*block.Object, len(snapshots))
+		for i, s := range snapshots {
+			objects[i] = block.NewObjectFromData(s.Data, s.Block)
+		}
+		return objects, nil
+	}))
+	query := func(planned ...*metastorev1.BlockMeta) []*querybackendv1.Report {
+		resp, err := reader.Invoke(context.Background(), &querybackendv1.InvokeRequest{
+			Tenant:        []string{"t1"},
+			StartTime:     0,
+			EndTime:       math.MaxInt64 / int64(1e6),
+			LabelSelector: `{service_name="svc1"}`,
+			QueryPlan:     &querybackendv1.QueryPlan{Blocks: planned},
+			Options:       &querybackendv1.InvokeOptions{ReadHeads: true},
+			Query: []*querybackendv1.Query{{
+				QueryType: querybackendv1.QueryType_QUERY_TREE,
+				Tree:      new(querybackendv1.TreeQuery),
+			}},
+		})
+		require.NoError(t, err)
+		return resp.Reports
+	}
+	total := func(reports []*querybackendv1.Report) int64 {
+		require.Len(t, reports, 1)
+		return model.MustUnmarshalTree(reports[0].Tree.Tree).Total()
+	}
+
+	ingest("t1", 42)
+	ingest("t2", 13)
+	snapshots, err := sw.headSnapshots(context.Background(), []string{"t1"})
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	for _, ds := range snapshots[0].Block.Datasets {
+		require.Equal(t, "t1", ds.TenantId)
+	}
+
+	// The planned block is not stored: it has no datasets.
+	other := &metastorev1.BlockMeta{Id: "other"}
+	require.EqualValues(t, 42, total(query(other)))
+
+	// The head can be snapshotted repeatedly.
+	ingest("t1", 8)
+	require.EqualValues(t, 50, total(query(other)))
+
+	// The head has been flushed to the planned block.
+	flushed := &metastorev1.BlockMeta{Id: snapshots[0].Block.Id}
+	require.Empty(t, query(flushed))
+}
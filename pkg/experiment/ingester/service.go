@@ -38,6 +38,8 @@ type Config struct {
 	LifecyclerConfig ring.LifecyclerConfig `yaml:"lifecycler,omitempty"`
 	SegmentDuration  time.Duration         `yaml:"segmentDuration,omitempty"`
 	Async            bool                  `yaml:"async,omitempty"` //todo make it pertenant
+
+	HeadSnapshotInterval time.Duration `yaml:"head_snapshot_interval,omitempty"`
 }
 
 // RegisterFlags registers the flags.
@@ -47,6 +49,10 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.LifecyclerConfig.RegisterFlagsWithPrefix(prefix+".", f, util.Logger)
 	f.DurationVar(&cfg.SegmentDuration, prefix+".segment.duration", 500*time.Millisecond, "Timeout when flushing segments to bucket.")
 	f.BoolVar(&cfg.Async, prefix+".async", false, "Enable async mode for segment writer.")
+	f.DurationVar(&cfg.HeadSnapshotInterval, prefix+".head-snapshot-interval", 100*time.Millisecond,
+		"Minimum interval between the snapshots of a head queried before it is flushed. "+
+			"Ingestion into the head is blocked while the snapshot is taken; within the interval, "+
+			"the queries are served from the previous snapshot, which may lack the most recent data.")
 }
 
 func (cfg *Config) Validate() error {
@@ -125,7 +131,8 @@ func New(
 	headMetrics := memdb.NewHeadMetricsWithPrefix(reg, "pyroscope_segment_writer")
 
 	i.segmentWriter = newSegmentWriter(i.logger, segmentMetrics, headMetrics, segmentWriterConfig{
-		segmentDuration: cfg.SegmentDuration,
+		segmentDuration:      cfg.SegmentDuration,
+		headSnapshotInterval: cfg.HeadSnapshotInterval,
 	}, storageBucket, metastoreClient)
 	i.subservicesWatcher = services.NewFailureWatcher()
 	i.subservicesWatcher.WatchManager(i.subservices)
@@ -2,6 +2,9 @@ package metastoreclient
 
 import (
 	"context"
+	"fmt"
+	"sync"
+	"time"
 
 	"github.com/go-kit/log"
 
@@ -15,15 +18,49 @@ import (
 	metastorev1 "github.com/grafana/pyroscope/api/gen/proto/go/metastore/v1"
 )
 
+const (
+	raftLeaderHealthServiceName  = "metastore.v1.MetastoreService.RaftLeader"
+	readServingHealthServiceName = "metastore.v1.MetastoreService.ReadServing"
+)
+
 type Client struct {
 	metastorev1.MetastoreServiceClient
 	compactorv1.CompactionPlannerClient
 	service services.Service
 	conn    *grpc.ClientConn
+
+	// Follower reads, if enabled.
+	followerReads bool
+	reads         metastorev1.MetastoreServiceClient
+	readsConn     *grpc.ClientConn
+	lease         time.Duration
+	mu            sync.Mutex
+	readIndex     uint64
+	leasedAt      time.Time
+}
+
+type Option func(*Client)
+
+// WithFollowerReads makes the client send the read-only requests to
+// any replica that serves reads, including the followers, instead of the
+// leader. Before the request is sent, the client obtains the read index
+// from the leader: the replica serves the request once it has applied the
+// log up to the index, therefore the read observes all the writes that
+// completed before it started.
+//
+// If the lease is positive, the read index is reused for the requests
+// made within the lease period, which saves a round trip to the leader
+// at the cost of bounded staleness: the writes that completed within
+// the lease period might not be observed.
+func WithFollowerReads(lease time.Duration) Option {
+	return func(c *Client) {
+		c.followerReads = true
+		c.lease = lease
+	}
 }
 
-func New(address string, logger log.Logger, grpcClientConfig grpcclient.Config) (*Client, error) {
-	conn, err := dial(address, grpcClientConfig, logger)
+func New(address string, logger log.Logger, grpcClientConfig grpcclient.Config, opts ...Option) (*Client, error) {
+	conn, err := dial(address, grpcClientConfig, logger, raftLeaderHealthServiceName)
 	if err != nil {
 		return nil, err
 	}
@@ -32,21 +69,86 @@ func New(address string, logger log.Logger, grpcClientConfig grpcclient.Config)
 	c.CompactionPlannerClient = compactorv1.NewCompactionPlannerClient(conn)
 	c.service = services.NewIdleService(c.starting, c.stopping)
 	c.conn = conn
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.followerReads {
+		if c.readsConn, err = dial(address, grpcClientConfig, logger, readServingHealthServiceName); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		c.reads = metastorev1.NewMetastoreServiceClient(c.readsConn)
+	}
 	return &c, nil
 }
 
 func (c *Client) Service() services.Service      { return c.service }
 func (c *Client) starting(context.Context) error { return nil }
-func (c *Client) stopping(error) error           { return c.conn.Close() }
 
-func dial(address string, grpcClientConfig grpcclient.Config, _ log.Logger) (*grpc.ClientConn, error) {
+func (c *Client) stopping(error) error { return c.Close() }
+
+// Close closes the connections of the client. It is called when the
+// service stops, and is only needed if the service is never started.
+func (c *Client) Close() error {
+	if c.readsConn != nil {
+		_ = c.readsConn.Close()
+	}
+	return c.conn.Close()
+}
+
+// QueryMetadata queries the metadata of the blocks. If follower
+// reads are enabled, the request may be served by a follower.
+func (c *Client) QueryMetadata(
+	ctx context.Context,
+	in *metastorev1.QueryMetadataRequest,
+	opts ...grpc.CallOption,
+) (*metastorev1.QueryMetadataResponse, error) {
+	if c.reads == nil {
+		return c.MetastoreServiceClient.QueryMetadata(ctx, in, opts...)
+	}
+	readIndex, err := c.leaseReadIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req := in.CloneVT()
+	req.ReadIndex = max(req.ReadIndex, readIndex)
+	return c.reads.QueryMetadata(ctx, req, opts...)
+}
+
+// leaseReadIndex returns the read index obtained from the leader
+// within the lease period, or a new one.
+func (c *Client) leaseReadIndex(ctx context.Context) (uint64, error) {
+	c.mu.Lock()
+	if c.lease > 0 && time.Since(c.leasedAt) < c.lease {
+		readIndex := c.readIndex
+		c.mu.Unlock()
+		return readIndex, nil
+	}
+	c.mu.Unlock()
+	requested := time.Now()
+	resp, err := c.MetastoreServiceClient.ReadIndex(ctx, new(metastorev1.ReadIndexRequest))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get read index: %w", err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// The lease starts when the read index was requested: the index
+	// covers the writes that completed before that, but not after.
+	if resp.ReadIndex >= c.readIndex && requested.After(c.leasedAt) {
+		c.readIndex = resp.ReadIndex
+		c.leasedAt = requested
+	}
+	return resp.ReadIndex, nil
+}
+
+func dial(address string, grpcClientConfig grpcclient.Config, _ log.Logger, healthService string) (*grpc.ClientConn, error) {
 	options, err := grpcClientConfig.DialOption(nil, nil)
 	if err != nil {
 		return nil, err
 	}
 	// TODO: https://github.com/grpc/grpc-proto/blob/master/grpc/service_config/service_config.proto
 	options = append(options,
-		grpc.WithDefaultServiceConfig(grpcServiceConfig),
+		grpc.WithDefaultServiceConfig(fmt.Sprintf(grpcServiceConfig, healthService)),
 		grpc.WithUnaryInterceptor(otgrpc.OpenTracingClientInterceptor(opentracing.GlobalTracer())),
 	)
 	// TODO: Implement k8s grpc resolver.
@@ -65,7 +167,7 @@ func dial(address string, grpcClientConfig grpcclient.Config, _ log.Logger) (*gr
 
 const grpcServiceConfig = `{
 	"healthCheckConfig": {
-		"serviceName": "metastore.v1.MetastoreService.RaftLeader"
+		"serviceName": "%s"
 	},
     "loadBalancingPolicy":"round_robin",
     "methodConfig": [{
@@ -0,0 +1,58 @@
+package metastoreclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	metastorev1 "github.com/grafana/pyroscope/api/gen/proto/go/metastore/v1"
+	"github.com/grafana/pyroscope/pkg/test/mocks/mockmetastorev1"
+)
+
+func Test_Client_FollowerReads(t *testing.T) {
+	leader := mockmetastorev1.NewMockMetastoreServiceClient(t)
+	reads := mockmetastorev1.NewMockMetastoreServiceClient(t)
+	c := &Client{
+		MetastoreServiceClient: leader,
+		followerReads:          true,
+		reads:                  reads,
+		lease:                  time.Hour,
+	}
+
+	leader.On("ReadIndex", mock.Anything, mock.Anything).
+		Return(&metastorev1.ReadIndexResponse{ReadIndex: 42}, nil).Once()
+	reads.On("QueryMetadata", mock.Anything, mock.MatchedBy(func(r *metastorev1.QueryMetadataRequest) bool {
+		return r.ReadIndex == 42
+	})).Return(new(metastorev1.QueryMetadataResponse), nil).Times(2)
+
+	req := &metastorev1.QueryMetadataRequest{TenantId: []string{"tenant"}}
+	_, err := c.QueryMetadata(context.Background(), req)
+	require.NoError(t, err)
+	// The read index is reused within the lease period.
+	_, err = c.QueryMetadata(context.Background(), req)
+	require.NoError(t, err)
+	// The request is not modified.
+	require.Zero(t, req.ReadIndex)
+
+	// The read index is obtained for every query, if there is no lease.
+	c.lease = 0
+	leader.On("ReadIndex", mock.Anything, mock.Anything).
+		Return(&metastorev1.ReadIndexResponse{ReadIndex: 43}, nil).Once()
+	reads.On("QueryMetadata", mock.Anything, mock.MatchedBy(func(r *metastorev1.QueryMetadataRequest) bool {
+		return r.ReadIndex == 43
+	})).Return(new(metastorev1.QueryMetadataResponse), nil).Once()
+	_, err = c.QueryMetadata(context.Background(), req)
+	require.NoError(t, err)
+}
+
+func Test_Client_LeaderReads(t *testing.T) {
+	leader := mockmetastorev1.NewMockMetastoreServiceClient(t)
+	c := &Client{MetastoreServiceClient: leader}
+	leader.On("QueryMetadata", mock.Anything, mock.Anything).
+		Return(new(metastorev1.QueryMetadataResponse), nil).Once()
+	_, err := c.QueryMetadata(context.Background(), new(metastorev1.QueryMetadataRequest))
+	require.NoError(t, err)
+}
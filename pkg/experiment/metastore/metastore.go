@@ -39,7 +39,9 @@ const (
 	raftSnapshotInterval  = 180 * time.Second
 	raftSnapshotThreshold = 8 << 10
 
-	metastoreRaftLeaderHealthServiceName = "metastore.v1.MetastoreService.RaftLeader"
+	metastoreRaftLeaderHealthServiceName  = "metastore.v1.MetastoreService.RaftLeader"
+	metastoreReadServingHealthServiceName = "metastore.v1.MetastoreService.ReadServing"
+	metastoreRaftStatsPollInterval        = 15 * time.Second
 )
 
 type Config struct {
@@ -48,6 +50,9 @@ type Config struct {
 	DataDir          string            `yaml:"data_dir"`
 	Raft             RaftConfig        `yaml:"raft"`
 	Compaction       CompactionConfig  `yaml:"compaction_config"`
+
+	FollowerReads     bool          `yaml:"follower_reads"`
+	FollowerReadLease time.Duration `yaml:"follower_read_lease"`
 }
 
 type RaftConfig struct {
@@ -61,6 +66,9 @@ type RaftConfig struct {
 	AdvertiseAddress string `yaml:"advertise_address"`
 
 	ApplyTimeout time.Duration `yaml:"apply_timeout" doc:"hidden"`
+
+	LeaderHealthGracePeriod time.Duration `yaml:"leader_health_grace_period"`
+	LearnerPromotionMaxLag  uint64        `yaml:"learner_promotion_max_lag"`
 }
 
 func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
@@ -70,6 +78,12 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.StringVar(&cfg.DataDir, prefix+"data-dir", "./data-metastore/data", "")
 	cfg.Raft.RegisterFlagsWithPrefix(prefix+"raft.", f)
 	cfg.Compaction.RegisterFlagsWithPrefix(prefix+"compaction.", f)
+	f.BoolVar(&cfg.FollowerReads, prefix+"follower-reads", false,
+		"If enabled, the metadata queries are served by any metastore replica, including the followers. "+
+			"The replica serves the query once it has applied the raft log up to the read index obtained from the leader.")
+	f.DurationVar(&cfg.FollowerReadLease, prefix+"follower-read-lease", 0,
+		"Period of time the read index obtained from the leader is reused for the follower reads. "+
+			"The writes made within the period are not guaranteed to be observed. 0 to obtain the read index for every query.")
 }
 
 func (cfg *Config) Validate() error {
@@ -79,6 +93,9 @@ func (cfg *Config) Validate() error {
 	if err := cfg.GRPCClientConfig.Validate(); err != nil {
 		return err
 	}
+	if cfg.FollowerReadLease < 0 {
+		return fmt.Errorf("metastore.follower-read-lease must be non-negative")
+	}
 	return cfg.Raft.Validate()
 }
 
@@ -90,16 +107,26 @@ func (cfg *RaftConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
 	f.StringVar(&cfg.ServerID, prefix+"server-id", "localhost:9099", "")
 	f.StringVar(&cfg.AdvertiseAddress, prefix+"advertise-address", "localhost:9099", "")
 	f.DurationVar(&cfg.ApplyTimeout, prefix+"apply-timeout", 5*time.Second, "")
+	f.DurationVar(&cfg.LeaderHealthGracePeriod, prefix+"leader-health-grace-period", 0,
+		"Period of time the raft leader health check keeps serving after the node loses the leadership. "+
+			"If the node is elected again within the period, the health check does not flap. 0 to disable.")
+	f.Uint64Var(&cfg.LearnerPromotionMaxLag, prefix+"learner-promotion-max-lag", 0,
+		"If the node is the leader, it promotes the learners (non-voting members) to voters once they have "+
+			"replicated the raft log up to the given number of entries behind the leader. 0 to disable.")
 }
 
 func (cfg *RaftConfig) Validate() error {
 	// TODO(kolesnikovae): Check the params.
+	if cfg.LeaderHealthGracePeriod < 0 {
+		return fmt.Errorf("metastore.raft.leader-health-grace-period must be non-negative")
+	}
 	return nil
 }
 
 type Metastore struct {
 	service services.Service
 	metastorev1.MetastoreServiceServer
+	metastorev1.MetastoreAdminServiceServer
 	compactorv1.CompactionPlannerServer
 
 	config Config
@@ -117,6 +144,7 @@ type Metastore struct {
 	wal          *raftwal.WAL
 	snapshots    *raft.FileSnapshotStore
 	transport    *raft.NetworkTransport
+	replication  *raftReplication
 	raft         *raft.Raft
 	leaderhealth *raftleader.HealthObserver
 
@@ -149,6 +177,7 @@ func New(config Config, limits Limits, logger log.Logger, reg prometheus.Registe
 		metrics: metrics,
 		client:  client,
 	}
+	m.replication = newRaftReplication()
 	m.leaderhealth = raftleader.NewRaftLeaderHealthObserver(hs, logger, raftleader.NewMetrics(reg))
 	m.state = newMetastoreState(logger, m.db, m.reg, &config.Compaction)
 	m.service = services.NewBasicService(m.starting, m.running, m.stopping)
@@ -172,6 +201,10 @@ func (m *Metastore) starting(context.Context) error {
 	}
 	m.wg.Add(1)
 	go m.cleanupLoop()
+	if m.config.Raft.LearnerPromotionMaxLag > 0 {
+		m.wg.Add(1)
+		go m.learnerPromotionLoop()
+	}
 	return nil
 }
 
@@ -219,7 +252,8 @@ func (m *Metastore) initRaft() (err error) {
 	config.LocalID = raft.ServerID(m.config.Raft.ServerID)
 
 	fsm := newFSM(m.logger, m.db, m.state)
-	m.raft, err = raft.NewRaft(config, fsm, m.logStore, m.stableStore, m.snapshotStore, m.transport)
+	transport := &replicationTransport{raftTransport: m.transport, replication: m.replication}
+	m.raft, err = raft.NewRaft(config, fsm, m.logStore, m.stableStore, m.snapshotStore, transport)
 	if err != nil {
 		return fmt.Errorf("starting raft node: %w", err)
 	}
@@ -233,10 +267,21 @@ func (m *Metastore) initRaft() (err error) {
 		_ = level.Info(m.logger).Log("msg", "restoring existing state, not bootstraping")
 	}
 
-	m.leaderhealth.Register(m.raft, metastoreRaftLeaderHealthServiceName)
+	m.leaderhealth.Register(m.raft, metastoreRaftLeaderHealthServiceName,
+		raftleader.WithStatsPolling(metastoreRaftStatsPollInterval),
+		raftleader.WithSnapshotStore(m.snapshotStore),
+		raftleader.WithDemotionGracePeriod(m.config.Raft.LeaderHealthGracePeriod))
+	m.leaderhealth.Register(m.raft, metastoreReadServingHealthServiceName,
+		raftleader.WithReadServing())
 	return nil
 }
 
+// LatestRaftSnapshot returns the metadata of the latest raft snapshot
+// of the node. False is returned, if no snapshots have been taken yet.
+func (m *Metastore) LatestRaftSnapshot() (raftleader.SnapshotMeta, bool, error) {
+	return m.leaderhealth.LatestSnapshot(metastoreRaftLeaderHealthServiceName)
+}
+
 func (m *Metastore) openRaftStore() (hasState bool, err error) {
 	if err = m.createRaftDirs(); err != nil {
 		return false, err
@@ -287,6 +332,7 @@ func (m *Metastore) shutdownRaft() {
 				_ = level.Error(m.logger).Log("msg", "failed to transfer leadership", "err", err)
 			}
 		}
+		m.leaderhealth.Deregister(m.raft, metastoreReadServingHealthServiceName)
 		m.leaderhealth.Deregister(m.raft, metastoreRaftLeaderHealthServiceName)
 		if err := m.raft.Shutdown().Error(); err != nil {
 			_ = level.Error(m.logger).Log("msg", "failed to shutdown raft", "err", err)
@@ -0,0 +1,200 @@
+package metastore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/hashicorp/raft"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	metastorev1 "github.com/grafana/pyroscope/api/gen/proto/go/metastore/v1"
+)
+
+const (
+	learnerPromotionCheckInterval = 5 * time.Second
+	// The number of consecutive checks the learner must pass
+	// before it is promoted: a learner that has just installed
+	// a snapshot may fall behind again while catching up on the
+	// log entries that followed.
+	learnerPromotionChecks = 3
+)
+
+// AddLearner adds a non-voting member to the cluster. The learner receives
+// the snapshot and the log entries, but does not count toward the quorum:
+// the availability of the cluster is not affected while it catches up.
+func (m *Metastore) AddLearner(_ context.Context, req *metastorev1.AddLearnerRequest) (*metastorev1.AddLearnerResponse, error) {
+	if req.ServerId == "" || req.Address == "" {
+		return nil, status.Error(codes.InvalidArgument, "server id and address are required")
+	}
+	server, found, err := m.raftServer(raft.ServerID(req.ServerId))
+	if err != nil {
+		return nil, err
+	}
+	if found && server.Suffrage == raft.Voter {
+		return nil, status.Errorf(codes.FailedPrecondition, "server %s is a voter", req.ServerId)
+	}
+	_ = level.Info(m.logger).Log("msg", "adding learner", "server_id", req.ServerId, "address", req.Address)
+	err = m.raft.AddNonvoter(raft.ServerID(req.ServerId), raft.ServerAddress(req.Address), 0, m.config.Raft.ApplyTimeout).Error()
+	if err != nil {
+		return nil, raftMembershipError(err)
+	}
+	return new(metastorev1.AddLearnerResponse), nil
+}
+
+// PromoteLearner promotes a learner to voter. The request is idempotent:
+// promoting a voter has no effect.
+func (m *Metastore) PromoteLearner(_ context.Context, req *metastorev1.PromoteLearnerRequest) (*metastorev1.PromoteLearnerResponse, error) {
+	if req.ServerId == "" {
+		return nil, status.Error(codes.InvalidArgument, "server id is required")
+	}
+	server, found, err := m.raftServer(raft.ServerID(req.ServerId))
+	switch {
+	case err != nil:
+		return nil, err
+	case !found:
+		return nil, status.Errorf(codes.NotFound, "server %s is not a member of the cluster", req.ServerId)
+	case server.Suffrage == raft.Voter:
+		return new(metastorev1.PromoteLearnerResponse), nil
+	}
+	if req.MaxLag > 0 {
+		matchIndex, ok := m.replication.index(server.ID)
+		if !ok {
+			return nil, status.Errorf(codes.FailedPrecondition,
+				"server %s has not acknowledged any log entries", req.ServerId)
+		}
+		if lag := replicationLag(m.raft.CommitIndex(), matchIndex); lag > req.MaxLag {
+			return nil, status.Errorf(codes.FailedPrecondition,
+				"server %s is %d entries behind the leader, max lag is %d", req.ServerId, lag, req.MaxLag)
+		}
+	}
+	if err = m.promoteLearner(server); err != nil {
+		return nil, err
+	}
+	return new(metastorev1.PromoteLearnerResponse), nil
+}
+
+func (m *Metastore) promoteLearner(server raft.Server) error {
+	matchIndex, _ := m.replication.index(server.ID)
+	_ = level.Info(m.logger).Log("msg", "promoting learner", "server_id", server.ID, "match_index", matchIndex)
+	if err := m.raft.AddVoter(server.ID, server.Address, 0, m.config.Raft.ApplyTimeout).Error(); err != nil {
+		return raftMembershipError(err)
+	}
+	return nil
+}
+
+// raftServer returns the member of the cluster configuration. The
+// membership changes are only handled by the leader: the configuration
+// of a follower may be behind.
+func (m *Metastore) raftServer(id raft.ServerID) (raft.Server, bool, error) {
+	if m.raft.State() != raft.Leader {
+		return raft.Server{}, false, status.Error(codes.Unavailable, raft.ErrNotLeader.Error())
+	}
+	f := m.raft.GetConfiguration()
+	if err := f.Error(); err != nil {
+		return raft.Server{}, false, raftMembershipError(err)
+	}
+	for _, s := range f.Configuration().Servers {
+		if s.ID == id {
+			return s, true, nil
+		}
+	}
+	return raft.Server{}, false, nil
+}
+
+func raftMembershipError(err error) error {
+	if errors.Is(err, raft.ErrLeadershipLost) ||
+		errors.Is(err, raft.ErrNotLeader) ||
+		errors.Is(err, raft.ErrLeadershipTransferInProgress) ||
+		errors.Is(err, raft.ErrRaftShutdown) {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return err
+}
+
+func replicationLag(commitIndex, matchIndex uint64) uint64 {
+	if matchIndex >= commitIndex {
+		return 0
+	}
+	return commitIndex - matchIndex
+}
+
+// learnerPromotion decides whether the learner has caught up with the leader.
+type learnerPromotion struct {
+	maxLag   uint64
+	caughtUp int
+}
+
+// observe records the commit index of the leader and the index of the
+// last log entry acknowledged by the learner, and reports whether the
+// learner should be promoted.
+func (p *learnerPromotion) observe(commitIndex, matchIndex uint64) bool {
+	// Nothing has been acknowledged: the snapshot has not been installed yet.
+	if matchIndex == 0 || replicationLag(commitIndex, matchIndex) > p.maxLag {
+		p.caughtUp = 0
+		return false
+	}
+	p.caughtUp++
+	return p.caughtUp >= learnerPromotionChecks
+}
+
+// learnerPromotionLoop promotes the learners that have caught up with
+// the leader, if the local node is the leader. The lag is computed from
+// the replication state observed by the leader.
+func (m *Metastore) learnerPromotionLoop() {
+	t := time.NewTicker(learnerPromotionCheckInterval)
+	defer func() {
+		t.Stop()
+		m.wg.Done()
+	}()
+	learners := make(map[raft.ServerID]*learnerPromotion)
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-t.C:
+			if err := m.checkLearnerPromotion(learners); err != nil {
+				_ = level.Warn(m.logger).Log("msg", "learner promotion failed", "err", err)
+			}
+		}
+	}
+}
+
+func (m *Metastore) checkLearnerPromotion(learners map[raft.ServerID]*learnerPromotion) error {
+	if m.raft.State() != raft.Leader {
+		clear(learners)
+		return nil
+	}
+	f := m.raft.GetConfiguration()
+	if err := f.Error(); err != nil {
+		return err
+	}
+	seen := make(map[raft.ServerID]struct{}, len(learners))
+	for _, s := range f.Configuration().Servers {
+		if s.Suffrage != raft.Nonvoter {
+			continue
+		}
+		seen[s.ID] = struct{}{}
+		p, ok := learners[s.ID]
+		if !ok {
+			p = &learnerPromotion{maxLag: m.config.Raft.LearnerPromotionMaxLag}
+			learners[s.ID] = p
+		}
+		matchIndex, _ := m.replication.index(s.ID)
+		if !p.observe(m.raft.CommitIndex(), matchIndex) {
+			continue
+		}
+		if err := m.promoteLearner(s); err != nil {
+			return err
+		}
+		delete(learners, s.ID)
+	}
+	for id := range learners {
+		if _, ok := seen[id]; !ok {
+			delete(learners, id)
+		}
+	}
+	return nil
+}
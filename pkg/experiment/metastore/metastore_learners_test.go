@@ -0,0 +1,146 @@
+package metastore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/hashicorp/raft"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	metastorev1 "github.com/grafana/pyroscope/api/gen/proto/go/metastore/v1"
+)
+
+func newTestRaftNode(t *testing.T, id raft.ServerID, bootstrap bool, replication *raftReplication) (*raft.Raft, *raft.InmemTransport) {
+	config := raft.DefaultConfig()
+	config.LocalID = id
+	config.Logger = nil
+	config.HeartbeatTimeout = 50 * time.Millisecond
+	config.ElectionTimeout = 50 * time.Millisecond
+	config.LeaderLeaseTimeout = 50 * time.Millisecond
+	addr, transport := raft.NewInmemTransport(raft.ServerAddress(id))
+	store := raft.NewInmemStore()
+	snapshots := raft.NewInmemSnapshotStore()
+	if bootstrap {
+		servers := raft.Configuration{Servers: []raft.Server{{ID: id, Address: addr}}}
+		require.NoError(t, raft.BootstrapCluster(config, store, store, snapshots, transport, servers))
+	}
+	var trans raft.Transport = transport
+	if replication != nil {
+		trans = &replicationTransport{raftTransport: transport, replication: replication}
+	}
+	r, err := raft.NewRaft(config, new(raft.MockFSM), store, store, snapshots, trans)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = r.Shutdown().Error() })
+	return r, transport
+}
+
+func Test_Metastore_Learners(t *testing.T) {
+	replication := newRaftReplication()
+	leader, lt := newTestRaftNode(t, "leader", true, replication)
+	learner, ft := newTestRaftNode(t, "learner", false, nil)
+	lt.Connect(ft.LocalAddr(), ft)
+	ft.Connect(lt.LocalAddr(), lt)
+	require.Eventually(t, func() bool {
+		return leader.State() == raft.Leader
+	}, 5*time.Second, 10*time.Millisecond)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, leader.Apply([]byte("entry"), time.Second).Error())
+	}
+
+	m := &Metastore{
+		raft:        leader,
+		replication: replication,
+		logger:      log.NewNopLogger(),
+		config:      Config{Raft: RaftConfig{ApplyTimeout: 5 * time.Second, LearnerPromotionMaxLag: 1}},
+	}
+	ctx := context.Background()
+	suffrage := func(id raft.ServerID) raft.ServerSuffrage {
+		s, found, err := m.raftServer(id)
+		require.NoError(t, err)
+		require.True(t, found)
+		return s.Suffrage
+	}
+
+	_, err := m.AddLearner(ctx, &metastorev1.AddLearnerRequest{ServerId: "learner"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = m.AddLearner(ctx, &metastorev1.AddLearnerRequest{ServerId: "leader", Address: "leader"})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = m.PromoteLearner(ctx, &metastorev1.PromoteLearnerRequest{ServerId: "learner"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = m.AddLearner(ctx, &metastorev1.AddLearnerRequest{ServerId: "learner", Address: string(ft.LocalAddr())})
+	require.NoError(t, err)
+	require.Equal(t, raft.Nonvoter, suffrage("learner"))
+	require.Eventually(t, func() bool {
+		matchIndex, ok := replication.index("learner")
+		return ok && matchIndex >= leader.CommitIndex()
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, raft.Follower, learner.State())
+
+	// The learner is promoted once it passes the consecutive checks.
+	learners := make(map[raft.ServerID]*learnerPromotion)
+	for i := 1; i < learnerPromotionChecks; i++ {
+		require.NoError(t, m.checkLearnerPromotion(learners))
+		require.Equal(t, raft.Nonvoter, suffrage("learner"))
+	}
+	require.NoError(t, m.checkLearnerPromotion(learners))
+	require.Equal(t, raft.Voter, suffrage("learner"))
+	require.Empty(t, learners)
+
+	// The lag is checked against the replication state of the leader:
+	// the server has not acknowledged any entries.
+	_, err = m.AddLearner(ctx, &metastorev1.AddLearnerRequest{ServerId: "other", Address: "other"})
+	require.NoError(t, err)
+	_, err = m.PromoteLearner(ctx, &metastorev1.PromoteLearnerRequest{ServerId: "other", MaxLag: 1})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	require.Equal(t, raft.Nonvoter, suffrage("other"))
+	require.NoError(t, leader.RemoveServer("other", 0, time.Second).Error())
+
+	req := &metastorev1.PromoteLearnerRequest{ServerId: "learner", MaxLag: 1}
+	_, err = m.PromoteLearner(ctx, req)
+	require.NoError(t, err)
+
+	// The membership changes are only handled by the leader.
+	f := &Metastore{raft: learner, logger: log.NewNopLogger()}
+	_, err = f.AddLearner(ctx, &metastorev1.AddLearnerRequest{ServerId: "other", Address: "other"})
+	require.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func Test_LearnerPromotion(t *testing.T) {
+	p := learnerPromotion{maxLag: 10}
+	require.False(t, p.observe(100, 0))
+	require.False(t, p.observe(100, 80))
+	require.False(t, p.observe(100, 90))
+	require.False(t, p.observe(110, 105))
+	// The lag must remain below the threshold for the consecutive checks.
+	require.False(t, p.observe(200, 105))
+	require.False(t, p.observe(200, 190))
+	require.False(t, p.observe(200, 195))
+	require.True(t, p.observe(200, 201))
+}
+
+func Test_RaftReplication(t *testing.T) {
+	r := newRaftReplication()
+	_, ok := r.index("a")
+	require.False(t, ok)
+
+	success := &raft.AppendEntriesResponse{Success: true}
+	r.appended("a", &raft.AppendEntriesRequest{PrevLogEntry: 4, Entries: []*raft.Log{{Index: 5}, {Index: 6}}}, success)
+	// Heartbeats do not move the index back.
+	r.appended("a", new(raft.AppendEntriesRequest), success)
+	i, ok := r.index("a")
+	require.True(t, ok)
+	require.Equal(t, uint64(6), i)
+
+	r.appended("a", &raft.AppendEntriesRequest{PrevLogEntry: 6}, new(raft.AppendEntriesResponse))
+	_, ok = r.index("a")
+	require.False(t, ok)
+
+	r.installed("a", &raft.InstallSnapshotRequest{LastLogIndex: 10}, &raft.InstallSnapshotResponse{Success: true})
+	i, _ = r.index("a")
+	require.Equal(t, uint64(10), i)
+}
@@ -2,16 +2,62 @@ package metastore
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/go-kit/log"
 	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	metastorev1 "github.com/grafana/pyroscope/api/gen/proto/go/metastore/v1"
 )
 
-var tcheckFreq = 10 * time.Millisecond
+var (
+	tcheckFreq    = 10 * time.Millisecond
+	tcheckTimeout = 5 * time.Second
+)
+
+var errWaitTimeout = errors.New("timeout")
+
+// waitFor checks the condition periodically, until it is met, the
+// timeout expires, or the context is canceled.
+func waitFor(ctx context.Context, cond func() bool) error {
+	tcheck := time.NewTicker(tcheckFreq)
+	defer tcheck.Stop()
+	timeout := time.NewTimer(tcheckTimeout)
+	defer timeout.Stop()
+	for {
+		select {
+		case <-tcheck.C:
+			if cond() {
+				return nil
+			}
+		case <-timeout.C:
+			return errWaitTimeout
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// waitApplied waits for the node to apply the raft log up to the
+// read index. If the node does not catch up in time, the request
+// may be retried with another replica.
+func (m *Metastore) waitApplied(ctx context.Context, readIndex uint64) error {
+	if m.raft.AppliedIndex() >= readIndex {
+		return nil
+	}
+	err := waitFor(ctx, func() bool { return m.raft.AppliedIndex() >= readIndex })
+	switch {
+	case errors.Is(err, errWaitTimeout):
+		return status.Errorf(codes.Unavailable, "read index %d is not applied: applied index %d", readIndex, m.raft.AppliedIndex())
+	case err != nil:
+		return fmt.Errorf("canceled %w", err)
+	}
+	return nil
+}
 
 func (m *Metastore) ReadIndex(ctx context.Context, req *metastorev1.ReadIndexRequest) (*metastorev1.ReadIndexResponse, error) {
 	//todo
@@ -40,29 +86,21 @@ func (m *Metastore) ReadIndex(ctx context.Context, req *metastorev1.ReadIndexReq
 		return new(metastorev1.ReadIndexResponse), err
 	}
 
-	tcheck := time.NewTicker(tcheckFreq)
-	defer tcheck.Stop()
-	timeout := time.NewTimer(5 * time.Second)
-	defer timeout.Stop()
-
-	for {
-		select {
-		case <-tcheck.C:
-			appliedIndex := m.raft.AppliedIndex()
-			raftLogger().Log("msg", "tick")
-			if appliedIndex >= readIndex {
-				raftLogger().Log("msg", "caught up")
-				return &metastorev1.ReadIndexResponse{ReadIndex: readIndex}, nil
-			}
-			continue
-		case <-timeout.C:
-			raftLogger().Log("err", "timeout")
-			return new(metastorev1.ReadIndexResponse), fmt.Errorf("timeout")
-		case <-ctx.Done():
-			raftLogger().Log("err", "context canceled")
-			return new(metastorev1.ReadIndexResponse), fmt.Errorf("canceled %w", ctx.Err())
-		}
+	err := waitFor(ctx, func() bool {
+		appliedIndex := m.raft.AppliedIndex()
+		raftLogger().Log("msg", "tick")
+		return appliedIndex >= readIndex
+	})
+	switch {
+	case errors.Is(err, errWaitTimeout):
+		raftLogger().Log("err", "timeout")
+		return new(metastorev1.ReadIndexResponse), fmt.Errorf("timeout")
+	case err != nil:
+		raftLogger().Log("err", "context canceled")
+		return new(metastorev1.ReadIndexResponse), fmt.Errorf("canceled %w", err)
 	}
+	raftLogger().Log("msg", "caught up")
+	return &metastorev1.ReadIndexResponse{ReadIndex: readIndex}, nil
 }
 
 func (m *Metastore) CheckReady(ctx context.Context) (err error) {
@@ -96,37 +134,30 @@ func (m *Metastore) CheckReady(ctx context.Context) (err error) {
 	}
 	readIndex = res.ReadIndex
 
-	tcheck := time.NewTicker(tcheckFreq)
-	defer tcheck.Stop()
-	timeout := time.NewTimer(5 * time.Second)
-	defer timeout.Stop()
-
-	for {
-		select {
-		case <-tcheck.C:
-			commitIndex := m.raft.CommitIndex()
-			raftLogger().Log("msg", "tick")
-			if commitIndex >= res.ReadIndex {
-				if m.readySince.IsZero() {
-					m.readySince = time.Now()
-				}
-				minReadyTime := 30 * time.Second
-				if time.Since(m.readySince) < minReadyTime {
-					err := fmt.Errorf("waiting for %v after being ready", minReadyTime)
-					raftLogger().Log(status, notReady, "err", err)
-					return err
-				}
+	err = waitFor(ctx, func() bool {
+		commitIndex := m.raft.CommitIndex()
+		raftLogger().Log("msg", "tick")
+		return commitIndex >= res.ReadIndex
+	})
+	switch {
+	case errors.Is(err, errWaitTimeout):
+		raftLogger().Log(status, notReady, "err", "timeout")
+		return fmt.Errorf("metastore ready check timeout")
+	case err != nil:
+		raftLogger().Log(status, notReady, "err", "context canceled")
+		return fmt.Errorf("metastore check context canceled %w", err)
+	}
 
-				raftLogger().Log(status, ready)
-				return nil
-			}
-			continue
-		case <-timeout.C:
-			raftLogger().Log(status, notReady, "err", "timeout")
-			return fmt.Errorf("metastore ready check timeout")
-		case <-ctx.Done():
-			raftLogger().Log(status, notReady, "err", "context canceled")
-			return fmt.Errorf("metastore check context canceled %w", ctx.Err())
-		}
+	if m.readySince.IsZero() {
+		m.readySince = time.Now()
+	}
+	minReadyTime := 30 * time.Second
+	if time.Since(m.readySince) < minReadyTime {
+		err = fmt.Errorf("waiting for %v after being ready", minReadyTime)
+		raftLogger().Log(status, notReady, "err", err)
+		return err
 	}
+
+	raftLogger().Log(status, ready)
+	return nil
 }
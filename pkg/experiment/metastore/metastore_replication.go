@@ -0,0 +1,150 @@
+package metastore
+
+import (
+	"io"
+	"sync"
+
+	"github.com/hashicorp/raft"
+)
+
+// raftReplication tracks the replication of the raft log to the other
+// members of the cluster, as observed by the leader: the index of the last
+// log entry the member has acknowledged. The raft library does not expose
+// the replication state of the followers, therefore the responses are
+// inspected at the transport level.
+type raftReplication struct {
+	mu         sync.Mutex
+	matchIndex map[raft.ServerID]uint64
+}
+
+func newRaftReplication() *raftReplication {
+	return &raftReplication{matchIndex: make(map[raft.ServerID]uint64)}
+}
+
+// index returns the index of the last log entry acknowledged by the
+// member. False is returned if no entries have been acknowledged.
+func (r *raftReplication) index(id raft.ServerID) (uint64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.matchIndex[id]
+	return i, i > 0
+}
+
+func (r *raftReplication) appended(id raft.ServerID, req *raft.AppendEntriesRequest, resp *raft.AppendEntriesResponse) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !resp.Success {
+		// The log of the member is inconsistent with the one of
+		// the leader: the entries are to be replaced.
+		delete(r.matchIndex, id)
+		return
+	}
+	// Heartbeats carry neither the entries nor the previous one.
+	i := req.PrevLogEntry
+	if n := len(req.Entries); n > 0 {
+		i = req.Entries[n-1].Index
+	}
+	r.matchIndex[id] = max(r.matchIndex[id], i)
+}
+
+func (r *raftReplication) installed(id raft.ServerID, req *raft.InstallSnapshotRequest, resp *raft.InstallSnapshotResponse) {
+	if !resp.Success {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.matchIndex[id] = max(r.matchIndex[id], req.LastLogIndex)
+}
+
+type raftTransport interface {
+	raft.Transport
+	raft.WithClose
+	raft.WithPreVote
+}
+
+// replicationTransport records the replication state of the
+// members from the responses to the requests of the leader.
+type replicationTransport struct {
+	raftTransport
+	replication *raftReplication
+}
+
+func (t *replicationTransport) AppendEntries(
+	id raft.ServerID,
+	target raft.ServerAddress,
+	req *raft.AppendEntriesRequest,
+	resp *raft.AppendEntriesResponse,
+) error {
+	if err := t.raftTransport.AppendEntries(id, target, req, resp); err != nil {
+		return err
+	}
+	t.replication.appended(id, req, resp)
+	return nil
+}
+
+func (t *replicationTransport) InstallSnapshot(
+	id raft.ServerID,
+	target raft.ServerAddress,
+	req *raft.InstallSnapshotRequest,
+	resp *raft.InstallSnapshotResponse,
+	data io.Reader,
+) error {
+	if err := t.raftTransport.InstallSnapshot(id, target, req, resp, data); err != nil {
+		return err
+	}
+	t.replication.installed(id, req, resp)
+	return nil
+}
+
+func (t *replicationTransport) AppendEntriesPipeline(id raft.ServerID, target raft.ServerAddress) (raft.AppendPipeline, error) {
+	p, err := t.raftTransport.AppendEntriesPipeline(id, target)
+	if err != nil {
+		return nil, err
+	}
+	r := &replicationPipeline{
+		AppendPipeline: p,
+		id:             id,
+		replication:    t.replication,
+		consumer:       make(chan raft.AppendFuture),
+		done:           make(chan struct{}),
+	}
+	go r.relay()
+	return r, nil
+}
+
+// replicationPipeline relays the responses of the pipeline
+// to the consumer, once the replication state is recorded.
+type replicationPipeline struct {
+	raft.AppendPipeline
+	id          raft.ServerID
+	replication *raftReplication
+	consumer    chan raft.AppendFuture
+	done        chan struct{}
+	close       sync.Once
+}
+
+func (p *replicationPipeline) relay() {
+	c := p.AppendPipeline.Consumer()
+	for {
+		select {
+		case <-p.done:
+			return
+		case f := <-c:
+			if f.Error() == nil {
+				p.replication.appended(p.id, f.Request(), f.Response())
+			}
+			select {
+			case p.consumer <- f:
+			case <-p.done:
+				return
+			}
+		}
+	}
+}
+
+func (p *replicationPipeline) Consumer() <-chan raft.AppendFuture { return p.consumer }
+
+func (p *replicationPipeline) Close() error {
+	p.close.Do(func() { close(p.done) })
+	return p.AppendPipeline.Close()
+}
@@ -21,7 +21,9 @@ func (m *Metastore) QueryMetadata(
 	ctx context.Context,
 	request *metastorev1.QueryMetadataRequest,
 ) (*metastorev1.QueryMetadataResponse, error) {
-	// TODO(kolesnikovae): ReadIndex
+	if err := m.waitApplied(ctx, request.ReadIndex); err != nil {
+		return nil, err
+	}
 	return m.state.listBlocksForQuery(ctx, request)
 }
 
@@ -30,6 +32,8 @@ type metadataQuery struct {
 	endTime        int64
 	tenants        map[string]struct{}
 	serviceMatcher *labels.Matcher
+	// If not empty, only the blocks listed are selected.
+	blockIDs map[string]struct{}
 }
 
 func newMetadataQuery(request *metastorev1.QueryMetadataRequest) (*metadataQuery, error) {
@@ -44,6 +48,13 @@ func newMetadataQuery(request *metastorev1.QueryMetadataRequest) (*metadataQuery
 	for _, tenant := range request.TenantId {
 		q.tenants[tenant] = struct{}{}
 	}
+	if len(request.BlockIds) > 0 {
+		q.blockIDs = make(map[string]struct{}, len(request.BlockIds))
+		for _, id := range request.BlockIds {
+			q.blockIDs[id] = struct{}{}
+		}
+		return q, nil
+	}
 	selectors, err := parser.ParseMetricSelector(request.Query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse label selectors: %w", err)
@@ -61,6 +72,10 @@ func newMetadataQuery(request *metastorev1.QueryMetadataRequest) (*metadataQuery
 }
 
 func (q *metadataQuery) matchBlock(b *metastorev1.BlockMeta) bool {
+	if q.blockIDs != nil {
+		_, ok := q.blockIDs[b.Id]
+		return ok
+	}
 	return inRange(b.MinTime, b.MaxTime, q.startTime, q.endTime)
 }
 
@@ -69,6 +84,9 @@ func (q *metadataQuery) matchService(s *metastorev1.Dataset) bool {
 	if !ok {
 		return false
 	}
+	if q.blockIDs != nil {
+		return true
+	}
 	if !inRange(s.MinTime, s.MaxTime, q.startTime, q.endTime) {
 		return false
 	}
@@ -144,5 +162,31 @@ func (m *metastoreState) listBlocksForQuery(
 	slices.SortFunc(resp.Blocks, func(a, b *metastorev1.BlockMeta) int {
 		return strings.Compare(a.Id, b.Id)
 	})
+	if missing := q.missingBlocks(resp.Blocks); len(missing) > 0 {
+		return nil, status.Errorf(codes.NotFound, "blocks not found: %s", strings.Join(missing, ", "))
+	}
 	return &resp, nil
 }
+
+// missingBlocks returns the IDs of the blocks listed in the query
+// explicitly that are not found among the blocks given. A block that
+// has no datasets of the tenants queried is not found as well.
+func (q *metadataQuery) missingBlocks(blocks []*metastorev1.BlockMeta) []string {
+	if q.blockIDs == nil || len(q.blockIDs) == len(blocks) {
+		// No blocks are listed, or all of them are found: the
+		// blocks returned are only those listed in the query.
+		return nil
+	}
+	found := make(map[string]struct{}, len(blocks))
+	for _, b := range blocks {
+		found[b.Id] = struct{}{}
+	}
+	var missing []string
+	for id := range q.blockIDs {
+		if _, ok := found[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	slices.Sort(missing)
+	return missing
+}
@@ -0,0 +1,53 @@
+package metastore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	metastorev1 "github.com/grafana/pyroscope/api/gen/proto/go/metastore/v1"
+)
+
+func Test_ListBlocksForQuery_BlockIDs(t *testing.T) {
+	m := &metastoreState{shards: map[uint32]*metastoreShard{
+		0: {segments: map[string]*metastorev1.BlockMeta{
+			"a": {Id: "a", MinTime: 0, MaxTime: 10, Datasets: []*metastorev1.Dataset{
+				{TenantId: "t1", Name: "svc-1", MinTime: 0, MaxTime: 10},
+				{TenantId: "t2", Name: "svc-1", MinTime: 0, MaxTime: 10},
+			}},
+			"b": {Id: "b", MinTime: 100, MaxTime: 200, Datasets: []*metastorev1.Dataset{
+				{TenantId: "t1", Name: "svc-2", MinTime: 100, MaxTime: 200},
+			}},
+		}},
+		1: {segments: map[string]*metastorev1.BlockMeta{
+			"c": {Id: "c", MinTime: 100, MaxTime: 200, Datasets: []*metastorev1.Dataset{
+				{TenantId: "t2", Name: "svc-1", MinTime: 100, MaxTime: 200},
+			}},
+		}},
+	}}
+
+	// The time range and the query are not used for selection.
+	resp, err := m.listBlocksForQuery(context.Background(), &metastorev1.QueryMetadataRequest{
+		TenantId:  []string{"t1"},
+		StartTime: 1000,
+		EndTime:   2000,
+		BlockIds:  []string{"b", "a"},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Blocks, 2)
+	require.Equal(t, "a", resp.Blocks[0].Id)
+	require.Len(t, resp.Blocks[0].Datasets, 1)
+	require.Equal(t, "t1", resp.Blocks[0].Datasets[0].TenantId)
+	require.Equal(t, "b", resp.Blocks[1].Id)
+
+	// Block "c" has no datasets of the tenant.
+	_, err = m.listBlocksForQuery(context.Background(), &metastorev1.QueryMetadataRequest{
+		TenantId: []string{"t1"},
+		BlockIds: []string{"a", "c", "d"},
+	})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	require.ErrorContains(t, err, "blocks not found: c, d")
+}
@@ -1,7 +1,11 @@
 package raftleader
 
 import (
+	"context"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 
@@ -19,9 +23,40 @@ type HealthObserver struct {
 	mu         sync.Mutex
 	registered map[serviceKey]*raftService
 	metrics    *Metrics
+
+	sm          sync.Mutex
+	subscribers []chan StatusChange
+	shutdown    bool
+
+	om     sync.Mutex
+	forced map[string]grpc_health_v1.HealthCheckResponse_ServingStatus
+	paused map[string]struct{}
 }
+
+// StatusChange describes a transition of the service health status.
+type StatusChange struct {
+	Service string
+	Old     grpc_health_v1.HealthCheckResponse_ServingStatus
+	New     grpc_health_v1.HealthCheckResponse_ServingStatus
+}
+
+// The number of status changes buffered per subscriber. If the buffer
+// is full, the status change is dropped for the subscriber.
+const subscriberBufferSize = 16
+
+// The time Deregister waits for the service to stop,
+// unless WithDeregisterGracePeriod is specified.
+const defaultDeregisterGracePeriod = 10 * time.Second
+
 type Metrics struct {
-	status prometheus.Gauge
+	status             prometheus.Gauge
+	droppedChanges     prometheus.Counter
+	deregisterTimeouts prometheus.Counter
+
+	commitIndex  *prometheus.GaugeVec
+	appliedIndex *prometheus.GaugeVec
+	lastLogIndex *prometheus.GaugeVec
+	term         *prometheus.GaugeVec
 }
 
 func NewMetrics(reg prometheus.Registerer) *Metrics {
@@ -30,9 +65,47 @@ func NewMetrics(reg prometheus.Registerer) *Metrics {
 			Namespace: "pyroscope",
 			Name:      "metastore_raft_status",
 		}),
+		droppedChanges: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "pyroscope",
+			Name:      "metastore_raft_status_changes_dropped_total",
+			Help:      "Number of health status changes not delivered to subscribers because their buffer was full.",
+		}),
+		deregisterTimeouts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "pyroscope",
+			Name:      "metastore_raft_deregister_timeouts_total",
+			Help:      "Number of health check deregistrations that exceeded the grace period.",
+		}),
+		commitIndex: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "pyroscope",
+			Name:      "metastore_raft_commit_index",
+			Help:      "The index of the latest committed raft log entry.",
+		}, []string{"service"}),
+		appliedIndex: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "pyroscope",
+			Name:      "metastore_raft_applied_index",
+			Help:      "The index of the latest raft log entry applied to the state machine.",
+		}, []string{"service"}),
+		lastLogIndex: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "pyroscope",
+			Name:      "metastore_raft_last_log_index",
+			Help:      "The index of the last entry in the raft log.",
+		}, []string{"service"}),
+		term: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "pyroscope",
+			Name:      "metastore_raft_term",
+			Help:      "The current raft term.",
+		}, []string{"service"}),
 	}
 	if reg != nil {
-		reg.MustRegister(m.status)
+		reg.MustRegister(
+			m.status,
+			m.droppedChanges,
+			m.deregisterTimeouts,
+			m.commitIndex,
+			m.appliedIndex,
+			m.lastLogIndex,
+			m.term,
+		)
 	}
 	return m
 }
@@ -43,10 +116,85 @@ func NewRaftLeaderHealthObserver(hs health.Service, logger log.Logger, m *Metric
 		logger:     logger,
 		metrics:    m,
 		registered: make(map[serviceKey]*raftService),
+		forced:     make(map[string]grpc_health_v1.HealthCheckResponse_ServingStatus),
+		paused:     make(map[string]struct{}),
+	}
+}
+
+type RegisterOption func(*raftService)
+
+// WithStatsPolling makes the observer export the raft log indices
+// and the term as gauges labeled with the service name. The values
+// are polled from the raft stats at the given interval, until the
+// service is deregistered.
+func WithStatsPolling(interval time.Duration) RegisterOption {
+	return func(svc *raftService) {
+		svc.statsInterval = interval
+	}
+}
+
+// WithSnapshotStore makes the observer report the latest snapshot of
+// the store, see LatestSnapshot. The store is expected to be the one
+// the raft uses.
+func WithSnapshotStore(store raft.SnapshotStore) RegisterOption {
+	return func(svc *raftService) {
+		svc.snapshots = store
+	}
+}
+
+// WithDemotionGracePeriod delays the removal of the service from serving
+// once the node loses the leadership: during an election, a new leader is
+// usually elected shortly, and the node may be the one. If the node is the
+// leader again by the end of the grace period, the service keeps serving.
+// The service is moved to serving immediately, and the overrides are not
+// delayed. The raft status gauge reflects the actual raft state, while the
+// demotion is pending.
+func WithDemotionGracePeriod(d time.Duration) RegisterOption {
+	return func(svc *raftService) {
+		svc.gracePeriod = d
+	}
+}
+
+// WithDeregisterGracePeriod sets the time Deregister waits for the
+// service to stop. If the service does not stop in time, e.g. because
+// the health service blocks, it is detached and stops in background:
+// the shutdown is not stalled by the service. Defaults to 10 seconds.
+func WithDeregisterGracePeriod(d time.Duration) RegisterOption {
+	return func(svc *raftService) {
+		svc.deregisterGracePeriod = d
+	}
+}
+
+// WithReadServing makes the service report whether the node can serve
+// reads, rather than whether it is the leader: the service is serving on
+// the leader, and on the followers that know the current leader, including
+// the non-voting ones. A follower that lost the contact with the leader is
+// removed from serving, once it becomes a candidate, or learns that the
+// leader is unknown. Note that the state of a follower may be stale: the
+// consistency of the reads must be ensured by the caller.
+func WithReadServing() RegisterOption {
+	return func(svc *raftService) {
+		svc.readServing = true
+	}
+}
+
+// withRaftState makes the service obtain the raft state from the
+// function instead of the raft, e.g., to simulate an election.
+func withRaftState(state func() raft.RaftState) RegisterOption {
+	return func(svc *raftService) {
+		svc.state = state
+	}
+}
+
+// withKnownLeader makes the service obtain from the function whether
+// the leader is known, instead of the raft.
+func withKnownLeader(known func() bool) RegisterOption {
+	return func(svc *raftService) {
+		svc.knownLeader = known
 	}
 }
 
-func (hs *HealthObserver) Register(r *raft.Raft, service string) {
+func (hs *HealthObserver) Register(r *raft.Raft, service string, opts ...RegisterOption) {
 	hs.mu.Lock()
 	defer hs.mu.Unlock()
 	k := serviceKey{raft: r, service: service}
@@ -60,8 +208,17 @@ func (hs *HealthObserver) Register(r *raft.Raft, service string) {
 		service: service,
 		raft:    r,
 		c:       make(chan raft.Observation, 1),
+		refresh: make(chan struct{}, 1),
 		stop:    make(chan struct{}),
 		done:    make(chan struct{}),
+		state:   r.State,
+		knownLeader: func() bool {
+			addr, _ := r.LeaderWithID()
+			return addr != ""
+		},
+	}
+	for _, opt := range opts {
+		opt(svc)
 	}
 	_ = level.Debug(svc.logger).Log("msg", "registering health check")
 	svc.updateStatus()
@@ -74,7 +231,29 @@ func (hs *HealthObserver) Register(r *raft.Raft, service string) {
 	hs.registered[k] = svc
 }
 
+// Deregister stops observing the raft for the service, and removes the
+// service from serving. The call waits for the service to stop for no
+// longer than the grace period, see WithDeregisterGracePeriod.
 func (hs *HealthObserver) Deregister(r *raft.Raft, service string) {
+	gracePeriod := defaultDeregisterGracePeriod
+	hs.mu.Lock()
+	if svc, ok := hs.registered[serviceKey{raft: r, service: service}]; ok && svc.deregisterGracePeriod > 0 {
+		gracePeriod = svc.deregisterGracePeriod
+	}
+	hs.mu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), gracePeriod)
+	defer cancel()
+	if err := hs.DeregisterContext(ctx, r, service); err != nil {
+		hs.metrics.deregisterTimeouts.Inc()
+		_ = level.Warn(hs.logger).Log("msg", "health check is not deregistered within the grace period; detaching",
+			"service", service, "grace_period", gracePeriod)
+	}
+}
+
+// DeregisterContext is like Deregister, but waits for the service to stop
+// until the context is done. If the context is done first, the service is
+// detached and stops in background, and the context error is returned.
+func (hs *HealthObserver) DeregisterContext(ctx context.Context, r *raft.Raft, service string) (err error) {
 	hs.mu.Lock()
 	k := serviceKey{raft: r, service: service}
 	svc, ok := hs.registered[k]
@@ -82,8 +261,220 @@ func (hs *HealthObserver) Deregister(r *raft.Raft, service string) {
 	hs.mu.Unlock()
 	if ok {
 		close(svc.stop)
-		<-svc.done
+		select {
+		case <-svc.done:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+	hs.mu.Lock()
+	last := len(hs.registered) == 0
+	hs.mu.Unlock()
+	if last {
+		// The status changes of a detached service
+		// are not delivered to the subscribers.
+		hs.closeSubscribers(false)
+	}
+	return err
+}
+
+// ForceStatus overrides the health status of the service, e.g., to
+// drain the node for maintenance. The override takes precedence over
+// the raft observations until it is cleared with ClearForcedStatus:
+// a leadership change does not alter the status of the service. The
+// override also applies to the service registered after the call.
+func (hs *HealthObserver) ForceStatus(service string, status grpc_health_v1.HealthCheckResponse_ServingStatus) {
+	hs.om.Lock()
+	hs.forced[service] = status
+	hs.om.Unlock()
+	hs.refresh(service)
+}
+
+// ClearForcedStatus removes the status override of the service:
+// the status is determined by the raft state again.
+func (hs *HealthObserver) ClearForcedStatus(service string) {
+	hs.om.Lock()
+	delete(hs.forced, service)
+	hs.om.Unlock()
+	hs.refresh(service)
+}
+
+// Pause stops updating the health status of the service, e.g., during a
+// maintenance window: the raft observations and the status overrides are
+// not processed, and the status is frozen at its last value until Resume
+// is called. The service remains registered, and is removed from serving
+// if deregistered while paused. Pause also applies to the service
+// registered after the call, once its initial status has been set.
+func (hs *HealthObserver) Pause(service string) {
+	hs.om.Lock()
+	hs.paused[service] = struct{}{}
+	hs.om.Unlock()
+}
+
+// Resume resumes updating the health status of the paused service: the
+// status is determined by the current raft state, or the override, as
+// the observations made while the service was paused are discarded.
+func (hs *HealthObserver) Resume(service string) {
+	hs.om.Lock()
+	_, ok := hs.paused[service]
+	delete(hs.paused, service)
+	hs.om.Unlock()
+	if ok {
+		hs.refresh(service)
+	}
+}
+
+func (hs *HealthObserver) isPaused(service string) bool {
+	hs.om.Lock()
+	defer hs.om.Unlock()
+	_, ok := hs.paused[service]
+	return ok
+}
+
+func (hs *HealthObserver) forcedStatus(service string) (grpc_health_v1.HealthCheckResponse_ServingStatus, bool) {
+	hs.om.Lock()
+	defer hs.om.Unlock()
+	status, ok := hs.forced[service]
+	return status, ok
+}
+
+func (hs *HealthObserver) refresh(service string) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	for k, svc := range hs.registered {
+		if k.service == service {
+			select {
+			case svc.refresh <- struct{}{}:
+			default:
+				// The status update is already pending.
+			}
+		}
+	}
+}
+
+// Shutdown deregisters all the services and closes the subscriptions.
+// Subscriptions made after the shutdown are closed immediately.
+func (hs *HealthObserver) Shutdown() {
+	hs.mu.Lock()
+	registered := make([]serviceKey, 0, len(hs.registered))
+	for k := range hs.registered {
+		registered = append(registered, k)
+	}
+	hs.mu.Unlock()
+	for _, k := range registered {
+		hs.Deregister(k.raft, k.service)
+	}
+	hs.closeSubscribers(true)
+}
+
+// Subscribe returns a channel that receives health status changes of
+// all the registered services. The channel is closed once the last
+// registered service is deregistered, or when the observer is shut down.
+//
+// Status changes are never blocked by subscribers: if the subscriber
+// does not keep up with the changes, they are dropped.
+func (hs *HealthObserver) Subscribe() <-chan StatusChange {
+	c := make(chan StatusChange, subscriberBufferSize)
+	hs.sm.Lock()
+	defer hs.sm.Unlock()
+	if hs.shutdown {
+		close(c)
+		return c
+	}
+	hs.subscribers = append(hs.subscribers, c)
+	return c
+}
+
+func (hs *HealthObserver) notify(change StatusChange) {
+	hs.sm.Lock()
+	defer hs.sm.Unlock()
+	for _, c := range hs.subscribers {
+		select {
+		case c <- change:
+		default:
+			hs.metrics.droppedChanges.Inc()
+		}
+	}
+}
+
+func (hs *HealthObserver) closeSubscribers(shutdown bool) {
+	hs.sm.Lock()
+	defer hs.sm.Unlock()
+	for _, c := range hs.subscribers {
+		close(c)
+	}
+	hs.subscribers = nil
+	hs.shutdown = hs.shutdown || shutdown
+}
+
+// SnapshotMeta describes a raft snapshot.
+type SnapshotMeta struct {
+	// ID of the snapshot in the snapshot store.
+	ID    string
+	Index uint64
+	Term  uint64
+	// Size of the snapshot in bytes.
+	Size int64
+	// Time the snapshot was taken.
+	Time time.Time
+}
+
+// LatestSnapshot returns the metadata of the latest raft snapshot of the
+// service. False is returned, if the service is not registered, or no
+// snapshots have been taken yet. Only the index and the term are known,
+// unless the service is registered with the snapshot store; the time is
+// only known for the snapshots of the file snapshot store.
+func (hs *HealthObserver) LatestSnapshot(service string) (SnapshotMeta, bool, error) {
+	hs.mu.Lock()
+	var svc *raftService
+	for k, x := range hs.registered {
+		if k.service == service {
+			svc = x
+			break
+		}
 	}
+	hs.mu.Unlock()
+	if svc == nil {
+		return SnapshotMeta{}, false, nil
+	}
+	return svc.latestSnapshot()
+}
+
+func (svc *raftService) latestSnapshot() (SnapshotMeta, bool, error) {
+	if svc.snapshots == nil {
+		stats := svc.raft.Stats()
+		index, _ := strconv.ParseUint(stats["last_snapshot_index"], 10, 64)
+		term, _ := strconv.ParseUint(stats["last_snapshot_term"], 10, 64)
+		return SnapshotMeta{Index: index, Term: term}, index > 0, nil
+	}
+	snapshots, err := svc.snapshots.List()
+	if err != nil || len(snapshots) == 0 {
+		return SnapshotMeta{}, false, err
+	}
+	// The snapshots are listed from the newest to the oldest.
+	s := snapshots[0]
+	return SnapshotMeta{
+		ID:    s.ID,
+		Index: s.Index,
+		Term:  s.Term,
+		Size:  s.Size,
+		Time:  snapshotTime(s.ID),
+	}, true, nil
+}
+
+// snapshotTime returns the time the snapshot was taken, if the snapshot
+// ID has the format of the file snapshot store: "term-index-timestamp",
+// with the timestamp in milliseconds. Otherwise, zero time is returned.
+func snapshotTime(id string) time.Time {
+	parts := strings.Split(id, "-")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	ms, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
 }
 
 type serviceKey struct {
@@ -99,35 +490,185 @@ type raftService struct {
 	raft     *raft.Raft
 	observer *raft.Observer
 	c        chan raft.Observation
+	refresh  chan struct{}
 	stop     chan struct{}
 	done     chan struct{}
+
+	statsInterval time.Duration
+	snapshots     raft.SnapshotStore
+	gracePeriod   time.Duration
+	readServing   bool
+	state         func() raft.RaftState
+	knownLeader   func() bool
+
+	deregisterGracePeriod time.Duration
+	// Accessed by the service goroutine only,
+	// once the service has been registered.
+	status grpc_health_v1.HealthCheckResponse_ServingStatus
+	// Pending removal from serving, if any.
+	demotion *time.Timer
 }
 
 func (svc *raftService) run() {
 	defer func() {
 		close(svc.done)
 	}()
+	var stats <-chan time.Time
+	if svc.statsInterval > 0 {
+		ticker := time.NewTicker(svc.statsInterval)
+		defer ticker.Stop()
+		stats = ticker.C
+		svc.updateStats()
+	}
 	for {
 		select {
 		case <-svc.c:
-			svc.updateStatus()
+			svc.updateStatusUnlessPaused()
+		case <-svc.refresh:
+			svc.updateStatusUnlessPaused()
+		case <-stats:
+			svc.updateStats()
+		case <-svc.demotionC():
+			svc.demotion = nil
+			svc.demoteUnlessPaused()
 		case <-svc.stop:
-			_ = level.Debug(svc.logger).Log("msg", "deregistering health check")
-			// We explicitly remove the service from serving when we stop observing it.
-			svc.server.SetServingStatus(svc.service, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
-			svc.raft.DeregisterObserver(svc.observer)
+			svc.cancelDemotion()
+			svc.deregister(stats != nil)
 			return
 		}
 	}
 }
 
+// deregister removes the service from serving and stops observing
+// the raft. A panic, e.g. of a misbehaving health service, is logged:
+// the observer is deregistered regardless, so that it does not leak.
+func (svc *raftService) deregister(stats bool) {
+	_ = level.Debug(svc.logger).Log("msg", "deregistering health check")
+	defer func() {
+		if r := recover(); r != nil {
+			_ = level.Error(svc.logger).Log("msg", "panic while deregistering health check", "err", r)
+		}
+	}()
+	defer func() {
+		svc.raft.DeregisterObserver(svc.observer)
+		if stats {
+			svc.deleteStats()
+		}
+	}()
+	// We explicitly remove the service from serving when we stop observing it.
+	svc.setStatus(grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+}
+
+func (svc *raftService) updateStats() {
+	m := svc.hs.metrics
+	stats := svc.raft.Stats()
+	for key, g := range map[string]*prometheus.GaugeVec{
+		"commit_index":   m.commitIndex,
+		"applied_index":  m.appliedIndex,
+		"last_log_index": m.lastLogIndex,
+		"term":           m.term,
+	} {
+		v, err := strconv.ParseUint(stats[key], 10, 64)
+		if err != nil {
+			_ = level.Warn(svc.logger).Log("msg", "invalid raft stats value", "key", key, "value", stats[key])
+			continue
+		}
+		g.WithLabelValues(svc.service).Set(float64(v))
+	}
+}
+
+func (svc *raftService) deleteStats() {
+	m := svc.hs.metrics
+	for _, g := range []*prometheus.GaugeVec{m.commitIndex, m.appliedIndex, m.lastLogIndex, m.term} {
+		g.DeleteLabelValues(svc.service)
+	}
+}
+
 func (svc *raftService) updateStatus() {
+	status, forced := svc.currentStatus()
+	if !forced && svc.gracePeriod > 0 &&
+		status == grpc_health_v1.HealthCheckResponse_NOT_SERVING &&
+		svc.status == grpc_health_v1.HealthCheckResponse_SERVING {
+		if svc.demotion == nil {
+			_ = level.Info(svc.logger).Log("msg", "leadership lost; health status update is delayed", "grace_period", svc.gracePeriod)
+			svc.demotion = time.NewTimer(svc.gracePeriod)
+		}
+		return
+	}
+	svc.cancelDemotion()
+	_ = level.Info(svc.logger).Log("msg", "updating health status", "status", status)
+	svc.setStatus(status)
+}
+
+// currentStatus returns the status of the service that corresponds to
+// the raft state, or the override, if any.
+func (svc *raftService) currentStatus() (grpc_health_v1.HealthCheckResponse_ServingStatus, bool) {
+	state := svc.state()
 	status := grpc_health_v1.HealthCheckResponse_NOT_SERVING
-	if svc.raft.State() == raft.Leader {
+	if svc.serving(state) {
 		status = grpc_health_v1.HealthCheckResponse_SERVING
 	}
-	svc.hs.metrics.status.Set(float64(svc.raft.State()))
+	svc.hs.metrics.status.Set(float64(state))
+	if forced, ok := svc.hs.forcedStatus(svc.service); ok {
+		// The manual override always wins over the raft state.
+		if forced != status {
+			_ = level.Info(svc.logger).Log("msg", "health status is overridden", "status", forced, "raft_status", status)
+		}
+		return forced, true
+	}
+	return status, false
+}
 
+func (svc *raftService) serving(state raft.RaftState) bool {
+	switch state {
+	case raft.Leader:
+		return true
+	case raft.Follower:
+		return svc.readServing && svc.knownLeader()
+	default:
+		return false
+	}
+}
+
+// demoteUnlessPaused applies the status once the grace period is over:
+// if the node is still not the leader, the service is removed from
+// serving.
+func (svc *raftService) demoteUnlessPaused() {
+	if svc.hs.isPaused(svc.service) {
+		_ = level.Debug(svc.logger).Log("msg", "health status update skipped: paused", "status", svc.status)
+		return
+	}
+	status, _ := svc.currentStatus()
 	_ = level.Info(svc.logger).Log("msg", "updating health status", "status", status)
+	svc.setStatus(status)
+}
+
+func (svc *raftService) demotionC() <-chan time.Time {
+	if svc.demotion == nil {
+		return nil
+	}
+	return svc.demotion.C
+}
+
+func (svc *raftService) cancelDemotion() {
+	if svc.demotion != nil {
+		svc.demotion.Stop()
+		svc.demotion = nil
+	}
+}
+
+func (svc *raftService) updateStatusUnlessPaused() {
+	if svc.hs.isPaused(svc.service) {
+		_ = level.Debug(svc.logger).Log("msg", "health status update skipped: paused", "status", svc.status)
+		return
+	}
+	svc.updateStatus()
+}
+
+func (svc *raftService) setStatus(status grpc_health_v1.HealthCheckResponse_ServingStatus) {
 	svc.server.SetServingStatus(svc.service, status)
+	if old := svc.status; old != status {
+		svc.status = status
+		svc.hs.notify(StatusChange{Service: svc.service, Old: old, New: status})
+	}
 }
@@ -0,0 +1,488 @@
+package raftleader
+
+import (
+	"io"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/hashicorp/raft"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/grafana/pyroscope/pkg/util/health"
+)
+
+func newTestRaft(t *testing.T) *raft.Raft {
+	return newTestRaftWithSnapshots(t, raft.NewInmemSnapshotStore())
+}
+
+func newTestRaftWithSnapshots(t *testing.T, snapshots raft.SnapshotStore) *raft.Raft {
+	config := raft.DefaultConfig()
+	config.LocalID = "node"
+	config.Logger = nil
+	config.HeartbeatTimeout = 50 * time.Millisecond
+	config.ElectionTimeout = 50 * time.Millisecond
+	config.LeaderLeaseTimeout = 50 * time.Millisecond
+	addr, transport := raft.NewInmemTransport("")
+	store := raft.NewInmemStore()
+	servers := raft.Configuration{Servers: []raft.Server{{ID: config.LocalID, Address: addr}}}
+	require.NoError(t, raft.BootstrapCluster(config, store, store, snapshots, transport, servers))
+	r, err := raft.NewRaft(config, new(raft.MockFSM), store, store, snapshots, transport)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = r.Shutdown().Error() })
+	require.Eventually(t, func() bool {
+		return r.State() == raft.Leader
+	}, 5*time.Second, 10*time.Millisecond)
+	return r
+}
+
+func Test_HealthObserver_Subscribe(t *testing.T) {
+	r := newTestRaft(t)
+	hs := NewRaftLeaderHealthObserver(health.NoOpService, log.NewNopLogger(), NewMetrics(nil))
+	c := hs.Subscribe()
+
+	hs.Register(r, "test")
+	require.Equal(t, StatusChange{
+		Service: "test",
+		Old:     grpc_health_v1.HealthCheckResponse_UNKNOWN,
+		New:     grpc_health_v1.HealthCheckResponse_SERVING,
+	}, <-c)
+
+	hs.Deregister(r, "test")
+	require.Equal(t, StatusChange{
+		Service: "test",
+		Old:     grpc_health_v1.HealthCheckResponse_SERVING,
+		New:     grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+	}, <-c)
+	_, ok := <-c
+	require.False(t, ok)
+}
+
+func Test_HealthObserver_SlowSubscriber(t *testing.T) {
+	m := NewMetrics(nil)
+	hs := NewRaftLeaderHealthObserver(health.NoOpService, log.NewNopLogger(), m)
+	slow := hs.Subscribe()
+	for i := 0; i < subscriberBufferSize+2; i++ {
+		hs.notify(StatusChange{Service: "test"})
+	}
+	require.Len(t, slow, subscriberBufferSize)
+	require.Equal(t, float64(2), testutil.ToFloat64(m.droppedChanges))
+}
+
+func Test_HealthObserver_Shutdown(t *testing.T) {
+	r := newTestRaft(t)
+	hs := NewRaftLeaderHealthObserver(health.NoOpService, log.NewNopLogger(), NewMetrics(nil))
+	hs.Register(r, "test")
+	c := hs.Subscribe()
+	hs.Shutdown()
+
+	var changes []StatusChange
+	for change := range c {
+		changes = append(changes, change)
+	}
+	require.Equal(t, []StatusChange{{
+		Service: "test",
+		Old:     grpc_health_v1.HealthCheckResponse_SERVING,
+		New:     grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+	}}, changes)
+
+	_, ok := <-hs.Subscribe()
+	require.False(t, ok)
+}
+
+func Test_HealthObserver_StatsPolling(t *testing.T) {
+	r := newTestRaft(t)
+	m := NewMetrics(nil)
+	hs := NewRaftLeaderHealthObserver(health.NoOpService, log.NewNopLogger(), m)
+	hs.Register(r, "test", WithStatsPolling(10*time.Millisecond))
+	require.NoError(t, r.Apply([]byte("x"), time.Second).Error())
+
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(m.appliedIndex.WithLabelValues("test")) == float64(r.AppliedIndex())
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, float64(r.LastIndex()), testutil.ToFloat64(m.lastLogIndex.WithLabelValues("test")))
+	require.NotZero(t, testutil.ToFloat64(m.commitIndex.WithLabelValues("test")))
+	require.NotZero(t, testutil.ToFloat64(m.term.WithLabelValues("test")))
+
+	hs.Deregister(r, "test")
+	require.Zero(t, testutil.CollectAndCount(m.appliedIndex))
+}
+
+type testHealthService struct {
+	mu      sync.Mutex
+	updates int
+	status  grpc_health_v1.HealthCheckResponse_ServingStatus
+	history []grpc_health_v1.HealthCheckResponse_ServingStatus
+}
+
+func (s *testHealthService) SetServingStatus(_ string, status grpc_health_v1.HealthCheckResponse_ServingStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updates++
+	s.status = status
+	s.history = append(s.history, status)
+}
+
+func (s *testHealthService) statuses() []grpc_health_v1.HealthCheckResponse_ServingStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.history)
+}
+
+func (s *testHealthService) get() (int, grpc_health_v1.HealthCheckResponse_ServingStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.updates, s.status
+}
+
+// observeLeader delivers a leader observation to the service, as if the
+// leadership changed, and waits for the health status to be updated.
+func observeLeader(t *testing.T, hs *HealthObserver, r *raft.Raft, server *testHealthService) {
+	updates, _ := server.get()
+	hs.mu.Lock()
+	svc := hs.registered[serviceKey{raft: r, service: "test"}]
+	hs.mu.Unlock()
+	svc.c <- raft.Observation{Raft: r, Data: raft.LeaderObservation{}}
+	require.Eventually(t, func() bool {
+		n, _ := server.get()
+		return n > updates
+	}, 5*time.Second, time.Millisecond)
+}
+
+func Test_HealthObserver_ForceStatus(t *testing.T) {
+	r := newTestRaft(t)
+	server := new(testHealthService)
+	hs := NewRaftLeaderHealthObserver(server, log.NewNopLogger(), NewMetrics(nil))
+	hs.Register(r, "test")
+	c := hs.Subscribe()
+
+	hs.ForceStatus("test", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	require.Equal(t, StatusChange{
+		Service: "test",
+		Old:     grpc_health_v1.HealthCheckResponse_SERVING,
+		New:     grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+	}, <-c)
+
+	// The node is still the leader: the override holds.
+	for i := 0; i < 3; i++ {
+		observeLeader(t, hs, r, server)
+		_, status := server.get()
+		require.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, status)
+	}
+	require.Empty(t, c)
+
+	hs.ClearForcedStatus("test")
+	require.Equal(t, StatusChange{
+		Service: "test",
+		Old:     grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+		New:     grpc_health_v1.HealthCheckResponse_SERVING,
+	}, <-c)
+	hs.Deregister(r, "test")
+}
+
+func Test_HealthObserver_ForceStatus_LeadershipLost(t *testing.T) {
+	r := newTestRaft(t)
+	server := new(testHealthService)
+	hs := NewRaftLeaderHealthObserver(server, log.NewNopLogger(), NewMetrics(nil))
+	// The override applies to the services registered later.
+	hs.ForceStatus("test", grpc_health_v1.HealthCheckResponse_SERVING)
+	hs.Register(r, "test")
+	c := hs.Subscribe()
+
+	require.NoError(t, r.Shutdown().Error())
+	observeLeader(t, hs, r, server)
+	_, status := server.get()
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, status)
+	require.Empty(t, c)
+
+	hs.ClearForcedStatus("test")
+	require.Equal(t, StatusChange{
+		Service: "test",
+		Old:     grpc_health_v1.HealthCheckResponse_SERVING,
+		New:     grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+	}, <-c)
+	hs.Shutdown()
+}
+
+func Test_HealthObserver_Pause(t *testing.T) {
+	r := newTestRaft(t)
+	server := new(testHealthService)
+	hs := NewRaftLeaderHealthObserver(server, log.NewNopLogger(), NewMetrics(nil))
+	hs.Register(r, "test")
+	c := hs.Subscribe()
+	hs.mu.Lock()
+	svc := hs.registered[serviceKey{raft: r, service: "test"}]
+	hs.mu.Unlock()
+
+	hs.Pause("test")
+	require.NoError(t, r.Shutdown().Error())
+	// The observation buffer holds one observation: once the
+	// last one is accepted, the first two have been processed.
+	for i := 0; i < 3; i++ {
+		svc.c <- raft.Observation{Raft: r, Data: raft.LeaderObservation{}}
+	}
+	hs.ForceStatus("test", grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN)
+	hs.ClearForcedStatus("test")
+	updates, status := server.get()
+	require.Equal(t, 1, updates)
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, status)
+	require.Empty(t, c)
+
+	hs.Resume("test")
+	require.Equal(t, StatusChange{
+		Service: "test",
+		Old:     grpc_health_v1.HealthCheckResponse_SERVING,
+		New:     grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+	}, <-c)
+	hs.Shutdown()
+}
+
+type panickingHealthService struct {
+	panic atomic.Bool
+}
+
+func (s *panickingHealthService) SetServingStatus(string, grpc_health_v1.HealthCheckResponse_ServingStatus) {
+	if s.panic.Load() {
+		panic("health service failure")
+	}
+}
+
+func Test_HealthObserver_DeregisterPanic(t *testing.T) {
+	r := newTestRaft(t)
+	server := new(panickingHealthService)
+	hs := NewRaftLeaderHealthObserver(server, log.NewNopLogger(), NewMetrics(nil))
+	hs.Register(r, "test", WithStatsPolling(time.Hour))
+	hs.mu.Lock()
+	observer := hs.registered[serviceKey{raft: r, service: "test"}].observer
+	hs.mu.Unlock()
+
+	server.panic.Store(true)
+	hs.Deregister(r, "test")
+	require.Empty(t, hs.registered)
+
+	// The leadership is lost on shutdown: only
+	// the registered observers are notified.
+	probe := raft.NewObserver(make(chan raft.Observation, 16), false, nil)
+	r.RegisterObserver(probe)
+	require.NoError(t, r.Shutdown().Error())
+	require.NotZero(t, probe.GetNumObserved())
+	require.Zero(t, observer.GetNumObserved())
+}
+
+type blockingHealthService struct {
+	health.Service
+	block   atomic.Bool
+	release chan struct{}
+}
+
+func (s *blockingHealthService) SetServingStatus(string, grpc_health_v1.HealthCheckResponse_ServingStatus) {
+	if s.block.Load() {
+		<-s.release
+	}
+}
+
+func Test_HealthObserver_DeregisterGracePeriod(t *testing.T) {
+	r := newTestRaft(t)
+	server := &blockingHealthService{release: make(chan struct{})}
+	m := NewMetrics(nil)
+	hs := NewRaftLeaderHealthObserver(server, log.NewNopLogger(), m)
+	hs.Register(r, "test", WithDeregisterGracePeriod(50*time.Millisecond))
+	hs.mu.Lock()
+	svc := hs.registered[serviceKey{raft: r, service: "test"}]
+	hs.mu.Unlock()
+
+	server.block.Store(true)
+	hs.Deregister(r, "test")
+	require.Empty(t, hs.registered)
+	require.Equal(t, float64(1), testutil.ToFloat64(m.deregisterTimeouts))
+
+	// The detached service stops once the health service unblocks.
+	close(server.release)
+	select {
+	case <-svc.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("service is not stopped")
+	}
+}
+
+func Test_HealthObserver_LatestSnapshot(t *testing.T) {
+	snapshots, err := raft.NewFileSnapshotStore(t.TempDir(), 1, io.Discard)
+	require.NoError(t, err)
+	r := newTestRaftWithSnapshots(t, snapshots)
+	hs := NewRaftLeaderHealthObserver(health.NoOpService, log.NewNopLogger(), NewMetrics(nil))
+	hs.Register(r, "stats")
+	hs.Register(r, "store", WithSnapshotStore(snapshots))
+
+	_, ok, err := hs.LatestSnapshot("store")
+	require.NoError(t, err)
+	require.False(t, ok)
+	_, ok, err = hs.LatestSnapshot("unknown")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	before := time.Now().Truncate(time.Millisecond)
+	require.NoError(t, r.Apply([]byte("entry"), time.Second).Error())
+	require.NoError(t, r.Snapshot().Error())
+
+	s, ok, err := hs.LatestSnapshot("store")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NotEmpty(t, s.ID)
+	require.NotZero(t, s.Index)
+	require.NotZero(t, s.Term)
+	require.False(t, s.Time.Before(before))
+
+	stats, ok, err := hs.LatestSnapshot("stats")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, SnapshotMeta{Index: s.Index, Term: s.Term}, stats)
+}
+
+// testRaftState simulates the raft state changes of an election.
+type testRaftState struct {
+	state atomic.Uint32
+}
+
+func (s *testRaftState) get() raft.RaftState  { return raft.RaftState(s.state.Load()) }
+func (s *testRaftState) set(x raft.RaftState) { s.state.Store(uint32(x)) }
+
+func observeLeadership(hs *HealthObserver, r *raft.Raft) {
+	hs.mu.Lock()
+	svc := hs.registered[serviceKey{raft: r, service: "test"}]
+	hs.mu.Unlock()
+	svc.c <- raft.Observation{Raft: r, Data: raft.LeaderObservation{}}
+}
+
+func Test_HealthObserver_DemotionGracePeriod(t *testing.T) {
+	const gracePeriod = 200 * time.Millisecond
+	serving := []grpc_health_v1.HealthCheckResponse_ServingStatus{grpc_health_v1.HealthCheckResponse_SERVING}
+
+	t.Run("leadership regained within the grace period", func(t *testing.T) {
+		r := newTestRaft(t)
+		server := new(testHealthService)
+		m := NewMetrics(nil)
+		hs := NewRaftLeaderHealthObserver(server, log.NewNopLogger(), m)
+		state := new(testRaftState)
+		state.set(raft.Leader)
+		hs.Register(r, "test", WithDemotionGracePeriod(gracePeriod), withRaftState(state.get))
+		require.Equal(t, serving, server.statuses())
+
+		state.set(raft.Follower)
+		observeLeadership(hs, r)
+		// The raft status is reported as is.
+		require.Eventually(t, func() bool {
+			return testutil.ToFloat64(m.status) == float64(raft.Follower)
+		}, 5*time.Second, time.Millisecond)
+		require.Equal(t, serving, server.statuses())
+
+		state.set(raft.Leader)
+		observeLeadership(hs, r)
+		require.Eventually(t, func() bool {
+			return len(server.statuses()) == 2
+		}, 5*time.Second, time.Millisecond)
+		// The pending demotion is canceled.
+		require.Never(t, func() bool {
+			return slices.Contains(server.statuses(), grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+		}, 2*gracePeriod, 10*time.Millisecond)
+		require.Equal(t, float64(raft.Leader), testutil.ToFloat64(m.status))
+		hs.Deregister(r, "test")
+	})
+
+	t.Run("leadership lost", func(t *testing.T) {
+		r := newTestRaft(t)
+		server := new(testHealthService)
+		hs := NewRaftLeaderHealthObserver(server, log.NewNopLogger(), NewMetrics(nil))
+		state := new(testRaftState)
+		state.set(raft.Leader)
+		hs.Register(r, "test", WithDemotionGracePeriod(gracePeriod), withRaftState(state.get))
+		c := hs.Subscribe()
+
+		state.set(raft.Follower)
+		lost := time.Now()
+		observeLeadership(hs, r)
+		require.Equal(t, StatusChange{
+			Service: "test",
+			Old:     grpc_health_v1.HealthCheckResponse_SERVING,
+			New:     grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+		}, <-c)
+		require.GreaterOrEqual(t, time.Since(lost), gracePeriod)
+		require.Equal(t, []grpc_health_v1.HealthCheckResponse_ServingStatus{
+			grpc_health_v1.HealthCheckResponse_SERVING,
+			grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+		}, server.statuses())
+		hs.Deregister(r, "test")
+	})
+
+	t.Run("deregistered while the demotion is pending", func(t *testing.T) {
+		r := newTestRaft(t)
+		server := new(testHealthService)
+		hs := NewRaftLeaderHealthObserver(server, log.NewNopLogger(), NewMetrics(nil))
+		state := new(testRaftState)
+		state.set(raft.Leader)
+		hs.Register(r, "test", WithDemotionGracePeriod(gracePeriod), withRaftState(state.get))
+
+		state.set(raft.Follower)
+		observeLeadership(hs, r)
+		hs.Deregister(r, "test")
+		expected := []grpc_health_v1.HealthCheckResponse_ServingStatus{
+			grpc_health_v1.HealthCheckResponse_SERVING,
+			grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+		}
+		require.Equal(t, expected, server.statuses())
+		// The timer does not fire once the service is stopped.
+		time.Sleep(2 * gracePeriod)
+		require.Equal(t, expected, server.statuses())
+	})
+}
+
+func Test_HealthObserver_ReadServing(t *testing.T) {
+	r := newTestRaft(t)
+	server := new(testHealthService)
+	hs := NewRaftLeaderHealthObserver(server, log.NewNopLogger(), NewMetrics(nil))
+	state := new(testRaftState)
+	state.set(raft.Follower)
+	var known atomic.Bool
+	known.Store(true)
+	hs.Register(r, "test", WithReadServing(), withRaftState(state.get), withKnownLeader(known.Load))
+	c := hs.Subscribe()
+	_, status := server.get()
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, status)
+
+	// The follower does not know the leader.
+	known.Store(false)
+	observeLeadership(hs, r)
+	require.Equal(t, StatusChange{
+		Service: "test",
+		Old:     grpc_health_v1.HealthCheckResponse_SERVING,
+		New:     grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+	}, <-c)
+
+	known.Store(true)
+	state.set(raft.Candidate)
+	observeLeadership(hs, r)
+	state.set(raft.Leader)
+	observeLeadership(hs, r)
+	require.Equal(t, StatusChange{
+		Service: "test",
+		Old:     grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+		New:     grpc_health_v1.HealthCheckResponse_SERVING,
+	}, <-c)
+	hs.Deregister(r, "test")
+
+	// A follower is not serving, unless the service is read-serving.
+	server = new(testHealthService)
+	hs = NewRaftLeaderHealthObserver(server, log.NewNopLogger(), NewMetrics(nil))
+	hs.Register(r, "test", withRaftState(state.get), withKnownLeader(known.Load))
+	state.set(raft.Follower)
+	observeLeadership(hs, r)
+	require.Eventually(t, func() bool {
+		updates, status := server.get()
+		return updates == 2 && status == grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}, 5*time.Second, time.Millisecond)
+	hs.Deregister(r, "test")
+}
@@ -2,8 +2,10 @@ package querybackend
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/grafana/dskit/grpcclient"
@@ -12,6 +14,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/atomic"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -24,13 +27,98 @@ import (
 
 const defaultConcurrencyLimit = 25
 
+const (
+	invalidSampleValuesDrop = "drop"
+	invalidSampleValuesFail = "fail"
+)
+
 type Config struct {
 	Address          string            `yaml:"address"`
 	GRPCClientConfig grpcclient.Config `yaml:"grpc_client_config" doc:"description=Configures the gRPC client used to communicate between the query-frontends and the query-schedulers."`
+
+	AggregationDeadlineReserve float64 `yaml:"aggregation_deadline_reserve"`
+	MaxTreeReports             int64   `yaml:"max_tree_reports"`
+	MaxTreeReportSize          int64   `yaml:"max_tree_report_size"`
+	StreamChunkSize            int64   `yaml:"stream_chunk_size"`
+	TreeStringInterning        bool    `yaml:"tree_string_interning"`
+	ParquetReadAheadSize       int64   `yaml:"parquet_read_ahead_size"`
+	DebugTreeJSON              bool    `yaml:"debug_tree_json"`
+	TreeTraceExport            bool    `yaml:"tree_trace_export"`
+	InvalidSampleValues        string  `yaml:"invalid_sample_values"`
+	MaxResolveDepth            int     `yaml:"max_resolve_depth"`
+	VerifyChecksums            bool    `yaml:"verify_checksums"`
+
+	MaxQueryCPUTime       time.Duration `yaml:"max_query_cpu_time"`
+	MaxSymbolsSectionSize int64         `yaml:"max_symbols_section_size"`
+
+	SectionCacheSize int64         `yaml:"section_cache_size"`
+	SectionCacheTTL  time.Duration `yaml:"section_cache_ttl"`
+
+	MaxConcurrentBlockReads   int `yaml:"max_concurrent_block_reads"`
+	MaxConcurrentAggregations int `yaml:"max_concurrent_aggregations"`
+
+	ReadHeads bool `yaml:"read_heads"`
+
+	// Stack groupings that can be referenced in TreeQuery.StackGrouping.
+	StackGroupings []StackGroupingConfig `yaml:"stack_groupings"`
 }
 
 func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.StringVar(&cfg.Address, "query-backend.address", "localhost:9095", "")
+	f.Float64Var(&cfg.AggregationDeadlineReserve, "query-backend.aggregation-deadline-reserve", 0,
+		"Fraction of the query deadline reserved for aggregation of the results. "+
+			"Once the rest of the time is exhausted, pending sub-queries are canceled and the "+
+			"results received so far are aggregated. 0 to disable.")
+	f.Int64Var(&cfg.MaxTreeReports, "query-backend.max-tree-reports", 0,
+		"Maximum number of tree reports a single aggregator accepts, unless specified in the request. "+
+			"The query fails once the limit is exceeded. 0 to disable.")
+	f.Int64Var(&cfg.MaxTreeReportSize, "query-backend.max-tree-report-size", 0,
+		"Maximum size in bytes of the serialized tree of a tree report, unless specified in the request. "+
+			"Larger trees are truncated to fewer nodes until they fit. 0 to disable.")
+	f.Int64Var(&cfg.StreamChunkSize, "query-backend.stream-chunk-size", 0,
+		"Approximate maximum size in bytes of a chunk of the sub-query responses, unless specified in the request. "+
+			"If set, the responses are streamed in chunks and aggregated as they are received; the large trees are "+
+			"split into parts. 0 to receive the responses as a whole.")
+	f.BoolVar(&cfg.TreeStringInterning, "query-backend.tree-string-interning", false,
+		"Interns the node names of the tree reports as they are aggregated, unless specified in the request. "+
+			"Reduces the heap of the aggregations of many trees with the same functions, but the reports are decoded one at a time.")
+	f.Int64Var(&cfg.ParquetReadAheadSize, "query-backend.parquet-read-ahead-size", 0,
+		"Maximum size in bytes of the profile table column chunks prefetched per dataset, while the "+
+			"preceding row group is being processed. Larger chunks are not prefetched. 0 to disable.")
+	f.BoolVar(&cfg.DebugTreeJSON, "query-backend.debug-tree-json", false,
+		"Enables the debug endpoint that returns the result of a tree query as indented JSON.")
+	f.BoolVar(&cfg.TreeTraceExport, "query-backend.tree-trace-export", false,
+		"Enables the endpoint that returns the result of a tree query in the Chrome trace event format.")
+	f.StringVar(&cfg.InvalidSampleValues, "query-backend.invalid-sample-values", invalidSampleValuesDrop,
+		"Specifies how to handle the sample values that can't be represented in a tree, e.g. produced by "+
+			"malformed profiles: 'drop' excludes the samples from the result, 'fail' fails the query.")
+	f.IntVar(&cfg.MaxResolveDepth, "query-backend.max-resolve-depth", 8192,
+		"Maximum depth of the stack traces resolved into a tree; deeper stack traces are truncated. "+
+			"The limit protects the query backend from degenerate profiles. 0 to disable.")
+	f.BoolVar(&cfg.VerifyChecksums, "query-backend.verify-checksums", false,
+		"Verifies the checksums of the dataset sections read by the queries, and fails a query if the data is corrupted. "+
+			"The sections are read entirely, therefore the verification is expensive. The requests may enable it explicitly.")
+	f.DurationVar(&cfg.MaxQueryCPUTime, "query-backend.max-query-cpu-time", 0,
+		"Maximum CPU time a query may consume resolving trees on a query backend instance; the query fails "+
+			"once the budget is exhausted. The CPU time is approximated by the processing time. 0 to disable.")
+	f.Int64Var(&cfg.MaxSymbolsSectionSize, "query-backend.max-symbols-section-size", 0,
+		"Maximum size in bytes of the symbols section of a dataset resolved by a tree query. The stack traces "+
+			"of larger datasets are only resolved into the location addresses. 0 to disable.")
+	f.Int64Var(&cfg.SectionCacheSize, "query-backend.section-cache-size", 0,
+		"Maximum size in bytes of the sections of the pinned blocks held in memory; the queries pin the blocks "+
+			"with the pin_blocks invoke option. "+
+			"The least recently used sections are evicted once the limit is reached. 0 to disable.")
+	f.DurationVar(&cfg.SectionCacheTTL, "query-backend.section-cache-ttl", 10*time.Minute,
+		"Period of time after which a block pin expires, unless renewed. 0 means that pins never expire.")
+	f.IntVar(&cfg.MaxConcurrentBlockReads, "query-backend.max-concurrent-block-reads", 0,
+		"Maximum number of the dataset queries a query backend instance executes concurrently. "+
+			"The queries are mostly bound by the object storage bandwidth. 0 to disable.")
+	f.IntVar(&cfg.MaxConcurrentAggregations, "query-backend.max-concurrent-aggregations", 0,
+		"Maximum number of the query reports a query backend instance aggregates concurrently. "+
+			"The aggregation is mostly bound by CPU. 0 to disable.")
+	f.BoolVar(&cfg.ReadHeads, "query-backend.read-heads", false,
+		"Query the in-memory heads of the segment writers along with the blocks, so that the data is visible "+
+			"before the segments are flushed. The heads are snapshotted and transferred for each query that requests them.")
 	cfg.GRPCClientConfig.RegisterFlagsWithPrefix("query-backend.grpc-client-config", f)
 }
 
@@ -38,6 +126,51 @@ func (cfg *Config) Validate() error {
 	if cfg.Address == "" {
 		return fmt.Errorf("query-backend.address is required")
 	}
+	if cfg.AggregationDeadlineReserve < 0 || cfg.AggregationDeadlineReserve >= 1 {
+		return fmt.Errorf("query-backend.aggregation-deadline-reserve must be in [0, 1)")
+	}
+	if cfg.MaxTreeReports < 0 {
+		return fmt.Errorf("query-backend.max-tree-reports must be non-negative")
+	}
+	if cfg.MaxTreeReportSize < 0 {
+		return fmt.Errorf("query-backend.max-tree-report-size must be non-negative")
+	}
+	if cfg.StreamChunkSize < 0 {
+		return fmt.Errorf("query-backend.stream-chunk-size must be non-negative")
+	}
+	if cfg.ParquetReadAheadSize < 0 {
+		return fmt.Errorf("query-backend.parquet-read-ahead-size must be non-negative")
+	}
+	switch cfg.InvalidSampleValues {
+	case "", invalidSampleValuesDrop, invalidSampleValuesFail:
+	default:
+		return fmt.Errorf("query-backend.invalid-sample-values must be one of: %s, %s",
+			invalidSampleValuesDrop, invalidSampleValuesFail)
+	}
+	if cfg.MaxResolveDepth < 0 {
+		return fmt.Errorf("query-backend.max-resolve-depth must be non-negative")
+	}
+	if _, err := compileStackGroupings(cfg.StackGroupings); err != nil {
+		return fmt.Errorf("invalid stack groupings: %w", err)
+	}
+	if cfg.MaxQueryCPUTime < 0 {
+		return fmt.Errorf("query-backend.max-query-cpu-time must be non-negative")
+	}
+	if cfg.MaxSymbolsSectionSize < 0 {
+		return fmt.Errorf("query-backend.max-symbols-section-size must be non-negative")
+	}
+	if cfg.SectionCacheSize < 0 {
+		return fmt.Errorf("query-backend.section-cache-size must be non-negative")
+	}
+	if cfg.SectionCacheTTL < 0 {
+		return fmt.Errorf("query-backend.section-cache-ttl must be non-negative")
+	}
+	if cfg.MaxConcurrentBlockReads < 0 {
+		return fmt.Errorf("query-backend.max-concurrent-block-reads must be non-negative")
+	}
+	if cfg.MaxConcurrentAggregations < 0 {
+		return fmt.Errorf("query-backend.max-concurrent-aggregations must be non-negative")
+	}
 	return cfg.GRPCClientConfig.Validate()
 }
 
@@ -45,16 +178,24 @@ type QueryHandler interface {
 	Invoke(context.Context, *querybackendv1.InvokeRequest) (*querybackendv1.InvokeResponse, error)
 }
 
+// MetadataClient looks up the blocks of the queries that list them explicitly.
+type MetadataClient interface {
+	QueryMetadata(context.Context, *metastorev1.QueryMetadataRequest, ...grpc.CallOption) (*metastorev1.QueryMetadataResponse, error)
+}
+
 type QueryBackend struct {
 	service services.Service
 	querybackendv1.QueryBackendServiceServer
 
-	config Config
-	logger log.Logger
-	reg    prometheus.Registerer
+	config  Config
+	logger  log.Logger
+	reg     prometheus.Registerer
+	metrics *metrics
 
-	backendClient QueryHandler
-	blockReader   QueryHandler
+	backendClient  QueryHandler
+	blockReader    QueryHandler
+	metadataClient MetadataClient
+	aggregations   *concurrencyLimit
 
 	concurrency uint32
 	running     atomic.Uint32
@@ -66,16 +207,27 @@ func New(
 	reg prometheus.Registerer,
 	backendClient QueryHandler,
 	blockReader QueryHandler,
+	metadataClient MetadataClient,
 ) (*QueryBackend, error) {
+	m := newMetrics(reg)
 	q := QueryBackend{
-		config:        config,
-		logger:        logger,
-		reg:           reg,
-		backendClient: backendClient,
-		blockReader:   blockReader,
+		config:         config,
+		logger:         logger,
+		reg:            reg,
+		metrics:        m,
+		backendClient:  backendClient,
+		blockReader:    blockReader,
+		metadataClient: metadataClient,
 
 		concurrency: defaultConcurrencyLimit,
 	}
+	// The aggregations of the remote responses and of the local reads
+	// are CPU bound alike: the limit is shared with the block reader.
+	if r, ok := blockReader.(*BlockReader); ok {
+		q.aggregations = r.aggregations
+	} else {
+		q.aggregations = newConcurrencyLimit(config.MaxConcurrentAggregations, m.aggregationsInProgress)
+	}
 	q.service = services.NewIdleService(q.starting, q.stopping)
 	return &q, nil
 }
@@ -90,56 +242,241 @@ func (q *QueryBackend) Invoke(
 ) (*querybackendv1.InvokeResponse, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "QueryBackend.Invoke")
 	defer span.Finish()
+	withQueryID(span, req)
+	var resp *querybackendv1.InvokeResponse
+	err := q.invoke(ctx, req, false, func(r *querybackendv1.InvokeResponse) error {
+		resp = r
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// invoke executes the query, and calls the function for the response.
+// If chunked, the function is called for each of the chunks of the
+// response, of the size specified in the request options.
+func (q *QueryBackend) invoke(
+	ctx context.Context,
+	req *querybackendv1.InvokeRequest,
+	chunked bool,
+	fn func(*querybackendv1.InvokeResponse) error,
+) error {
+	if req.Options.GetMaxTreeReports() == 0 && q.config.MaxTreeReports > 0 {
+		if req.Options == nil {
+			req.Options = new(querybackendv1.InvokeOptions)
+		}
+		req.Options.MaxTreeReports = q.config.MaxTreeReports
+	}
+	if req.Options.GetMaxTreeReportSize() == 0 && q.config.MaxTreeReportSize > 0 {
+		if req.Options == nil {
+			req.Options = new(querybackendv1.InvokeOptions)
+		}
+		req.Options.MaxTreeReportSize = q.config.MaxTreeReportSize
+	}
+	if req.Options.GetStreamChunkSize() == 0 && q.config.StreamChunkSize > 0 {
+		if req.Options == nil {
+			req.Options = new(querybackendv1.InvokeOptions)
+		}
+		req.Options.StreamChunkSize = q.config.StreamChunkSize
+	}
+	if !req.Options.GetTreeStringInterning() && q.config.TreeStringInterning {
+		if req.Options == nil {
+			req.Options = new(querybackendv1.InvokeOptions)
+		}
+		req.Options.TreeStringInterning = true
+	}
+	if len(req.BlockIds) > 0 {
+		if err := q.planBlocks(ctx, req); err != nil {
+			return err
+		}
+	}
+	var chunkSize int64
+	if chunked {
+		chunkSize = req.Options.GetStreamChunkSize()
+	}
+	if !req.Options.GetPartial() {
+		// Only the final reports are accounted: the
+		// ones of the sub-queries are partial.
+		send := fn
+		fn = func(resp *querybackendv1.InvokeResponse) error {
+			q.metrics.observeTreeReportCapping(resp)
+			return send(resp)
+		}
+	}
 
 	p := queryplan.Open(req.QueryPlan)
 	switch r := p.Root(); r.Type {
 	case queryplan.NodeMerge:
-		return q.merge(ctx, req, r.Children())
+		return q.merge(ctx, req, r.Children(), chunkSize, fn)
 	case queryplan.NodeRead:
-		return q.withThrottling(func() (*querybackendv1.InvokeResponse, error) {
-			return q.read(ctx, req, r.Blocks())
+		return q.withThrottling(func() error {
+			return q.read(ctx, req, r.Blocks(), chunkSize, fn)
 		})
 	default:
 		panic("query plan: unknown node type")
 	}
 }
 
+// planBlocks replaces the block IDs of the request with the query plan
+// of the blocks, and makes the query fail if any of them can't be read.
+func (q *QueryBackend) planBlocks(ctx context.Context, req *querybackendv1.InvokeRequest) error {
+	if len(req.QueryPlan.GetBlocks()) > 0 {
+		return status.Error(codes.InvalidArgument, "block IDs can't be specified along with the query plan")
+	}
+	if q.metadataClient == nil {
+		return status.Error(codes.Unimplemented, "block IDs are not supported")
+	}
+	resp, err := q.metadataClient.QueryMetadata(ctx, &metastorev1.QueryMetadataRequest{
+		TenantId:  req.Tenant,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+		Query:     req.LabelSelector,
+		BlockIds:  req.BlockIds,
+	})
+	if err != nil {
+		return err
+	}
+	if req.StartTime == 0 && req.EndTime == 0 {
+		req.StartTime, req.EndTime = blocksTimeRange(resp.Blocks)
+	}
+	if req.Options == nil {
+		req.Options = new(querybackendv1.InvokeOptions)
+	}
+	req.Options.FailOnSkippedBlocks = true
+	// TODO: Params.
+	req.QueryPlan = queryplan.Build(resp.Blocks, 2, 10).Proto()
+	req.BlockIds = nil
+	return nil
+}
+
+// blocksTimeRange returns the time range covering all the blocks.
+func blocksTimeRange(blocks []*metastorev1.BlockMeta) (startTime, endTime int64) {
+	if len(blocks) == 0 {
+		return 0, 0
+	}
+	startTime, endTime = blocks[0].MinTime, blocks[0].MaxTime
+	for _, b := range blocks[1:] {
+		startTime = min(startTime, b.MinTime)
+		endTime = max(endTime, b.MaxTime)
+	}
+	return startTime, endTime
+}
+
 func (q *QueryBackend) merge(
 	ctx context.Context,
 	request *querybackendv1.InvokeRequest,
 	children iter.Iterator[*queryplan.Node],
-) (*querybackendv1.InvokeResponse, error) {
+	chunkSize int64,
+	fn func(*querybackendv1.InvokeResponse) error,
+) error {
+	// The sub-query requests are cloned without the plan, while the
+	// aggregator retains it: the plan tells the datasets expected.
+	plan := request.QueryPlan
 	request.QueryPlan = nil
-	m := newAggregator(request)
-	g, ctx := errgroup.WithContext(ctx)
-	for children.Next() {
-		req := request.CloneVT()
+	template := request.CloneVT()
+	request.QueryPlan = plan
+	if template.Options == nil {
+		template.Options = new(querybackendv1.InvokeOptions)
+	}
+	template.Options.Partial = true
+	if template.Options.ReadHeads && len(template.Options.PlanBlockIds) == 0 {
+		for _, b := range plan.GetBlocks() {
+			template.Options.PlanBlockIds = append(template.Options.PlanBlockIds, b.Id)
+		}
+	}
+	m := newAggregator(q.logger, request)
+	fanout, cancel := q.fanoutContext(ctx)
+	defer cancel()
+	g, gctx := errgroup.WithContext(fanout)
+	for i := 0; children.Next(); i++ {
+		req := template.CloneVT()
 		req.QueryPlan = children.At().Plan().Proto()
+		if i > 0 {
+			// The heads are read by the first sub-query only.
+			req.Options.ReadHeads = false
+		}
+		stripTreeBaseline(req)
+		stripCallGraphMaxNodes(req)
 		g.Go(util.RecoverPanic(func() error {
+			// The sub-queries are not limited: the blocks are read by
+			// the backends the sub-queries are dispatched to. The response
+			// is aggregated even if the fan-out deadline is exceeded.
+			var aggregated bool
+			var aggErr error
+			aggregate := func(resp *querybackendv1.InvokeResponse) error {
+				aggregated = true
+				aggErr = q.aggregations.run(ctx, func() error { return m.aggregateResponse(resp, nil) })
+				if errors.Is(aggErr, errTooManyTreeReports) {
+					q.metrics.treeReportsLimitExceeded.Inc()
+				}
+				return aggErr
+			}
 			// TODO: Speculative retry.
-			return m.aggregateResponse(q.backendClient.Invoke(ctx, req))
+			err := q.invokeSubQuery(gctx, req, aggregate)
+			if err == nil || aggErr != nil {
+				return err
+			}
+			// The blocks can't be skipped, if a part of
+			// the streamed response has been aggregated.
+			if !aggregated && fanout.Err() != nil && ctx.Err() == nil && !req.Options.GetFailOnSkippedBlocks() {
+				// The fan-out deadline is exceeded: the rest
+				// of the time is reserved for the aggregation.
+				for _, b := range req.QueryPlan.Blocks {
+					m.skipBlock(b.Id, querybackendv1.SkipReason_SKIP_REASON_DEADLINE_EXCEEDED, fanout.Err().Error())
+				}
+				return nil
+			}
+			return err
 		}))
 	}
 	if err := g.Wait(); err != nil {
-		return nil, err
+		return err
 	}
-	return m.response()
+	return m.responseChunks(chunkSize, fn)
+}
+
+// fanoutContext returns the context for the sub-queries. If the query
+// has a deadline, a fraction of the time left is reserved for the
+// aggregation of the results.
+func (q *QueryBackend) fanoutContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok || q.config.AggregationDeadlineReserve <= 0 {
+		return context.WithCancel(ctx)
+	}
+	reserve := time.Duration(float64(time.Until(deadline)) * q.config.AggregationDeadlineReserve)
+	return context.WithDeadline(ctx, deadline.Add(-reserve))
 }
 
 func (q *QueryBackend) read(
 	ctx context.Context,
 	request *querybackendv1.InvokeRequest,
 	blocks iter.Iterator[*metastorev1.BlockMeta],
-) (*querybackendv1.InvokeResponse, error) {
+	chunkSize int64,
+	fn func(*querybackendv1.InvokeResponse) error,
+) error {
 	request.QueryPlan = &querybackendv1.QueryPlan{
 		Blocks: iter.MustSlice(blocks),
 	}
-	return q.blockReader.Invoke(ctx, request)
+	if h, ok := q.blockReader.(ChunkedQueryHandler); ok && chunkSize > 0 {
+		return h.InvokeChunks(ctx, request, fn)
+	}
+	resp, err := q.blockReader.Invoke(ctx, request)
+	if err != nil {
+		return err
+	}
+	for _, chunk := range splitResponse(resp, chunkSize) {
+		if err = fn(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (q *QueryBackend) withThrottling(fn func() (*querybackendv1.InvokeResponse, error)) (*querybackendv1.InvokeResponse, error) {
+func (q *QueryBackend) withThrottling(fn func() error) error {
 	if q.running.Inc() > q.concurrency {
-		return nil, status.Error(codes.ResourceExhausted, "all minions are busy, please try later")
+		return status.Error(codes.ResourceExhausted, "all minions are busy, please try later")
 	}
 	defer q.running.Dec()
 	return fn()
@@ -0,0 +1,357 @@
+package querybackend
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	metastorev1 "github.com/grafana/pyroscope/api/gen/proto/go/metastore/v1"
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend/queryplan"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+// testBlockReader responds to read requests with a single tree
+// report per block; blocks listed in slow are never resolved
+// before the context is canceled.
+type testBlockReader struct {
+	slow map[string]struct{}
+}
+
+func (r *testBlockReader) Invoke(ctx context.Context, req *querybackendv1.InvokeRequest) (*querybackendv1.InvokeResponse, error) {
+	resp := new(querybackendv1.InvokeResponse)
+	for _, b := range req.QueryPlan.Blocks {
+		if _, ok := r.slow[b.Id]; ok {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		tree := new(model.Tree)
+		tree.InsertStack(1, "main", b.Id)
+		resp.Reports = append(resp.Reports, &querybackendv1.Report{
+			ReportType: querybackendv1.ReportType_REPORT_TREE,
+			Tree: &querybackendv1.TreeReport{
+				Query: &querybackendv1.TreeQuery{},
+				Tree:  tree.Bytes(-1),
+			},
+		})
+	}
+	return resp, nil
+}
+
+func newTestQueryBackend(t *testing.T, config Config, reader QueryHandler) *QueryBackend {
+	b, err := New(config, log.NewNopLogger(), nil, nil, reader, nil)
+	require.NoError(t, err)
+	// Sub-queries are handled by the same backend.
+	b.backendClient = b
+	return b
+}
+
+func newTestTreeRequest(blocks ...string) *querybackendv1.InvokeRequest {
+	metas := make([]*metastorev1.BlockMeta, len(blocks))
+	for i, b := range blocks {
+		metas[i] = &metastorev1.BlockMeta{Id: b}
+	}
+	return &querybackendv1.InvokeRequest{
+		Query: []*querybackendv1.Query{{
+			QueryType: querybackendv1.QueryType_QUERY_TREE,
+			Tree:      &querybackendv1.TreeQuery{},
+		}},
+		QueryPlan: queryplan.Build(metas, 1, 8).Proto(),
+	}
+}
+
+func Test_QueryBackend_AggregationDeadlineReserve(t *testing.T) {
+	reader := &testBlockReader{slow: map[string]struct{}{"c": {}}}
+
+	t.Run("sub-queries are canceled before the deadline", func(t *testing.T) {
+		b := newTestQueryBackend(t, Config{AggregationDeadlineReserve: 0.5}, reader)
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+		resp, err := b.Invoke(ctx, newTestTreeRequest("a", "b", "c"))
+		require.NoError(t, err)
+		require.NoError(t, ctx.Err())
+		require.Len(t, resp.Reports, 1)
+
+		expected := new(model.Tree)
+		expected.InsertStack(1, "main", "a")
+		expected.InsertStack(1, "main", "b")
+		actual := model.MustUnmarshalTree(resp.Reports[0].Tree.Tree)
+		require.Equal(t, expected.String(), actual.String())
+
+		require.EqualValues(t, 1, resp.Diagnostics.GetSkippedBlocksCount())
+		require.Len(t, resp.Diagnostics.SkippedBlocks, 1)
+		skipped := resp.Diagnostics.SkippedBlocks[0]
+		require.Equal(t, "c", skipped.BlockId)
+		require.Equal(t, querybackendv1.SkipReason_SKIP_REASON_DEADLINE_EXCEEDED, skipped.Reason)
+	})
+
+	t.Run("no time is reserved by default", func(t *testing.T) {
+		b := newTestQueryBackend(t, Config{}, reader)
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		_, err := b.Invoke(ctx, newTestTreeRequest("a", "b", "c"))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func Test_ReportAggregator_SkippedBlocks(t *testing.T) {
+	partial := func(n uint64, ids ...string) *querybackendv1.InvokeResponse {
+		d := &querybackendv1.Diagnostics{SkippedBlocksCount: n}
+		for _, id := range ids {
+			d.SkippedBlocks = append(d.SkippedBlocks, &querybackendv1.SkippedBlock{
+				BlockId: id,
+				Reason:  querybackendv1.SkipReason_SKIP_REASON_SECTION_MISSING,
+			})
+		}
+		return &querybackendv1.InvokeResponse{Diagnostics: d}
+	}
+
+	m := newAggregator(log.NewNopLogger(), new(querybackendv1.InvokeRequest))
+	require.NoError(t, m.aggregateResponse(&querybackendv1.InvokeResponse{}, nil))
+	resp, err := m.response()
+	require.NoError(t, err)
+	require.Nil(t, resp.Diagnostics)
+
+	m.skipBlock("c", querybackendv1.SkipReason_SKIP_REASON_DEADLINE_EXCEEDED, "")
+	require.NoError(t, m.aggregateResponse(partial(2, "b", "a"), nil))
+	// Blocks skipped repeatedly are only counted once.
+	require.NoError(t, m.aggregateResponse(partial(1, "a"), nil))
+	// Blocks not listed in the partial response are counted.
+	require.NoError(t, m.aggregateResponse(partial(10), nil))
+	resp, err = m.response()
+	require.NoError(t, err)
+	require.EqualValues(t, 13, resp.Diagnostics.SkippedBlocksCount)
+	ids := make([]string, len(resp.Diagnostics.SkippedBlocks))
+	for i, b := range resp.Diagnostics.SkippedBlocks {
+		ids[i] = b.BlockId
+	}
+	require.Equal(t, []string{"a", "b", "c"}, ids)
+}
+
+func Test_ReportAggregator_BatchConcurrent(t *testing.T) {
+	const queries = 16
+	req := &querybackendv1.InvokeRequest{Options: &querybackendv1.InvokeOptions{Batch: true}}
+	for i := 0; i < queries; i++ {
+		req.Query = append(req.Query, &querybackendv1.Query{
+			QueryType:  querybackendv1.QueryType_QUERY_LABEL_NAMES,
+			LabelNames: &querybackendv1.LabelNamesQuery{},
+		})
+	}
+	report := func(i int, name string) *querybackendv1.Report {
+		return &querybackendv1.Report{
+			ReportType: querybackendv1.ReportType_REPORT_LABEL_NAMES,
+			QueryIndex: uint32(i),
+			LabelNames: &querybackendv1.LabelNamesReport{
+				Query:      &querybackendv1.LabelNamesQuery{},
+				LabelNames: []string{name},
+			},
+		}
+	}
+
+	m := newAggregator(log.NewNopLogger(), req)
+	// The aggregators of the queries are created concurrently.
+	var wg sync.WaitGroup
+	for i := 0; i < queries; i++ {
+		for _, name := range []string{"a", "b"} {
+			wg.Add(1)
+			go func(r *querybackendv1.Report) {
+				defer wg.Done()
+				require.NoError(t, m.aggregateReport(r))
+			}(report(i, name))
+		}
+	}
+	wg.Wait()
+
+	resp, err := m.response()
+	require.NoError(t, err)
+	require.Len(t, resp.Reports, queries)
+	for i, r := range resp.Reports {
+		require.EqualValues(t, i, r.QueryIndex)
+		require.Equal(t, []string{"a", "b"}, r.LabelNames.LabelNames)
+	}
+}
+
+type failingTestAggregator struct{ err error }
+
+func (a *failingTestAggregator) aggregate(*querybackendv1.Report) error { return nil }
+func (a *failingTestAggregator) build() *querybackendv1.Report          { return nil }
+func (a *failingTestAggregator) buildError() error                      { return a.err }
+
+func Test_ReportAggregator_BuildError(t *testing.T) {
+	m := newAggregator(log.NewNopLogger(), new(querybackendv1.InvokeRequest))
+	k := reportKey{reportType: querybackendv1.ReportType_REPORT_TREE}
+	m.aggregators[k] = new(failingTestAggregator)
+	// The report of a faulty aggregator is empty.
+	resp, err := m.response()
+	require.NoError(t, err)
+	require.Len(t, resp.Reports, 1)
+
+	// The response fails, if the aggregator reports the error.
+	buildErr := errors.New("build error")
+	m.aggregators[k] = &failingTestAggregator{err: buildErr}
+	_, err = m.response()
+	require.ErrorIs(t, err, buildErr)
+}
+
+func Test_QueryBackend_MaxTreeReports(t *testing.T) {
+	reader := new(testBlockReader)
+
+	t.Run("limit exceeded", func(t *testing.T) {
+		b := newTestQueryBackend(t, Config{MaxTreeReports: 2}, reader)
+		_, err := b.Invoke(context.Background(), newTestTreeRequest("a", "b", "c"))
+		require.ErrorIs(t, err, errTooManyTreeReports)
+		require.Equal(t, float64(1), testutil.ToFloat64(b.metrics.treeReportsLimitExceeded))
+	})
+
+	t.Run("request limit takes precedence", func(t *testing.T) {
+		b := newTestQueryBackend(t, Config{MaxTreeReports: 2}, reader)
+		req := newTestTreeRequest("a", "b", "c")
+		req.Options = &querybackendv1.InvokeOptions{MaxTreeReports: 3}
+		resp, err := b.Invoke(context.Background(), req)
+		require.NoError(t, err)
+		require.Len(t, resp.Reports, 1)
+		require.Zero(t, testutil.ToFloat64(b.metrics.treeReportsLimitExceeded))
+	})
+
+	t.Run("no limit by default", func(t *testing.T) {
+		b := newTestQueryBackend(t, Config{}, reader)
+		resp, err := b.Invoke(context.Background(), newTestTreeRequest("a", "b", "c"))
+		require.NoError(t, err)
+		require.Len(t, resp.Reports, 1)
+	})
+}
+
+type testMetadataClient struct {
+	blocks []*metastorev1.BlockMeta
+	req    *metastorev1.QueryMetadataRequest
+}
+
+func (c *testMetadataClient) QueryMetadata(
+	_ context.Context,
+	req *metastorev1.QueryMetadataRequest,
+	_ ...grpc.CallOption,
+) (*metastorev1.QueryMetadataResponse, error) {
+	c.req = req
+	var resp metastorev1.QueryMetadataResponse
+	for _, id := range req.BlockIds {
+		for _, b := range c.blocks {
+			if b.Id == id {
+				resp.Blocks = append(resp.Blocks, b)
+			}
+		}
+	}
+	if len(resp.Blocks) != len(req.BlockIds) {
+		return nil, status.Error(codes.NotFound, "blocks not found")
+	}
+	return &resp, nil
+}
+
+func Test_QueryBackend_BlockIDs(t *testing.T) {
+	metadata := &testMetadataClient{blocks: []*metastorev1.BlockMeta{
+		{Id: "a", MinTime: 20, MaxTime: 30},
+		{Id: "b", MinTime: 10, MaxTime: 15},
+		{Id: "c", MinTime: 25, MaxTime: 40},
+	}}
+	b := newTestQueryBackend(t, Config{}, new(testBlockReader))
+	b.metadataClient = metadata
+
+	req := newTestTreeRequest()
+	req.QueryPlan = nil
+	req.Tenant = []string{"tenant"}
+	req.BlockIds = []string{"a", "b"}
+	resp, err := b.Invoke(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, []string{"tenant"}, metadata.req.TenantId)
+	// The time range of the blocks is queried.
+	require.Equal(t, int64(10), req.StartTime)
+	require.Equal(t, int64(30), req.EndTime)
+	require.True(t, req.Options.GetFailOnSkippedBlocks())
+
+	actual := new(model.Tree)
+	for _, r := range resp.Reports {
+		actual.Merge(model.MustUnmarshalTree(r.Tree.Tree))
+	}
+	expected := new(model.Tree)
+	expected.InsertStack(1, "main", "a")
+	expected.InsertStack(1, "main", "b")
+	require.Equal(t, expected.String(), actual.String())
+
+	req = newTestTreeRequest()
+	req.QueryPlan = nil
+	req.BlockIds = []string{"a", "d"}
+	_, err = b.Invoke(context.Background(), req)
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	req = newTestTreeRequest("a")
+	req.BlockIds = []string{"a"}
+	_, err = b.Invoke(context.Background(), req)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// headsBlockReader records the options of
+// the read requests that read the heads.
+type headsBlockReader struct {
+	testBlockReader
+	mu    sync.Mutex
+	heads []*querybackendv1.InvokeOptions
+}
+
+func (r *headsBlockReader) Invoke(ctx context.Context, req *querybackendv1.InvokeRequest) (*querybackendv1.InvokeResponse, error) {
+	if req.Options.GetReadHeads() {
+		r.mu.Lock()
+		r.heads = append(r.heads, req.Options)
+		r.mu.Unlock()
+	}
+	return r.testBlockReader.Invoke(ctx, req)
+}
+
+func Test_QueryBackend_ReadHeads(t *testing.T) {
+	reader := new(headsBlockReader)
+	b := newTestQueryBackend(t, Config{}, reader)
+	req := newTestTreeRequest("a", "b", "c")
+	req.Options = &querybackendv1.InvokeOptions{ReadHeads: true}
+	_, err := b.Invoke(context.Background(), req)
+	require.NoError(t, err)
+	// The heads are read once, skipping the
+	// ones flushed to any of the planned blocks.
+	require.Len(t, reader.heads, 1)
+	require.ElementsMatch(t, []string{"a", "b", "c"}, reader.heads[0].PlanBlockIds)
+}
+
+func Test_QueryBackend_ColdPathsMaxNodes(t *testing.T) {
+	b := newTestQueryBackend(t, Config{}, new(testBlockReader))
+	baseline := new(model.Tree)
+	baseline.InsertStack(40, "main", "w")
+	baseline.InsertStack(30, "main", "x")
+	baseline.InsertStack(20, "main", "y")
+	baseline.InsertStack(10, "main", "z")
+	req := newTestTreeRequest("a", "b", "c")
+	req.Query[0].Tree = &querybackendv1.TreeQuery{
+		MaxNodes: 3,
+		Baseline: &querybackendv1.TreeBaseline{Tree: baseline.Bytes(-1), ColdPaths: true},
+	}
+	resp, err := b.Invoke(context.Background(), req)
+	require.NoError(t, err)
+	require.Len(t, resp.Reports, 1)
+	r := resp.Reports[0].Tree
+	// The merged tree is compared to the baseline,
+	// and then truncated to the max nodes.
+	require.True(t, r.Truncated)
+	require.EqualValues(t, 5, r.Nodes)
+	expected := `.
+└── main: self 0 total 100
+    ├── other: self 30 total 30
+    ├── w: self 40 total 40
+    └── x: self 30 total 30
+`
+	require.Equal(t, expected, model.MustUnmarshalTree(r.Tree).String())
+}
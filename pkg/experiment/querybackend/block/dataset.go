@@ -2,10 +2,13 @@ package block
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/grafana/dskit/multierror"
 	"github.com/parquet-go/parquet-go"
+	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/sync/errgroup"
 
 	metastorev1 "github.com/grafana/pyroscope/api/gen/proto/go/metastore/v1"
@@ -30,7 +33,13 @@ type Dataset struct {
 	symbols  *symdb.Reader
 	profiles *ParquetFile
 
+	customMutex sync.Mutex
+	custom      map[Section]any
+
 	memSize int
+
+	verifyChecksums  bool
+	checksumFailures *prometheus.CounterVec
 }
 
 func NewDataset(meta *metastorev1.Dataset, obj *Object) *Dataset {
@@ -90,6 +99,9 @@ func (s *Dataset) open(ctx context.Context, sections ...Section) (err error) {
 			if openErr := sc.open(ctx, s); openErr != nil {
 				return fmt.Errorf("openning section %v: %w", s.sectionName(sc), openErr)
 			}
+			if s.verifyChecksums {
+				return s.verifySection(ctx, sc)
+			}
 			return nil
 		}))
 	}
@@ -125,6 +137,7 @@ func (s *Dataset) closeErr(err error) error {
 	if s.profiles != nil {
 		merr.Add(s.profiles.Close())
 	}
+	s.closeCustomSections(&merr)
 	if s.obj != nil {
 		merr.Add(s.obj.CloseWithError(err))
 	}
@@ -133,14 +146,43 @@ func (s *Dataset) closeErr(err error) error {
 
 func (s *Dataset) Meta() *metastorev1.Dataset { return s.meta }
 
+func (s *Dataset) Object() *Object { return s.obj }
+
 func (s *Dataset) Profiles() *ParquetFile { return s.profiles }
 
 func (s *Dataset) ProfileRowReader() parquet.RowReader { return s.profiles.RowReader() }
 
 func (s *Dataset) Symbols() symdb.SymbolsReader { return s.symbols }
 
+// SymbolsFormatVersion returns the format version of the symbols
+// section, or zero, if the section has not been opened.
+func (s *Dataset) SymbolsFormatVersion() symdb.FormatVersion {
+	if s.symbols == nil {
+		return 0
+	}
+	return s.symbols.FormatVersion()
+}
+
 func (s *Dataset) Index() phlaredb.IndexReader { return s.tsdb.index }
 
+// ErrSectionMissing indicates that the dataset table of contents
+// does not include a section.
+var ErrSectionMissing = errors.New("section missing")
+
+// Validate checks that the dataset table of contents
+// includes all the sections of the object format.
+func (s *Dataset) Validate() error {
+	for _, sc := range allSections {
+		if s.sectionIndex(sc) >= len(s.meta.TableOfContents) {
+			return fmt.Errorf("%w: dataset %q: %s", ErrSectionMissing, s.meta.Name, sc)
+		}
+	}
+	return nil
+}
+
+// SectionSize returns the size of the section in bytes.
+func (s *Dataset) SectionSize(sc Section) int64 { return s.sectionSize(sc) }
+
 // Offset of the tenant dataset section within the object.
 func (s *Dataset) offset() uint64 { return s.meta.TableOfContents[0] }
 
@@ -157,6 +199,9 @@ func (s *Dataset) sectionIndex(sc Section) int {
 }
 
 func (s *Dataset) sectionName(sc Section) string {
+	if c, ok := lookupCustomSection(sc); ok {
+		return c.name
+	}
 	var n []string
 	switch s.obj.meta.FormatVersion {
 	default:
@@ -202,6 +247,20 @@ func (s *Dataset) inMemoryBuffer() []byte {
 	return nil
 }
 
+// sectionBuffer returns the buffer holding the section data, and the
+// offset of the section within the buffer. If the section is neither
+// loaded into memory nor cached, nil is returned.
+func (s *Dataset) sectionBuffer(ctx context.Context, sc Section) ([]byte, int64, error) {
+	if buf := s.inMemoryBuffer(); buf != nil {
+		return buf, s.sectionOffset(sc) - int64(s.offset()), nil
+	}
+	if s.obj.cache != nil {
+		buf, err := s.obj.cache.section(ctx, s, sc)
+		return buf, 0, err
+	}
+	return nil, 0, nil
+}
+
 func (s *Dataset) inMemoryBucket(buf []byte) objstore.Bucket {
 	bucket := memory.NewInMemBucket()
 	bucket.Set(s.obj.path, buf)
@@ -12,6 +12,7 @@ import (
 
 	metastorev1 "github.com/grafana/pyroscope/api/gen/proto/go/metastore/v1"
 	"github.com/grafana/pyroscope/pkg/objstore"
+	"github.com/grafana/pyroscope/pkg/objstore/providers/memory"
 	"github.com/grafana/pyroscope/pkg/util"
 	"github.com/grafana/pyroscope/pkg/util/bufferpool"
 	"github.com/grafana/pyroscope/pkg/util/refctr"
@@ -47,6 +48,17 @@ var (
 	sectionIndices = [...][]int{1: {-1, 0, 1, 2}}
 )
 
+// String returns the name of the section in the latest format version.
+func (sc Section) String() string {
+	if c, ok := lookupCustomSection(sc); ok {
+		return c.name
+	}
+	if n := sectionNames[1]; int(sc) < len(n) {
+		return n[sc]
+	}
+	return "section-" + strconv.Itoa(int(sc))
+}
+
 func (sc Section) open(ctx context.Context, s *Dataset) (err error) {
 	switch sc {
 	case SectionTSDB:
@@ -56,7 +68,7 @@ func (sc Section) open(ctx context.Context, s *Dataset) (err error) {
 	case SectionProfiles:
 		return openProfileTable(ctx, s)
 	default:
-		panic(fmt.Sprintf("bug: unknown section: %d", sc))
+		return openCustomSection(ctx, s, sc)
 	}
 }
 
@@ -73,6 +85,8 @@ type Object struct {
 
 	memSize     int
 	downloadDir string
+	readAhead   readAheadOptions
+	cache       *SectionCache
 }
 
 type ObjectOption func(*Object)
@@ -95,6 +109,27 @@ func WithObjectDownload(dir string) ObjectOption {
 	}
 }
 
+// WithObjectParquetReadAhead enables prefetching of parquet column
+// chunks while the profile table is being read: once a query proceeds
+// to the next row group, the column chunks of the following one are
+// fetched in the background. The size limits the total size of the
+// chunks held in memory per dataset; chunks that exceed it are not
+// prefetched. Metrics are optional.
+func WithObjectParquetReadAhead(size int64, m *ReadAheadMetrics) ObjectOption {
+	return func(obj *Object) {
+		obj.readAhead = readAheadOptions{size: size, metrics: m}
+	}
+}
+
+// WithObjectSectionCache enables caching of the sections of the
+// pinned blocks. If the object is loaded into memory, the cache
+// is not used.
+func WithObjectSectionCache(c *SectionCache) ObjectOption {
+	return func(obj *Object) {
+		obj.cache = c
+	}
+}
+
 func NewObject(storage objstore.Bucket, meta *metastorev1.BlockMeta, opts ...ObjectOption) *Object {
 	o := &Object{
 		storage: storage,
@@ -108,6 +143,14 @@ func NewObject(storage objstore.Bucket, meta *metastorev1.BlockMeta, opts ...Obj
 	return o
 }
 
+// NewObjectFromData creates an object of the data held in memory, e.g.,
+// of a snapshot of a segment that has not been flushed yet.
+func NewObjectFromData(data []byte, meta *metastorev1.BlockMeta, opts ...ObjectOption) *Object {
+	bucket := memory.NewInMemBucket()
+	bucket.Set(ObjectPath(meta), data)
+	return NewObject(objstore.NewBucket(bucket), meta, opts...)
+}
+
 func ObjectPath(md *metastorev1.BlockMeta) string {
 	topLevel := DirPathBlock
 	tenantDirName := md.TenantId
@@ -201,6 +244,12 @@ func (obj *Object) closeErr(err error) (closeErr error) {
 
 func (obj *Object) Meta() *metastorev1.BlockMeta { return obj.meta }
 
+// Path returns the path of the object in the storage.
+func (obj *Object) Path() string { return obj.path }
+
+// Storage returns the bucket the object is read from.
+func (obj *Object) Storage() objstore.BucketReader { return obj.storage }
+
 func (obj *Object) Download(ctx context.Context) error {
 	dir := filepath.Join(obj.downloadDir, obj.meta.Id)
 	local, err := objstore.Download(ctx, obj.path, obj.storage, dir)
@@ -0,0 +1,127 @@
+package block
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ReadAheadMetrics measure the effectiveness of the read-ahead: the
+// ratio of the used bytes to the fetched ones.
+type ReadAheadMetrics struct {
+	// Bytes fetched ahead of time.
+	Fetched prometheus.Counter
+	// Bytes read from the fetched ranges.
+	Used prometheus.Counter
+}
+
+type readAheadOptions struct {
+	size    int64
+	metrics *ReadAheadMetrics
+}
+
+// readAheadReader serves reads from the ranges fetched in the background,
+// if possible. The total size of the ranges held is bounded: the oldest
+// ones are discarded to make room for new ones.
+type readAheadReader struct {
+	ctx     context.Context
+	reader  io.ReaderAt
+	size    int64
+	metrics *ReadAheadMetrics
+
+	m      sync.Mutex
+	ranges []*readAheadRange
+	held   int64
+	wg     sync.WaitGroup
+}
+
+type readAheadRange struct {
+	off  int64
+	buf  []byte
+	done chan struct{}
+	err  error
+}
+
+func newReadAheadReader(ctx context.Context, r io.ReaderAt, size int64, m *ReadAheadMetrics) *readAheadReader {
+	return &readAheadReader{
+		ctx:     ctx,
+		reader:  r,
+		size:    size,
+		metrics: m,
+	}
+}
+
+// prefetch starts fetching the range in the background. The range is
+// ignored if it exceeds the size limit, or has already been requested.
+func (r *readAheadReader) prefetch(off, size int64) {
+	if size <= 0 || size > r.size {
+		return
+	}
+	r.m.Lock()
+	defer r.m.Unlock()
+	for _, x := range r.ranges {
+		if x.off == off && int64(len(x.buf)) == size {
+			return
+		}
+	}
+	for len(r.ranges) > 0 && r.held+size > r.size {
+		r.held -= int64(len(r.ranges[0].buf))
+		r.ranges[0] = nil
+		r.ranges = r.ranges[1:]
+	}
+	x := &readAheadRange{
+		off:  off,
+		buf:  make([]byte, size),
+		done: make(chan struct{}),
+	}
+	r.ranges = append(r.ranges, x)
+	r.held += size
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer close(x.done)
+		if x.err = r.ctx.Err(); x.err != nil {
+			return
+		}
+		var n int
+		n, x.err = r.reader.ReadAt(x.buf, x.off)
+		if x.err == io.EOF && n == len(x.buf) {
+			x.err = nil
+		}
+		if r.metrics != nil {
+			r.metrics.Fetched.Add(float64(n))
+		}
+	}()
+}
+
+func (r *readAheadReader) lookup(off int64, size int) *readAheadRange {
+	r.m.Lock()
+	defer r.m.Unlock()
+	for _, x := range r.ranges {
+		if off >= x.off && off+int64(size) <= x.off+int64(len(x.buf)) {
+			return x
+		}
+	}
+	return nil
+}
+
+func (r *readAheadReader) ReadAt(p []byte, off int64) (int, error) {
+	if x := r.lookup(off, len(p)); x != nil {
+		// The range may still be in flight: waiting for
+		// it is cheaper than issuing another request.
+		<-x.done
+		if x.err == nil {
+			n := copy(p, x.buf[off-x.off:])
+			if r.metrics != nil {
+				r.metrics.Used.Add(float64(n))
+			}
+			return n, nil
+		}
+	}
+	return r.reader.ReadAt(p, off)
+}
+
+// wait blocks until all the fetches are complete.
+func (r *readAheadReader) wait() { r.wg.Wait() }
@@ -0,0 +1,83 @@
+package block
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+)
+
+type countingReaderAt struct {
+	r     io.ReaderAt
+	reads atomic.Int64
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	c.reads.Inc()
+	return c.r.ReadAt(p, off)
+}
+
+func Test_readAheadReader(t *testing.T) {
+	data := make([]byte, 100)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	src := &countingReaderAt{r: bytes.NewReader(data)}
+	m := &ReadAheadMetrics{
+		Fetched: prometheus.NewCounter(prometheus.CounterOpts{}),
+		Used:    prometheus.NewCounter(prometheus.CounterOpts{}),
+	}
+	r := newReadAheadReader(context.Background(), src, 30, m)
+
+	read := func(off, size int64) []byte {
+		p := make([]byte, size)
+		n, err := r.ReadAt(p, off)
+		require.NoError(t, err)
+		require.Equal(t, int(size), n)
+		return p
+	}
+
+	r.prefetch(10, 20)
+	r.prefetch(10, 20) // Ignored: already requested.
+	r.prefetch(0, 40)  // Ignored: exceeds the limit.
+	r.wait()
+	require.Equal(t, int64(1), src.reads.Load())
+
+	require.Equal(t, data[10:15], read(10, 5))
+	require.Equal(t, data[15:30], read(15, 15))
+	require.Equal(t, int64(1), src.reads.Load())
+	// Out of the prefetched range.
+	require.Equal(t, data[25:35], read(25, 10))
+	require.Equal(t, int64(2), src.reads.Load())
+
+	// The first range is discarded to make room.
+	r.prefetch(50, 20)
+	r.wait()
+	require.Equal(t, int64(3), src.reads.Load())
+	require.Equal(t, data[50:70], read(50, 20))
+	require.Equal(t, data[10:20], read(10, 10))
+	require.Equal(t, int64(4), src.reads.Load())
+
+	require.Equal(t, float64(40), testutil.ToFloat64(m.Fetched))
+	require.Equal(t, float64(40), testutil.ToFloat64(m.Used))
+}
+
+func Test_readAheadReader_Canceled(t *testing.T) {
+	src := &countingReaderAt{r: bytes.NewReader(make([]byte, 10))}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r := newReadAheadReader(ctx, src, 10, nil)
+	r.prefetch(0, 10)
+	r.wait()
+	require.Equal(t, int64(0), src.reads.Load())
+	// The failed range is not used.
+	n, err := r.ReadAt(make([]byte, 5), 0)
+	require.NoError(t, err)
+	require.Equal(t, 5, n)
+	require.Equal(t, int64(1), src.reads.Load())
+}
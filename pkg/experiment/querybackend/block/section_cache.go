@@ -0,0 +1,206 @@
+package block
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/pyroscope/pkg/objstore"
+)
+
+// SectionCacheMetrics describe the occupancy of the section cache.
+type SectionCacheMetrics struct {
+	// Total size of the cached sections in bytes.
+	Size prometheus.Gauge
+	// Number of the cached sections.
+	Entries prometheus.Gauge
+	// Number of the pinned blocks.
+	Pinned prometheus.Gauge
+	// Number of the section lookups that found the section in cache.
+	Hits prometheus.Counter
+	// Number of the section lookups that had to load the section.
+	Misses prometheus.Counter
+	// Number of the sections evicted to make room for new ones.
+	Evictions prometheus.Counter
+}
+
+// SectionCache holds the sections of the pinned blocks in memory, so
+// that the queries against them do not load the sections from the
+// object storage. Only the sections of the blocks pinned explicitly
+// are cached; a pin expires after the TTL, unless it is renewed.
+// Once the cache size limit is reached, the least recently used
+// sections are evicted.
+//
+// SectionCache is safe for concurrent use.
+type SectionCache struct {
+	size    int64
+	ttl     time.Duration
+	metrics *SectionCacheMetrics
+	now     func() time.Time
+
+	m       sync.Mutex
+	pins    map[string]*sectionPin
+	entries map[sectionCacheKey]*list.Element
+	lru     list.List // Front is the most recently used.
+	held    int64
+}
+
+type sectionPin struct {
+	sections []Section
+	expires  time.Time
+}
+
+type sectionCacheKey struct {
+	path   string
+	offset int64
+}
+
+type sectionCacheEntry struct {
+	key   sectionCacheKey
+	block string
+	buf   []byte
+}
+
+// NewSectionCache creates a new section cache of the given size in bytes.
+// A non-positive TTL means that pins never expire. Metrics are optional.
+func NewSectionCache(size int64, ttl time.Duration, m *SectionCacheMetrics) *SectionCache {
+	return &SectionCache{
+		size:    size,
+		ttl:     ttl,
+		metrics: m,
+		now:     time.Now,
+		pins:    make(map[string]*sectionPin),
+		entries: make(map[sectionCacheKey]*list.Element),
+	}
+}
+
+// Pin marks the sections of the block for caching. The sections are
+// loaded into the cache by the first query that opens them. Pinning
+// of an already pinned block replaces the sections and renews the pin.
+func (c *SectionCache) Pin(blockID string, sections ...Section) {
+	if len(sections) == 0 {
+		return
+	}
+	c.m.Lock()
+	defer c.m.Unlock()
+	now := c.now()
+	c.expire(now)
+	p, ok := c.pins[blockID]
+	if !ok {
+		p = new(sectionPin)
+		c.pins[blockID] = p
+	}
+	p.sections = append(p.sections[:0], sections...)
+	if c.ttl > 0 {
+		p.expires = now.Add(c.ttl)
+	}
+	c.updateMetrics()
+}
+
+func (c *SectionCache) unpin(blockID string) {
+	delete(c.pins, blockID)
+	for e := c.lru.Front(); e != nil; {
+		next := e.Next()
+		if x := e.Value.(*sectionCacheEntry); x.block == blockID {
+			c.remove(e)
+		}
+		e = next
+	}
+}
+
+func (c *SectionCache) expire(now time.Time) {
+	for blockID, p := range c.pins {
+		if !p.expires.IsZero() && now.After(p.expires) {
+			c.unpin(blockID)
+		}
+	}
+}
+
+func (c *SectionCache) pinned(blockID string, sc Section) bool {
+	p, ok := c.pins[blockID]
+	if !ok {
+		return false
+	}
+	for _, x := range p.sections {
+		if x == sc {
+			return true
+		}
+	}
+	return false
+}
+
+// section returns the section data, if the section is pinned. The
+// section is loaded from the storage, if it is not cached yet. The
+// data must not be modified. Concurrent lookups of a missing section
+// may load it multiple times: it is only cached once.
+func (c *SectionCache) section(ctx context.Context, s *Dataset, sc Section) ([]byte, error) {
+	key := sectionCacheKey{path: s.obj.path, offset: s.sectionOffset(sc)}
+	size := s.sectionSize(sc)
+	if size <= 0 || size > c.size {
+		return nil, nil
+	}
+	c.m.Lock()
+	c.expire(c.now())
+	if !c.pinned(s.obj.meta.Id, sc) {
+		c.updateMetrics()
+		c.m.Unlock()
+		return nil, nil
+	}
+	if e, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(e)
+		buf := e.Value.(*sectionCacheEntry).buf
+		c.m.Unlock()
+		if c.metrics != nil {
+			c.metrics.Hits.Inc()
+		}
+		return buf, nil
+	}
+	c.m.Unlock()
+	if c.metrics != nil {
+		c.metrics.Misses.Inc()
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, size))
+	if err := objstore.ReadRange(ctx, buf, s.obj.path, s.obj.storage, key.offset, size); err != nil {
+		return nil, err
+	}
+
+	c.m.Lock()
+	defer c.m.Unlock()
+	// The block might have been unpinned while the section was loading.
+	if _, ok := c.entries[key]; !ok && c.pinned(s.obj.meta.Id, sc) {
+		c.add(&sectionCacheEntry{key: key, block: s.obj.meta.Id, buf: buf.Bytes()})
+		c.updateMetrics()
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *SectionCache) add(x *sectionCacheEntry) {
+	for c.lru.Len() > 0 && c.held+int64(len(x.buf)) > c.size {
+		c.remove(c.lru.Back())
+		if c.metrics != nil {
+			c.metrics.Evictions.Inc()
+		}
+	}
+	c.entries[x.key] = c.lru.PushFront(x)
+	c.held += int64(len(x.buf))
+}
+
+func (c *SectionCache) remove(e *list.Element) {
+	x := c.lru.Remove(e).(*sectionCacheEntry)
+	delete(c.entries, x.key)
+	c.held -= int64(len(x.buf))
+}
+
+func (c *SectionCache) updateMetrics() {
+	if c.metrics == nil {
+		return
+	}
+	c.metrics.Size.Set(float64(c.held))
+	c.metrics.Entries.Set(float64(c.lru.Len()))
+	c.metrics.Pinned.Set(float64(len(c.pins)))
+}
@@ -0,0 +1,129 @@
+package block
+
+import (
+	"context"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	compactorv1 "github.com/grafana/pyroscope/api/gen/proto/go/compactor/v1"
+	"github.com/grafana/pyroscope/pkg/objstore"
+	objstoretestutil "github.com/grafana/pyroscope/pkg/objstore/testutil"
+)
+
+type countingBucket struct {
+	objstore.Bucket
+	reads atomic.Int64
+}
+
+func (b *countingBucket) GetRange(ctx context.Context, name string, off, length int64) (io.ReadCloser, error) {
+	b.reads.Inc()
+	return b.Bucket.GetRange(ctx, name, off, length)
+}
+
+func newTestSectionCacheMetrics() *SectionCacheMetrics {
+	return &SectionCacheMetrics{
+		Size:      prometheus.NewGauge(prometheus.GaugeOpts{}),
+		Entries:   prometheus.NewGauge(prometheus.GaugeOpts{}),
+		Pinned:    prometheus.NewGauge(prometheus.GaugeOpts{}),
+		Hits:      prometheus.NewCounter(prometheus.CounterOpts{}),
+		Misses:    prometheus.NewCounter(prometheus.CounterOpts{}),
+		Evictions: prometheus.NewCounter(prometheus.CounterOpts{}),
+	}
+}
+
+func Test_SectionCache(t *testing.T) {
+	ctx := context.Background()
+	fs, _ := objstoretestutil.NewFilesystemBucket(t, ctx, "testdata")
+	bucket := &countingBucket{Bucket: fs}
+
+	var blocks compactorv1.CompletedJob
+	data, err := os.ReadFile("testdata/block-metas.json")
+	require.NoError(t, err)
+	require.NoError(t, protojson.Unmarshal(data, &blocks))
+	md := blocks.Blocks[0].CloneVT()
+	// Prevent the object and the dataset from being loaded into memory.
+	md.Size = 1 << 30
+
+	// reads opens the dataset and returns
+	// the number of the storage reads.
+	reads := func(c *SectionCache, sections ...Section) int64 {
+		before := bucket.reads.Load()
+		obj := NewObject(bucket, md, WithObjectMaxSizeLoadInMemory(0), WithObjectSectionCache(c))
+		ds := NewDataset(md.Datasets[0], obj)
+		WithDatasetMaxSizeLoadInMemory(0)(ds)
+		require.NoError(t, ds.Open(ctx, sections...))
+		require.NoError(t, ds.Close())
+		return bucket.reads.Load() - before
+	}
+
+	t.Run("pinned sections are not loaded from the storage", func(t *testing.T) {
+		m := newTestSectionCacheMetrics()
+		c := NewSectionCache(1<<20, time.Minute, m)
+		uncached := reads(c, SectionTSDB, SectionSymbols)
+
+		c.Pin(md.Id, SectionTSDB)
+		require.Equal(t, uncached, reads(c, SectionTSDB, SectionSymbols))
+		require.Equal(t, float64(1), testutil.ToFloat64(m.Misses))
+		require.Equal(t, uncached-1, reads(c, SectionTSDB, SectionSymbols))
+		require.Equal(t, float64(1), testutil.ToFloat64(m.Hits))
+
+		size := NewDataset(md.Datasets[0], NewObject(bucket, md)).sectionSize(SectionTSDB)
+		require.Equal(t, float64(size), testutil.ToFloat64(m.Size))
+		require.Equal(t, float64(1), testutil.ToFloat64(m.Entries))
+		require.Equal(t, float64(1), testutil.ToFloat64(m.Pinned))
+	})
+
+	t.Run("pins expire", func(t *testing.T) {
+		m := newTestSectionCacheMetrics()
+		c := NewSectionCache(1<<20, time.Minute, m)
+		now := time.Now()
+		c.now = func() time.Time { return now }
+		uncached := reads(c, SectionTSDB)
+
+		c.Pin(md.Id, SectionTSDB)
+		reads(c, SectionTSDB)
+		require.Less(t, reads(c, SectionTSDB), uncached)
+
+		now = now.Add(2 * time.Minute)
+		require.Equal(t, uncached, reads(c, SectionTSDB))
+		require.Zero(t, testutil.ToFloat64(m.Size))
+		require.Zero(t, testutil.ToFloat64(m.Entries))
+		require.Zero(t, testutil.ToFloat64(m.Pinned))
+	})
+
+	t.Run("least recently used sections are evicted", func(t *testing.T) {
+		ds := NewDataset(md.Datasets[0], NewObject(bucket, md))
+		tsdbSize := ds.sectionSize(SectionTSDB)
+		profilesSize := ds.sectionSize(SectionProfiles)
+		m := newTestSectionCacheMetrics()
+		c := NewSectionCache(tsdbSize+profilesSize-1, 0, m)
+
+		c.Pin(md.Id, SectionTSDB, SectionProfiles)
+		reads(c, SectionTSDB)
+		reads(c, SectionProfiles)
+		require.Equal(t, float64(1), testutil.ToFloat64(m.Evictions))
+		require.Equal(t, float64(1), testutil.ToFloat64(m.Entries))
+		require.Equal(t, float64(profilesSize), testutil.ToFloat64(m.Size))
+	})
+}
+
+func Test_SectionCache_Pin(t *testing.T) {
+	c := NewSectionCache(1<<20, 0, nil)
+	c.Pin("a")
+	require.False(t, c.pinned("a", SectionSymbols))
+	c.Pin("a", SectionSymbols)
+	require.True(t, c.pinned("a", SectionSymbols))
+	require.False(t, c.pinned("a", SectionTSDB))
+	// Pinning replaces the sections.
+	c.Pin("a", SectionTSDB)
+	require.False(t, c.pinned("a", SectionSymbols))
+	require.True(t, c.pinned("a", SectionTSDB))
+}
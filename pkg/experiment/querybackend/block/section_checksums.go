@@ -0,0 +1,143 @@
+package block
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/prometheus/client_golang/prometheus"
+	tsdb_enc "github.com/prometheus/prometheus/tsdb/encoding"
+
+	"github.com/grafana/pyroscope/pkg/phlaredb/symdb"
+	"github.com/grafana/pyroscope/pkg/phlaredb/tsdb/index"
+)
+
+// ErrSectionCorrupted indicates that the data of a dataset
+// section does not match its checksum.
+var ErrSectionCorrupted = errors.New("section corrupted")
+
+// SectionCorruptedError is returned by Dataset.Open, if the checksum
+// verification is enabled and a section of the dataset is corrupted.
+type SectionCorruptedError struct {
+	Dataset string
+	Section Section
+	Err     error
+}
+
+func (e *SectionCorruptedError) Error() string {
+	return fmt.Sprintf("%v: dataset %q: %s: %v", ErrSectionCorrupted, e.Dataset, e.Section, e.Err)
+}
+
+func (e *SectionCorruptedError) Is(target error) bool { return target == ErrSectionCorrupted }
+
+func (e *SectionCorruptedError) Unwrap() error { return e.Err }
+
+// WithDatasetChecksumVerification enables verification of the checksums
+// of the sections, when the dataset is opened: the sections are read
+// entirely, and the dataset fails to open, if any of them is corrupted.
+// The failures are counted by the section, if the counter is not nil.
+//
+// Only the built-in sections are verified.
+func WithDatasetChecksumVerification(failures *prometheus.CounterVec) DatasetOption {
+	return func(s *Dataset) {
+		s.verifyChecksums = true
+		s.checksumFailures = failures
+	}
+}
+
+func (s *Dataset) verifySection(ctx context.Context, sc Section) error {
+	var err error
+	switch sc {
+	case SectionTSDB:
+		err = verifyTSDB(s.tsdb.index)
+	case SectionSymbols:
+		err = s.symbols.Verify(ctx)
+	case SectionProfiles:
+		err = verifyParquetFile(s.profiles.File)
+	}
+	if err == nil {
+		return nil
+	}
+	if !isChecksumMismatch(err) {
+		return fmt.Errorf("verifying section %v: %w", s.sectionName(sc), err)
+	}
+	if s.checksumFailures != nil {
+		s.checksumFailures.WithLabelValues(sc.String()).Inc()
+	}
+	return &SectionCorruptedError{Dataset: s.meta.Name, Section: sc, Err: err}
+}
+
+func isChecksumMismatch(err error) bool {
+	return errors.Is(err, tsdb_enc.ErrInvalidChecksum) ||
+		errors.Is(err, symdb.ErrInvalidCRC) ||
+		errors.Is(err, parquet.ErrCorrupted)
+}
+
+// verifyTSDB reads all the postings lists and series of the index:
+// the checksums are verified by the reader.
+func verifyTSDB(r *index.Reader) error {
+	names, err := r.LabelNames()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		values, err := r.LabelValues(name)
+		if err != nil {
+			return err
+		}
+		p, err := r.Postings(name, nil, values...)
+		if err != nil {
+			return err
+		}
+		for p.Next() {
+		}
+		if err = p.Err(); err != nil {
+			return err
+		}
+	}
+	k, v := index.AllPostingsKey()
+	p, err := r.Postings(k, nil, v)
+	if err != nil {
+		return err
+	}
+	var chunks []index.ChunkMeta
+	for p.Next() {
+		if _, err = r.Series(p.At(), nil, &chunks); err != nil {
+			return err
+		}
+	}
+	return p.Err()
+}
+
+// verifyParquetFile reads all the pages of the file:
+// the page checksums, if present, are verified by
+// the reader.
+func verifyParquetFile(f *parquet.File) error {
+	for _, rg := range f.RowGroups() {
+		for _, cc := range rg.ColumnChunks() {
+			if err := verifyColumnChunk(cc); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func verifyColumnChunk(cc parquet.ColumnChunk) error {
+	pages := cc.Pages()
+	defer func() {
+		_ = pages.Close()
+	}()
+	for {
+		p, err := pages.ReadPage()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		parquet.Release(p)
+	}
+}
@@ -0,0 +1,70 @@
+package block
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	compactorv1 "github.com/grafana/pyroscope/api/gen/proto/go/compactor/v1"
+	"github.com/grafana/pyroscope/pkg/objstore"
+	"github.com/grafana/pyroscope/pkg/objstore/providers/memory"
+	"github.com/grafana/pyroscope/pkg/phlaredb/tsdb/index"
+)
+
+func Test_Dataset_VerifyChecksums(t *testing.T) {
+	ctx := context.Background()
+	var blocks compactorv1.CompletedJob
+	data, err := os.ReadFile("testdata/block-metas.json")
+	require.NoError(t, err)
+	require.NoError(t, protojson.Unmarshal(data, &blocks))
+	md := blocks.Blocks[0]
+	path := ObjectPath(md)
+	object, err := os.ReadFile("testdata/" + path)
+	require.NoError(t, err)
+
+	open := func(object []byte, failures *prometheus.CounterVec) error {
+		bucket := memory.NewInMemBucket()
+		bucket.Set(path, object)
+		ds := NewDataset(md.Datasets[0], NewObject(objstore.NewBucket(bucket), md))
+		WithDatasetChecksumVerification(failures)(ds)
+		if err := ds.Open(ctx, allSections...); err != nil {
+			return err
+		}
+		return ds.Close()
+	}
+
+	failures := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test"}, []string{"section"})
+	require.NoError(t, open(object, failures))
+	require.Zero(t, testutil.CollectAndCount(failures))
+
+	// Corrupt the first series of the index: the series are
+	// not read when the index is opened, unless verified.
+	ds := NewDataset(md.Datasets[0], NewObject(nil, md))
+	off := ds.sectionOffset(SectionTSDB)
+	toc, err := index.NewTOCFromByteSlice(index.RealByteSlice(object[off : off+ds.sectionSize(SectionTSDB)]))
+	require.NoError(t, err)
+	// The series are 16-byte aligned.
+	series := off + int64(toc.Series+15)/16*16
+	corrupted := append([]byte(nil), object...)
+	corrupted[series+2] ^= 0xff
+
+	err = open(corrupted, failures)
+	require.ErrorIs(t, err, ErrSectionCorrupted)
+	var e *SectionCorruptedError
+	require.True(t, errors.As(err, &e))
+	require.Equal(t, SectionTSDB, e.Section)
+	require.Equal(t, float64(1), testutil.ToFloat64(failures.WithLabelValues("tsdb")))
+
+	// The sections are not verified by default.
+	bucket := memory.NewInMemBucket()
+	bucket.Set(path, corrupted)
+	ds = NewDataset(md.Datasets[0], NewObject(objstore.NewBucket(bucket), md))
+	require.NoError(t, ds.Open(ctx, allSections...))
+	require.NoError(t, ds.Close())
+}
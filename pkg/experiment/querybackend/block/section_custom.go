@@ -0,0 +1,118 @@
+package block
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/grafana/dskit/multierror"
+)
+
+// SectionOpener opens a custom section of the dataset, e.g. an
+// auxiliary index stored alongside the block. The value returned
+// is available via Dataset.Section; if it implements io.Closer,
+// it is closed along with the dataset.
+type SectionOpener func(ctx context.Context, ds *Dataset) (any, error)
+
+// Custom sections are numbered after the built-in ones; the gap
+// is reserved for the sections added in future format versions.
+const firstCustomSection Section = 1 << 16
+
+var (
+	customSectionsMutex sync.RWMutex
+	customSections      []customSection
+)
+
+type customSection struct {
+	name string
+	open SectionOpener
+}
+
+// RegisterSection registers a custom section and returns its identifier,
+// which can be specified as a query dependency. The section name must be
+// unique. The function must be called at initialization, before any of
+// the datasets are opened.
+func RegisterSection(name string, open SectionOpener) (Section, error) {
+	if name == "" {
+		return 0, fmt.Errorf("section name must not be empty")
+	}
+	if open == nil {
+		return 0, fmt.Errorf("section %q: opener must not be nil", name)
+	}
+	for _, n := range sectionNames[1] {
+		if n == name {
+			return 0, fmt.Errorf("section %q collides with built-in section", name)
+		}
+	}
+	customSectionsMutex.Lock()
+	defer customSectionsMutex.Unlock()
+	for _, c := range customSections {
+		if c.name == name {
+			return 0, fmt.Errorf("section %q already registered", name)
+		}
+	}
+	customSections = append(customSections, customSection{name: name, open: open})
+	return firstCustomSection + Section(len(customSections)-1), nil
+}
+
+// IsValid reports whether the section is either
+// a built-in one, or has been registered.
+func (sc Section) IsValid() bool {
+	for _, s := range allSections {
+		if sc == s {
+			return true
+		}
+	}
+	_, ok := lookupCustomSection(sc)
+	return ok
+}
+
+func lookupCustomSection(sc Section) (customSection, bool) {
+	if sc < firstCustomSection {
+		return customSection{}, false
+	}
+	customSectionsMutex.RLock()
+	defer customSectionsMutex.RUnlock()
+	if i := int(sc - firstCustomSection); i < len(customSections) {
+		return customSections[i], true
+	}
+	return customSection{}, false
+}
+
+func openCustomSection(ctx context.Context, s *Dataset, sc Section) error {
+	c, ok := lookupCustomSection(sc)
+	if !ok {
+		panic(fmt.Sprintf("bug: unknown section: %d", sc))
+	}
+	v, err := c.open(ctx, s)
+	if err != nil {
+		return err
+	}
+	s.customMutex.Lock()
+	defer s.customMutex.Unlock()
+	if s.custom == nil {
+		s.custom = make(map[Section]any)
+	}
+	s.custom[sc] = v
+	return nil
+}
+
+// Section returns the value of the custom section opened with
+// the dataset, or nil, if the section has not been opened.
+func (s *Dataset) Section(sc Section) any {
+	s.customMutex.Lock()
+	defer s.customMutex.Unlock()
+	return s.custom[sc]
+}
+
+func (s *Dataset) closeCustomSections(merr *multierror.MultiError) {
+	s.customMutex.Lock()
+	defer s.customMutex.Unlock()
+	for _, v := range s.custom {
+		if c, ok := v.(io.Closer); ok {
+			merr.Add(c.Close())
+		}
+	}
+	s.custom = nil
+}
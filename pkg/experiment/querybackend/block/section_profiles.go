@@ -26,14 +26,18 @@ import (
 	"github.com/grafana/pyroscope/pkg/util/loser"
 )
 
-func openProfileTable(_ context.Context, s *Dataset) (err error) {
+func openProfileTable(ctx context.Context, s *Dataset) (err error) {
 	offset := s.sectionOffset(SectionProfiles)
 	size := s.sectionSize(SectionProfiles)
-	if buf := s.inMemoryBuffer(); buf != nil {
-		offset -= int64(s.offset())
+	buf, bufOffset, err := s.sectionBuffer(ctx, SectionProfiles)
+	if err != nil {
+		return fmt.Errorf("loading profile parquet table: %w", err)
+	}
+	if buf != nil {
 		s.profiles, err = openParquetFile(
-			s.inMemoryBucket(buf), s.obj.path, offset, size,
+			s.inMemoryBucket(buf), s.obj.path, bufOffset, size,
 			0, // Do not prefetch the footer.
+			readAheadOptions{},
 			parquet.SkipBloomFilters(true),
 			parquet.FileReadMode(parquet.ReadModeSync),
 			parquet.ReadBufferSize(4<<10))
@@ -41,6 +45,7 @@ func openProfileTable(_ context.Context, s *Dataset) (err error) {
 		s.profiles, err = openParquetFile(
 			s.obj.storage, s.obj.path, offset, size,
 			estimateFooterSize(size),
+			s.obj.readAhead,
 			parquet.SkipBloomFilters(true),
 			parquet.FileReadMode(parquet.ReadModeAsync),
 			parquet.ReadBufferSize(estimateReadBufferSize(size)))
@@ -54,8 +59,9 @@ func openProfileTable(_ context.Context, s *Dataset) (err error) {
 type ParquetFile struct {
 	*parquet.File
 
-	reader objstore.ReaderAtCloser
-	cancel context.CancelFunc
+	reader    objstore.ReaderAtCloser
+	readAhead *readAheadReader
+	cancel    context.CancelFunc
 
 	storage objstore.BucketReader
 	path    string
@@ -67,6 +73,7 @@ func openParquetFile(
 	storage objstore.BucketReader,
 	path string,
 	offset, size, footerSize int64,
+	readAhead readAheadOptions,
 	options ...parquet.FileOption,
 ) (p *ParquetFile, err error) {
 	// The context is used for GetRange calls and should not
@@ -93,6 +100,10 @@ func openParquetFile(
 
 	var ra io.ReaderAt
 	ra = io.NewSectionReader(r, offset, size)
+	if readAhead.size > 0 {
+		p.readAhead = newReadAheadReader(ctx, ra, readAhead.size, readAhead.metrics)
+		ra = p.readAhead
+	}
 	if footerSize > 0 {
 		buf := bufferpool.GetBuffer(int(footerSize))
 		defer func() {
@@ -139,10 +150,31 @@ func (f *ParquetFile) fetchFooter(ctx context.Context, buf *bufferpool.Buffer, e
 	return objstore.ReadRange(ctx, buf, f.path, f.storage, f.off+f.size-s, s)
 }
 
+// PrefetchColumnChunk implements query.ColumnChunkPrefetcher. The call
+// has no effect, unless the read-ahead is enabled for the file.
+func (f *ParquetFile) PrefetchColumnChunk(rowGroup, column int) {
+	if f.readAhead == nil {
+		return
+	}
+	rowGroups := f.Metadata().RowGroups
+	if rowGroup >= len(rowGroups) || column >= len(rowGroups[rowGroup].Columns) {
+		return
+	}
+	md := rowGroups[rowGroup].Columns[column].MetaData
+	off := md.DataPageOffset
+	if md.DictionaryPageOffset > 0 && md.DictionaryPageOffset < off {
+		off = md.DictionaryPageOffset
+	}
+	f.readAhead.prefetch(off, md.TotalCompressedSize)
+}
+
 func (f *ParquetFile) Close() error {
 	if f.cancel != nil {
 		f.cancel()
 	}
+	if f.readAhead != nil {
+		f.readAhead.wait()
+	}
 	if f.reader != nil {
 		return f.reader.Close()
 	}
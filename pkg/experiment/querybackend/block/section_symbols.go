@@ -10,9 +10,12 @@ import (
 func openSymbols(ctx context.Context, s *Dataset) (err error) {
 	offset := s.sectionOffset(SectionSymbols)
 	size := s.sectionSize(SectionSymbols)
-	if buf := s.inMemoryBuffer(); buf != nil {
-		offset -= int64(s.offset())
-		s.symbols, err = symdb.OpenObject(ctx, s.inMemoryBucket(buf), s.obj.path, offset, size)
+	buf, bufOffset, err := s.sectionBuffer(ctx, SectionSymbols)
+	if err != nil {
+		return fmt.Errorf("loading symbols: %w", err)
+	}
+	if buf != nil {
+		s.symbols, err = symdb.OpenObject(ctx, s.inMemoryBucket(buf), s.obj.path, bufOffset, size)
 	} else {
 		s.symbols, err = symdb.OpenObject(ctx, s.obj.storage, s.obj.path, offset, size,
 			symdb.WithPrefetchSize(symbolsPrefetchSize))
@@ -18,9 +18,12 @@ func openTSDB(ctx context.Context, s *Dataset) (err error) {
 			_ = s.tsdb.Close()
 		}
 	}()
-	if buf := s.inMemoryBuffer(); buf != nil {
-		offset -= int64(s.offset())
-		s.tsdb.index, err = index.NewReader(index.RealByteSlice(buf[offset : offset+size]))
+	buf, bufOffset, err := s.sectionBuffer(ctx, SectionTSDB)
+	if err != nil {
+		return fmt.Errorf("loading tsdb: %w", err)
+	}
+	if buf != nil {
+		s.tsdb.index, err = index.NewReader(index.RealByteSlice(buf[bufOffset : bufOffset+size]))
 	} else {
 		s.tsdb.buf = bufferpool.GetBuffer(int(size))
 		if err = objstore.ReadRange(ctx, s.tsdb.buf, s.obj.path, s.obj.storage, offset, size); err == nil {
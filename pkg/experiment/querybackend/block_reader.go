@@ -2,10 +2,13 @@ package querybackend
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/opentracing/opentracing-go"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/promql/parser"
@@ -46,6 +49,23 @@ import (
 type BlockReader struct {
 	log     log.Logger
 	storage objstore.Bucket
+	metrics *metrics
+	options []block.ObjectOption
+	cache   *block.SectionCache
+	heads   HeadSource
+
+	// The dataset queries are mostly I/O bound, while the
+	// aggregation of the reports is CPU bound: the limits
+	// are independent.
+	reads        *concurrencyLimit
+	aggregations *concurrencyLimit
+
+	failOnInvalidSampleValues bool
+	maxResolveDepth           int
+	stackGroupings            map[string]StackGrouping
+	maxQueryCPUTime           time.Duration
+	maxSymbolsSectionSize     int64
+	verifyChecksums           bool
 
 	// TODO:
 	//  - Use a worker pool instead of the errgroup.
@@ -55,45 +75,239 @@ type BlockReader struct {
 	//    Instead, they should share the processing pipeline, if possible.
 }
 
-func NewBlockReader(logger log.Logger, storage objstore.Bucket) *BlockReader {
-	return &BlockReader{
+// NewBlockReader creates a new block reader. A positive read-ahead size
+// enables prefetching of the profile table column chunks, see
+// block.WithObjectParquetReadAhead. A positive section cache size
+// enables pinning of the block sections, see InvokeOptions.pin_blocks.
+func NewBlockReader(logger log.Logger, storage objstore.Bucket, reg prometheus.Registerer, config Config) *BlockReader {
+	b := &BlockReader{
 		log:     logger,
 		storage: storage,
+		metrics: newMetrics(reg),
+
+		failOnInvalidSampleValues: config.InvalidSampleValues == invalidSampleValuesFail,
+		maxResolveDepth:           config.MaxResolveDepth,
+		maxQueryCPUTime:           config.MaxQueryCPUTime,
+		maxSymbolsSectionSize:     config.MaxSymbolsSectionSize,
+		verifyChecksums:           config.VerifyChecksums,
+	}
+	// The configuration is expected to be validated.
+	b.stackGroupings, _ = compileStackGroupings(config.StackGroupings)
+	b.reads = newConcurrencyLimit(config.MaxConcurrentBlockReads, b.metrics.blockReadsInProgress)
+	b.aggregations = newConcurrencyLimit(config.MaxConcurrentAggregations, b.metrics.aggregationsInProgress)
+	if config.ParquetReadAheadSize > 0 {
+		b.options = append(b.options, block.WithObjectParquetReadAhead(config.ParquetReadAheadSize, &block.ReadAheadMetrics{
+			Fetched: b.metrics.readAheadFetchedBytes,
+			Used:    b.metrics.readAheadUsedBytes,
+		}))
 	}
+	if config.SectionCacheSize > 0 {
+		b.cache = block.NewSectionCache(config.SectionCacheSize, config.SectionCacheTTL, &block.SectionCacheMetrics{
+			Size:      b.metrics.sectionCacheSize,
+			Entries:   b.metrics.sectionCacheEntries,
+			Pinned:    b.metrics.sectionCachePinned,
+			Hits:      b.metrics.sectionCacheHits,
+			Misses:    b.metrics.sectionCacheMisses,
+			Evictions: b.metrics.sectionCacheEvictions,
+		})
+		b.options = append(b.options, block.WithObjectSectionCache(b.cache))
+	}
+	return b
+}
+
+// pinBlocks pins the sections the queries of the request depend on, if
+// requested: the queries against the blocks do not load the sections from
+// the object storage, until the pins expire or the sections are evicted.
+// This is useful for the most recent blocks that are queried repeatedly.
+func (b *BlockReader) pinBlocks(req *querybackendv1.InvokeRequest) {
+	if b.cache == nil || !req.Options.GetPinBlocks() {
+		return
+	}
+	sections := requestSections(req)
+	for _, md := range req.QueryPlan.Blocks {
+		b.cache.Pin(md.Id, sections...)
+	}
+}
+
+// HeadSource provides the snapshots of the in-memory heads
+// that have not been flushed to the object storage yet.
+type HeadSource interface {
+	// HeadSnapshots returns the objects of the head snapshots that have
+	// the data of the tenants. An object has the ID of the block the
+	// head is flushed to.
+	HeadSnapshots(ctx context.Context, tenants ...string) ([]*block.Object, error)
+}
+
+// SetHeadSource makes the queries also read the heads of the source, in
+// addition to the blocks of the query plan, so that the most recent data
+// is visible before it is flushed, see InvokeOptions.read_heads. The heads
+// that have already been flushed to the blocks of the query plan are
+// skipped.
+func (b *BlockReader) SetHeadSource(s HeadSource) {
+	b.heads = s
 }
 
 func (b *BlockReader) Invoke(
 	ctx context.Context,
 	req *querybackendv1.InvokeRequest,
 ) (*querybackendv1.InvokeResponse, error) {
+	m, err := b.aggregate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return m.response()
+}
+
+// aggregate reads the blocks of the query plan, and aggregates the reports.
+func (b *BlockReader) aggregate(
+	ctx context.Context,
+	req *querybackendv1.InvokeRequest,
+) (*reportAggregator, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "BlockReader.Invoke")
 	defer span.Finish()
+	withQueryID(span, req)
 	vr, err := validateRequest(req)
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "request validation failed: %v", err)
 	}
+	b.pinBlocks(req)
 	g, ctx := errgroup.WithContext(ctx)
-	m := newAggregator(req)
+	m := newAggregator(b.log, req)
+	cpu := newCPUBudget(b.maxQueryCPUTime)
+	seen := make([]*profileSet, len(req.Query))
+	for i, query := range req.Query {
+		if query.Tree.GetDeduplicateProfiles() {
+			seen[i] = newProfileSet()
+		}
+	}
+	objects := make([]*block.Object, 0, len(req.QueryPlan.Blocks))
 	for _, md := range req.QueryPlan.Blocks {
-		obj := block.NewObject(b.storage, md)
+		objects = append(objects, block.NewObject(b.storage, md, b.options...))
+	}
+	if b.heads != nil && req.Options.GetReadHeads() {
+		heads, err := b.headSnapshots(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, heads...)
+	}
+	for _, obj := range objects {
+		md := obj.Meta()
 		for _, meta := range md.Datasets {
-			c := newQueryContext(ctx, b.log, meta, vr, obj)
-			for _, query := range req.Query {
+			c := newQueryContext(ctx, b.log, b.metrics, meta, vr, obj)
+			c.failOnInvalidSampleValues = b.failOnInvalidSampleValues
+			c.maxResolveDepth = b.maxResolveDepth
+			c.stackGroupings = b.stackGroupings
+			c.cpu = cpu
+			c.maxSymbolsSectionSize = b.maxSymbolsSectionSize
+			if b.verifyChecksums || req.Options.GetVerifyChecksums() {
+				block.WithDatasetChecksumVerification(b.metrics.sectionChecksumFailures)(c.ds)
+			}
+			if err = c.ds.Validate(); err != nil {
+				if req.Options.GetFailOnSkippedBlocks() {
+					return nil, status.Errorf(codes.FailedPrecondition, "block %s can't be read: %v", md.Id, err)
+				}
+				// The dataset can't be queried: we skip it
+				// instead of failing the whole request.
+				m.skipBlock(md.Id, querybackendv1.SkipReason_SKIP_REASON_SECTION_MISSING, err.Error())
+				continue
+			}
+			if req.Options.GetBatch() {
+				g.Go(util.RecoverPanic(func() error {
+					return b.executeBatch(ctx, c, seen, m)
+				}))
+				continue
+			}
+			for i, query := range req.Query {
 				q := query
+				qc := c
+				if seen[i] != nil {
+					x := *c
+					x.seen = seen[i]
+					qc = &x
+				}
 				g.Go(util.RecoverPanic(func() error {
-					r, err := executeQuery(c, q)
+					var r *querybackendv1.Report
+					err := b.reads.run(ctx, func() (err error) {
+						r, err = executeQuery(qc, q)
+						return err
+					})
 					if err != nil {
 						return err
 					}
-					return m.aggregateReport(r)
+					return b.aggregations.run(ctx, func() error { return m.aggregateReport(r) })
 				}))
 			}
 		}
 	}
 	if err = g.Wait(); err != nil {
+		if errors.Is(err, errCPUBudgetExceeded) {
+			b.metrics.cpuBudgetExceeded.Inc()
+			return nil, status.Error(codes.ResourceExhausted, err.Error())
+		}
 		return nil, err
 	}
-	return m.response()
+	return m, nil
+}
+
+// headSnapshots returns the head snapshots of the tenants of the request,
+// except for the ones that have been flushed to the blocks of the query
+// plan. The snapshots are expected not to use the section cache: the
+// cache is keyed by the block ID, which the snapshot shares with the
+// block the head is flushed to.
+func (b *BlockReader) headSnapshots(ctx context.Context, req *querybackendv1.InvokeRequest) ([]*block.Object, error) {
+	heads, err := b.heads.HeadSnapshots(ctx, req.Tenant...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot heads: %w", err)
+	}
+	planned := make(map[string]struct{}, len(req.QueryPlan.Blocks))
+	for _, md := range req.QueryPlan.Blocks {
+		planned[md.Id] = struct{}{}
+	}
+	for _, id := range req.Options.GetPlanBlockIds() {
+		planned[id] = struct{}{}
+	}
+	j := 0
+	for _, obj := range heads {
+		if _, ok := planned[obj.Meta().Id]; !ok {
+			heads[j] = obj
+			j++
+		}
+	}
+	return heads[:j], nil
+}
+
+// executeBatch runs the queries of the batch against the dataset. The
+// dataset is opened once with the sections of all the queries, and stays
+// open until the last query completes: the queries share the sections.
+func (b *BlockReader) executeBatch(ctx context.Context, c *queryContext, seen []*profileSet, m *reportAggregator) error {
+	return b.reads.run(ctx, func() (err error) {
+		if err = c.open(); err != nil {
+			return fmt.Errorf("failed to initialize query context: %w", err)
+		}
+		defer func() {
+			_ = c.close(err)
+		}()
+		for i, query := range c.req.src.Query {
+			qc := c
+			if seen[i] != nil {
+				x := *c
+				x.seen = seen[i]
+				qc = &x
+			}
+			r, err := executeQuery(qc, query)
+			if err != nil {
+				return err
+			}
+			if r != nil {
+				r.QueryIndex = uint32(i)
+			}
+			if err = b.aggregations.run(ctx, func() error { return m.aggregateReport(r) }); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 type request struct {
@@ -101,6 +315,9 @@ type request struct {
 	matchers  []*labels.Matcher
 	startTime int64 // Unix nano.
 	endTime   int64 // Unix nano.
+	// If not empty, only the profiles of the
+	// stack trace partitions are selected.
+	partitions map[uint64]struct{}
 }
 
 func validateRequest(req *querybackendv1.InvokeRequest) (*request, error) {
@@ -2,18 +2,23 @@ package querybackendclient
 
 import (
 	"context"
+	"errors"
+	"io"
 
 	"github.com/grafana/dskit/grpcclient"
 	"github.com/grafana/dskit/services"
 	"github.com/opentracing-contrib/go-grpc"
 	"github.com/opentracing/opentracing-go"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
 )
 
 type Client struct {
 	service    services.Service
+	conn       *grpc.ClientConn
 	grpcClient querybackendv1.QueryBackendServiceClient
 }
 
@@ -25,6 +30,7 @@ func New(address string, grpcClientConfig grpcclient.Config) (*Client, error) {
 	var c Client
 	c.grpcClient = querybackendv1.NewQueryBackendServiceClient(conn)
 	c.service = services.NewIdleService(c.starting, c.stopping)
+	c.conn = conn
 	return &c, nil
 }
 
@@ -38,6 +44,7 @@ func dial(address string, grpcClientConfig grpcclient.Config) (*grpc.ClientConn,
 	// TODO: https://github.com/grpc/grpc-proto/blob/master/grpc/service_config/service_config.proto
 	options = append(options,
 		grpc.WithUnaryInterceptor(otgrpc.OpenTracingClientInterceptor(opentracing.GlobalTracer())),
+		grpc.WithStreamInterceptor(otgrpc.OpenTracingStreamClientInterceptor(opentracing.GlobalTracer())),
 		grpc.WithDefaultServiceConfig(grpcServiceConfig),
 	)
 	return grpc.Dial(address, options...)
@@ -45,12 +52,91 @@ func dial(address string, grpcClientConfig grpcclient.Config) (*grpc.ClientConn,
 
 func (b *Client) Service() services.Service      { return b.service }
 func (b *Client) starting(context.Context) error { return nil }
-func (b *Client) stopping(error) error           { return nil }
+func (b *Client) stopping(error) error           { return b.Close() }
+
+// Close closes the connection of the client. It is called when the
+// service stops, and is only needed if the service is never started.
+func (b *Client) Close() error { return b.conn.Close() }
 
 func (b *Client) Invoke(ctx context.Context, req *querybackendv1.InvokeRequest) (*querybackendv1.InvokeResponse, error) {
 	return b.grpcClient.Invoke(ctx, req)
 }
 
+// maxStreamResumes is the number of times an interrupted
+// stream is resumed before the query fails.
+const maxStreamResumes = 3
+
+// InvokeChunks executes the query over the InvokeStream RPC, and calls
+// the function for each of the chunks received. If the stream is
+// interrupted, e.g. the connection is lost, it is resumed after the last
+// chunk received: the chunks that have already been delivered are skipped.
+func (b *Client) InvokeChunks(
+	ctx context.Context,
+	req *querybackendv1.InvokeRequest,
+	fn func(*querybackendv1.InvokeResponse) error,
+) error {
+	var (
+		sequence int64
+		// The stream can't be resumed if the chunks are not numbered.
+		unnumbered bool
+		failed     bool
+	)
+	deliver := func(resp *querybackendv1.InvokeResponse) error {
+		if resp.Sequence == 0 {
+			unnumbered = true
+		} else if resp.Sequence <= sequence {
+			return nil
+		} else {
+			sequence = resp.Sequence
+		}
+		if err := fn(resp); err != nil {
+			failed = true
+			return err
+		}
+		return nil
+	}
+	for resumes := 0; ; resumes++ {
+		err := b.invokeStream(ctx, req, deliver)
+		if err == nil || failed || unnumbered || resumes == maxStreamResumes {
+			return err
+		}
+		// Only the streams interrupted by the transport are resumed.
+		if ctx.Err() != nil || status.Code(err) != codes.Unavailable {
+			return err
+		}
+		req = req.CloneVT()
+		if req.Options == nil {
+			req.Options = new(querybackendv1.InvokeOptions)
+		}
+		req.Options.StreamResumeAfter = sequence
+	}
+}
+
+func (b *Client) invokeStream(
+	ctx context.Context,
+	req *querybackendv1.InvokeRequest,
+	fn func(*querybackendv1.InvokeResponse) error,
+) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := b.grpcClient.InvokeStream(ctx, req)
+	if err != nil {
+		return err
+	}
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = fn(resp); err != nil {
+			return err
+		}
+	}
+}
+
 const grpcServiceConfig = `{
     "loadBalancingPolicy":"round_robin",
     "methodConfig": [{
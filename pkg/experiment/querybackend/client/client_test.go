@@ -0,0 +1,127 @@
+package querybackendclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+)
+
+type mockStream struct {
+	grpc.ClientStream
+	chunks []*querybackendv1.InvokeResponse
+	err    error
+}
+
+func (s *mockStream) Recv() (*querybackendv1.InvokeResponse, error) {
+	if len(s.chunks) == 0 {
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, io.EOF
+	}
+	c := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	return c, nil
+}
+
+// mockBackend responds with the streams in order,
+// and records the requests of the streams.
+type mockBackend struct {
+	querybackendv1.QueryBackendServiceClient
+	streams  []*mockStream
+	requests []*querybackendv1.InvokeRequest
+}
+
+func (b *mockBackend) InvokeStream(_ context.Context, req *querybackendv1.InvokeRequest, _ ...grpc.CallOption) (querybackendv1.QueryBackendService_InvokeStreamClient, error) {
+	b.requests = append(b.requests, req)
+	s := b.streams[0]
+	b.streams = b.streams[1:]
+	return s, nil
+}
+
+func chunks(sequences ...int64) []*querybackendv1.InvokeResponse {
+	c := make([]*querybackendv1.InvokeResponse, len(sequences))
+	for i, s := range sequences {
+		c[i] = &querybackendv1.InvokeResponse{Sequence: s}
+	}
+	return c
+}
+
+func Test_InvokeChunks_Resume(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "connection reset")
+	backend := &mockBackend{streams: []*mockStream{
+		{chunks: chunks(1, 2), err: unavailable},
+		// The backend may deliver the chunks received again.
+		{chunks: chunks(2, 3), err: unavailable},
+		{chunks: chunks(4, 5)},
+	}}
+	c := &Client{grpcClient: backend}
+
+	req := &querybackendv1.InvokeRequest{Tenant: []string{"tenant"}}
+	var received []int64
+	err := c.InvokeChunks(context.Background(), req, func(resp *querybackendv1.InvokeResponse) error {
+		received = append(received, resp.Sequence)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 2, 3, 4, 5}, received)
+
+	require.Len(t, backend.requests, 3)
+	require.Zero(t, backend.requests[0].Options.GetStreamResumeAfter())
+	require.Equal(t, int64(2), backend.requests[1].Options.GetStreamResumeAfter())
+	require.Equal(t, int64(3), backend.requests[2].Options.GetStreamResumeAfter())
+	require.Equal(t, req.Tenant, backend.requests[2].Tenant)
+	// The request of the caller is not modified.
+	require.Nil(t, req.Options)
+}
+
+func Test_InvokeChunks_NotResumed(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "connection reset")
+	for _, tc := range []struct {
+		name    string
+		streams []*mockStream
+		fn      func(*querybackendv1.InvokeResponse) error
+	}{
+		{
+			name:    "not unavailable",
+			streams: []*mockStream{{chunks: chunks(1), err: status.Error(codes.Internal, "failed")}},
+		},
+		{
+			name:    "not numbered",
+			streams: []*mockStream{{chunks: chunks(0), err: unavailable}},
+		},
+		{
+			name:    "function failed",
+			streams: []*mockStream{{chunks: chunks(1), err: unavailable}},
+			fn:      func(*querybackendv1.InvokeResponse) error { return errors.New("failed") },
+		},
+		{
+			name: "too many resumes",
+			streams: []*mockStream{
+				{err: unavailable},
+				{err: unavailable},
+				{err: unavailable},
+				{err: unavailable},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			backend := &mockBackend{streams: tc.streams}
+			c := &Client{grpcClient: backend}
+			fn := tc.fn
+			if fn == nil {
+				fn = func(*querybackendv1.InvokeResponse) error { return nil }
+			}
+			require.Error(t, c.InvokeChunks(context.Background(), new(querybackendv1.InvokeRequest), fn))
+			require.Empty(t, backend.streams)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package querybackend
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// concurrencyLimit bounds the number of operations running concurrently,
+// and tracks the number of the operations in progress. A non-positive
+// limit means that the operations are not limited, but still tracked.
+type concurrencyLimit struct {
+	sem        chan struct{}
+	inProgress prometheus.Gauge
+}
+
+func newConcurrencyLimit(n int, inProgress prometheus.Gauge) *concurrencyLimit {
+	l := &concurrencyLimit{inProgress: inProgress}
+	if n > 0 {
+		l.sem = make(chan struct{}, n)
+	}
+	return l
+}
+
+// acquire blocks until the operation is allowed to run,
+// or the context is done. The release must be called once
+// the operation completes, unless an error is returned.
+func (l *concurrencyLimit) acquire(ctx context.Context) error {
+	if l.sem != nil {
+		select {
+		case l.sem <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	l.inProgress.Inc()
+	return nil
+}
+
+func (l *concurrencyLimit) release() {
+	l.inProgress.Dec()
+	if l.sem != nil {
+		<-l.sem
+	}
+}
+
+// run runs the function once the operation is allowed to run.
+func (l *concurrencyLimit) run(ctx context.Context, fn func() error) error {
+	if err := l.acquire(ctx); err != nil {
+		return err
+	}
+	defer l.release()
+	return fn()
+}
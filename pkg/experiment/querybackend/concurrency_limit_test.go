@@ -0,0 +1,67 @@
+package querybackend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ConcurrencyLimit(t *testing.T) {
+	inProgress := prometheus.NewGauge(prometheus.GaugeOpts{})
+	l := newConcurrencyLimit(1, inProgress)
+	ctx := context.Background()
+
+	require.NoError(t, l.acquire(ctx))
+	require.Equal(t, float64(1), testutil.ToFloat64(inProgress))
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, l.acquire(timeout), context.DeadlineExceeded)
+	require.Equal(t, float64(1), testutil.ToFloat64(inProgress))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = l.run(ctx, func() error { return nil })
+	}()
+	select {
+	case <-done:
+		t.Fatal("the limit is not enforced")
+	case <-time.After(10 * time.Millisecond):
+	}
+	l.release()
+	<-done
+	require.Zero(t, testutil.ToFloat64(inProgress))
+}
+
+func Test_ConcurrencyLimit_Unlimited(t *testing.T) {
+	inProgress := prometheus.NewGauge(prometheus.GaugeOpts{})
+	l := newConcurrencyLimit(0, inProgress)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, l.acquire(context.Background()))
+	}
+	require.Equal(t, float64(3), testutil.ToFloat64(inProgress))
+	for i := 0; i < 3; i++ {
+		l.release()
+	}
+	require.Zero(t, testutil.ToFloat64(inProgress))
+}
+
+func Test_QueryBackend_SharedAggregationLimit(t *testing.T) {
+	config := Config{MaxConcurrentAggregations: 1}
+	reader := NewBlockReader(log.NewNopLogger(), nil, nil, config)
+	q, err := New(config, log.NewNopLogger(), nil, nil, reader, nil)
+	require.NoError(t, err)
+	require.Same(t, reader.aggregations, q.aggregations)
+
+	require.NoError(t, q.aggregations.acquire(context.Background()))
+	defer q.aggregations.release()
+	timeout, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, reader.aggregations.acquire(timeout), context.DeadlineExceeded)
+}
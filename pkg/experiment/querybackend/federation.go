@@ -0,0 +1,178 @@
+package querybackend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/opentracing/opentracing-go"
+	"golang.org/x/sync/errgroup"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+	"github.com/grafana/pyroscope/pkg/util"
+)
+
+var errIncompatibleRegion = errors.New("incompatible region response")
+
+// Region is a member of a federation: an independent deployment
+// that executes the queries against its own data.
+type Region struct {
+	Name string
+	// Handler executes the query in the region, e.g. the client of
+	// the entry point that plans the query against the blocks of the
+	// region. The request is passed as is, including the query plan,
+	// if any.
+	Handler QueryHandler
+	// Timeout of the queries of the region. Zero means
+	// no timeout other than the deadline of the query.
+	Timeout time.Duration
+}
+
+// Federation dispatches the queries to all the regions and merges the
+// responses, as if the data of the regions were queried at once. The
+// query fails only if none of the regions responds: otherwise, the
+// regions that failed are listed in the response diagnostics.
+type Federation struct {
+	logger  log.Logger
+	regions []Region
+}
+
+func NewFederation(logger log.Logger, regions ...Region) (*Federation, error) {
+	if len(regions) == 0 {
+		return nil, fmt.Errorf("federation: no regions")
+	}
+	seen := make(map[string]struct{}, len(regions))
+	for _, r := range regions {
+		if r.Name == "" {
+			return nil, fmt.Errorf("federation: region name is required")
+		}
+		if r.Handler == nil {
+			return nil, fmt.Errorf("federation: region %s: handler is required", r.Name)
+		}
+		if r.Timeout < 0 {
+			return nil, fmt.Errorf("federation: region %s: timeout must be non-negative", r.Name)
+		}
+		if _, ok := seen[r.Name]; ok {
+			return nil, fmt.Errorf("federation: region %s: duplicate region", r.Name)
+		}
+		seen[r.Name] = struct{}{}
+	}
+	return &Federation{logger: logger, regions: slices.Clone(regions)}, nil
+}
+
+func (f *Federation) Invoke(
+	ctx context.Context,
+	request *querybackendv1.InvokeRequest,
+) (*querybackendv1.InvokeResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Federation.Invoke")
+	defer span.Finish()
+	withQueryID(span, request)
+
+	// All the regions receive the same request; as in the case of the
+	// sub-queries, the reports of the regions are partial, and the
+	// baseline is only applied to the merged trees.
+	template := request.CloneVT()
+	if template.Options == nil {
+		template.Options = new(querybackendv1.InvokeOptions)
+	}
+	template.Options.Partial = true
+	stripTreeBaseline(template)
+	m := newAggregator(f.logger, request)
+	var (
+		mu        sync.Mutex
+		failed    []*querybackendv1.FailedRegion
+		responded int
+	)
+	fail := func(region string, err error) {
+		level.Warn(f.logger).Log("msg", "federated query failed", queryIDLabel, request.GetOptions().GetQueryId(), "region", region, "err", err)
+		mu.Lock()
+		failed = append(failed, &querybackendv1.FailedRegion{Region: region, Message: err.Error()})
+		mu.Unlock()
+	}
+	g, gctx := errgroup.WithContext(ctx)
+	for _, r := range f.regions {
+		r := r
+		g.Go(util.RecoverPanic(func() error {
+			resp, err := f.invokeRegion(gctx, r, template.CloneVT())
+			if err == nil {
+				err = checkRegionResponse(template, resp)
+			}
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				fail(r.Name, err)
+				return nil
+			}
+			// The diagnostics of the region are included as is: a region
+			// may be a federation itself.
+			mu.Lock()
+			failed = append(failed, resp.Diagnostics.GetFailedRegions()...)
+			responded++
+			mu.Unlock()
+			return m.aggregateResponse(resp, nil)
+		}))
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	slices.SortFunc(failed, func(a, b *querybackendv1.FailedRegion) int {
+		return strings.Compare(a.Region, b.Region)
+	})
+	if responded == 0 {
+		return nil, fmt.Errorf("federation: all regions failed: %s: %s", failed[0].Region, failed[0].Message)
+	}
+	resp, err := m.response()
+	if err != nil || len(failed) == 0 {
+		return resp, err
+	}
+	if resp.Diagnostics == nil {
+		resp.Diagnostics = new(querybackendv1.Diagnostics)
+	}
+	resp.Diagnostics.FailedRegions = failed
+	return resp, nil
+}
+
+func (f *Federation) invokeRegion(
+	ctx context.Context,
+	region Region,
+	req *querybackendv1.InvokeRequest,
+) (*querybackendv1.InvokeResponse, error) {
+	if region.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, region.Timeout)
+		defer cancel()
+	}
+	return region.Handler.Invoke(ctx, req)
+}
+
+// checkRegionResponse verifies that the tree reports of the region can be
+// merged with the others. A region running a different version may ignore
+// parameters of the query it does not support, or serialize the trees in
+// a format this version can't read: merging such reports would give
+// subtly wrong results, or fail the query in all the regions.
+func checkRegionResponse(req *querybackendv1.InvokeRequest, resp *querybackendv1.InvokeResponse) error {
+	for _, r := range resp.Reports {
+		if r.GetTree() == nil {
+			continue
+		}
+		expected := treeQuery(req)
+		if req.Options.GetBatch() && int(r.QueryIndex) < len(req.Query) {
+			expected = req.Query[r.QueryIndex].Tree
+		}
+		if v := reportFormatVersion(r.Tree); v > model.TreeFormatLatest {
+			return fmt.Errorf("%w: unsupported tree format version %d", errIncompatibleRegion, v)
+		}
+		if expected != nil && !treeQueryOutputParams(expected).EqualVT(treeQueryOutputParams(r.Tree.Query)) {
+			return fmt.Errorf("%w: %v, expected %v", errIncompatibleRegion, r.Tree.Query, expected)
+		}
+	}
+	return nil
+}
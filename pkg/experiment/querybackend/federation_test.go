@@ -0,0 +1,79 @@
+package querybackend
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+type queryHandlerFunc func(context.Context, *querybackendv1.InvokeRequest) (*querybackendv1.InvokeResponse, error)
+
+func (fn queryHandlerFunc) Invoke(ctx context.Context, req *querybackendv1.InvokeRequest) (*querybackendv1.InvokeResponse, error) {
+	return fn(ctx, req)
+}
+
+// testRegion plans the query against the blocks of the region.
+func testRegion(t *testing.T, blocks ...string) QueryHandler {
+	b := newTestQueryBackend(t, Config{}, &testBlockReader{slow: map[string]struct{}{"slow": {}}})
+	return queryHandlerFunc(func(ctx context.Context, req *querybackendv1.InvokeRequest) (*querybackendv1.InvokeResponse, error) {
+		req.QueryPlan = newTestTreeRequest(blocks...).QueryPlan
+		return b.Invoke(ctx, req)
+	})
+}
+
+func Test_Federation(t *testing.T) {
+	failing := queryHandlerFunc(func(context.Context, *querybackendv1.InvokeRequest) (*querybackendv1.InvokeResponse, error) {
+		return nil, errors.New("unavailable")
+	})
+	incompatible := queryHandlerFunc(func(context.Context, *querybackendv1.InvokeRequest) (*querybackendv1.InvokeResponse, error) {
+		// The region runs a newer version.
+		return &querybackendv1.InvokeResponse{Reports: []*querybackendv1.Report{{
+			ReportType: querybackendv1.ReportType_REPORT_TREE,
+			Tree: &querybackendv1.TreeReport{
+				Query:         &querybackendv1.TreeQuery{},
+				FormatVersion: model.TreeFormatLatest + 1,
+			},
+		}}}, nil
+	})
+
+	f, err := NewFederation(log.NewNopLogger(),
+		Region{Name: "us", Handler: testRegion(t, "a", "b")},
+		Region{Name: "eu", Handler: testRegion(t, "c")},
+		Region{Name: "ap", Handler: testRegion(t, "slow"), Timeout: 100 * time.Millisecond},
+		Region{Name: "sa", Handler: failing},
+		Region{Name: "af", Handler: incompatible},
+	)
+	require.NoError(t, err)
+
+	resp, err := f.Invoke(context.Background(), newTestTreeRequest())
+	require.NoError(t, err)
+	require.Len(t, resp.Reports, 1)
+
+	expected := new(model.Tree)
+	expected.InsertStack(1, "main", "a")
+	expected.InsertStack(1, "main", "b")
+	expected.InsertStack(1, "main", "c")
+	require.Equal(t, expected.String(), model.MustUnmarshalTree(resp.Reports[0].Tree.Tree).String())
+
+	var failed []string
+	for _, r := range resp.Diagnostics.GetFailedRegions() {
+		failed = append(failed, r.Region)
+	}
+	require.Equal(t, []string{"af", "ap", "sa"}, failed)
+
+	// The query fails if none of the regions responds.
+	f, err = NewFederation(log.NewNopLogger(), Region{Name: "sa", Handler: failing})
+	require.NoError(t, err)
+	_, err = f.Invoke(context.Background(), newTestTreeRequest())
+	require.ErrorContains(t, err, "all regions failed")
+
+	_, err = NewFederation(log.NewNopLogger(), Region{Name: "sa", Handler: failing}, Region{Name: "sa", Handler: failing})
+	require.Error(t, err)
+}
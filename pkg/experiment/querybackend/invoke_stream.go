@@ -0,0 +1,301 @@
+package querybackend
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opentracing/opentracing-go"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+// ChunkedQueryHandler is a QueryHandler that delivers the response in
+// chunks, e.g. over the InvokeStream RPC: the chunks are aggregated as
+// they are received, and the response is never held as a whole. The
+// chunk size is specified in the request options.
+type ChunkedQueryHandler interface {
+	QueryHandler
+	// InvokeChunks calls the function for each of the chunks of the
+	// response. If the function fails, the query is canceled.
+	InvokeChunks(context.Context, *querybackendv1.InvokeRequest, func(*querybackendv1.InvokeResponse) error) error
+}
+
+func (q *QueryBackend) InvokeStream(
+	req *querybackendv1.InvokeRequest,
+	stream querybackendv1.QueryBackendService_InvokeStreamServer,
+) error {
+	return q.InvokeChunks(stream.Context(), req, stream.Send)
+}
+
+// InvokeChunks executes the query, and delivers the response in chunks.
+// The reports are sent as they are built by the aggregator: the response
+// is never held as a whole, and a report is released once it is sent.
+// The chunks are numbered, see sequenceChunks.
+func (q *QueryBackend) InvokeChunks(
+	ctx context.Context,
+	req *querybackendv1.InvokeRequest,
+	fn func(*querybackendv1.InvokeResponse) error,
+) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "QueryBackend.InvokeChunks")
+	defer span.Finish()
+	withQueryID(span, req)
+	return q.invoke(ctx, req, true, sequenceChunks(req, fn))
+}
+
+// sequenceChunks numbers the chunks of the response, starting from one.
+// If the request resumes a stream, the chunks up to the one it resumes
+// after are not delivered: the response is built again, and the reports
+// are built in the same order, split into the same chunks. The request
+// option is cleared, so that it does not apply to the sub-queries.
+func sequenceChunks(
+	req *querybackendv1.InvokeRequest,
+	fn func(*querybackendv1.InvokeResponse) error,
+) func(*querybackendv1.InvokeResponse) error {
+	resumeAfter := req.Options.GetStreamResumeAfter()
+	if req.Options != nil {
+		req.Options.StreamResumeAfter = 0
+	}
+	var sequence int64
+	return func(chunk *querybackendv1.InvokeResponse) error {
+		sequence++
+		if sequence <= resumeAfter {
+			return nil
+		}
+		chunk.Sequence = sequence
+		return fn(chunk)
+	}
+}
+
+// InvokeChunks is like Invoke, but the response is delivered in
+// chunks, as the reports are built, see QueryBackend.InvokeChunks.
+func (b *BlockReader) InvokeChunks(
+	ctx context.Context,
+	req *querybackendv1.InvokeRequest,
+	fn func(*querybackendv1.InvokeResponse) error,
+) error {
+	m, err := b.aggregate(ctx, req)
+	if err != nil {
+		return err
+	}
+	return m.responseChunks(req.Options.GetStreamChunkSize(), fn)
+}
+
+// InvokeChunked executes the query with the handler, and assembles the
+// response from the chunks as they are received: the continuations of
+// a tree report are merged into the report they continue. The reports
+// are not aggregated otherwise: the response is the one the handler
+// responds with to Invoke.
+func InvokeChunked(
+	ctx context.Context,
+	h ChunkedQueryHandler,
+	req *querybackendv1.InvokeRequest,
+) (*querybackendv1.InvokeResponse, error) {
+	a := responseAssembler{resp: new(querybackendv1.InvokeResponse)}
+	if err := h.InvokeChunks(ctx, req, a.add); err != nil {
+		return nil, err
+	}
+	a.flush()
+	return a.resp, nil
+}
+
+type responseAssembler struct {
+	resp *querybackendv1.InvokeResponse
+	// Sequence number of the last chunk added.
+	sequence int64
+	// The tree of the last report, if it is continued.
+	tree    *model.Tree
+	version int
+}
+
+func (a *responseAssembler) add(chunk *querybackendv1.InvokeResponse) error {
+	if chunk.Sequence != 0 {
+		if chunk.Sequence <= a.sequence {
+			// The chunk has been delivered again, e.g.
+			// when the stream is resumed: it is skipped.
+			return nil
+		}
+		a.sequence = chunk.Sequence
+	}
+	for _, r := range chunk.Reports {
+		if !r.GetTree().GetContinuation() {
+			a.flush()
+			a.resp.Reports = append(a.resp.Reports, r)
+			continue
+		}
+		n := len(a.resp.Reports)
+		if n == 0 || a.resp.Reports[n-1].Tree == nil || keyOf(a.resp.Reports[n-1]) != keyOf(r) {
+			return fmt.Errorf("tree report continuation does not follow the report")
+		}
+		if a.tree == nil {
+			last := a.resp.Reports[n-1].Tree
+			var err error
+			a.version = reportFormatVersion(last)
+			if a.tree, err = model.UnmarshalTreeVersion(last.Tree, a.version); err != nil {
+				return err
+			}
+		}
+		part, err := model.UnmarshalTreeVersion(r.Tree.Tree, a.version)
+		if err != nil {
+			return err
+		}
+		a.tree.Merge(part)
+	}
+	if chunk.Diagnostics != nil {
+		a.resp.Diagnostics = chunk.Diagnostics
+	}
+	return nil
+}
+
+// flush serializes the tree of the last report, if it has been continued.
+func (a *responseAssembler) flush() {
+	if a.tree == nil {
+		return
+	}
+	last := a.resp.Reports[len(a.resp.Reports)-1].Tree
+	last.Tree = a.tree.BytesVersion(0, a.version)
+	a.tree = nil
+}
+
+// invokeSubQuery executes the sub-query, and calls the function for the
+// response, or for each of its chunks, if the handler supports them and
+// the request specifies the chunk size.
+func (q *QueryBackend) invokeSubQuery(
+	ctx context.Context,
+	req *querybackendv1.InvokeRequest,
+	fn func(*querybackendv1.InvokeResponse) error,
+) error {
+	if h, ok := q.backendClient.(ChunkedQueryHandler); ok && req.Options.GetStreamChunkSize() > 0 {
+		return h.InvokeChunks(ctx, req, fn)
+	}
+	resp, err := q.backendClient.Invoke(ctx, req)
+	if err != nil {
+		return err
+	}
+	return fn(resp)
+}
+
+// splitResponse splits the response into chunks of approximately the
+// given size. The reports are sent in the order of the response, and
+// the large trees are split into parts. The diagnostics are included
+// in the last chunk.
+func splitResponse(resp *querybackendv1.InvokeResponse, chunkSize int64) []*querybackendv1.InvokeResponse {
+	if chunkSize <= 0 {
+		return []*querybackendv1.InvokeResponse{resp}
+	}
+	var chunks []*querybackendv1.InvokeResponse
+	c := newResponseChunker(chunkSize, func(chunk *querybackendv1.InvokeResponse) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	for _, r := range resp.Reports {
+		_ = c.add(r)
+	}
+	_ = c.flush(resp.Diagnostics)
+	return chunks
+}
+
+// responseChunker groups the reports into the chunks of approximately
+// the given size, and calls the function for each of them. If the size
+// is not positive, all the reports are delivered in a single chunk.
+type responseChunker struct {
+	chunkSize int64
+	fn        func(*querybackendv1.InvokeResponse) error
+	chunk     *querybackendv1.InvokeResponse
+	size      int64
+}
+
+func newResponseChunker(chunkSize int64, fn func(*querybackendv1.InvokeResponse) error) *responseChunker {
+	return &responseChunker{
+		chunkSize: chunkSize,
+		fn:        fn,
+		chunk:     new(querybackendv1.InvokeResponse),
+	}
+}
+
+func (c *responseChunker) add(r *querybackendv1.Report) error {
+	if c.chunkSize <= 0 {
+		c.chunk.Reports = append(c.chunk.Reports, r)
+		return nil
+	}
+	for _, part := range splitTreeReport(r, c.chunkSize) {
+		s := int64(part.SizeVT())
+		if c.size > 0 && c.size+s > c.chunkSize {
+			if err := c.fn(c.chunk); err != nil {
+				return err
+			}
+			c.chunk = new(querybackendv1.InvokeResponse)
+			c.size = 0
+		}
+		c.chunk.Reports = append(c.chunk.Reports, part)
+		c.size += s
+	}
+	return nil
+}
+
+// flush delivers the last chunk, along with the diagnostics.
+func (c *responseChunker) flush(diagnostics *querybackendv1.Diagnostics) error {
+	c.chunk.Diagnostics = diagnostics
+	return c.fn(c.chunk)
+}
+
+// splitTreeReport splits the tree of the report into parts of
+// approximately the given size. The first part retains the rest of the
+// report fields; the others are the continuations of the report. Only
+// the trees which values are summed up can be split: the parts are
+// merged back by the aggregator of the receiver.
+func splitTreeReport(r *querybackendv1.Report, chunkSize int64) []*querybackendv1.Report {
+	t := r.Tree
+	if t == nil || int64(len(t.Tree)) <= chunkSize || !splittableTree(t.Query) {
+		return []*querybackendv1.Report{r}
+	}
+	version := reportFormatVersion(t)
+	tree, err := model.UnmarshalTreeVersion(t.Tree, version)
+	if err != nil {
+		// The receiver fails to merge the tree as well.
+		return []*querybackendv1.Report{r}
+	}
+	// The parts are of approximately the same number of nodes:
+	// the size of the nodes is assumed to be uniform.
+	maxNodes := max(1, tree.Size()*chunkSize/int64(len(t.Tree)))
+	parts := tree.Split(maxNodes)
+	if len(parts) < 2 {
+		return []*querybackendv1.Report{r}
+	}
+	b := t.Tree
+	t.Tree = nil
+	first := r.CloneVT()
+	t.Tree = b
+	first.Tree.Tree = parts[0].BytesVersion(0, version)
+	reports := make([]*querybackendv1.Report, 0, len(parts))
+	reports = append(reports, first)
+	for _, part := range parts[1:] {
+		reports = append(reports, &querybackendv1.Report{
+			ReportType: r.ReportType,
+			QueryType:  r.QueryType,
+			QueryIndex: r.QueryIndex,
+			Tree: &querybackendv1.TreeReport{
+				Query:         t.Query,
+				Tree:          part.BytesVersion(0, version),
+				FormatVersion: t.FormatVersion,
+				Continuation:  true,
+			},
+		})
+	}
+	return reports
+}
+
+func splittableTree(query *querybackendv1.TreeQuery) bool {
+	// The representative trees are not merged.
+	if query.GetRepresentative() {
+		return false
+	}
+	// The copies of the ancestors in the parts have no self value,
+	// which is only neutral to the sum.
+	switch query.GetValueMerge() {
+	case "", TreeValueMergeSum:
+		return true
+	default:
+		return false
+	}
+}
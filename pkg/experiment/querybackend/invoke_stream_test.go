@@ -0,0 +1,153 @@
+package querybackend
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+func newTestLargeTree(root string) *model.Tree {
+	tree := new(model.Tree)
+	for i := 0; i < 32; i++ {
+		for j := 0; j < 8; j++ {
+			tree.InsertStack(int64(i+j+1), root, fmt.Sprintf("f%d", i), fmt.Sprintf("g%d", j))
+		}
+	}
+	return tree
+}
+
+// largeTreeReader responds with a large tree report per block.
+var largeTreeReader = queryHandlerFunc(func(_ context.Context, req *querybackendv1.InvokeRequest) (*querybackendv1.InvokeResponse, error) {
+	resp := new(querybackendv1.InvokeResponse)
+	for _, b := range req.QueryPlan.Blocks {
+		resp.Reports = append(resp.Reports, &querybackendv1.Report{
+			ReportType: querybackendv1.ReportType_REPORT_TREE,
+			Tree: &querybackendv1.TreeReport{
+				Query: &querybackendv1.TreeQuery{},
+				Tree:  newTestLargeTree(b.Id).Bytes(-1),
+			},
+		})
+	}
+	return resp, nil
+})
+
+func Test_SplitResponse(t *testing.T) {
+	tree := newTestLargeTree("main")
+	b := tree.Bytes(-1)
+	resp := &querybackendv1.InvokeResponse{
+		Reports: []*querybackendv1.Report{{
+			ReportType: querybackendv1.ReportType_REPORT_TREE,
+			Tree: &querybackendv1.TreeReport{
+				Query: &querybackendv1.TreeQuery{},
+				Tree:  b,
+				Nodes: tree.Size(),
+			},
+		}},
+		Diagnostics: &querybackendv1.Diagnostics{SkippedBlocksCount: 1},
+	}
+	require.Equal(t, []*querybackendv1.InvokeResponse{resp}, splitResponse(resp, 0))
+	require.Len(t, splitResponse(resp, int64(2*len(b))), 1)
+
+	chunkSize := int64(len(b) / 4)
+	chunks := splitResponse(resp, chunkSize)
+	require.Greater(t, len(chunks), 3)
+	// The response is not modified.
+	require.Equal(t, b, resp.Reports[0].Tree.Tree)
+
+	m := newAggregator(log.NewNopLogger(), new(querybackendv1.InvokeRequest))
+	for i, c := range chunks {
+		require.Len(t, c.Reports, 1)
+		require.Equal(t, i > 0, c.Reports[0].Tree.Continuation)
+		require.Less(t, int64(c.SizeVT()), 2*chunkSize)
+		if i < len(chunks)-1 {
+			require.Nil(t, c.Diagnostics)
+		}
+		require.NoError(t, m.aggregateResponse(c, nil))
+	}
+	require.Equal(t, resp.Diagnostics.SkippedBlocksCount, chunks[len(chunks)-1].Diagnostics.SkippedBlocksCount)
+	merged, err := m.response()
+	require.NoError(t, err)
+	require.Equal(t, tree.String(), model.MustUnmarshalTree(merged.Reports[0].Tree.Tree).String())
+
+	// The trees of the representative queries are not split.
+	resp.Reports[0].Tree.Query.Representative = true
+	require.Len(t, splitResponse(resp, chunkSize), 1)
+}
+
+func Test_QueryBackend_StreamChunks(t *testing.T) {
+	blocks := []string{"a", "b", "c", "d"}
+	expected, err := newTestQueryBackend(t, Config{}, largeTreeReader).Invoke(context.Background(), newTestTreeRequest(blocks...))
+	require.NoError(t, err)
+
+	// The continuations are not counted as tree reports.
+	b := newTestQueryBackend(t, Config{StreamChunkSize: 256, MaxTreeReports: int64(len(blocks))}, largeTreeReader)
+	var chunks int
+	err = b.InvokeChunks(context.Background(), newTestTreeRequest(blocks...), func(*querybackendv1.InvokeResponse) error {
+		chunks++
+		return nil
+	})
+	require.NoError(t, err)
+	require.Greater(t, chunks, 1)
+
+	actual, err := b.Invoke(context.Background(), newTestTreeRequest(blocks...))
+	require.NoError(t, err)
+	require.Len(t, actual.Reports, 1)
+	require.Equal(t,
+		model.MustUnmarshalTree(expected.Reports[0].Tree.Tree).String(),
+		model.MustUnmarshalTree(actual.Reports[0].Tree.Tree).String())
+
+	// The chunks are assembled into the response of Invoke.
+	assembled, err := InvokeChunked(context.Background(), b, newTestTreeRequest(blocks...))
+	require.NoError(t, err)
+	require.Equal(t, actual.String(), assembled.String())
+
+	require.Error(t, newTreeAggregator(new(querybackendv1.InvokeRequest)).aggregate(&querybackendv1.Report{
+		Tree: &querybackendv1.TreeReport{Query: &querybackendv1.TreeQuery{}, Continuation: true},
+	}))
+}
+
+func Test_QueryBackend_StreamResume(t *testing.T) {
+	blocks := []string{"a", "b", "c", "d"}
+	b := newTestQueryBackend(t, Config{StreamChunkSize: 256}, largeTreeReader)
+	var chunks []*querybackendv1.InvokeResponse
+	err := b.InvokeChunks(context.Background(), newTestTreeRequest(blocks...), func(c *querybackendv1.InvokeResponse) error {
+		chunks = append(chunks, c)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Greater(t, len(chunks), 2)
+	for i, c := range chunks {
+		require.Equal(t, int64(i+1), c.Sequence)
+	}
+
+	// The stream is interrupted after the second chunk, and resumed:
+	// the chunks that follow are the ones of the original stream.
+	req := newTestTreeRequest(blocks...)
+	req.Options = &querybackendv1.InvokeOptions{StreamResumeAfter: 2}
+	var resumed []*querybackendv1.InvokeResponse
+	err = b.InvokeChunks(context.Background(), req, func(c *querybackendv1.InvokeResponse) error {
+		resumed = append(resumed, c)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, resumed, len(chunks)-2)
+	for i, c := range resumed {
+		require.Equal(t, chunks[i+2].String(), c.String())
+	}
+
+	// The chunks delivered twice are only assembled once.
+	a := responseAssembler{resp: new(querybackendv1.InvokeResponse)}
+	for _, c := range append(chunks[:2:2], append(chunks[1:2:2], resumed...)...) {
+		require.NoError(t, a.add(c))
+	}
+	a.flush()
+	expected, err := InvokeChunked(context.Background(), b, newTestTreeRequest(blocks...))
+	require.NoError(t, err)
+	require.Equal(t, expected.String(), a.resp.String())
+}
@@ -0,0 +1,195 @@
+package querybackend
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/util"
+)
+
+type metrics struct {
+	treeReportsLimitExceeded prometheus.Counter
+	treeReportsCapped        *prometheus.CounterVec
+	resolverReleaseDuration  prometheus.Histogram
+	readAheadFetchedBytes    prometheus.Counter
+	readAheadUsedBytes       prometheus.Counter
+	queryTimeouts            *prometheus.CounterVec
+	invalidSampleValues      prometheus.Counter
+	truncatedStacks          prometheus.Counter
+	cpuBudgetExceeded        prometheus.Counter
+	deduplicatedProfiles     prometheus.Counter
+	symbolsOverLimit         prometheus.Counter
+	sectionCacheSize         prometheus.Gauge
+	sectionCacheEntries      prometheus.Gauge
+	sectionCachePinned       prometheus.Gauge
+	sectionCacheHits         prometheus.Counter
+	sectionCacheMisses       prometheus.Counter
+	sectionCacheEvictions    prometheus.Counter
+	blockReadsInProgress     prometheus.Gauge
+	aggregationsInProgress   prometheus.Gauge
+	resolveIODuration        *prometheus.HistogramVec
+	resolveCPUDuration       *prometheus.HistogramVec
+	abandonedResolvers       prometheus.Gauge
+	sectionChecksumFailures  *prometheus.CounterVec
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		treeReportsLimitExceeded: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "pyroscope",
+			Name:      "query_backend_tree_reports_limit_exceeded_total",
+			Help:      "Number of queries failed because the tree aggregator received too many reports.",
+		}),
+		treeReportsCapped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "pyroscope",
+			Name:      "query_backend_tree_reports_capped_total",
+			Help:      "Number of the final tree reports truncated by the reason: the maximum number of nodes, or the maximum report size.",
+		}, []string{"reason"}),
+		resolverReleaseDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "pyroscope",
+			Name:      "symdb_resolver_release_seconds",
+			Help:      "Time spent releasing the symbols resolver after the query.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+		}),
+		readAheadFetchedBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "pyroscope",
+			Name:      "query_backend_parquet_read_ahead_fetched_bytes_total",
+			Help:      "Number of bytes of parquet column chunks prefetched.",
+		}),
+		readAheadUsedBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "pyroscope",
+			Name:      "query_backend_parquet_read_ahead_used_bytes_total",
+			Help:      "Number of bytes read from the prefetched parquet column chunks.",
+		}),
+		queryTimeouts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "pyroscope",
+			Name:      "query_backend_query_timeouts_total",
+			Help:      "Number of queries failed because the query type timeout was exceeded.",
+		}, []string{"query_type"}),
+		invalidSampleValues: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "pyroscope",
+			Name:      "query_backend_invalid_sample_values_total",
+			Help:      "Number of samples dropped because their values can't be represented in a tree.",
+		}),
+		truncatedStacks: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "pyroscope",
+			Name:      "query_backend_truncated_stacks_total",
+			Help:      "Number of stack traces truncated because they exceed the maximum resolve depth.",
+		}),
+		cpuBudgetExceeded: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "pyroscope",
+			Name:      "query_backend_cpu_budget_exceeded_total",
+			Help:      "Number of queries failed because the CPU time budget was exceeded.",
+		}),
+		deduplicatedProfiles: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "pyroscope",
+			Name:      "query_backend_deduplicated_profiles_total",
+			Help:      "Number of duplicate profiles skipped by the queries.",
+		}),
+		symbolsOverLimit: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "pyroscope",
+			Name:      "query_backend_symbols_over_limit_total",
+			Help:      "Number of datasets resolved into the location addresses by tree queries because the symbols section exceeds the size limit.",
+		}),
+		sectionCacheSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "pyroscope",
+			Name:      "query_backend_section_cache_size_bytes",
+			Help:      "Total size of the block sections held in the section cache.",
+		}),
+		sectionCacheEntries: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "pyroscope",
+			Name:      "query_backend_section_cache_entries",
+			Help:      "Number of the block sections held in the section cache.",
+		}),
+		sectionCachePinned: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "pyroscope",
+			Name:      "query_backend_section_cache_pinned_blocks",
+			Help:      "Number of the blocks pinned in the section cache.",
+		}),
+		sectionCacheHits: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "pyroscope",
+			Name:      "query_backend_section_cache_hits_total",
+			Help:      "Number of the pinned block sections found in the section cache.",
+		}),
+		sectionCacheMisses: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "pyroscope",
+			Name:      "query_backend_section_cache_misses_total",
+			Help:      "Number of the pinned block sections loaded from the object storage.",
+		}),
+		sectionCacheEvictions: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "pyroscope",
+			Name:      "query_backend_section_cache_evictions_total",
+			Help:      "Number of the block sections evicted from the section cache to make room for new ones.",
+		}),
+		blockReadsInProgress: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "pyroscope",
+			Name:      "query_backend_block_reads_in_progress",
+			Help:      "Number of the dataset queries being executed by the block reader.",
+		}),
+		aggregationsInProgress: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "pyroscope",
+			Name:      "query_backend_aggregations_in_progress",
+			Help:      "Number of the query reports being aggregated.",
+		}),
+		resolveIODuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "pyroscope",
+			Name:      "query_backend_resolve_io_seconds",
+			Help:      "Time spent reading the profiles of a dataset while resolving a tree.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 16),
+		}, []string{"query_type"}),
+		resolveCPUDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "pyroscope",
+			Name:      "query_backend_resolve_cpu_seconds",
+			Help:      "Time spent resolving the samples of the profiles of a dataset into a tree.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 16),
+		}, []string{"query_type"}),
+		abandonedResolvers: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "pyroscope",
+			Name:      "query_backend_abandoned_resolvers",
+			Help:      "Number of the tree resolutions abandoned on the query context expiry that have not completed yet.",
+		}),
+		sectionChecksumFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "pyroscope",
+			Name:      "query_backend_section_checksum_failures_total",
+			Help:      "Number of the dataset sections which data does not match the checksum, by the section.",
+		}, []string{"section"}),
+	}
+	m.treeReportsLimitExceeded = util.RegisterOrGet(reg, m.treeReportsLimitExceeded)
+	m.treeReportsCapped = util.RegisterOrGet(reg, m.treeReportsCapped)
+	m.resolverReleaseDuration = util.RegisterOrGet(reg, m.resolverReleaseDuration)
+	m.readAheadFetchedBytes = util.RegisterOrGet(reg, m.readAheadFetchedBytes)
+	m.readAheadUsedBytes = util.RegisterOrGet(reg, m.readAheadUsedBytes)
+	m.queryTimeouts = util.RegisterOrGet(reg, m.queryTimeouts)
+	m.invalidSampleValues = util.RegisterOrGet(reg, m.invalidSampleValues)
+	m.truncatedStacks = util.RegisterOrGet(reg, m.truncatedStacks)
+	m.cpuBudgetExceeded = util.RegisterOrGet(reg, m.cpuBudgetExceeded)
+	m.deduplicatedProfiles = util.RegisterOrGet(reg, m.deduplicatedProfiles)
+	m.symbolsOverLimit = util.RegisterOrGet(reg, m.symbolsOverLimit)
+	m.sectionCacheSize = util.RegisterOrGet(reg, m.sectionCacheSize)
+	m.sectionCacheEntries = util.RegisterOrGet(reg, m.sectionCacheEntries)
+	m.sectionCachePinned = util.RegisterOrGet(reg, m.sectionCachePinned)
+	m.sectionCacheHits = util.RegisterOrGet(reg, m.sectionCacheHits)
+	m.sectionCacheMisses = util.RegisterOrGet(reg, m.sectionCacheMisses)
+	m.sectionCacheEvictions = util.RegisterOrGet(reg, m.sectionCacheEvictions)
+	m.blockReadsInProgress = util.RegisterOrGet(reg, m.blockReadsInProgress)
+	m.aggregationsInProgress = util.RegisterOrGet(reg, m.aggregationsInProgress)
+	m.resolveIODuration = util.RegisterOrGet(reg, m.resolveIODuration)
+	m.resolveCPUDuration = util.RegisterOrGet(reg, m.resolveCPUDuration)
+	m.abandonedResolvers = util.RegisterOrGet(reg, m.abandonedResolvers)
+	m.sectionChecksumFailures = util.RegisterOrGet(reg, m.sectionChecksumFailures)
+	return m
+}
+
+// observeTreeReportCapping accounts the tree reports of the response
+// truncated by the size limit, or by the number of nodes otherwise.
+func (m *metrics) observeTreeReportCapping(resp *querybackendv1.InvokeResponse) {
+	for _, r := range resp.Reports {
+		switch t := r.Tree; {
+		case t == nil:
+		case t.SizeCapped:
+			m.treeReportsCapped.WithLabelValues("size").Inc()
+		case t.Truncated:
+			m.treeReportsCapped.WithLabelValues("nodes").Inc()
+		}
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/iancoleman/strcase"
 	"github.com/opentracing/opentracing-go"
+	"github.com/prometheus/prometheus/model/labels"
+	"go.uber.org/atomic"
 
 	metastorev1 "github.com/grafana/pyroscope/api/gen/proto/go/metastore/v1"
 	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
@@ -58,47 +61,150 @@ func registerQueryDependencies(t querybackendv1.QueryType, deps ...block.Section
 	queryDependencies[t] = deps
 }
 
+// Default query timeouts. Queries that only access the index are expected
+// to complete quickly, while the ones that resolve stack traces are only
+// bounded by the request deadline.
+const (
+	indexQueryTimeout = 30 * time.Second
+	noQueryTimeout    = 0
+)
+
+var (
+	timeoutMutex  = new(sync.RWMutex)
+	queryTimeouts = map[querybackendv1.QueryType]time.Duration{}
+)
+
+func registerQueryTimeout(t querybackendv1.QueryType, timeout time.Duration) {
+	timeoutMutex.Lock()
+	defer timeoutMutex.Unlock()
+	if _, ok := queryTimeouts[t]; ok {
+		panic(fmt.Sprintf("%s: timeout already registered", t))
+	}
+	queryTimeouts[t] = timeout
+}
+
+// queryTimeout returns the timeout of the query: the default timeout of
+// the query type, unless the query specifies a shorter one. Zero means
+// that the query is only bounded by the request deadline.
+func queryTimeout(query *querybackendv1.Query) time.Duration {
+	timeoutMutex.RLock()
+	timeout := queryTimeouts[query.QueryType]
+	timeoutMutex.RUnlock()
+	if query.TimeoutMs > 0 {
+		d := time.Duration(query.TimeoutMs) * time.Millisecond
+		if timeout == 0 || d < timeout {
+			timeout = d
+		}
+	}
+	return timeout
+}
+
 func registerQueryType(
 	qt querybackendv1.QueryType,
 	rt querybackendv1.ReportType,
 	q queryHandler,
 	a aggregatorProvider,
+	timeout time.Duration,
 	deps ...block.Section,
 ) {
 	registerQueryReportType(qt, rt)
 	registerQueryHandler(qt, q)
+	registerQueryTimeout(qt, timeout)
 	registerQueryDependencies(qt, deps...)
 	registerAggregator(rt, a)
 }
 
 type queryContext struct {
-	ctx  context.Context
-	log  log.Logger
-	meta *metastorev1.Dataset
-	req  *request
-	obj  *block.Object
-	ds   *block.Dataset
-	err  error
+	ctx     context.Context
+	log     log.Logger
+	metrics *metrics
+	meta    *metastorev1.Dataset
+	req     *request
+	obj     *block.Object
+	ds      *block.Dataset
+	err     error
+	// Type of the query being executed; set by executeQuery.
+	queryType querybackendv1.QueryType
+
+	// If set, the query fails on sample values that can't
+	// be represented in a tree; otherwise they are dropped.
+	failOnInvalidSampleValues bool
+	// If positive, the stack traces resolved
+	// are truncated at the depth.
+	maxResolveDepth int
+	// Stack groupings of the query backend configuration.
+	stackGroupings map[string]StackGrouping
+	// CPU time budget of the query, shared by the query
+	// contexts of all the datasets. Optional.
+	cpu *cpuBudget
+	// If positive, the stack traces of the dataset are not
+	// resolved, if the symbols section exceeds the size.
+	maxSymbolsSectionSize int64
+	// Profiles accounted by the query, shared by the query
+	// contexts of all the datasets. Set if the duplicate
+	// profiles must be skipped.
+	seen *profileSet
+	// If set, the values of the same stack trace are
+	// combined with the function, instead of being added.
+	valueMerge TreeValueMerge
+	// If positive, the profiles which total value is below
+	// the threshold are skipped; belowMinValue is set then.
+	minProfileValue int64
+	belowMinValue   *atomic.Bool
 }
 
 func newQueryContext(
 	ctx context.Context,
 	logger log.Logger,
+	metrics *metrics,
 	meta *metastorev1.Dataset,
 	req *request,
 	obj *block.Object,
 ) *queryContext {
 	return &queryContext{
-		ctx:  ctx,
-		log:  logger,
-		req:  req,
-		meta: meta,
-		obj:  obj,
-		ds:   block.NewDataset(meta, obj),
+		ctx:     ctx,
+		log:     log.With(logger, queryIDLabel, req.src.GetOptions().GetQueryId()),
+		metrics: metrics,
+		req:     req,
+		meta:    meta,
+		obj:     obj,
+		ds:      block.NewDataset(meta, obj),
 	}
 }
 
+// withMatchers returns a copy of the query context with
+// the label matchers added to the request label selector.
+func (q *queryContext) withMatchers(matchers ...*labels.Matcher) *queryContext {
+	r := *q.req
+	r.matchers = append(r.matchers[:len(r.matchers):len(r.matchers)], matchers...)
+	c := *q
+	c.req = &r
+	return &c
+}
+
+// withPartitions returns a copy of the query context that only selects
+// the profiles of the stack trace partitions given. If no partitions
+// are specified, the query context is returned as is.
+func (q *queryContext) withPartitions(partitions []uint64) *queryContext {
+	if len(partitions) == 0 {
+		return q
+	}
+	r := *q.req
+	r.partitions = make(map[uint64]struct{}, len(partitions))
+	for _, p := range partitions {
+		r.partitions[p] = struct{}{}
+	}
+	c := *q
+	c.req = &r
+	return &c
+}
+
 func executeQuery(q *queryContext, query *querybackendv1.Query) (r *querybackendv1.Report, err error) {
+	// The query context is shared by the queries of the
+	// dataset, therefore we make a copy of it.
+	c := *q
+	q = &c
+	q.queryType = query.QueryType
 	var span opentracing.Span
 	span, q.ctx = opentracing.StartSpanFromContext(q.ctx, "executeQuery."+strcase.ToCamel(query.QueryType.String()))
 	defer span.Finish()
@@ -106,6 +212,18 @@ func executeQuery(q *queryContext, query *querybackendv1.Query) (r *querybackend
 	if err != nil {
 		return nil, err
 	}
+	if timeout := queryTimeout(query); timeout > 0 {
+		parent := q.ctx
+		var cancel context.CancelFunc
+		q.ctx, cancel = context.WithTimeout(parent, timeout)
+		defer cancel()
+		defer func() {
+			if err != nil && q.ctx.Err() == context.DeadlineExceeded && parent.Err() == nil {
+				q.metrics.queryTimeouts.WithLabelValues(query.QueryType.String()).Inc()
+				err = fmt.Errorf("%s: query timed out after %v: %w", query.QueryType, timeout, err)
+			}
+		}()
+	}
 	if err = q.open(); err != nil {
 		return nil, fmt.Errorf("failed to initialize query context: %w", err)
 	}
@@ -114,6 +232,7 @@ func executeQuery(q *queryContext, query *querybackendv1.Query) (r *querybackend
 	}()
 	if r, err = handle(q, query); r != nil {
 		r.ReportType = QueryReportType(query.QueryType)
+		r.QueryType = query.QueryType
 	}
 	return r, err
 }
@@ -127,8 +246,13 @@ func (q *queryContext) close(err error) error {
 }
 
 func (q *queryContext) sections() []block.Section {
+	return requestSections(q.req.src)
+}
+
+// requestSections returns the sections the queries of the request depend on.
+func requestSections(req *querybackendv1.InvokeRequest) []block.Section {
 	sections := make(map[block.Section]struct{}, 3)
-	for _, qt := range q.req.src.Query {
+	for _, qt := range req.Query {
 		for _, s := range queryDependencies[qt.QueryType] {
 			sections[s] = struct{}{}
 		}
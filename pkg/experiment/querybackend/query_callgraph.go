@@ -0,0 +1,238 @@
+package querybackend
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend/block"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+func init() {
+	registerQueryType(
+		querybackendv1.QueryType_QUERY_CALLGRAPH,
+		querybackendv1.ReportType_REPORT_CALLGRAPH,
+		queryCallGraph,
+		newCallGraphAggregator,
+		noQueryTimeout,
+		[]block.Section{
+			block.SectionTSDB,
+			block.SectionProfiles,
+			block.SectionSymbols,
+		}...,
+	)
+}
+
+// queryCallGraph reports the call graph of the dataset. The graph is not
+// truncated: the nodes retained in a partial graph may not be the ones of
+// the merged graph.
+func queryCallGraph(q *queryContext, query *querybackendv1.Query) (*querybackendv1.Report, error) {
+	tree, _, err := resolveTree(q, nil)
+	if err != nil {
+		return nil, err
+	}
+	g := newCallGraph()
+	g.addTree(tree)
+	resp := &querybackendv1.Report{
+		CallGraph: g.proto(query.CallGraph, 0),
+	}
+	return resp, nil
+}
+
+// callGraphQuery returns the call graph query of the request.
+func callGraphQuery(req *querybackendv1.InvokeRequest) *querybackendv1.CallGraphQuery {
+	for _, q := range req.Query {
+		if q.CallGraph != nil {
+			return q.CallGraph
+		}
+	}
+	return nil
+}
+
+// stripCallGraphMaxNodes removes the node limit from the call graph queries
+// of the sub-query request: the graph is only truncated at the root.
+func stripCallGraphMaxNodes(req *querybackendv1.InvokeRequest) {
+	for _, q := range req.Query {
+		if q.CallGraph != nil {
+			q.CallGraph.MaxNodes = 0
+		}
+	}
+}
+
+type callGraphAggregator struct {
+	init  sync.Once
+	query *querybackendv1.CallGraphQuery
+	m     sync.Mutex
+	graph *callGraph
+}
+
+func newCallGraphAggregator(req *querybackendv1.InvokeRequest) aggregator {
+	a := &callGraphAggregator{graph: newCallGraph()}
+	if q := callGraphQuery(req); q != nil {
+		// The node limit of the request applies: the
+		// one of the sub-queries has been removed.
+		a.query = q.CloneVT()
+	}
+	return a
+}
+
+func (a *callGraphAggregator) aggregate(report *querybackendv1.Report) error {
+	r := report.CallGraph
+	a.init.Do(func() {
+		if a.query == nil {
+			a.query = r.Query.CloneVT()
+		}
+	})
+	a.m.Lock()
+	a.graph.merge(r)
+	a.m.Unlock()
+	return nil
+}
+
+func (a *callGraphAggregator) build() *querybackendv1.Report {
+	return &querybackendv1.Report{
+		CallGraph: a.graph.proto(a.query, a.query.GetMaxNodes()),
+	}
+}
+
+// callGraph is a graph, where each function is represented
+// by a single node. It is not safe for concurrent use.
+type callGraph struct {
+	index map[string]uint32
+	nodes []callGraphNode
+	edges map[callGraphEdge]int64
+
+	// Functions and calls seen in the current stack trace.
+	seenNodes map[uint32]struct{}
+	seenEdges map[callGraphEdge]struct{}
+}
+
+type callGraphNode struct {
+	name  string
+	self  int64
+	total int64
+}
+
+type callGraphEdge struct {
+	caller uint32
+	callee uint32
+}
+
+func newCallGraph() *callGraph {
+	return &callGraph{
+		index:     make(map[string]uint32),
+		edges:     make(map[callGraphEdge]int64),
+		seenNodes: make(map[uint32]struct{}),
+		seenEdges: make(map[callGraphEdge]struct{}),
+	}
+}
+
+func (g *callGraph) node(name string) uint32 {
+	n, ok := g.index[name]
+	if !ok {
+		n = uint32(len(g.nodes))
+		g.nodes = append(g.nodes, callGraphNode{name: name})
+		g.index[name] = n
+	}
+	return n
+}
+
+// addTree adds the stack traces of the tree to the graph. A function
+// that occurs in a stack trace multiple times, e.g. due to recursion,
+// contributes to the node total and to the edge weights only once;
+// self-calls are not represented as edges.
+func (g *callGraph) addTree(t *model.Tree) {
+	t.IterateStacks(func(_ string, self int64, stack []string) {
+		clear(g.seenNodes)
+		clear(g.seenEdges)
+		// The stack starts at the leaf.
+		callee := g.node(stack[0])
+		g.nodes[callee].self += self
+		for i := range stack {
+			if i > 0 {
+				caller := g.node(stack[i])
+				e := callGraphEdge{caller: caller, callee: callee}
+				if _, seen := g.seenEdges[e]; !seen && caller != callee {
+					g.seenEdges[e] = struct{}{}
+					g.edges[e] += self
+				}
+				callee = caller
+			}
+			if _, seen := g.seenNodes[callee]; !seen {
+				g.seenNodes[callee] = struct{}{}
+				g.nodes[callee].total += self
+			}
+		}
+	})
+}
+
+func (g *callGraph) merge(r *querybackendv1.CallGraphReport) {
+	nodes := make([]uint32, len(r.Nodes))
+	for i, x := range r.Nodes {
+		n := g.node(x.Name)
+		g.nodes[n].self += x.Self
+		g.nodes[n].total += x.Total
+		nodes[i] = n
+	}
+	for _, x := range r.Edges {
+		if int(x.Caller) < len(nodes) && int(x.Callee) < len(nodes) {
+			g.edges[callGraphEdge{caller: nodes[x.Caller], callee: nodes[x.Callee]}] += x.Value
+		}
+	}
+}
+
+// proto returns the graph retaining max nodes with the highest total
+// values, and the edges between them. Zero max nodes means no limit.
+func (g *callGraph) proto(query *querybackendv1.CallGraphQuery, maxNodes int64) *querybackendv1.CallGraphReport {
+	order := make([]uint32, len(g.nodes))
+	for i := range order {
+		order[i] = uint32(i)
+	}
+	slices.SortFunc(order, func(a, b uint32) int {
+		if c := cmp.Compare(g.nodes[b].total, g.nodes[a].total); c != 0 {
+			return c
+		}
+		return cmp.Compare(g.nodes[a].name, g.nodes[b].name)
+	})
+	if maxNodes > 0 && int64(len(order)) > maxNodes {
+		order = order[:maxNodes]
+	}
+	p := &querybackendv1.CallGraphReport{
+		Query: query,
+		Nodes: make([]*querybackendv1.CallGraphNode, len(order)),
+	}
+	// Maps the graph node index to the report node index.
+	retained := make(map[uint32]uint32, len(order))
+	for i, n := range order {
+		retained[n] = uint32(i)
+		p.Nodes[i] = &querybackendv1.CallGraphNode{
+			Name:  g.nodes[n].name,
+			Self:  g.nodes[n].self,
+			Total: g.nodes[n].total,
+		}
+	}
+	for e, v := range g.edges {
+		caller, ok := retained[e.caller]
+		if !ok {
+			continue
+		}
+		callee, ok := retained[e.callee]
+		if !ok {
+			continue
+		}
+		p.Edges = append(p.Edges, &querybackendv1.CallGraphEdge{
+			Caller: caller,
+			Callee: callee,
+			Value:  v,
+		})
+	}
+	slices.SortFunc(p.Edges, func(a, b *querybackendv1.CallGraphEdge) int {
+		if c := cmp.Compare(a.Caller, b.Caller); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Callee, b.Callee)
+	})
+	return p
+}
@@ -0,0 +1,119 @@
+package querybackend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+func Test_CallGraph_Recursion(t *testing.T) {
+	tree := new(model.Tree)
+	tree.InsertStack(1, "main", "a", "a", "a", "b")
+	tree.InsertStack(2, "main", "a", "b", "a")
+	tree.InsertStack(4, "main", "c")
+
+	g := newCallGraph()
+	g.addTree(tree)
+	p := g.proto(new(querybackendv1.CallGraphQuery), 0)
+
+	expectedNodes := []*querybackendv1.CallGraphNode{
+		{Name: "main", Self: 0, Total: 7},
+		{Name: "c", Self: 4, Total: 4},
+		{Name: "a", Self: 2, Total: 3},
+		{Name: "b", Self: 1, Total: 3},
+	}
+	require.Equal(t, expectedNodes, p.Nodes)
+
+	const main, c, a, b = 0, 1, 2, 3
+	expectedEdges := []*querybackendv1.CallGraphEdge{
+		{Caller: main, Callee: c, Value: 4},
+		{Caller: main, Callee: a, Value: 3},
+		{Caller: a, Callee: b, Value: 3},
+		{Caller: b, Callee: a, Value: 2},
+	}
+	require.Equal(t, expectedEdges, p.Edges)
+}
+
+func Test_CallGraph_MergeTruncate(t *testing.T) {
+	t1 := new(model.Tree)
+	t1.InsertStack(1, "main", "a", "b")
+	t1.InsertStack(5, "main", "c")
+	t2 := new(model.Tree)
+	t2.InsertStack(2, "main", "b")
+	t2.InsertStack(1, "main", "a")
+
+	query := &querybackendv1.Query{CallGraph: &querybackendv1.CallGraphQuery{MaxNodes: 3}}
+	a := newCallGraphAggregator(&querybackendv1.InvokeRequest{Query: []*querybackendv1.Query{query}})
+	for _, tree := range []*model.Tree{t1, t2} {
+		g := newCallGraph()
+		g.addTree(tree)
+		// The partial graphs are not truncated: "b" is not
+		// among the top nodes of the first one.
+		report := &querybackendv1.Report{CallGraph: g.proto(new(querybackendv1.CallGraphQuery), 0)}
+		require.NoError(t, a.aggregate(report))
+	}
+
+	p := a.build().CallGraph
+	expectedNodes := []*querybackendv1.CallGraphNode{
+		{Name: "main", Self: 0, Total: 9},
+		{Name: "c", Self: 5, Total: 5},
+		{Name: "b", Self: 3, Total: 3},
+	}
+	require.Equal(t, expectedNodes, p.Nodes)
+	// Edges of the nodes that have not been
+	// retained are removed from the graph.
+	expectedEdges := []*querybackendv1.CallGraphEdge{
+		{Caller: 0, Callee: 1, Value: 5},
+		{Caller: 0, Callee: 2, Value: 2},
+	}
+	require.Equal(t, expectedEdges, p.Edges)
+}
+
+func Test_QueryCallGraph(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	invoke := func(query *querybackendv1.Query) *querybackendv1.Report {
+		resp, err := reader.Invoke(context.Background(), newTestInvokeRequest(blocks, `{service_name=~".+"}`, query))
+		require.NoError(t, err)
+		require.Len(t, resp.Reports, 1)
+		return resp.Reports[0]
+	}
+
+	r := invoke(&querybackendv1.Query{
+		QueryType: querybackendv1.QueryType_QUERY_TREE,
+		Tree:      new(querybackendv1.TreeQuery),
+	})
+	tree, err := model.UnmarshalTree(r.Tree.Tree)
+	require.NoError(t, err)
+
+	r = invoke(&querybackendv1.Query{
+		QueryType: querybackendv1.QueryType_QUERY_CALLGRAPH,
+		CallGraph: new(querybackendv1.CallGraphQuery),
+	})
+	require.Equal(t, querybackendv1.ReportType_REPORT_CALLGRAPH, r.ReportType)
+	graph := r.CallGraph
+	require.NotEmpty(t, graph.Nodes)
+	var self int64
+	for _, n := range graph.Nodes {
+		self += n.Self
+		require.LessOrEqual(t, n.Self, n.Total)
+		require.LessOrEqual(t, n.Total, tree.Total())
+	}
+	require.Equal(t, tree.Total(), self)
+	for _, e := range graph.Edges {
+		require.NotEqual(t, e.Caller, e.Callee)
+		require.LessOrEqual(t, e.Value, graph.Nodes[e.Callee].Total)
+	}
+
+	r = invoke(&querybackendv1.Query{
+		QueryType: querybackendv1.QueryType_QUERY_CALLGRAPH,
+		CallGraph: &querybackendv1.CallGraphQuery{MaxNodes: 10},
+	})
+	require.Len(t, r.CallGraph.Nodes, 10)
+	for i := 1; i < len(r.CallGraph.Nodes); i++ {
+		require.GreaterOrEqual(t, r.CallGraph.Nodes[i-1].Total, r.CallGraph.Nodes[i].Total)
+	}
+}
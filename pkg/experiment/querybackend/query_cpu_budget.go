@@ -0,0 +1,82 @@
+package querybackend
+
+import (
+	"errors"
+	"runtime"
+	"time"
+
+	"go.uber.org/atomic"
+)
+
+var errCPUBudgetExceeded = errors.New("query CPU time budget exceeded")
+
+// cpuBudgetCheckInterval is the number of iterations
+// after which the budget is checked.
+const cpuBudgetCheckInterval = 256
+
+// cpuBudget limits the CPU time consumed by a query. Go does not expose
+// the CPU time of a goroutine, therefore it is approximated by the time
+// spent processing the data, excluding the time spent waiting for it.
+// The budget is shared by all the datasets the query is executed
+// against. A nil budget is unlimited.
+type cpuBudget struct {
+	limit time.Duration
+	used  atomic.Duration
+}
+
+func newCPUBudget(limit time.Duration) *cpuBudget {
+	if limit <= 0 {
+		return nil
+	}
+	return &cpuBudget{limit: limit}
+}
+
+func (b *cpuBudget) charge(d time.Duration) error {
+	if b == nil || b.used.Add(d) <= b.limit {
+		return nil
+	}
+	return errCPUBudgetExceeded
+}
+
+// cpuMeter measures the processing time of the loop iterations, and
+// charges the budget periodically, yielding the processor to other
+// goroutines, so that a heavy query does not monopolize it.
+type cpuMeter struct {
+	budget  *cpuBudget
+	start   time.Time
+	pending time.Duration
+	n       int
+}
+
+func (m *cpuMeter) begin() {
+	if m.budget != nil {
+		m.start = time.Now()
+	}
+}
+
+func (m *cpuMeter) end() error {
+	if m.budget == nil {
+		return nil
+	}
+	m.pending += time.Since(m.start)
+	if m.n++; m.n%cpuBudgetCheckInterval != 0 {
+		return nil
+	}
+	runtime.Gosched()
+	return m.flush()
+}
+
+// endFlush is like end, but the budget is charged immediately.
+func (m *cpuMeter) endFlush() error {
+	if m.budget == nil {
+		return nil
+	}
+	m.pending += time.Since(m.start)
+	return m.flush()
+}
+
+func (m *cpuMeter) flush() error {
+	d := m.pending
+	m.pending = 0
+	return m.budget.charge(d)
+}
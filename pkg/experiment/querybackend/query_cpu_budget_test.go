@@ -0,0 +1,54 @@
+package querybackend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+)
+
+func Test_CPUBudget(t *testing.T) {
+	require.Nil(t, newCPUBudget(0))
+	var unlimited *cpuBudget
+	require.NoError(t, unlimited.charge(time.Hour))
+
+	b := newCPUBudget(time.Second)
+	require.NoError(t, b.charge(time.Second))
+	require.ErrorIs(t, b.charge(time.Nanosecond), errCPUBudgetExceeded)
+
+	m := cpuMeter{budget: newCPUBudget(time.Nanosecond)}
+	for i := 1; i < cpuBudgetCheckInterval; i++ {
+		m.begin()
+		require.NoError(t, m.end())
+	}
+	m.begin()
+	require.ErrorIs(t, m.end(), errCPUBudgetExceeded)
+}
+
+func Test_QueryTree_CPUBudgetExceeded(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	invoke := func() error {
+		_, err := reader.Invoke(context.Background(), newTestInvokeRequest(
+			blocks,
+			`{service_name=~".+"}`,
+			&querybackendv1.Query{
+				QueryType: querybackendv1.QueryType_QUERY_TREE,
+				Tree:      new(querybackendv1.TreeQuery),
+			},
+		))
+		return err
+	}
+
+	require.NoError(t, invoke())
+	reader.maxQueryCPUTime = time.Nanosecond
+	err := invoke()
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.ErrorContains(t, err, errCPUBudgetExceeded.Error())
+	require.Equal(t, float64(1), testutil.ToFloat64(reader.metrics.cpuBudgetExceeded))
+}
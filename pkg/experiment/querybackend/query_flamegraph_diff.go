@@ -0,0 +1,145 @@
+package querybackend
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+
+	querierv1 "github.com/grafana/pyroscope/api/gen/proto/go/querier/v1"
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend/block"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+func init() {
+	registerQueryType(
+		querybackendv1.QueryType_QUERY_FLAMEGRAPH_DIFF,
+		querybackendv1.ReportType_REPORT_FLAMEGRAPH_DIFF,
+		queryFlameGraphDiff,
+		newFlameGraphDiffAggregator,
+		noQueryTimeout,
+		[]block.Section{
+			block.SectionTSDB,
+			block.SectionProfiles,
+			block.SectionSymbols,
+		}...,
+	)
+}
+
+func queryFlameGraphDiff(q *queryContext, query *querybackendv1.Query) (*querybackendv1.Report, error) {
+	if err := validateDiffMode(query.FlamegraphDiff.GetDiffMode()); err != nil {
+		return nil, fmt.Errorf("flame graph diff: %w", err)
+	}
+	left, err := flameGraphDiffSide(q, query.FlamegraphDiff.GetLeftSelector())
+	if err != nil {
+		return nil, fmt.Errorf("left side: %w", err)
+	}
+	right, err := flameGraphDiffSide(q, query.FlamegraphDiff.GetRightSelector())
+	if err != nil {
+		return nil, fmt.Errorf("right side: %w", err)
+	}
+	if hasNegativeValues(left) || hasNegativeValues(right) {
+		return nil, fmt.Errorf("flame graph diff: negative values are not supported")
+	}
+	// The sides are truncated to the same nodes: otherwise, the
+	// nodes truncated in one of them would appear absent.
+	left, right = truncateTreeDiffSides(left, right, query.FlamegraphDiff.GetMaxNodes())
+	return &querybackendv1.Report{
+		FlamegraphDiff: &querybackendv1.FlameGraphDiffReport{
+			Query:     query.FlamegraphDiff.CloneVT(),
+			LeftTree:  left.Bytes(-1),
+			RightTree: right.Bytes(-1),
+		},
+	}, nil
+}
+
+// hasNegativeValues reports whether any of the nodes of the tree has a
+// negative self value, e.g. of a delta profile: the diff flame graph is
+// not defined for such trees.
+func hasNegativeValues(t *model.Tree) (negative bool) {
+	t.IterateStacks(func(_ string, self int64, _ []string) {
+		negative = negative || self < 0
+	})
+	return negative
+}
+
+func flameGraphDiffSide(q *queryContext, selector string) (*model.Tree, error) {
+	var matchers []*labels.Matcher
+	if selector != "" {
+		var err error
+		if matchers, err = parser.ParseMetricSelector(selector); err != nil {
+			return nil, fmt.Errorf("label selection is invalid: %w", err)
+		}
+	}
+	tree, _, err := resolveTree(q.withMatchers(matchers...), nil)
+	return tree, err
+}
+
+type flameGraphDiffAggregator struct {
+	init    sync.Once
+	query   *querybackendv1.FlameGraphDiffQuery
+	left    *model.TreeMerger
+	right   *model.TreeMerger
+	partial bool
+}
+
+func newFlameGraphDiffAggregator(req *querybackendv1.InvokeRequest) aggregator {
+	return &flameGraphDiffAggregator{
+		left:    model.NewTreeMerger(),
+		right:   model.NewTreeMerger(),
+		partial: req.GetOptions().GetPartial(),
+	}
+}
+
+func (a *flameGraphDiffAggregator) aggregate(report *querybackendv1.Report) error {
+	r := report.FlamegraphDiff
+	a.init.Do(func() {
+		a.query = r.Query.CloneVT()
+	})
+	// The flame graph of the partial report is
+	// discarded: it is built for the merged trees.
+	if err := a.left.MergeTreeBytes(r.LeftTree); err != nil {
+		return err
+	}
+	return a.right.MergeTreeBytes(r.RightTree)
+}
+
+func (a *flameGraphDiffAggregator) build() *querybackendv1.Report {
+	left, right := a.left.Tree(), a.right.Tree()
+	maxNodes := a.query.GetMaxNodes()
+	r := &querybackendv1.FlameGraphDiffReport{Query: a.query}
+	// The trees are serialized first: the flame graph
+	// is built for the whole trees, which it modifies.
+	truncatedLeft, truncatedRight := truncateTreeDiffSides(left, right, maxNodes)
+	r.LeftTree, r.RightTree = truncatedLeft.Bytes(-1), truncatedRight.Bytes(-1)
+	if !a.partial && (left.Total() > 0 || right.Total() > 0) {
+		var err error
+		if r.Flamegraph, err = model.NewFlamegraphDiff(left, right, maxNodes); err != nil {
+			// The partial trees with negative values are rejected,
+			// therefore this is not expected: the aggregator is
+			// faulty, and the report is not built.
+			return nil
+		}
+		r.DiffLevels = flameGraphDiffLevels(r.Flamegraph, a.query.GetDiffMode())
+	}
+	return &querybackendv1.Report{FlamegraphDiff: r}
+}
+
+// flameGraphDiffLevels returns the differences of the totals of the nodes
+// of the diff flame graph in the given mode. A node of the diff flame graph
+// level is encoded as the left offset, total, and self values, followed
+// by the right ones and the name index.
+func flameGraphDiffLevels(fg *querierv1.FlameGraphDiff, mode querybackendv1.DiffMode) []*querybackendv1.FlameGraphDiffLevel {
+	const nodeSize = 7
+	levels := make([]*querybackendv1.FlameGraphDiffLevel, len(fg.Levels))
+	for i, l := range fg.Levels {
+		values := make([]float64, 0, len(l.Values)/nodeSize)
+		for j := 0; j+nodeSize <= len(l.Values); j += nodeSize {
+			values = append(values, diffValue(mode, l.Values[j+1], l.Values[j+4]))
+		}
+		levels[i] = &querybackendv1.FlameGraphDiffLevel{Values: values}
+	}
+	return levels
+}
@@ -0,0 +1,164 @@
+package querybackend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+func Test_QueryFlameGraphDiff(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	invoke := func(query *querybackendv1.Query) *querybackendv1.Report {
+		resp, err := reader.Invoke(context.Background(), newTestInvokeRequest(blocks, `{service_name=~".+", __type__="cpu"}`, query))
+		require.NoError(t, err)
+		require.Len(t, resp.Reports, 1)
+		return resp.Reports[0]
+	}
+
+	const ingester = `{service_name="pyroscope-test/ingester"}`
+	r := invoke(&querybackendv1.Query{
+		QueryType: querybackendv1.QueryType_QUERY_FLAMEGRAPH_DIFF,
+		FlamegraphDiff: &querybackendv1.FlameGraphDiffQuery{
+			LeftSelector:  ingester,
+			RightSelector: `{service_name!="pyroscope-test/ingester"}`,
+			MaxNodes:      64,
+		},
+	}).FlamegraphDiff
+
+	all := model.MustUnmarshalTree(invoke(&querybackendv1.Query{
+		QueryType: querybackendv1.QueryType_QUERY_TREE,
+		Tree:      new(querybackendv1.TreeQuery),
+	}).Tree.Tree)
+	require.NotNil(t, r.Flamegraph)
+	leftTree := model.MustUnmarshalTree(r.LeftTree)
+	rightTree := model.MustUnmarshalTree(r.RightTree)
+	require.Positive(t, leftTree.Total())
+	require.Positive(t, rightTree.Total())
+	require.Equal(t, leftTree.Total(), r.Flamegraph.LeftTicks)
+	require.Equal(t, rightTree.Total(), r.Flamegraph.RightTicks)
+	require.Equal(t, all.Total(), leftTree.Total()+rightTree.Total())
+
+	_, err := reader.Invoke(context.Background(), newTestInvokeRequest(
+		blocks,
+		`{service_name=~".+"}`,
+		&querybackendv1.Query{
+			QueryType:      querybackendv1.QueryType_QUERY_FLAMEGRAPH_DIFF,
+			FlamegraphDiff: &querybackendv1.FlameGraphDiffQuery{LeftSelector: "{"},
+		},
+	))
+	require.Error(t, err)
+
+	_, err = reader.Invoke(context.Background(), newTestInvokeRequest(
+		blocks,
+		`{service_name=~".+"}`,
+		&querybackendv1.Query{
+			QueryType:      querybackendv1.QueryType_QUERY_FLAMEGRAPH_DIFF,
+			FlamegraphDiff: &querybackendv1.FlameGraphDiffQuery{DiffMode: querybackendv1.DiffMode(-1)},
+		},
+	))
+	require.Error(t, err)
+}
+
+func Test_FlameGraphDiffAggregator(t *testing.T) {
+	report := func(left, right []string) *querybackendv1.Report {
+		l, r := new(model.Tree), new(model.Tree)
+		if len(left) > 0 {
+			l.InsertStack(1, left...)
+		}
+		if len(right) > 0 {
+			r.InsertStack(2, right...)
+		}
+		return &querybackendv1.Report{
+			FlamegraphDiff: &querybackendv1.FlameGraphDiffReport{
+				Query:     new(querybackendv1.FlameGraphDiffQuery),
+				LeftTree:  l.Bytes(-1),
+				RightTree: r.Bytes(-1),
+			},
+		}
+	}
+
+	a := newFlameGraphDiffAggregator(nil)
+	require.NoError(t, a.aggregate(report([]string{"main", "foo"}, []string{"main", "bar"})))
+	require.NoError(t, a.aggregate(report([]string{"main", "bar"}, nil)))
+	r := a.build().FlamegraphDiff
+
+	expectedLeft := new(model.Tree)
+	expectedLeft.InsertStack(1, "main", "foo")
+	expectedLeft.InsertStack(1, "main", "bar")
+	expectedRight := new(model.Tree)
+	expectedRight.InsertStack(2, "main", "bar")
+	require.Equal(t, expectedLeft.String(), model.MustUnmarshalTree(r.LeftTree).String())
+	require.Equal(t, expectedRight.String(), model.MustUnmarshalTree(r.RightTree).String())
+
+	expected, err := model.NewFlamegraphDiff(expectedLeft, expectedRight, 0)
+	require.NoError(t, err)
+	require.True(t, expected.EqualVT(r.Flamegraph))
+	// The deltas of the totals, by default.
+	require.Len(t, r.DiffLevels, len(r.Flamegraph.Levels))
+	require.Equal(t, []float64{0}, r.DiffLevels[0].Values)
+	require.Equal(t, []float64{0}, r.DiffLevels[1].Values)
+	require.ElementsMatch(t, []float64{-1, 1}, r.DiffLevels[2].Values)
+
+	ratio := newFlameGraphDiffAggregator(nil)
+	for _, x := range []*querybackendv1.Report{
+		report([]string{"main", "foo"}, []string{"main", "bar"}),
+		report([]string{"main", "bar"}, nil),
+	} {
+		x.FlamegraphDiff.Query.DiffMode = querybackendv1.DiffMode_DIFF_MODE_RATIO
+		require.NoError(t, ratio.aggregate(x))
+	}
+	r = ratio.build().FlamegraphDiff
+	require.Equal(t, []float64{1}, r.DiffLevels[1].Values)
+	require.ElementsMatch(t, []float64{0, 2}, r.DiffLevels[2].Values)
+
+	empty := newFlameGraphDiffAggregator(nil)
+	require.NoError(t, empty.aggregate(report(nil, nil)))
+	require.Nil(t, empty.build().FlamegraphDiff.Flamegraph)
+	require.Nil(t, empty.build().FlamegraphDiff.DiffLevels)
+}
+
+func Test_FlameGraphDiffAggregator_Truncate(t *testing.T) {
+	left, right := new(model.Tree), new(model.Tree)
+	left.InsertStack(8, "main", "a")
+	left.InsertStack(1, "main", "b")
+	right.InsertStack(1, "main", "a")
+	right.InsertStack(2, "main", "c")
+	report := &querybackendv1.Report{
+		FlamegraphDiff: &querybackendv1.FlameGraphDiffReport{
+			Query:     &querybackendv1.FlameGraphDiffQuery{MaxNodes: 2},
+			LeftTree:  left.Bytes(-1),
+			RightTree: right.Bytes(-1),
+		},
+	}
+
+	a := newFlameGraphDiffAggregator(nil)
+	require.NoError(t, a.aggregate(report))
+	r := a.build().FlamegraphDiff
+	require.NotNil(t, r.Flamegraph)
+	// Both sides retain the same nodes: the values
+	// of the rest are accounted in the other node.
+	expectedLeft := `.
+└── main: self 0 total 9
+    ├── a: self 8 total 8
+    └── other: self 1 total 1
+`
+	expectedRight := `.
+└── main: self 0 total 3
+    ├── a: self 1 total 1
+    └── other: self 2 total 2
+`
+	require.Equal(t, expectedLeft, model.MustUnmarshalTree(r.LeftTree).String())
+	require.Equal(t, expectedRight, model.MustUnmarshalTree(r.RightTree).String())
+
+	partial := newFlameGraphDiffAggregator(&querybackendv1.InvokeRequest{
+		Options: &querybackendv1.InvokeOptions{Partial: true},
+	})
+	require.NoError(t, partial.aggregate(report))
+	r = partial.build().FlamegraphDiff
+	require.Nil(t, r.Flamegraph)
+	require.Equal(t, expectedLeft, model.MustUnmarshalTree(r.LeftTree).String())
+}
@@ -0,0 +1,210 @@
+package querybackend
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/common/model"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend/block"
+)
+
+func init() {
+	registerQueryType(
+		querybackendv1.QueryType_QUERY_FUNCTION_CHANGES,
+		querybackendv1.ReportType_REPORT_FUNCTION_CHANGES,
+		queryFunctionChanges,
+		newFunctionChangesAggregator,
+		noQueryTimeout,
+		[]block.Section{
+			block.SectionTSDB,
+			block.SectionProfiles,
+			block.SectionSymbols,
+		}...,
+	)
+}
+
+// queryFunctionChanges resolves the trees of the two time windows, and
+// reports the self values of the functions in each of them. The partial
+// reports are not ranked: the rank depends on the aggregated values.
+func queryFunctionChanges(q *queryContext, query *querybackendv1.Query) (*querybackendv1.Report, error) {
+	fc := query.FunctionChanges
+	if err := validateFunctionChangesQuery(fc); err != nil {
+		return nil, err
+	}
+	changes := make(functionChanges)
+	windows := []struct {
+		start, end int64
+		value      func(*functionChange) *int64
+	}{
+		{fc.GetBaselineStart(), fc.GetBaselineEnd(), func(c *functionChange) *int64 { return &c.baseline }},
+		{fc.GetCurrentStart(), fc.GetCurrentEnd(), func(c *functionChange) *int64 { return &c.current }},
+	}
+	for _, w := range windows {
+		wq, ok := q.withTimeRange(w.start, w.end)
+		if !ok {
+			continue
+		}
+		tree, _, err := resolveTree(wq, nil)
+		if err != nil {
+			return nil, err
+		}
+		tree.IterateStacks(func(name string, self int64, _ []string) {
+			*w.value(changes.get(name)) += self
+		})
+	}
+	return &querybackendv1.Report{
+		FunctionChanges: &querybackendv1.FunctionChangesReport{
+			Query:     fc.CloneVT(),
+			Functions: changes.proto(nil),
+		},
+	}, nil
+}
+
+func validateFunctionChangesQuery(q *querybackendv1.FunctionChangesQuery) error {
+	if q.GetBaselineStart() > q.GetBaselineEnd() || q.GetCurrentStart() > q.GetCurrentEnd() {
+		return fmt.Errorf("function changes: window start must not be after the end")
+	}
+	if q.GetLimit() < 0 {
+		return fmt.Errorf("function changes: limit must not be negative")
+	}
+	return nil
+}
+
+// withTimeRange returns a copy of the query context that only selects
+// the profiles within the time range given in milliseconds, limited by
+// the time range of the request. False is returned if the ranges do not
+// overlap.
+func (q *queryContext) withTimeRange(start, end int64) (*queryContext, bool) {
+	r := *q.req
+	r.startTime = max(r.startTime, model.Time(start).UnixNano())
+	r.endTime = min(r.endTime, model.Time(end).UnixNano())
+	if r.startTime > r.endTime {
+		return nil, false
+	}
+	c := *q
+	c.req = &r
+	return &c, true
+}
+
+type functionChange struct {
+	baseline int64
+	current  int64
+}
+
+func (c *functionChange) delta() int64 { return c.current - c.baseline }
+
+type functionChanges map[string]*functionChange
+
+func (m functionChanges) get(name string) *functionChange {
+	c, ok := m[name]
+	if !ok {
+		c = new(functionChange)
+		m[name] = c
+	}
+	return c
+}
+
+func (m functionChanges) merge(r *querybackendv1.FunctionChangesReport) {
+	for _, f := range r.Functions {
+		c := m.get(f.Name)
+		c.baseline += f.BaselineSelf
+		c.current += f.CurrentSelf
+	}
+}
+
+// proto returns the functions which self values differ, ranked
+// if the query is provided, and limited to the query limit.
+func (m functionChanges) proto(query *querybackendv1.FunctionChangesQuery) []*querybackendv1.FunctionChange {
+	names := make([]string, 0, len(m))
+	for name, c := range m {
+		if query == nil || c.delta() != 0 {
+			names = append(names, name)
+		}
+	}
+	if query != nil {
+		slices.SortFunc(names, func(a, b string) int {
+			if c := compareFunctionChanges(m[a], m[b], query.Ranking); c != 0 {
+				return c
+			}
+			return strings.Compare(a, b)
+		})
+		if limit := query.GetLimit(); limit > 0 && int64(len(names)) > limit {
+			names = names[:limit]
+		}
+	}
+	functions := make([]*querybackendv1.FunctionChange, len(names))
+	for i, name := range names {
+		c := m[name]
+		functions[i] = &querybackendv1.FunctionChange{
+			Name:         name,
+			BaselineSelf: c.baseline,
+			CurrentSelf:  c.current,
+		}
+	}
+	return functions
+}
+
+// compareFunctionChanges orders the function changes by rank:
+// the most changed functions come first.
+func compareFunctionChanges(a, b *functionChange, ranking querybackendv1.FunctionChangeRanking) int {
+	if ranking == querybackendv1.FunctionChangeRanking_FUNCTION_CHANGE_RANKING_PERCENT {
+		// Functions absent in the baseline rank first.
+		if (a.baseline == 0) != (b.baseline == 0) {
+			if a.baseline == 0 {
+				return -1
+			}
+			return 1
+		}
+		if a.baseline != 0 {
+			return cmp.Compare(relativeChange(b), relativeChange(a))
+		}
+	}
+	return cmp.Compare(absInt64(b.delta()), absInt64(a.delta()))
+}
+
+func relativeChange(c *functionChange) float64 {
+	return float64(absInt64(c.delta())) / float64(absInt64(c.baseline))
+}
+
+func absInt64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+type functionChangesAggregator struct {
+	init    sync.Once
+	m       sync.Mutex
+	query   *querybackendv1.FunctionChangesQuery
+	changes functionChanges
+}
+
+func newFunctionChangesAggregator(*querybackendv1.InvokeRequest) aggregator {
+	return &functionChangesAggregator{changes: make(functionChanges)}
+}
+
+func (a *functionChangesAggregator) aggregate(report *querybackendv1.Report) error {
+	r := report.FunctionChanges
+	a.init.Do(func() {
+		a.query = r.Query.CloneVT()
+	})
+	a.m.Lock()
+	a.changes.merge(r)
+	a.m.Unlock()
+	return nil
+}
+
+func (a *functionChangesAggregator) build() *querybackendv1.Report {
+	return &querybackendv1.Report{
+		FunctionChanges: &querybackendv1.FunctionChangesReport{
+			Query:     a.query,
+			Functions: a.changes.proto(a.query),
+		},
+	}
+}
@@ -0,0 +1,92 @@
+package querybackend
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+func Test_QueryFunctionChanges(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	const maxTime = math.MaxInt64 / int64(1e6)
+	invoke := func(query *querybackendv1.Query) *querybackendv1.Report {
+		resp, err := reader.Invoke(context.Background(), newTestInvokeRequest(blocks, `{service_name=~".+"}`, query))
+		require.NoError(t, err)
+		require.Len(t, resp.Reports, 1)
+		return resp.Reports[0]
+	}
+	functionChanges := func(q *querybackendv1.FunctionChangesQuery) []*querybackendv1.FunctionChange {
+		return invoke(&querybackendv1.Query{
+			QueryType:       querybackendv1.QueryType_QUERY_FUNCTION_CHANGES,
+			FunctionChanges: q,
+		}).FunctionChanges.Functions
+	}
+
+	// No changes between identical windows.
+	require.Empty(t, functionChanges(&querybackendv1.FunctionChangesQuery{
+		BaselineEnd: maxTime,
+		CurrentEnd:  maxTime,
+	}))
+
+	// Against an empty baseline, all the functions have changed.
+	tree, err := model.UnmarshalTree(invoke(&querybackendv1.Query{
+		QueryType: querybackendv1.QueryType_QUERY_TREE,
+		Tree:      &querybackendv1.TreeQuery{MaxNodes: -1},
+	}).Tree.Tree)
+	require.NoError(t, err)
+	functions := functionChanges(&querybackendv1.FunctionChangesQuery{CurrentEnd: maxTime})
+	require.NotEmpty(t, functions)
+	var total int64
+	for i, f := range functions {
+		require.Zero(t, f.BaselineSelf)
+		if i > 0 {
+			require.LessOrEqual(t, f.CurrentSelf, functions[i-1].CurrentSelf)
+		}
+		total += f.CurrentSelf
+	}
+	require.Equal(t, tree.Total(), total)
+
+	limited := functionChanges(&querybackendv1.FunctionChangesQuery{CurrentEnd: maxTime, Limit: 2})
+	require.Equal(t, functions[:2], limited)
+}
+
+func Test_FunctionChangesAggregator(t *testing.T) {
+	report := func(ranking querybackendv1.FunctionChangeRanking, functions ...*querybackendv1.FunctionChange) *querybackendv1.Report {
+		return &querybackendv1.Report{
+			FunctionChanges: &querybackendv1.FunctionChangesReport{
+				Query:     &querybackendv1.FunctionChangesQuery{Ranking: ranking},
+				Functions: functions,
+			},
+		}
+	}
+	aggregate := func(ranking querybackendv1.FunctionChangeRanking) []*querybackendv1.FunctionChange {
+		a := newFunctionChangesAggregator(nil)
+		require.NoError(t, a.aggregate(report(ranking,
+			&querybackendv1.FunctionChange{Name: "a", BaselineSelf: 100, CurrentSelf: 150},
+			&querybackendv1.FunctionChange{Name: "b", BaselineSelf: 10, CurrentSelf: 20},
+		)))
+		require.NoError(t, a.aggregate(report(ranking,
+			&querybackendv1.FunctionChange{Name: "a", BaselineSelf: 100, CurrentSelf: 100},
+			&querybackendv1.FunctionChange{Name: "c", CurrentSelf: 5},
+			&querybackendv1.FunctionChange{Name: "d", BaselineSelf: 7, CurrentSelf: 7},
+		)))
+		return a.build().FunctionChanges.Functions
+	}
+
+	require.Equal(t, []*querybackendv1.FunctionChange{
+		{Name: "a", BaselineSelf: 200, CurrentSelf: 250},
+		{Name: "b", BaselineSelf: 10, CurrentSelf: 20},
+		{Name: "c", CurrentSelf: 5},
+	}, aggregate(querybackendv1.FunctionChangeRanking_FUNCTION_CHANGE_RANKING_ABSOLUTE))
+
+	require.Equal(t, []*querybackendv1.FunctionChange{
+		{Name: "c", CurrentSelf: 5},
+		{Name: "b", BaselineSelf: 10, CurrentSelf: 20},
+		{Name: "a", BaselineSelf: 200, CurrentSelf: 250},
+	}, aggregate(querybackendv1.FunctionChangeRanking_FUNCTION_CHANGE_RANKING_PERCENT))
+}
@@ -0,0 +1,44 @@
+package querybackend
+
+import (
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"github.com/prometheus/client_golang/prometheus"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+)
+
+// queryIDLabel is the name of the log field, the span
+// tag, and the exemplar label carrying the query ID.
+const queryIDLabel = "query_id"
+
+// withQueryID generates the ID of the query, unless the request already
+// has one, and tags the span with it. The ID is kept in the options, so
+// that the sub-queries of the request inherit it.
+func withQueryID(span opentracing.Span, req *querybackendv1.InvokeRequest) {
+	if req.Options.GetQueryId() == "" {
+		if req.Options == nil {
+			req.Options = new(querybackendv1.InvokeOptions)
+		}
+		req.Options.QueryId = uuid.NewString()
+	}
+	span.SetTag(queryIDLabel, req.Options.QueryId)
+}
+
+func (q *queryContext) queryID() string {
+	if q.req == nil {
+		return ""
+	}
+	return q.req.src.GetOptions().GetQueryId()
+}
+
+// observe observes the value with the query ID exemplar,
+// if the observer supports exemplars.
+func (q *queryContext) observe(o prometheus.Observer, v float64) {
+	id := q.queryID()
+	if e, ok := o.(prometheus.ExemplarObserver); ok && id != "" {
+		e.ObserveWithExemplar(v, prometheus.Labels{queryIDLabel: id})
+		return
+	}
+	o.Observe(v)
+}
@@ -0,0 +1,63 @@
+package querybackend
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/opentracing/opentracing-go"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend/block"
+)
+
+func Test_WithQueryID(t *testing.T) {
+	span := opentracing.NoopTracer{}.StartSpan("test")
+	req := new(querybackendv1.InvokeRequest)
+	withQueryID(span, req)
+	id := req.Options.GetQueryId()
+	require.NotEmpty(t, id)
+
+	// The ID provided is retained.
+	withQueryID(span, req)
+	require.Equal(t, id, req.Options.GetQueryId())
+}
+
+func Test_QueryContext_QueryID(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	req := newTestInvokeRequest(blocks, `{service_name=~".+"}`, &querybackendv1.Query{
+		QueryType: querybackendv1.QueryType_QUERY_TREE,
+		Tree:      new(querybackendv1.TreeQuery),
+	})
+	req.Options = &querybackendv1.InvokeOptions{QueryId: "test-query"}
+	_, err := reader.Invoke(context.Background(), req)
+	require.NoError(t, err)
+
+	// The resolve time is observed with the query ID exemplar.
+	var m dto.Metric
+	o, err := reader.metrics.resolveIODuration.GetMetricWithLabelValues(querybackendv1.QueryType_QUERY_TREE.String())
+	require.NoError(t, err)
+	require.NoError(t, o.(prometheus.Histogram).Write(&m))
+	var exemplars int
+	for _, b := range m.Histogram.Bucket {
+		if e := b.GetExemplar(); e != nil {
+			require.Equal(t, queryIDLabel, e.Label[0].GetName())
+			require.Equal(t, "test-query", e.Label[0].GetValue())
+			exemplars++
+		}
+	}
+	require.Positive(t, exemplars)
+
+	// The log lines of the query context carry the query ID.
+	vr, err := validateRequest(req)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	md := blocks[0]
+	q := newQueryContext(context.Background(), log.NewLogfmtLogger(&buf), reader.metrics, md.Datasets[0], vr, block.NewObject(reader.storage, md))
+	require.NoError(t, q.log.Log("msg", "test"))
+	require.Equal(t, "query_id=test-query msg=test\n", buf.String())
+}
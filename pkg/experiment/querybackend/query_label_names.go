@@ -18,6 +18,7 @@ func init() {
 		querybackendv1.ReportType_REPORT_LABEL_NAMES,
 		queryLabelNames,
 		newLabelNameAggregator,
+		indexQueryTimeout,
 		[]block.Section{block.SectionTSDB}...,
 	)
 }
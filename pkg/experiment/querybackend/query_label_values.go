@@ -20,6 +20,7 @@ func init() {
 		querybackendv1.ReportType_REPORT_LABEL_VALUES,
 		queryLabelValues,
 		newLabelValueAggregator,
+		indexQueryTimeout,
 		[]block.Section{block.SectionTSDB}...,
 	)
 }
@@ -0,0 +1,139 @@
+package querybackend
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/grafana/dskit/runutil"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend/block"
+	phlaremodel "github.com/grafana/pyroscope/pkg/model"
+	parquetquery "github.com/grafana/pyroscope/pkg/phlaredb/query"
+	schemav1 "github.com/grafana/pyroscope/pkg/phlaredb/schemas/v1"
+)
+
+func init() {
+	registerQueryType(
+		querybackendv1.QueryType_QUERY_MANIFEST,
+		querybackendv1.ReportType_REPORT_MANIFEST,
+		queryManifest,
+		newManifestAggregator,
+		noQueryTimeout,
+		[]block.Section{
+			block.SectionTSDB,
+			block.SectionProfiles,
+		}...,
+	)
+}
+
+// queryManifest lists the descriptors of the matching profiles. The stack
+// traces are not accessed: the profile total values are read from the
+// profile table.
+func queryManifest(q *queryContext, query *querybackendv1.Query) (r *querybackendv1.Report, err error) {
+	if query.Manifest.GetLimit() < 0 {
+		return nil, fmt.Errorf("manifest limit must not be negative")
+	}
+	// The series labels are only retrieved for the label names given.
+	names, err := q.ds.Index().LabelNames()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := profileEntryIterator(q, nil, names...)
+	if err != nil {
+		return nil, err
+	}
+	defer runutil.CloseWithErrCapture(&err, entries, "failed to close profile entry iterator")
+
+	column, err := schemav1.ResolveColumnByPath(q.ds.Profiles().Schema(), strings.Split("TotalValue", "."))
+	if err != nil {
+		return nil, err
+	}
+
+	rows := parquetquery.NewRepeatedRowIterator(q.ctx, entries, q.ds.Profiles().RowGroups(), column.ColumnIndex)
+	defer runutil.CloseWithErrCapture(&err, rows, "failed to close column iterator")
+
+	var profiles []*querybackendv1.ProfileDescriptor
+	for rows.Next() {
+		row := rows.At()
+		profiles = append(profiles, &querybackendv1.ProfileDescriptor{
+			Timestamp:   int64(row.Row.Timestamp),
+			Labels:      row.Row.Labels,
+			ProfileType: row.Row.Labels.Get(phlaremodel.LabelNameProfileType),
+			TotalValue:  row.Values[0][0].Int64(),
+			BlockId:     q.obj.Meta().Id,
+		})
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	resp := &querybackendv1.Report{
+		Manifest: &querybackendv1.ManifestReport{
+			Query:    query.Manifest.CloneVT(),
+			Profiles: limitProfileDescriptors(profiles, query.Manifest.GetLimit()),
+		},
+	}
+	return resp, nil
+}
+
+// limitProfileDescriptors sorts the descriptors, and
+// returns the first ones, if the limit is positive.
+func limitProfileDescriptors(profiles []*querybackendv1.ProfileDescriptor, limit int64) []*querybackendv1.ProfileDescriptor {
+	slices.SortFunc(profiles, compareProfileDescriptors)
+	if limit > 0 && int64(len(profiles)) > limit {
+		profiles = profiles[:limit]
+	}
+	return profiles
+}
+
+func compareProfileDescriptors(a, b *querybackendv1.ProfileDescriptor) int {
+	switch {
+	case a.Timestamp < b.Timestamp:
+		return -1
+	case a.Timestamp > b.Timestamp:
+		return 1
+	}
+	if c := phlaremodel.CompareLabelPairs(a.Labels, b.Labels); c != 0 {
+		return c
+	}
+	return strings.Compare(a.BlockId, b.BlockId)
+}
+
+type manifestAggregator struct {
+	init     sync.Once
+	m        sync.Mutex
+	query    *querybackendv1.ManifestQuery
+	profiles []*querybackendv1.ProfileDescriptor
+}
+
+func newManifestAggregator(*querybackendv1.InvokeRequest) aggregator {
+	return new(manifestAggregator)
+}
+
+func (a *manifestAggregator) aggregate(report *querybackendv1.Report) error {
+	r := report.Manifest
+	a.init.Do(func() {
+		a.query = r.Query.CloneVT()
+	})
+	a.m.Lock()
+	a.profiles = append(a.profiles, r.Profiles...)
+	if limit := a.query.GetLimit(); limit > 0 && int64(len(a.profiles)) > 2*limit {
+		// Only the earliest profiles are retained: we don't
+		// need to keep the rest till the report is built.
+		a.profiles = limitProfileDescriptors(a.profiles, limit)
+	}
+	a.m.Unlock()
+	return nil
+}
+
+func (a *manifestAggregator) build() *querybackendv1.Report {
+	return &querybackendv1.Report{
+		Manifest: &querybackendv1.ManifestReport{
+			Query:    a.query,
+			Profiles: limitProfileDescriptors(a.profiles, a.query.GetLimit()),
+		},
+	}
+}
@@ -0,0 +1,62 @@
+package querybackend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	phlaremodel "github.com/grafana/pyroscope/pkg/model"
+)
+
+func Test_QueryManifest(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	manifest := func(selector string, limit int64) []*querybackendv1.ProfileDescriptor {
+		resp, err := reader.Invoke(context.Background(), newTestInvokeRequest(
+			blocks,
+			selector,
+			&querybackendv1.Query{
+				QueryType: querybackendv1.QueryType_QUERY_MANIFEST,
+				Manifest:  &querybackendv1.ManifestQuery{Limit: limit},
+			},
+		))
+		require.NoError(t, err)
+		require.Len(t, resp.Reports, 1)
+		return resp.Reports[0].Manifest.Profiles
+	}
+
+	profiles := manifest(`{service_name=~".+"}`, 0)
+	require.NotEmpty(t, profiles)
+	blockIDs := make(map[string]struct{})
+	for _, b := range blocks {
+		blockIDs[b.Id] = struct{}{}
+	}
+	for i, p := range profiles {
+		if i > 0 {
+			require.LessOrEqual(t, profiles[i-1].Timestamp, p.Timestamp)
+		}
+		require.Contains(t, blockIDs, p.BlockId)
+		require.Equal(t, phlaremodel.Labels(p.Labels).Get(phlaremodel.LabelNameProfileType), p.ProfileType)
+		require.NotEmpty(t, p.ProfileType)
+	}
+
+	// The totals match the tree totals.
+	resp, err := reader.Invoke(context.Background(), newTestInvokeRequest(
+		blocks,
+		`{service_name=~".+", __type__="cpu"}`,
+		&querybackendv1.Query{
+			QueryType: querybackendv1.QueryType_QUERY_TREE,
+			Tree:      &querybackendv1.TreeQuery{},
+		},
+	))
+	require.NoError(t, err)
+	var total int64
+	for _, p := range manifest(`{service_name=~".+", __type__="cpu"}`, 0) {
+		total += p.TotalValue
+	}
+	require.Equal(t, phlaremodel.MustUnmarshalTree(resp.Reports[0].Tree.Tree).Total(), total)
+
+	limited := manifest(`{service_name=~".+"}`, 3)
+	require.Equal(t, profiles[:3], limited)
+}
@@ -0,0 +1,57 @@
+package querybackend
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/parquet-go/parquet-go"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend/block"
+)
+
+const profileIDColumnName = "ID"
+
+// profileSet tracks the profiles accounted by a query. A profile
+// ingested once has the same ID in all the blocks it is stored in,
+// e.g. in the source and the compacted block; the ID is shared by
+// the profiles of all the sample types, therefore the series is
+// a part of the key.
+type profileSet struct {
+	m sync.Mutex
+	// The dataset that accounted the profile first.
+	seen map[profileKey]*block.Dataset
+}
+
+type profileKey struct {
+	id uuid.UUID
+	fp model.Fingerprint
+}
+
+func newProfileSet() *profileSet {
+	return &profileSet{seen: make(map[profileKey]*block.Dataset)}
+}
+
+// claim reports whether the profile should be accounted by the dataset:
+// only the dataset that claimed the profile first accounts it. Once the
+// profile is claimed, the dataset may read it again, e.g. on retry.
+// Profiles without an ID can't be deduplicated and are always accounted.
+func (s *profileSet) claim(ds *block.Dataset, id []parquet.Value, fp model.Fingerprint) bool {
+	if len(id) == 0 {
+		return true
+	}
+	var k profileKey
+	copy(k.id[:], id[0].ByteArray())
+	if k.id == uuid.Nil {
+		return true
+	}
+	k.fp = fp
+	s.m.Lock()
+	defer s.m.Unlock()
+	owner, ok := s.seen[k]
+	if !ok {
+		s.seen[k] = ds
+		return true
+	}
+	return owner == ds
+}
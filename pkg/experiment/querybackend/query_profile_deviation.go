@@ -0,0 +1,224 @@
+package querybackend
+
+import (
+	"sync"
+
+	"github.com/grafana/dskit/runutil"
+	"github.com/prometheus/common/model"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend/block"
+	phlaremodel "github.com/grafana/pyroscope/pkg/model"
+)
+
+func init() {
+	registerQueryType(
+		querybackendv1.QueryType_QUERY_PROFILE_DEVIATION,
+		querybackendv1.ReportType_REPORT_PROFILE_DEVIATION,
+		queryProfileDeviation,
+		newProfileDeviationAggregator,
+		noQueryTimeout,
+		[]block.Section{
+			block.SectionTSDB,
+			block.SectionProfiles,
+			block.SectionSymbols,
+		}...,
+	)
+}
+
+// queryProfileDeviation resolves the trees of the target profiles and of
+// all the profiles of the dataset. The cohort is the difference: it is
+// only derived once the reports are merged.
+func queryProfileDeviation(q *queryContext, query *querybackendv1.Query) (*querybackendv1.Report, error) {
+	tree, profiles, err := resolveDeviationTree(q)
+	if err != nil {
+		return nil, err
+	}
+	r := &querybackendv1.ProfileDeviationReport{
+		Query:    query.ProfileDeviation.CloneVT(),
+		Tree:     tree.Bytes(-1),
+		Profiles: profiles,
+	}
+	if target, ok := q.withProfileTime(query.ProfileDeviation.GetProfileTime()); ok {
+		if tree, r.TargetProfiles, err = resolveDeviationTree(target); err != nil {
+			return nil, err
+		}
+		r.TargetTree = tree.Bytes(-1)
+	}
+	return &querybackendv1.Report{ProfileDeviation: r}, nil
+}
+
+// withProfileTime returns a copy of the query context that only selects
+// the profiles taken at the given time, in milliseconds. The profile
+// timestamps are truncated to milliseconds before they are compared.
+func (q *queryContext) withProfileTime(t int64) (*queryContext, bool) {
+	r := *q.req
+	r.startTime = max(r.startTime, model.Time(t).UnixNano())
+	r.endTime = min(r.endTime, model.Time(t+1).UnixNano()-1)
+	if r.startTime > r.endTime {
+		return nil, false
+	}
+	c := *q
+	c.req = &r
+	return &c, true
+}
+
+func resolveDeviationTree(q *queryContext) (*phlaremodel.Tree, uint64, error) {
+	profiles, err := countProfiles(q)
+	if err != nil || profiles == 0 {
+		return new(phlaremodel.Tree), 0, err
+	}
+	tree, _, err := resolveTree(q, nil)
+	return tree, profiles, err
+}
+
+// countProfiles returns the number of the profiles matching the query.
+func countProfiles(q *queryContext) (n uint64, err error) {
+	entries, err := profileEntryIterator(q, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer runutil.CloseWithErrCapture(&err, entries, "failed to close profile entry iterator")
+	for entries.Next() {
+		n++
+	}
+	return n, entries.Err()
+}
+
+type profileDeviationAggregator struct {
+	init    sync.Once
+	query   *querybackendv1.ProfileDeviationQuery
+	m       sync.Mutex
+	target  *phlaremodel.TreeMerger
+	tree    *phlaremodel.TreeMerger
+	targets uint64
+	total   uint64
+}
+
+func newProfileDeviationAggregator(*querybackendv1.InvokeRequest) aggregator {
+	return &profileDeviationAggregator{
+		target: phlaremodel.NewTreeMerger(),
+		tree:   phlaremodel.NewTreeMerger(),
+	}
+}
+
+func (a *profileDeviationAggregator) aggregate(report *querybackendv1.Report) error {
+	r := report.ProfileDeviation
+	a.init.Do(func() {
+		a.query = r.Query.CloneVT()
+	})
+	a.m.Lock()
+	a.targets += r.TargetProfiles
+	a.total += r.Profiles
+	a.m.Unlock()
+	// The nodes of the partial report are discarded:
+	// the deviation is computed for the merged trees.
+	if err := a.target.MergeTreeBytes(r.TargetTree); err != nil {
+		return err
+	}
+	return a.tree.MergeTreeBytes(r.Tree)
+}
+
+func (a *profileDeviationAggregator) build() *querybackendv1.Report {
+	target, tree := a.target.Tree(), a.tree.Tree()
+	r := &querybackendv1.ProfileDeviationReport{
+		Query:          a.query,
+		TargetTree:     target.Bytes(-1),
+		Tree:           tree.Bytes(-1),
+		TargetProfiles: a.targets,
+		Profiles:       a.total,
+	}
+	d := newProfileDeviation()
+	d.addTree(tree, false)
+	d.addTree(target, true)
+	r.Nodes = d.proto(tree, a.query.GetMaxNodes(), a.targets, a.total-a.targets)
+	return &querybackendv1.Report{ProfileDeviation: r}
+}
+
+// profileDeviation accumulates the node totals of the target profiles and
+// of all the profiles. It is not safe for concurrent use.
+type profileDeviation struct {
+	root deviationNode
+}
+
+type deviationNode struct {
+	children map[string]*deviationNode
+	target   int64
+	total    int64
+}
+
+func newProfileDeviation() *profileDeviation {
+	return new(profileDeviation)
+}
+
+func (n *deviationNode) child(name string) *deviationNode {
+	if n.children == nil {
+		n.children = make(map[string]*deviationNode)
+	}
+	c, ok := n.children[name]
+	if !ok {
+		c = new(deviationNode)
+		n.children[name] = c
+	}
+	return c
+}
+
+func (d *profileDeviation) addTree(t *phlaremodel.Tree, target bool) {
+	t.IterateStacks(func(_ string, self int64, stack []string) {
+		n := &d.root
+		for i := len(stack) - 1; i >= 0; i-- {
+			n = n.child(stack[i])
+			if target {
+				n.target += self
+			} else {
+				n.total += self
+			}
+		}
+	})
+}
+
+// proto returns the deviation of the nodes present in the tree of all the
+// profiles, truncated to maxNodes. The target values and the cohort values
+// are averaged over the number of the profiles.
+func (d *profileDeviation) proto(t *phlaremodel.Tree, maxNodes int64, targets, cohort uint64) []*querybackendv1.ProfileDeviationNode {
+	if maxNodes > 0 {
+		truncated, err := phlaremodel.UnmarshalTree(t.Bytes(maxNodes))
+		if err != nil {
+			return nil
+		}
+		t = truncated
+	}
+	average := func(v int64, n uint64) float64 {
+		if n == 0 {
+			return 0
+		}
+		return float64(v) / float64(n)
+	}
+	var nodes []*querybackendv1.ProfileDeviationNode
+	visited := make(map[*deviationNode]int32)
+	t.IterateStacks(func(_ string, _ int64, stack []string) {
+		n := &d.root
+		parent := int32(-1)
+		for i := len(stack) - 1; i >= 0; i-- {
+			name := stack[i]
+			if n = n.children[name]; n == nil {
+				return
+			}
+			j, ok := visited[n]
+			if !ok {
+				j = int32(len(nodes))
+				visited[n] = j
+				x := &querybackendv1.ProfileDeviationNode{
+					Parent:  parent,
+					Name:    name,
+					Target:  average(n.target, targets),
+					Average: average(n.total-n.target, cohort),
+				}
+				x.Deviation = x.Target - x.Average
+				nodes = append(nodes, x)
+			}
+			parent = j
+		}
+	})
+	return nodes
+}
@@ -0,0 +1,104 @@
+package querybackend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+func Test_QueryProfileDeviation(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	invoke := func(query *querybackendv1.Query) *querybackendv1.Report {
+		resp, err := reader.Invoke(context.Background(), newTestInvokeRequest(blocks, `{service_name=~".+", __type__="cpu"}`, query))
+		require.NoError(t, err)
+		require.Len(t, resp.Reports, 1)
+		return resp.Reports[0]
+	}
+
+	// The latest profile is compared with the rest.
+	timeRange := invoke(&querybackendv1.Query{
+		QueryType: querybackendv1.QueryType_QUERY_TIME_RANGE,
+		TimeRange: &querybackendv1.TimeRangeQuery{},
+	}).TimeRange
+	r := invoke(&querybackendv1.Query{
+		QueryType:        querybackendv1.QueryType_QUERY_PROFILE_DEVIATION,
+		ProfileDeviation: &querybackendv1.ProfileDeviationQuery{ProfileTime: timeRange.MaxTime},
+	}).ProfileDeviation
+	require.Positive(t, r.TargetProfiles)
+	require.Greater(t, r.Profiles, r.TargetProfiles)
+	require.NotEmpty(t, r.Nodes)
+
+	target, err := model.UnmarshalTree(r.TargetTree)
+	require.NoError(t, err)
+	tree, err := model.UnmarshalTree(r.Tree)
+	require.NoError(t, err)
+	var targetTotal, averageTotal float64
+	for i, n := range r.Nodes {
+		require.Less(t, int(n.Parent), i)
+		require.InDelta(t, n.Target-n.Average, n.Deviation, 1e-9)
+		if n.Parent < 0 {
+			targetTotal += n.Target
+			averageTotal += n.Average
+		}
+	}
+	require.InDelta(t, float64(target.Total())/float64(r.TargetProfiles), targetTotal, 1e-6)
+	cohort := float64(tree.Total()-target.Total()) / float64(r.Profiles-r.TargetProfiles)
+	require.InDelta(t, cohort, averageTotal, 1e-6)
+
+	limited := invoke(&querybackendv1.Query{
+		QueryType:        querybackendv1.QueryType_QUERY_PROFILE_DEVIATION,
+		ProfileDeviation: &querybackendv1.ProfileDeviationQuery{ProfileTime: timeRange.MaxTime, MaxNodes: 4},
+	}).ProfileDeviation
+	require.NotEmpty(t, limited.Nodes)
+	require.LessOrEqual(t, len(limited.Nodes), 4)
+
+	// No profiles are found at the time.
+	r = invoke(&querybackendv1.Query{
+		QueryType:        querybackendv1.QueryType_QUERY_PROFILE_DEVIATION,
+		ProfileDeviation: &querybackendv1.ProfileDeviationQuery{ProfileTime: timeRange.MaxTime + 1},
+	}).ProfileDeviation
+	require.Zero(t, r.TargetProfiles)
+	for _, n := range r.Nodes {
+		require.Zero(t, n.Target)
+		require.Equal(t, -n.Average, n.Deviation)
+	}
+}
+
+func Test_ProfileDeviationAggregator(t *testing.T) {
+	report := func(target, all map[string]int64, targets, profiles uint64) *querybackendv1.Report {
+		tt, at := new(model.Tree), new(model.Tree)
+		for name, v := range target {
+			tt.InsertStack(v, "root", name)
+		}
+		for name, v := range all {
+			at.InsertStack(v, "root", name)
+		}
+		return &querybackendv1.Report{ProfileDeviation: &querybackendv1.ProfileDeviationReport{
+			Query:          &querybackendv1.ProfileDeviationQuery{},
+			TargetTree:     tt.Bytes(-1),
+			Tree:           at.Bytes(-1),
+			TargetProfiles: targets,
+			Profiles:       profiles,
+		}}
+	}
+
+	a := newProfileDeviationAggregator(nil)
+	require.NoError(t, a.aggregate(report(map[string]int64{"a": 30}, map[string]int64{"a": 40, "b": 10}, 1, 3)))
+	require.NoError(t, a.aggregate(report(nil, map[string]int64{"a": 20, "b": 30}, 0, 2)))
+	r := a.build().ProfileDeviation
+	require.EqualValues(t, 1, r.TargetProfiles)
+	require.EqualValues(t, 5, r.Profiles)
+
+	// The cohort is of 4 profiles: a = (40+20-30)/4, b = (10+30)/4.
+	nodes := make(map[string]*querybackendv1.ProfileDeviationNode)
+	for _, n := range r.Nodes {
+		nodes[n.Name] = n
+	}
+	require.Equal(t, &querybackendv1.ProfileDeviationNode{Parent: -1, Name: "root", Target: 30, Average: 17.5, Deviation: 12.5}, nodes["root"])
+	require.Equal(t, &querybackendv1.ProfileDeviationNode{Parent: 0, Name: "a", Target: 30, Average: 7.5, Deviation: 22.5}, nodes["a"])
+	require.Equal(t, &querybackendv1.ProfileDeviationNode{Parent: 0, Name: "b", Target: 0, Average: 10, Deviation: -10}, nodes["b"])
+}
@@ -4,17 +4,19 @@ import (
 	"github.com/parquet-go/parquet-go"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
 
 	"github.com/grafana/pyroscope/pkg/iter"
 	phlaremodel "github.com/grafana/pyroscope/pkg/model"
+	phlarerelabel "github.com/grafana/pyroscope/pkg/model/relabel"
 	"github.com/grafana/pyroscope/pkg/phlaredb"
 	parquetquery "github.com/grafana/pyroscope/pkg/phlaredb/query"
 	schemav1 "github.com/grafana/pyroscope/pkg/phlaredb/schemas/v1"
 	"github.com/grafana/pyroscope/pkg/phlaredb/tsdb/index"
 )
 
-func profileEntryIterator(q *queryContext, groupBy ...string) (iter.Iterator[ProfileEntry], error) {
-	series, err := getSeriesLabels(q.ds.Index(), q.req.matchers, groupBy...)
+func profileEntryIterator(q *queryContext, rules []*relabel.Config, groupBy ...string) (iter.Iterator[ProfileEntry], error) {
+	series, err := getSeriesLabels(q.ds.Index(), q.req.matchers, rules, groupBy...)
 	if err != nil {
 		return nil, err
 	}
@@ -22,8 +24,12 @@ func profileEntryIterator(q *queryContext, groupBy ...string) (iter.Iterator[Pro
 		q.ds.Profiles().Column(q.ctx, "SeriesIndex", parquetquery.NewMapPredicate(series)),
 		q.ds.Profiles().Column(q.ctx, "TimeNanos", parquetquery.NewIntBetweenPredicate(q.req.startTime, q.req.endTime)),
 	)
+	var partitions parquetquery.Predicate
+	if len(q.req.partitions) > 0 {
+		partitions = parquetquery.NewMapPredicate(q.req.partitions)
+	}
 	results = parquetquery.NewBinaryJoinIterator(0, results,
-		q.ds.Profiles().Column(q.ctx, "StacktracePartition", nil),
+		q.ds.Profiles().Column(q.ctx, "StacktracePartition", partitions),
 	)
 
 	buf := make([][]parquet.Value, 3)
@@ -63,11 +69,17 @@ type seriesLabels struct {
 	labels      phlaremodel.Labels
 }
 
-func getSeriesLabels(reader phlaredb.IndexReader, matchers []*labels.Matcher, by ...string) (map[uint32]seriesLabels, error) {
+// getSeriesLabels returns the labels of the series matching the selector.
+// If relabeling rules are provided, they are applied to the series labels
+// before the "by" labels are selected; dropped series are not included.
+func getSeriesLabels(reader phlaredb.IndexReader, matchers []*labels.Matcher, rules []*relabel.Config, by ...string) (map[uint32]seriesLabels, error) {
 	postings, err := getPostings(reader, matchers...)
 	if err != nil {
 		return nil, err
 	}
+	if len(rules) > 0 {
+		return getSeriesLabelsWithRelabeling(reader, postings, rules, by...)
+	}
 	chunks := make([]index.ChunkMeta, 1)
 	series := make(map[uint32]seriesLabels)
 	l := make(phlaremodel.Labels, 0, 6)
@@ -88,3 +100,29 @@ func getSeriesLabels(reader phlaredb.IndexReader, matchers []*labels.Matcher, by
 
 	return series, postings.Err()
 }
+
+func getSeriesLabelsWithRelabeling(reader phlaredb.IndexReader, postings index.Postings, rules []*relabel.Config, by ...string) (map[uint32]seriesLabels, error) {
+	chunks := make([]index.ChunkMeta, 1)
+	series := make(map[uint32]seriesLabels)
+	l := make(phlaremodel.Labels, 0, 6)
+	for postings.Next() {
+		if _, err := reader.Series(postings.At(), &l, &chunks); err != nil {
+			return nil, err
+		}
+		if _, ok := series[chunks[0].SeriesIndex]; ok {
+			continue
+		}
+		relabeled, keep := phlarerelabel.Process(l.Clone(), rules...)
+		if !keep {
+			continue
+		}
+		if len(by) > 0 {
+			relabeled = relabeled.WithLabels(by...)
+		}
+		series[chunks[0].SeriesIndex] = seriesLabels{
+			fingerprint: model.Fingerprint(relabeled.Hash()),
+			labels:      relabeled,
+		}
+	}
+	return series, postings.Err()
+}
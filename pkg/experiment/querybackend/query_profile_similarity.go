@@ -0,0 +1,205 @@
+package querybackend
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend/block"
+)
+
+func init() {
+	registerQueryType(
+		querybackendv1.QueryType_QUERY_PROFILE_SIMILARITY,
+		querybackendv1.ReportType_REPORT_PROFILE_SIMILARITY,
+		queryProfileSimilarity,
+		newProfileSimilarityAggregator,
+		noQueryTimeout,
+		[]block.Section{
+			block.SectionTSDB,
+			block.SectionProfiles,
+			block.SectionSymbols,
+		}...,
+	)
+}
+
+// queryProfileSimilarity resolves the trees of the two sides, and reports
+// the self values of the functions in each of them. The partial reports
+// are not scored: the hot sets depend on the aggregated values.
+func queryProfileSimilarity(q *queryContext, query *querybackendv1.Query) (*querybackendv1.Report, error) {
+	ps := query.ProfileSimilarity
+	if ps.GetTopK() < 0 {
+		return nil, fmt.Errorf("profile similarity: top k must not be negative")
+	}
+	functions := make(similarityFunctions)
+	sides := []struct {
+		name     string
+		selector string
+		value    func(*similarityFunction) *int64
+	}{
+		{"left", ps.GetLeftSelector(), func(f *similarityFunction) *int64 { return &f.left }},
+		{"right", ps.GetRightSelector(), func(f *similarityFunction) *int64 { return &f.right }},
+	}
+	for _, s := range sides {
+		tree, err := flameGraphDiffSide(q, s.selector)
+		if err != nil {
+			return nil, fmt.Errorf("%s side: %w", s.name, err)
+		}
+		tree.IterateStacks(func(name string, self int64, _ []string) {
+			*s.value(functions.get(name)) += self
+		})
+	}
+	return &querybackendv1.Report{
+		ProfileSimilarity: &querybackendv1.ProfileSimilarityReport{
+			Query:     ps.CloneVT(),
+			Functions: functions.proto(false),
+		},
+	}, nil
+}
+
+type similarityFunction struct {
+	left     int64
+	right    int64
+	leftHot  bool
+	rightHot bool
+}
+
+type similarityFunctions map[string]*similarityFunction
+
+func (m similarityFunctions) get(name string) *similarityFunction {
+	f, ok := m[name]
+	if !ok {
+		f = new(similarityFunction)
+		m[name] = f
+	}
+	return f
+}
+
+func (m similarityFunctions) merge(r *querybackendv1.ProfileSimilarityReport) {
+	for _, x := range r.Functions {
+		f := m.get(x.Name)
+		f.left += x.LeftSelf
+		f.right += x.RightSelf
+	}
+}
+
+// markHot marks the top k functions with the highest non-zero
+// self values on each of the sides. Ties are broken by name.
+func (m similarityFunctions) markHot(k int64) {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sides := []struct {
+		value func(*similarityFunction) int64
+		mark  func(*similarityFunction)
+	}{
+		{func(f *similarityFunction) int64 { return f.left }, func(f *similarityFunction) { f.leftHot = true }},
+		{func(f *similarityFunction) int64 { return f.right }, func(f *similarityFunction) { f.rightHot = true }},
+	}
+	for _, s := range sides {
+		slices.SortFunc(names, func(a, b string) int {
+			if c := cmp.Compare(s.value(m[b]), s.value(m[a])); c != 0 {
+				return c
+			}
+			return strings.Compare(a, b)
+		})
+		for i, name := range names {
+			f := m[name]
+			if (k > 0 && int64(i) >= k) || s.value(f) <= 0 {
+				break
+			}
+			s.mark(f)
+		}
+	}
+}
+
+// score returns the similarity of the hot sets.
+func (m similarityFunctions) score(metric querybackendv1.ProfileSimilarityMetric) float64 {
+	var union, intersection int
+	var dot, left, right float64
+	for _, f := range m {
+		if !f.leftHot && !f.rightHot {
+			continue
+		}
+		union++
+		if f.leftHot && f.rightHot {
+			intersection++
+		}
+		l, r := float64(f.left), float64(f.right)
+		dot += l * r
+		left += l * l
+		right += r * r
+	}
+	if metric == querybackendv1.ProfileSimilarityMetric_PROFILE_SIMILARITY_METRIC_COSINE {
+		if left == 0 || right == 0 {
+			return 0
+		}
+		return dot / (math.Sqrt(left) * math.Sqrt(right))
+	}
+	if union == 0 {
+		return 0
+	}
+	return float64(intersection) / float64(union)
+}
+
+// proto returns the functions ordered by name: either all of
+// them, or only the ones of the union of the hot sets.
+func (m similarityFunctions) proto(hotOnly bool) []*querybackendv1.SimilarityFunction {
+	names := make([]string, 0, len(m))
+	for name, f := range m {
+		if !hotOnly || f.leftHot || f.rightHot {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	functions := make([]*querybackendv1.SimilarityFunction, len(names))
+	for i, name := range names {
+		f := m[name]
+		functions[i] = &querybackendv1.SimilarityFunction{
+			Name:      name,
+			LeftSelf:  f.left,
+			RightSelf: f.right,
+			LeftHot:   f.leftHot,
+			RightHot:  f.rightHot,
+		}
+	}
+	return functions
+}
+
+type profileSimilarityAggregator struct {
+	init      sync.Once
+	m         sync.Mutex
+	query     *querybackendv1.ProfileSimilarityQuery
+	functions similarityFunctions
+}
+
+func newProfileSimilarityAggregator(*querybackendv1.InvokeRequest) aggregator {
+	return &profileSimilarityAggregator{functions: make(similarityFunctions)}
+}
+
+func (a *profileSimilarityAggregator) aggregate(report *querybackendv1.Report) error {
+	r := report.ProfileSimilarity
+	a.init.Do(func() {
+		a.query = r.Query.CloneVT()
+	})
+	a.m.Lock()
+	a.functions.merge(r)
+	a.m.Unlock()
+	return nil
+}
+
+func (a *profileSimilarityAggregator) build() *querybackendv1.Report {
+	a.functions.markHot(a.query.GetTopK())
+	return &querybackendv1.Report{
+		ProfileSimilarity: &querybackendv1.ProfileSimilarityReport{
+			Query:     a.query,
+			Functions: a.functions.proto(true),
+			Score:     a.functions.score(a.query.GetMetric()),
+		},
+	}
+}
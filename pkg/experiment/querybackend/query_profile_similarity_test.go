@@ -0,0 +1,96 @@
+package querybackend
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+)
+
+func Test_QueryProfileSimilarity(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	invoke := func(query *querybackendv1.ProfileSimilarityQuery) *querybackendv1.ProfileSimilarityReport {
+		resp, err := reader.Invoke(context.Background(), newTestInvokeRequest(
+			blocks,
+			`{service_name=~".+", __type__="cpu"}`,
+			&querybackendv1.Query{
+				QueryType:         querybackendv1.QueryType_QUERY_PROFILE_SIMILARITY,
+				ProfileSimilarity: query,
+			},
+		))
+		require.NoError(t, err)
+		require.Len(t, resp.Reports, 1)
+		return resp.Reports[0].ProfileSimilarity
+	}
+
+	const ingester = `{service_name="pyroscope-test/ingester"}`
+	for _, metric := range []querybackendv1.ProfileSimilarityMetric{
+		querybackendv1.ProfileSimilarityMetric_PROFILE_SIMILARITY_METRIC_JACCARD,
+		querybackendv1.ProfileSimilarityMetric_PROFILE_SIMILARITY_METRIC_COSINE,
+	} {
+		r := invoke(&querybackendv1.ProfileSimilarityQuery{
+			LeftSelector:  ingester,
+			RightSelector: ingester,
+			TopK:          10,
+			Metric:        metric,
+		})
+		require.InDelta(t, 1, r.Score, 1e-9, metric.String())
+		require.Len(t, r.Functions, 10)
+
+		r = invoke(&querybackendv1.ProfileSimilarityQuery{
+			LeftSelector:  ingester,
+			RightSelector: `{service_name!="pyroscope-test/ingester"}`,
+			TopK:          10,
+			Metric:        metric,
+		})
+		require.GreaterOrEqual(t, r.Score, 0.0)
+		require.Less(t, r.Score, 1.0)
+	}
+
+	r := invoke(&querybackendv1.ProfileSimilarityQuery{
+		LeftSelector:  ingester,
+		RightSelector: `{service_name="unknown"}`,
+	})
+	require.Zero(t, r.Score)
+}
+
+func Test_ProfileSimilarityAggregator(t *testing.T) {
+	report := func(functions ...*querybackendv1.SimilarityFunction) *querybackendv1.Report {
+		return &querybackendv1.Report{
+			ProfileSimilarity: &querybackendv1.ProfileSimilarityReport{
+				Query: &querybackendv1.ProfileSimilarityQuery{TopK: 2},
+				// The flags of the partial reports are ignored.
+				Functions: functions,
+			},
+		}
+	}
+	build := func(metric querybackendv1.ProfileSimilarityMetric) *querybackendv1.ProfileSimilarityReport {
+		a := newProfileSimilarityAggregator(nil)
+		require.NoError(t, a.aggregate(report(
+			&querybackendv1.SimilarityFunction{Name: "a", LeftSelf: 10},
+			&querybackendv1.SimilarityFunction{Name: "b", LeftSelf: 5, RightSelf: 5, LeftHot: true},
+		)))
+		require.NoError(t, a.aggregate(report(
+			&querybackendv1.SimilarityFunction{Name: "c", RightSelf: 8},
+			&querybackendv1.SimilarityFunction{Name: "b", LeftSelf: 1},
+			&querybackendv1.SimilarityFunction{Name: "d", LeftSelf: 1, RightSelf: 1},
+		)))
+		a.(*profileSimilarityAggregator).query.Metric = metric
+		return a.build().ProfileSimilarity
+	}
+
+	// The hot sets are {a, b} and {c, b}.
+	r := build(querybackendv1.ProfileSimilarityMetric_PROFILE_SIMILARITY_METRIC_JACCARD)
+	require.Equal(t, []*querybackendv1.SimilarityFunction{
+		{Name: "a", LeftSelf: 10, LeftHot: true},
+		{Name: "b", LeftSelf: 6, RightSelf: 5, LeftHot: true, RightHot: true},
+		{Name: "c", RightSelf: 8, RightHot: true},
+	}, r.Functions)
+	require.InDelta(t, 1.0/3, r.Score, 1e-9)
+
+	r = build(querybackendv1.ProfileSimilarityMetric_PROFILE_SIMILARITY_METRIC_COSINE)
+	require.InDelta(t, 30/math.Sqrt(136*89), r.Score, 1e-9)
+}
@@ -0,0 +1,30 @@
+package querybackend
+
+import (
+	"time"
+)
+
+// resolveTimer splits the time spent resolving a tree between reading
+// the profiles from the table (I/O), and resolving the samples (CPU).
+// The time is accounted in laps: each lap is attributed to one of them.
+type resolveTimer struct {
+	io   time.Duration
+	cpu  time.Duration
+	last time.Time
+}
+
+func (t *resolveTimer) start() { t.last = time.Now() }
+
+// lap returns the time elapsed since the previous lap.
+func (t *resolveTimer) lap() time.Duration {
+	now := time.Now()
+	d := now.Sub(t.last)
+	t.last = now
+	return d
+}
+
+func (t *resolveTimer) observe(q *queryContext) {
+	queryType := q.queryType.String()
+	q.observe(q.metrics.resolveIODuration.WithLabelValues(queryType), t.io.Seconds())
+	q.observe(q.metrics.resolveCPUDuration.WithLabelValues(queryType), t.cpu.Seconds())
+}
@@ -0,0 +1,123 @@
+package querybackend
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend/block"
+)
+
+func init() {
+	registerQueryType(
+		querybackendv1.QueryType_QUERY_SELFTEST,
+		querybackendv1.ReportType_REPORT_SELFTEST,
+		querySelfTest,
+		newSelfTestAggregator,
+		noQueryTimeout,
+		// The sections are loaded by the query itself.
+	)
+}
+
+const defaultSelfTestMaxNodes = 16
+
+var selfTestSections = []block.Section{
+	block.SectionTSDB,
+	block.SectionProfiles,
+	block.SectionSymbols,
+}
+
+// querySelfTest loads the sections of the dataset one by one, measuring
+// the time each of them takes, then opens the dataset with all the sections,
+// as regular queries do, and resolves the tree. Therefore, the sections are
+// loaded twice. The datasets are not loaded into memory in full, so that
+// each of the sections is fetched individually.
+func querySelfTest(q *queryContext, query *querybackendv1.Query) (*querybackendv1.Report, error) {
+	if q.obj.Meta().Id != query.SelfTest.GetBlockId() {
+		return nil, nil
+	}
+	d := &querybackendv1.SelfTestDataset{
+		TenantId: q.meta.TenantId,
+		Name:     q.meta.Name,
+		Sections: make([]*querybackendv1.SelfTestSection, 0, len(selfTestSections)),
+	}
+	for _, sc := range selfTestSections {
+		ds := block.NewDataset(q.meta, q.obj)
+		block.WithDatasetMaxSizeLoadInMemory(0)(ds)
+		start := time.Now()
+		if err := ds.Open(q.ctx, sc); err != nil {
+			return nil, fmt.Errorf("self-test: loading %s: %w", sc, err)
+		}
+		d.Sections = append(d.Sections, &querybackendv1.SelfTestSection{
+			Name:              sc.String(),
+			LoadDurationNanos: time.Since(start).Nanoseconds(),
+		})
+		if err := ds.Close(); err != nil {
+			return nil, fmt.Errorf("self-test: closing %s: %w", sc, err)
+		}
+	}
+
+	c := *q
+	c.ds = block.NewDataset(q.meta, q.obj)
+	if err := c.ds.Open(c.ctx, selfTestSections...); err != nil {
+		return nil, fmt.Errorf("self-test: opening dataset: %w", err)
+	}
+	tree, _, err := resolveTree(&c, nil)
+	_ = c.close(err)
+	if err != nil {
+		return nil, fmt.Errorf("self-test: resolving tree: %w", err)
+	}
+	maxNodes := query.SelfTest.GetMaxNodes()
+	if maxNodes <= 0 {
+		maxNodes = defaultSelfTestMaxNodes
+	}
+	d.Tree = tree.Bytes(maxNodes)
+
+	resp := &querybackendv1.Report{
+		SelfTest: &querybackendv1.SelfTestReport{
+			Query:    query.SelfTest.CloneVT(),
+			Datasets: []*querybackendv1.SelfTestDataset{d},
+		},
+	}
+	return resp, nil
+}
+
+type selfTestAggregator struct {
+	init     sync.Once
+	query    *querybackendv1.SelfTestQuery
+	m        sync.Mutex
+	datasets []*querybackendv1.SelfTestDataset
+}
+
+func newSelfTestAggregator(*querybackendv1.InvokeRequest) aggregator {
+	return new(selfTestAggregator)
+}
+
+func (a *selfTestAggregator) aggregate(report *querybackendv1.Report) error {
+	r := report.SelfTest
+	a.init.Do(func() {
+		a.query = r.Query.CloneVT()
+	})
+	a.m.Lock()
+	a.datasets = append(a.datasets, r.Datasets...)
+	a.m.Unlock()
+	return nil
+}
+
+func (a *selfTestAggregator) build() *querybackendv1.Report {
+	slices.SortFunc(a.datasets, func(x, y *querybackendv1.SelfTestDataset) int {
+		if c := cmp.Compare(x.TenantId, y.TenantId); c != 0 {
+			return c
+		}
+		return cmp.Compare(x.Name, y.Name)
+	})
+	return &querybackendv1.Report{
+		SelfTest: &querybackendv1.SelfTestReport{
+			Query:    a.query,
+			Datasets: a.datasets,
+		},
+	}
+}
@@ -0,0 +1,53 @@
+package querybackend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+func Test_QuerySelfTest(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	invoke := func(blockID string) *querybackendv1.InvokeResponse {
+		resp, err := reader.Invoke(context.Background(), newTestInvokeRequest(
+			blocks,
+			`{service_name=~".+"}`,
+			&querybackendv1.Query{
+				QueryType: querybackendv1.QueryType_QUERY_SELFTEST,
+				SelfTest:  &querybackendv1.SelfTestQuery{BlockId: blockID, MaxNodes: 8},
+			},
+		))
+		require.NoError(t, err)
+		return resp
+	}
+
+	b := blocks[0]
+	resp := invoke(b.Id)
+	require.Len(t, resp.Reports, 1)
+	r := resp.Reports[0]
+	require.Equal(t, querybackendv1.ReportType_REPORT_SELFTEST, r.ReportType)
+	require.Len(t, r.SelfTest.Datasets, len(b.Datasets))
+	for _, d := range r.SelfTest.Datasets {
+		var sections []string
+		for _, s := range d.Sections {
+			sections = append(sections, s.Name)
+			require.Positive(t, s.LoadDurationNanos)
+		}
+		require.Equal(t, []string{"tsdb", "profiles", "symbols"}, sections)
+		tree, err := model.UnmarshalTree(d.Tree)
+		require.NoError(t, err)
+		require.Positive(t, tree.Total())
+	}
+
+	// The result is deterministic.
+	again := invoke(b.Id).Reports[0].SelfTest
+	for i, d := range again.Datasets {
+		require.Equal(t, r.SelfTest.Datasets[i].Tree, d.Tree)
+	}
+
+	require.Empty(t, invoke("unknown").Reports)
+}
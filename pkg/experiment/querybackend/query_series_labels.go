@@ -19,6 +19,7 @@ func init() {
 		querybackendv1.ReportType_REPORT_SERIES_LABELS,
 		querySeriesLabels,
 		newSeriesLabelsAggregator,
+		indexQueryTimeout,
 		[]block.Section{block.SectionTSDB}...,
 	)
 }
@@ -0,0 +1,171 @@
+package querybackend
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend/block"
+)
+
+func init() {
+	registerQueryType(
+		querybackendv1.QueryType_QUERY_STACK_DEPTH,
+		querybackendv1.ReportType_REPORT_STACK_DEPTH,
+		queryStackDepth,
+		newStackDepthAggregator,
+		noQueryTimeout,
+		[]block.Section{
+			block.SectionTSDB,
+			block.SectionProfiles,
+			block.SectionSymbols,
+		}...,
+	)
+}
+
+// defaultStackDepthBounds are the powers of two from 1 to 8192.
+var defaultStackDepthBounds = func() []int64 {
+	bounds := make([]int64, 14)
+	for i := range bounds {
+		bounds[i] = 1 << i
+	}
+	return bounds
+}()
+
+// queryStackDepth builds the histogram of the stack trace depths. The
+// stack traces are only resolved into locations: the tree is not built,
+// and the locations are not symbolized.
+func queryStackDepth(q *queryContext, query *querybackendv1.Query) (*querybackendv1.Report, error) {
+	h, err := newStackDepthHistogram(query.StackDepth.GetBounds())
+	if err != nil {
+		return nil, err
+	}
+	values, err := readStackValues(q)
+	if err != nil {
+		return nil, err
+	}
+	for partition, p := range values.partitions {
+		if err = h.addPartition(q, partition, p); err != nil {
+			return nil, err
+		}
+	}
+	return &querybackendv1.Report{
+		StackDepth: h.proto(query.StackDepth.CloneVT()),
+	}, nil
+}
+
+type stackDepthHistogram struct {
+	bounds      []int64
+	stacktraces []int64
+	values      []int64
+	maxDepth    int64
+}
+
+func newStackDepthHistogram(bounds []int64) (*stackDepthHistogram, error) {
+	if len(bounds) == 0 {
+		bounds = slices.Clone(defaultStackDepthBounds)
+	}
+	for i, b := range bounds {
+		if b < 0 || (i > 0 && b <= bounds[i-1]) {
+			return nil, fmt.Errorf("stack depth histogram bounds must be non-negative and ascending")
+		}
+	}
+	return &stackDepthHistogram{
+		bounds:      bounds,
+		stacktraces: make([]int64, len(bounds)+1),
+		values:      make([]int64, len(bounds)+1),
+	}, nil
+}
+
+func (h *stackDepthHistogram) add(depth, stacktraces, value int64) {
+	i, _ := slices.BinarySearch(h.bounds, depth)
+	h.stacktraces[i] += stacktraces
+	h.values[i] += value
+	h.maxDepth = max(h.maxDepth, depth)
+}
+
+// addPartition resolves the locations of the stack traces
+// of the partition, and adds their depths to the histogram.
+func (h *stackDepthHistogram) addPartition(q *queryContext, partition uint64, values map[uint32]int64) error {
+	p, err := q.ds.Symbols().Partition(q.ctx, partition)
+	if err != nil {
+		return err
+	}
+	defer p.Release()
+	ids := make([]uint32, 0, len(values))
+	for id := range values {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return p.Symbols().Stacktraces.ResolveStacktraceLocations(q.ctx,
+		stackDepthInserter(func(id uint32, locations []int32) {
+			h.add(int64(len(locations)), 1, values[id])
+		}), ids)
+}
+
+type stackDepthInserter func(stacktraceID uint32, locations []int32)
+
+func (f stackDepthInserter) InsertStacktrace(stacktraceID uint32, locations []int32) {
+	f(stacktraceID, locations)
+}
+
+func (h *stackDepthHistogram) merge(r *querybackendv1.StackDepthReport) error {
+	if !slices.Equal(h.bounds, r.Bounds) || len(r.Buckets) != len(h.values) {
+		return fmt.Errorf("stack depth histogram bounds mismatch: %v, expected %v", r.Bounds, h.bounds)
+	}
+	for i, b := range r.Buckets {
+		h.stacktraces[i] += b.Stacktraces
+		h.values[i] += b.Value
+	}
+	h.maxDepth = max(h.maxDepth, r.MaxDepth)
+	return nil
+}
+
+func (h *stackDepthHistogram) proto(query *querybackendv1.StackDepthQuery) *querybackendv1.StackDepthReport {
+	r := &querybackendv1.StackDepthReport{
+		Query:    query,
+		Bounds:   h.bounds,
+		Buckets:  make([]*querybackendv1.StackDepthBucket, len(h.values)),
+		MaxDepth: h.maxDepth,
+	}
+	for i := range h.values {
+		r.Buckets[i] = &querybackendv1.StackDepthBucket{
+			Stacktraces: h.stacktraces[i],
+			Value:       h.values[i],
+		}
+	}
+	return r
+}
+
+type stackDepthAggregator struct {
+	init      sync.Once
+	m         sync.Mutex
+	query     *querybackendv1.StackDepthQuery
+	histogram *stackDepthHistogram
+	initErr   error
+}
+
+func newStackDepthAggregator(*querybackendv1.InvokeRequest) aggregator {
+	return new(stackDepthAggregator)
+}
+
+func (a *stackDepthAggregator) aggregate(report *querybackendv1.Report) error {
+	r := report.StackDepth
+	a.init.Do(func() {
+		a.query = r.Query.CloneVT()
+		a.histogram, a.initErr = newStackDepthHistogram(r.Bounds)
+	})
+	if a.initErr != nil {
+		return a.initErr
+	}
+	a.m.Lock()
+	defer a.m.Unlock()
+	return a.histogram.merge(r)
+}
+
+func (a *stackDepthAggregator) build() *querybackendv1.Report {
+	return &querybackendv1.Report{
+		StackDepth: a.histogram.proto(a.query),
+	}
+}
@@ -0,0 +1,85 @@
+package querybackend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+func Test_QueryStackDepth(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	invoke := func(query *querybackendv1.Query) (*querybackendv1.Report, error) {
+		resp, err := reader.Invoke(context.Background(), newTestInvokeRequest(blocks, `{service_name=~".+", __type__="cpu"}`, query))
+		if err != nil {
+			return nil, err
+		}
+		require.Len(t, resp.Reports, 1)
+		return resp.Reports[0], nil
+	}
+
+	r, err := invoke(&querybackendv1.Query{
+		QueryType:  querybackendv1.QueryType_QUERY_STACK_DEPTH,
+		StackDepth: new(querybackendv1.StackDepthQuery),
+	})
+	require.NoError(t, err)
+	h := r.StackDepth
+	require.Equal(t, defaultStackDepthBounds, h.Bounds)
+	require.Len(t, h.Buckets, len(h.Bounds)+1)
+	var total int64
+	for _, b := range h.Buckets {
+		total += b.Value
+	}
+	r, err = invoke(&querybackendv1.Query{
+		QueryType: querybackendv1.QueryType_QUERY_TREE,
+		Tree:      new(querybackendv1.TreeQuery),
+	})
+	require.NoError(t, err)
+	tree := model.MustUnmarshalTree(r.Tree.Tree)
+	require.Equal(t, tree.Total(), total)
+	require.Positive(t, h.MaxDepth)
+
+	// All the stack traces are in the overflow bucket.
+	r, err = invoke(&querybackendv1.Query{
+		QueryType:  querybackendv1.QueryType_QUERY_STACK_DEPTH,
+		StackDepth: &querybackendv1.StackDepthQuery{Bounds: []int64{0}},
+	})
+	require.NoError(t, err)
+	require.Zero(t, r.StackDepth.Buckets[0].Value)
+	require.Equal(t, total, r.StackDepth.Buckets[1].Value)
+
+	_, err = invoke(&querybackendv1.Query{
+		QueryType:  querybackendv1.QueryType_QUERY_STACK_DEPTH,
+		StackDepth: &querybackendv1.StackDepthQuery{Bounds: []int64{4, 2}},
+	})
+	require.Error(t, err)
+}
+
+func Test_StackDepthAggregator(t *testing.T) {
+	report := func(bounds []int64, depths ...int64) *querybackendv1.Report {
+		h, err := newStackDepthHistogram(bounds)
+		require.NoError(t, err)
+		for _, d := range depths {
+			h.add(d, 1, 10)
+		}
+		return &querybackendv1.Report{StackDepth: h.proto(&querybackendv1.StackDepthQuery{Bounds: bounds})}
+	}
+
+	bounds := []int64{2, 8}
+	a := newStackDepthAggregator(nil)
+	require.NoError(t, a.aggregate(report(bounds, 1, 2, 3)))
+	require.NoError(t, a.aggregate(report(bounds, 8, 20)))
+	require.Error(t, a.aggregate(report([]int64{4}, 1)))
+
+	r := a.build().StackDepth
+	require.Equal(t, bounds, r.Bounds)
+	require.Equal(t, []*querybackendv1.StackDepthBucket{
+		{Stacktraces: 2, Value: 20},
+		{Stacktraces: 2, Value: 20},
+		{Stacktraces: 1, Value: 10},
+	}, r.Buckets)
+	require.Equal(t, int64(20), r.MaxDepth)
+}
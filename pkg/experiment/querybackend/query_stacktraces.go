@@ -0,0 +1,292 @@
+package querybackend
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+
+	"github.com/grafana/dskit/runutil"
+	"github.com/parquet-go/parquet-go"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend/block"
+	parquetquery "github.com/grafana/pyroscope/pkg/phlaredb/query"
+	v1 "github.com/grafana/pyroscope/pkg/phlaredb/schemas/v1"
+)
+
+func init() {
+	registerQueryType(
+		querybackendv1.QueryType_QUERY_STACKTRACES,
+		querybackendv1.ReportType_REPORT_STACKTRACES,
+		queryStacktraces,
+		newStacktracesAggregator,
+		noQueryTimeout,
+		[]block.Section{
+			block.SectionTSDB,
+			block.SectionProfiles,
+			block.SectionSymbols,
+		}...,
+	)
+}
+
+// queryStacktraces sums the sample values of the matching profiles
+// per stack trace ID, and resolves the stack traces into the frames.
+func queryStacktraces(q *queryContext, query *querybackendv1.Query) (*querybackendv1.Report, error) {
+	values, err := readStackValues(q)
+	if err != nil {
+		return nil, err
+	}
+	table := newStacktraceTable()
+	resolver := q.newResolver()
+	defer resolver.Release()
+	key := func(partition uint64) stacktracePartitionKey {
+		return stacktracePartitionKey{
+			block:     q.obj.Meta().Id,
+			tenant:    q.meta.TenantId,
+			dataset:   q.meta.Name,
+			partition: partition,
+		}
+	}
+	for partition, p := range values.partitions {
+		samples := v1.Samples{
+			StacktraceIDs: make([]uint32, 0, len(p)),
+			Values:        make([]uint64, 0, len(p)),
+		}
+		k := key(partition)
+		for id, v := range p {
+			table.add(k, id, v)
+			// The values are only needed by the
+			// resolver to tell the stack traces.
+			samples.StacktraceIDs = append(samples.StacktraceIDs, id)
+			samples.Values = append(samples.Values, 1)
+		}
+		resolver.AddSamples(partition, samples)
+	}
+	err = resolver.Stacks(func(partition uint64, stacktraceID uint32, stack []string) {
+		table.setFrames(key(partition), stacktraceID, slices.Clone(stack))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &querybackendv1.Report{
+		Stacktraces: &querybackendv1.StacktracesReport{
+			Query:      query.Stacktraces.CloneVT(),
+			Partitions: table.proto(),
+		},
+	}, nil
+}
+
+// readStackValues reads the samples of the matching profiles, and sums
+// the values per stack trace of each partition.
+func readStackValues(q *queryContext) (values *stackValues, err error) {
+	entries, err := profileEntryIterator(q, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer runutil.CloseWithErrCapture(&err, entries, "failed to close profile entry iterator")
+
+	columns, err := q.sampleColumns(v1.SampleStacktraceColumns)
+	if err != nil {
+		return nil, err
+	}
+	profiles := parquetquery.NewRepeatedRowIteratorWithPrefetch(q.ctx, entries,
+		q.ds.Profiles().RowGroups(), q.ds.Profiles(), columns.Indices()...)
+	defer runutil.CloseWithErrCapture(&err, profiles, "failed to close profile stream")
+
+	values = newStackValues()
+	cpu := cpuMeter{budget: q.cpu}
+	for profiles.Next() {
+		p := profiles.At()
+		cpu.begin()
+		stacktraceIDs, sampleValues, err := q.validSamples(p.Values[0], p.Values[1])
+		if err != nil {
+			return nil, err
+		}
+		values.add(p.Row.Partition, stacktraceIDs, sampleValues)
+		if err = cpu.end(); err != nil {
+			return nil, err
+		}
+	}
+	if err = profiles.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// stackValues sums the sample values of the profiles per stack trace.
+type stackValues struct {
+	partitions map[uint64]map[uint32]int64
+}
+
+func newStackValues() *stackValues {
+	return &stackValues{partitions: make(map[uint64]map[uint32]int64)}
+}
+
+func (s *stackValues) add(partition uint64, stacktraceIDs, values []parquet.Value) {
+	p, ok := s.partitions[partition]
+	if !ok {
+		p = make(map[uint32]int64)
+		s.partitions[partition] = p
+	}
+	for i, sid := range stacktraceIDs {
+		if id, v := sid.Uint32(), values[i].Int64(); id != 0 && v != 0 {
+			p[id] += v
+		}
+	}
+}
+
+type stacktracePartitionKey struct {
+	block     string
+	tenant    string
+	dataset   string
+	partition uint64
+}
+
+func (k stacktracePartitionKey) compare(x stacktracePartitionKey) int {
+	if c := cmp.Compare(k.block, x.block); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(k.tenant, x.tenant); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(k.dataset, x.dataset); c != 0 {
+		return c
+	}
+	return cmp.Compare(k.partition, x.partition)
+}
+
+// stacktraceTable holds the sample values and the frames per
+// stack trace ID of the partitions. It is not safe for
+// concurrent use.
+type stacktraceTable struct {
+	partitions map[stacktracePartitionKey]*stacktracePartitionValues
+}
+
+type stacktracePartitionValues struct {
+	values map[uint32]int64
+	frames map[uint32][]string
+}
+
+func newStacktraceTable() *stacktraceTable {
+	return &stacktraceTable{partitions: make(map[stacktracePartitionKey]*stacktracePartitionValues)}
+}
+
+func (t *stacktraceTable) partition(k stacktracePartitionKey) *stacktracePartitionValues {
+	p, ok := t.partitions[k]
+	if !ok {
+		p = &stacktracePartitionValues{
+			values: make(map[uint32]int64),
+			frames: make(map[uint32][]string),
+		}
+		t.partitions[k] = p
+	}
+	return p
+}
+
+func (t *stacktraceTable) add(k stacktracePartitionKey, id uint32, v int64) {
+	t.partition(k).values[id] += v
+}
+
+// setFrames sets the frames of the stack trace, root first. The frames
+// of a stack trace are the same in all the reports of the partition.
+func (t *stacktraceTable) setFrames(k stacktracePartitionKey, id uint32, frames []string) {
+	t.partition(k).frames[id] = frames
+}
+
+func (t *stacktraceTable) merge(r *querybackendv1.StacktracesReport) {
+	for _, p := range r.Partitions {
+		k := stacktracePartitionKey{
+			block:     p.BlockId,
+			tenant:    p.TenantId,
+			dataset:   p.Dataset,
+			partition: p.Partition,
+		}
+		for i, id := range p.StacktraceIds {
+			if i < len(p.Values) {
+				t.add(k, id, p.Values[i])
+			}
+			if i < len(p.Stacktraces) {
+				frames := make([]string, 0, len(p.Stacktraces[i].Frames))
+				for _, f := range p.Stacktraces[i].Frames {
+					if f >= 0 && int(f) < len(p.Names) {
+						frames = append(frames, p.Names[f])
+					}
+				}
+				t.setFrames(k, id, frames)
+			}
+		}
+	}
+}
+
+func (t *stacktraceTable) proto() []*querybackendv1.StacktracePartition {
+	keys := make([]stacktracePartitionKey, 0, len(t.partitions))
+	for k := range t.partitions {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, stacktracePartitionKey.compare)
+	partitions := make([]*querybackendv1.StacktracePartition, len(keys))
+	for i, k := range keys {
+		p := t.partitions[k]
+		x := &querybackendv1.StacktracePartition{
+			BlockId:       k.block,
+			TenantId:      k.tenant,
+			Dataset:       k.dataset,
+			Partition:     k.partition,
+			StacktraceIds: make([]uint32, 0, len(p.values)),
+			Values:        make([]int64, 0, len(p.values)),
+			Stacktraces:   make([]*querybackendv1.StacktraceFrames, 0, len(p.values)),
+		}
+		for id := range p.values {
+			x.StacktraceIds = append(x.StacktraceIds, id)
+		}
+		slices.Sort(x.StacktraceIds)
+		names := make(map[string]int32)
+		for _, id := range x.StacktraceIds {
+			x.Values = append(x.Values, p.values[id])
+			frames := &querybackendv1.StacktraceFrames{Frames: make([]int32, len(p.frames[id]))}
+			for j, name := range p.frames[id] {
+				n, ok := names[name]
+				if !ok {
+					n = int32(len(x.Names))
+					names[name] = n
+					x.Names = append(x.Names, name)
+				}
+				frames.Frames[j] = n
+			}
+			x.Stacktraces = append(x.Stacktraces, frames)
+		}
+		partitions[i] = x
+	}
+	return partitions
+}
+
+type stacktracesAggregator struct {
+	init  sync.Once
+	m     sync.Mutex
+	query *querybackendv1.StacktracesQuery
+	table *stacktraceTable
+}
+
+func newStacktracesAggregator(*querybackendv1.InvokeRequest) aggregator {
+	return &stacktracesAggregator{table: newStacktraceTable()}
+}
+
+func (a *stacktracesAggregator) aggregate(report *querybackendv1.Report) error {
+	r := report.Stacktraces
+	a.init.Do(func() {
+		a.query = r.Query.CloneVT()
+	})
+	a.m.Lock()
+	a.table.merge(r)
+	a.m.Unlock()
+	return nil
+}
+
+func (a *stacktracesAggregator) build() *querybackendv1.Report {
+	return &querybackendv1.Report{
+		Stacktraces: &querybackendv1.StacktracesReport{
+			Query:      a.query,
+			Partitions: a.table.proto(),
+		},
+	}
+}
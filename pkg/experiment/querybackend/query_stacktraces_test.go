@@ -0,0 +1,109 @@
+package querybackend
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+func Test_QueryStacktraces(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	invoke := func(query *querybackendv1.Query) *querybackendv1.Report {
+		resp, err := reader.Invoke(context.Background(), newTestInvokeRequest(blocks, `{service_name=~".+", __type__="cpu"}`, query))
+		require.NoError(t, err)
+		require.Len(t, resp.Reports, 1)
+		return resp.Reports[0]
+	}
+
+	r := invoke(&querybackendv1.Query{
+		QueryType:   querybackendv1.QueryType_QUERY_STACKTRACES,
+		Stacktraces: new(querybackendv1.StacktracesQuery),
+	}).Stacktraces
+	require.NotEmpty(t, r.Partitions)
+	var total int64
+	resolved := new(model.Tree)
+	for i, p := range r.Partitions {
+		require.NotEmpty(t, p.BlockId)
+		require.NotEmpty(t, p.Dataset)
+		require.Len(t, p.Values, len(p.StacktraceIds))
+		require.Len(t, p.Stacktraces, len(p.StacktraceIds))
+		for j, v := range p.Values {
+			total += v
+			stack := make([]string, len(p.Stacktraces[j].Frames))
+			for k, f := range p.Stacktraces[j].Frames {
+				stack[k] = p.Names[f]
+			}
+			resolved.InsertStack(v, stack...)
+			if j > 0 {
+				require.Less(t, p.StacktraceIds[j-1], p.StacktraceIds[j])
+			}
+		}
+		if i > 0 {
+			k := func(p *querybackendv1.StacktracePartition) stacktracePartitionKey {
+				return stacktracePartitionKey{block: p.BlockId, tenant: p.TenantId, dataset: p.Dataset, partition: p.Partition}
+			}
+			require.Negative(t, k(r.Partitions[i-1]).compare(k(p)))
+		}
+	}
+
+	tree := model.MustUnmarshalTree(invoke(&querybackendv1.Query{
+		QueryType: querybackendv1.QueryType_QUERY_TREE,
+		Tree:      new(querybackendv1.TreeQuery),
+	}).Tree.Tree)
+	require.Equal(t, tree.Total(), total)
+	// The frames of the stack traces make up the tree.
+	require.Equal(t, tree.String(), resolved.String())
+}
+
+func Test_StacktracesAggregator(t *testing.T) {
+	// All the stack traces have a single frame named after the ID.
+	report := func(partition uint64, ids []uint32, values []int64) *querybackendv1.Report {
+		p := &querybackendv1.StacktracePartition{
+			BlockId:       "block",
+			Dataset:       "service",
+			Partition:     partition,
+			StacktraceIds: ids,
+			Values:        values,
+		}
+		for i, id := range ids {
+			p.Names = append(p.Names, fmt.Sprint(id))
+			p.Stacktraces = append(p.Stacktraces, &querybackendv1.StacktraceFrames{Frames: []int32{int32(i)}})
+		}
+		return &querybackendv1.Report{
+			Stacktraces: &querybackendv1.StacktracesReport{
+				Query:      new(querybackendv1.StacktracesQuery),
+				Partitions: []*querybackendv1.StacktracePartition{p},
+			},
+		}
+	}
+
+	a := newStacktracesAggregator(nil)
+	require.NoError(t, a.aggregate(report(2, []uint32{1, 5}, []int64{10, 20})))
+	require.NoError(t, a.aggregate(report(1, []uint32{3}, []int64{1})))
+	require.NoError(t, a.aggregate(report(2, []uint32{5, 7}, []int64{2, 3})))
+
+	frames := func(n int) []*querybackendv1.StacktraceFrames {
+		f := make([]*querybackendv1.StacktraceFrames, n)
+		for i := range f {
+			f[i] = &querybackendv1.StacktraceFrames{Frames: []int32{int32(i)}}
+		}
+		return f
+	}
+	require.Equal(t, []*querybackendv1.StacktracePartition{
+		{
+			BlockId: "block", Dataset: "service", Partition: 1,
+			StacktraceIds: []uint32{3}, Values: []int64{1},
+			Names: []string{"3"}, Stacktraces: frames(1),
+		},
+		{
+			BlockId: "block", Dataset: "service", Partition: 2,
+			StacktraceIds: []uint32{1, 5, 7}, Values: []int64{10, 22, 3},
+			Names: []string{"1", "5", "7"}, Stacktraces: frames(3),
+		},
+	}, a.build().Stacktraces.Partitions)
+}
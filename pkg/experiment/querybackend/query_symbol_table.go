@@ -0,0 +1,227 @@
+package querybackend
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+
+	"github.com/grafana/dskit/runutil"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend/block"
+	schemav1 "github.com/grafana/pyroscope/pkg/phlaredb/schemas/v1"
+)
+
+func init() {
+	registerQueryType(
+		querybackendv1.QueryType_QUERY_SYMBOL_TABLE,
+		querybackendv1.ReportType_REPORT_SYMBOL_TABLE,
+		querySymbolTable,
+		newSymbolTableAggregator,
+		noQueryTimeout,
+		[]block.Section{
+			block.SectionTSDB,
+			block.SectionProfiles,
+			block.SectionSymbols,
+		}...,
+	)
+}
+
+// querySymbolTable lists the locations of the stack trace partitions
+// of the matching profiles. The stack traces are not resolved: all
+// the locations of a partition are included.
+func querySymbolTable(q *queryContext, query *querybackendv1.Query) (r *querybackendv1.Report, err error) {
+	entries, err := profileEntryIterator(q, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer runutil.CloseWithErrCapture(&err, entries, "failed to close profile entry iterator")
+	partitions := make(map[uint64]struct{})
+	for entries.Next() {
+		partitions[entries.At().Partition] = struct{}{}
+	}
+	if err = entries.Err(); err != nil {
+		return nil, err
+	}
+
+	table := newSymbolTable(query.SymbolTable.GetUnsymbolizedOnly())
+	for p := range partitions {
+		partition, err := q.ds.Symbols().Partition(q.ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		s := partition.Symbols()
+		for i := range s.Locations {
+			table.addLocation(s.Strings, s.Mappings, &s.Locations[i])
+		}
+		partition.Release()
+	}
+
+	return &querybackendv1.Report{
+		SymbolTable: table.proto(query.SymbolTable),
+	}, nil
+}
+
+// symbolTable is a set of distinct locations and their mappings.
+type symbolTable struct {
+	unsymbolizedOnly bool
+	mappings         map[symbolMappingKey]int
+	locations        map[symbolLocationKey]bool
+}
+
+type symbolMappingKey struct {
+	memoryStart uint64
+	memoryLimit uint64
+	fileOffset  uint64
+	filename    string
+	buildID     string
+}
+
+type symbolLocationKey struct {
+	mapping int
+	address uint64
+}
+
+func newSymbolTable(unsymbolizedOnly bool) *symbolTable {
+	return &symbolTable{
+		unsymbolizedOnly: unsymbolizedOnly,
+		mappings:         make(map[symbolMappingKey]int),
+		locations:        make(map[symbolLocationKey]bool),
+	}
+}
+
+func (t *symbolTable) addMapping(k symbolMappingKey) int {
+	i, ok := t.mappings[k]
+	if !ok {
+		i = len(t.mappings)
+		t.mappings[k] = i
+	}
+	return i
+}
+
+// addLocation adds the location of a stack trace partition.
+// Locations without an address are ignored.
+func (t *symbolTable) addLocation(strings []string, mappings []schemav1.InMemoryMapping, loc *schemav1.InMemoryLocation) {
+	symbolized := len(loc.Line) > 0
+	if loc.Address == 0 || (symbolized && t.unsymbolizedOnly) {
+		return
+	}
+	var k symbolMappingKey
+	if int(loc.MappingId) < len(mappings) {
+		m := &mappings[loc.MappingId]
+		k = symbolMappingKey{
+			memoryStart: m.MemoryStart,
+			memoryLimit: m.MemoryLimit,
+			fileOffset:  m.FileOffset,
+			filename:    strings[m.Filename],
+			buildID:     strings[m.BuildId],
+		}
+	}
+	t.add(k, loc.Address, symbolized)
+}
+
+func (t *symbolTable) add(m symbolMappingKey, address uint64, symbolized bool) {
+	k := symbolLocationKey{mapping: t.addMapping(m), address: address}
+	t.locations[k] = t.locations[k] || symbolized
+}
+
+func (t *symbolTable) merge(r *querybackendv1.SymbolTableReport) {
+	for _, loc := range r.Locations {
+		var k symbolMappingKey
+		if int(loc.Mapping) < len(r.Mappings) {
+			m := r.Mappings[loc.Mapping]
+			k = symbolMappingKey{
+				memoryStart: m.MemoryStart,
+				memoryLimit: m.MemoryLimit,
+				fileOffset:  m.FileOffset,
+				filename:    m.Filename,
+				buildID:     m.BuildId,
+			}
+		}
+		t.add(k, loc.Address, loc.Symbolized)
+	}
+}
+
+func (t *symbolTable) proto(query *querybackendv1.SymbolTableQuery) *querybackendv1.SymbolTableReport {
+	keys := make([]symbolMappingKey, 0, len(t.mappings))
+	for k := range t.mappings {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, compareSymbolMappings)
+	// Mapping indices are reassigned in the sorted order.
+	index := make([]uint32, len(t.mappings))
+	r := &querybackendv1.SymbolTableReport{
+		Query:     query.CloneVT(),
+		Mappings:  make([]*querybackendv1.SymbolMapping, len(keys)),
+		Locations: make([]*querybackendv1.SymbolLocation, 0, len(t.locations)),
+	}
+	for i, k := range keys {
+		index[t.mappings[k]] = uint32(i)
+		r.Mappings[i] = &querybackendv1.SymbolMapping{
+			MemoryStart: k.memoryStart,
+			MemoryLimit: k.memoryLimit,
+			FileOffset:  k.fileOffset,
+			Filename:    k.filename,
+			BuildId:     k.buildID,
+		}
+	}
+	for k, symbolized := range t.locations {
+		r.Locations = append(r.Locations, &querybackendv1.SymbolLocation{
+			Address:    k.address,
+			Mapping:    index[k.mapping],
+			Symbolized: symbolized,
+		})
+	}
+	slices.SortFunc(r.Locations, func(a, b *querybackendv1.SymbolLocation) int {
+		if c := cmp.Compare(a.Mapping, b.Mapping); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Address, b.Address)
+	})
+	return r
+}
+
+func compareSymbolMappings(a, b symbolMappingKey) int {
+	if c := cmp.Compare(a.buildID, b.buildID); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.filename, b.filename); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.memoryStart, b.memoryStart); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.memoryLimit, b.memoryLimit); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.fileOffset, b.fileOffset)
+}
+
+type symbolTableAggregator struct {
+	init  sync.Once
+	m     sync.Mutex
+	query *querybackendv1.SymbolTableQuery
+	table *symbolTable
+}
+
+func newSymbolTableAggregator(*querybackendv1.InvokeRequest) aggregator {
+	return new(symbolTableAggregator)
+}
+
+func (a *symbolTableAggregator) aggregate(report *querybackendv1.Report) error {
+	r := report.SymbolTable
+	a.init.Do(func() {
+		a.query = r.Query.CloneVT()
+		a.table = newSymbolTable(a.query.GetUnsymbolizedOnly())
+	})
+	a.m.Lock()
+	a.table.merge(r)
+	a.m.Unlock()
+	return nil
+}
+
+func (a *symbolTableAggregator) build() *querybackendv1.Report {
+	return &querybackendv1.Report{
+		SymbolTable: a.table.proto(a.query),
+	}
+}
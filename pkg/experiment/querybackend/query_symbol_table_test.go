@@ -0,0 +1,107 @@
+package querybackend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	schemav1 "github.com/grafana/pyroscope/pkg/phlaredb/schemas/v1"
+)
+
+func Test_QuerySymbolTable(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	symbolTable := func(unsymbolizedOnly bool) *querybackendv1.SymbolTableReport {
+		resp, err := reader.Invoke(context.Background(), newTestInvokeRequest(
+			blocks,
+			`{service_name=~".+"}`,
+			&querybackendv1.Query{
+				QueryType:   querybackendv1.QueryType_QUERY_SYMBOL_TABLE,
+				SymbolTable: &querybackendv1.SymbolTableQuery{UnsymbolizedOnly: unsymbolizedOnly},
+			},
+		))
+		require.NoError(t, err)
+		require.Len(t, resp.Reports, 1)
+		return resp.Reports[0].SymbolTable
+	}
+
+	// The locations of the test profiles have no addresses.
+	r := symbolTable(true)
+	require.True(t, r.Query.UnsymbolizedOnly)
+	require.Empty(t, r.Locations)
+}
+
+func Test_SymbolTable_AddLocation(t *testing.T) {
+	strings := []string{"", "app", "build-id", "libc.so"}
+	mappings := []schemav1.InMemoryMapping{
+		{},
+		{MemoryStart: 0x1000, MemoryLimit: 0x2000, Filename: 1, BuildId: 2},
+		{MemoryStart: 0x3000, MemoryLimit: 0x4000, Filename: 3},
+	}
+	locations := []schemav1.InMemoryLocation{
+		{},
+		{Address: 0x1100, MappingId: 1},
+		{Address: 0x1200, MappingId: 1, Line: []schemav1.InMemoryLine{{FunctionId: 1, Line: 1}}},
+		{Address: 0x3100, MappingId: 2},
+		{Address: 0x1100, MappingId: 1},
+	}
+	table := func(unsymbolizedOnly bool) *querybackendv1.SymbolTableReport {
+		x := newSymbolTable(unsymbolizedOnly)
+		for i := range locations {
+			x.addLocation(strings, mappings, &locations[i])
+		}
+		return x.proto(new(querybackendv1.SymbolTableQuery))
+	}
+
+	r := table(false)
+	require.Equal(t, []*querybackendv1.SymbolMapping{
+		{MemoryStart: 0x3000, MemoryLimit: 0x4000, Filename: "libc.so"},
+		{MemoryStart: 0x1000, MemoryLimit: 0x2000, Filename: "app", BuildId: "build-id"},
+	}, r.Mappings)
+	require.Equal(t, []*querybackendv1.SymbolLocation{
+		{Address: 0x3100, Mapping: 0},
+		{Address: 0x1100, Mapping: 1},
+		{Address: 0x1200, Mapping: 1, Symbolized: true},
+	}, r.Locations)
+
+	r = table(true)
+	require.Equal(t, []*querybackendv1.SymbolLocation{
+		{Address: 0x3100, Mapping: 0},
+		{Address: 0x1100, Mapping: 1},
+	}, r.Locations)
+}
+
+func Test_SymbolTableAggregator(t *testing.T) {
+	report := func(locations ...*querybackendv1.SymbolLocation) *querybackendv1.Report {
+		return &querybackendv1.Report{
+			SymbolTable: &querybackendv1.SymbolTableReport{
+				Query: new(querybackendv1.SymbolTableQuery),
+				Mappings: []*querybackendv1.SymbolMapping{
+					{BuildId: "b", Filename: "b.so", MemoryStart: 0x2000, MemoryLimit: 0x3000},
+					{BuildId: "a", Filename: "a", MemoryStart: 0x1000, MemoryLimit: 0x2000},
+				},
+				Locations: locations,
+			},
+		}
+	}
+	a := newSymbolTableAggregator(new(querybackendv1.InvokeRequest))
+	require.NoError(t, a.aggregate(report(
+		&querybackendv1.SymbolLocation{Address: 0x2100, Mapping: 0},
+		&querybackendv1.SymbolLocation{Address: 0x1100, Mapping: 1},
+	)))
+	require.NoError(t, a.aggregate(report(
+		&querybackendv1.SymbolLocation{Address: 0x2100, Mapping: 0, Symbolized: true},
+		&querybackendv1.SymbolLocation{Address: 0x1050, Mapping: 1},
+	)))
+	r := a.build().SymbolTable
+	require.Equal(t, []*querybackendv1.SymbolMapping{
+		{BuildId: "a", Filename: "a", MemoryStart: 0x1000, MemoryLimit: 0x2000},
+		{BuildId: "b", Filename: "b.so", MemoryStart: 0x2000, MemoryLimit: 0x3000},
+	}, r.Mappings)
+	require.Equal(t, []*querybackendv1.SymbolLocation{
+		{Address: 0x1050, Mapping: 0},
+		{Address: 0x1100, Mapping: 0},
+		{Address: 0x2100, Mapping: 1, Symbolized: true},
+	}, r.Locations)
+}
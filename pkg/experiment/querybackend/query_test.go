@@ -0,0 +1,215 @@
+package querybackend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	metastorev1 "github.com/grafana/pyroscope/api/gen/proto/go/metastore/v1"
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend/block"
+)
+
+const (
+	testQueryBlocking  querybackendv1.QueryType  = 1003
+	testReportBlocking querybackendv1.ReportType = 1003
+)
+
+func init() {
+	registerQueryType(
+		testQueryBlocking,
+		testReportBlocking,
+		func(q *queryContext, _ *querybackendv1.Query) (*querybackendv1.Report, error) {
+			<-q.ctx.Done()
+			return nil, q.ctx.Err()
+		},
+		newTimeRangeAggregator,
+		50*time.Millisecond,
+		block.SectionTSDB,
+	)
+}
+
+func Test_QueryTimeout(t *testing.T) {
+	for _, tc := range []struct {
+		query    *querybackendv1.Query
+		expected time.Duration
+	}{
+		{
+			query:    &querybackendv1.Query{QueryType: querybackendv1.QueryType_QUERY_TREE},
+			expected: 0,
+		},
+		{
+			query:    &querybackendv1.Query{QueryType: querybackendv1.QueryType_QUERY_TREE, TimeoutMs: 100},
+			expected: 100 * time.Millisecond,
+		},
+		{
+			query:    &querybackendv1.Query{QueryType: querybackendv1.QueryType_QUERY_LABEL_NAMES},
+			expected: indexQueryTimeout,
+		},
+		{
+			query:    &querybackendv1.Query{QueryType: querybackendv1.QueryType_QUERY_LABEL_NAMES, TimeoutMs: 100},
+			expected: 100 * time.Millisecond,
+		},
+		{
+			// The client may not extend the default timeout.
+			query:    &querybackendv1.Query{QueryType: querybackendv1.QueryType_QUERY_LABEL_NAMES, TimeoutMs: 1e9},
+			expected: indexQueryTimeout,
+		},
+	} {
+		require.Equal(t, tc.expected, queryTimeout(tc.query))
+	}
+}
+
+func Test_QueryTimeout_Exceeded(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	_, err := reader.Invoke(context.Background(), &querybackendv1.InvokeRequest{
+		LabelSelector: "{}",
+		QueryPlan:     &querybackendv1.QueryPlan{Blocks: blocks[:1]},
+		Query:         []*querybackendv1.Query{{QueryType: testQueryBlocking}},
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorContains(t, err, "query timed out")
+	require.NotZero(t, testutil.ToFloat64(reader.metrics.queryTimeouts.WithLabelValues(testQueryBlocking.String())))
+}
+
+func Test_BlockReader_SectionMissing(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	md := blocks[0].CloneVT()
+	md.Datasets[0].TableOfContents = md.Datasets[0].TableOfContents[:1]
+	resp, err := reader.Invoke(context.Background(), &querybackendv1.InvokeRequest{
+		LabelSelector: "{}",
+		QueryPlan:     &querybackendv1.QueryPlan{Blocks: []*metastorev1.BlockMeta{md}},
+		Query: []*querybackendv1.Query{{
+			QueryType:  querybackendv1.QueryType_QUERY_LABEL_NAMES,
+			LabelNames: &querybackendv1.LabelNamesQuery{},
+		}},
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, resp.Diagnostics.GetSkippedBlocksCount())
+	skipped := resp.Diagnostics.SkippedBlocks[0]
+	require.Equal(t, md.Id, skipped.BlockId)
+	require.Equal(t, querybackendv1.SkipReason_SKIP_REASON_SECTION_MISSING, skipped.Reason)
+	require.Contains(t, skipped.Message, block.ErrSectionMissing.Error())
+
+	_, err = reader.Invoke(context.Background(), &querybackendv1.InvokeRequest{
+		LabelSelector: "{}",
+		QueryPlan:     &querybackendv1.QueryPlan{Blocks: []*metastorev1.BlockMeta{md}},
+		Options:       &querybackendv1.InvokeOptions{FailOnSkippedBlocks: true},
+		Query: []*querybackendv1.Query{{
+			QueryType:  querybackendv1.QueryType_QUERY_LABEL_NAMES,
+			LabelNames: &querybackendv1.LabelNamesQuery{},
+		}},
+	})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	require.ErrorContains(t, err, md.Id)
+}
+
+func Test_BlockReader_VerifyChecksums(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	req := newTestInvokeRequest(
+		blocks,
+		`{service_name=~".+", __type__="cpu"}`,
+		&querybackendv1.Query{
+			QueryType: querybackendv1.QueryType_QUERY_TREE,
+			Tree:      &querybackendv1.TreeQuery{MaxNodes: 16},
+		},
+	)
+	expected, err := reader.Invoke(context.Background(), req)
+	require.NoError(t, err)
+
+	req.Options = &querybackendv1.InvokeOptions{VerifyChecksums: true}
+	resp, err := reader.Invoke(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, expected.Reports[0].Tree.Tree, resp.Reports[0].Tree.Tree)
+	require.Zero(t, testutil.CollectAndCount(reader.metrics.sectionChecksumFailures))
+}
+
+func Test_BlockReader_QueryType(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	resp, err := reader.Invoke(context.Background(), newTestInvokeRequest(
+		blocks,
+		`{service_name=~".+", __type__="cpu"}`,
+		&querybackendv1.Query{
+			QueryType: querybackendv1.QueryType_QUERY_TREE,
+			Tree:      &querybackendv1.TreeQuery{MaxNodes: 16},
+		},
+		&querybackendv1.Query{
+			QueryType:  querybackendv1.QueryType_QUERY_LABEL_NAMES,
+			LabelNames: &querybackendv1.LabelNamesQuery{},
+		},
+	))
+	require.NoError(t, err)
+	require.Len(t, resp.Reports, 2)
+	for _, r := range resp.Reports {
+		require.Equal(t, QueryReportType(r.QueryType), r.ReportType)
+		if r.QueryType == querybackendv1.QueryType_QUERY_TREE {
+			require.Positive(t, r.Tree.SymbolsFormatVersion)
+		}
+	}
+}
+
+func Test_BlockReader_Batch(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	queries := []*querybackendv1.Query{
+		{
+			QueryType: querybackendv1.QueryType_QUERY_TREE,
+			Tree:      &querybackendv1.TreeQuery{MaxNodes: 16},
+		},
+		{
+			QueryType:  querybackendv1.QueryType_QUERY_LABEL_NAMES,
+			LabelNames: &querybackendv1.LabelNamesQuery{},
+		},
+		{
+			QueryType: querybackendv1.QueryType_QUERY_TREE,
+			Tree:      &querybackendv1.TreeQuery{MaxNodes: 4},
+		},
+	}
+	invoke := func(options *querybackendv1.InvokeOptions, queries ...*querybackendv1.Query) *querybackendv1.InvokeResponse {
+		req := newTestInvokeRequest(blocks, `{service_name=~".+", __type__="cpu"}`, queries...)
+		req.Options = options
+		resp, err := reader.Invoke(context.Background(), req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := invoke(&querybackendv1.InvokeOptions{Batch: true}, queries...)
+	require.Len(t, resp.Reports, len(queries))
+	for i, query := range queries {
+		r := resp.Reports[i]
+		require.EqualValues(t, i, r.QueryIndex)
+		require.Equal(t, QueryReportType(query.QueryType), r.ReportType)
+		expected := invoke(nil, query)
+		require.Len(t, expected.Reports, 1)
+		expected.Reports[0].QueryIndex = r.QueryIndex
+		require.Equal(t, expected.Reports[0].String(), r.String())
+	}
+}
+
+func Test_BlockReader_PinBlocks(t *testing.T) {
+	r, blocks := newTestBlockReader(t)
+	reader := NewBlockReader(r.log, r.storage, nil, Config{SectionCacheSize: 1 << 30, SectionCacheTTL: time.Minute})
+	req := newTestInvokeRequest(
+		blocks,
+		`{service_name=~".+"}`,
+		&querybackendv1.Query{
+			QueryType: querybackendv1.QueryType_QUERY_TREE,
+			Tree:      &querybackendv1.TreeQuery{MaxNodes: 16},
+		},
+	)
+	expected, err := reader.Invoke(context.Background(), req)
+	require.NoError(t, err)
+	require.Zero(t, testutil.ToFloat64(reader.metrics.sectionCachePinned))
+
+	req.Options = &querybackendv1.InvokeOptions{PinBlocks: true}
+	for i := 0; i < 2; i++ {
+		resp, err := reader.Invoke(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, expected.Reports[0].Tree.Tree, resp.Reports[0].Tree.Tree)
+	}
+	require.Equal(t, float64(len(blocks)), testutil.ToFloat64(reader.metrics.sectionCachePinned))
+}
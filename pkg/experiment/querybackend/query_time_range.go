@@ -0,0 +1,101 @@
+package querybackend
+
+import (
+	"math"
+	"sync"
+
+	"github.com/prometheus/common/model"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend/block"
+	phlaremodel "github.com/grafana/pyroscope/pkg/model"
+	"github.com/grafana/pyroscope/pkg/phlaredb/tsdb/index"
+)
+
+func init() {
+	registerQueryType(
+		querybackendv1.QueryType_QUERY_TIME_RANGE,
+		querybackendv1.ReportType_REPORT_TIME_RANGE,
+		queryTimeRange,
+		newTimeRangeAggregator,
+		indexQueryTimeout,
+		[]block.Section{block.SectionTSDB}...,
+	)
+}
+
+// queryTimeRange reports the time range of the matching profiles,
+// based on the series time bounds stored in the index. The range is
+// limited to the time range of the request.
+func queryTimeRange(q *queryContext, query *querybackendv1.Query) (*querybackendv1.Report, error) {
+	postings, err := getPostings(q.ds.Index(), q.req.matchers...)
+	if err != nil {
+		return nil, err
+	}
+	var tmp phlaremodel.Labels
+	var c []index.ChunkMeta
+	minTime, maxTime := int64(math.MaxInt64), int64(math.MinInt64)
+	for postings.Next() {
+		if _, err = q.ds.Index().Series(postings.At(), &tmp, &c); err != nil {
+			return nil, err
+		}
+		for _, chunk := range c {
+			if chunk.MaxTime < q.req.startTime || chunk.MinTime > q.req.endTime {
+				continue
+			}
+			minTime = min(minTime, max(chunk.MinTime, q.req.startTime))
+			maxTime = max(maxTime, min(chunk.MaxTime, q.req.endTime))
+		}
+	}
+	if err = postings.Err(); err != nil {
+		return nil, err
+	}
+	if minTime > maxTime {
+		// No profiles match the query.
+		return nil, nil
+	}
+	resp := &querybackendv1.Report{
+		TimeRange: &querybackendv1.TimeRangeReport{
+			Query:   query.TimeRange.CloneVT(),
+			MinTime: int64(model.TimeFromUnixNano(minTime)),
+			MaxTime: int64(model.TimeFromUnixNano(maxTime)),
+		},
+	}
+	return resp, nil
+}
+
+type timeRangeAggregator struct {
+	init    sync.Once
+	m       sync.Mutex
+	query   *querybackendv1.TimeRangeQuery
+	minTime int64
+	maxTime int64
+}
+
+func newTimeRangeAggregator(*querybackendv1.InvokeRequest) aggregator {
+	return &timeRangeAggregator{
+		minTime: math.MaxInt64,
+		maxTime: math.MinInt64,
+	}
+}
+
+func (a *timeRangeAggregator) aggregate(report *querybackendv1.Report) error {
+	r := report.TimeRange
+	a.init.Do(func() {
+		a.query = r.Query.CloneVT()
+	})
+	a.m.Lock()
+	a.minTime = min(a.minTime, r.MinTime)
+	a.maxTime = max(a.maxTime, r.MaxTime)
+	a.m.Unlock()
+	return nil
+}
+
+func (a *timeRangeAggregator) build() *querybackendv1.Report {
+	return &querybackendv1.Report{
+		TimeRange: &querybackendv1.TimeRangeReport{
+			Query:   a.query,
+			MinTime: a.minTime,
+			MaxTime: a.maxTime,
+		},
+	}
+}
@@ -0,0 +1,131 @@
+package querybackend
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	compactorv1 "github.com/grafana/pyroscope/api/gen/proto/go/compactor/v1"
+	metastorev1 "github.com/grafana/pyroscope/api/gen/proto/go/metastore/v1"
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+	"github.com/grafana/pyroscope/pkg/objstore/testutil"
+)
+
+func newTestBlockReader(t testing.TB) (*BlockReader, []*metastorev1.BlockMeta) {
+	bucket, _ := testutil.NewFilesystemBucket(t, context.Background(), "block/testdata")
+	var blocks compactorv1.CompletedJob
+	data, err := os.ReadFile("block/testdata/block-metas.json")
+	require.NoError(t, err)
+	require.NoError(t, protojson.Unmarshal(data, &blocks))
+	return NewBlockReader(log.NewNopLogger(), bucket, nil, Config{}), blocks.Blocks
+}
+
+// newTestInvokeRequest creates a request of the queries that covers
+// the blocks and the whole time range.
+func newTestInvokeRequest(
+	blocks []*metastorev1.BlockMeta,
+	selector string,
+	queries ...*querybackendv1.Query,
+) *querybackendv1.InvokeRequest {
+	return &querybackendv1.InvokeRequest{
+		EndTime:       math.MaxInt64 / int64(1e6),
+		LabelSelector: selector,
+		QueryPlan:     &querybackendv1.QueryPlan{Blocks: blocks},
+		Query:         queries,
+	}
+}
+
+// invokeTestTree runs the tree query against the blocks and returns
+// the report.
+func invokeTestTree(
+	reader *BlockReader,
+	blocks []*metastorev1.BlockMeta,
+	selector string,
+	query *querybackendv1.TreeQuery,
+) (*querybackendv1.TreeReport, error) {
+	resp, err := reader.Invoke(context.Background(), newTestInvokeRequest(blocks, selector, &querybackendv1.Query{
+		QueryType: querybackendv1.QueryType_QUERY_TREE,
+		Tree:      query,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Reports) != 1 {
+		return nil, fmt.Errorf("expected 1 report, got %d", len(resp.Reports))
+	}
+	return resp.Reports[0].Tree, nil
+}
+
+// queryTestTree runs the tree query against the blocks and returns
+// the resulting tree.
+func queryTestTree(
+	reader *BlockReader,
+	blocks []*metastorev1.BlockMeta,
+	selector string,
+	query *querybackendv1.TreeQuery,
+) (*model.Tree, error) {
+	r, err := invokeTestTree(reader, blocks, selector, query)
+	if err != nil {
+		return nil, err
+	}
+	return model.UnmarshalTree(r.Tree)
+}
+
+func newTestTimeRangeRequest(blocks []*metastorev1.BlockMeta, start, end int64) *querybackendv1.InvokeRequest {
+	return &querybackendv1.InvokeRequest{
+		StartTime:     start,
+		EndTime:       end,
+		LabelSelector: `{service_name=~".+"}`,
+		QueryPlan:     &querybackendv1.QueryPlan{Blocks: blocks},
+		Query: []*querybackendv1.Query{{
+			QueryType: querybackendv1.QueryType_QUERY_TIME_RANGE,
+			TimeRange: &querybackendv1.TimeRangeQuery{},
+		}},
+	}
+}
+
+func Test_QueryTimeRange(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+
+	t.Run("observed time range", func(t *testing.T) {
+		minTime, maxTime := int64(math.MaxInt64), int64(math.MinInt64)
+		for _, b := range blocks {
+			for _, ds := range b.Datasets {
+				minTime = min(minTime, ds.MinTime)
+				maxTime = max(maxTime, ds.MaxTime)
+			}
+		}
+		req := newTestTimeRangeRequest(blocks, 0, math.MaxInt64/int64(1e6))
+		resp, err := reader.Invoke(context.Background(), req)
+		require.NoError(t, err)
+		require.Len(t, resp.Reports, 1)
+		r := resp.Reports[0].TimeRange
+		require.Equal(t, minTime, r.MinTime)
+		require.Equal(t, maxTime, r.MaxTime)
+	})
+
+	t.Run("narrow time range", func(t *testing.T) {
+		ts := blocks[0].Datasets[0].MinTime
+		req := newTestTimeRangeRequest(blocks, ts, ts+1)
+		resp, err := reader.Invoke(context.Background(), req)
+		require.NoError(t, err)
+		require.Len(t, resp.Reports, 1)
+		r := resp.Reports[0].TimeRange
+		require.Equal(t, ts, r.MinTime)
+		require.Equal(t, ts, r.MaxTime)
+	})
+
+	t.Run("no matching profiles", func(t *testing.T) {
+		req := newTestTimeRangeRequest(blocks, 0, 1)
+		resp, err := reader.Invoke(context.Background(), req)
+		require.NoError(t, err)
+		require.Empty(t, resp.Reports)
+	})
+}
@@ -21,6 +21,7 @@ func init() {
 		querybackendv1.ReportType_REPORT_TIME_SERIES,
 		queryTimeSeries,
 		newTimeSeriesAggregator,
+		noQueryTimeout,
 		[]block.Section{
 			block.SectionTSDB,
 			block.SectionProfiles,
@@ -29,7 +30,7 @@ func init() {
 }
 
 func queryTimeSeries(q *queryContext, query *querybackendv1.Query) (r *querybackendv1.Report, err error) {
-	entries, err := profileEntryIterator(q, query.TimeSeries.GroupBy...)
+	entries, err := profileEntryIterator(q, nil, query.TimeSeries.GroupBy...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,150 @@
+package querybackend
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sync"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend/block"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+func init() {
+	registerQueryType(
+		querybackendv1.QueryType_QUERY_TOP_CALLERS,
+		querybackendv1.ReportType_REPORT_TOP_CALLERS,
+		queryTopCallers,
+		newTopCallersAggregator,
+		noQueryTimeout,
+		[]block.Section{
+			block.SectionTSDB,
+			block.SectionProfiles,
+			block.SectionSymbols,
+		}...,
+	)
+}
+
+func queryTopCallers(q *queryContext, query *querybackendv1.Query) (*querybackendv1.Report, error) {
+	tc := query.TopCallers
+	if tc.GetFunction() == "" {
+		return nil, fmt.Errorf("top callers: function is required")
+	}
+	if tc.GetLimit() < 0 {
+		return nil, fmt.Errorf("top callers: limit must not be negative")
+	}
+	tree, _, err := resolveTree(q, nil)
+	if err != nil {
+		return nil, err
+	}
+	c := newTopCallers()
+	c.addTree(tree, tc)
+	return &querybackendv1.Report{
+		TopCallers: c.proto(tc, 0),
+	}, nil
+}
+
+type topCallersAggregator struct {
+	init    sync.Once
+	query   *querybackendv1.TopCallersQuery
+	m       sync.Mutex
+	callers *topCallers
+}
+
+func newTopCallersAggregator(*querybackendv1.InvokeRequest) aggregator {
+	return &topCallersAggregator{callers: newTopCallers()}
+}
+
+func (a *topCallersAggregator) aggregate(report *querybackendv1.Report) error {
+	r := report.TopCallers
+	a.init.Do(func() {
+		a.query = r.Query.CloneVT()
+	})
+	a.m.Lock()
+	a.callers.merge(r)
+	a.m.Unlock()
+	return nil
+}
+
+func (a *topCallersAggregator) build() *querybackendv1.Report {
+	return &querybackendv1.Report{
+		TopCallers: a.callers.proto(a.query, a.query.GetLimit()),
+	}
+}
+
+// topCallers accumulates the values of the callers
+// of a function. It is not safe for concurrent use.
+type topCallers struct {
+	values map[string]int64
+	total  int64
+	// Callers seen in the current stack trace.
+	seen map[string]struct{}
+}
+
+func newTopCallers() *topCallers {
+	return &topCallers{
+		values: make(map[string]int64),
+		seen:   make(map[string]struct{}),
+	}
+}
+
+func (c *topCallers) addTree(t *model.Tree, query *querybackendv1.TopCallersQuery) {
+	target := query.Function
+	t.IterateStacks(func(_ string, self int64, stack []string) {
+		clear(c.seen)
+		// The stack starts at the leaf: the callers follow the callee.
+		found := false
+		for i, name := range stack {
+			if name == target {
+				found = true
+				if !query.Transitive && i+1 < len(stack) && stack[i+1] != target {
+					c.add(stack[i+1], self)
+				}
+				continue
+			}
+			if found && query.Transitive {
+				c.add(name, self)
+			}
+		}
+		if found {
+			c.total += self
+		}
+	})
+}
+
+func (c *topCallers) add(name string, value int64) {
+	if _, seen := c.seen[name]; seen {
+		return
+	}
+	c.seen[name] = struct{}{}
+	c.values[name] += value
+}
+
+func (c *topCallers) merge(r *querybackendv1.TopCallersReport) {
+	for _, x := range r.Callers {
+		c.values[x.Name] += x.Value
+	}
+	c.total += r.Total
+}
+
+func (c *topCallers) proto(query *querybackendv1.TopCallersQuery, limit int64) *querybackendv1.TopCallersReport {
+	callers := make([]*querybackendv1.TopCaller, 0, len(c.values))
+	for name, v := range c.values {
+		callers = append(callers, &querybackendv1.TopCaller{Name: name, Value: v})
+	}
+	slices.SortFunc(callers, func(a, b *querybackendv1.TopCaller) int {
+		if r := cmp.Compare(b.Value, a.Value); r != 0 {
+			return r
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	if limit > 0 && int64(len(callers)) > limit {
+		callers = callers[:limit]
+	}
+	return &querybackendv1.TopCallersReport{
+		Query:   query,
+		Callers: callers,
+		Total:   c.total,
+	}
+}
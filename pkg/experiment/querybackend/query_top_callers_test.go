@@ -0,0 +1,115 @@
+package querybackend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+func Test_TopCallers_Recursion(t *testing.T) {
+	tree := new(model.Tree)
+	tree.InsertStack(1, "main", "a", "b", "b", "c")
+	tree.InsertStack(2, "main", "b", "a", "b")
+	tree.InsertStack(4, "main", "c")
+	tree.InsertStack(8, "b")
+
+	callers := func(transitive bool) *querybackendv1.TopCallersReport {
+		c := newTopCallers()
+		query := &querybackendv1.TopCallersQuery{Function: "b", Transitive: transitive}
+		c.addTree(tree, query)
+		return c.proto(query, 0)
+	}
+
+	r := callers(false)
+	require.Equal(t, []*querybackendv1.TopCaller{
+		{Name: "a", Value: 3},
+		{Name: "main", Value: 2},
+	}, r.Callers)
+	require.EqualValues(t, 11, r.Total)
+
+	r = callers(true)
+	require.Equal(t, []*querybackendv1.TopCaller{
+		{Name: "a", Value: 3},
+		{Name: "main", Value: 3},
+	}, r.Callers)
+	require.EqualValues(t, 11, r.Total)
+}
+
+func Test_TopCallers_MergeLimit(t *testing.T) {
+	t1 := new(model.Tree)
+	t1.InsertStack(1, "main", "a", "x")
+	t1.InsertStack(5, "main", "c", "x")
+	t2 := new(model.Tree)
+	t2.InsertStack(2, "main", "b", "x")
+	t2.InsertStack(1, "main", "a", "x")
+
+	a := newTopCallersAggregator(nil)
+	query := &querybackendv1.TopCallersQuery{Function: "x", Limit: 2}
+	for _, tree := range []*model.Tree{t1, t2} {
+		c := newTopCallers()
+		c.addTree(tree, query)
+		require.NoError(t, a.aggregate(&querybackendv1.Report{TopCallers: c.proto(query, 0)}))
+	}
+
+	r := a.build().TopCallers
+	// The partial reports are not truncated.
+	require.Equal(t, []*querybackendv1.TopCaller{
+		{Name: "c", Value: 5},
+		{Name: "a", Value: 2},
+	}, r.Callers)
+	require.EqualValues(t, 9, r.Total)
+}
+
+func Test_QueryTopCallers(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	invoke := func(query *querybackendv1.Query) (*querybackendv1.Report, error) {
+		resp, err := reader.Invoke(context.Background(), newTestInvokeRequest(blocks, `{service_name=~".+", __type__="cpu"}`, query))
+		if err != nil {
+			return nil, err
+		}
+		require.Len(t, resp.Reports, 1)
+		return resp.Reports[0], nil
+	}
+
+	r, err := invoke(&querybackendv1.Query{
+		QueryType: querybackendv1.QueryType_QUERY_CALLGRAPH,
+		CallGraph: new(querybackendv1.CallGraphQuery),
+	})
+	require.NoError(t, err)
+	graph := r.CallGraph
+	require.NotEmpty(t, graph.Edges)
+	target := graph.Edges[0].Callee
+
+	// The immediate callers match the incoming edges of the call graph.
+	r, err = invoke(&querybackendv1.Query{
+		QueryType:  querybackendv1.QueryType_QUERY_TOP_CALLERS,
+		TopCallers: &querybackendv1.TopCallersQuery{Function: graph.Nodes[target].Name},
+	})
+	require.NoError(t, err)
+	require.Equal(t, querybackendv1.ReportType_REPORT_TOP_CALLERS, r.ReportType)
+	require.Equal(t, graph.Nodes[target].Total, r.TopCallers.Total)
+	expected := make(map[string]int64)
+	for _, e := range graph.Edges {
+		if e.Callee == target {
+			expected[graph.Nodes[e.Caller].Name] = e.Value
+		}
+	}
+	actual := make(map[string]int64)
+	for i, c := range r.TopCallers.Callers {
+		actual[c.Name] = c.Value
+		if i > 0 {
+			require.GreaterOrEqual(t, r.TopCallers.Callers[i-1].Value, c.Value)
+		}
+	}
+	require.Equal(t, expected, actual)
+
+	_, err = invoke(&querybackendv1.Query{
+		QueryType:  querybackendv1.QueryType_QUERY_TOP_CALLERS,
+		TopCallers: new(querybackendv1.TopCallersQuery),
+	})
+	require.Error(t, err)
+}
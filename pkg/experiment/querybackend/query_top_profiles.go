@@ -0,0 +1,257 @@
+package querybackend
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/grafana/dskit/runutil"
+	"github.com/parquet-go/parquet-go"
+	"github.com/prometheus/common/model"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend/block"
+	phlaremodel "github.com/grafana/pyroscope/pkg/model"
+	parquetquery "github.com/grafana/pyroscope/pkg/phlaredb/query"
+	v1 "github.com/grafana/pyroscope/pkg/phlaredb/schemas/v1"
+	"github.com/grafana/pyroscope/pkg/phlaredb/tsdb/index"
+)
+
+func init() {
+	registerQueryType(
+		querybackendv1.QueryType_QUERY_TOP_PROFILES,
+		querybackendv1.ReportType_REPORT_TOP_PROFILES,
+		queryTopProfiles,
+		newTopProfilesAggregator,
+		noQueryTimeout,
+		[]block.Section{
+			block.SectionTSDB,
+			block.SectionProfiles,
+			block.SectionSymbols,
+		}...,
+	)
+}
+
+// queryTopProfiles ranks the profiles of the dataset by the total value.
+// The totals are read from the profile table; the stack traces are only
+// resolved for the retained profiles, if the trees are requested.
+func queryTopProfiles(q *queryContext, query *querybackendv1.Query) (*querybackendv1.Report, error) {
+	tp := query.TopProfiles
+	if tp.GetLimit() <= 0 {
+		return nil, fmt.Errorf("top profiles: limit must be positive")
+	}
+	if tp.GetMaxNodes() < 0 {
+		return nil, fmt.Errorf("top profiles: max nodes must not be negative")
+	}
+	top, err := largestProfiles(q, tp.Limit)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := profileSeriesLabels(q, top)
+	if err != nil {
+		return nil, err
+	}
+	profiles := make([]*querybackendv1.TopProfile, len(top))
+	for i, p := range top {
+		profiles[i] = &querybackendv1.TopProfile{
+			Id:        p.id,
+			Timestamp: int64(p.entry.Timestamp),
+			Labels:    labels[p.entry.Fingerprint],
+			Total:     p.total,
+		}
+	}
+	if tp.IncludeTrees {
+		for i, p := range top {
+			tree, _, err := resolveProfileTree(q, p.entry)
+			if err != nil {
+				return nil, err
+			}
+			profiles[i].Tree = tree.Bytes(profileTreeMaxNodes(tp))
+		}
+	}
+	return &querybackendv1.Report{
+		TopProfiles: &querybackendv1.TopProfilesReport{
+			Query:    tp.CloneVT(),
+			Profiles: profiles,
+		},
+	}, nil
+}
+
+func profileTreeMaxNodes(query *querybackendv1.TopProfilesQuery) int64 {
+	if n := query.GetMaxNodes(); n > 0 {
+		return n
+	}
+	return -1
+}
+
+type largeProfile struct {
+	entry ProfileEntry
+	id    string
+	total int64
+}
+
+// largestProfiles returns the profiles with the highest total values,
+// ordered as in the report.
+func largestProfiles(q *queryContext, limit int64) (top []largeProfile, err error) {
+	entries, err := profileEntryIterator(q, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer runutil.CloseWithErrCapture(&err, entries, "failed to close profile entry iterator")
+
+	schema := q.ds.Profiles().Schema()
+	total, err := v1.ResolveColumnByPath(schema, []string{v1.TotalValueColumnName})
+	if err != nil {
+		return nil, err
+	}
+	id, err := v1.ResolveColumnByPath(schema, []string{profileIDColumnName})
+	if err != nil {
+		return nil, err
+	}
+	rows := parquetquery.NewRepeatedRowIterator(q.ctx, entries, q.ds.Profiles().RowGroups(),
+		total.ColumnIndex, id.ColumnIndex)
+	defer runutil.CloseWithErrCapture(&err, rows, "failed to close column iterator")
+
+	for rows.Next() {
+		r := rows.At()
+		v := r.Values[0][0]
+		if invalidSampleValue(v) {
+			continue
+		}
+		top = append(top, largeProfile{
+			entry: r.Row,
+			id:    profileID(r.Values[1]),
+			total: v.Int64(),
+		})
+		// The list is compacted once it grows twice as large as
+		// the limit, so that only a few profiles are retained.
+		if int64(len(top)) >= 2*limit {
+			top = truncateLargeProfiles(top, limit)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return truncateLargeProfiles(top, limit), nil
+}
+
+func truncateLargeProfiles(s []largeProfile, limit int64) []largeProfile {
+	slices.SortFunc(s, func(a, b largeProfile) int {
+		return compareTopProfiles(a.total, b.total, int64(a.entry.Timestamp), int64(b.entry.Timestamp), a.id, b.id)
+	})
+	if int64(len(s)) > limit {
+		s = s[:limit]
+	}
+	return s
+}
+
+// compareTopProfiles orders the profiles by total, descending,
+// then by timestamp and ID.
+func compareTopProfiles(totalA, totalB, tsA, tsB int64, idA, idB string) int {
+	if r := cmp.Compare(totalB, totalA); r != 0 {
+		return r
+	}
+	if r := cmp.Compare(tsA, tsB); r != 0 {
+		return r
+	}
+	return cmp.Compare(idA, idB)
+}
+
+// profileSeriesLabels returns the labels of the series of the profiles.
+// The profile entries do not carry the series labels, unless grouped.
+func profileSeriesLabels(q *queryContext, profiles []largeProfile) (map[model.Fingerprint]phlaremodel.Labels, error) {
+	m := make(map[model.Fingerprint]phlaremodel.Labels, len(profiles))
+	for _, p := range profiles {
+		m[p.entry.Fingerprint] = nil
+	}
+	postings, err := getPostings(q.ds.Index(), q.req.matchers...)
+	if err != nil {
+		return nil, err
+	}
+	chunks := make([]index.ChunkMeta, 1)
+	l := make(phlaremodel.Labels, 0, 6)
+	for postings.Next() {
+		fp, err := q.ds.Index().Series(postings.At(), &l, &chunks)
+		if err != nil {
+			return nil, err
+		}
+		if x, ok := m[model.Fingerprint(fp)]; ok && x == nil {
+			m[model.Fingerprint(fp)] = l.Clone()
+		}
+	}
+	return m, postings.Err()
+}
+
+func profileID(v []parquet.Value) string {
+	if len(v) == 0 || len(v[0].ByteArray()) != len(uuid.UUID{}) {
+		return ""
+	}
+	id := uuid.UUID(v[0].ByteArray())
+	if id == uuid.Nil {
+		return ""
+	}
+	return id.String()
+}
+
+type topProfilesAggregator struct {
+	init     sync.Once
+	query    *querybackendv1.TopProfilesQuery
+	m        sync.Mutex
+	profiles []*querybackendv1.TopProfile
+	// Profiles already added: a profile may be stored
+	// in multiple blocks, e.g. before the compaction.
+	seen map[topProfileKey]struct{}
+}
+
+type topProfileKey struct {
+	id     string
+	labels uint64
+}
+
+func newTopProfilesAggregator(*querybackendv1.InvokeRequest) aggregator {
+	return &topProfilesAggregator{seen: make(map[topProfileKey]struct{})}
+}
+
+func (a *topProfilesAggregator) aggregate(report *querybackendv1.Report) error {
+	r := report.TopProfiles
+	a.init.Do(func() {
+		a.query = r.Query.CloneVT()
+	})
+	a.m.Lock()
+	defer a.m.Unlock()
+	for _, p := range r.Profiles {
+		if p.Id != "" {
+			k := topProfileKey{id: p.Id, labels: phlaremodel.Labels(p.Labels).Hash()}
+			if _, seen := a.seen[k]; seen {
+				continue
+			}
+			a.seen[k] = struct{}{}
+		}
+		a.profiles = append(a.profiles, p)
+	}
+	if limit := a.query.GetLimit(); limit > 0 && int64(len(a.profiles)) >= 2*limit {
+		a.profiles = truncateTopProfiles(a.profiles, limit)
+	}
+	return nil
+}
+
+func (a *topProfilesAggregator) build() *querybackendv1.Report {
+	return &querybackendv1.Report{
+		TopProfiles: &querybackendv1.TopProfilesReport{
+			Query:    a.query,
+			Profiles: truncateTopProfiles(a.profiles, a.query.GetLimit()),
+		},
+	}
+}
+
+func truncateTopProfiles(s []*querybackendv1.TopProfile, limit int64) []*querybackendv1.TopProfile {
+	slices.SortFunc(s, func(a, b *querybackendv1.TopProfile) int {
+		return compareTopProfiles(a.Total, b.Total, a.Timestamp, b.Timestamp, a.Id, b.Id)
+	})
+	if limit > 0 && int64(len(s)) > limit {
+		s = s[:limit]
+	}
+	return s
+}
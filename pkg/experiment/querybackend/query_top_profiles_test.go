@@ -0,0 +1,84 @@
+package querybackend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	typesv1 "github.com/grafana/pyroscope/api/gen/proto/go/types/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+func Test_TopProfiles_Merge(t *testing.T) {
+	labels := []*typesv1.LabelPair{{Name: "service_name", Value: "svc"}}
+	query := &querybackendv1.TopProfilesQuery{Limit: 3}
+	a := newTopProfilesAggregator(nil)
+	for _, profiles := range [][]*querybackendv1.TopProfile{
+		{
+			{Id: "a", Timestamp: 1, Labels: labels, Total: 10},
+			{Id: "b", Timestamp: 2, Labels: labels, Total: 5},
+		},
+		{
+			// The same profile stored in another block.
+			{Id: "a", Timestamp: 1, Labels: labels, Total: 10},
+			{Id: "c", Timestamp: 1, Labels: labels, Total: 5},
+			{Id: "d", Timestamp: 3, Labels: labels, Total: 1},
+		},
+	} {
+		require.NoError(t, a.aggregate(&querybackendv1.Report{
+			TopProfiles: &querybackendv1.TopProfilesReport{Query: query, Profiles: profiles},
+		}))
+	}
+
+	var ids []string
+	for _, p := range a.build().TopProfiles.Profiles {
+		ids = append(ids, p.Id)
+	}
+	require.Equal(t, []string{"a", "c", "b"}, ids)
+}
+
+func Test_QueryTopProfiles(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	invoke := func(query *querybackendv1.TopProfilesQuery) (*querybackendv1.TopProfilesReport, error) {
+		resp, err := reader.Invoke(context.Background(), newTestInvokeRequest(
+			blocks,
+			`{service_name=~".+", __type__="cpu"}`,
+			&querybackendv1.Query{
+				QueryType:   querybackendv1.QueryType_QUERY_TOP_PROFILES,
+				TopProfiles: query,
+			},
+		))
+		if err != nil {
+			return nil, err
+		}
+		return resp.Reports[0].TopProfiles, nil
+	}
+
+	_, err := invoke(&querybackendv1.TopProfilesQuery{})
+	require.Error(t, err)
+
+	r, err := invoke(&querybackendv1.TopProfilesQuery{Limit: 2, IncludeTrees: true, MaxNodes: 8})
+	require.NoError(t, err)
+	require.Len(t, r.Profiles, 2)
+	for i, p := range r.Profiles {
+		if i > 0 {
+			require.LessOrEqual(t, p.Total, r.Profiles[i-1].Total)
+		}
+		require.NotEmpty(t, p.Labels)
+		require.NotEmpty(t, p.Id)
+		tree, err := model.UnmarshalTree(p.Tree)
+		require.NoError(t, err)
+		require.Equal(t, p.Total, tree.Total())
+	}
+
+	// The trees are only resolved on demand; the ranking
+	// is the same, regardless of the number of profiles.
+	top, err := invoke(&querybackendv1.TopProfilesQuery{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, top.Profiles, 1)
+	require.Empty(t, top.Profiles[0].Tree)
+	require.Equal(t, r.Profiles[0].Id, top.Profiles[0].Id)
+	require.Equal(t, r.Profiles[0].Total, top.Profiles[0].Total)
+}
@@ -1,9 +1,18 @@
 package querybackend
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/grafana/dskit/runutil"
+	"github.com/parquet-go/parquet-go"
+	"github.com/prometheus/prometheus/model/relabel"
+	"go.uber.org/atomic"
 
 	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
 	"github.com/grafana/pyroscope/pkg/experiment/querybackend/block"
@@ -19,6 +28,7 @@ func init() {
 		querybackendv1.ReportType_REPORT_TREE,
 		queryTree,
 		newTreeAggregator,
+		noQueryTimeout,
 		[]block.Section{
 			block.SectionTSDB,
 			block.SectionProfiles,
@@ -28,68 +38,1040 @@ func init() {
 }
 
 func queryTree(q *queryContext, query *querybackendv1.Query) (*querybackendv1.Report, error) {
-	entries, err := profileEntryIterator(q)
+	q = q.withPartitions(query.Tree.GetPartitions())
+	var sanitize NameSanitizer
+	if name := query.Tree.GetNameSanitizer(); name != "" {
+		var err error
+		if sanitize, err = getNameSanitizer(name); err != nil {
+			return nil, err
+		}
+	}
+	normalize, err := getNameNormalizer(query.Tree.GetNameNormalizers())
 	if err != nil {
 		return nil, err
 	}
-	defer runutil.CloseWithErrCapture(&err, entries, "failed to close profile entry iterator")
+	// The normalization is applied wherever the frames are sanitized.
+	sanitize = withNameNormalizer(sanitize, normalize)
+
+	var grouping StackGrouping
+	if name := query.Tree.GetStackGrouping(); name != "" {
+		var err error
+		if grouping, err = q.stackGrouping(name); err != nil {
+			return nil, err
+		}
+	}
 
-	var columns v1.SampleColumns
-	if err = columns.Resolve(q.ds.Profiles().Schema()); err != nil {
+	rules, err := relabelConfigs(query.Tree.GetRelabel())
+	if err != nil {
+		return nil, err
+	}
+	opts, err := treeResolverOptions(query.Tree)
+	if err != nil {
+		return nil, err
+	}
+	if err = validateTreeValueRange(query.Tree); err != nil {
+		return nil, err
+	}
+	if query.Tree.GetMaxDepth() < 0 {
+		return nil, fmt.Errorf("max depth must not be negative")
+	}
+	if query.Tree.GetMaxFunctions() < 0 {
+		return nil, fmt.Errorf("max functions must not be negative")
+	}
+	if err = validateTreeQueryOptions(query.Tree); err != nil {
+		return nil, err
+	}
+	expr, err := treeValueExpression(query.Tree)
+	if err != nil {
+		return nil, err
+	}
+	if c := query.Tree.GetValueCombination(); c != nil {
+		if err = q.checkValueCombinationUnits(c); err != nil {
+			return nil, err
+		}
+	}
+	if q, err = q.withValueMerge(query.Tree.GetValueMerge()); err != nil {
+		return nil, err
+	}
+	if query.Tree.GetIntervalValues() && q.req.endTime <= q.req.startTime {
+		return nil, fmt.Errorf("interval values require a non-empty time range")
+	}
+	if err = validateTreeLegend(query.Tree.GetLegend()); err != nil {
+		return nil, err
+	}
+	if err = validateMinProfileValue(query.Tree); err != nil {
+		return nil, err
+	}
+	if query.Tree.GetMinProfileValue() > 0 {
+		q = q.withMinProfileValue(query.Tree)
+	}
+	dominantLabel := query.Tree.GetDominantLabel()
+	groupRootLabel := query.Tree.GetGroupRootLabel()
+	labelBuckets := query.Tree.GetLabelBuckets()
+	if err = validateLabelBuckets(labelBuckets); err != nil {
 		return nil, err
 	}
+	overLimit := q.symbolsOverLimit()
+	if overLimit {
+		q.metrics.symbolsOverLimit.Inc()
+	}
+	if len(query.Tree.GetProfileTypes()) > 0 {
+		return queryMultiValueTree(q, query, rules, sanitize, opts)
+	}
 
-	profiles := parquetquery.NewRepeatedRowIterator(q.ctx, entries, q.ds.Profiles().RowGroups(),
-		columns.StacktraceID.ColumnIndex,
-		columns.Value.ColumnIndex)
-	defer runutil.CloseWithErrCapture(&err, profiles, "failed to close profile stream")
+	transform := func(tree *model.Tree) *model.Tree {
+		if sanitize != nil {
+			tree.FormatNodeNames(sanitize)
+		}
+		if grouping != nil {
+			tree = groupStacks(tree, grouping)
+		}
+		if query.Tree.GetInverted() {
+			tree = tree.Inverted()
+		}
+		if maxDepth := subtreeMaxDepth(tree, query.Tree); maxDepth > 0 {
+			tree.LimitDepth(maxDepth)
+		}
+		return tree
+	}
 
-	resolver := symdb.NewResolver(q.ctx, q.ds.Symbols())
-	defer resolver.Release()
-	for profiles.Next() {
-		p := profiles.At()
-		resolver.AddSamplesFromParquetRow(p.Row.Partition, p.Values[0], p.Values[1])
+	version := treeFormatVersion(query.Tree)
+	var tree *model.Tree
+	var labelValues *treeLabelValues
+	var buckets []*querybackendv1.TreeBucket
+	var operands *multiValueTree
+	var unsymbolized float64
+	var transformed bool
+	switch {
+	case dominantLabel != "":
+		// The trees of the label values are transformed
+		// individually, before they are combined.
+		tree, labelValues, unsymbolized, err = resolveTreeLabelValues(q, dominantLabel, rules, transform, opts...)
+		transformed = true
+	case groupRootLabel != "":
+		// The group trees are transformed before they
+		// are grafted under the synthetic root nodes.
+		tree, unsymbolized, err = resolveGroupRootTree(q, groupRootLabel, rules, transform, opts...)
+		transformed = true
+	case labelBuckets != nil:
+		serialize := func(t *model.Tree) *querybackendv1.TreeBucket {
+			return &querybackendv1.TreeBucket{
+				Tree:      t.BytesVersion(query.Tree.GetMaxNodes(), version),
+				Truncated: treeTruncated(t.Size(), query.Tree.GetMaxNodes()),
+			}
+		}
+		tree, buckets, unsymbolized, err = resolveTreeLabelBuckets(q, labelBuckets, rules, transform, serialize, opts...)
+		transformed = true
+	case expr != nil:
+		// The operand trees are transformed before the
+		// expression is evaluated, see resolveExpressionTree.
+		tree, operands, unsymbolized, err = resolveExpressionTree(q, expr, rules, transform, opts...)
+		transformed = true
+	case query.Tree.GetRepresentative():
+		tree, unsymbolized, err = resolveRepresentativeTree(q, rules, opts...)
+	default:
+		tree, unsymbolized, err = resolveTree(q, rules, opts...)
 	}
-	if err = profiles.Err(); err != nil {
+	if err != nil {
 		return nil, err
 	}
-
-	tree, err := resolver.Tree()
+	if !transformed {
+		tree = transform(tree)
+	}
+	var counts *multiValueTree
+	var countsMissing bool
+	if query.Tree.GetSampleCounts() {
+		var u float64
+		if counts, u, countsMissing, err = resolveSampleCounts(q, query.Tree, tree, rules, sanitize, opts); err != nil {
+			return nil, err
+		}
+		unsymbolized = max(unsymbolized, u)
+	}
+	sampleTypes, err := querySampleTypes(q)
 	if err != nil {
 		return nil, err
 	}
+	var goroutineStates *treeLabelValues
+	if query.Tree.GetGoroutineStates() && isGoroutineProfile(sampleTypes) {
+		goroutineStates = newTreeGoroutineStates(tree, query.Tree.GetInverted())
+	}
 
+	nodes := tree.Size()
+	maxNodes := partialTreeMaxNodes(query.Tree)
 	resp := &querybackendv1.Report{
 		Tree: &querybackendv1.TreeReport{
-			Query: query.Tree.CloneVT(),
-			Tree:  tree.Bytes(query.Tree.GetMaxNodes()),
+			Query:         query.Tree.CloneVT(),
+			Tree:          tree.BytesVersion(maxNodes, version),
+			Unsymbolized:  unsymbolized > 0,
+			FormatVersion: uint32(version),
+			Nodes:         nodes,
+			Truncated:     treeTruncated(nodes, maxNodes),
+
+			SampleCountsMissing: countsMissing,
+			SymbolsOverLimit:    overLimit,
+
+			ProfilesBelowMinValue: q.profilesBelowMinValue(),
+
+			Buckets:     buckets,
+			SampleTypes: sampleTypes,
 		},
 	}
+	if !overLimit {
+		resp.Tree.SymbolsFormatVersion = uint32(q.ds.SymbolsFormatVersion())
+	}
+	if counts != nil {
+		resp.Tree.MultiValueTree = counts.proto(maxNodes)
+	}
+	if operands != nil {
+		resp.Tree.MultiValueTree = operands.proto(maxNodes)
+	}
+	if query.Tree.GetNodeIds() {
+		if resp.Tree.NodeIds, err = model.TreeNodeIDs(resp.Tree.Tree, version); err != nil {
+			return nil, err
+		}
+	}
+	if !query.Tree.GetAttribution() && !query.Tree.GetIncludeSourceLocations() && labelValues == nil && !query.Tree.GetCoverage() && goroutineStates == nil {
+		return resp, nil
+	}
+	truncated, err := model.UnmarshalTreeVersion(resp.Tree.Tree, version)
+	if err != nil {
+		return nil, err
+	}
+	if query.Tree.GetAttribution() {
+		attribution := newTreeAttribution()
+		attribution.addTree(q.obj.Meta().Id, truncated)
+		resp.Tree.Attribution = attribution.proto(truncated)
+	}
+	if query.Tree.GetIncludeSourceLocations() {
+		var functions map[string]sourceLocation
+		if !overLimit {
+			// The symbols of the block are not read, if they
+			// exceed the limit: the locations are unknown.
+			if functions, err = functionSourceLocations(q, rules, sanitize); err != nil {
+				return nil, err
+			}
+		}
+		locations := newTreeSourceLocations()
+		locations.addTree(truncated, functions)
+		resp.Tree.SourceLocations = locations.proto(truncated)
+	}
+	if labelValues != nil {
+		resp.Tree.LabelValues = labelValues.proto(truncated)
+	}
+	if goroutineStates != nil {
+		resp.Tree.GoroutineStates = goroutineStates.proto(truncated)
+	}
+	if query.Tree.GetCoverage() {
+		coverage := newTreeCoverage()
+		coverage.addTree(truncated)
+		resp.Tree.Coverage = coverage.proto(truncated, 0)
+	}
 	return resp, nil
 }
 
+// subtreeMaxDepth removes the nodes outside of the subtree of the query,
+// if any, and returns the max depth of the tree: the max depth of the
+// query is counted from the subtree node.
+func subtreeMaxDepth(tree *model.Tree, query *querybackendv1.TreeQuery) int {
+	maxDepth := int(query.GetMaxDepth())
+	if id := query.GetSubtreeNodeId(); id != 0 {
+		if depth := tree.Subtree(id); depth > 0 && maxDepth > 0 {
+			maxDepth += depth - 1
+		}
+	}
+	return maxDepth
+}
+
+// treeTruncated reports whether a tree of the given size
+// may be truncated, if limited to maxNodes.
+func treeTruncated(nodes, maxNodes int64) bool {
+	return maxNodes > 0 && nodes > maxNodes
+}
+
+// capTreeSize reduces the number of the tree nodes until the serialized
+// tree fits maxSize, and returns the tree along with the node limit that
+// was applied. The tree b is expected to exceed the size: it is returned
+// as is, if it can't be reduced any further.
+func capTreeSize(tree *model.Tree, b []byte, nodes, maxNodes int64, version int, maxSize int64) ([]byte, int64) {
+	n := nodes
+	if maxNodes > 0 {
+		n = min(n, maxNodes)
+	}
+	for int64(len(b)) > maxSize && n > 1 {
+		// The size is roughly proportional to the number of nodes;
+		// the limit is reduced by at least one node per iteration.
+		n = max(1, min(n-1, n*maxSize/int64(len(b))))
+		b = tree.BytesVersion(n, version)
+	}
+	return b, n
+}
+
+// treeFormatVersion returns the latest tree serialization
+// format version supported by both the client and the server.
+func treeFormatVersion(query *querybackendv1.TreeQuery) int {
+	v := int(query.GetFormatVersion())
+	if v < model.TreeFormatV1 {
+		return model.TreeFormatV1
+	}
+	return min(v, model.TreeFormatLatest)
+}
+
+// UnmarshalReportTree decodes the tree of the report.
+func UnmarshalReportTree(report *querybackendv1.TreeReport) (*model.Tree, error) {
+	return model.UnmarshalTreeVersion(report.Tree, reportFormatVersion(report))
+}
+
+// reportFormatVersion returns the serialization
+// format version of the tree in the report.
+func reportFormatVersion(report *querybackendv1.TreeReport) int {
+	if v := int(report.FormatVersion); v > 0 {
+		return v
+	}
+	return model.TreeFormatV1
+}
+
+const defaultApproximationError = 0.001
+
+func treeResolverOptions(query *querybackendv1.TreeQuery) ([]symdb.ResolverOption, error) {
+	if query.GetShallow() {
+		var entryPoint *regexp.Regexp
+		if expr := query.GetShallowEntryPoint(); expr != "" {
+			var err error
+			if entryPoint, err = regexp.Compile(expr); err != nil {
+				return nil, fmt.Errorf("invalid shallow entry point: %w", err)
+			}
+		}
+		// Approximation is pointless, as the shallow
+		// tree is much smaller than the full one.
+		return []symdb.ResolverOption{symdb.WithResolverShallow(entryPoint)}, nil
+	}
+	if query.GetShallowEntryPoint() != "" {
+		return nil, fmt.Errorf("shallow entry point requires shallow mode")
+	}
+	if !query.GetApproximate() || query.GetMaxNodes() <= 0 {
+		return nil, nil
+	}
+	epsilon := query.GetApproximationError()
+	if epsilon == 0 {
+		epsilon = defaultApproximationError
+	}
+	if !(epsilon > 0 && epsilon < 1) {
+		return nil, fmt.Errorf("approximation error must be in the range (0, 1), got %v", epsilon)
+	}
+	return []symdb.ResolverOption{
+		symdb.WithResolverMaxNodes(query.MaxNodes),
+		symdb.WithResolverApproximation(epsilon),
+		symdb.WithResolverSeed(query.Seed),
+	}, nil
+}
+
+func validateTreeValueRange(query *querybackendv1.TreeQuery) error {
+	minValue, maxValue := query.GetMinValue(), query.GetMaxValue()
+	if minValue < 0 || maxValue < 0 {
+		return fmt.Errorf("node value range bounds must not be negative")
+	}
+	if maxValue > 0 && minValue > maxValue {
+		return fmt.Errorf("invalid node value range: min value %d exceeds max value %d", minValue, maxValue)
+	}
+	if p := query.GetMinValuePercent(); !(p >= 0 && p <= 100) {
+		return fmt.Errorf("min value percent must be in the range [0, 100], got %v", p)
+	}
+	return nil
+}
+
+// treeMinValue returns the lower bound of the node values of the merged
+// tree: the higher one of the absolute and the relative bounds. The bound
+// is not applied to the partial trees, as it may only be computed against
+// the merged values: the partials are only bounded by the max nodes.
+func treeMinValue(tree *model.Tree, query *querybackendv1.TreeQuery) int64 {
+	minValue := query.GetMinValue()
+	if p := query.GetMinValuePercent(); p > 0 {
+		minValue = max(minValue, int64(math.Ceil(float64(tree.Total())*p/100)))
+	}
+	return minValue
+}
+
+// resolveTree builds the tree of the profiles matching the query,
+// and reports the fraction of the stack traces lacking symbols.
+func resolveTree(
+	q *queryContext,
+	rules []*relabel.Config,
+	opts ...symdb.ResolverOption,
+) (*model.Tree, float64, error) {
+	trees, unsymbolized, err := resolveTreesByLabel(q, rules, "", opts...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return trees[""], unsymbolized, nil
+}
+
+// resolveTreesByLabel builds a tree for each of the values of the label,
+// and reports the largest fraction of the stack traces lacking symbols.
+// The profiles lacking the label are accounted under the empty value.
+// If no label is given, a single tree is built, even if no profiles match.
+func resolveTreesByLabel(
+	q *queryContext,
+	rules []*relabel.Config,
+	label string,
+	opts ...symdb.ResolverOption,
+) (map[string]*model.Tree, float64, error) {
+	return resolveTreesByGroup(q, rules, label, nil, opts...)
+}
+
+// resolveTreesByGroup is like resolveTreesByLabel, but the profiles are
+// grouped by the key the label value is mapped to, if the function is
+// given. The profiles mapped to the empty key are accounted under it,
+// along with the ones lacking the label.
+func resolveTreesByGroup(
+	q *queryContext,
+	rules []*relabel.Config,
+	label string,
+	groupKey func(value string) string,
+	opts ...symdb.ResolverOption,
+) (map[string]*model.Tree, float64, error) {
+	// The results are only accessed if the function completes:
+	// otherwise, it may still be running when we return.
+	var trees map[string]*model.Tree
+	var unsymbolized float64
+	err := q.runWatched(func(w *resolverWatchdog) (err error) {
+		trees, unsymbolized, err = resolveTreesByGroupWatched(q, w, rules, label, groupKey, opts...)
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return trees, unsymbolized, nil
+}
+
+func resolveTreesByGroupWatched(
+	q *queryContext,
+	w *resolverWatchdog,
+	rules []*relabel.Config,
+	label string,
+	groupKey func(value string) string,
+	opts ...symdb.ResolverOption,
+) (trees map[string]*model.Tree, unsymbolized float64, err error) {
+	var groupBy []string
+	if label != "" {
+		groupBy = append(groupBy, label)
+	}
+	entries, err := profileEntryIterator(q, rules, groupBy...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer runutil.CloseWithErrCapture(&err, entries, "failed to close profile entry iterator")
+
+	columns, err := q.sampleColumns(v1.SampleStacktraceColumns)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	columnIndices := columns.Indices()
+	idColumn, totalColumn := -1, -1
+	if q.seen != nil {
+		id, err := v1.ResolveColumnByPath(q.ds.Profiles().Schema(), []string{profileIDColumnName})
+		if err != nil {
+			return nil, 0, err
+		}
+		idColumn = len(columnIndices)
+		columnIndices = append(columnIndices, id.ColumnIndex)
+	}
+	if q.minProfileValue > 0 {
+		total, err := v1.ResolveColumnByPath(q.ds.Profiles().Schema(), []string{v1.TotalValueColumnName})
+		if err != nil {
+			return nil, 0, err
+		}
+		totalColumn = len(columnIndices)
+		columnIndices = append(columnIndices, total.ColumnIndex)
+	}
+	profiles := parquetquery.NewRepeatedRowIteratorWithPrefetch(q.ctx, entries,
+		q.ds.Profiles().RowGroups(), q.ds.Profiles(), columnIndices...)
+	defer runutil.CloseWithErrCapture(&err, profiles, "failed to close profile stream")
+
+	type group struct {
+		resolver *symdb.Resolver
+		values   *pathValues
+	}
+	groups := make(map[string]*group)
+	defer func() {
+		start := time.Now()
+		for _, g := range groups {
+			g.resolver.Release()
+		}
+		q.observe(q.metrics.resolverReleaseDuration, time.Since(start).Seconds())
+	}()
+	groupOf := func(value string) *group {
+		g, ok := groups[value]
+		if !ok {
+			g = &group{resolver: q.newResolver(opts...)}
+			w.add(g.resolver)
+			if q.valueMerge != nil {
+				g.values = newPathValues(q.valueMerge)
+			}
+			groups[value] = g
+		}
+		return g
+	}
+	if label == "" {
+		groupOf("")
+	}
+	cpu := cpuMeter{budget: q.cpu}
+	var timer resolveTimer
+	defer timer.observe(q)
+	timer.start()
+	for profiles.Next() {
+		p := profiles.At()
+		if totalColumn >= 0 && q.skipProfile(p.Values[totalColumn][0].Int64()) {
+			continue
+		}
+		if idColumn >= 0 && !q.seen.claim(q.ds, p.Values[idColumn], p.Row.Fingerprint) {
+			q.metrics.deduplicatedProfiles.Inc()
+			continue
+		}
+		// Reading and filtering the rows is accounted as I/O.
+		timer.io += timer.lap()
+		cpu.begin()
+		stacktraceIDs, sampleValues, err := q.validSamples(p.Values[0], p.Values[1])
+		if err != nil {
+			return nil, 0, err
+		}
+		var g *group
+		if label == "" {
+			g = groups[""]
+		} else if value := p.Row.Labels.Get(label); groupKey != nil {
+			g = groupOf(groupKey(value))
+		} else {
+			g = groupOf(value)
+		}
+		g.resolver.AddSamplesFromParquetRow(p.Row.Partition, stacktraceIDs, sampleValues)
+		if g.values != nil {
+			g.values.add(p.Row.Partition, stacktraceIDs, sampleValues)
+		}
+		timer.cpu += timer.lap()
+		if err = cpu.end(); err != nil {
+			return nil, 0, err
+		}
+	}
+	timer.io += timer.lap()
+	if err = profiles.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	trees = make(map[string]*model.Tree, len(groups))
+	for value, g := range groups {
+		cpu.begin()
+		if g.values != nil {
+			trees[value], err = g.values.tree(g.resolver)
+		} else {
+			trees[value], err = g.resolver.Tree()
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		timer.cpu += timer.lap()
+		if err = cpu.endFlush(); err != nil {
+			return nil, 0, err
+		}
+		q.metrics.truncatedStacks.Add(float64(g.resolver.TruncatedStacks()))
+		unsymbolized = max(unsymbolized, g.resolver.UnsymbolizedFraction())
+	}
+	return trees, unsymbolized, nil
+}
+
+// sampleColumns resolves the sample columns of the profiles table. Only
+// the columns of the set are projected: for example, the queries that
+// only need the sample values do not decode the stack trace IDs.
+func (q *queryContext) sampleColumns(set v1.SampleColumnSet) (columns v1.SampleColumns, err error) {
+	err = columns.ResolveColumns(q.ds.Profiles().Schema(), set)
+	return columns, err
+}
+
+// symbolsOverLimit reports whether the symbols section of the dataset
+// is too large to be resolved: loading the partitions of an outlier
+// dataset may exhaust the memory of the query backend.
+func (q *queryContext) symbolsOverLimit() bool {
+	return q.maxSymbolsSectionSize > 0 && q.ds.SectionSize(block.SectionSymbols) > q.maxSymbolsSectionSize
+}
+
+// newResolver creates the symbols resolver of the dataset,
+// limiting the stack trace depth, if the limit is set. If the
+// symbols exceed the size limit, only the addresses are resolved.
+func (q *queryContext) newResolver(opts ...symdb.ResolverOption) *symdb.Resolver {
+	if q.maxResolveDepth > 0 {
+		opts = append(slices.Clip(opts), symdb.WithResolverMaxDepth(q.maxResolveDepth))
+	}
+	if q.symbolsOverLimit() {
+		opts = append(slices.Clip(opts), symdb.WithResolverAddresses())
+	}
+	return symdb.NewResolver(q.ctx, q.ds.Symbols(), opts...)
+}
+
+// errInvalidSampleValue indicates a sample value that is not an int64,
+// or which magnitude does not fit into int64: such values are likely to
+// be produced by a malformed profile, and would corrupt the tree sums.
+// Note that negative values are valid, e.g. in delta profiles.
+var errInvalidSampleValue = errors.New("invalid sample value")
+
+// validSamples returns the samples of the profile excluding the ones with
+// invalid values, or an error, if the query must fail on invalid values.
+// The slices are only copied, if any of the values is invalid.
+func (q *queryContext) validSamples(stacktraceIDs, values []parquet.Value) ([]parquet.Value, []parquet.Value, error) {
+	i := slices.IndexFunc(values, invalidSampleValue)
+	if i < 0 {
+		return stacktraceIDs, values, nil
+	}
+	if q.failOnInvalidSampleValues {
+		return nil, nil, fmt.Errorf("%w: %d (block %s, dataset %s)",
+			errInvalidSampleValue, values[i].Int64(), q.obj.Meta().Id, q.meta.Name)
+	}
+	validIDs := slices.Clone(stacktraceIDs[:i])
+	validValues := slices.Clone(values[:i])
+	var dropped int
+	for ; i < len(values); i++ {
+		if invalidSampleValue(values[i]) {
+			dropped++
+			continue
+		}
+		validIDs = append(validIDs, stacktraceIDs[i])
+		validValues = append(validValues, values[i])
+	}
+	q.metrics.invalidSampleValues.Add(float64(dropped))
+	return validIDs, validValues, nil
+}
+
+func invalidSampleValue(v parquet.Value) bool {
+	return v.Kind() != parquet.Int64 || v.Int64() == math.MinInt64
+}
+
+var (
+	errTooManyTreeReports  = errors.New("too many tree reports")
+	errTreeQueryMismatch   = errors.New("tree report query mismatch")
+	errInvalidTreeBaseline = errors.New("invalid tree baseline")
+)
+
 type treeAggregator struct {
-	init  sync.Once
-	query *querybackendv1.TreeQuery
-	tree  *model.TreeMerger
+	init    sync.Once
+	query   *querybackendv1.TreeQuery
+	output  *querybackendv1.TreeQuery
+	tree    *model.TreeMerger
+	limit   int64
+	maxSize int64
+	// Set if the node names of the reports are interned.
+	intern bool
+	// Number of the datasets planned for the query;
+	// zero, if the request has no query plan.
+	planned uint64
+	// Duration of the query time range.
+	interval time.Duration
+	reports  atomic.Int64
+	// Set if any of the reports is unsymbolized.
+	unsymbolized atomic.Bool
+	// Set if any of the reports lacks sample counts.
+	sampleCountsMissing atomic.Bool
+	// Set if any of the reports is not resolved.
+	symbolsOverLimit atomic.Bool
+	// Set if any of the reports skipped profiles.
+	profilesBelowMinValue atomic.Bool
+	// The highest symbols format version of the reports.
+	symbolsFormatVersion atomic.Uint32
+	// The value expression of the query, if any: the expression is
+	// evaluated once the operands are merged, unless the request is
+	// partial, in which case the merged operands are reported.
+	expr    *valueExpression
+	partial bool
+	// The label bucket trees are merged concurrently.
+	buckets treeBuckets
+	// Sample types of the reports.
+	sampleTypes *treeSampleTypes
+
+	am              sync.Mutex
+	attribution     *treeAttribution
+	locations       *treeSourceLocations
+	labelValues     *treeLabelValues
+	goroutineStates *treeLabelValues
+	coverage        *treeCoverage
+	multiValue      *multiValueTree
+	representative  representativeTrees
+
+	// The baseline is only taken from the request: the reports
+	// of the sub-queries are built without it, see treeBaseline.
+	baseline     *querybackendv1.TreeBaseline
+	baselineTree *model.Tree
+	// Error of the aggregator initialization, e.g.,
+	// an invalid baseline: all the reports fail.
+	initErr error
+	// Applied to the partial trees and the baseline.
+	normalize NameNormalizer
+	// Error of the report build, if any: the response fails.
+	buildErr error
 }
 
-func newTreeAggregator(*querybackendv1.InvokeRequest) aggregator { return new(treeAggregator) }
+func newTreeAggregator(req *querybackendv1.InvokeRequest) aggregator {
+	a := &treeAggregator{
+		limit:    req.Options.GetMaxTreeReports(),
+		maxSize:  req.Options.GetMaxTreeReportSize(),
+		intern:   req.Options.GetTreeStringInterning(),
+		planned:  plannedDatasets(req),
+		interval: queryInterval(req),
+		baseline: treeBaseline(req),
+		partial:  req.Options.GetPartial(),
+
+		sampleTypes: newTreeSampleTypes(),
+	}
+	if q := treeQuery(req); q != nil {
+		// The query is taken from the request, so that the result
+		// does not depend on the order the reports are delivered in.
+		a.query = q.CloneVT()
+		a.query.Baseline = nil
+	}
+	return a
+}
+
+// treeQuery returns the tree query of the request.
+func treeQuery(req *querybackendv1.InvokeRequest) *querybackendv1.TreeQuery {
+	for _, q := range req.Query {
+		if q.Tree != nil {
+			return q.Tree
+		}
+	}
+	return nil
+}
+
+// treeBaseline returns the baseline of the tree query of the request.
+func treeBaseline(req *querybackendv1.InvokeRequest) *querybackendv1.TreeBaseline {
+	return treeQuery(req).GetBaseline()
+}
+
+// stripTreeBaseline removes the baseline from the tree queries of the
+// sub-query request: the delta is only computed for the final tree,
+// as the deltas of the partial trees can't be merged.
+func stripTreeBaseline(req *querybackendv1.InvokeRequest) {
+	for _, q := range req.Query {
+		if q.Tree != nil {
+			q.Tree.MaxNodes = partialTreeMaxNodes(q.Tree)
+			q.Tree.Baseline = nil
+		}
+	}
+}
+
+// partialTreeMaxNodes returns the maximum number of nodes of the partial
+// trees of the query. The partial trees of a cold paths query are not
+// truncated: otherwise, the nodes truncated would appear absent.
+func partialTreeMaxNodes(query *querybackendv1.TreeQuery) int64 {
+	if query.GetBaseline().GetColdPaths() {
+		return 0
+	}
+	return query.GetMaxNodes()
+}
 
 func (a *treeAggregator) aggregate(report *querybackendv1.Report) error {
 	r := report.Tree
+	if r.Continuation {
+		// The continuations are parts of the reports already counted.
+		return a.aggregateContinuation(r)
+	}
+	if n := a.reports.Inc(); a.limit > 0 && n > a.limit {
+		return fmt.Errorf("%w: limit %d", errTooManyTreeReports, a.limit)
+	}
+	if r.Unsymbolized {
+		a.unsymbolized.Store(true)
+	}
+	if r.SampleCountsMissing {
+		a.sampleCountsMissing.Store(true)
+	}
+	if r.SymbolsOverLimit {
+		a.symbolsOverLimit.Store(true)
+	}
+	if r.ProfilesBelowMinValue {
+		a.profilesBelowMinValue.Store(true)
+	}
+	for v := a.symbolsFormatVersion.Load(); r.SymbolsFormatVersion > v; v = a.symbolsFormatVersion.Load() {
+		if a.symbolsFormatVersion.CompareAndSwap(v, r.SymbolsFormatVersion) {
+			break
+		}
+	}
 	a.init.Do(func() {
-		a.tree = model.NewTreeMerger()
-		a.query = r.Query.CloneVT()
+		if a.query == nil {
+			// The request has no tree query: only the output parameters
+			// are taken from the report, as all the reports are expected
+			// to have the same ones, regardless of the order.
+			a.query = treeQueryOutputParams(r.Query)
+		}
+		a.output = treeQueryOutputParams(a.query)
+		if a.baseline.GetColdPaths() {
+			// The baseline has been removed from the query, and
+			// the reports are not truncated. Only the reports are
+			// matched against the output: the max nodes of the
+			// query apply to the tree built.
+			a.output.MaxNodes = 0
+		}
+		var combine TreeValueMerge
+		combine, a.initErr = getTreeValueMerge(a.query.GetValueMerge())
+		if a.initErr == nil {
+			a.expr, a.initErr = treeValueExpression(a.query)
+		}
+		if a.initErr == nil {
+			a.normalize, a.initErr = getNameNormalizer(a.query.GetNameNormalizers())
+		}
+		var opts []model.TreeMergerOption
+		if a.normalize != nil {
+			// The partial trees are normalized before they
+			// are merged, as the values may not be additive.
+			opts = append(opts, model.WithTreeMergerNodeNames(a.normalize))
+		}
+		a.tree = model.NewTreeMerger(append([]model.TreeMergerOption{
+			model.WithTreeMergerStringInterning(a.intern),
+			model.WithTreeMergerOverflowCheck(true),
+			model.WithTreeMergerValueFunc(combine),
+		}, opts...)...)
+		if a.baseline != nil && a.initErr == nil {
+			a.baselineTree, a.initErr = a.parseBaseline()
+		}
 	})
-	return a.tree.MergeTreeBytes(r.Tree)
+	if a.initErr != nil {
+		return a.initErr
+	}
+	if !a.output.EqualVT(treeQueryOutputParams(r.Query)) {
+		// All the reports are expected to be produced by the same
+		// query: merging them otherwise gives subtly wrong results.
+		return fmt.Errorf("%w: %v, expected %v", errTreeQueryMismatch, r.Query, a.query)
+	}
+	if err := a.sampleTypes.merge(r.SampleTypes, a.query.GetForceSampleTypeMerge()); err != nil {
+		return err
+	}
+	if a.query.GetRepresentative() {
+		// The representative trees are not merged:
+		// only one of them is chosen.
+		return a.representative.add(r.Tree, reportFormatVersion(r))
+	}
+	if a.expr == nil {
+		// The trees of the expression values are not merged:
+		// the expression is evaluated over the merged operands.
+		if err := a.tree.MergeTreeBytesVersion(r.Tree, reportFormatVersion(r)); err != nil {
+			return err
+		}
+	}
+	if err := a.buckets.merge(r.Buckets, reportFormatVersion(r)); err != nil {
+		return err
+	}
+	if r.Attribution == nil && r.SourceLocations == nil && r.LabelValues == nil && r.GoroutineStates == nil && r.Coverage == nil && r.MultiValueTree == nil {
+		return nil
+	}
+	a.am.Lock()
+	defer a.am.Unlock()
+	if r.Attribution != nil {
+		if a.attribution == nil {
+			a.attribution = newTreeAttribution()
+		}
+		a.attribution.merge(r.Attribution)
+	}
+	if r.SourceLocations != nil {
+		if a.locations == nil {
+			a.locations = newTreeSourceLocations()
+		}
+		a.locations.merge(r.SourceLocations)
+	}
+	if r.LabelValues != nil {
+		if a.labelValues == nil {
+			a.labelValues = newTreeLabelValues()
+		}
+		a.labelValues.merge(r.LabelValues)
+	}
+	if r.GoroutineStates != nil {
+		if a.goroutineStates == nil {
+			a.goroutineStates = newTreeLabelValues()
+		}
+		a.goroutineStates.merge(r.GoroutineStates)
+	}
+	if r.Coverage != nil {
+		if a.coverage == nil {
+			a.coverage = newTreeCoverage()
+		}
+		a.coverage.merge(r.Coverage)
+	}
+	if r.MultiValueTree != nil {
+		if a.multiValue == nil {
+			a.multiValue = newMultiValueTree(multiValueTreeWidth(a.query))
+		}
+		a.multiValue.merge(r.MultiValueTree)
+	}
+	return nil
+}
+
+// aggregateContinuation merges the part of the tree of the preceding
+// report. The report is expected to carry the tree only.
+func (a *treeAggregator) aggregateContinuation(r *querybackendv1.TreeReport) error {
+	a.init.Do(func() {
+		a.initErr = fmt.Errorf("%w: continuation is not preceded by the report", errTreeQueryMismatch)
+	})
+	if a.initErr != nil {
+		return a.initErr
+	}
+	if !a.output.EqualVT(treeQueryOutputParams(r.Query)) {
+		return fmt.Errorf("%w: %v, expected %v", errTreeQueryMismatch, r.Query, a.query)
+	}
+	if a.query.GetRepresentative() {
+		return fmt.Errorf("%w: representative tree can't be split", errTreeQueryMismatch)
+	}
+	return a.tree.MergeTreeBytesVersion(r.Tree, reportFormatVersion(r))
+}
+
+// treeQueryOutputParams returns a copy of the query without the
+// parameters that only affect the way the partial trees are built,
+// but not the result.
+func treeQueryOutputParams(query *querybackendv1.TreeQuery) *querybackendv1.TreeQuery {
+	c := query.CloneVT()
+	c.Approximate = false
+	c.ApproximationError = 0
+	c.Seed = 0
+	c.MaxNodes = partialTreeMaxNodes(query)
+	c.Baseline = nil
+	return c
+}
+
+func (a *treeAggregator) parseBaseline() (*model.Tree, error) {
+	version := int(a.baseline.FormatVersion)
+	if version == 0 {
+		version = treeFormatVersion(a.query)
+	}
+	tree, err := model.UnmarshalTreeVersion(a.baseline.Tree, version)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidTreeBaseline, err)
+	}
+	if a.normalize != nil {
+		tree.FormatNodeNames(a.normalize)
+	}
+	return tree, nil
 }
 
 func (a *treeAggregator) build() *querybackendv1.Report {
-	return &querybackendv1.Report{
+	r, err := a.buildReport()
+	if err != nil {
+		a.buildErr = err
+		return nil
+	}
+	return r
+}
+
+func (a *treeAggregator) buildError() error { return a.buildErr }
+
+func (a *treeAggregator) buildReport() (*querybackendv1.Report, error) {
+	tree := a.tree.Tree()
+	if a.query.GetRepresentative() {
+		tree = a.representative.tree()
+	}
+	if a.expr != nil && !a.partial {
+		tree = a.expr.tree(a.multiValue)
+	}
+	coldPaths := a.baselineTree != nil && a.baseline.ColdPaths
+	if coldPaths {
+		// The comparison precedes the other transformations
+		// of the merged tree: a node they remove would
+		// appear absent.
+		tree = tree.ColdPaths(a.baselineTree, a.baseline.MaxColdRatio, a.baseline.MinDelta)
+	}
+	if maxDepth := subtreeMaxDepth(tree, a.query); maxDepth > 0 {
+		// The reports are expected to be limited already;
+		// the limit is enforced for the merged tree as well.
+		tree.LimitDepth(maxDepth)
+	}
+	if a.query.GetDropZeroNodes() {
+		tree.DropZeroNodes()
+	}
+	if maxFunctions := a.query.GetMaxFunctions(); maxFunctions > 0 {
+		// The functions are ranked by the values of the merged
+		// tree: the top ones may differ from those of a partial.
+		tree.LimitFunctions(int(maxFunctions))
+	}
+	if minValue := treeMinValue(tree, a.query); !a.partial && (minValue > 0 || a.query.GetMaxValue() > 0) {
+		// The node value range only applies to the final tree: a
+		// node out of the range in a partial tree may be within it
+		// once merged, and its descendants would be lost otherwise.
+		tree.FilterNodes(minValue, a.query.GetMaxValue())
+		if a.multiValue != nil && a.query.GetSampleCounts() {
+			a.multiValue.filterNodes(0, minValue, a.query.GetMaxValue())
+		}
+	}
+	if a.baselineTree != nil && !coldPaths {
+		tree = tree.Delta(a.baselineTree, a.baseline.MinDelta, a.baseline.MinDeltaPercent)
+	}
+	if a.query.GetHottestPath() {
+		// The path of a partial tree may differ from
+		// the one of the merged tree.
+		tree.HottestPath()
+	}
+	version := treeFormatVersion(a.query)
+	nodes := tree.Size()
+	// The query of the request retains the max nodes,
+	// including the ones of a cold paths query.
+	maxNodes := a.query.GetMaxNodes()
+	b := tree.BytesVersion(maxNodes, version)
+	sizeCapped := a.maxSize > 0 && int64(len(b)) > a.maxSize
+	if sizeCapped {
+		b, maxNodes = capTreeSize(tree, b, nodes, maxNodes, version, a.maxSize)
+	}
+	r := &querybackendv1.Report{
 		Tree: &querybackendv1.TreeReport{
-			Query: a.query,
-			Tree:  a.tree.Tree().Bytes(a.query.GetMaxNodes()),
+			Query:         a.query,
+			Tree:          b,
+			Unsymbolized:  a.unsymbolized.Load(),
+			FormatVersion: uint32(version),
+			Nodes:         nodes,
+			Truncated:     treeTruncated(nodes, maxNodes),
+
+			SampleCountsMissing: a.sampleCountsMissing.Load(),
+			SymbolsOverLimit:    a.symbolsOverLimit.Load(),
+			SizeCapped:          sizeCapped,
+
+			ProfilesBelowMinValue: a.profilesBelowMinValue.Load(),
+			SymbolsFormatVersion:  a.symbolsFormatVersion.Load(),
+			SampleTypes:           a.sampleTypes.proto(),
 		},
 	}
+	if a.attribution != nil || a.locations != nil || a.labelValues != nil || a.goroutineStates != nil || a.coverage != nil || a.query.GetLegend() != nil {
+		// Only the nodes that remain after truncation are attributed
+		// and annotated with the source locations, label values,
+		// goroutine states, and coverage, and included in the legend.
+		truncated, err := model.UnmarshalTreeVersion(r.Tree.Tree, version)
+		if err != nil {
+			return nil, err
+		}
+		if a.attribution != nil {
+			r.Tree.Attribution = a.attribution.proto(truncated)
+		}
+		if a.locations != nil {
+			r.Tree.SourceLocations = a.locations.proto(truncated)
+		}
+		if a.labelValues != nil {
+			// The dominant values are chosen after
+			// the contributions have been combined.
+			r.Tree.LabelValues = a.labelValues.proto(truncated)
+		}
+		if a.goroutineStates != nil {
+			r.Tree.GoroutineStates = a.goroutineStates.proto(truncated)
+		}
+		if a.coverage != nil {
+			r.Tree.Coverage = a.coverage.proto(truncated, a.planned)
+		}
+		if legend := a.query.GetLegend(); legend != nil {
+			r.Tree.Legend = treeLegend(truncated, legend)
+		}
+	}
+	if a.multiValue != nil && (a.expr == nil || a.partial) {
+		r.Tree.MultiValueTree = a.multiValue.proto(a.query.GetMaxNodes())
+	}
+	r.Tree.Buckets = a.buckets.proto(a.query.GetMaxDepth(), a.query.GetMaxNodes(), version)
+	if a.query.GetNodeIds() {
+		var err error
+		if r.Tree.NodeIds, err = model.TreeNodeIDs(r.Tree.Tree, version); err != nil {
+			return nil, err
+		}
+	}
+	if a.query.GetIntervalValues() {
+		var err error
+		if r.Tree.IntervalValues, err = treeIntervalValues(r.Tree.Tree, version, a.interval); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
 }
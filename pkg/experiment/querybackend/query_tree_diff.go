@@ -0,0 +1,390 @@
+package querybackend
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/prometheus/promql/parser"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend/block"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+func init() {
+	registerQueryType(
+		querybackendv1.QueryType_QUERY_TREE_DIFF,
+		querybackendv1.ReportType_REPORT_TREE_DIFF,
+		queryTreeDiff,
+		newTreeDiffAggregator,
+		noQueryTimeout,
+		[]block.Section{
+			block.SectionTSDB,
+			block.SectionProfiles,
+			block.SectionSymbols,
+		}...,
+	)
+}
+
+// queryTreeDiff resolves the trees of the two sets, and reports the nodes
+// of both. The partial trees are not ranked by the differences, which may
+// not be the ones of the merged trees, but by the magnitudes.
+func queryTreeDiff(q *queryContext, query *querybackendv1.Query) (*querybackendv1.Report, error) {
+	td := query.TreeDiff
+	if err := validateTreeDiffQuery(td); err != nil {
+		return nil, err
+	}
+	var sets [2]*model.Tree
+	for i, set := range []*querybackendv1.TreeDiffSet{td.Baseline, td.Comparison} {
+		sq, ok := q.withTimeRange(set.StartTime, set.EndTime)
+		if !ok {
+			sets[i] = new(model.Tree)
+			continue
+		}
+		tree, err := flameGraphDiffSide(sq, set.LabelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("tree diff: set %d: %w", i, err)
+		}
+		sets[i] = tree
+	}
+	d := newTreeDiff()
+	d.addTree(sets[0], func(v *treeDiffNode) *treeDiffValues { return &v.baseline })
+	d.addTree(sets[1], func(v *treeDiffNode) *treeDiffValues { return &v.comparison })
+	nodes, truncated := d.proto(td.GetMaxNodes(), (*treeDiffNode).magnitude, td.GetDiffMode())
+	return &querybackendv1.Report{
+		TreeDiff: &querybackendv1.TreeDiffReport{
+			Query:     td.CloneVT(),
+			Nodes:     nodes,
+			Truncated: truncated,
+		},
+	}, nil
+}
+
+func validateTreeDiffQuery(q *querybackendv1.TreeDiffQuery) error {
+	if q.GetBaseline() == nil || q.GetComparison() == nil {
+		return fmt.Errorf("tree diff: both baseline and comparison sets are required")
+	}
+	for _, set := range []*querybackendv1.TreeDiffSet{q.Baseline, q.Comparison} {
+		if set.StartTime > set.EndTime {
+			return fmt.Errorf("tree diff: set start must not be after the end")
+		}
+		if set.LabelSelector != "" {
+			if _, err := parser.ParseMetricSelector(set.LabelSelector); err != nil {
+				return fmt.Errorf("tree diff: label selection is invalid: %w", err)
+			}
+		}
+	}
+	if q.GetMaxNodes() < 0 {
+		return fmt.Errorf("tree diff: max nodes must not be negative")
+	}
+	if err := validateDiffMode(q.GetDiffMode()); err != nil {
+		return fmt.Errorf("tree diff: %w", err)
+	}
+	return nil
+}
+
+func validateDiffMode(mode querybackendv1.DiffMode) error {
+	if _, ok := querybackendv1.DiffMode_name[int32(mode)]; !ok {
+		return fmt.Errorf("unknown diff mode %d", mode)
+	}
+	return nil
+}
+
+// diffValue returns the difference of the values in the given mode.
+func diffValue(mode querybackendv1.DiffMode, baseline, comparison int64) float64 {
+	switch mode {
+	case querybackendv1.DiffMode_DIFF_MODE_RATIO:
+		return diffRatio(baseline, comparison)
+	case querybackendv1.DiffMode_DIFF_MODE_LOG_RATIO:
+		return math.Log(diffRatio(baseline, comparison))
+	default:
+		return float64(comparison - baseline)
+	}
+}
+
+// diffRatio returns the ratio of the comparison value to the baseline.
+// If the baseline is zero, the ratio is an infinity of the sign of the
+// comparison value, or one, if the comparison value is zero as well.
+func diffRatio(baseline, comparison int64) float64 {
+	if baseline == 0 && comparison == 0 {
+		return 1
+	}
+	return float64(comparison) / float64(baseline)
+}
+
+// treeDiffQuery returns the tree diff query of the request.
+func treeDiffQuery(req *querybackendv1.InvokeRequest) *querybackendv1.TreeDiffQuery {
+	for _, q := range req.GetQuery() {
+		if q.TreeDiff != nil {
+			return q.TreeDiff
+		}
+	}
+	return nil
+}
+
+type treeDiffValues struct {
+	self, total int64
+}
+
+type treeDiffNode struct {
+	name       string
+	baseline   treeDiffValues
+	comparison treeDiffValues
+	children   map[string]*treeDiffNode
+	// Largest rank of the node and its descendants.
+	maxRank int64
+}
+
+func (n *treeDiffNode) child(name string) *treeDiffNode {
+	if n.children == nil {
+		n.children = make(map[string]*treeDiffNode)
+	}
+	c, ok := n.children[name]
+	if !ok {
+		c = &treeDiffNode{name: name}
+		n.children[name] = c
+	}
+	return c
+}
+
+func (n *treeDiffNode) diff() int64 {
+	return absInt64(n.comparison.total - n.baseline.total)
+}
+
+// magnitude returns the largest of the totals of the node. Unlike the
+// differences, the magnitudes of the nodes only grow as the partial
+// trees are merged, which makes it suitable for the partial trees.
+func (n *treeDiffNode) magnitude() int64 {
+	return max(absInt64(n.baseline.total), absInt64(n.comparison.total))
+}
+
+// sortedChildren returns the children of the node ordered by name.
+func (n *treeDiffNode) sortedChildren() []*treeDiffNode {
+	children := make([]*treeDiffNode, 0, len(n.children))
+	for _, c := range n.children {
+		children = append(children, c)
+	}
+	slices.SortFunc(children, func(a, b *treeDiffNode) int {
+		return strings.Compare(a.name, b.name)
+	})
+	return children
+}
+
+// treeDiff is a tree which nodes carry the values of both sets.
+type treeDiff struct {
+	root *treeDiffNode
+}
+
+func newTreeDiff() *treeDiff {
+	return &treeDiff{root: new(treeDiffNode)}
+}
+
+func (d *treeDiff) addTree(t *model.Tree, values func(*treeDiffNode) *treeDiffValues) {
+	var add func(*treeDiffNode, []*model.TreeNode)
+	add = func(parent *treeDiffNode, nodes []*model.TreeNode) {
+		for _, n := range nodes {
+			c := parent.child(n.Name)
+			v := values(c)
+			v.self += n.Self
+			v.total += n.Total
+			add(c, n.Children)
+		}
+	}
+	add(d.root, t.Nodes())
+}
+
+// merge adds the nodes of the report. The values of the two sets
+// are added independently: the differences are not merged.
+func (d *treeDiff) merge(nodes []*querybackendv1.TreeDiffNode) error {
+	merged := make([]*treeDiffNode, len(nodes))
+	for i, n := range nodes {
+		parent := d.root
+		if n.Parent >= 0 {
+			if int(n.Parent) >= i {
+				return fmt.Errorf("tree diff: node %d: invalid parent %d", i, n.Parent)
+			}
+			parent = merged[n.Parent]
+		}
+		c := parent.child(n.Name)
+		c.baseline.self += n.BaselineSelf
+		c.baseline.total += n.BaselineTotal
+		c.comparison.self += n.ComparisonSelf
+		c.comparison.total += n.ComparisonTotal
+		merged[i] = c
+	}
+	return nil
+}
+
+// proto returns the nodes of the tree, parents preceding their children.
+// If maxNodes is positive, only the nodes of the highest rank, or having
+// descendants that do, are retained: the values of the nodes removed are
+// accounted in the "other" node of the parent. The differences of the
+// nodes are computed in the given mode.
+func (d *treeDiff) proto(maxNodes int64, rank func(*treeDiffNode) int64, mode querybackendv1.DiffMode) ([]*querybackendv1.TreeDiffNode, bool) {
+	order := d.breadthFirst()
+	retained := func(*treeDiffNode) bool { return true }
+	truncated := maxNodes > 0 && int64(len(order)) > maxNodes
+	if truncated {
+		// A node is ranked by the largest rank in its subtree: the rank
+		// of a node is never below the ranks of its descendants,
+		// therefore the ancestors of a node retained are retained as well.
+		// The ties are broken in the breadth-first order, which puts the
+		// parents before their children.
+		for i := len(order) - 1; i >= 0; i-- {
+			n := order[i]
+			n.maxRank = rank(n)
+			for _, c := range n.children {
+				n.maxRank = max(n.maxRank, c.maxRank)
+			}
+		}
+		ranked := slices.Clone(order)
+		slices.SortStableFunc(ranked, func(a, b *treeDiffNode) int {
+			return cmp.Compare(b.maxRank, a.maxRank)
+		})
+		keep := make(map[*treeDiffNode]struct{}, maxNodes)
+		for _, n := range ranked[:maxNodes] {
+			keep[n] = struct{}{}
+		}
+		retained = func(n *treeDiffNode) bool {
+			_, ok := keep[n]
+			return ok
+		}
+	}
+
+	type entry struct {
+		node   *treeDiffNode
+		parent int32
+	}
+	var queue []entry
+	enqueueChildren := func(n *treeDiffNode, index int32) {
+		var other treeDiffNode
+		for _, c := range n.sortedChildren() {
+			if retained(c) {
+				queue = append(queue, entry{node: c, parent: index})
+				continue
+			}
+			other.baseline.total += c.baseline.total
+			other.comparison.total += c.comparison.total
+		}
+		if other.baseline.total != 0 || other.comparison.total != 0 {
+			other.name = truncatedNodeName
+			other.baseline.self = other.baseline.total
+			other.comparison.self = other.comparison.total
+			queue = append(queue, entry{node: &other, parent: index})
+		}
+	}
+	nodes := make([]*querybackendv1.TreeDiffNode, 0, len(order))
+	enqueueChildren(d.root, -1)
+	for len(queue) > 0 {
+		e := queue[0]
+		queue = queue[1:]
+		nodes = append(nodes, &querybackendv1.TreeDiffNode{
+			Parent:          e.parent,
+			Name:            e.node.name,
+			BaselineSelf:    e.node.baseline.self,
+			BaselineTotal:   e.node.baseline.total,
+			ComparisonSelf:  e.node.comparison.self,
+			ComparisonTotal: e.node.comparison.total,
+			Diff:            diffValue(mode, e.node.baseline.total, e.node.comparison.total),
+		})
+		enqueueChildren(e.node, int32(len(nodes)-1))
+	}
+	return nodes, truncated
+}
+
+// truncateTreeDiffSides truncates the trees of the two sides to the
+// same nodes: the ones of the largest magnitude. The values of the
+// nodes removed are accounted in the "other" node of the parent.
+func truncateTreeDiffSides(left, right *model.Tree, maxNodes int64) (*model.Tree, *model.Tree) {
+	if maxNodes <= 0 {
+		return left, right
+	}
+	d := newTreeDiff()
+	d.addTree(left, func(v *treeDiffNode) *treeDiffValues { return &v.baseline })
+	d.addTree(right, func(v *treeDiffNode) *treeDiffValues { return &v.comparison })
+	nodes, truncated := d.proto(maxNodes, (*treeDiffNode).magnitude, querybackendv1.DiffMode_DIFF_MODE_DELTA)
+	if !truncated {
+		return left, right
+	}
+	left, right = new(model.Tree), new(model.Tree)
+	stacks := make([][]string, len(nodes))
+	for i, n := range nodes {
+		var stack []string
+		if n.Parent >= 0 {
+			stack = stacks[n.Parent]
+		}
+		stack = append(slices.Clip(stack), n.Name)
+		stacks[i] = stack
+		if n.BaselineSelf != 0 {
+			left.InsertStack(n.BaselineSelf, stack...)
+		}
+		if n.ComparisonSelf != 0 {
+			right.InsertStack(n.ComparisonSelf, stack...)
+		}
+	}
+	return left, right
+}
+
+// breadthFirst returns the nodes of the tree, except the root,
+// in the breadth-first order.
+func (d *treeDiff) breadthFirst() []*treeDiffNode {
+	var order []*treeDiffNode
+	queue := d.root.sortedChildren()
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		order = append(order, n)
+		queue = append(queue, n.sortedChildren()...)
+	}
+	return order
+}
+
+type treeDiffAggregator struct {
+	init    sync.Once
+	query   *querybackendv1.TreeDiffQuery
+	m       sync.Mutex
+	tree    *treeDiff
+	partial bool
+}
+
+func newTreeDiffAggregator(req *querybackendv1.InvokeRequest) aggregator {
+	a := &treeDiffAggregator{
+		tree:    newTreeDiff(),
+		partial: req.GetOptions().GetPartial(),
+	}
+	if q := treeDiffQuery(req); q != nil {
+		a.query = q.CloneVT()
+	}
+	return a
+}
+
+func (a *treeDiffAggregator) aggregate(report *querybackendv1.Report) error {
+	r := report.TreeDiff
+	a.init.Do(func() {
+		if a.query == nil {
+			a.query = r.Query.CloneVT()
+		}
+	})
+	a.m.Lock()
+	defer a.m.Unlock()
+	return a.tree.merge(r.Nodes)
+}
+
+func (a *treeDiffAggregator) build() *querybackendv1.Report {
+	rank := (*treeDiffNode).diff
+	if a.partial {
+		rank = (*treeDiffNode).magnitude
+	}
+	nodes, truncated := a.tree.proto(a.query.GetMaxNodes(), rank, a.query.GetDiffMode())
+	return &querybackendv1.Report{
+		TreeDiff: &querybackendv1.TreeDiffReport{
+			Query:     a.query,
+			Nodes:     nodes,
+			Truncated: truncated,
+		},
+	}
+}
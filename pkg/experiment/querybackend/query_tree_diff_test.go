@@ -0,0 +1,188 @@
+package querybackend
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+func Test_TreeDiff_Truncation(t *testing.T) {
+	baseline := new(model.Tree)
+	baseline.InsertStack(10, "main", "a")
+	baseline.InsertStack(10, "main", "b")
+	baseline.InsertStack(5, "main", "c")
+	comparison := new(model.Tree)
+	comparison.InsertStack(10, "main", "a")
+	comparison.InsertStack(30, "main", "b")
+	comparison.InsertStack(1, "main", "c")
+	report := func() *querybackendv1.Report {
+		d := newTreeDiff()
+		d.addTree(baseline, func(v *treeDiffNode) *treeDiffValues { return &v.baseline })
+		d.addTree(comparison, func(v *treeDiffNode) *treeDiffValues { return &v.comparison })
+		nodes, truncated := d.proto(0, (*treeDiffNode).diff, querybackendv1.DiffMode_DIFF_MODE_DELTA)
+		require.False(t, truncated)
+		return &querybackendv1.Report{TreeDiff: &querybackendv1.TreeDiffReport{Nodes: nodes}}
+	}
+
+	a := newTreeDiffAggregator(&querybackendv1.InvokeRequest{
+		Query: []*querybackendv1.Query{{
+			QueryType: querybackendv1.QueryType_QUERY_TREE_DIFF,
+			TreeDiff:  &querybackendv1.TreeDiffQuery{MaxNodes: 2},
+		}},
+	})
+	require.NoError(t, a.aggregate(report()))
+	require.NoError(t, a.aggregate(report()))
+	r := a.build().TreeDiff
+	require.True(t, r.Truncated)
+	// The node "b" changed the most; "a" did not change,
+	// despite having a larger value than "c", which did.
+	require.Equal(t, []*querybackendv1.TreeDiffNode{
+		{Parent: -1, Name: "main", BaselineTotal: 50, ComparisonTotal: 82, Diff: 32},
+		{Parent: 0, Name: "b", BaselineSelf: 20, BaselineTotal: 20, ComparisonSelf: 60, ComparisonTotal: 60, Diff: 40},
+		{Parent: 0, Name: "other", BaselineSelf: 30, BaselineTotal: 30, ComparisonSelf: 22, ComparisonTotal: 22, Diff: -8},
+	}, r.Nodes)
+
+	// The other nodes have no effect, if merged again.
+	b := newTreeDiffAggregator(new(querybackendv1.InvokeRequest))
+	require.NoError(t, b.aggregate(&querybackendv1.Report{TreeDiff: r}))
+	require.Equal(t, r.Nodes, b.build().TreeDiff.Nodes)
+
+	require.Error(t, a.aggregate(&querybackendv1.Report{TreeDiff: &querybackendv1.TreeDiffReport{
+		Nodes: []*querybackendv1.TreeDiffNode{{Parent: 0, Name: "main"}},
+	}}))
+
+	// The partial trees retain the nodes of the largest totals.
+	partial := newTreeDiffAggregator(&querybackendv1.InvokeRequest{
+		Query: []*querybackendv1.Query{{
+			QueryType: querybackendv1.QueryType_QUERY_TREE_DIFF,
+			TreeDiff:  &querybackendv1.TreeDiffQuery{MaxNodes: 3},
+		}},
+		Options: &querybackendv1.InvokeOptions{Partial: true},
+	})
+	require.NoError(t, partial.aggregate(report()))
+	r = partial.build().TreeDiff
+	require.True(t, r.Truncated)
+	require.Equal(t, []*querybackendv1.TreeDiffNode{
+		{Parent: -1, Name: "main", BaselineTotal: 25, ComparisonTotal: 41, Diff: 16},
+		{Parent: 0, Name: "a", BaselineSelf: 10, BaselineTotal: 10, ComparisonSelf: 10, ComparisonTotal: 10},
+		{Parent: 0, Name: "b", BaselineSelf: 10, BaselineTotal: 10, ComparisonSelf: 30, ComparisonTotal: 30, Diff: 20},
+		{Parent: 0, Name: "other", BaselineSelf: 5, BaselineTotal: 5, ComparisonSelf: 1, ComparisonTotal: 1, Diff: -4},
+	}, r.Nodes)
+}
+
+func Test_DiffValue(t *testing.T) {
+	const (
+		delta    = querybackendv1.DiffMode_DIFF_MODE_DELTA
+		ratio    = querybackendv1.DiffMode_DIFF_MODE_RATIO
+		logRatio = querybackendv1.DiffMode_DIFF_MODE_LOG_RATIO
+	)
+	for _, tc := range []struct {
+		mode                 querybackendv1.DiffMode
+		baseline, comparison int64
+		expected             float64
+	}{
+		{mode: delta, baseline: 10, comparison: 4, expected: -6},
+		{mode: ratio, baseline: 10, comparison: 4, expected: 0.4},
+		{mode: ratio, baseline: 0, comparison: 4, expected: math.Inf(1)},
+		{mode: ratio, baseline: 0, comparison: -4, expected: math.Inf(-1)},
+		{mode: ratio, baseline: 10, comparison: 0, expected: 0},
+		{mode: ratio, baseline: 0, comparison: 0, expected: 1},
+		{mode: logRatio, baseline: 10, comparison: 10, expected: 0},
+		{mode: logRatio, baseline: 0, comparison: 4, expected: math.Inf(1)},
+		{mode: logRatio, baseline: 10, comparison: 0, expected: math.Inf(-1)},
+		{mode: logRatio, baseline: 0, comparison: 0, expected: 0},
+	} {
+		require.Equal(t, tc.expected, diffValue(tc.mode, tc.baseline, tc.comparison), "%+v", tc)
+	}
+	require.True(t, math.IsNaN(diffValue(logRatio, 10, -4)))
+}
+
+func Test_TreeDiff_Ratio(t *testing.T) {
+	report := func(baseline, comparison int64) *querybackendv1.Report {
+		return &querybackendv1.Report{TreeDiff: &querybackendv1.TreeDiffReport{
+			Nodes: []*querybackendv1.TreeDiffNode{
+				{Parent: -1, Name: "main", BaselineTotal: baseline, ComparisonTotal: comparison},
+				{Parent: 0, Name: "a", BaselineSelf: baseline, BaselineTotal: baseline},
+				{Parent: 0, Name: "b", ComparisonSelf: comparison, ComparisonTotal: comparison},
+			},
+		}}
+	}
+	a := newTreeDiffAggregator(&querybackendv1.InvokeRequest{
+		Query: []*querybackendv1.Query{{
+			QueryType: querybackendv1.QueryType_QUERY_TREE_DIFF,
+			TreeDiff:  &querybackendv1.TreeDiffQuery{DiffMode: querybackendv1.DiffMode_DIFF_MODE_RATIO},
+		}},
+	})
+	// The ratios are computed for the merged values:
+	// (30+10) / (10+10), rather than 30/10 + 10/10.
+	require.NoError(t, a.aggregate(report(10, 30)))
+	require.NoError(t, a.aggregate(report(10, 10)))
+	require.Equal(t, []*querybackendv1.TreeDiffNode{
+		{Parent: -1, Name: "main", BaselineTotal: 20, ComparisonTotal: 40, Diff: 2},
+		{Parent: 0, Name: "a", BaselineSelf: 20, BaselineTotal: 20},
+		{Parent: 0, Name: "b", ComparisonSelf: 40, ComparisonTotal: 40, Diff: math.Inf(1)},
+	}, a.build().TreeDiff.Nodes)
+}
+
+func Test_QueryTreeDiff(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	invoke := func(query *querybackendv1.TreeDiffQuery) (*querybackendv1.TreeDiffReport, error) {
+		resp, err := reader.Invoke(context.Background(), newTestInvokeRequest(
+			blocks,
+			`{service_name=~".+", __type__="cpu"}`,
+			&querybackendv1.Query{
+				QueryType: querybackendv1.QueryType_QUERY_TREE_DIFF,
+				TreeDiff:  query,
+			},
+		))
+		if err != nil {
+			return nil, err
+		}
+		return resp.Reports[0].TreeDiff, nil
+	}
+
+	_, err := invoke(&querybackendv1.TreeDiffQuery{Baseline: new(querybackendv1.TreeDiffSet)})
+	require.Error(t, err)
+	_, err = invoke(&querybackendv1.TreeDiffQuery{
+		Baseline:   new(querybackendv1.TreeDiffSet),
+		Comparison: new(querybackendv1.TreeDiffSet),
+		DiffMode:   querybackendv1.DiffMode(-1),
+	})
+	require.Error(t, err)
+
+	set := &querybackendv1.TreeDiffSet{EndTime: math.MaxInt64 / int64(1e6)}
+	r, err := invoke(&querybackendv1.TreeDiffQuery{Baseline: set, Comparison: set, MaxNodes: 16})
+	require.NoError(t, err)
+	require.NotEmpty(t, r.Nodes)
+	// The sets are identical: the tree does not differ.
+	for _, n := range r.Nodes {
+		require.NotZero(t, n.BaselineTotal)
+		require.Equal(t, n.BaselineSelf, n.ComparisonSelf)
+		require.Equal(t, n.BaselineTotal, n.ComparisonTotal)
+	}
+
+	// The comparison set matches no profiles.
+	empty := &querybackendv1.TreeDiffSet{EndTime: math.MaxInt64 / int64(1e6), LabelSelector: `{service_name="none"}`}
+	r, err = invoke(&querybackendv1.TreeDiffQuery{Baseline: set, Comparison: empty})
+	require.NoError(t, err)
+	require.NotEmpty(t, r.Nodes)
+	for _, n := range r.Nodes {
+		require.Zero(t, n.ComparisonTotal)
+	}
+
+	// The functions absent in the comparison set have the ratio of zero.
+	r, err = invoke(&querybackendv1.TreeDiffQuery{
+		Baseline:   set,
+		Comparison: empty,
+		DiffMode:   querybackendv1.DiffMode_DIFF_MODE_LOG_RATIO,
+	})
+	require.NoError(t, err)
+	for _, n := range r.Nodes {
+		require.Equal(t, math.Inf(-1), n.Diff)
+	}
+}
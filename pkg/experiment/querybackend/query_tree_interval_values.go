@@ -0,0 +1,32 @@
+package querybackend
+
+import (
+	"time"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+// queryInterval returns the duration of the time range of the request.
+func queryInterval(req *querybackendv1.InvokeRequest) time.Duration {
+	return time.Duration(req.GetEndTime()-req.GetStartTime()) * time.Millisecond
+}
+
+// treeIntervalValues returns the node totals of the serialized tree per
+// second of the interval. The division is only done once, for the final
+// tree: the totals of the partial trees are merged as is.
+func treeIntervalValues(b []byte, version int, interval time.Duration) ([]float64, error) {
+	if interval <= 0 {
+		return nil, nil
+	}
+	totals, err := model.TreeNodeTotals(b, version)
+	if err != nil {
+		return nil, err
+	}
+	seconds := interval.Seconds()
+	values := make([]float64, len(totals))
+	for i, v := range totals {
+		values[i] = float64(v) / seconds
+	}
+	return values, nil
+}
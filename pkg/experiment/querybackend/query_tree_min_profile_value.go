@@ -0,0 +1,45 @@
+package querybackend
+
+import (
+	"fmt"
+
+	"go.uber.org/atomic"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+)
+
+func validateMinProfileValue(query *querybackendv1.TreeQuery) error {
+	if query.GetMinProfileValue() < 0 {
+		return fmt.Errorf("min profile value must not be negative")
+	}
+	return nil
+}
+
+// withMinProfileValue returns a copy of the query context that skips
+// the profiles which total value is below the threshold of the query.
+func (q *queryContext) withMinProfileValue(query *querybackendv1.TreeQuery) *queryContext {
+	threshold := query.GetMinProfileValue()
+	if threshold <= 0 {
+		return q
+	}
+	c := *q
+	c.minProfileValue = threshold
+	c.belowMinValue = new(atomic.Bool)
+	return &c
+}
+
+// skipProfile reports whether the profile of the given
+// total value must be skipped, and records the fact.
+func (q *queryContext) skipProfile(total int64) bool {
+	if q.minProfileValue <= 0 || total >= q.minProfileValue {
+		return false
+	}
+	q.belowMinValue.Store(true)
+	return true
+}
+
+// profilesBelowMinValue reports whether any of the
+// profiles have been skipped by the query context.
+func (q *queryContext) profilesBelowMinValue() bool {
+	return q.belowMinValue != nil && q.belowMinValue.Load()
+}
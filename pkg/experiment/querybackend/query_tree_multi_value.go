@@ -0,0 +1,254 @@
+package querybackend
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/relabel"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+	"github.com/grafana/pyroscope/pkg/phlaredb/symdb"
+)
+
+func queryMultiValueTree(
+	q *queryContext,
+	query *querybackendv1.Query,
+	rules []*relabel.Config,
+	sanitize NameSanitizer,
+	opts []symdb.ResolverOption,
+) (*querybackendv1.Report, error) {
+	profileTypes := query.Tree.ProfileTypes
+	tree := newMultiValueTree(len(profileTypes))
+	var unsymbolized bool
+	for i, profileType := range profileTypes {
+		t, err := model.ParseProfileTypeSelector(profileType)
+		if err != nil {
+			return nil, err
+		}
+		resolved, u, err := resolveTree(q.withMatchers(model.SelectorFromProfileType(t)), rules, opts...)
+		if err != nil {
+			return nil, err
+		}
+		if sanitize != nil {
+			resolved.FormatNodeNames(sanitize)
+		}
+		if query.Tree.GetInverted() {
+			resolved = resolved.Inverted()
+		}
+		if maxDepth := query.Tree.GetMaxDepth(); maxDepth > 0 {
+			resolved.LimitDepth(int(maxDepth))
+		}
+		tree.addTree(i, resolved)
+		unsymbolized = unsymbolized || u > 0
+	}
+	resp := &querybackendv1.Report{
+		Tree: &querybackendv1.TreeReport{
+			Query:          query.Tree.CloneVT(),
+			MultiValueTree: tree.proto(query.Tree.GetMaxNodes()),
+			Unsymbolized:   unsymbolized,
+
+			SymbolsFormatVersion: uint32(q.ds.SymbolsFormatVersion()),
+		},
+	}
+	return resp, nil
+}
+
+// truncatedNodeName matches the name model.Tree uses for truncated nodes.
+const truncatedNodeName = "other"
+
+// multiValueTree is a tree, each node of which carries multiple
+// values. It is not safe for concurrent use.
+type multiValueTree struct {
+	width int
+	root  multiValueNode
+}
+
+type multiValueNode struct {
+	name     string
+	children map[string]*multiValueNode
+	self     []int64
+	total    int64 // Sum of all the values in the subtree.
+}
+
+func newMultiValueTree(width int) *multiValueTree {
+	return &multiValueTree{width: width}
+}
+
+func (n *multiValueNode) child(name string, width int) *multiValueNode {
+	if n.children == nil {
+		n.children = make(map[string]*multiValueNode)
+	}
+	c, ok := n.children[name]
+	if !ok {
+		c = &multiValueNode{name: name, self: make([]int64, width)}
+		n.children[name] = c
+	}
+	return c
+}
+
+func (n *multiValueNode) sortedChildren() []*multiValueNode {
+	children := make([]*multiValueNode, 0, len(n.children))
+	for _, c := range n.children {
+		children = append(children, c)
+	}
+	slices.SortFunc(children, func(a, b *multiValueNode) int {
+		return strings.Compare(a.name, b.name)
+	})
+	return children
+}
+
+// addTree adds the tree values to the given slot.
+func (t *multiValueTree) addTree(slot int, tree *model.Tree) {
+	tree.IterateStacks(func(_ string, self int64, stack []string) {
+		n := &t.root
+		for i := len(stack) - 1; i >= 0; i-- {
+			n = n.child(stack[i], t.width)
+		}
+		n.self[slot] += self
+	})
+}
+
+func (t *multiValueTree) merge(p *querybackendv1.MultiValueTree) {
+	nodes := make([]*multiValueNode, len(p.Nodes))
+	for i, x := range p.Nodes {
+		parent := &t.root
+		if x.Parent >= 0 && int(x.Parent) < i {
+			parent = nodes[x.Parent]
+		}
+		n := parent.child(x.Name, t.width)
+		for j := 0; j < len(x.Self) && j < t.width; j++ {
+			n.self[j] += x.Self[j]
+		}
+		nodes[i] = n
+	}
+}
+
+// proto returns the tree in the wire format. If maxNodes is positive,
+// at most maxNodes nodes with the largest totals are retained, and the
+// rest are replaced with the "other" nodes. Ties are broken by the node
+// depth, name, and position, therefore the result is deterministic.
+func (t *multiValueTree) proto(maxNodes int64) *querybackendv1.MultiValueTree {
+	type ranked struct {
+		node  *multiValueNode
+		depth int
+	}
+	var nodes []ranked
+	var propagate func(n *multiValueNode, depth int) int64
+	propagate = func(n *multiValueNode, depth int) int64 {
+		n.total = 0
+		for _, v := range n.self {
+			n.total += v
+		}
+		for _, c := range n.sortedChildren() {
+			nodes = append(nodes, ranked{node: c, depth: depth})
+			n.total += propagate(c, depth+1)
+		}
+		return n.total
+	}
+	propagate(&t.root, 0)
+	var retained map[*multiValueNode]struct{}
+	if maxNodes > 0 && int64(len(nodes)) > maxNodes {
+		// A node total is never less than the totals of its children,
+		// and a parent precedes its children on ties, therefore the
+		// parents of the retained nodes are always retained.
+		slices.SortStableFunc(nodes, func(a, b ranked) int {
+			switch {
+			case a.node.total != b.node.total:
+				if a.node.total > b.node.total {
+					return -1
+				}
+				return 1
+			case a.depth != b.depth:
+				return a.depth - b.depth
+			default:
+				return strings.Compare(a.node.name, b.node.name)
+			}
+		})
+		retained = make(map[*multiValueNode]struct{}, maxNodes)
+		for _, r := range nodes[:maxNodes] {
+			retained[r.node] = struct{}{}
+		}
+	}
+
+	p := new(querybackendv1.MultiValueTree)
+	var emit func(n *multiValueNode, parent int32)
+	emit = func(n *multiValueNode, parent int32) {
+		var other []int64
+		for _, c := range n.sortedChildren() {
+			if _, ok := retained[c]; retained != nil && !ok {
+				if other == nil {
+					other = make([]int64, t.width)
+				}
+				c.sum(other)
+				continue
+			}
+			i := int32(len(p.Nodes))
+			p.Nodes = append(p.Nodes, &querybackendv1.MultiValueTreeNode{
+				Parent: parent,
+				Name:   c.name,
+				Self:   c.self,
+			})
+			emit(c, i)
+		}
+		if other != nil {
+			p.Nodes = append(p.Nodes, &querybackendv1.MultiValueTreeNode{
+				Parent: parent,
+				Name:   truncatedNodeName,
+				Self:   other,
+			})
+		}
+	}
+	emit(&t.root, -1)
+	return p
+}
+
+// filterNodes removes the nodes which slot values are outside of the range
+// [minValue, maxValue], the same way model.Tree.FilterNodes does: the values
+// of the removed nodes, of all the slots, are accounted in the "other" child
+// of the parent node. If maxValue is zero, the range has no upper bound.
+func (t *multiValueTree) filterNodes(slot int, minValue, maxValue int64) {
+	t.root.filter(slot, t.width, minValue, maxValue)
+}
+
+// filter filters the children of the node, and returns the total of the
+// slot values of the subtree, and whether the node or any of its
+// descendants is within the range.
+func (n *multiValueNode) filter(slot, width int, minValue, maxValue int64) (int64, bool) {
+	var total int64
+	if slot < len(n.self) {
+		total = n.self[slot]
+	}
+	var found bool
+	var other []int64
+	for name, c := range n.children {
+		t, ok := c.filter(slot, width, minValue, maxValue)
+		total += t
+		if ok {
+			found = true
+			continue
+		}
+		if other == nil {
+			other = make([]int64, width)
+		}
+		c.sum(other)
+		delete(n.children, name)
+	}
+	if other != nil {
+		o := n.child(truncatedNodeName, width)
+		for i, v := range other {
+			o.self[i] += v
+		}
+	}
+	return total, found || (total >= minValue && (maxValue == 0 || total <= maxValue))
+}
+
+// sum adds the values of the subtree to dst.
+func (n *multiValueNode) sum(dst []int64) {
+	for i, v := range n.self {
+		dst[i] += v
+	}
+	for _, c := range n.children {
+		c.sum(dst)
+	}
+}
@@ -0,0 +1,201 @@
+package querybackend
+
+import (
+	"fmt"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+)
+
+// treeQueryOption is a tree query option that changes the way the tree
+// is built, and therefore can't be combined with some of the others.
+type treeQueryOption struct {
+	name string
+	set  func(*querybackendv1.TreeQuery) bool
+	// Options listed before this one that it can't be used along with.
+	conflicts []string
+}
+
+const (
+	treeOptionProfileTypes     = "profile types"
+	treeOptionValueExpression  = "value expression"
+	treeOptionValueCombination = "value combination"
+	treeOptionAttribution      = "attribution"
+	treeOptionRepresentative   = "representative profile"
+	treeOptionSampleCounts     = "sample counts"
+	treeOptionValueMerge       = "value merge function"
+	treeOptionDominantLabel    = "dominant label"
+	treeOptionGroupRootLabel   = "group root label"
+	treeOptionLabelBuckets     = "label buckets"
+)
+
+// treeQueryOptions is the compatibility table of the tree query options.
+// A new option is appended to the list along with the options it conflicts
+// with; the conflicts are symmetric and only need to be listed once.
+var treeQueryOptions = []treeQueryOption{
+	{
+		name: treeOptionProfileTypes,
+		set:  func(q *querybackendv1.TreeQuery) bool { return len(q.GetProfileTypes()) > 0 },
+	},
+	{
+		name:      treeOptionValueExpression,
+		set:       func(q *querybackendv1.TreeQuery) bool { return q.GetValueExpression() != "" },
+		conflicts: []string{treeOptionProfileTypes},
+	},
+	{
+		name:      treeOptionValueCombination,
+		set:       func(q *querybackendv1.TreeQuery) bool { return q.GetValueCombination() != nil },
+		conflicts: []string{treeOptionProfileTypes, treeOptionValueExpression},
+	},
+	{
+		name: treeOptionAttribution,
+		set:  func(q *querybackendv1.TreeQuery) bool { return q.GetAttribution() },
+	},
+	{
+		name: treeOptionRepresentative,
+		set:  func(q *querybackendv1.TreeQuery) bool { return q.GetRepresentative() },
+		conflicts: []string{
+			treeOptionProfileTypes,
+			treeOptionValueExpression,
+			treeOptionValueCombination,
+			treeOptionAttribution,
+		},
+	},
+	{
+		name: treeOptionSampleCounts,
+		set:  func(q *querybackendv1.TreeQuery) bool { return q.GetSampleCounts() },
+		conflicts: []string{
+			treeOptionProfileTypes,
+			treeOptionValueExpression,
+			treeOptionValueCombination,
+			treeOptionRepresentative,
+		},
+	},
+	{
+		name: treeOptionValueMerge,
+		set: func(q *querybackendv1.TreeQuery) bool {
+			// Sum is the default merge function.
+			m := q.GetValueMerge()
+			return m != "" && m != TreeValueMergeSum
+		},
+		conflicts: []string{
+			treeOptionProfileTypes,
+			treeOptionValueExpression,
+			treeOptionValueCombination,
+			treeOptionRepresentative,
+			treeOptionSampleCounts,
+		},
+	},
+	{
+		name:      "profile deduplication",
+		set:       func(q *querybackendv1.TreeQuery) bool { return q.GetDeduplicateProfiles() },
+		conflicts: []string{treeOptionProfileTypes, treeOptionRepresentative},
+	},
+	{
+		name:      "source locations",
+		set:       func(q *querybackendv1.TreeQuery) bool { return q.GetIncludeSourceLocations() },
+		conflicts: []string{treeOptionProfileTypes},
+	},
+	{
+		name:      "hottest path",
+		set:       func(q *querybackendv1.TreeQuery) bool { return q.GetHottestPath() },
+		conflicts: []string{treeOptionProfileTypes},
+	},
+	{
+		name:      "stack grouping",
+		set:       func(q *querybackendv1.TreeQuery) bool { return q.GetStackGrouping() != "" },
+		conflicts: []string{treeOptionProfileTypes},
+	},
+	{
+		name:      "goroutine states",
+		set:       func(q *querybackendv1.TreeQuery) bool { return q.GetGoroutineStates() },
+		conflicts: []string{treeOptionProfileTypes},
+	},
+	{
+		name:      "interval values",
+		set:       func(q *querybackendv1.TreeQuery) bool { return q.GetIntervalValues() },
+		conflicts: []string{treeOptionProfileTypes, treeOptionRepresentative},
+	},
+	{
+		name:      "legend",
+		set:       func(q *querybackendv1.TreeQuery) bool { return q.GetLegend() != nil },
+		conflicts: []string{treeOptionProfileTypes},
+	},
+	{
+		name: "min profile value",
+		set:  func(q *querybackendv1.TreeQuery) bool { return q.GetMinProfileValue() > 0 },
+		conflicts: []string{
+			treeOptionProfileTypes,
+			treeOptionValueExpression,
+			treeOptionValueCombination,
+			treeOptionRepresentative,
+			treeOptionSampleCounts,
+		},
+	},
+	{
+		name: treeOptionDominantLabel,
+		set:  func(q *querybackendv1.TreeQuery) bool { return q.GetDominantLabel() != "" },
+		conflicts: []string{
+			treeOptionProfileTypes,
+			treeOptionValueExpression,
+			treeOptionValueCombination,
+			treeOptionRepresentative,
+			treeOptionSampleCounts,
+			treeOptionValueMerge,
+		},
+	},
+	{
+		name: treeOptionGroupRootLabel,
+		set:  func(q *querybackendv1.TreeQuery) bool { return q.GetGroupRootLabel() != "" },
+		conflicts: []string{
+			treeOptionProfileTypes,
+			treeOptionValueExpression,
+			treeOptionValueCombination,
+			treeOptionRepresentative,
+			treeOptionSampleCounts,
+			treeOptionDominantLabel,
+		},
+	},
+	{
+		name: treeOptionLabelBuckets,
+		set:  func(q *querybackendv1.TreeQuery) bool { return q.GetLabelBuckets() != nil },
+		conflicts: []string{
+			treeOptionProfileTypes,
+			treeOptionValueExpression,
+			treeOptionValueCombination,
+			treeOptionRepresentative,
+			treeOptionSampleCounts,
+			treeOptionValueMerge,
+			treeOptionDominantLabel,
+			treeOptionGroupRootLabel,
+		},
+	},
+	{
+		name: "subtree",
+		set:  func(q *querybackendv1.TreeQuery) bool { return q.GetSubtreeNodeId() != 0 },
+		conflicts: []string{
+			treeOptionProfileTypes,
+			treeOptionRepresentative,
+			treeOptionDominantLabel,
+			treeOptionGroupRootLabel,
+			treeOptionLabelBuckets,
+		},
+	},
+}
+
+// validateTreeQueryOptions checks the tree query options against the
+// compatibility table.
+func validateTreeQueryOptions(q *querybackendv1.TreeQuery) error {
+	set := make(map[string]bool, len(treeQueryOptions))
+	for _, o := range treeQueryOptions {
+		if !o.set(q) {
+			continue
+		}
+		for _, c := range o.conflicts {
+			if set[c] {
+				return fmt.Errorf("%s can't be used along with %s", o.name, c)
+			}
+		}
+		set[o.name] = true
+	}
+	return nil
+}
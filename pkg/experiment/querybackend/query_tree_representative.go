@@ -0,0 +1,166 @@
+package querybackend
+
+import (
+	"bytes"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/grafana/dskit/runutil"
+	"github.com/prometheus/prometheus/model/relabel"
+
+	"github.com/grafana/pyroscope/pkg/iter"
+	"github.com/grafana/pyroscope/pkg/model"
+	parquetquery "github.com/grafana/pyroscope/pkg/phlaredb/query"
+	v1 "github.com/grafana/pyroscope/pkg/phlaredb/schemas/v1"
+	"github.com/grafana/pyroscope/pkg/phlaredb/symdb"
+)
+
+type profileTotal struct {
+	entry ProfileEntry
+	total int64
+}
+
+// resolveRepresentativeTree builds the tree of the profile with the
+// median total value among the profiles matching the query. The profile
+// totals are calculated in the first pass, that only reads the sample
+// values; the stack traces are only resolved for the chosen profile.
+func resolveRepresentativeTree(
+	q *queryContext,
+	rules []*relabel.Config,
+	opts ...symdb.ResolverOption,
+) (tree *model.Tree, unsymbolized float64, err error) {
+	totals, err := profileTotals(q, rules)
+	if err != nil || len(totals) == 0 {
+		return new(model.Tree), 0, err
+	}
+	median := medianProfileTotal(totals, func(p profileTotal) int64 { return p.total })
+	return resolveProfileTree(q, median.entry, opts...)
+}
+
+// resolveProfileTree builds the tree of the single profile.
+func resolveProfileTree(
+	q *queryContext,
+	entry ProfileEntry,
+	opts ...symdb.ResolverOption,
+) (tree *model.Tree, unsymbolized float64, err error) {
+	columns, err := q.sampleColumns(v1.SampleStacktraceColumns)
+	if err != nil {
+		return nil, 0, err
+	}
+	profiles := parquetquery.NewRepeatedRowIteratorWithPrefetch(q.ctx,
+		iter.NewSliceIterator([]ProfileEntry{entry}),
+		q.ds.Profiles().RowGroups(), q.ds.Profiles(), columns.Indices()...)
+	defer runutil.CloseWithErrCapture(&err, profiles, "failed to close profile stream")
+
+	resolver := q.newResolver(opts...)
+	defer func() {
+		start := time.Now()
+		resolver.Release()
+		q.observe(q.metrics.resolverReleaseDuration, time.Since(start).Seconds())
+	}()
+	for profiles.Next() {
+		p := profiles.At()
+		stacktraceIDs, values, err := q.validSamples(p.Values[0], p.Values[1])
+		if err != nil {
+			return nil, 0, err
+		}
+		resolver.AddSamplesFromParquetRow(p.Row.Partition, stacktraceIDs, values)
+	}
+	if err = profiles.Err(); err != nil {
+		return nil, 0, err
+	}
+	if tree, err = resolver.Tree(); err != nil {
+		return nil, 0, err
+	}
+	q.metrics.truncatedStacks.Add(float64(resolver.TruncatedStacks()))
+	return tree, resolver.UnsymbolizedFraction(), nil
+}
+
+// profileTotals sums the sample values of the profiles: the
+// stack trace IDs are not projected.
+func profileTotals(q *queryContext, rules []*relabel.Config) (totals []profileTotal, err error) {
+	columns, err := q.sampleColumns(v1.SampleValueColumn)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := profileEntryIterator(q, rules)
+	if err != nil {
+		return nil, err
+	}
+	defer runutil.CloseWithErrCapture(&err, entries, "failed to close profile entry iterator")
+	profiles := parquetquery.NewRepeatedRowIteratorWithPrefetch(q.ctx, entries,
+		q.ds.Profiles().RowGroups(), q.ds.Profiles(), columns.Indices()...)
+	defer runutil.CloseWithErrCapture(&err, profiles, "failed to close profile stream")
+	for profiles.Next() {
+		p := profiles.At()
+		var total int64
+		for _, v := range p.Values[0] {
+			if !invalidSampleValue(v) {
+				total += v.Int64()
+			}
+		}
+		totals = append(totals, profileTotal{entry: p.Row, total: total})
+	}
+	return totals, profiles.Err()
+}
+
+// medianProfileTotal returns the element with the median total value;
+// the lower median is chosen if the number of elements is even. Elements
+// with equal totals are ordered as given. The slice is sorted in place.
+func medianProfileTotal[T any](s []T, total func(T) int64) T {
+	slices.SortStableFunc(s, func(a, b T) int {
+		x, y := total(a), total(b)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	})
+	return s[(len(s)-1)/2]
+}
+
+// representativeTrees collects the representative trees of the partial
+// reports, and picks the one with the median total value. Trees with
+// the same total are ordered by the serialized form, so that the choice
+// does not depend on the order the reports are delivered in.
+type representativeTrees struct {
+	mu    sync.Mutex
+	trees []representativeTree
+}
+
+type representativeTree struct {
+	tree *model.Tree
+	b    []byte
+}
+
+func (r *representativeTrees) add(b []byte, version int) error {
+	if len(b) == 0 {
+		// No profiles matched the query.
+		return nil
+	}
+	t, err := model.UnmarshalTreeVersion(b, version)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.trees = append(r.trees, representativeTree{tree: t, b: b})
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *representativeTrees) tree() *model.Tree {
+	if len(r.trees) == 0 {
+		return new(model.Tree)
+	}
+	// Ties are broken by the serialized form, as
+	// the median is chosen with a stable sort.
+	slices.SortFunc(r.trees, func(a, b representativeTree) int {
+		return bytes.Compare(a.b, b.b)
+	})
+	return medianProfileTotal(r.trees, func(x representativeTree) int64 {
+		return x.tree.Total()
+	}).tree
+}
@@ -0,0 +1,112 @@
+package querybackend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend/block"
+	"github.com/grafana/pyroscope/pkg/model"
+	parquetquery "github.com/grafana/pyroscope/pkg/phlaredb/query"
+	v1 "github.com/grafana/pyroscope/pkg/phlaredb/schemas/v1"
+)
+
+func Test_MedianProfileTotal(t *testing.T) {
+	total := func(v int64) int64 { return v }
+	require.Equal(t, int64(3), medianProfileTotal([]int64{5, 3, 1}, total))
+	require.Equal(t, int64(2), medianProfileTotal([]int64{4, 1, 2, 3}, total))
+	require.Equal(t, int64(7), medianProfileTotal([]int64{7}, total))
+}
+
+func Test_TreeAggregator_Representative(t *testing.T) {
+	report := func(v int64) *querybackendv1.Report {
+		tree := new(model.Tree)
+		if v != 0 {
+			tree.InsertStack(v, "main", "f")
+		}
+		return &querybackendv1.Report{
+			ReportType: querybackendv1.ReportType_REPORT_TREE,
+			Tree: &querybackendv1.TreeReport{
+				Query: &querybackendv1.TreeQuery{Representative: true},
+				Tree:  tree.Bytes(-1),
+			},
+		}
+	}
+	a := newTreeAggregator(new(querybackendv1.InvokeRequest))
+	for _, v := range []int64{5, 0, 1, 3} {
+		// Empty trees of the blocks with no matching
+		// profiles do not participate in the selection.
+		require.NoError(t, a.aggregate(report(v)))
+	}
+	r := a.build()
+	require.Equal(t, int64(3), model.MustUnmarshalTree(r.Tree.Tree).Total())
+}
+
+func Test_QueryTree_Representative(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	queryTree := func(query *querybackendv1.TreeQuery) (*model.Tree, error) {
+		return queryTestTree(reader, blocks, `{service_name=~".+", __type__="cpu"}`, query)
+	}
+
+	full, err := queryTree(new(querybackendv1.TreeQuery))
+	require.NoError(t, err)
+	representative, err := queryTree(&querybackendv1.TreeQuery{Representative: true})
+	require.NoError(t, err)
+	require.Positive(t, representative.Total())
+	require.Less(t, representative.Total(), full.Total())
+
+	_, err = queryTree(&querybackendv1.TreeQuery{Representative: true, ValueExpression: "cpu"})
+	require.Error(t, err)
+	_, err = queryTree(&querybackendv1.TreeQuery{Representative: true, Attribution: true})
+	require.Error(t, err)
+}
+
+// Benchmark_SampleColumnsProjection measures the cost of reading the
+// samples of all the profiles, with and without the stack trace IDs.
+func Benchmark_SampleColumnsProjection(b *testing.B) {
+	reader, blocks := newTestBlockReader(b)
+	vr, err := validateRequest(newTestInvokeRequest(
+		blocks,
+		`{service_name=~".+"}`,
+		&querybackendv1.Query{QueryType: querybackendv1.QueryType_QUERY_TREE},
+	))
+	require.NoError(b, err)
+	var contexts []*queryContext
+	for _, md := range blocks {
+		for _, meta := range md.Datasets {
+			q := newQueryContext(context.Background(), reader.log, reader.metrics, meta, vr, block.NewObject(reader.storage, md))
+			require.NoError(b, q.ds.Open(q.ctx, block.SectionTSDB, block.SectionProfiles))
+			b.Cleanup(func() { _ = q.ds.Close() })
+			contexts = append(contexts, q)
+		}
+	}
+
+	for _, bc := range []struct {
+		name string
+		set  v1.SampleColumnSet
+	}{
+		{name: "values", set: v1.SampleValueColumn},
+		{name: "stacktraces", set: v1.SampleStacktraceColumns},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for _, q := range contexts {
+					columns, err := q.sampleColumns(bc.set)
+					require.NoError(b, err)
+					entries, err := profileEntryIterator(q, nil)
+					require.NoError(b, err)
+					profiles := parquetquery.NewRepeatedRowIterator(q.ctx, entries,
+						q.ds.Profiles().RowGroups(), columns.Indices()...)
+					for profiles.Next() {
+					}
+					require.NoError(b, profiles.Err())
+					require.NoError(b, profiles.Close())
+					require.NoError(b, entries.Close())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package querybackend
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+	"github.com/grafana/pyroscope/pkg/phlaredb/symdb"
+)
+
+// sampleCountType is the sample type of the sample counts,
+// e.g. "process_cpu:samples:count:cpu:nanoseconds".
+const sampleCountType = "samples"
+
+// multiValueTreeWidth returns the number of values
+// per node of the multi-value tree of the query.
+func multiValueTreeWidth(query *querybackendv1.TreeQuery) int {
+	if query.GetSampleCounts() {
+		return 2
+	}
+	if expr, err := treeValueExpression(query); err == nil && expr != nil {
+		// The operands of the value expression.
+		return len(expr.columns)
+	}
+	return len(query.GetProfileTypes())
+}
+
+// withSampleCountMatchers returns a copy of the query context that
+// selects the sample counts of the profiles matching the query: the
+// sample type matchers are replaced with the sample count type one,
+// and the profile names are limited to the ones of the profiles.
+func (q *queryContext) withSampleCountMatchers() (*queryContext, error) {
+	names, err := labelValuesForMatchers(q.ds.Index(), model.LabelNameProfileName, q.req.matchers)
+	if err != nil {
+		return nil, err
+	}
+	for i, name := range names {
+		names[i] = regexp.QuoteMeta(name)
+	}
+	r := *q.req
+	r.matchers = make([]*labels.Matcher, 0, len(q.req.matchers)+2)
+	for _, m := range q.req.matchers {
+		switch m.Name {
+		case model.LabelNameProfileType, model.LabelNameType, model.LabelNameUnit:
+		default:
+			r.matchers = append(r.matchers, m)
+		}
+	}
+	r.matchers = append(r.matchers,
+		labels.MustNewMatcher(labels.MatchRegexp, model.LabelNameProfileName, strings.Join(names, "|")),
+		labels.MustNewMatcher(labels.MatchEqual, model.LabelNameType, sampleCountType),
+	)
+	c := *q
+	c.req = &r
+	return &c, nil
+}
+
+// resolveSampleCounts builds the multi-value tree of the values of the
+// tree given, and the sample counts of the same profiles. The count tree
+// is transformed the same way the value tree is. The function reports
+// whether the sample counts are missing.
+func resolveSampleCounts(
+	q *queryContext,
+	query *querybackendv1.TreeQuery,
+	tree *model.Tree,
+	rules []*relabel.Config,
+	sanitize NameSanitizer,
+	opts []symdb.ResolverOption,
+) (*multiValueTree, float64, bool, error) {
+	c, err := q.withSampleCountMatchers()
+	if err != nil {
+		return nil, 0, false, err
+	}
+	counts, unsymbolized, err := resolveTree(c, rules, opts...)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	if sanitize != nil {
+		counts.FormatNodeNames(sanitize)
+	}
+	if query.GetInverted() {
+		counts = counts.Inverted()
+	}
+	if maxDepth := query.GetMaxDepth(); maxDepth > 0 {
+		counts.LimitDepth(int(maxDepth))
+	}
+	m := newMultiValueTree(2)
+	m.addTree(0, tree)
+	m.addTree(1, counts)
+	missing := tree.Size() > 0 && counts.Size() == 0
+	return m, unsymbolized, missing, nil
+}
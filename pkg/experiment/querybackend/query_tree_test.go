@@ -0,0 +1,1858 @@
+package querybackend
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+
+	metastorev1 "github.com/grafana/pyroscope/api/gen/proto/go/metastore/v1"
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend/block"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+func Test_TreeAggregator_Unsymbolized(t *testing.T) {
+	symbolized := new(model.Tree)
+	symbolized.InsertStack(1, "main", "foo")
+	unsymbolized := new(model.Tree)
+	unsymbolized.InsertStack(2, "0x4a1f20", "0x4a2b10")
+
+	a := newTreeAggregator(new(querybackendv1.InvokeRequest))
+	for _, r := range []*querybackendv1.TreeReport{
+		{Query: new(querybackendv1.TreeQuery), Tree: symbolized.Bytes(-1)},
+		{Query: new(querybackendv1.TreeQuery), Tree: unsymbolized.Bytes(-1), Unsymbolized: true},
+	} {
+		require.NoError(t, a.aggregate(&querybackendv1.Report{Tree: r}))
+	}
+
+	r := a.build().Tree
+	require.True(t, r.Unsymbolized)
+	expected := new(model.Tree)
+	expected.InsertStack(1, "main", "foo")
+	expected.InsertStack(2, "0x4a1f20", "0x4a2b10")
+	require.Equal(t, expected.String(), model.MustUnmarshalTree(r.Tree).String())
+}
+
+func Test_TreeAggregator_QueryMismatch(t *testing.T) {
+	tree := new(model.Tree)
+	tree.InsertStack(1, "main", "foo")
+	report := func(q *querybackendv1.TreeQuery) *querybackendv1.Report {
+		return &querybackendv1.Report{Tree: &querybackendv1.TreeReport{Query: q, Tree: tree.Bytes(-1)}}
+	}
+
+	a := newTreeAggregator(new(querybackendv1.InvokeRequest))
+	require.NoError(t, a.aggregate(report(&querybackendv1.TreeQuery{MaxNodes: 10})))
+	// Parameters that do not affect the result may differ.
+	require.NoError(t, a.aggregate(report(&querybackendv1.TreeQuery{MaxNodes: 10, Approximate: true})))
+	err := a.aggregate(report(&querybackendv1.TreeQuery{MaxNodes: 20}))
+	require.ErrorIs(t, err, errTreeQueryMismatch)
+	err = a.aggregate(report(&querybackendv1.TreeQuery{MaxNodes: 10, Inverted: true}))
+	require.ErrorIs(t, err, errTreeQueryMismatch)
+}
+
+// Benchmark_TreeAggregator_StringInterning measures the heap retained by
+// the aggregation of many reports with the same functions, and the time
+// it takes to aggregate them concurrently, with and without interning.
+func Benchmark_TreeAggregator_StringInterning(b *testing.B) {
+	const functions = 64
+	names := make([]string, functions)
+	for i := range names {
+		names[i] = fmt.Sprintf("github.com/grafana/pyroscope/pkg/library.function_%d", i)
+	}
+	reports := make([]*querybackendv1.Report, 512)
+	stack := make([]string, 8)
+	for i := range reports {
+		tree := new(model.Tree)
+		for j := 0; j < functions; j++ {
+			for k := range stack {
+				stack[k] = names[(i+j*k)%functions]
+			}
+			tree.InsertStack(int64(j+1), stack...)
+		}
+		reports[i] = &querybackendv1.Report{Tree: &querybackendv1.TreeReport{
+			Query: new(querybackendv1.TreeQuery),
+			Tree:  tree.Bytes(-1),
+		}}
+	}
+
+	for _, intern := range []bool{false, true} {
+		b.Run(fmt.Sprintf("interning=%v", intern), func(b *testing.B) {
+			b.ReportAllocs()
+			req := &querybackendv1.InvokeRequest{
+				Options: &querybackendv1.InvokeOptions{TreeStringInterning: intern},
+			}
+			var heap uint64
+			for i := 0; i < b.N; i++ {
+				a := newTreeAggregator(req)
+				var wg sync.WaitGroup
+				for w := 0; w < runtime.GOMAXPROCS(0); w++ {
+					wg.Add(1)
+					go func(w int) {
+						defer wg.Done()
+						for j := w; j < len(reports); j += runtime.GOMAXPROCS(0) {
+							_ = a.aggregate(reports[j])
+						}
+					}(w)
+				}
+				wg.Wait()
+				b.StopTimer()
+				runtime.GC()
+				var m runtime.MemStats
+				runtime.ReadMemStats(&m)
+				heap += m.HeapInuse
+				runtime.KeepAlive(a)
+				b.StartTimer()
+			}
+			b.ReportMetric(float64(heap)/float64(b.N), "heap-bytes/op")
+		})
+	}
+}
+
+func Test_TreeAggregator_DropZeroNodes(t *testing.T) {
+	report := func(v int64, stack ...string) *querybackendv1.Report {
+		tree := new(model.Tree)
+		tree.InsertStack(v, stack...)
+		return &querybackendv1.Report{Tree: &querybackendv1.TreeReport{
+			Query: &querybackendv1.TreeQuery{DropZeroNodes: true},
+			Tree:  tree.Bytes(-1),
+		}}
+	}
+
+	a := newTreeAggregator(new(querybackendv1.InvokeRequest))
+	require.NoError(t, a.aggregate(report(2, "main", "foo")))
+	require.NoError(t, a.aggregate(report(-2, "main", "foo")))
+	require.NoError(t, a.aggregate(report(-1, "main", "bar")))
+
+	actual, err := model.UnmarshalTree(a.build().Tree.Tree)
+	require.NoError(t, err)
+	expected := new(model.Tree)
+	expected.InsertStack(-1, "main", "bar")
+	require.Equal(t, expected.String(), actual.String())
+}
+
+func Test_TreeAggregator_MaxFunctions(t *testing.T) {
+	query := &querybackendv1.TreeQuery{MaxFunctions: 2}
+	report := func(tree *model.Tree) *querybackendv1.Report {
+		return &querybackendv1.Report{Tree: &querybackendv1.TreeReport{Query: query, Tree: tree.Bytes(-1)}}
+	}
+	t1 := new(model.Tree)
+	t1.InsertStack(3, "main", "a")
+	t1.InsertStack(2, "main", "b")
+	t2 := new(model.Tree)
+	t2.InsertStack(2, "main", "b")
+	t2.InsertStack(1, "main", "c")
+
+	a := newTreeAggregator(new(querybackendv1.InvokeRequest))
+	require.NoError(t, a.aggregate(report(t1)))
+	require.NoError(t, a.aggregate(report(t2)))
+
+	// The function "a" has the highest value in the first
+	// partial tree, but not in the merged one.
+	actual, err := model.UnmarshalTree(a.build().Tree.Tree)
+	require.NoError(t, err)
+	expected := new(model.Tree)
+	expected.InsertStack(4, "main", "b")
+	expected.InsertStack(4, "main", "other")
+	require.Equal(t, expected.String(), actual.String())
+}
+
+func Test_TreeAggregator_FormatVersion(t *testing.T) {
+	newPartial := func(version int, stack ...string) *querybackendv1.Report {
+		tree := new(model.Tree)
+		tree.InsertStack(1, stack...)
+		return &querybackendv1.Report{Tree: &querybackendv1.TreeReport{
+			Query:         &querybackendv1.TreeQuery{FormatVersion: 99},
+			Tree:          tree.BytesVersion(-1, version),
+			FormatVersion: uint32(version),
+		}}
+	}
+
+	// Partial reports may come from servers of different versions.
+	a := newTreeAggregator(new(querybackendv1.InvokeRequest))
+	v1 := newPartial(model.TreeFormatV1, "main", "foo")
+	v1.Tree.FormatVersion = 0
+	require.NoError(t, a.aggregate(v1))
+	require.NoError(t, a.aggregate(newPartial(model.TreeFormatV2, "main", "bar")))
+
+	r := a.build().Tree
+	require.EqualValues(t, model.TreeFormatLatest, r.FormatVersion)
+	actual, err := model.UnmarshalTreeVersion(r.Tree, int(r.FormatVersion))
+	require.NoError(t, err)
+	expected := new(model.Tree)
+	expected.InsertStack(1, "main", "foo")
+	expected.InsertStack(1, "main", "bar")
+	require.Equal(t, expected.String(), actual.String())
+}
+
+func Test_TreeAggregator_SymbolsFormatVersion(t *testing.T) {
+	tree := new(model.Tree)
+	tree.InsertStack(1, "main", "foo")
+	a := newTreeAggregator(new(querybackendv1.InvokeRequest))
+	for _, v := range []uint32{2, 3, 0} {
+		require.NoError(t, a.aggregate(&querybackendv1.Report{Tree: &querybackendv1.TreeReport{
+			Query:                new(querybackendv1.TreeQuery),
+			Tree:                 tree.Bytes(-1),
+			SymbolsFormatVersion: v,
+		}}))
+	}
+	require.EqualValues(t, 3, a.build().Tree.SymbolsFormatVersion)
+}
+
+func Test_TreeAggregator_HottestPath(t *testing.T) {
+	query := &querybackendv1.TreeQuery{HottestPath: true}
+	report := func(tree *model.Tree) *querybackendv1.Report {
+		return &querybackendv1.Report{Tree: &querybackendv1.TreeReport{Query: query, Tree: tree.Bytes(-1)}}
+	}
+	t1 := new(model.Tree)
+	t1.InsertStack(3, "main", "a")
+	t1.InsertStack(2, "main", "b")
+	t2 := new(model.Tree)
+	t2.InsertStack(2, "main", "b")
+	t2.InsertStack(1, "main", "c")
+
+	a := newTreeAggregator(new(querybackendv1.InvokeRequest))
+	require.NoError(t, a.aggregate(report(t1)))
+	require.NoError(t, a.aggregate(report(t2)))
+
+	// The function "a" is on the hottest path of the
+	// first partial tree, but not of the merged one.
+	actual, err := model.UnmarshalTree(a.build().Tree.Tree)
+	require.NoError(t, err)
+	expected := new(model.Tree)
+	expected.InsertStack(4, "main")
+	expected.InsertStack(4, "main", "b")
+	require.Equal(t, expected.String(), actual.String())
+}
+
+func Test_TreeAggregator_SampleTypes(t *testing.T) {
+	tree := new(model.Tree)
+	tree.InsertStack(1, "main", "foo")
+	aggregate := func(force bool) (*querybackendv1.Report, error) {
+		a := newTreeAggregator(new(querybackendv1.InvokeRequest))
+		for _, unit := range []string{"nanoseconds", "bytes"} {
+			err := a.aggregate(&querybackendv1.Report{Tree: &querybackendv1.TreeReport{
+				Query:       &querybackendv1.TreeQuery{ForceSampleTypeMerge: force},
+				Tree:        tree.Bytes(-1),
+				SampleTypes: []*querybackendv1.TreeSampleType{{Type: "cpu", Unit: unit}},
+			}})
+			if err != nil {
+				return nil, err
+			}
+		}
+		return a.build(), nil
+	}
+
+	_, err := aggregate(false)
+	require.ErrorIs(t, err, errSampleTypeUnitMismatch)
+
+	r, err := aggregate(true)
+	require.NoError(t, err)
+	require.Equal(t, []*querybackendv1.TreeSampleType{
+		{Type: "cpu", Unit: "bytes"},
+		{Type: "cpu", Unit: "nanoseconds"},
+	}, r.Tree.SampleTypes)
+}
+
+func Test_QueryTree_SampleTypes(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	resp, err := reader.Invoke(context.Background(), newTestInvokeRequest(
+		blocks,
+		`{service_name=~".+", __type__="cpu"}`,
+		&querybackendv1.Query{
+			QueryType: querybackendv1.QueryType_QUERY_TREE,
+			Tree:      new(querybackendv1.TreeQuery),
+		},
+	))
+	require.NoError(t, err)
+	require.Len(t, resp.Reports, 1)
+	require.Equal(t, []*querybackendv1.TreeSampleType{
+		{Type: "cpu", Unit: "nanoseconds"},
+	}, resp.Reports[0].Tree.SampleTypes)
+}
+
+func Test_TreeFormatVersion(t *testing.T) {
+	for v, expected := range map[uint32]int{
+		0:  model.TreeFormatV1,
+		1:  model.TreeFormatV1,
+		2:  model.TreeFormatV2,
+		99: model.TreeFormatLatest,
+	} {
+		q := &querybackendv1.TreeQuery{FormatVersion: v}
+		require.Equal(t, expected, treeFormatVersion(q), "client version %d", v)
+	}
+}
+
+func Test_TreeAggregator_ValueRange(t *testing.T) {
+	query := &querybackendv1.TreeQuery{MinValue: 5, MaxValue: 50}
+	newPartial := func() *querybackendv1.Report {
+		tree := new(model.Tree)
+		tree.InsertStack(30, "main", "hot")
+		tree.InsertStack(3, "main", "medium")
+		tree.InsertStack(1, "main", "cold")
+		return &querybackendv1.Report{Tree: &querybackendv1.TreeReport{
+			Query: query,
+			Tree:  tree.Bytes(-1),
+		}}
+	}
+
+	// The "medium" node is below the lower bound in each of the
+	// partial reports, but is within the range after aggregation.
+	a := newTreeAggregator(new(querybackendv1.InvokeRequest))
+	require.NoError(t, a.aggregate(newPartial()))
+	require.NoError(t, a.aggregate(newPartial()))
+	expected := new(model.Tree)
+	expected.InsertStack(60, "main", "other")
+	expected.InsertStack(6, "main", "medium")
+	expected.InsertStack(2, "main", "other")
+	require.Equal(t, expected.String(), model.MustUnmarshalTree(a.build().Tree.Tree).String())
+}
+
+func Test_TreeAggregator_MaxValue(t *testing.T) {
+	query := &querybackendv1.TreeQuery{MaxValue: 50}
+	newPartial := func(tree *model.Tree) *querybackendv1.Report {
+		return &querybackendv1.Report{Tree: &querybackendv1.TreeReport{
+			Query: query,
+			Tree:  tree.Bytes(-1),
+		}}
+	}
+	// The node "X" exceeds the bound in the first partial
+	// only, where none of its descendants are in the range.
+	a := new(model.Tree)
+	a.InsertStack(40, "X")
+	a.InsertStack(60, "X", "Y")
+	b := new(model.Tree)
+	b.InsertStack(5, "X", "Z")
+
+	// The trees of the shards are aggregated by the sub-queries.
+	shard := func(tree *model.Tree) *querybackendv1.Report {
+		agg := newTreeAggregator(&querybackendv1.InvokeRequest{
+			Options: &querybackendv1.InvokeOptions{Partial: true},
+		})
+		require.NoError(t, agg.aggregate(newPartial(tree)))
+		return agg.build()
+	}
+	agg := newTreeAggregator(new(querybackendv1.InvokeRequest))
+	require.NoError(t, agg.aggregate(shard(a)))
+	require.NoError(t, agg.aggregate(shard(b)))
+	actual := model.MustUnmarshalTree(agg.build().Tree.Tree)
+
+	// The partial trees are not filtered: the result is
+	// the same as if the merged tree was filtered.
+	expected := new(model.Tree)
+	expected.InsertStack(40, "X")
+	expected.InsertStack(60, "X", "other")
+	expected.InsertStack(5, "X", "Z")
+	require.Equal(t, expected.String(), actual.String())
+
+	// With negative values, e.g. of delta profiles, the merged
+	// values may be lower than the ones of a partial tree.
+	a = new(model.Tree)
+	a.InsertStack(100, "X", "Y")
+	b = new(model.Tree)
+	b.InsertStack(-60, "X", "Y")
+	agg = newTreeAggregator(new(querybackendv1.InvokeRequest))
+	require.NoError(t, agg.aggregate(shard(a)))
+	require.NoError(t, agg.aggregate(shard(b)))
+	expected = new(model.Tree)
+	expected.InsertStack(40, "X", "Y")
+	require.Equal(t, expected.String(), model.MustUnmarshalTree(agg.build().Tree.Tree).String())
+}
+
+func Test_TreeAggregator_MinValuePercent(t *testing.T) {
+	newPartial := func(query *querybackendv1.TreeQuery) *querybackendv1.Report {
+		tree := new(model.Tree)
+		tree.InsertStack(90, "main", "hot")
+		tree.InsertStack(6, "main", "medium")
+		tree.InsertStack(4, "main", "cold")
+		return &querybackendv1.Report{Tree: &querybackendv1.TreeReport{
+			Query: query,
+			Tree:  tree.Bytes(-1),
+		}}
+	}
+	build := func(query *querybackendv1.TreeQuery) *model.Tree {
+		a := newTreeAggregator(new(querybackendv1.InvokeRequest))
+		require.NoError(t, a.aggregate(newPartial(query)))
+		require.NoError(t, a.aggregate(newPartial(query)))
+		return model.MustUnmarshalTree(a.build().Tree.Tree)
+	}
+
+	// The bound is relative to the merged tree total: 5% of 200.
+	expected := new(model.Tree)
+	expected.InsertStack(180, "main", "hot")
+	expected.InsertStack(12, "main", "medium")
+	expected.InsertStack(8, "main", "other")
+	require.Equal(t, expected.String(), build(&querybackendv1.TreeQuery{MinValuePercent: 5}).String())
+	// The higher bound applies.
+	expected = new(model.Tree)
+	expected.InsertStack(180, "main", "hot")
+	expected.InsertStack(20, "main", "other")
+	require.Equal(t, expected.String(), build(&querybackendv1.TreeQuery{MinValuePercent: 5, MinValue: 13}).String())
+
+	// No bound: the tree is intact.
+	expected = model.MustUnmarshalTree(newPartial(nil).Tree.Tree)
+	expected.Merge(model.MustUnmarshalTree(newPartial(nil).Tree.Tree))
+	require.Equal(t, expected.String(), build(new(querybackendv1.TreeQuery)).String())
+
+	// The tree total is kept, even if all the nodes are below the bound.
+	tree := build(&querybackendv1.TreeQuery{MinValuePercent: 100, MinValue: 1000})
+	require.Equal(t, int64(200), tree.Total())
+}
+
+func Test_ValidateTreeValueRange(t *testing.T) {
+	for _, q := range []*querybackendv1.TreeQuery{
+		{MinValue: -1},
+		{MaxValue: -1},
+		{MinValue: 10, MaxValue: 5},
+		{MinValuePercent: -1},
+		{MinValuePercent: 101},
+		{MinValuePercent: math.NaN()},
+	} {
+		require.Error(t, validateTreeValueRange(q))
+	}
+	require.NoError(t, validateTreeValueRange(&querybackendv1.TreeQuery{MinValue: 10}))
+	require.NoError(t, validateTreeValueRange(&querybackendv1.TreeQuery{MinValue: 5, MaxValue: 5}))
+}
+
+func Test_TreeAggregator_Attribution(t *testing.T) {
+	newReport := func(source string, stacks ...[]string) *querybackendv1.Report {
+		tree := new(model.Tree)
+		for _, s := range stacks {
+			tree.InsertStack(1, s...)
+		}
+		a := newTreeAttribution()
+		a.addTree(source, tree)
+		return &querybackendv1.Report{Tree: &querybackendv1.TreeReport{
+			Query:       &querybackendv1.TreeQuery{Attribution: true},
+			Tree:        tree.Bytes(-1),
+			Attribution: a.proto(tree),
+		}}
+	}
+
+	a := newTreeAggregator(new(querybackendv1.InvokeRequest))
+	require.NoError(t, a.aggregate(newReport("block-a", []string{"main", "foo"})))
+	require.NoError(t, a.aggregate(newReport("block-b", []string{"main", "foo"}, []string{"main", "bar"})))
+	r := a.build().Tree
+	require.NotNil(t, r.Attribution)
+
+	actual := make(map[string][]string)
+	paths := make([]string, len(r.Attribution.Nodes))
+	for i, n := range r.Attribution.Nodes {
+		paths[i] = n.Name
+		if n.Parent >= 0 {
+			paths[i] = paths[n.Parent] + ";" + n.Name
+		}
+		sources := make([]string, len(n.Sources))
+		for j, s := range n.Sources {
+			sources[j] = r.Attribution.Sources[s]
+		}
+		actual[paths[i]] = sources
+	}
+	require.Equal(t, map[string][]string{
+		"main":     {"block-a", "block-b"},
+		"main;foo": {"block-a", "block-b"},
+		"main;bar": {"block-b"},
+	}, actual)
+}
+
+func Test_TreeAggregator_NoAttribution(t *testing.T) {
+	tree := new(model.Tree)
+	tree.InsertStack(1, "main", "foo")
+	a := newTreeAggregator(new(querybackendv1.InvokeRequest))
+	for i := 0; i < 2; i++ {
+		require.NoError(t, a.aggregate(&querybackendv1.Report{Tree: &querybackendv1.TreeReport{
+			Query: new(querybackendv1.TreeQuery),
+			Tree:  tree.Bytes(-1),
+		}}))
+	}
+	require.Nil(t, a.build().Tree.Attribution)
+}
+
+func Test_QueryTree_Relabel(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	queryTree := func(selector string, rules ...*querybackendv1.RelabelRule) *model.Tree {
+		tree, err := queryTestTree(reader, blocks, selector, &querybackendv1.TreeQuery{Relabel: rules})
+		require.NoError(t, err)
+		return tree
+	}
+
+	const (
+		selector = `{service_name=~".+"}`
+		excluded = "pyroscope-test/ingester"
+	)
+	all := queryTree(selector)
+	expected := queryTree(`{service_name!="` + excluded + `"}`)
+	require.Greater(t, all.Total(), expected.Total())
+
+	actual := queryTree(selector,
+		// Rename service_name to service.
+		&querybackendv1.RelabelRule{SourceLabels: []string{"service_name"}, TargetLabel: "service"},
+		&querybackendv1.RelabelRule{Regex: "service_name", Action: "labeldrop"},
+		// Drop series by the new label.
+		&querybackendv1.RelabelRule{SourceLabels: []string{"service"}, Regex: excluded, Action: "drop"},
+		// The label does not exist anymore: nothing is dropped.
+		&querybackendv1.RelabelRule{SourceLabels: []string{"service_name"}, Regex: ".+", Action: "drop"},
+	)
+	require.Equal(t, expected.String(), actual.String())
+}
+
+func Test_QueryTree_ResolverReleaseDuration(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	_, err := reader.Invoke(context.Background(), newTestInvokeRequest(
+		blocks,
+		`{service_name=~".+"}`,
+		&querybackendv1.Query{
+			QueryType: querybackendv1.QueryType_QUERY_TREE,
+			Tree:      new(querybackendv1.TreeQuery),
+		},
+	))
+	require.NoError(t, err)
+
+	var m dto.Metric
+	require.NoError(t, reader.metrics.resolverReleaseDuration.Write(&m))
+	var datasets uint64
+	for _, b := range blocks {
+		datasets += uint64(len(b.Datasets))
+	}
+	require.Equal(t, datasets, m.Histogram.GetSampleCount())
+}
+
+func Test_QueryTree_ResolveDuration(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	_, err := reader.Invoke(context.Background(), newTestInvokeRequest(
+		blocks,
+		`{service_name=~".+"}`,
+		&querybackendv1.Query{
+			QueryType: querybackendv1.QueryType_QUERY_TREE,
+			Tree:      new(querybackendv1.TreeQuery),
+		},
+	))
+	require.NoError(t, err)
+
+	var datasets uint64
+	for _, b := range blocks {
+		datasets += uint64(len(b.Datasets))
+	}
+	for _, h := range []*prometheus.HistogramVec{reader.metrics.resolveIODuration, reader.metrics.resolveCPUDuration} {
+		var m dto.Metric
+		o, err := h.GetMetricWithLabelValues(querybackendv1.QueryType_QUERY_TREE.String())
+		require.NoError(t, err)
+		require.NoError(t, o.(prometheus.Histogram).Write(&m))
+		require.Equal(t, datasets, m.Histogram.GetSampleCount())
+		require.Positive(t, m.Histogram.GetSampleSum())
+	}
+}
+
+func Test_QueryTree_Inverted(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	queryTree := func(query *querybackendv1.TreeQuery) *model.Tree {
+		tree, err := queryTestTree(reader, blocks, `{service_name=~".+"}`, query)
+		require.NoError(t, err)
+		return tree
+	}
+
+	tree := queryTree(new(querybackendv1.TreeQuery))
+	inverted := queryTree(&querybackendv1.TreeQuery{Inverted: true})
+	require.Equal(t, tree.Inverted().String(), inverted.String())
+
+	// Truncation applies to the inverted tree.
+	const maxNodes = 16
+	truncated := queryTree(&querybackendv1.TreeQuery{Inverted: true, MaxNodes: maxNodes})
+	require.Equal(t, inverted.Total(), truncated.Total())
+	require.Less(t, len(truncated.Bytes(-1)), len(inverted.Bytes(-1)))
+}
+
+func Test_QueryTree_MaxDepth(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	queryTree := func(query *querybackendv1.TreeQuery) *model.Tree {
+		tree, err := queryTestTree(reader, blocks, `{service_name=~".+"}`, query)
+		require.NoError(t, err)
+		return tree
+	}
+
+	const maxDepth = 3
+	tree := queryTree(new(querybackendv1.TreeQuery))
+	limited := queryTree(&querybackendv1.TreeQuery{MaxDepth: maxDepth})
+	tree.LimitDepth(maxDepth)
+	require.Equal(t, tree.String(), limited.String())
+	limited.IterateStacks(func(_ string, _ int64, stack []string) {
+		require.LessOrEqual(t, len(stack), maxDepth)
+	})
+}
+
+func Test_QueryTree_Subtree(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	queryTree := func(query *querybackendv1.TreeQuery) *querybackendv1.TreeReport {
+		report, err := invokeTestTree(reader, blocks, `{service_name=~".+", __type__="cpu"}`, query)
+		require.NoError(t, err)
+		return report
+	}
+
+	// The node IDs are listed in the order of serialization, depth-first:
+	// the third node is a child of the root node serialized first.
+	r := queryTree(&querybackendv1.TreeQuery{NodeIds: true})
+	require.Greater(t, len(r.NodeIds), 2)
+	id := r.NodeIds[2]
+	tree := model.MustUnmarshalTree(r.Tree)
+	require.Equal(t, 2, tree.Subtree(id))
+	tree.LimitDepth(3)
+
+	// The max depth is counted from the node.
+	r = queryTree(&querybackendv1.TreeQuery{SubtreeNodeId: id, MaxDepth: 2, NodeIds: true})
+	require.Equal(t, tree.String(), model.MustUnmarshalTree(r.Tree).String())
+	require.Contains(t, r.NodeIds, id)
+
+	r = queryTree(&querybackendv1.TreeQuery{SubtreeNodeId: 42})
+	require.Zero(t, model.MustUnmarshalTree(r.Tree).Total())
+}
+
+func Test_QueryTree_MaxResolveDepth(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	queryTree := func() *model.Tree {
+		tree, err := queryTestTree(reader, blocks, `{service_name=~".+"}`, new(querybackendv1.TreeQuery))
+		require.NoError(t, err)
+		return tree
+	}
+
+	tree := queryTree()
+	require.Zero(t, testutil.ToFloat64(reader.metrics.truncatedStacks))
+	reader.maxResolveDepth = 2
+	truncated := queryTree()
+	require.Positive(t, testutil.ToFloat64(reader.metrics.truncatedStacks))
+	require.Equal(t, tree.Total(), truncated.Total())
+	require.Less(t, truncated.Size(), tree.Size())
+}
+
+func Test_QueryTree_Approximate(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	queryTree := func(query *querybackendv1.TreeQuery) (*model.Tree, error) {
+		return queryTestTree(reader, blocks, `{service_name=~".+"}`, query)
+	}
+
+	exact, err := queryTree(&querybackendv1.TreeQuery{MaxNodes: 64})
+	require.NoError(t, err)
+	approximate, err := queryTree(&querybackendv1.TreeQuery{MaxNodes: 64, Approximate: true})
+	require.NoError(t, err)
+	require.Equal(t, exact.Total(), approximate.Total())
+
+	_, err = queryTree(&querybackendv1.TreeQuery{MaxNodes: 64, Approximate: true, ApproximationError: 1})
+	require.ErrorContains(t, err, "approximation error")
+}
+
+func Test_RelabelConfigs_Invalid(t *testing.T) {
+	for _, rule := range []*querybackendv1.RelabelRule{
+		{Action: "hashmod", TargetLabel: "x"},
+		{Action: "unknown"},
+		{Regex: "(", TargetLabel: "x"},
+		{SourceLabels: []string{"x"}},
+	} {
+		_, err := relabelConfigs([]*querybackendv1.RelabelRule{rule})
+		require.Error(t, err, rule.String())
+	}
+}
+
+func Test_QueryTree_MultiValue(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	invoke := func(selector string, query *querybackendv1.Query) *querybackendv1.Report {
+		resp, err := reader.Invoke(context.Background(), newTestInvokeRequest(blocks, selector, query))
+		require.NoError(t, err)
+		require.Len(t, resp.Reports, 1)
+		return resp.Reports[0]
+	}
+
+	const selector = `{service_name=~".+"}`
+	profileTypes := invoke(selector, &querybackendv1.Query{
+		QueryType:   querybackendv1.QueryType_QUERY_LABEL_VALUES,
+		LabelValues: &querybackendv1.LabelValuesQuery{LabelName: model.LabelNameProfileType},
+	}).LabelValues.LabelValues
+	require.GreaterOrEqual(t, len(profileTypes), 2)
+	// The profile type is not present in the data.
+	profileTypes = []string{profileTypes[0], "absent:absent:count:absent:count", profileTypes[1]}
+
+	r := invoke(selector, &querybackendv1.Query{
+		QueryType: querybackendv1.QueryType_QUERY_TREE,
+		Tree:      &querybackendv1.TreeQuery{ProfileTypes: profileTypes},
+	}).Tree
+	require.NotNil(t, r.MultiValueTree)
+
+	for i, profileType := range profileTypes {
+		expected := model.MustUnmarshalTree(invoke(
+			`{service_name=~".+", __profile_type__="`+profileType+`"}`,
+			&querybackendv1.Query{
+				QueryType: querybackendv1.QueryType_QUERY_TREE,
+				Tree:      new(querybackendv1.TreeQuery),
+			}).Tree.Tree)
+		actual := multiValueTreeSlot(r.MultiValueTree, i)
+		require.Equal(t, expected.String(), actual.String(), profileType)
+	}
+}
+
+func Test_MultiValueTree_Truncation(t *testing.T) {
+	a := new(model.Tree)
+	a.InsertStack(10, "main", "foo")
+	a.InsertStack(1, "main", "bar")
+	b := new(model.Tree)
+	b.InsertStack(5, "main", "foo")
+	b.InsertStack(1, "main", "baz")
+
+	tree := newMultiValueTree(2)
+	tree.addTree(0, a)
+	tree.addTree(1, b)
+	p := tree.proto(2)
+
+	expected := new(model.Tree)
+	expected.InsertStack(10, "main", "foo")
+	expected.InsertStack(1, "main", "other")
+	require.Equal(t, expected.String(), multiValueTreeSlot(p, 0).String())
+	expected = new(model.Tree)
+	expected.InsertStack(5, "main", "foo")
+	expected.InsertStack(1, "main", "other")
+	require.Equal(t, expected.String(), multiValueTreeSlot(p, 1).String())
+}
+
+func Test_MultiValueTree_TruncationTies(t *testing.T) {
+	a := new(model.Tree)
+	a.InsertStack(1, "main", "foo")
+	a.InsertStack(1, "main", "bar")
+	a.InsertStack(1, "main", "baz")
+
+	tree := newMultiValueTree(1)
+	tree.addTree(0, a)
+	p := tree.proto(3)
+	// "main", and "bar" and "baz" which precede "foo" by name.
+	// The "other" node is not counted.
+	require.Len(t, p.Nodes, 4)
+
+	expected := new(model.Tree)
+	expected.InsertStack(1, "main", "bar")
+	expected.InsertStack(1, "main", "baz")
+	expected.InsertStack(1, "main", "other")
+	require.Equal(t, expected.String(), multiValueTreeSlot(p, 0).String())
+}
+
+func Test_MultiValueTree_FilterNodes(t *testing.T) {
+	values := new(model.Tree)
+	values.InsertStack(100, "main", "foo")
+	values.InsertStack(10, "main", "bar")
+	values.InsertStack(5, "main", "bar", "baz")
+	counts := new(model.Tree)
+	counts.InsertStack(1, "main", "foo")
+	counts.InsertStack(2, "main", "bar")
+	counts.InsertStack(3, "main", "bar", "baz")
+
+	tree := newMultiValueTree(2)
+	tree.addTree(0, values)
+	tree.addTree(1, counts)
+	tree.filterNodes(0, 0, 50)
+	p := tree.proto(0)
+
+	// The nodes are removed by the values of the first slot:
+	// the value tree is filtered the same way.
+	values.FilterNodes(0, 50)
+	require.Equal(t, values.String(), multiValueTreeSlot(p, 0).String())
+	expected := new(model.Tree)
+	expected.InsertStack(1, "main", "other")
+	expected.InsertStack(2, "main", "bar")
+	expected.InsertStack(3, "main", "bar", "baz")
+	require.Equal(t, expected.String(), multiValueTreeSlot(p, 1).String())
+}
+
+func multiValueTreeSlot(p *querybackendv1.MultiValueTree, slot int) *model.Tree {
+	tree := new(model.Tree)
+	stacks := make([][]string, len(p.Nodes))
+	for i, n := range p.Nodes {
+		if n.Parent >= 0 {
+			stacks[i] = append(stacks[i], stacks[n.Parent]...)
+		}
+		stacks[i] = append(stacks[i], n.Name)
+		tree.InsertStack(n.Self[slot], stacks[i]...)
+	}
+	return tree
+}
+
+func Test_QueryTree_Shallow(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	queryTree := func(query *querybackendv1.TreeQuery) (*model.Tree, error) {
+		return queryTestTree(reader, blocks, `{service_name=~".+", __type__="cpu"}`, query)
+	}
+
+	full, err := queryTree(new(querybackendv1.TreeQuery))
+	require.NoError(t, err)
+	require.Positive(t, full.Total())
+
+	shallow, err := queryTree(&querybackendv1.TreeQuery{Shallow: true})
+	require.NoError(t, err)
+	require.Equal(t, full.Total(), shallow.Total())
+	full.LimitDepth(2)
+	require.Equal(t, full.String(), shallow.String())
+
+	entryPoint, err := queryTree(&querybackendv1.TreeQuery{Shallow: true, ShallowEntryPoint: "^runtime\\."})
+	require.NoError(t, err)
+	require.Equal(t, full.Total(), entryPoint.Total())
+
+	_, err = queryTree(&querybackendv1.TreeQuery{Shallow: true, ShallowEntryPoint: "("})
+	require.ErrorContains(t, err, "invalid shallow entry point")
+	_, err = queryTree(&querybackendv1.TreeQuery{ShallowEntryPoint: "main"})
+	require.Error(t, err)
+}
+
+func Test_QueryContext_ValidSamples(t *testing.T) {
+	values := func(v ...int64) []parquet.Value {
+		s := make([]parquet.Value, len(v))
+		for i, x := range v {
+			s[i] = parquet.Int64Value(x)
+		}
+		return s
+	}
+
+	m := newMetrics(nil)
+	q := &queryContext{
+		metrics: m,
+		meta:    &metastorev1.Dataset{Name: "dataset"},
+		obj:     block.NewObject(nil, &metastorev1.BlockMeta{Id: "block"}),
+	}
+	ids, vs := values(1, 2, 3), values(10, 20, 30)
+	validIDs, validValues, err := q.validSamples(ids, vs)
+	require.NoError(t, err)
+	require.Equal(t, ids, validIDs)
+	require.Equal(t, vs, validValues)
+
+	// Negative values are valid.
+	ids, vs = values(1, 2, 3), values(-10, math.MaxInt64, -math.MaxInt64)
+	validIDs, validValues, err = q.validSamples(ids, vs)
+	require.NoError(t, err)
+	require.Equal(t, ids, validIDs)
+	require.Equal(t, vs, validValues)
+
+	ids, vs = values(1, 2, 3, 4), values(10, math.MinInt64, -30, 40)
+	vs[3] = parquet.NullValue()
+	validIDs, validValues, err = q.validSamples(ids, vs)
+	require.NoError(t, err)
+	require.Equal(t, values(1, 3), validIDs)
+	require.Equal(t, values(10, -30), validValues)
+	require.Equal(t, float64(2), testutil.ToFloat64(m.invalidSampleValues))
+	// The input is not modified.
+	require.Equal(t, values(1, 2, 3, 4), ids)
+
+	q.failOnInvalidSampleValues = true
+	_, _, err = q.validSamples(ids, vs)
+	require.ErrorIs(t, err, errInvalidSampleValue)
+	require.ErrorContains(t, err, "block")
+}
+
+func Test_TreeAggregator_TotalOverflow(t *testing.T) {
+	report := func(v int64) *querybackendv1.Report {
+		tree := new(model.Tree)
+		tree.InsertStack(v, "main")
+		return &querybackendv1.Report{
+			ReportType: querybackendv1.ReportType_REPORT_TREE,
+			Tree: &querybackendv1.TreeReport{
+				Query: &querybackendv1.TreeQuery{},
+				Tree:  tree.Bytes(-1),
+			},
+		}
+	}
+	a := newTreeAggregator(new(querybackendv1.InvokeRequest))
+	require.NoError(t, a.aggregate(report(math.MaxInt64)))
+	require.ErrorIs(t, a.aggregate(report(1)), model.ErrTreeTotalOverflow)
+}
+
+func Test_QueryTree_Partitions(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	req := newTestInvokeRequest(
+		blocks,
+		`{service_name=~".+", __type__="cpu"}`,
+		&querybackendv1.Query{QueryType: querybackendv1.QueryType_QUERY_TREE},
+	)
+	queryTree := func(partitions ...uint64) *model.Tree {
+		req.Query[0].Tree = &querybackendv1.TreeQuery{Partitions: partitions}
+		resp, err := reader.Invoke(context.Background(), req)
+		require.NoError(t, err)
+		require.Len(t, resp.Reports, 1)
+		return model.MustUnmarshalTree(resp.Reports[0].Tree.Tree)
+	}
+
+	// Collect the partitions of the matching profiles.
+	vr, err := validateRequest(req)
+	require.NoError(t, err)
+	partitions := make(map[uint64]struct{})
+	for _, md := range blocks {
+		for _, meta := range md.Datasets {
+			c := newQueryContext(context.Background(), reader.log, reader.metrics, meta, vr, block.NewObject(reader.storage, md))
+			require.NoError(t, c.ds.Open(c.ctx, block.SectionTSDB, block.SectionProfiles))
+			entries, err := profileEntryIterator(c, nil)
+			require.NoError(t, err)
+			for entries.Next() {
+				partitions[entries.At().Partition] = struct{}{}
+			}
+			require.NoError(t, entries.Close())
+			require.NoError(t, c.ds.Close())
+		}
+	}
+	require.NotEmpty(t, partitions)
+
+	full := queryTree()
+	require.Positive(t, full.Total())
+	var total int64
+	for p := range partitions {
+		tree := queryTree(p)
+		require.Positive(t, tree.Total())
+		total += tree.Total()
+	}
+	require.Equal(t, full.Total(), total)
+	require.Zero(t, queryTree(math.MaxUint32).Total())
+}
+
+func Test_TreeAggregator_NodeCount(t *testing.T) {
+	report := func(maxNodes int64, stacks ...[]string) *querybackendv1.Report {
+		tree := new(model.Tree)
+		for _, s := range stacks {
+			tree.InsertStack(1, s...)
+		}
+		return &querybackendv1.Report{
+			ReportType: querybackendv1.ReportType_REPORT_TREE,
+			Tree: &querybackendv1.TreeReport{
+				Query: &querybackendv1.TreeQuery{MaxNodes: maxNodes},
+				Tree:  tree.Bytes(-1),
+			},
+		}
+	}
+	for _, tc := range []struct {
+		maxNodes  int64
+		truncated bool
+	}{
+		{maxNodes: 0},
+		{maxNodes: 4},
+		{maxNodes: 3, truncated: true},
+	} {
+		a := newTreeAggregator(new(querybackendv1.InvokeRequest))
+		require.NoError(t, a.aggregate(report(tc.maxNodes, []string{"a", "b"})))
+		require.NoError(t, a.aggregate(report(tc.maxNodes, []string{"a", "c"}, []string{"d"})))
+		r := a.build()
+		require.Equal(t, int64(4), r.Tree.Nodes)
+		require.Equal(t, tc.truncated, r.Tree.Truncated, tc.maxNodes)
+	}
+}
+
+func Test_QueryTree_SampleCounts(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	invoke := func(selector string, query *querybackendv1.TreeQuery) *querybackendv1.TreeReport {
+		report, err := invokeTestTree(reader, blocks, selector, query)
+		require.NoError(t, err)
+		return report
+	}
+
+	r := invoke(`{service_name=~".+", __type__="cpu"}`, &querybackendv1.TreeQuery{SampleCounts: true})
+	require.False(t, r.SampleCountsMissing)
+	require.NotNil(t, r.MultiValueTree)
+	values := model.MustUnmarshalTree(r.Tree)
+	require.Equal(t, values.String(), multiValueTreeSlot(r.MultiValueTree, 0).String())
+	counts := invoke(`{service_name=~".+", __type__="samples"}`, new(querybackendv1.TreeQuery))
+	require.Equal(t, model.MustUnmarshalTree(counts.Tree).String(), multiValueTreeSlot(r.MultiValueTree, 1).String())
+	require.Positive(t, multiValueTreeSlot(r.MultiValueTree, 1).Total())
+
+	// Memory profiles have no sample counts.
+	r = invoke(`{service_name=~".+", __type__="inuse_space"}`, &querybackendv1.TreeQuery{SampleCounts: true})
+	require.True(t, r.SampleCountsMissing)
+	require.Zero(t, multiValueTreeSlot(r.MultiValueTree, 1).Total())
+	require.Positive(t, multiValueTreeSlot(r.MultiValueTree, 0).Total())
+
+	_, err := reader.Invoke(context.Background(), newTestInvokeRequest(
+		blocks,
+		`{service_name=~".+"}`,
+		&querybackendv1.Query{
+			QueryType: querybackendv1.QueryType_QUERY_TREE,
+			Tree:      &querybackendv1.TreeQuery{SampleCounts: true, ProfileTypes: []string{"a"}},
+		},
+	))
+	require.Error(t, err)
+}
+
+func Test_QueryTree_SampleCountsMaxNodes(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	// The partial trees of a cold paths query are not truncated,
+	// and neither are the sample counts.
+	query := &querybackendv1.Query{
+		QueryType: querybackendv1.QueryType_QUERY_TREE,
+		Tree: &querybackendv1.TreeQuery{
+			SampleCounts: true,
+			MaxNodes:     2,
+			Baseline:     &querybackendv1.TreeBaseline{Tree: new(model.Tree).Bytes(-1), ColdPaths: true},
+		},
+	}
+	vr, err := validateRequest(newTestInvokeRequest(blocks, `{service_name=~".+", __type__="cpu"}`, query))
+	require.NoError(t, err)
+	for _, md := range blocks {
+		for _, meta := range md.Datasets {
+			q := newQueryContext(context.Background(), reader.log, reader.metrics, meta, vr, block.NewObject(reader.storage, md))
+			r, err := executeQuery(q, query)
+			require.NoError(t, err)
+			values := model.MustUnmarshalTree(r.Tree.Tree)
+			// The channels of the multi-value tree have the nodes of the value tree.
+			require.Equal(t, values.String(), multiValueTreeSlot(r.Tree.MultiValueTree, 0).String())
+		}
+	}
+}
+
+func Test_TreeAggregator_SampleCountsMissing(t *testing.T) {
+	query := &querybackendv1.TreeQuery{SampleCounts: true}
+	report := func(missing bool) *querybackendv1.Report {
+		a := new(model.Tree)
+		a.InsertStack(10, "main", "foo")
+		b := new(model.Tree)
+		if !missing {
+			b.InsertStack(1, "main", "foo")
+		}
+		m := newMultiValueTree(2)
+		m.addTree(0, a)
+		m.addTree(1, b)
+		return &querybackendv1.Report{
+			ReportType: querybackendv1.ReportType_REPORT_TREE,
+			Tree: &querybackendv1.TreeReport{
+				Query:               query,
+				Tree:                a.Bytes(-1),
+				MultiValueTree:      m.proto(0),
+				SampleCountsMissing: missing,
+			},
+		}
+	}
+	a := newTreeAggregator(new(querybackendv1.InvokeRequest))
+	require.NoError(t, a.aggregate(report(false)))
+	require.NoError(t, a.aggregate(report(true)))
+	r := a.build()
+	require.True(t, r.Tree.SampleCountsMissing)
+	require.Equal(t, int64(20), multiValueTreeSlot(r.Tree.MultiValueTree, 0).Total())
+	require.Equal(t, int64(1), multiValueTreeSlot(r.Tree.MultiValueTree, 1).Total())
+}
+
+func Test_TreeAggregator_NodeIDs(t *testing.T) {
+	query := &querybackendv1.TreeQuery{NodeIds: true}
+	report := func(stacks ...[]string) *querybackendv1.Report {
+		tree := new(model.Tree)
+		for _, s := range stacks {
+			tree.InsertStack(1, s...)
+		}
+		b := tree.Bytes(-1)
+		ids, err := model.TreeNodeIDs(b, model.TreeFormatV1)
+		require.NoError(t, err)
+		return &querybackendv1.Report{
+			ReportType: querybackendv1.ReportType_REPORT_TREE,
+			Tree:       &querybackendv1.TreeReport{Query: query, Tree: b, NodeIds: ids},
+		}
+	}
+	a := report([]string{"a", "b"}, []string{"c"})
+	b := report([]string{"a", "d"}, []string{"c", "a"})
+	agg := newTreeAggregator(new(querybackendv1.InvokeRequest))
+	require.NoError(t, agg.aggregate(a))
+	require.NoError(t, agg.aggregate(b))
+	r := agg.build()
+	require.Len(t, r.Tree.NodeIds, 6)
+	// The nodes of the partial trees have the same IDs in the merged one.
+	require.Subset(t, r.Tree.NodeIds, a.Tree.NodeIds)
+	require.Subset(t, r.Tree.NodeIds, b.Tree.NodeIds)
+}
+
+func Test_TreeAggregator_Baseline(t *testing.T) {
+	report := func(v int64, stacks ...[]string) *querybackendv1.Report {
+		tree := new(model.Tree)
+		for _, s := range stacks {
+			tree.InsertStack(v, s...)
+		}
+		return &querybackendv1.Report{
+			ReportType: querybackendv1.ReportType_REPORT_TREE,
+			Tree: &querybackendv1.TreeReport{
+				Query: new(querybackendv1.TreeQuery),
+				Tree:  tree.Bytes(-1),
+			},
+		}
+	}
+	baseline := new(model.Tree)
+	baseline.InsertStack(10, "a", "b")
+	baseline.InsertStack(10, "c")
+	req := &querybackendv1.InvokeRequest{
+		Query: []*querybackendv1.Query{{
+			QueryType: querybackendv1.QueryType_QUERY_TREE,
+			Tree: &querybackendv1.TreeQuery{
+				Baseline: &querybackendv1.TreeBaseline{Tree: baseline.Bytes(-1), MinDelta: 2},
+			},
+		}},
+	}
+
+	agg := newTreeAggregator(req)
+	require.NoError(t, agg.aggregate(report(5, []string{"a", "b"}, []string{"c"})))
+	require.NoError(t, agg.aggregate(report(6, []string{"a", "b"}, []string{"d"})))
+	tree, err := model.UnmarshalTree(agg.build().Tree.Tree)
+	require.NoError(t, err)
+	// a;b has changed by 1, which is less than the minimal delta.
+	expected := `.
+├── c: self -5 total -5
+└── d: self 6 total 6
+`
+	require.Equal(t, expected, tree.String())
+
+	// The sub-queries are built without the baseline.
+	sub := req.CloneVT()
+	stripTreeBaseline(sub)
+	require.Nil(t, sub.Query[0].Tree.Baseline)
+	require.Nil(t, treeBaseline(sub))
+
+	req.Query[0].Tree.Baseline.Tree = []byte("invalid")
+	agg = newTreeAggregator(req)
+	require.ErrorIs(t, agg.aggregate(report(1, []string{"a"})), errInvalidTreeBaseline)
+}
+
+func Test_TreeAggregator_ColdPaths(t *testing.T) {
+	baseline := new(model.Tree)
+	baseline.InsertStack(10, "a", "b")
+	baseline.InsertStack(10, "c")
+	baseline.InsertStack(10, "d")
+	req := &querybackendv1.InvokeRequest{
+		Query: []*querybackendv1.Query{{
+			QueryType: querybackendv1.QueryType_QUERY_TREE,
+			Tree: &querybackendv1.TreeQuery{
+				MaxNodes: 2,
+				Baseline: &querybackendv1.TreeBaseline{Tree: baseline.Bytes(-1), ColdPaths: true},
+			},
+		}},
+	}
+
+	// The partial trees of the sub-queries are not truncated.
+	sub := req.CloneVT()
+	stripTreeBaseline(sub)
+	require.Nil(t, sub.Query[0].Tree.Baseline)
+	require.Zero(t, sub.Query[0].Tree.MaxNodes)
+
+	report := func(stacks ...[]string) *querybackendv1.Report {
+		tree := new(model.Tree)
+		for _, s := range stacks {
+			tree.InsertStack(10, s...)
+		}
+		return &querybackendv1.Report{
+			ReportType: querybackendv1.ReportType_REPORT_TREE,
+			Tree: &querybackendv1.TreeReport{
+				Query: sub.Query[0].Tree,
+				Tree:  tree.Bytes(-1),
+			},
+		}
+	}
+	agg := newTreeAggregator(req)
+	require.NoError(t, agg.aggregate(report([]string{"a", "b"})))
+	require.NoError(t, agg.aggregate(report([]string{"c"}, []string{"e"})))
+	r := agg.build().Tree
+	tree, err := model.UnmarshalTree(r.Tree)
+	require.NoError(t, err)
+	// Paths absent in any of the partial trees
+	// are only cold if absent in the merged one.
+	expected := `.
+└── d: self 10 total 10
+`
+	require.Equal(t, expected, tree.String())
+
+	// The reports of the leaf queries of the request are not
+	// truncated either: the baseline is only removed from the
+	// sub-queries.
+	require.Zero(t, partialTreeMaxNodes(req.Query[0].Tree))
+	leaf := report([]string{"a", "b"})
+	leaf.Tree.Query = req.Query[0].Tree
+	require.NoError(t, newTreeAggregator(req).aggregate(leaf))
+
+	// The final tree is truncated to the max nodes of the query.
+	baseline = new(model.Tree)
+	for i, name := range []string{"a", "b", "c", "d"} {
+		baseline.InsertStack(int64(10*(i+1)), name)
+	}
+	req.Query[0].Tree.Baseline.Tree = baseline.Bytes(-1)
+	agg = newTreeAggregator(req)
+	require.NoError(t, agg.aggregate(report([]string{"e"})))
+	r = agg.build().Tree
+	require.EqualValues(t, 2, r.Query.MaxNodes)
+	require.EqualValues(t, 4, r.Nodes)
+	require.True(t, r.Truncated)
+	tree, err = model.UnmarshalTree(r.Tree)
+	require.NoError(t, err)
+	expected = `.
+├── c: self 30 total 30
+├── d: self 40 total 40
+└── other: self 30 total 30
+`
+	require.Equal(t, expected, tree.String())
+}
+
+func Test_QueryTree_DeduplicateProfiles(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	queryTree := func(blocks []*metastorev1.BlockMeta, query *querybackendv1.TreeQuery) (*model.Tree, error) {
+		return queryTestTree(reader, blocks, `{service_name=~".+"}`, query)
+	}
+
+	expected, err := queryTree(blocks, new(querybackendv1.TreeQuery))
+	require.NoError(t, err)
+	// The first block is read twice, as if it overlapped with itself.
+	overlapping := append(slices.Clone(blocks), blocks[0].CloneVT())
+	inflated, err := queryTree(overlapping, new(querybackendv1.TreeQuery))
+	require.NoError(t, err)
+	require.Greater(t, inflated.Total(), expected.Total())
+	require.Zero(t, testutil.ToFloat64(reader.metrics.deduplicatedProfiles))
+
+	deduplicated, err := queryTree(overlapping, &querybackendv1.TreeQuery{DeduplicateProfiles: true})
+	require.NoError(t, err)
+	require.Equal(t, expected.String(), deduplicated.String())
+	require.Positive(t, testutil.ToFloat64(reader.metrics.deduplicatedProfiles))
+
+	_, err = queryTree(blocks, &querybackendv1.TreeQuery{DeduplicateProfiles: true, Representative: true})
+	require.Error(t, err)
+}
+
+func Test_QueryTree_ValueMerge(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	queryTree := func(query *querybackendv1.TreeQuery) (*model.Tree, error) {
+		return queryTestTree(reader, blocks, `{service_name=~".+", __type__="cpu"}`, query)
+	}
+
+	sum, err := queryTree(new(querybackendv1.TreeQuery))
+	require.NoError(t, err)
+	explicit, err := queryTree(&querybackendv1.TreeQuery{ValueMerge: TreeValueMergeSum})
+	require.NoError(t, err)
+	require.Equal(t, sum.String(), explicit.String())
+
+	maxTree, err := queryTree(&querybackendv1.TreeQuery{ValueMerge: TreeValueMergeMax})
+	require.NoError(t, err)
+	minTree, err := queryTree(&querybackendv1.TreeQuery{ValueMerge: TreeValueMergeMin})
+	require.NoError(t, err)
+	require.Less(t, maxTree.Total(), sum.Total())
+	require.Less(t, minTree.Total(), maxTree.Total())
+	sumSelf := make(map[string]int64)
+	sum.IterateStacks(func(name string, self int64, stack []string) {
+		sumSelf[strings.Join(stack, ";")] = self
+	})
+	maxTree.IterateStacks(func(name string, self int64, stack []string) {
+		require.LessOrEqual(t, self, sumSelf[strings.Join(stack, ";")])
+	})
+
+	_, err = queryTree(&querybackendv1.TreeQuery{ValueMerge: "unknown"})
+	require.Error(t, err)
+	_, err = queryTree(&querybackendv1.TreeQuery{ValueMerge: TreeValueMergeMax, Representative: true})
+	require.Error(t, err)
+}
+
+func Test_TreeAggregator_ValueMerge(t *testing.T) {
+	query := &querybackendv1.TreeQuery{ValueMerge: TreeValueMergeMax}
+	report := func(v int64, stacks ...[]string) *querybackendv1.Report {
+		tree := new(model.Tree)
+		for _, s := range stacks {
+			tree.InsertStack(v, s...)
+		}
+		return &querybackendv1.Report{
+			ReportType: querybackendv1.ReportType_REPORT_TREE,
+			Tree:       &querybackendv1.TreeReport{Query: query, Tree: tree.Bytes(-1)},
+		}
+	}
+	agg := newTreeAggregator(new(querybackendv1.InvokeRequest))
+	require.NoError(t, agg.aggregate(report(5, []string{"a", "b"}, []string{"c"})))
+	require.NoError(t, agg.aggregate(report(3, []string{"a", "b"}, []string{"d"})))
+	require.NoError(t, agg.aggregate(report(7, []string{"c"})))
+	tree := model.MustUnmarshalTree(agg.build().Tree.Tree)
+	expected := `.
+├── a: self 0 total 5
+│   └── b: self 5 total 5
+├── c: self 7 total 7
+└── d: self 3 total 3
+`
+	require.Equal(t, expected, tree.String())
+
+	agg = newTreeAggregator(new(querybackendv1.InvokeRequest))
+	r := report(1, []string{"a"})
+	r.Tree.Query = &querybackendv1.TreeQuery{ValueMerge: "unknown"}
+	require.Error(t, agg.aggregate(r))
+}
+
+func Test_QueryTree_SymbolsOverLimit(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	queryTree := func(query *querybackendv1.TreeQuery) (*querybackendv1.TreeReport, error) {
+		return invokeTestTree(reader, blocks, `{service_name=~".+", __type__="cpu"}`, query)
+	}
+
+	resolved, err := queryTree(new(querybackendv1.TreeQuery))
+	require.NoError(t, err)
+	require.False(t, resolved.SymbolsOverLimit)
+	expected := model.MustUnmarshalTree(resolved.Tree)
+
+	reader.maxSymbolsSectionSize = 1
+	addresses, err := queryTree(new(querybackendv1.TreeQuery))
+	require.NoError(t, err)
+	require.True(t, addresses.SymbolsOverLimit)
+	require.True(t, addresses.Unsymbolized)
+	tree := model.MustUnmarshalTree(addresses.Tree)
+	require.Equal(t, expected.Total(), tree.Total())
+	require.Greater(t, tree.Size(), int64(1))
+	tree.IterateStacks(func(name string, _ int64, _ []string) {
+		require.True(t, strings.HasPrefix(name, "0x"), name)
+	})
+	require.Positive(t, testutil.ToFloat64(reader.metrics.symbolsOverLimit))
+
+	// The samples are processed as usual.
+	maxMerged, err := queryTree(&querybackendv1.TreeQuery{ValueMerge: TreeValueMergeMax})
+	require.NoError(t, err)
+	require.Less(t, model.MustUnmarshalTree(maxMerged.Tree).Total(), tree.Total())
+	_, err = queryTree(&querybackendv1.TreeQuery{ValueExpression: "cpu"})
+	require.NoError(t, err)
+}
+
+func Test_QueryTree_SourceLocations(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	resp, err := reader.Invoke(context.Background(), newTestInvokeRequest(
+		blocks,
+		`{service_name=~".+", __type__="cpu"}`,
+		&querybackendv1.Query{
+			QueryType: querybackendv1.QueryType_QUERY_TREE,
+			Tree:      &querybackendv1.TreeQuery{MaxNodes: 16, IncludeSourceLocations: true},
+		},
+	))
+	require.NoError(t, err)
+	require.Len(t, resp.Reports, 1)
+	r := resp.Reports[0].Tree
+	require.NotNil(t, r.SourceLocations)
+	require.NotEmpty(t, r.SourceLocations.Nodes)
+	var known int
+	for _, n := range r.SourceLocations.Nodes {
+		if n.File != "" {
+			known++
+		}
+	}
+	require.Positive(t, known)
+}
+
+func Test_TreeAggregator_SourceLocations(t *testing.T) {
+	newReport := func(locations map[string]sourceLocation, stacks ...[]string) *querybackendv1.Report {
+		tree := new(model.Tree)
+		for _, s := range stacks {
+			tree.InsertStack(1, s...)
+		}
+		s := newTreeSourceLocations()
+		s.addTree(tree, locations)
+		return &querybackendv1.Report{Tree: &querybackendv1.TreeReport{
+			Query:           &querybackendv1.TreeQuery{IncludeSourceLocations: true},
+			Tree:            tree.Bytes(-1),
+			SourceLocations: s.proto(tree),
+		}}
+	}
+
+	a := newTreeAggregator(new(querybackendv1.InvokeRequest))
+	require.NoError(t, a.aggregate(newReport(map[string]sourceLocation{
+		"main": {file: "main.go", line: 10},
+		"foo":  {file: "foo/b.go", line: 3},
+	}, []string{"main", "foo"})))
+	require.NoError(t, a.aggregate(newReport(map[string]sourceLocation{
+		"main": {file: "main.go", line: 10},
+		"foo":  {file: "foo/a.go", line: 5},
+	}, []string{"main", "foo"}, []string{"main", "bar"})))
+	r := a.build().Tree
+	require.NotNil(t, r.SourceLocations)
+
+	actual := make(map[string]string)
+	paths := make([]string, len(r.SourceLocations.Nodes))
+	for i, n := range r.SourceLocations.Nodes {
+		paths[i] = n.Name
+		if n.Parent >= 0 {
+			paths[i] = paths[n.Parent] + ";" + n.Name
+		}
+		actual[paths[i]] = fmt.Sprintf("%s:%d", n.File, n.Line)
+	}
+	require.Equal(t, map[string]string{
+		"main":     "main.go:10",
+		"main;foo": "foo/a.go:5",
+		"main;bar": ":0",
+	}, actual)
+}
+
+func Test_TreeAggregator_ReportOrder(t *testing.T) {
+	newReport := func(source string, query *querybackendv1.TreeQuery, stacks ...[]string) *querybackendv1.Report {
+		tree := new(model.Tree)
+		for i, s := range stacks {
+			tree.InsertStack(int64(i+1), s...)
+		}
+		a := newTreeAttribution()
+		a.addTree(source, tree)
+		r := &querybackendv1.Report{Tree: &querybackendv1.TreeReport{
+			Query:        query.CloneVT(),
+			Tree:         tree.Bytes(-1),
+			Unsymbolized: source == "block-c",
+		}}
+		if query.Attribution {
+			r.Tree.Attribution = a.proto(tree)
+		}
+		return r
+	}
+
+	for _, tc := range []struct {
+		name  string
+		query *querybackendv1.TreeQuery
+	}{
+		{name: "merge", query: &querybackendv1.TreeQuery{MaxNodes: 3, Attribution: true}},
+		{name: "representative", query: &querybackendv1.TreeQuery{Representative: true}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			reports := []*querybackendv1.Report{
+				newReport("block-a", tc.query, []string{"main", "foo"}),
+				newReport("block-b", tc.query, []string{"main", "bar"}),
+				newReport("block-c", tc.query, []string{"main", "foo"}, []string{"main", "baz"}),
+				newReport("block-d", tc.query, []string{"main", "qux"}, []string{"main", "foo", "bar"}),
+				newReport("block-e", tc.query, []string{"main", "baz"}),
+			}
+			// The reports are built with different approximation parameters.
+			for i, r := range reports {
+				r.Tree.Query.Seed = uint64(i)
+			}
+			req := &querybackendv1.InvokeRequest{
+				Query: []*querybackendv1.Query{{QueryType: querybackendv1.QueryType_QUERY_TREE, Tree: tc.query}},
+			}
+			var expected *querybackendv1.Report
+			rnd := rand.New(rand.NewSource(1))
+			for i := 0; i < 20; i++ {
+				rnd.Shuffle(len(reports), func(i, j int) { reports[i], reports[j] = reports[j], reports[i] })
+				a := newTreeAggregator(req)
+				for _, r := range reports {
+					require.NoError(t, a.aggregate(r))
+				}
+				actual := a.build()
+				if expected == nil {
+					expected = actual
+					continue
+				}
+				require.True(t, expected.EqualVT(actual), "expected %v, got %v", expected, actual)
+			}
+			require.Equal(t, tc.query.MaxNodes, expected.Tree.Query.MaxNodes)
+			require.Zero(t, expected.Tree.Query.Seed)
+		})
+	}
+}
+
+func Test_TreeAggregator_MaxReportSize(t *testing.T) {
+	tree := new(model.Tree)
+	for i := 0; i < 100; i++ {
+		tree.InsertStack(int64(i+1), "main", fmt.Sprintf("function_%d", i))
+	}
+	full := tree.Bytes(-1)
+	build := func(maxSize int64) *querybackendv1.TreeReport {
+		a := newTreeAggregator(&querybackendv1.InvokeRequest{
+			Options: &querybackendv1.InvokeOptions{MaxTreeReportSize: maxSize},
+		})
+		require.NoError(t, a.aggregate(&querybackendv1.Report{Tree: &querybackendv1.TreeReport{
+			Query: &querybackendv1.TreeQuery{MaxNodes: 1000},
+			Tree:  full,
+		}}))
+		return a.build().Tree
+	}
+
+	r := build(int64(len(full)))
+	require.False(t, r.SizeCapped)
+	require.False(t, r.Truncated)
+	require.Equal(t, full, r.Tree)
+
+	maxSize := int64(len(full) / 3)
+	r = build(maxSize)
+	require.True(t, r.SizeCapped)
+	require.True(t, r.Truncated)
+	require.LessOrEqual(t, int64(len(r.Tree)), maxSize)
+	require.Less(t, model.MustUnmarshalTree(r.Tree).Size(), tree.Size())
+
+	m := newMetrics(nil)
+	m.observeTreeReportCapping(&querybackendv1.InvokeResponse{Reports: []*querybackendv1.Report{
+		{Tree: r},
+		{Tree: &querybackendv1.TreeReport{Truncated: true}},
+		{Tree: new(querybackendv1.TreeReport)},
+		{LabelNames: new(querybackendv1.LabelNamesReport)},
+	}})
+	require.Equal(t, float64(1), testutil.ToFloat64(m.treeReportsCapped.WithLabelValues("size")))
+	require.Equal(t, float64(1), testutil.ToFloat64(m.treeReportsCapped.WithLabelValues("nodes")))
+}
+
+func Test_QueryTree_MinProfileValue(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	query := func(tree *querybackendv1.TreeQuery) *querybackendv1.TreeReport {
+		report, err := invokeTestTree(reader, blocks, `{service_name=~".+", __type__="cpu"}`, tree)
+		require.NoError(t, err)
+		return report
+	}
+	total := func(r *querybackendv1.TreeReport) int64 {
+		tree, err := model.UnmarshalTree(r.Tree)
+		require.NoError(t, err)
+		return tree.Total()
+	}
+
+	all := query(&querybackendv1.TreeQuery{MaxNodes: -1})
+	require.False(t, all.ProfilesBelowMinValue)
+
+	r := query(&querybackendv1.TreeQuery{MaxNodes: -1, MinProfileValue: 1})
+	require.False(t, r.ProfilesBelowMinValue)
+	require.Equal(t, total(all), total(r))
+
+	r = query(&querybackendv1.TreeQuery{MaxNodes: -1, MinProfileValue: math.MaxInt64})
+	require.True(t, r.ProfilesBelowMinValue)
+	require.Zero(t, total(r))
+
+	_, err := reader.Invoke(context.Background(), newTestInvokeRequest(
+		blocks,
+		`{service_name=~".+", __type__="cpu"}`,
+		&querybackendv1.Query{
+			QueryType: querybackendv1.QueryType_QUERY_TREE,
+			Tree:      &querybackendv1.TreeQuery{MinProfileValue: -1},
+		},
+	))
+	require.Error(t, err)
+}
+
+func Test_QueryTree_DominantLabel(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	query := func(tree *querybackendv1.TreeQuery) *querybackendv1.TreeReport {
+		report, err := invokeTestTree(reader, blocks, `{service_name=~".+", __type__="cpu"}`, tree)
+		require.NoError(t, err)
+		return report
+	}
+
+	expected := query(&querybackendv1.TreeQuery{MaxNodes: -1})
+	r := query(&querybackendv1.TreeQuery{MaxNodes: -1, DominantLabel: "service_name"})
+	require.Equal(t, expected.Tree, r.Tree)
+	require.NotNil(t, r.LabelValues)
+	require.Contains(t, r.LabelValues.Values, "pyroscope-test/ingester")
+	require.NotEmpty(t, r.LabelValues.Nodes)
+
+	tree, err := model.UnmarshalTree(r.Tree)
+	require.NoError(t, err)
+	var rootTotal int64
+	for _, n := range r.LabelValues.Nodes {
+		require.Contains(t, n.Values, n.Dominant)
+		if n.Parent < 0 {
+			for _, v := range n.Totals {
+				rootTotal += v
+			}
+		}
+	}
+	require.Equal(t, tree.Total(), rootTotal)
+}
+
+func Test_QueryTree_GroupRootLabel(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	query := func(selector string, query *querybackendv1.TreeQuery) *model.Tree {
+		tree, err := queryTestTree(reader, blocks, selector, query)
+		require.NoError(t, err)
+		return tree
+	}
+
+	const selector = `{service_name=~".+", __type__="cpu"}`
+	expected := query(selector, &querybackendv1.TreeQuery{MaxNodes: -1})
+	grouped := query(selector, &querybackendv1.TreeQuery{MaxNodes: -1, GroupRootLabel: "service_name"})
+	require.Equal(t, expected.Total(), grouped.Total())
+
+	roots := make(map[string]int64)
+	grouped.IterateStacks(func(_ string, self int64, stack []string) {
+		roots[stack[len(stack)-1]] += self
+	})
+	require.Contains(t, roots, "service_name=pyroscope-test/ingester")
+	for root, total := range roots {
+		service, ok := strings.CutPrefix(root, "service_name=")
+		require.True(t, ok, root)
+		group := query(fmt.Sprintf(`{service_name=%q, __type__="cpu"}`, service), &querybackendv1.TreeQuery{MaxNodes: -1})
+		require.Equal(t, group.Total(), total, root)
+	}
+
+	_, err := reader.Invoke(context.Background(), newTestInvokeRequest(
+		blocks,
+		selector,
+		&querybackendv1.Query{
+			QueryType: querybackendv1.QueryType_QUERY_TREE,
+			Tree:      &querybackendv1.TreeQuery{GroupRootLabel: "service_name", DominantLabel: "service_name"},
+		},
+	))
+	require.ErrorContains(t, err, "group root label")
+}
+
+func Test_QueryTree_LabelBuckets(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	query := func(selector string, tree *querybackendv1.TreeQuery) *querybackendv1.TreeReport {
+		report, err := invokeTestTree(reader, blocks, selector, tree)
+		require.NoError(t, err)
+		return report
+	}
+
+	// Only the profiles of the ingester have the numeric label.
+	const service = "pyroscope-test/ingester"
+	rules := []*querybackendv1.RelabelRule{{
+		SourceLabels: []string{"service_name"},
+		Regex:        service,
+		TargetLabel:  "size",
+		Replacement:  "100",
+	}}
+	const selector = `{service_name=~".+", __type__="cpu"}`
+	expected := query(selector, &querybackendv1.TreeQuery{MaxNodes: -1, Relabel: rules})
+	r := query(selector, &querybackendv1.TreeQuery{
+		MaxNodes:     -1,
+		Relabel:      rules,
+		LabelBuckets: &querybackendv1.TreeLabelBuckets{Label: "size", Bounds: []float64{10, 1000}},
+	})
+	require.Equal(t, model.MustUnmarshalTree(expected.Tree).String(), model.MustUnmarshalTree(r.Tree).String())
+	require.Len(t, r.Buckets, 3)
+	require.Empty(t, r.Buckets[0].Tree)
+	require.Empty(t, r.Buckets[2].Tree)
+	ingester := query(fmt.Sprintf(`{service_name=%q, __type__="cpu"}`, service), &querybackendv1.TreeQuery{MaxNodes: -1})
+	require.Equal(t, model.MustUnmarshalTree(ingester.Tree).String(), model.MustUnmarshalTree(r.Buckets[1].Tree).String())
+
+	for _, b := range []*querybackendv1.TreeLabelBuckets{
+		{Bounds: []float64{1}},
+		{Label: "size"},
+		{Label: "size", Bounds: []float64{2, 1}},
+		{Label: "size", Bounds: []float64{math.NaN()}},
+	} {
+		_, err := reader.Invoke(context.Background(), newTestInvokeRequest(
+			blocks,
+			selector,
+			&querybackendv1.Query{
+				QueryType: querybackendv1.QueryType_QUERY_TREE,
+				Tree:      &querybackendv1.TreeQuery{LabelBuckets: b},
+			},
+		))
+		require.ErrorContains(t, err, "label buckets", b.String())
+	}
+}
+
+func Test_LabelBucket(t *testing.T) {
+	bounds := []float64{10, 100}
+	for _, tc := range []struct {
+		value  string
+		bucket int
+		ok     bool
+	}{
+		{value: "-1", bucket: 0, ok: true},
+		{value: "10", bucket: 0, ok: true},
+		{value: "10.5", bucket: 1, ok: true},
+		{value: "100", bucket: 1, ok: true},
+		{value: "1e3", bucket: 2, ok: true},
+		{value: ""},
+		{value: "NaN"},
+		{value: "large"},
+	} {
+		bucket, ok := labelBucket(bounds, tc.value)
+		require.Equal(t, tc.ok, ok, tc.value)
+		require.Equal(t, tc.bucket, bucket, tc.value)
+	}
+}
+
+func Test_TreeAggregator_LabelBuckets(t *testing.T) {
+	newReport := func(stacks ...string) *querybackendv1.Report {
+		r := &querybackendv1.TreeReport{
+			Query: &querybackendv1.TreeQuery{LabelBuckets: &querybackendv1.TreeLabelBuckets{Label: "size", Bounds: []float64{1}}},
+		}
+		tree := new(model.Tree)
+		for _, s := range stacks {
+			b := new(model.Tree)
+			if s != "" {
+				b.InsertStack(1, "root", s)
+			}
+			tree.InsertStack(b.Total(), "root", s)
+			r.Buckets = append(r.Buckets, &querybackendv1.TreeBucket{Tree: b.Bytes(-1)})
+		}
+		r.Tree = tree.Bytes(-1)
+		return &querybackendv1.Report{Tree: r}
+	}
+
+	a := newTreeAggregator(new(querybackendv1.InvokeRequest))
+	require.NoError(t, a.aggregate(newReport("a", "")))
+	require.NoError(t, a.aggregate(newReport("a", "b")))
+	r := a.build().Tree
+	require.Len(t, r.Buckets, 2)
+	expected := new(model.Tree)
+	expected.InsertStack(2, "root", "a")
+	require.Equal(t, expected.String(), model.MustUnmarshalTree(r.Buckets[0].Tree).String())
+	expected = new(model.Tree)
+	expected.InsertStack(1, "root", "b")
+	require.Equal(t, expected.String(), model.MustUnmarshalTree(r.Buckets[1].Tree).String())
+}
+
+func Test_TreeAggregator_DominantLabel(t *testing.T) {
+	newReport := func(values map[string]int64) *querybackendv1.Report {
+		l := newTreeLabelValues()
+		tree := new(model.Tree)
+		for value, v := range values {
+			x := new(model.Tree)
+			x.InsertStack(v, "main")
+			l.addTree(value, x)
+			tree.Merge(x)
+		}
+		return &querybackendv1.Report{Tree: &querybackendv1.TreeReport{
+			Query:       &querybackendv1.TreeQuery{DominantLabel: "service_name"},
+			Tree:        tree.Bytes(-1),
+			LabelValues: l.proto(tree),
+		}}
+	}
+
+	a := newTreeAggregator(new(querybackendv1.InvokeRequest))
+	r1 := newReport(map[string]int64{"a": 3, "b": 2})
+	require.Equal(t, "a", r1.Tree.LabelValues.Values[r1.Tree.LabelValues.Nodes[0].Dominant])
+	require.NoError(t, a.aggregate(r1))
+	require.NoError(t, a.aggregate(newReport(map[string]int64{"b": 4})))
+	r := a.build().Tree
+	require.NotNil(t, r.LabelValues)
+	require.Equal(t, []string{"a", "b"}, r.LabelValues.Values)
+	require.Len(t, r.LabelValues.Nodes, 1)
+	n := r.LabelValues.Nodes[0]
+	require.Equal(t, []int64{3, 6}, n.Totals)
+	require.Equal(t, "b", r.LabelValues.Values[n.Dominant])
+}
+
+func Test_QueryTree_Coverage(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	resp, err := reader.Invoke(context.Background(), newTestInvokeRequest(
+		blocks,
+		`{service_name=~".+", __type__="cpu"}`,
+		&querybackendv1.Query{
+			QueryType: querybackendv1.QueryType_QUERY_TREE,
+			Tree:      &querybackendv1.TreeQuery{MaxNodes: 16, Coverage: true},
+		},
+	))
+	require.NoError(t, err)
+	require.Len(t, resp.Reports, 1)
+	c := resp.Reports[0].Tree.Coverage
+	require.NotNil(t, c)
+	require.Equal(t, plannedDatasets(&querybackendv1.InvokeRequest{QueryPlan: &querybackendv1.QueryPlan{Blocks: blocks}}), c.Expected)
+	require.Equal(t, c.Expected, c.Arrived)
+	require.NotEmpty(t, c.Nodes)
+	for _, n := range c.Nodes {
+		require.Positive(t, n.Datasets)
+		require.LessOrEqual(t, n.Datasets, c.Expected)
+		require.Equal(t, float64(n.Datasets)/float64(c.Expected), n.Coverage)
+	}
+}
+
+func Test_TreeAggregator_Coverage(t *testing.T) {
+	newReport := func(stacks ...[]string) *querybackendv1.Report {
+		tree := new(model.Tree)
+		for _, s := range stacks {
+			tree.InsertStack(1, s...)
+		}
+		c := newTreeCoverage()
+		c.addTree(tree)
+		return &querybackendv1.Report{Tree: &querybackendv1.TreeReport{
+			Query:    &querybackendv1.TreeQuery{Coverage: true},
+			Tree:     tree.Bytes(-1),
+			Coverage: c.proto(tree, 0),
+		}}
+	}
+
+	// Two blocks of two datasets each are planned,
+	// while only two of the datasets are reported.
+	req := &querybackendv1.InvokeRequest{QueryPlan: &querybackendv1.QueryPlan{
+		Blocks: []*metastorev1.BlockMeta{
+			{Id: "a", Datasets: make([]*metastorev1.Dataset, 2)},
+			{Id: "b", Datasets: make([]*metastorev1.Dataset, 2)},
+		},
+	}}
+	a := newTreeAggregator(req)
+	require.NoError(t, a.aggregate(newReport([]string{"main", "foo"}, []string{"main", "bar"})))
+	require.NoError(t, a.aggregate(newReport([]string{"main", "foo"})))
+	r := a.build().Tree
+	require.NotNil(t, r.Coverage)
+	require.EqualValues(t, 4, r.Coverage.Expected)
+	require.EqualValues(t, 2, r.Coverage.Arrived)
+
+	paths := make([]string, len(r.Coverage.Nodes))
+	actual := make(map[string]float64)
+	for i, n := range r.Coverage.Nodes {
+		paths[i] = n.Name
+		if n.Parent >= 0 {
+			paths[i] = paths[n.Parent] + ";" + n.Name
+		}
+		actual[paths[i]] = n.Coverage
+	}
+	require.Equal(t, map[string]float64{
+		"main":     0.5,
+		"main;foo": 0.5,
+		"main;bar": 0.25,
+	}, actual)
+}
+
+func Test_QueryTree_IntervalValues(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	minTime, maxTime := int64(math.MaxInt64), int64(math.MinInt64)
+	for _, b := range blocks {
+		minTime, maxTime = min(minTime, b.MinTime), max(maxTime, b.MaxTime)
+	}
+	req := &querybackendv1.InvokeRequest{
+		StartTime:     minTime,
+		EndTime:       maxTime + 1,
+		LabelSelector: `{service_name=~".+", __type__="cpu"}`,
+		QueryPlan:     &querybackendv1.QueryPlan{Blocks: blocks},
+		Query: []*querybackendv1.Query{{
+			QueryType: querybackendv1.QueryType_QUERY_TREE,
+			Tree:      &querybackendv1.TreeQuery{MaxNodes: 16, NodeIds: true, IntervalValues: true},
+		}},
+	}
+	resp, err := reader.Invoke(context.Background(), req)
+	require.NoError(t, err)
+	r := resp.Reports[0].Tree
+	tree := model.MustUnmarshalTree(r.Tree)
+	require.Positive(t, tree.Total())
+	require.Len(t, r.IntervalValues, len(r.NodeIds))
+	seconds := float64(maxTime+1-minTime) / 1e3
+	require.InDelta(t, float64(tree.Total())/seconds, r.IntervalValues[0], 1e-6)
+	for _, v := range r.IntervalValues[1:] {
+		require.LessOrEqual(t, v, r.IntervalValues[0])
+	}
+
+	req.Query[0].Tree = &querybackendv1.TreeQuery{IntervalValues: true, Representative: true}
+	_, err = reader.Invoke(context.Background(), req)
+	require.Error(t, err)
+}
+
+func Test_TreeQueryOptions_Table(t *testing.T) {
+	listed := make(map[string]bool)
+	for _, o := range treeQueryOptions {
+		require.False(t, listed[o.name], "duplicate option %q", o.name)
+		for _, c := range o.conflicts {
+			require.True(t, listed[c], "%q conflicts with %q, which is not listed before it", o.name, c)
+		}
+		listed[o.name] = true
+	}
+}
+
+func Test_ValidateTreeQueryOptions(t *testing.T) {
+	for _, tc := range []struct {
+		query *querybackendv1.TreeQuery
+		err   string
+	}{
+		{query: &querybackendv1.TreeQuery{}},
+		{query: &querybackendv1.TreeQuery{HottestPath: true, SampleCounts: true, ValueMerge: TreeValueMergeSum}},
+		{
+			query: &querybackendv1.TreeQuery{ProfileTypes: []string{"a"}, ValueExpression: "a"},
+			err:   "value expression can't be used along with profile types",
+		},
+		{
+			query: &querybackendv1.TreeQuery{Representative: true, Attribution: true},
+			err:   "representative profile can't be used along with attribution",
+		},
+		{
+			query: &querybackendv1.TreeQuery{SampleCounts: true, ValueMerge: TreeValueMergeMax},
+			err:   "value merge function can't be used along with sample counts",
+		},
+		{
+			query: &querybackendv1.TreeQuery{DominantLabel: "a", SubtreeNodeId: 1},
+			err:   "subtree can't be used along with dominant label",
+		},
+	} {
+		err := validateTreeQueryOptions(tc.query)
+		if tc.err == "" {
+			require.NoError(t, err)
+		} else {
+			require.EqualError(t, err, tc.err)
+		}
+	}
+}
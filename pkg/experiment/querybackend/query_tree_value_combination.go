@@ -0,0 +1,73 @@
+package querybackend
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/prometheus/prometheus/model/labels"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+var valueCombinationOperators = map[querybackendv1.TreeValueOperation]string{
+	querybackendv1.TreeValueOperation_TREE_VALUE_OPERATION_ADD:      "+",
+	querybackendv1.TreeValueOperation_TREE_VALUE_OPERATION_SUBTRACT: "-",
+	querybackendv1.TreeValueOperation_TREE_VALUE_OPERATION_MULTIPLY: "*",
+	querybackendv1.TreeValueOperation_TREE_VALUE_OPERATION_DIVIDE:   "/",
+}
+
+// valueCombinationExpression returns the value expression that
+// combines the sample types with the operation: the combination
+// is evaluated the same way as the expression would be, i.e., once
+// the operands are merged, as the products and the quotients of
+// the partial values do not add up to the ones of the merged values.
+func valueCombinationExpression(c *querybackendv1.TreeValueCombination) (*valueExpression, error) {
+	if c.Lhs == "" || c.Rhs == "" {
+		return nil, fmt.Errorf("%w: both operands of the value combination must be specified", errInvalidValueExpression)
+	}
+	op, ok := valueCombinationOperators[c.Operation]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported value combination operation %v", errInvalidValueExpression, c.Operation)
+	}
+	e := new(valueExpression)
+	lhs, rhs := e.column(c.Lhs), e.column(c.Rhs)
+	e.root = binaryValueExprNode(op,
+		func(values []float64) (float64, bool) { return values[lhs], true },
+		func(values []float64) (float64, bool) { return values[rhs], true },
+	)
+	return e, nil
+}
+
+// checkValueCombinationUnits verifies that the values of the sample
+// types can be added or subtracted: the units must be the same. The
+// sample types missing in the dataset are not verified.
+func (q *queryContext) checkValueCombinationUnits(c *querybackendv1.TreeValueCombination) error {
+	switch c.Operation {
+	case querybackendv1.TreeValueOperation_TREE_VALUE_OPERATION_ADD,
+		querybackendv1.TreeValueOperation_TREE_VALUE_OPERATION_SUBTRACT:
+	default:
+		return nil
+	}
+	lhs, err := q.sampleTypeUnits(c.Lhs)
+	if err != nil {
+		return err
+	}
+	rhs, err := q.sampleTypeUnits(c.Rhs)
+	if err != nil {
+		return err
+	}
+	if len(lhs) > 0 && len(rhs) > 0 && !slices.Equal(lhs, rhs) {
+		return fmt.Errorf("%w: mismatched units of %s (%v) and %s (%v)",
+			errInvalidValueExpression, c.Lhs, lhs, c.Rhs, rhs)
+	}
+	return nil
+}
+
+// sampleTypeUnits returns the sorted units of the sample type
+// values of the profiles matching the query.
+func (q *queryContext) sampleTypeUnits(sampleType string) ([]string, error) {
+	matcher := &labels.Matcher{Type: labels.MatchEqual, Name: model.LabelNameType, Value: sampleType}
+	c := q.withMatchers(matcher)
+	return labelValuesForMatchers(c.ds.Index(), model.LabelNameUnit, c.req.matchers)
+}
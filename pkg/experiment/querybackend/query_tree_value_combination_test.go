@@ -0,0 +1,108 @@
+package querybackend
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+func Test_QueryTree_ValueCombination(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	queryTree := func(query *querybackendv1.TreeQuery) (*model.Tree, error) {
+		return queryTestTree(reader, blocks, `{service_name=~".+"}`, query)
+	}
+	combine := func(lhs string, op querybackendv1.TreeValueOperation, rhs string) (*model.Tree, error) {
+		return queryTree(&querybackendv1.TreeQuery{
+			ValueCombination: &querybackendv1.TreeValueCombination{Lhs: lhs, Rhs: rhs, Operation: op},
+		})
+	}
+	expression := func(expr string) *model.Tree {
+		tree, err := queryTree(&querybackendv1.TreeQuery{ValueExpression: expr})
+		require.NoError(t, err)
+		return tree
+	}
+
+	for _, tc := range []struct {
+		op   querybackendv1.TreeValueOperation
+		lhs  string
+		rhs  string
+		expr string
+	}{
+		{querybackendv1.TreeValueOperation_TREE_VALUE_OPERATION_ADD, "alloc_space", "inuse_space", "alloc_space + inuse_space"},
+		{querybackendv1.TreeValueOperation_TREE_VALUE_OPERATION_SUBTRACT, "alloc_space", "inuse_space", "alloc_space - inuse_space"},
+		{querybackendv1.TreeValueOperation_TREE_VALUE_OPERATION_MULTIPLY, "alloc_objects", "alloc_space", "alloc_objects * alloc_space"},
+		{querybackendv1.TreeValueOperation_TREE_VALUE_OPERATION_DIVIDE, "alloc_space", "alloc_objects", "alloc_space / alloc_objects"},
+	} {
+		t.Run(tc.op.String(), func(t *testing.T) {
+			tree, err := combine(tc.lhs, tc.op, tc.rhs)
+			require.NoError(t, err)
+			require.NotZero(t, tree.Total())
+			require.Equal(t, expression(tc.expr).String(), tree.String())
+		})
+	}
+
+	t.Run("sum of operands", func(t *testing.T) {
+		sum, err := combine("alloc_space", querybackendv1.TreeValueOperation_TREE_VALUE_OPERATION_ADD, "inuse_space")
+		require.NoError(t, err)
+		require.Equal(t, expression("alloc_space").Total()+expression("inuse_space").Total(), sum.Total())
+	})
+
+	t.Run("division by zero", func(t *testing.T) {
+		tree, err := combine("alloc_space", querybackendv1.TreeValueOperation_TREE_VALUE_OPERATION_DIVIDE, "unknown")
+		require.NoError(t, err)
+		require.Zero(t, tree.Total())
+	})
+
+	t.Run("mismatched units", func(t *testing.T) {
+		_, err := combine("alloc_space", querybackendv1.TreeValueOperation_TREE_VALUE_OPERATION_SUBTRACT, "alloc_objects")
+		require.ErrorContains(t, err, "mismatched units")
+	})
+
+	t.Run("invalid combination", func(t *testing.T) {
+		_, err := combine("alloc_space", querybackendv1.TreeValueOperation_TREE_VALUE_OPERATION_UNSPECIFIED, "inuse_space")
+		require.Error(t, err)
+		_, err = combine("alloc_space", querybackendv1.TreeValueOperation_TREE_VALUE_OPERATION_ADD, "")
+		require.Error(t, err)
+		_, err = queryTree(&querybackendv1.TreeQuery{
+			ValueExpression:  "alloc_space",
+			ValueCombination: &querybackendv1.TreeValueCombination{Lhs: "alloc_space", Rhs: "inuse_space"},
+		})
+		require.Error(t, err)
+	})
+}
+
+func Test_TreeAggregator_ValueCombination(t *testing.T) {
+	for _, tc := range []struct {
+		op       querybackendv1.TreeValueOperation
+		expected int64
+	}{
+		// (2 + 6) / (1 + 3), rather than 2/1 + 6/3.
+		{op: querybackendv1.TreeValueOperation_TREE_VALUE_OPERATION_DIVIDE, expected: 2},
+		// (2 + 6) * (1 + 3), rather than 2*1 + 6*3.
+		{op: querybackendv1.TreeValueOperation_TREE_VALUE_OPERATION_MULTIPLY, expected: 32},
+	} {
+		query := &querybackendv1.TreeQuery{ValueCombination: &querybackendv1.TreeValueCombination{
+			Lhs: "a", Rhs: "b", Operation: tc.op,
+		}}
+		a := newTreeAggregator(&querybackendv1.InvokeRequest{
+			Query: []*querybackendv1.Query{{QueryType: querybackendv1.QueryType_QUERY_TREE, Tree: query}},
+		})
+		for _, v := range [][2]int64{{2, 1}, {6, 3}} {
+			operands := newMultiValueTree(2)
+			for i := range v {
+				tree := new(model.Tree)
+				tree.InsertStack(v[i], "main")
+				operands.addTree(i, tree)
+			}
+			require.NoError(t, a.aggregate(&querybackendv1.Report{Tree: &querybackendv1.TreeReport{
+				Query:          query,
+				MultiValueTree: operands.proto(0),
+			}}))
+		}
+		tree := model.MustUnmarshalTree(a.build().Tree.Tree)
+		require.Equal(t, tc.expected, tree.Total(), tc.op.String())
+	}
+}
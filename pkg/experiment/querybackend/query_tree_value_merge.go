@@ -0,0 +1,159 @@
+package querybackend
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/grafana/pyroscope/pkg/model"
+	"github.com/grafana/pyroscope/pkg/phlaredb/symdb"
+)
+
+// TreeValueMerge combines two values of the same stack trace, or
+// of the same tree node. The function must be commutative and
+// associative, as the order of the values is not defined.
+type TreeValueMerge func(a, b int64) int64
+
+const (
+	TreeValueMergeSum = "sum"
+	TreeValueMergeMax = "max"
+	TreeValueMergeMin = "min"
+)
+
+var (
+	valueMergeMutex = new(sync.RWMutex)
+	valueMerges     = map[string]TreeValueMerge{}
+)
+
+func init() {
+	RegisterTreeValueMerge(TreeValueMergeSum, func(a, b int64) int64 { return a + b })
+	RegisterTreeValueMerge(TreeValueMergeMax, func(a, b int64) int64 { return max(a, b) })
+	RegisterTreeValueMerge(TreeValueMergeMin, func(a, b int64) int64 { return min(a, b) })
+}
+
+// RegisterTreeValueMerge registers a named value merge function that can
+// be referenced in TreeQuery.ValueMerge. The function must be called at
+// initialization, before the query backend starts serving requests.
+func RegisterTreeValueMerge(name string, fn TreeValueMerge) {
+	valueMergeMutex.Lock()
+	defer valueMergeMutex.Unlock()
+	if _, ok := valueMerges[name]; ok {
+		panic(fmt.Sprintf("%s: value merge function already registered", name))
+	}
+	valueMerges[name] = fn
+}
+
+// getTreeValueMerge returns the value merge function of the given name.
+// Nil is returned for the default one: the values are added up.
+func getTreeValueMerge(name string) (TreeValueMerge, error) {
+	if name == "" || name == TreeValueMergeSum {
+		return nil, nil
+	}
+	valueMergeMutex.RLock()
+	defer valueMergeMutex.RUnlock()
+	fn, ok := valueMerges[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown value merge function %q", name)
+	}
+	return fn, nil
+}
+
+// withValueMerge returns a copy of the query context that combines the
+// values with the named function. If the function is the default one,
+// the query context is returned as is.
+func (q *queryContext) withValueMerge(name string) (*queryContext, error) {
+	fn, err := getTreeValueMerge(name)
+	if err != nil || fn == nil {
+		return q, err
+	}
+	c := *q
+	c.valueMerge = fn
+	return &c, nil
+}
+
+// pathValues combines the sample values of the profiles per resolved
+// stack trace: the values of the stack traces of a profile that resolve
+// to the same path are added up, as in the tree, and the values of the
+// profiles are combined with the merge function. The samples are kept
+// until the stack traces are resolved.
+type pathValues struct {
+	merge    TreeValueMerge
+	profiles []profileSamples
+}
+
+type profileSamples struct {
+	partition     uint64
+	stacktraceIDs []uint32
+	values        []int64
+}
+
+func newPathValues(merge TreeValueMerge) *pathValues {
+	return &pathValues{merge: merge}
+}
+
+func (s *pathValues) add(partition uint64, stacktraceIDs, values []parquet.Value) {
+	p := profileSamples{
+		partition:     partition,
+		stacktraceIDs: make([]uint32, 0, len(stacktraceIDs)),
+		values:        make([]int64, 0, len(values)),
+	}
+	for i, sid := range stacktraceIDs {
+		if id, v := sid.Uint32(), values[i].Int64(); id != 0 && v != 0 {
+			p.stacktraceIDs = append(p.stacktraceIDs, id)
+			p.values = append(p.values, v)
+		}
+	}
+	s.profiles = append(s.profiles, p)
+}
+
+// tree resolves the stack traces of the samples added to the resolver,
+// which must include the ones of the profiles, and builds the tree.
+func (s *pathValues) tree(resolver *symdb.Resolver) (*model.Tree, error) {
+	type stackKey struct {
+		partition    uint64
+		stacktraceID uint32
+	}
+	paths := make(map[stackKey]int)
+	index := make(map[string]int)
+	var stacks [][]string
+	err := resolver.Stacks(func(partition uint64, stacktraceID uint32, stack []string) {
+		k := strings.Join(stack, "\x00")
+		i, ok := index[k]
+		if !ok {
+			i = len(stacks)
+			index[k] = i
+			stacks = append(stacks, slices.Clone(stack))
+		}
+		paths[stackKey{partition, stacktraceID}] = i
+	})
+	if err != nil {
+		return nil, err
+	}
+	values := make([]int64, len(stacks))
+	seen := make([]bool, len(stacks))
+	profile := make(map[int]int64)
+	for _, p := range s.profiles {
+		clear(profile)
+		for j, id := range p.stacktraceIDs {
+			if i, ok := paths[stackKey{p.partition, id}]; ok {
+				profile[i] += p.values[j]
+			}
+		}
+		for i, v := range profile {
+			if seen[i] {
+				v = s.merge(values[i], v)
+			}
+			values[i], seen[i] = v, true
+		}
+	}
+	tree := new(model.Tree)
+	for i, stack := range stacks {
+		if seen[i] {
+			tree.InsertStack(values[i], stack...)
+		}
+	}
+	return tree, nil
+}
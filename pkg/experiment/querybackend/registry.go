@@ -0,0 +1,103 @@
+package querybackend
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/prometheus/model/labels"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend/block"
+)
+
+// QueryFunc executes an externally registered query against a dataset.
+// The query parameters and the report payload are expected to be carried
+// in the Custom fields of the Query and Report messages, respectively.
+type QueryFunc func(*QueryContext, *querybackendv1.Query) (*querybackendv1.Report, error)
+
+// Aggregator merges reports of an externally registered report type.
+// Aggregate is called concurrently; Build is called once, after at
+// least one Aggregate call.
+type Aggregator interface {
+	Aggregate(*querybackendv1.Report) error
+	Build() *querybackendv1.Report
+}
+
+type AggregatorProvider func(*querybackendv1.InvokeRequest) Aggregator
+
+// QueryContext provides access to the dataset being queried.
+type QueryContext struct{ q *queryContext }
+
+func (c *QueryContext) Context() context.Context    { return c.q.ctx }
+func (c *QueryContext) Logger() log.Logger          { return c.q.log }
+func (c *QueryContext) Dataset() *block.Dataset     { return c.q.ds }
+func (c *QueryContext) Matchers() []*labels.Matcher { return c.q.req.matchers }
+
+// TimeRange returns the time range of the query in nanoseconds.
+func (c *QueryContext) TimeRange() (start, end int64) {
+	return c.q.req.startTime, c.q.req.endTime
+}
+
+// RegisterQueryType registers a query type defined outside of the package.
+// The query and report types must not collide with the ones defined in
+// the querybackend.v1 API, or registered previously. The dependencies may
+// include custom sections registered with block.RegisterSection: they are
+// opened along with the dataset, and are accessible via Dataset.Section.
+// The function must be called at initialization, before the query backend
+// starts serving requests.
+func RegisterQueryType(
+	qt querybackendv1.QueryType,
+	rt querybackendv1.ReportType,
+	fn QueryFunc,
+	ap AggregatorProvider,
+	deps ...block.Section,
+) error {
+	if _, ok := querybackendv1.QueryType_name[int32(qt)]; ok {
+		return fmt.Errorf("query type %d collides with built-in query type %s", qt, qt)
+	}
+	if _, ok := querybackendv1.ReportType_name[int32(rt)]; ok {
+		return fmt.Errorf("report type %d collides with built-in report type %s", rt, rt)
+	}
+	if isQueryTypeRegistered(qt) {
+		return fmt.Errorf("query type %d already registered", qt)
+	}
+	if isReportTypeRegistered(rt) {
+		return fmt.Errorf("report type %d already registered", rt)
+	}
+	for _, s := range deps {
+		if !s.IsValid() {
+			return fmt.Errorf("query type %d depends on unknown section %d", qt, s)
+		}
+	}
+	registerQueryType(qt, rt,
+		func(q *queryContext, query *querybackendv1.Query) (*querybackendv1.Report, error) {
+			return fn(&QueryContext{q: q}, query)
+		},
+		func(r *querybackendv1.InvokeRequest) aggregator {
+			return externalAggregator{ap(r)}
+		},
+		noQueryTimeout,
+		deps...,
+	)
+	return nil
+}
+
+func isQueryTypeRegistered(qt querybackendv1.QueryType) bool {
+	handlerMutex.RLock()
+	defer handlerMutex.RUnlock()
+	_, ok := queryHandlers[qt]
+	return ok
+}
+
+func isReportTypeRegistered(rt querybackendv1.ReportType) bool {
+	aggregatorMutex.RLock()
+	defer aggregatorMutex.RUnlock()
+	_, ok := aggregators[rt]
+	return ok
+}
+
+type externalAggregator struct{ Aggregator }
+
+func (a externalAggregator) aggregate(r *querybackendv1.Report) error { return a.Aggregate(r) }
+func (a externalAggregator) build() *querybackendv1.Report            { return a.Build() }
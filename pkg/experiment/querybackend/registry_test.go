@@ -0,0 +1,198 @@
+package querybackend
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend/block"
+)
+
+const (
+	testQueryDatasetNames  querybackendv1.QueryType  = 1000
+	testReportDatasetNames querybackendv1.ReportType = 1000
+
+	testQuerySectionPath  querybackendv1.QueryType  = 1002
+	testReportSectionPath querybackendv1.ReportType = 1002
+
+	testQueryNilReport  querybackendv1.QueryType  = 1004
+	testReportNilReport querybackendv1.ReportType = 1004
+)
+
+// testSection is a custom section whose value is
+// the path of the object the dataset belongs to.
+var testSection block.Section
+
+func init() {
+	err := RegisterQueryType(
+		testQueryDatasetNames,
+		testReportDatasetNames,
+		func(q *QueryContext, _ *querybackendv1.Query) (*querybackendv1.Report, error) {
+			return &querybackendv1.Report{Custom: []byte(q.Dataset().Meta().Name)}, nil
+		},
+		func(*querybackendv1.InvokeRequest) Aggregator { return new(datasetNamesAggregator) },
+		block.SectionTSDB,
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	testSection, err = block.RegisterSection("test", func(_ context.Context, ds *block.Dataset) (any, error) {
+		return ds.Object().Path(), nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	err = RegisterQueryType(
+		testQuerySectionPath,
+		testReportSectionPath,
+		func(q *QueryContext, _ *querybackendv1.Query) (*querybackendv1.Report, error) {
+			return &querybackendv1.Report{Custom: []byte(q.Dataset().Section(testSection).(string))}, nil
+		},
+		func(*querybackendv1.InvokeRequest) Aggregator { return new(datasetNamesAggregator) },
+		testSection,
+	)
+	if err != nil {
+		panic(err)
+	}
+	err = RegisterQueryType(
+		testQueryNilReport,
+		testReportNilReport,
+		func(*QueryContext, *querybackendv1.Query) (*querybackendv1.Report, error) {
+			return new(querybackendv1.Report), nil
+		},
+		func(*querybackendv1.InvokeRequest) Aggregator { return nilReportAggregator{} },
+		block.SectionTSDB,
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// nilReportAggregator is a faulty aggregator that builds no report.
+type nilReportAggregator struct{}
+
+func (nilReportAggregator) Aggregate(*querybackendv1.Report) error { return nil }
+func (nilReportAggregator) Build() *querybackendv1.Report          { return nil }
+
+type datasetNamesAggregator struct {
+	m     sync.Mutex
+	names []string
+}
+
+func (a *datasetNamesAggregator) Aggregate(r *querybackendv1.Report) error {
+	a.m.Lock()
+	defer a.m.Unlock()
+	a.names = append(a.names, strings.Split(string(r.Custom), ",")...)
+	return nil
+}
+
+func (a *datasetNamesAggregator) Build() *querybackendv1.Report {
+	sort.Strings(a.names)
+	return &querybackendv1.Report{Custom: []byte(strings.Join(a.names, ","))}
+}
+
+func Test_RegisterQueryType(t *testing.T) {
+	fn := func(*QueryContext, *querybackendv1.Query) (*querybackendv1.Report, error) { return nil, nil }
+	ap := func(*querybackendv1.InvokeRequest) Aggregator { return nil }
+
+	err := RegisterQueryType(querybackendv1.QueryType_QUERY_TREE, 1001, fn, ap)
+	require.ErrorContains(t, err, "collides with built-in query type")
+	err = RegisterQueryType(1001, querybackendv1.ReportType_REPORT_TREE, fn, ap)
+	require.ErrorContains(t, err, "collides with built-in report type")
+	err = RegisterQueryType(querybackendv1.QueryType_QUERY_UNSPECIFIED, 1001, fn, ap)
+	require.Error(t, err)
+	err = RegisterQueryType(testQueryDatasetNames, 1001, fn, ap)
+	require.ErrorContains(t, err, "already registered")
+	err = RegisterQueryType(1001, testReportDatasetNames, fn, ap)
+	require.ErrorContains(t, err, "already registered")
+	err = RegisterQueryType(1001, 1001, fn, ap, block.SectionTSDB, block.Section(1<<20))
+	require.ErrorContains(t, err, "unknown section")
+}
+
+func Test_RegisterSection(t *testing.T) {
+	open := func(context.Context, *block.Dataset) (any, error) { return nil, nil }
+	_, err := block.RegisterSection("", open)
+	require.Error(t, err)
+	_, err = block.RegisterSection("index", nil)
+	require.Error(t, err)
+	_, err = block.RegisterSection("symbols", open)
+	require.ErrorContains(t, err, "collides with built-in section")
+	_, err = block.RegisterSection("test", open)
+	require.ErrorContains(t, err, "already registered")
+	require.True(t, testSection.IsValid())
+	require.True(t, block.SectionSymbols.IsValid())
+}
+
+func Test_ExternalQueryType_CustomSection(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	var expected []string
+	for _, b := range blocks {
+		for range b.Datasets {
+			expected = append(expected, block.ObjectPath(b))
+		}
+	}
+	sort.Strings(expected)
+
+	resp, err := reader.Invoke(context.Background(), &querybackendv1.InvokeRequest{
+		LabelSelector: "{}",
+		QueryPlan:     &querybackendv1.QueryPlan{Blocks: blocks},
+		Query:         []*querybackendv1.Query{{QueryType: testQuerySectionPath}},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Reports, 1)
+	require.Equal(t, strings.Join(expected, ","), string(resp.Reports[0].Custom))
+}
+
+func Test_ExternalQueryType(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	var expected []string
+	for _, b := range blocks {
+		for _, ds := range b.Datasets {
+			expected = append(expected, ds.Name)
+		}
+	}
+	sort.Strings(expected)
+
+	resp, err := reader.Invoke(context.Background(), &querybackendv1.InvokeRequest{
+		LabelSelector: "{}",
+		QueryPlan:     &querybackendv1.QueryPlan{Blocks: blocks},
+		Query:         []*querybackendv1.Query{{QueryType: testQueryDatasetNames}},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Reports, 1)
+	require.Equal(t, testReportDatasetNames, resp.Reports[0].ReportType)
+	require.Equal(t, strings.Join(expected, ","), string(resp.Reports[0].Custom))
+}
+
+func Test_ExternalQueryType_NilReport(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	resp, err := reader.Invoke(context.Background(), &querybackendv1.InvokeRequest{
+		LabelSelector: "{}",
+		QueryPlan:     &querybackendv1.QueryPlan{Blocks: blocks},
+		Query: []*querybackendv1.Query{
+			{QueryType: testQueryNilReport},
+			{QueryType: testQueryDatasetNames},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Reports, 2)
+	sort.Slice(resp.Reports, func(i, j int) bool {
+		return resp.Reports[i].ReportType < resp.Reports[j].ReportType
+	})
+	// The other reports are not affected.
+	require.Equal(t, testReportDatasetNames, resp.Reports[0].ReportType)
+	require.NotEmpty(t, resp.Reports[0].Custom)
+	// The faulty aggregator results in an empty report of the type.
+	require.Equal(t, &querybackendv1.Report{
+		ReportType: testReportNilReport,
+		QueryType:  testQueryNilReport,
+	}, resp.Reports[1])
+	_, err = resp.MarshalVT()
+	require.NoError(t, err)
+}
@@ -0,0 +1,57 @@
+package querybackend
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/relabel"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+)
+
+func relabelConfigs(rules []*querybackendv1.RelabelRule) ([]*relabel.Config, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	configs := make([]*relabel.Config, len(rules))
+	for i, rule := range rules {
+		c := relabel.DefaultRelabelConfig
+		if len(rule.SourceLabels) > 0 {
+			c.SourceLabels = make(model.LabelNames, len(rule.SourceLabels))
+			for j, l := range rule.SourceLabels {
+				c.SourceLabels[j] = model.LabelName(l)
+			}
+		}
+		if rule.Separator != "" {
+			c.Separator = rule.Separator
+		}
+		if rule.Regex != "" {
+			regex, err := relabel.NewRegexp(rule.Regex)
+			if err != nil {
+				return nil, fmt.Errorf("relabel rule %d: %w", i, err)
+			}
+			c.Regex = regex
+		}
+		if rule.TargetLabel != "" {
+			c.TargetLabel = rule.TargetLabel
+		}
+		if rule.Replacement != "" {
+			c.Replacement = rule.Replacement
+		}
+		if rule.Action != "" {
+			c.Action = relabel.Action(strings.ToLower(rule.Action))
+		}
+		switch c.Action {
+		case relabel.Replace, relabel.Keep, relabel.Drop, relabel.KeepEqual, relabel.DropEqual,
+			relabel.LabelMap, relabel.LabelDrop, relabel.LabelKeep, relabel.Lowercase, relabel.Uppercase:
+		default:
+			return nil, fmt.Errorf("relabel rule %d: unsupported action %q", i, c.Action)
+		}
+		if err := c.Validate(); err != nil {
+			return nil, fmt.Errorf("relabel rule %d: %w", i, err)
+		}
+		configs[i] = &c
+	}
+	return configs, nil
+}
@@ -1,9 +1,15 @@
 package querybackend
 
 import (
+	"cmp"
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
 
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+
 	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
 )
 
@@ -23,6 +29,13 @@ type aggregator interface {
 	build() *querybackendv1.Report
 }
 
+// failingAggregator is implemented by the aggregators that may fail to
+// build the aggregation result: the response fails with the error.
+type failingAggregator interface {
+	// buildError returns the error of the last build() call, if any.
+	buildError() error
+}
+
 func registerAggregator(t querybackendv1.ReportType, ap aggregatorProvider) {
 	aggregatorMutex.Lock()
 	defer aggregatorMutex.Unlock()
@@ -64,24 +77,76 @@ func QueryReportType(q querybackendv1.QueryType) querybackendv1.ReportType {
 }
 
 type reportAggregator struct {
+	logger      log.Logger
 	request     *querybackendv1.InvokeRequest
 	sm          sync.Mutex
-	staged      map[querybackendv1.ReportType]*querybackendv1.Report
-	aggregators map[querybackendv1.ReportType]aggregator
+	staged      map[reportKey]*querybackendv1.Report
+	aggregators map[reportKey]aggregator
+	// Query type of the first report of each key.
+	queryTypes map[reportKey]querybackendv1.QueryType
+	skipped    skippedBlocks
+	// Requests of the individual queries of the batch, by query
+	// index. Nil, unless the request is executed as a batch.
+	batch []*querybackendv1.InvokeRequest
 }
 
-func newAggregator(request *querybackendv1.InvokeRequest) *reportAggregator {
-	return &reportAggregator{
+// reportKey identifies the reports that are aggregated together.
+// The query index is only set if the request is executed as a batch:
+// otherwise, the reports of the same type are aggregated together.
+type reportKey struct {
+	reportType querybackendv1.ReportType
+	queryIndex uint32
+}
+
+func keyOf(r *querybackendv1.Report) reportKey {
+	return reportKey{reportType: r.ReportType, queryIndex: r.QueryIndex}
+}
+
+func newAggregator(logger log.Logger, request *querybackendv1.InvokeRequest) *reportAggregator {
+	ra := &reportAggregator{
+		logger:      logger,
 		request:     request,
-		staged:      make(map[querybackendv1.ReportType]*querybackendv1.Report),
-		aggregators: make(map[querybackendv1.ReportType]aggregator),
+		staged:      make(map[reportKey]*querybackendv1.Report),
+		aggregators: make(map[reportKey]aggregator),
+		queryTypes:  make(map[reportKey]querybackendv1.QueryType),
 	}
+	if request.Options.GetBatch() {
+		// The aggregators of the batch only see the query
+		// the reports belong to; the rest is shared.
+		ra.batch = make([]*querybackendv1.InvokeRequest, len(request.Query))
+		for i, query := range request.Query {
+			ra.batch[i] = &querybackendv1.InvokeRequest{
+				Tenant:        request.Tenant,
+				StartTime:     request.StartTime,
+				EndTime:       request.EndTime,
+				LabelSelector: request.LabelSelector,
+				Query:         []*querybackendv1.Query{query},
+				QueryPlan:     request.QueryPlan,
+				Options:       request.Options,
+			}
+		}
+	}
+	return ra
+}
+
+// queryRequest returns the request the aggregator of the report is
+// created for: either the request itself, or the request of the query
+// of the batch.
+func (ra *reportAggregator) queryRequest(r *querybackendv1.Report) (*querybackendv1.InvokeRequest, error) {
+	if ra.batch == nil {
+		return ra.request, nil
+	}
+	if int(r.QueryIndex) >= len(ra.batch) {
+		return nil, fmt.Errorf("%s: query index %d is out of range", r.ReportType, r.QueryIndex)
+	}
+	return ra.batch[r.QueryIndex], nil
 }
 
 func (ra *reportAggregator) aggregateResponse(resp *querybackendv1.InvokeResponse, err error) error {
 	if err != nil {
 		return err
 	}
+	ra.skipped.merge(resp.Diagnostics)
 	for _, r := range resp.Reports {
 		if err = ra.aggregateReport(r); err != nil {
 			return err
@@ -95,61 +160,219 @@ func (ra *reportAggregator) aggregateReport(r *querybackendv1.Report) (err error
 		return nil
 	}
 	ra.sm.Lock()
-	v, found := ra.staged[r.ReportType]
+	k := keyOf(r)
+	v, found := ra.staged[k]
 	if !found {
+		ra.queryTypes[k] = r.QueryType
 		// We delay aggregation until we have at least two
 		// reports of the same type. Otherwise, we just store
 		// the report and will return it as is, if it is the
 		// only one.
-		ra.staged[r.ReportType] = r
+		ra.staged[k] = r
 		ra.sm.Unlock()
 		return nil
 	}
-	// Found a staged report of the same type.
-	if v != nil {
-		// It should be aggregated and removed from the table.
-		err = ra.aggregateReportNoCheck(v)
-		ra.staged[r.ReportType] = nil
-	}
-	ra.sm.Unlock()
+	// Found a staged report of the same type. The aggregator is
+	// looked up while the lock is held, as the first reports of
+	// different keys may arrive concurrently; the reports are
+	// aggregated without the lock.
+	a, err := ra.aggregatorOf(r)
 	if err != nil {
+		ra.sm.Unlock()
 		return err
 	}
-	return ra.aggregateReportNoCheck(r)
-}
-
-func (ra *reportAggregator) aggregateReportNoCheck(report *querybackendv1.Report) (err error) {
-	a, ok := ra.aggregators[report.ReportType]
-	if !ok {
-		a, err = getAggregator(ra.request, report)
-		if err != nil {
+	// The staged report, if any, is removed from the table.
+	ra.staged[k] = nil
+	ra.sm.Unlock()
+	if v != nil {
+		if err = a.aggregate(v); err != nil {
 			return err
 		}
-		ra.aggregators[report.ReportType] = a
 	}
-	return a.aggregate(report)
+	return a.aggregate(r)
+}
+
+// aggregatorOf returns the aggregator of the report, creating
+// it if needed. The caller must hold the lock.
+func (ra *reportAggregator) aggregatorOf(report *querybackendv1.Report) (aggregator, error) {
+	k := keyOf(report)
+	if a, ok := ra.aggregators[k]; ok {
+		return a, nil
+	}
+	req, err := ra.queryRequest(report)
+	if err != nil {
+		return nil, err
+	}
+	a, err := getAggregator(req, report)
+	if err != nil {
+		return nil, err
+	}
+	ra.aggregators[k] = a
+	return a, nil
 }
 
 func (ra *reportAggregator) aggregateStaged() error {
+	ra.sm.Lock()
+	defer ra.sm.Unlock()
 	for _, r := range ra.staged {
-		if r != nil {
-			if err := ra.aggregateReportNoCheck(r); err != nil {
-				return err
-			}
+		if r == nil {
+			continue
+		}
+		a, err := ra.aggregatorOf(r)
+		if err != nil {
+			return err
+		}
+		if err = a.aggregate(r); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
 func (ra *reportAggregator) response() (*querybackendv1.InvokeResponse, error) {
+	var resp *querybackendv1.InvokeResponse
+	err := ra.responseChunks(0, func(r *querybackendv1.InvokeResponse) error {
+		resp = r
+		return nil
+	})
+	return resp, err
+}
+
+// responseChunks builds the reports, and calls the function for the chunks
+// of the response of approximately the given size, see splitResponse. The
+// reports are built one at a time, and their aggregators are released once
+// the reports are delivered. If the size is not positive, the response is
+// delivered in a single chunk.
+func (ra *reportAggregator) responseChunks(chunkSize int64, fn func(*querybackendv1.InvokeResponse) error) error {
 	if err := ra.aggregateStaged(); err != nil {
-		return nil, err
+		return err
+	}
+	keys := make([]reportKey, 0, len(ra.aggregators))
+	for k := range ra.aggregators {
+		keys = append(keys, k)
+	}
+	// The reports are built in the same order for the same request:
+	// the chunks of a resumed stream are the ones sent before.
+	slices.SortFunc(keys, func(a, b reportKey) int {
+		if c := cmp.Compare(a.queryIndex, b.queryIndex); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.reportType, b.reportType)
+	})
+	c := newResponseChunker(chunkSize, fn)
+	for _, k := range keys {
+		r, err := ra.buildReport(k)
+		if err != nil {
+			return err
+		}
+		if chunkSize > 0 {
+			delete(ra.aggregators, k)
+		}
+		if err := c.add(r); err != nil {
+			return err
+		}
+	}
+	return c.flush(ra.skipped.diagnostics())
+}
+
+func (ra *reportAggregator) buildReport(k reportKey) (*querybackendv1.Report, error) {
+	a := ra.aggregators[k]
+	r := a.build()
+	if f, ok := a.(failingAggregator); ok {
+		if err := f.buildError(); err != nil {
+			return nil, fmt.Errorf("%s: failed to build the report: %w", k.reportType, err)
+		}
+	}
+	if r == nil {
+		// The aggregator is faulty: we respond with an empty
+		// report of the type, so that the response is still
+		// well-formed and the other reports are not lost.
+		level.Warn(ra.logger).Log(
+			"msg", "aggregator returned no report",
+			queryIDLabel, ra.request.GetOptions().GetQueryId(),
+			"query_type", ra.queryTypes[k],
+			"report_type", k.reportType,
+		)
+		r = new(querybackendv1.Report)
+	}
+	r.ReportType = k.reportType
+	r.QueryIndex = k.queryIndex
+	r.QueryType = ra.queryTypes[k]
+	return r, nil
+}
+
+// skipBlock records that the block was not queried, e.g. because the
+// block is invalid, or because the query deadline has been exceeded.
+func (ra *reportAggregator) skipBlock(id string, reason querybackendv1.SkipReason, msg string) {
+	ra.skipped.add(&querybackendv1.SkippedBlock{
+		BlockId: id,
+		Reason:  reason,
+		Message: msg,
+	})
+}
+
+// The number of the skipped blocks reported individually. The total
+// number of the skipped blocks is not limited.
+const maxSkippedBlocksReported = 100
+
+// skippedBlocks collects the blocks skipped by the query and the reasons.
+// A block is only counted once, even if it was skipped multiple times.
+type skippedBlocks struct {
+	mu     sync.Mutex
+	blocks []*querybackendv1.SkippedBlock
+	seen   map[string]struct{}
+	// Skipped blocks that are not listed individually:
+	// either dropped here or in the partial responses.
+	unlisted uint64
+}
+
+func (s *skippedBlocks) add(b *querybackendv1.SkippedBlock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addNoLock(b)
+}
+
+func (s *skippedBlocks) addNoLock(b *querybackendv1.SkippedBlock) {
+	if s.seen == nil {
+		s.seen = make(map[string]struct{})
+	}
+	if _, ok := s.seen[b.BlockId]; ok {
+		return
+	}
+	s.seen[b.BlockId] = struct{}{}
+	if len(s.blocks) < maxSkippedBlocksReported {
+		s.blocks = append(s.blocks, b)
+	} else {
+		s.unlisted++
+	}
+}
+
+func (s *skippedBlocks) merge(d *querybackendv1.Diagnostics) {
+	if d == nil || d.SkippedBlocksCount == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, b := range d.SkippedBlocks {
+		s.addNoLock(b)
+	}
+	if n := uint64(len(d.SkippedBlocks)); d.SkippedBlocksCount > n {
+		s.unlisted += d.SkippedBlocksCount - n
+	}
+}
+
+func (s *skippedBlocks) diagnostics() *querybackendv1.Diagnostics {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := uint64(len(s.blocks)) + s.unlisted
+	if n == 0 {
+		return nil
 	}
-	reports := make([]*querybackendv1.Report, 0, len(ra.staged))
-	for t, a := range ra.aggregators {
-		r := a.build()
-		r.ReportType = t
-		reports = append(reports, r)
+	slices.SortFunc(s.blocks, func(a, b *querybackendv1.SkippedBlock) int {
+		return strings.Compare(a.BlockId, b.BlockId)
+	})
+	return &querybackendv1.Diagnostics{
+		SkippedBlocks:      s.blocks,
+		SkippedBlocksCount: n,
 	}
-	return &querybackendv1.InvokeResponse{Reports: reports}, nil
 }
@@ -0,0 +1,91 @@
+package querybackend
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/pyroscope/pkg/phlaredb/symdb"
+	"github.com/grafana/pyroscope/pkg/util"
+)
+
+// resolverWatchdog tracks the resolvers of a function run by
+// runWatched, so that they can be released if the function is
+// abandoned because the query context has expired.
+type resolverWatchdog struct {
+	// Number of the functions abandoned, but not completed yet.
+	abandonedGauge prometheus.Gauge
+
+	m         sync.Mutex
+	resolvers []*symdb.Resolver
+	abandoned bool
+	completed bool
+}
+
+// add registers the resolver. If the function has been
+// abandoned already, the resolver is released right away.
+func (w *resolverWatchdog) add(r *symdb.Resolver) {
+	w.m.Lock()
+	defer w.m.Unlock()
+	if w.abandoned {
+		r.ReleaseAsync()
+		return
+	}
+	w.resolvers = append(w.resolvers, r)
+}
+
+// abandon releases the resolvers asynchronously, and reports
+// whether the function was abandoned: this is not the case if
+// it has completed in the meantime.
+func (w *resolverWatchdog) abandon() bool {
+	w.m.Lock()
+	defer w.m.Unlock()
+	if w.completed {
+		return false
+	}
+	w.abandoned = true
+	w.abandonedGauge.Inc()
+	for _, r := range w.resolvers {
+		r.ReleaseAsync()
+	}
+	return true
+}
+
+func (w *resolverWatchdog) complete() {
+	w.m.Lock()
+	defer w.m.Unlock()
+	w.completed = true
+	if w.abandoned {
+		w.abandonedGauge.Dec()
+	}
+}
+
+// runWatched runs the function in a separate goroutine, and waits for it
+// to complete, or for the query context to expire. In the latter case, the
+// resolvers registered with the watchdog are released asynchronously, and
+// the context error is returned without waiting for the function: a stuck
+// resolver call must not block the query. The function holds a reference
+// to the dataset until it completes, so that the dataset is not closed
+// while it is still in use.
+func (q *queryContext) runWatched(fn func(*resolverWatchdog) error) error {
+	if err := q.open(); err != nil {
+		return err
+	}
+	w := &resolverWatchdog{abandonedGauge: q.metrics.abandonedResolvers}
+	done := make(chan error, 1)
+	go func() {
+		err := util.RecoverPanic(func() error { return fn(w) })()
+		w.complete()
+		_ = q.close(nil)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-q.ctx.Done():
+		if !w.abandon() {
+			return <-done
+		}
+		return q.ctx.Err()
+	}
+}
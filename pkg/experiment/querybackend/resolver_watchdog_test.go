@@ -0,0 +1,67 @@
+package querybackend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend/block"
+)
+
+func Test_RunWatched_Abandoned(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	vr, err := validateRequest(newTestInvokeRequest(
+		blocks,
+		`{service_name=~".+"}`,
+		&querybackendv1.Query{QueryType: querybackendv1.QueryType_QUERY_TREE},
+	))
+	require.NoError(t, err)
+	md := blocks[0]
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	q := newQueryContext(ctx, reader.log, reader.metrics, md.Datasets[0], vr, block.NewObject(reader.storage, md))
+	require.NoError(t, q.open())
+
+	// The function is stuck until it is unblocked: the resolver
+	// is released, even though the function has not completed.
+	unblock := make(chan struct{})
+	released := make(chan struct{})
+	err = q.runWatched(func(w *resolverWatchdog) error {
+		r := q.newResolver()
+		w.add(r)
+		<-unblock
+		<-r.ReleaseAsync()
+		close(released)
+		return nil
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, float64(1), testutil.ToFloat64(reader.metrics.abandonedResolvers))
+	// The dataset is still in use by the function.
+	require.NoError(t, q.close(nil))
+
+	close(unblock)
+	<-released
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(reader.metrics.abandonedResolvers) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func Test_RunWatched_Completed(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	vr, err := validateRequest(newTestInvokeRequest(
+		blocks,
+		`{service_name=~".+"}`,
+		&querybackendv1.Query{QueryType: querybackendv1.QueryType_QUERY_TREE},
+	))
+	require.NoError(t, err)
+	md := blocks[0]
+	q := newQueryContext(context.Background(), reader.log, reader.metrics, md.Datasets[0], vr, block.NewObject(reader.storage, md))
+	trees, _, err := resolveTreesByLabel(q, nil, "")
+	require.NoError(t, err)
+	require.Positive(t, trees[""].Total())
+	require.Zero(t, testutil.ToFloat64(reader.metrics.abandonedResolvers))
+}
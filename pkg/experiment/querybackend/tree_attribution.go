@@ -0,0 +1,129 @@
+package querybackend
+
+import (
+	"slices"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+// treeAttribution tracks the sources that contributed to each of
+// the tree nodes. It is not safe for concurrent use.
+type treeAttribution struct {
+	sources []string
+	index   map[string]uint32
+	root    attributionNode
+}
+
+type attributionNode struct {
+	children map[string]*attributionNode
+	// Sorted indices of the contributing sources.
+	sources []uint32
+}
+
+func newTreeAttribution() *treeAttribution {
+	return &treeAttribution{index: make(map[string]uint32)}
+}
+
+func (n *attributionNode) child(name string) *attributionNode {
+	if n.children == nil {
+		n.children = make(map[string]*attributionNode)
+	}
+	c, ok := n.children[name]
+	if !ok {
+		c = new(attributionNode)
+		n.children[name] = c
+	}
+	return c
+}
+
+func (n *attributionNode) addSource(s uint32) {
+	if i, found := slices.BinarySearch(n.sources, s); !found {
+		n.sources = slices.Insert(n.sources, i, s)
+	}
+}
+
+func (a *treeAttribution) source(id string) uint32 {
+	s, ok := a.index[id]
+	if !ok {
+		s = uint32(len(a.sources))
+		a.sources = append(a.sources, id)
+		a.index[id] = s
+	}
+	return s
+}
+
+// addTree attributes all the nodes of the tree to the source.
+func (a *treeAttribution) addTree(source string, t *model.Tree) {
+	s := a.source(source)
+	t.IterateStacks(func(_ string, _ int64, stack []string) {
+		n := &a.root
+		for i := len(stack) - 1; i >= 0; i-- {
+			n = n.child(stack[i])
+			n.addSource(s)
+		}
+	})
+}
+
+func (a *treeAttribution) merge(p *querybackendv1.TreeAttribution) {
+	sources := make([]uint32, len(p.Sources))
+	for i, id := range p.Sources {
+		sources[i] = a.source(id)
+	}
+	nodes := make([]*attributionNode, len(p.Nodes))
+	for i, x := range p.Nodes {
+		parent := &a.root
+		if x.Parent >= 0 && int(x.Parent) < i {
+			parent = nodes[x.Parent]
+		}
+		n := parent.child(x.Name)
+		for _, s := range x.Sources {
+			if int(s) < len(sources) {
+				n.addSource(sources[s])
+			}
+		}
+		nodes[i] = n
+	}
+}
+
+// proto returns the attribution of the nodes present in the tree.
+// The sources are sorted, so that the result does not depend on the
+// order the sources were added in.
+func (a *treeAttribution) proto(t *model.Tree) *querybackendv1.TreeAttribution {
+	p := &querybackendv1.TreeAttribution{Sources: slices.Clone(a.sources)}
+	slices.Sort(p.Sources)
+	remap := make([]uint32, len(a.sources))
+	for i, id := range a.sources {
+		j, _ := slices.BinarySearch(p.Sources, id)
+		remap[i] = uint32(j)
+	}
+	visited := make(map[*attributionNode]int32)
+	t.IterateStacks(func(_ string, _ int64, stack []string) {
+		n := &a.root
+		parent := int32(-1)
+		for i := len(stack) - 1; i >= 0; i-- {
+			name := stack[i]
+			if n = n.children[name]; n == nil {
+				// The node is not attributed.
+				return
+			}
+			j, ok := visited[n]
+			if !ok {
+				j = int32(len(p.Nodes))
+				visited[n] = j
+				sources := make([]uint32, len(n.sources))
+				for k, x := range n.sources {
+					sources[k] = remap[x]
+				}
+				slices.Sort(sources)
+				p.Nodes = append(p.Nodes, &querybackendv1.TreeNodeAttribution{
+					Parent:  parent,
+					Name:    name,
+					Sources: sources,
+				})
+			}
+			parent = j
+		}
+	})
+	return p
+}
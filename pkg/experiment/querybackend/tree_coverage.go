@@ -0,0 +1,114 @@
+package querybackend
+
+import (
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+// treeCoverage counts the datasets that contributed to each
+// of the tree nodes. It is not safe for concurrent use.
+type treeCoverage struct {
+	expected uint64
+	arrived  uint64
+	root     coverageNode
+}
+
+type coverageNode struct {
+	children map[string]*coverageNode
+	datasets uint64
+}
+
+func newTreeCoverage() *treeCoverage {
+	return new(treeCoverage)
+}
+
+// plannedDatasets returns the number of the datasets of
+// the blocks planned for the query, if the plan is known.
+func plannedDatasets(req *querybackendv1.InvokeRequest) uint64 {
+	var n uint64
+	for _, b := range req.GetQueryPlan().GetBlocks() {
+		n += uint64(len(b.Datasets))
+	}
+	return n
+}
+
+func (n *coverageNode) child(name string) *coverageNode {
+	if n.children == nil {
+		n.children = make(map[string]*coverageNode)
+	}
+	c, ok := n.children[name]
+	if !ok {
+		c = new(coverageNode)
+		n.children[name] = c
+	}
+	return c
+}
+
+// addTree accounts the nodes of the tree of a single dataset.
+func (c *treeCoverage) addTree(t *model.Tree) {
+	c.expected++
+	c.arrived++
+	visited := make(map[*coverageNode]struct{})
+	t.IterateStacks(func(_ string, _ int64, stack []string) {
+		n := &c.root
+		for i := len(stack) - 1; i >= 0; i-- {
+			n = n.child(stack[i])
+			if _, ok := visited[n]; !ok {
+				visited[n] = struct{}{}
+				n.datasets++
+			}
+		}
+	})
+}
+
+func (c *treeCoverage) merge(p *querybackendv1.TreeCoverage) {
+	c.expected += p.Expected
+	c.arrived += p.Arrived
+	nodes := make([]*coverageNode, len(p.Nodes))
+	for i, x := range p.Nodes {
+		parent := &c.root
+		if x.Parent >= 0 && int(x.Parent) < i {
+			parent = nodes[x.Parent]
+		}
+		n := parent.child(x.Name)
+		n.datasets += x.Datasets
+		nodes[i] = n
+	}
+}
+
+// proto returns the coverage of the nodes present in the tree. If the
+// number of the expected datasets is not known from the query plan, it
+// is the sum of the expected datasets of the merged reports.
+func (c *treeCoverage) proto(t *model.Tree, planned uint64) *querybackendv1.TreeCoverage {
+	p := &querybackendv1.TreeCoverage{
+		Expected: max(planned, c.expected),
+		Arrived:  c.arrived,
+	}
+	visited := make(map[*coverageNode]int32)
+	t.IterateStacks(func(_ string, _ int64, stack []string) {
+		n := &c.root
+		parent := int32(-1)
+		for i := len(stack) - 1; i >= 0; i-- {
+			name := stack[i]
+			if n = n.children[name]; n == nil {
+				return
+			}
+			j, ok := visited[n]
+			if !ok {
+				j = int32(len(p.Nodes))
+				visited[n] = j
+				x := &querybackendv1.TreeNodeCoverage{
+					Parent:   parent,
+					Name:     name,
+					Datasets: n.datasets,
+				}
+				if p.Expected > 0 {
+					x.Coverage = min(1, float64(n.datasets)/float64(p.Expected))
+				}
+				p.Nodes = append(p.Nodes, x)
+			}
+			parent = j
+		}
+	})
+	return p
+}
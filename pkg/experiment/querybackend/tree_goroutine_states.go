@@ -0,0 +1,93 @@
+package querybackend
+
+import (
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+const (
+	goroutineStateRunning = "running"
+	goroutineStateWaiting = "waiting"
+)
+
+// goroutineStates maps the runtime and standard library functions a
+// goroutine blocks in to the state of the goroutine. The names of the
+// states follow the wait reasons reported by the Go runtime.
+var goroutineStates = map[string]string{
+	"runtime.chanrecv":                 "chan receive",
+	"runtime.chanrecv1":                "chan receive",
+	"runtime.chanrecv2":                "chan receive",
+	"runtime.chansend":                 "chan send",
+	"runtime.chansend1":                "chan send",
+	"runtime.selectgo":                 "select",
+	"runtime.block":                    "select (no cases)",
+	"internal/poll.runtime_pollWait":   "IO wait",
+	"sync.runtime_Semacquire":          "semacquire",
+	"sync.runtime_SemacquireMutex":     "sync.Mutex.Lock",
+	"sync.runtime_SemacquireRWMutex":   "sync.RWMutex.Lock",
+	"sync.runtime_SemacquireRWMutexR":  "sync.RWMutex.RLock",
+	"sync.runtime_SemacquireWaitGroup": "sync.WaitGroup.Wait",
+	"sync.runtime_notifyListWait":      "sync.Cond.Wait",
+	"time.Sleep":                       "sleep",
+	"runtime.gcBgMarkWorker":           "GC worker (idle)",
+	"runtime.bgsweep":                  "GC sweep wait",
+	"runtime.bgscavenge":               "GC scavenge wait",
+	"runtime.forcegchelper":            "force gc (idle)",
+	"runtime.runfinq":                  "finalizer wait",
+	"syscall.Syscall":                  "syscall",
+	"syscall.Syscall6":                 "syscall",
+	"syscall.RawSyscall":               "syscall",
+	"syscall.RawSyscall6":              "syscall",
+	"syscall.syscall":                  "syscall",
+	"syscall.syscall6":                 "syscall",
+}
+
+// isGoroutineProfile reports whether the tree is built of goroutine
+// profiles only, judging by the sample types.
+func isGoroutineProfile(sampleTypes []*querybackendv1.TreeSampleType) bool {
+	for _, t := range sampleTypes {
+		if t.Type != "goroutine" && t.Type != "goroutines" {
+			return false
+		}
+	}
+	return len(sampleTypes) > 0
+}
+
+// goroutineState infers the state of the goroutine from the stack trace,
+// which starts with the leaf frame: the function closest to the leaf that
+// is known to block the goroutine determines the state. A goroutine parked
+// in any other function is waiting; otherwise, it is considered running,
+// which includes the runnable goroutines.
+func goroutineState(stack []string) string {
+	var parked bool
+	for _, name := range stack {
+		if state, ok := goroutineStates[name]; ok {
+			return state
+		}
+		parked = parked || name == "runtime.gopark" || name == "runtime.goparkunlock"
+	}
+	if parked {
+		return goroutineStateWaiting
+	}
+	return goroutineStateRunning
+}
+
+// newTreeGoroutineStates returns the contributions of the goroutine
+// states to the nodes of the tree. If the tree is inverted, the stack
+// traces are reversed to infer the state.
+func newTreeGoroutineStates(t *model.Tree, inverted bool) *treeLabelValues {
+	states := newTreeLabelValues()
+	var buf []string
+	t.IterateStacks(func(_ string, self int64, stack []string) {
+		s := stack
+		if inverted {
+			buf = append(buf[:0], stack...)
+			for i, j := 0, len(buf)-1; i < j; i, j = i+1, j-1 {
+				buf[i], buf[j] = buf[j], buf[i]
+			}
+			s = buf
+		}
+		states.addStack(goroutineState(s), self, stack)
+	})
+	return states
+}
@@ -0,0 +1,106 @@
+package querybackend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+func Test_GoroutineState(t *testing.T) {
+	for _, tc := range []struct {
+		stack    []string
+		expected string
+	}{
+		{[]string{"runtime.gopark", "runtime.chanrecv", "runtime.chanrecv1", "main.worker"}, "chan receive"},
+		{[]string{"runtime.gopark", "runtime.netpollblock", "internal/poll.runtime_pollWait", "net.(*conn).Read"}, "IO wait"},
+		{[]string{"runtime.gopark", "runtime.selectgo", "runtime.chanrecv", "main.loop"}, "select"},
+		{[]string{"runtime.gopark", "main.park"}, goroutineStateWaiting},
+		{[]string{"main.compute", "main.main"}, goroutineStateRunning},
+		{nil, goroutineStateRunning},
+	} {
+		require.Equal(t, tc.expected, goroutineState(tc.stack), tc.stack)
+	}
+}
+
+func Test_IsGoroutineProfile(t *testing.T) {
+	require.True(t, isGoroutineProfile([]*querybackendv1.TreeSampleType{{Type: "goroutine", Unit: "count"}}))
+	require.False(t, isGoroutineProfile([]*querybackendv1.TreeSampleType{{Type: "goroutine"}, {Type: "cpu"}}))
+	require.False(t, isGoroutineProfile(nil))
+}
+
+func Test_TreeGoroutineStates(t *testing.T) {
+	tree := new(model.Tree)
+	tree.InsertStack(3, "main", "main.worker", "runtime.chanrecv1", "runtime.gopark")
+	tree.InsertStack(2, "main", "main.worker", "internal/poll.runtime_pollWait", "runtime.gopark")
+	tree.InsertStack(1, "main", "main.compute")
+
+	expected := &querybackendv1.TreeLabelValues{
+		Values: []string{"IO wait", "chan receive", "running"},
+		Nodes: []*querybackendv1.TreeNodeLabelValues{
+			{Parent: -1, Name: "main", Values: []uint32{0, 1, 2}, Totals: []int64{2, 3, 1}, Dominant: 1},
+			{Parent: 0, Name: "main.compute", Values: []uint32{2}, Totals: []int64{1}, Dominant: 2},
+			{Parent: 0, Name: "main.worker", Values: []uint32{0, 1}, Totals: []int64{2, 3}, Dominant: 1},
+			{Parent: 2, Name: "internal/poll.runtime_pollWait", Values: []uint32{0}, Totals: []int64{2}},
+			{Parent: 3, Name: "runtime.gopark", Values: []uint32{0}, Totals: []int64{2}},
+			{Parent: 2, Name: "runtime.chanrecv1", Values: []uint32{1}, Totals: []int64{3}, Dominant: 1},
+			{Parent: 5, Name: "runtime.gopark", Values: []uint32{1}, Totals: []int64{3}, Dominant: 1},
+		},
+	}
+	require.Equal(t, expected, newTreeGoroutineStates(tree, false).proto(tree))
+
+	// The states of an inverted tree are inferred from the stack
+	// traces of the original one: the roots are the leaf frames.
+	inverted := tree.Inverted()
+	states := newTreeGoroutineStates(inverted, true).proto(inverted)
+	require.Equal(t, expected.Values, states.Values)
+	var gopark *querybackendv1.TreeNodeLabelValues
+	for _, n := range states.Nodes {
+		if n.Parent == -1 && n.Name == "runtime.gopark" {
+			gopark = n
+		}
+	}
+	require.NotNil(t, gopark)
+	require.Equal(t, []uint32{0, 1}, gopark.Values)
+	require.Equal(t, []int64{2, 3}, gopark.Totals)
+
+	// The contributions are preserved when the reports are merged.
+	agg := newTreeAggregator(&querybackendv1.InvokeRequest{
+		Query: []*querybackendv1.Query{{
+			QueryType: querybackendv1.QueryType_QUERY_TREE,
+			Tree:      &querybackendv1.TreeQuery{GoroutineStates: true},
+		}},
+	})
+	for i := 0; i < 2; i++ {
+		require.NoError(t, agg.aggregate(&querybackendv1.Report{
+			ReportType: querybackendv1.ReportType_REPORT_TREE,
+			Tree: &querybackendv1.TreeReport{
+				Query:           &querybackendv1.TreeQuery{GoroutineStates: true},
+				Tree:            tree.Bytes(-1),
+				GoroutineStates: newTreeGoroutineStates(tree, false).proto(tree),
+			},
+		}))
+	}
+	merged := agg.build().Tree.GoroutineStates
+	require.Equal(t, expected.Values, merged.Values)
+	require.Equal(t, []int64{4, 6, 2}, merged.Nodes[0].Totals)
+}
+
+func Test_QueryTree_GoroutineStates(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	resp, err := reader.Invoke(context.Background(), newTestInvokeRequest(
+		blocks,
+		`{service_name=~".+", __type__="cpu"}`,
+		&querybackendv1.Query{
+			QueryType: querybackendv1.QueryType_QUERY_TREE,
+			Tree:      &querybackendv1.TreeQuery{MaxNodes: 16, GoroutineStates: true},
+		},
+	))
+	require.NoError(t, err)
+	// The option has no effect on the other profile types.
+	require.NotEmpty(t, resp.Reports[0].Tree.Tree)
+	require.Nil(t, resp.Reports[0].Tree.GoroutineStates)
+}
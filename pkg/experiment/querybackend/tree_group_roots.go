@@ -0,0 +1,51 @@
+package querybackend
+
+import (
+	"github.com/prometheus/prometheus/model/relabel"
+
+	"github.com/grafana/pyroscope/pkg/model"
+	"github.com/grafana/pyroscope/pkg/phlaredb/symdb"
+)
+
+// resolveGroupRootTree resolves a tree for each of the values of the
+// label, and grafts the trees under the synthetic root nodes of the
+// values. The transformation is applied to the trees of the values
+// before they are grafted, so that the synthetic roots stay on top.
+func resolveGroupRootTree(
+	q *queryContext,
+	label string,
+	rules []*relabel.Config,
+	transform func(*model.Tree) *model.Tree,
+	opts ...symdb.ResolverOption,
+) (*model.Tree, float64, error) {
+	trees, unsymbolized, err := resolveTreesByLabel(q, rules, label, opts...)
+	if err != nil {
+		return nil, 0, err
+	}
+	tree := new(model.Tree)
+	for value, t := range trees {
+		graftTree(tree, groupRootName(label, value), transform(t))
+	}
+	return tree, unsymbolized, nil
+}
+
+// groupRootName returns the name of the synthetic
+// root node of the label value group.
+func groupRootName(label, value string) string {
+	return label + "=" + value
+}
+
+// graftTree inserts the stacks of the tree t into the tree
+// under the root node given: the node total is the total of t.
+func graftTree(tree *model.Tree, root string, t *model.Tree) {
+	var stack []string
+	t.IterateStacks(func(_ string, self int64, s []string) {
+		// The stack is given leaf first, while the tree
+		// expects the root first.
+		stack = append(stack[:0], root)
+		for i := len(s) - 1; i >= 0; i-- {
+			stack = append(stack, s[i])
+		}
+		tree.InsertStack(self, stack...)
+	})
+}
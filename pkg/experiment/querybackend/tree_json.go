@@ -0,0 +1,96 @@
+package querybackend
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/grafana/dskit/tenant"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+	httputil "github.com/grafana/pyroscope/pkg/util/http"
+)
+
+var errTreeJSONQuery = errors.New("exactly one tree query expected")
+
+// TreeJSONHandler returns the HTTP handler that invokes a tree query and
+// writes the resulting tree as indented JSON. The request body is an
+// InvokeRequest in the protobuf JSON format, with a single tree query.
+// The query is executed on behalf of the tenants of the request context.
+//
+// The handler is meant for debugging only: nil is returned,
+// unless the endpoint is enabled in the configuration.
+func (q *QueryBackend) TreeJSONHandler() http.Handler {
+	if !q.config.DebugTreeJSON {
+		return nil
+	}
+	return http.HandlerFunc(q.serveTreeJSON)
+}
+
+func (q *QueryBackend) serveTreeJSON(w http.ResponseWriter, r *http.Request) {
+	tree, _, ok := q.invokeTreeQuery(w, r)
+	if !ok {
+		return
+	}
+	nodes := tree.Nodes()
+	if nodes == nil {
+		nodes = []*model.TreeNode{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(nodes)
+}
+
+// invokeTreeQuery invokes the tree query of the HTTP request, and returns
+// the resulting tree and the report. If the query fails, the error is
+// written to the response, and false is returned.
+func (q *QueryBackend) invokeTreeQuery(w http.ResponseWriter, r *http.Request) (*model.Tree, *querybackendv1.TreeReport, bool) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		httputil.ErrorWithStatus(w, err, http.StatusBadRequest)
+		return nil, nil, false
+	}
+	var req querybackendv1.InvokeRequest
+	if err = protojson.Unmarshal(body, &req); err != nil {
+		httputil.ErrorWithStatus(w, fmt.Errorf("invalid request: %w", err), http.StatusBadRequest)
+		return nil, nil, false
+	}
+	if len(req.Query) != 1 || req.Query[0].QueryType != querybackendv1.QueryType_QUERY_TREE {
+		httputil.ErrorWithStatus(w, errTreeJSONQuery, http.StatusBadRequest)
+		return nil, nil, false
+	}
+	// The tenants specified in the request body are
+	// ignored: only the authenticated ones are queried.
+	tenants, err := tenant.TenantIDs(r.Context())
+	if err != nil {
+		httputil.ErrorWithStatus(w, err, http.StatusUnauthorized)
+		return nil, nil, false
+	}
+	req.Tenant = tenants
+	// The request goes through the same path as any other
+	// query, therefore the tree is identical to the one
+	// returned in the binary format.
+	resp, err := q.Invoke(r.Context(), &req)
+	if err != nil {
+		httputil.Error(w, err)
+		return nil, nil, false
+	}
+	tree := new(model.Tree)
+	var treeReport *querybackendv1.TreeReport
+	for _, report := range resp.Reports {
+		if report.ReportType != querybackendv1.ReportType_REPORT_TREE {
+			continue
+		}
+		if tree, err = UnmarshalReportTree(report.Tree); err != nil {
+			httputil.Error(w, err)
+			return nil, nil, false
+		}
+		treeReport = report.Tree
+	}
+	return tree, treeReport, true
+}
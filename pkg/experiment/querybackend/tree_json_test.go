@@ -0,0 +1,55 @@
+package querybackend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+	"github.com/grafana/pyroscope/pkg/tenant"
+)
+
+func Test_TreeJSONHandler(t *testing.T) {
+	require.Nil(t, newTestQueryBackend(t, Config{}, new(testBlockReader)).TreeJSONHandler())
+
+	b := newTestQueryBackend(t, Config{DebugTreeJSON: true}, new(testBlockReader))
+	h := b.TreeJSONHandler()
+	require.NotNil(t, h)
+
+	serve := func(req *querybackendv1.InvokeRequest) *httptest.ResponseRecorder {
+		body, err := protojson.Marshal(req)
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/query-backend/debug/tree", bytes.NewReader(body))
+		h.ServeHTTP(w, r.WithContext(tenant.InjectTenantID(r.Context(), "tenant")))
+		return w
+	}
+
+	req := newTestTreeRequest("a", "b")
+	w := serve(req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var actual []*model.TreeNode
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &actual))
+
+	resp, err := b.Invoke(context.Background(), newTestTreeRequest("a", "b"))
+	require.NoError(t, err)
+	expected := model.MustUnmarshalTree(resp.Reports[0].Tree.Tree).Nodes()
+	require.Equal(t, expected, actual)
+
+	// The tenant is taken from the request context.
+	body, err := protojson.Marshal(req)
+	require.NoError(t, err)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/query-backend/debug/tree", bytes.NewReader(body)))
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req.Query[0].QueryType = querybackendv1.QueryType_QUERY_LABEL_NAMES
+	require.Equal(t, http.StatusBadRequest, serve(req).Code)
+}
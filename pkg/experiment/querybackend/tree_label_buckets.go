@@ -0,0 +1,131 @@
+package querybackend
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/prometheus/prometheus/model/relabel"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+	"github.com/grafana/pyroscope/pkg/phlaredb/symdb"
+)
+
+func validateLabelBuckets(b *querybackendv1.TreeLabelBuckets) error {
+	if b == nil {
+		return nil
+	}
+	if b.Label == "" {
+		return fmt.Errorf("label buckets: label must be specified")
+	}
+	if len(b.Bounds) == 0 {
+		return fmt.Errorf("label buckets: at least one bound must be specified")
+	}
+	for i, v := range b.Bounds {
+		if math.IsNaN(v) {
+			return fmt.Errorf("label buckets: bound %d is not a number", i)
+		}
+		if i > 0 && v <= b.Bounds[i-1] {
+			return fmt.Errorf("label buckets: bounds must be in ascending order")
+		}
+	}
+	return nil
+}
+
+// labelBucket returns the index of the bucket the label value falls into:
+// the first bound not less than the value, or the overflow bucket. The
+// values that are not numbers do not fall into any of the buckets.
+func labelBucket(bounds []float64, value string) (int, bool) {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(v) {
+		return 0, false
+	}
+	return sort.SearchFloat64s(bounds, v), true
+}
+
+// resolveTreeLabelBuckets resolves the tree of each of the label buckets,
+// and returns the tree of all the profiles, along with the bucket trees
+// serialized. The transformation is applied to the trees of the buckets
+// before they are combined.
+func resolveTreeLabelBuckets(
+	q *queryContext,
+	b *querybackendv1.TreeLabelBuckets,
+	rules []*relabel.Config,
+	transform func(*model.Tree) *model.Tree,
+	serialize func(*model.Tree) *querybackendv1.TreeBucket,
+	opts ...symdb.ResolverOption,
+) (*model.Tree, []*querybackendv1.TreeBucket, float64, error) {
+	// The profiles out of the buckets are grouped under the empty key.
+	groupKey := func(value string) string {
+		if i, ok := labelBucket(b.Bounds, value); ok {
+			return strconv.Itoa(i)
+		}
+		return ""
+	}
+	trees, unsymbolized, err := resolveTreesByGroup(q, rules, b.Label, groupKey, opts...)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	buckets := make([]*querybackendv1.TreeBucket, len(b.Bounds)+1)
+	tree := new(model.Tree)
+	for i := range buckets {
+		t, ok := trees[strconv.Itoa(i)]
+		if !ok {
+			t = new(model.Tree)
+		}
+		t = transform(t)
+		// The bucket tree is serialized before it is merged:
+		// the tree merged first shares the nodes with the result.
+		buckets[i] = serialize(t)
+		tree.Merge(t)
+	}
+	if t, ok := trees[""]; ok {
+		tree.Merge(transform(t))
+	}
+	return tree, buckets, unsymbolized, nil
+}
+
+// treeBuckets merges the trees of the label buckets of the reports.
+// The zero value is ready to use.
+type treeBuckets struct {
+	m       sync.Mutex
+	buckets []*model.TreeMerger
+}
+
+func (b *treeBuckets) merge(buckets []*querybackendv1.TreeBucket, version int) error {
+	b.m.Lock()
+	for len(b.buckets) < len(buckets) {
+		b.buckets = append(b.buckets, model.NewTreeMerger())
+	}
+	mergers := b.buckets
+	b.m.Unlock()
+	for i, x := range buckets {
+		if err := mergers[i].MergeTreeBytesVersion(x.Tree, version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// proto returns the merged trees of the buckets, or nil,
+// if none of the reports have the label buckets.
+func (b *treeBuckets) proto(maxDepth, maxNodes int64, version int) []*querybackendv1.TreeBucket {
+	if len(b.buckets) == 0 {
+		return nil
+	}
+	buckets := make([]*querybackendv1.TreeBucket, len(b.buckets))
+	for i, m := range b.buckets {
+		tree := m.Tree()
+		if maxDepth > 0 {
+			tree.LimitDepth(int(maxDepth))
+		}
+		buckets[i] = &querybackendv1.TreeBucket{
+			Tree:      tree.BytesVersion(maxNodes, version),
+			Truncated: treeTruncated(tree.Size(), maxNodes),
+		}
+	}
+	return buckets
+}
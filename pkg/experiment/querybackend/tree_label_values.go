@@ -0,0 +1,181 @@
+package querybackend
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/prometheus/prometheus/model/relabel"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+	"github.com/grafana/pyroscope/pkg/phlaredb/symdb"
+)
+
+// resolveTreeLabelValues resolves a tree for each of the values of the
+// label, and returns the tree of all the values along with the label
+// value contributions to the tree nodes. The transformation is applied
+// to the trees of the values before they are combined.
+func resolveTreeLabelValues(
+	q *queryContext,
+	label string,
+	rules []*relabel.Config,
+	transform func(*model.Tree) *model.Tree,
+	opts ...symdb.ResolverOption,
+) (*model.Tree, *treeLabelValues, float64, error) {
+	trees, unsymbolized, err := resolveTreesByLabel(q, rules, label, opts...)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	values := newTreeLabelValues()
+	tree := new(model.Tree)
+	for value, t := range trees {
+		t = transform(t)
+		values.addTree(value, t)
+		tree.Merge(t)
+	}
+	return tree, values, unsymbolized, nil
+}
+
+// treeLabelValues tracks the contributions of the label values
+// to each of the tree nodes. It is not safe for concurrent use.
+type treeLabelValues struct {
+	values []string
+	index  map[string]uint32
+	root   labelValuesNode
+}
+
+type labelValuesNode struct {
+	children map[string]*labelValuesNode
+	// Contributions to the node total by label value index.
+	totals map[uint32]int64
+}
+
+func newTreeLabelValues() *treeLabelValues {
+	return &treeLabelValues{index: make(map[string]uint32)}
+}
+
+func (n *labelValuesNode) child(name string) *labelValuesNode {
+	if n.children == nil {
+		n.children = make(map[string]*labelValuesNode)
+	}
+	c, ok := n.children[name]
+	if !ok {
+		c = &labelValuesNode{totals: make(map[uint32]int64)}
+		n.children[name] = c
+	}
+	return c
+}
+
+func (l *treeLabelValues) value(v string) uint32 {
+	x, ok := l.index[v]
+	if !ok {
+		x = uint32(len(l.values))
+		l.values = append(l.values, v)
+		l.index[v] = x
+	}
+	return x
+}
+
+// addTree accounts the tree values to the label value.
+func (l *treeLabelValues) addTree(value string, t *model.Tree) {
+	t.IterateStacks(func(_ string, self int64, stack []string) {
+		l.addStack(value, self, stack)
+	})
+}
+
+// addStack accounts the value of the stack trace, which
+// starts with the leaf frame, to the label value.
+func (l *treeLabelValues) addStack(value string, self int64, stack []string) {
+	v := l.value(value)
+	n := &l.root
+	for i := len(stack) - 1; i >= 0; i-- {
+		n = n.child(stack[i])
+		n.totals[v] += self
+	}
+}
+
+func (l *treeLabelValues) merge(p *querybackendv1.TreeLabelValues) {
+	values := make([]uint32, len(p.Values))
+	for i, v := range p.Values {
+		values[i] = l.value(v)
+	}
+	nodes := make([]*labelValuesNode, len(p.Nodes))
+	for i, x := range p.Nodes {
+		parent := &l.root
+		if x.Parent >= 0 && int(x.Parent) < i {
+			parent = nodes[x.Parent]
+		}
+		n := parent.child(x.Name)
+		for j := 0; j < len(x.Values) && j < len(x.Totals); j++ {
+			if v := x.Values[j]; int(v) < len(values) {
+				n.totals[values[v]] += x.Totals[j]
+			}
+		}
+		nodes[i] = n
+	}
+}
+
+// proto returns the label value contributions to the nodes present in
+// the tree, and the dominant value of each node. The dominant value is
+// chosen once the contributions are combined, and the values are sorted,
+// so that the result does not depend on the order the trees were added in.
+func (l *treeLabelValues) proto(t *model.Tree) *querybackendv1.TreeLabelValues {
+	p := &querybackendv1.TreeLabelValues{Values: slices.Clone(l.values)}
+	slices.Sort(p.Values)
+	remap := make([]uint32, len(l.values))
+	for i, v := range l.values {
+		j, _ := slices.BinarySearch(p.Values, v)
+		remap[i] = uint32(j)
+	}
+	visited := make(map[*labelValuesNode]int32)
+	t.IterateStacks(func(_ string, _ int64, stack []string) {
+		n := &l.root
+		parent := int32(-1)
+		for i := len(stack) - 1; i >= 0; i-- {
+			name := stack[i]
+			if n = n.children[name]; n == nil {
+				return
+			}
+			j, ok := visited[n]
+			if !ok {
+				j = int32(len(p.Nodes))
+				visited[n] = j
+				p.Nodes = append(p.Nodes, n.proto(parent, name, remap))
+			}
+			parent = j
+		}
+	})
+	return p
+}
+
+func (n *labelValuesNode) proto(parent int32, name string, remap []uint32) *querybackendv1.TreeNodeLabelValues {
+	type contribution struct {
+		value uint32
+		total int64
+	}
+	contributions := make([]contribution, 0, len(n.totals))
+	for v, total := range n.totals {
+		contributions = append(contributions, contribution{value: remap[v], total: total})
+	}
+	slices.SortFunc(contributions, func(a, b contribution) int {
+		return cmp.Compare(a.value, b.value)
+	})
+	x := &querybackendv1.TreeNodeLabelValues{
+		Parent: parent,
+		Name:   name,
+		Values: make([]uint32, len(contributions)),
+		Totals: make([]int64, len(contributions)),
+	}
+	var dominant int
+	for i, c := range contributions {
+		x.Values[i] = c.value
+		x.Totals[i] = c.total
+		if c.total > contributions[dominant].total {
+			dominant = i
+		}
+	}
+	if len(contributions) > 0 {
+		x.Dominant = contributions[dominant].value
+	}
+	return x
+}
@@ -0,0 +1,103 @@
+package querybackend
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cespare/xxhash/v2"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+func validateTreeLegend(query *querybackendv1.TreeLegendQuery) error {
+	if query == nil {
+		return nil
+	}
+	if query.Limit < 0 {
+		return fmt.Errorf("legend: limit must not be negative")
+	}
+	if query.Colors == 0 {
+		return fmt.Errorf("legend: number of colors must be positive")
+	}
+	return nil
+}
+
+// treeLegend ranks the packages of the frames of the tree by the sum
+// of the self values. The legend is built from the tree as it is sent
+// to the client, so that the values match the nodes displayed: the
+// values of the truncated nodes are accounted as other.
+func treeLegend(tree *model.Tree, query *querybackendv1.TreeLegendQuery) *querybackendv1.TreeLegend {
+	values := make(map[string]int64)
+	var other int64
+	tree.IterateStacks(func(name string, self int64, _ []string) {
+		if name == truncatedNodeName {
+			other += self
+			return
+		}
+		values[framePackage(name)] += self
+	})
+	entries := make([]*querybackendv1.TreeLegendEntry, 0, len(values))
+	for pkg, v := range values {
+		entries = append(entries, &querybackendv1.TreeLegendEntry{
+			Package: pkg,
+			Value:   v,
+			Color:   packageColor(pkg, query.Colors),
+		})
+	}
+	slices.SortFunc(entries, func(a, b *querybackendv1.TreeLegendEntry) int {
+		if r := cmp.Compare(b.Value, a.Value); r != 0 {
+			return r
+		}
+		return strings.Compare(a.Package, b.Package)
+	})
+	if limit := query.Limit; limit > 0 && int64(len(entries)) > limit {
+		for _, e := range entries[limit:] {
+			other += e.Value
+		}
+		entries = entries[:limit]
+	}
+	return &querybackendv1.TreeLegend{Entries: entries, Other: other}
+}
+
+// packageColor returns the color index of the package. The index depends
+// on the name only, so that a package keeps its color across queries and
+// refreshes; the price is that distinct packages may share a color.
+func packageColor(pkg string, colors uint32) uint32 {
+	return uint32(xxhash.Sum64String(pkg) % uint64(colors))
+}
+
+// framePackage returns the package of the function of the frame:
+//
+//	github.com/grafana/pyroscope/pkg/model.(*Tree).Bytes -> github.com/grafana/pyroscope/pkg/model
+//	runtime.mallocgc                                     -> runtime
+//	std::collections::hash::map::HashMap<K,V>::insert    -> std::collections::hash::map::HashMap<K,V>
+//	java.util.HashMap.get                                -> java.util.HashMap
+//
+// The name itself is returned, if the package can't be told.
+func framePackage(name string) string {
+	// Go methods: the receiver follows the package.
+	if i := strings.Index(name, ".("); i > 0 {
+		return name[:i]
+	}
+	s := name
+	// The parameters may contain the separators.
+	if i := strings.IndexByte(s, '('); i > 0 {
+		s = s[:i]
+	}
+	if i := strings.LastIndex(s, "::"); i > 0 {
+		return s[:i]
+	}
+	if i := strings.LastIndexByte(s, '/'); i >= 0 {
+		if j := strings.IndexByte(s[i:], '.'); j > 0 {
+			return s[:i+j]
+		}
+		return name
+	}
+	if i := strings.LastIndexByte(s, '.'); i > 0 {
+		return s[:i]
+	}
+	return name
+}
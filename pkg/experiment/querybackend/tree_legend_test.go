@@ -0,0 +1,90 @@
+package querybackend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+func Test_FramePackage(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		expected string
+	}{
+		{"github.com/grafana/pyroscope/pkg/model.(*Tree).Bytes", "github.com/grafana/pyroscope/pkg/model"},
+		{"github.com/grafana/pyroscope/pkg/model.MustUnmarshalTree", "github.com/grafana/pyroscope/pkg/model"},
+		{"gopkg.in/yaml.v3.Unmarshal", "gopkg.in/yaml"},
+		{"main.(*server).Handle", "main"},
+		{"runtime.mallocgc", "runtime"},
+		{"std::collections::hash::map::HashMap<K,V>::insert", "std::collections::hash::map::HashMap<K,V>"},
+		{"ns::f(a::b)", "ns"},
+		{"java.util.HashMap.get", "java.util.HashMap"},
+		{"malloc", "malloc"},
+		{"/usr/lib/libc.so.6", "/usr/lib/libc"},
+		{"[unknown]", "[unknown]"},
+	} {
+		require.Equal(t, tc.expected, framePackage(tc.name), tc.name)
+	}
+}
+
+func Test_TreeLegend(t *testing.T) {
+	tree := new(model.Tree)
+	tree.InsertStack(5, "main.main", "runtime.mallocgc")
+	tree.InsertStack(3, "main.main", "main.(*server).Handle")
+	tree.InsertStack(2, "main.main", "runtime.memmove")
+	tree.InsertStack(1, "main.main", "syscall.Syscall")
+	tree.InsertStack(1, "main.main", "other")
+
+	legend := treeLegend(tree, &querybackendv1.TreeLegendQuery{Limit: 2, Colors: 8})
+	require.Len(t, legend.Entries, 2)
+	require.Equal(t, "runtime", legend.Entries[0].Package)
+	require.Equal(t, int64(7), legend.Entries[0].Value)
+	require.Equal(t, "main", legend.Entries[1].Package)
+	require.Equal(t, int64(3), legend.Entries[1].Value)
+	// The truncated nodes and the packages not included.
+	require.Equal(t, int64(2), legend.Other)
+
+	// The colors do not depend on the rank of the package.
+	all := treeLegend(tree, &querybackendv1.TreeLegendQuery{Colors: 8})
+	require.Len(t, all.Entries, 3)
+	require.Equal(t, int64(1), all.Other)
+	for i, e := range legend.Entries {
+		require.Equal(t, all.Entries[i].Color, e.Color)
+		require.Less(t, e.Color, uint32(8))
+	}
+
+	require.Error(t, validateTreeLegend(&querybackendv1.TreeLegendQuery{}))
+	require.Error(t, validateTreeLegend(&querybackendv1.TreeLegendQuery{Limit: -1, Colors: 1}))
+	require.NoError(t, validateTreeLegend(nil))
+}
+
+func Test_QueryTree_Legend(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	resp, err := reader.Invoke(context.Background(), newTestInvokeRequest(
+		blocks,
+		`{service_name=~".+", __type__="cpu"}`,
+		&querybackendv1.Query{
+			QueryType: querybackendv1.QueryType_QUERY_TREE,
+			Tree: &querybackendv1.TreeQuery{
+				MaxNodes: 1024,
+				Legend:   &querybackendv1.TreeLegendQuery{Limit: 4, Colors: 16},
+			},
+		},
+	))
+	require.NoError(t, err)
+	r := resp.Reports[0].Tree
+	require.NotNil(t, r.Legend)
+	require.NotEmpty(t, r.Legend.Entries)
+	require.LessOrEqual(t, len(r.Legend.Entries), 4)
+
+	// The legend matches the truncated tree.
+	total := r.Legend.Other
+	for _, e := range r.Legend.Entries {
+		total += e.Value
+	}
+	require.Equal(t, model.MustUnmarshalTree(r.Tree).Total(), total)
+}
@@ -0,0 +1,156 @@
+package querybackend
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// NameNormalizer maps a resolved frame name to the name of the logical
+// function, so that the frames of the same function produced by different
+// builds are merged into a single node. The function must be idempotent:
+// the names may be normalized more than once, e.g. by the aggregator.
+type NameNormalizer func(string) string
+
+const (
+	NameNormalizerLowercase    = "lowercase"
+	NameNormalizerDemangle     = "demangle"
+	NameNormalizerStripVersion = "strip_version"
+)
+
+var (
+	normalizerMutex = new(sync.RWMutex)
+	nameNormalizers = map[string]NameNormalizer{}
+)
+
+func init() {
+	RegisterNameNormalizer(NameNormalizerLowercase, strings.ToLower)
+	RegisterNameNormalizer(NameNormalizerDemangle, demangle)
+	RegisterNameNormalizer(NameNormalizerStripVersion, stripVersion)
+}
+
+// RegisterNameNormalizer registers a named frame name normalizer that can
+// be referenced in TreeQuery.NameNormalizers. The function must be called
+// at initialization, before the query backend starts serving requests.
+func RegisterNameNormalizer(name string, fn NameNormalizer) {
+	normalizerMutex.Lock()
+	defer normalizerMutex.Unlock()
+	if _, ok := nameNormalizers[name]; ok {
+		panic(fmt.Sprintf("%s: name normalizer already registered", name))
+	}
+	nameNormalizers[name] = fn
+}
+
+// getNameNormalizer returns the function that applies the normalizers
+// in the order given, or nil, if there are none.
+func getNameNormalizer(names []string) (NameNormalizer, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	normalizerMutex.RLock()
+	defer normalizerMutex.RUnlock()
+	chain := make([]NameNormalizer, len(names))
+	for i, name := range names {
+		fn, ok := nameNormalizers[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown name normalizer %q", name)
+		}
+		chain[i] = fn
+	}
+	return func(s string) string {
+		for _, fn := range chain {
+			s = fn(s)
+		}
+		return s
+	}, nil
+}
+
+// withNameNormalizer returns the sanitizer followed by the normalizer.
+func withNameNormalizer(sanitize NameSanitizer, normalize NameNormalizer) NameSanitizer {
+	switch {
+	case normalize == nil:
+		return sanitize
+	case sanitize == nil:
+		return NameSanitizer(normalize)
+	}
+	return func(s string) string { return normalize(sanitize(s)) }
+}
+
+var rustHashPattern = regexp.MustCompile(`::h[0-9a-f]{16}$`)
+
+var rustEscapes = strings.NewReplacer(
+	"$SP$", "@",
+	"$BP$", "*",
+	"$RF$", "&",
+	"$LT$", "<",
+	"$GT$", ">",
+	"$LP$", "(",
+	"$RP$", ")",
+	"$C$", ",",
+	"$u20$", " ",
+	"$u27$", "'",
+	"$u5b$", "[",
+	"$u5d$", "]",
+	"$u7b$", "{",
+	"$u7d$", "}",
+	"$u7e$", "~",
+	"..", "::",
+)
+
+// demangle decodes the nested names of the Itanium C++ ABI, which the
+// legacy Rust mangling is based on: _ZN4core3ptr13drop_in_place17h...E
+// becomes core::ptr::drop_in_place. The hash suffix of Rust symbols is
+// removed, including the one of a name that is already demangled. Names
+// with parameters, e.g. the ones of C++ functions, are left intact.
+func demangle(name string) string {
+	s, ok := strings.CutPrefix(name, "_ZN")
+	if !ok {
+		// Mach-O symbols have an extra underscore.
+		if s, ok = strings.CutPrefix(name, "__ZN"); !ok {
+			return rustHashPattern.ReplaceAllString(name, "")
+		}
+	}
+	var parts []string
+	for len(s) > 0 && s[0] != 'E' {
+		i := 0
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		n, err := strconv.Atoi(s[:i])
+		if err != nil || n == 0 || i+n > len(s) {
+			return name
+		}
+		part := s[i : i+n]
+		if strings.HasPrefix(part, "_$") {
+			part = part[1:]
+		}
+		parts = append(parts, rustEscapes.Replace(part))
+		s = s[i+n:]
+	}
+	if len(parts) == 0 || len(s) == 0 {
+		return name
+	}
+	// Anything but a suffix, e.g. ".llvm.1234",
+	// following the name is the parameters.
+	if rest := s[1:]; rest != "" && rest[0] != '.' {
+		return name
+	}
+	return rustHashPattern.ReplaceAllString(strings.Join(parts, "::"), "")
+}
+
+var goMajorVersionPattern = regexp.MustCompile(`[./]v[0-9]+([./])`)
+
+// stripVersion removes the version suffixes of the names: the ELF symbol
+// versions, e.g. memcpy@@GLIBC_2.14, and the major versions of Go module
+// paths, e.g. github.com/foo/bar/v2.Baz or gopkg.in/yaml.v3.Unmarshal.
+func stripVersion(name string) string {
+	if i := strings.IndexByte(name, '@'); i > 0 && !strings.ContainsAny(name[i:], " ()") {
+		name = name[:i]
+	}
+	if strings.Contains(name, "v") {
+		name = goMajorVersionPattern.ReplaceAllString(name, "$1")
+	}
+	return name
+}
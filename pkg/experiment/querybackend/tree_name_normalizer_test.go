@@ -0,0 +1,100 @@
+package querybackend
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+func Test_NameNormalizers(t *testing.T) {
+	for _, tc := range []struct {
+		normalizer string
+		input      string
+		expected   string
+	}{
+		{NameNormalizerLowercase, "MyApp.Handler.ServeHTTP", "myapp.handler.servehttp"},
+		{NameNormalizerDemangle, "main.main", "main.main"},
+		{NameNormalizerDemangle, "_ZN4core3ptr13drop_in_place17h0123456789abcdefE", "core::ptr::drop_in_place"},
+		{NameNormalizerDemangle, "__ZN3std2rt10lang_start17h0123456789abcdefE", "std::rt::lang_start"},
+		{NameNormalizerDemangle, "_ZN5alloc3vec12Vec$LT$T$GT$4push17h0123456789abcdefE.llvm.42", "alloc::vec::Vec<T>::push"},
+		{NameNormalizerDemangle, "_ZN66_$LT$alloc..vec..Vec$LT$T$GT$$u20$as$u20$core..ops..drop..Drop$GT$4dropE", "<alloc::vec::Vec<T> as core::ops::drop::Drop>::drop"},
+		{NameNormalizerDemangle, "core::ptr::drop_in_place::h0123456789abcdef", "core::ptr::drop_in_place"},
+		{NameNormalizerDemangle, "_ZN3foo3barEi", "_ZN3foo3barEi"},
+		{NameNormalizerDemangle, "_ZN3foo", "_ZN3foo"},
+		{NameNormalizerStripVersion, "memcpy@@GLIBC_2.14", "memcpy"},
+		{NameNormalizerStripVersion, "pthread_cond_wait@GLIBC_2.3.2", "pthread_cond_wait"},
+		{NameNormalizerStripVersion, "github.com/foo/bar/v2.(*Client).Do", "github.com/foo/bar.(*Client).Do"},
+		{NameNormalizerStripVersion, "gopkg.in/yaml.v3.Unmarshal", "gopkg.in/yaml.Unmarshal"},
+		{NameNormalizerStripVersion, "github.com/foo/bar/v2/baz.Qux", "github.com/foo/bar/baz.Qux"},
+		{NameNormalizerStripVersion, "main.(*server).v2Handler", "main.(*server).v2Handler"},
+	} {
+		fn, err := getNameNormalizer([]string{tc.normalizer})
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, fn(tc.input), tc.input)
+		// The normalizers are idempotent.
+		assert.Equal(t, tc.expected, fn(tc.expected), tc.input)
+	}
+
+	_, err := getNameNormalizer([]string{NameNormalizerLowercase, "unknown"})
+	require.ErrorContains(t, err, "unknown name normalizer")
+	fn, err := getNameNormalizer(nil)
+	require.NoError(t, err)
+	require.Nil(t, fn)
+}
+
+func Test_NameNormalizer_Aggregation(t *testing.T) {
+	query := &querybackendv1.TreeQuery{
+		NameNormalizers: []string{NameNormalizerStripVersion, NameNormalizerLowercase},
+		ValueMerge:      TreeValueMergeMax,
+	}
+	report := func(tree *model.Tree) *querybackendv1.Report {
+		return &querybackendv1.Report{
+			ReportType: querybackendv1.ReportType_REPORT_TREE,
+			Tree:       &querybackendv1.TreeReport{Query: query, Tree: tree.Bytes(-1)},
+		}
+	}
+	a := newTreeAggregator(&querybackendv1.InvokeRequest{
+		Query: []*querybackendv1.Query{{QueryType: querybackendv1.QueryType_QUERY_TREE, Tree: query}},
+	})
+	// The partial trees are expected to be normalized
+	// already; the aggregator normalizes them anyway.
+	x := new(model.Tree)
+	x.InsertStack(2, "main", "memcpy@@GLIBC_2.14")
+	x.InsertStack(3, "main", "memcpy@GLIBC_2.2.5")
+	y := new(model.Tree)
+	y.InsertStack(4, "Main", "memcpy")
+	require.NoError(t, a.aggregate(report(x)))
+	require.NoError(t, a.aggregate(report(y)))
+
+	expected := new(model.Tree)
+	// The partial trees are combined after normalization: max(2+3, 4),
+	// rather than 2+3+4, if the merged tree were normalized.
+	expected.InsertStack(5, "main", "memcpy")
+	require.Equal(t, expected.String(), model.MustUnmarshalTree(a.build().Tree.Tree).String())
+}
+
+func Test_QueryTree_NameNormalizers(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	queryTree := func(query *querybackendv1.TreeQuery) (*model.Tree, error) {
+		return queryTestTree(reader, blocks, `{service_name=~".+", __type__="cpu"}`, query)
+	}
+
+	_, err := queryTree(&querybackendv1.TreeQuery{NameNormalizers: []string{"unknown"}})
+	require.Error(t, err)
+
+	full, err := queryTree(new(querybackendv1.TreeQuery))
+	require.NoError(t, err)
+	normalized, err := queryTree(&querybackendv1.TreeQuery{NameNormalizers: []string{NameNormalizerLowercase}})
+	require.NoError(t, err)
+	require.Equal(t, full.Total(), normalized.Total())
+	normalized.IterateStacks(func(_ string, _ int64, stack []string) {
+		for _, name := range stack {
+			require.Equal(t, name, strings.ToLower(name))
+		}
+	})
+}
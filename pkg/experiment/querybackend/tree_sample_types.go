@@ -0,0 +1,96 @@
+package querybackend
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/prometheus/prometheus/storage"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+var errSampleTypeUnitMismatch = errors.New("sample type unit mismatch")
+
+type treeSampleType struct {
+	typ  string
+	unit string
+}
+
+// querySampleTypes returns the sample types of the series
+// matching the selector, ordered by type and unit.
+func querySampleTypes(q *queryContext) ([]*querybackendv1.TreeSampleType, error) {
+	reader := q.ds.Index()
+	postings, err := getPostings(reader, q.req.matchers...)
+	if err != nil {
+		return nil, err
+	}
+	types := newTreeSampleTypes()
+	var t treeSampleType
+	for postings.Next() {
+		if t.typ, err = reader.LabelValueFor(postings.At(), model.LabelNameType); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return nil, err
+		}
+		if t.unit, err = reader.LabelValueFor(postings.At(), model.LabelNameUnit); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return nil, err
+		}
+		types.types[t] = struct{}{}
+	}
+	if err = postings.Err(); err != nil {
+		return nil, err
+	}
+	return types.proto(), nil
+}
+
+// treeSampleTypes is a set of the sample types of
+// tree reports. It is safe for concurrent use.
+type treeSampleTypes struct {
+	m     sync.Mutex
+	types map[treeSampleType]struct{}
+	// The first unit seen of each sample type.
+	units map[string]string
+}
+
+func newTreeSampleTypes() *treeSampleTypes {
+	return &treeSampleTypes{
+		types: make(map[treeSampleType]struct{}),
+		units: make(map[string]string),
+	}
+}
+
+// merge adds the sample types of the report to the set. Unless forced,
+// it fails if a sample type has a unit different from the one seen.
+func (s *treeSampleTypes) merge(types []*querybackendv1.TreeSampleType, force bool) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for _, x := range types {
+		unit, ok := s.units[x.Type]
+		if !ok {
+			s.units[x.Type] = x.Unit
+		} else if unit != x.Unit && !force {
+			return fmt.Errorf("%w: sample type %q is reported in %q and %q", errSampleTypeUnitMismatch, x.Type, unit, x.Unit)
+		}
+		s.types[treeSampleType{typ: x.Type, unit: x.Unit}] = struct{}{}
+	}
+	return nil
+}
+
+func (s *treeSampleTypes) proto() []*querybackendv1.TreeSampleType {
+	if len(s.types) == 0 {
+		return nil
+	}
+	types := make([]*querybackendv1.TreeSampleType, 0, len(s.types))
+	for t := range s.types {
+		types = append(types, &querybackendv1.TreeSampleType{Type: t.typ, Unit: t.unit})
+	}
+	slices.SortFunc(types, func(a, b *querybackendv1.TreeSampleType) int {
+		if c := cmp.Compare(a.Type, b.Type); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Unit, b.Unit)
+	})
+	return types
+}
@@ -0,0 +1,101 @@
+package querybackend
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// NameSanitizer transforms a resolved frame name. Frames that
+// are sanitized to the same name are merged into a single node.
+type NameSanitizer func(string) string
+
+const (
+	NameSanitizerStripGenerics  = "strip_generics"
+	NameSanitizerStripAddresses = "strip_addresses"
+)
+
+var (
+	sanitizerMutex = new(sync.RWMutex)
+	nameSanitizers = map[string]NameSanitizer{}
+)
+
+func init() {
+	RegisterNameSanitizer(NameSanitizerStripGenerics, stripGenerics)
+	RegisterNameSanitizer(NameSanitizerStripAddresses, stripAddresses)
+}
+
+// RegisterNameSanitizer registers a named frame name sanitizer that can
+// be referenced in TreeQuery.NameSanitizer. The function must be called
+// at initialization, before the query backend starts serving requests.
+func RegisterNameSanitizer(name string, fn NameSanitizer) {
+	sanitizerMutex.Lock()
+	defer sanitizerMutex.Unlock()
+	if _, ok := nameSanitizers[name]; ok {
+		panic(fmt.Sprintf("%s: name sanitizer already registered", name))
+	}
+	nameSanitizers[name] = fn
+}
+
+func getNameSanitizer(name string) (NameSanitizer, error) {
+	sanitizerMutex.RLock()
+	defer sanitizerMutex.RUnlock()
+	fn, ok := nameSanitizers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown name sanitizer %q", name)
+	}
+	return fn, nil
+}
+
+// stripGenerics removes type parameters and template arguments,
+// including nested ones: std::vector<std::pair<int, int>>::push_back
+// becomes std::vector<...>::push_back.
+func stripGenerics(name string) string {
+	if strings.IndexByte(name, '<') < 0 {
+		return name
+	}
+	var b strings.Builder
+	b.Grow(len(name))
+	var depth int
+	for i := 0; i < len(name); i++ {
+		switch c := name[i]; c {
+		case '<':
+			if depth == 0 {
+				b.WriteString("<...>")
+			}
+			depth++
+		case '>':
+			if depth == 0 {
+				// Not a bracket pair, e.g. operator->.
+				b.WriteByte(c)
+				continue
+			}
+			depth--
+		default:
+			if depth == 0 {
+				b.WriteByte(c)
+			}
+		}
+	}
+	return b.String()
+}
+
+var addressPattern = regexp.MustCompile(`\s*\+?0x[0-9a-fA-F]+`)
+
+// stripAddresses removes hexadecimal addresses and offsets,
+// e.g. "foo+0x1a" or "[0x7f01e3a0]".
+func stripAddresses(name string) string {
+	if !strings.Contains(name, "0x") {
+		return name
+	}
+	s := addressPattern.ReplaceAllString(name, "")
+	s = strings.ReplaceAll(s, "[]", "")
+	s = strings.TrimSpace(s)
+	if s == "" {
+		// The frame name is an address: there's nothing
+		// to keep, therefore we leave it as is.
+		return name
+	}
+	return s
+}
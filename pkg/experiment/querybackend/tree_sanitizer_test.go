@@ -0,0 +1,57 @@
+package querybackend
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+func Test_NameSanitizers(t *testing.T) {
+	for _, tc := range []struct {
+		sanitizer string
+		input     string
+		expected  string
+	}{
+		{NameSanitizerStripGenerics, "main.main", "main.main"},
+		{NameSanitizerStripGenerics, "std::vector<int>::push_back", "std::vector<...>::push_back"},
+		{NameSanitizerStripGenerics, "std::map<std::string, std::vector<int>>::find", "std::map<...>::find"},
+		{NameSanitizerStripGenerics, "slices.Sort[go.shape.int]", "slices.Sort[go.shape.int]"},
+		{NameSanitizerStripGenerics, "foo::operator->", "foo::operator->"},
+		{NameSanitizerStripAddresses, "main.main", "main.main"},
+		{NameSanitizerStripAddresses, "foo+0x1a", "foo"},
+		{NameSanitizerStripAddresses, "libc.so [0x7f01e3a0]", "libc.so"},
+		{NameSanitizerStripAddresses, "0x7f01e3a0", "0x7f01e3a0"},
+	} {
+		fn, err := getNameSanitizer(tc.sanitizer)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, fn(tc.input), tc.sanitizer)
+	}
+}
+
+func Test_NameSanitizer_MergesNodes(t *testing.T) {
+	tree := new(model.Tree)
+	tree.InsertStack(1, "main", "std::vector<int>::push_back", "malloc+0x1a")
+	tree.InsertStack(2, "main", "std::vector<long>::push_back", "malloc+0x2b")
+	tree.InsertStack(3, "main", "std::map<int, int>::find")
+
+	fn, err := getNameSanitizer(NameSanitizerStripGenerics)
+	require.NoError(t, err)
+	tree.FormatNodeNames(fn)
+	fn, err = getNameSanitizer(NameSanitizerStripAddresses)
+	require.NoError(t, err)
+	tree.FormatNodeNames(fn)
+
+	expected := new(model.Tree)
+	expected.InsertStack(3, "main", "std::vector<...>::push_back", "malloc")
+	expected.InsertStack(3, "main", "std::map<...>::find")
+	assert.Equal(t, expected.String(), tree.String())
+	assert.Equal(t, int64(6), tree.Total())
+}
+
+func Test_NameSanitizer_Unknown(t *testing.T) {
+	_, err := getNameSanitizer("unknown")
+	require.Error(t, err)
+}
@@ -0,0 +1,158 @@
+package querybackend
+
+import (
+	"github.com/grafana/dskit/runutil"
+	"github.com/prometheus/prometheus/model/relabel"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+// sourceLocation is the location of a function in the source code.
+type sourceLocation struct {
+	file string
+	line int64
+}
+
+// less orders the locations, so that the choice between the
+// conflicting locations does not depend on the merge order.
+// Known locations precede the unknown ones.
+func (l sourceLocation) less(x sourceLocation) bool {
+	if (l.file == "") != (x.file == "") {
+		return x.file == ""
+	}
+	if l.file != x.file {
+		return l.file < x.file
+	}
+	return l.line < x.line
+}
+
+// functionSourceLocations returns the source locations of the functions
+// referenced by the stack trace partitions of the matching profiles.
+// The functions are identified by name, as the tree nodes are.
+func functionSourceLocations(
+	q *queryContext,
+	rules []*relabel.Config,
+	sanitize NameSanitizer,
+) (locations map[string]sourceLocation, err error) {
+	entries, err := profileEntryIterator(q, rules)
+	if err != nil {
+		return nil, err
+	}
+	defer runutil.CloseWithErrCapture(&err, entries, "failed to close profile entry iterator")
+	partitions := make(map[uint64]struct{})
+	for entries.Next() {
+		partitions[entries.At().Partition] = struct{}{}
+	}
+	if err = entries.Err(); err != nil {
+		return nil, err
+	}
+
+	locations = make(map[string]sourceLocation)
+	for p := range partitions {
+		partition, err := q.ds.Symbols().Partition(q.ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		s := partition.Symbols()
+		for _, f := range s.Functions {
+			name := s.Strings[f.Name]
+			if sanitize != nil {
+				name = sanitize(name)
+			}
+			loc := sourceLocation{file: s.Strings[f.Filename], line: int64(f.StartLine)}
+			if x, ok := locations[name]; !ok || loc.less(x) {
+				locations[name] = loc
+			}
+		}
+		partition.Release()
+	}
+	return locations, nil
+}
+
+// treeSourceLocations holds the source locations of the
+// tree nodes. It is not safe for concurrent use.
+type treeSourceLocations struct {
+	root sourceLocationNode
+}
+
+type sourceLocationNode struct {
+	children map[string]*sourceLocationNode
+	location sourceLocation
+}
+
+func newTreeSourceLocations() *treeSourceLocations {
+	return new(treeSourceLocations)
+}
+
+func (n *sourceLocationNode) child(name string) *sourceLocationNode {
+	if n.children == nil {
+		n.children = make(map[string]*sourceLocationNode)
+	}
+	c, ok := n.children[name]
+	if !ok {
+		c = new(sourceLocationNode)
+		n.children[name] = c
+	}
+	return c
+}
+
+func (n *sourceLocationNode) set(loc sourceLocation) {
+	if loc.less(n.location) {
+		n.location = loc
+	}
+}
+
+// addTree sets the source locations of the tree nodes
+// to the locations of the functions they are named after.
+func (s *treeSourceLocations) addTree(t *model.Tree, locations map[string]sourceLocation) {
+	t.IterateStacks(func(_ string, _ int64, stack []string) {
+		n := &s.root
+		for i := len(stack) - 1; i >= 0; i-- {
+			n = n.child(stack[i])
+			n.set(locations[stack[i]])
+		}
+	})
+}
+
+func (s *treeSourceLocations) merge(p *querybackendv1.TreeSourceLocations) {
+	nodes := make([]*sourceLocationNode, len(p.Nodes))
+	for i, x := range p.Nodes {
+		parent := &s.root
+		if x.Parent >= 0 && int(x.Parent) < i {
+			parent = nodes[x.Parent]
+		}
+		n := parent.child(x.Name)
+		n.set(sourceLocation{file: x.File, line: x.Line})
+		nodes[i] = n
+	}
+}
+
+// proto returns the source locations of the nodes present in the tree.
+func (s *treeSourceLocations) proto(t *model.Tree) *querybackendv1.TreeSourceLocations {
+	p := new(querybackendv1.TreeSourceLocations)
+	visited := make(map[*sourceLocationNode]int32)
+	t.IterateStacks(func(_ string, _ int64, stack []string) {
+		n := &s.root
+		parent := int32(-1)
+		for i := len(stack) - 1; i >= 0; i-- {
+			name := stack[i]
+			if n = n.children[name]; n == nil {
+				return
+			}
+			j, ok := visited[n]
+			if !ok {
+				j = int32(len(p.Nodes))
+				visited[n] = j
+				p.Nodes = append(p.Nodes, &querybackendv1.TreeNodeSourceLocation{
+					Parent: parent,
+					Name:   name,
+					File:   n.location.file,
+					Line:   n.location.line,
+				})
+			}
+			parent = j
+		}
+	})
+	return p
+}
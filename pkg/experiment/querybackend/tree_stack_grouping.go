@@ -0,0 +1,146 @@
+package querybackend
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+// StackGrouping maps a frame to the name of its group, e.g. "database"
+// or "serialization". Consecutive frames of the same group are collapsed
+// into a single node.
+type StackGrouping func(frame string) string
+
+var (
+	stackGroupingMutex = new(sync.RWMutex)
+	stackGroupings     = map[string]StackGrouping{}
+)
+
+// RegisterStackGrouping registers a named stack grouping function that can
+// be referenced in TreeQuery.StackGrouping. The function must be called at
+// initialization, before the query backend starts serving requests. The
+// groupings of the query backend configuration take precedence.
+func RegisterStackGrouping(name string, fn StackGrouping) {
+	stackGroupingMutex.Lock()
+	defer stackGroupingMutex.Unlock()
+	if _, ok := stackGroupings[name]; ok {
+		panic(fmt.Sprintf("%s: stack grouping already registered", name))
+	}
+	stackGroupings[name] = fn
+}
+
+func getStackGrouping(name string) (StackGrouping, error) {
+	stackGroupingMutex.RLock()
+	defer stackGroupingMutex.RUnlock()
+	fn, ok := stackGroupings[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown stack grouping %q", name)
+	}
+	return fn, nil
+}
+
+// StackGroupingConfig is a stack grouping defined in the query backend
+// configuration: a frame belongs to the group of the first rule matching
+// the frame, or to the default group, if none of the rules match.
+type StackGroupingConfig struct {
+	// Name of the grouping, as referenced in TreeQuery.StackGrouping.
+	Name  string              `yaml:"name"`
+	Rules []StackGroupingRule `yaml:"rules"`
+	// The group of the frames not matching any of the rules.
+	// If empty, the frames are kept as is.
+	Default string `yaml:"default"`
+}
+
+type StackGroupingRule struct {
+	Group string `yaml:"group"`
+	// Function is the regular expression the frames are matched
+	// against. The expression is fully anchored.
+	Function string `yaml:"function"`
+}
+
+func (c *StackGroupingConfig) compile() (StackGrouping, error) {
+	if c.Name == "" {
+		return nil, fmt.Errorf("stack grouping name is required")
+	}
+	if len(c.Rules) == 0 {
+		return nil, fmt.Errorf("stack grouping %q: no rules", c.Name)
+	}
+	groups := make([]string, len(c.Rules))
+	functions := make([]*regexp.Regexp, len(c.Rules))
+	for i, r := range c.Rules {
+		if r.Group == "" {
+			return nil, fmt.Errorf("stack grouping %q: rule group is required", c.Name)
+		}
+		var err error
+		if functions[i], err = regexp.Compile("^(?:" + r.Function + ")$"); err != nil {
+			return nil, fmt.Errorf("stack grouping %q: invalid function expression: %w", c.Name, err)
+		}
+		groups[i] = r.Group
+	}
+	def := c.Default
+	return func(frame string) string {
+		for i, fn := range functions {
+			if fn.MatchString(frame) {
+				return groups[i]
+			}
+		}
+		if def != "" {
+			return def
+		}
+		return frame
+	}, nil
+}
+
+// compileStackGroupings returns the stack groupings of
+// the configuration, by name.
+func compileStackGroupings(configs []StackGroupingConfig) (map[string]StackGrouping, error) {
+	groupings := make(map[string]StackGrouping, len(configs))
+	for i := range configs {
+		fn, err := configs[i].compile()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := groupings[configs[i].Name]; ok {
+			return nil, fmt.Errorf("duplicate stack grouping %q", configs[i].Name)
+		}
+		groupings[configs[i].Name] = fn
+	}
+	return groupings, nil
+}
+
+// stackGrouping returns the stack grouping of the configuration,
+// or the registered one, if the configuration does not define it.
+func (q *queryContext) stackGrouping(name string) (StackGrouping, error) {
+	if fn, ok := q.stackGroupings[name]; ok {
+		return fn, nil
+	}
+	return getStackGrouping(name)
+}
+
+// groupStacks returns the tree of the groups of the frames of the tree.
+func groupStacks(t *model.Tree, fn StackGrouping) *model.Tree {
+	grouped := new(model.Tree)
+	// The frames are shared by many stacks.
+	groups := make(map[string]string)
+	var stack []string
+	t.IterateStacks(func(_ string, self int64, s []string) {
+		// The stack is given leaf first, while the tree
+		// expects the root first.
+		stack = stack[:0]
+		for i := len(s) - 1; i >= 0; i-- {
+			g, ok := groups[s[i]]
+			if !ok {
+				g = fn(s[i])
+				groups[s[i]] = g
+			}
+			if len(stack) > 0 && stack[len(stack)-1] == g {
+				continue
+			}
+			stack = append(stack, g)
+		}
+		grouped.InsertStack(self, stack...)
+	})
+	return grouped
+}
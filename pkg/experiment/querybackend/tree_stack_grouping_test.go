@@ -0,0 +1,145 @@
+package querybackend
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+const testStackGroupingPackage = "test_package"
+
+func init() {
+	RegisterStackGrouping(testStackGroupingPackage, func(frame string) string {
+		pkg, _, _ := strings.Cut(frame, ".")
+		return pkg
+	})
+}
+
+func Test_GroupStacks(t *testing.T) {
+	tree := new(model.Tree)
+	tree.InsertStack(1, "main.main", "db.Query", "db.exec", "net.Write")
+	tree.InsertStack(2, "main.main", "json.Marshal", "json.encode")
+	tree.InsertStack(3, "main.main", "main.handle", "db.Query")
+
+	fn, err := getStackGrouping(testStackGroupingPackage)
+	require.NoError(t, err)
+	expected := new(model.Tree)
+	expected.InsertStack(1, "main", "db", "net")
+	expected.InsertStack(2, "main", "json")
+	expected.InsertStack(3, "main", "db")
+	assert.Equal(t, expected.String(), groupStacks(tree, fn).String())
+}
+
+func Test_StackGrouping_Unknown(t *testing.T) {
+	_, err := getStackGrouping("unknown")
+	require.Error(t, err)
+}
+
+func Test_QueryTree_StackGrouping(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	queryTree := func(query *querybackendv1.TreeQuery) *model.Tree {
+		tree, err := queryTestTree(reader, blocks, `{service_name=~".+", __type__="cpu"}`, query)
+		require.NoError(t, err)
+		return tree
+	}
+
+	tree := queryTree(new(querybackendv1.TreeQuery))
+	grouped := queryTree(&querybackendv1.TreeQuery{StackGrouping: testStackGroupingPackage})
+	require.Equal(t, tree.Total(), grouped.Total())
+	require.Less(t, grouped.Size(), tree.Size())
+	grouped.IterateStacks(func(_ string, _ int64, stack []string) {
+		for i := 1; i < len(stack); i++ {
+			require.NotEqual(t, stack[i-1], stack[i])
+		}
+	})
+
+	_, err := reader.Invoke(context.Background(), newTestInvokeRequest(
+		blocks,
+		`{service_name=~".+"}`,
+		&querybackendv1.Query{
+			QueryType: querybackendv1.QueryType_QUERY_TREE,
+			Tree:      &querybackendv1.TreeQuery{StackGrouping: "unknown"},
+		},
+	))
+	require.Error(t, err)
+}
+
+func Test_StackGroupingConfig(t *testing.T) {
+	groupings, err := compileStackGroupings([]StackGroupingConfig{
+		{
+			Name: "layers",
+			Rules: []StackGroupingRule{
+				{Group: "database", Function: `db\..*`},
+				{Group: "serialization", Function: `json\..*|proto\..*`},
+			},
+			Default: "business logic",
+		},
+		{
+			Name:  "database",
+			Rules: []StackGroupingRule{{Group: "database", Function: `db\..*`}},
+		},
+	})
+	require.NoError(t, err)
+
+	tree := new(model.Tree)
+	tree.InsertStack(1, "main.main", "db.Query", "db.exec")
+	tree.InsertStack(2, "main.main", "json.Marshal", "main.encode")
+	// The expression is anchored.
+	tree.InsertStack(3, "main.main", "main.db.Query")
+
+	expected := new(model.Tree)
+	expected.InsertStack(1, "business logic", "database")
+	expected.InsertStack(2, "business logic", "serialization", "business logic")
+	expected.InsertStack(3, "business logic")
+	assert.Equal(t, expected.String(), groupStacks(tree, groupings["layers"]).String())
+
+	// With no default group, the frames are kept as is.
+	expected = new(model.Tree)
+	expected.InsertStack(1, "main.main", "database")
+	expected.InsertStack(2, "main.main", "json.Marshal", "main.encode")
+	expected.InsertStack(3, "main.main", "main.db.Query")
+	assert.Equal(t, expected.String(), groupStacks(tree, groupings["database"]).String())
+
+	for _, invalid := range [][]StackGroupingConfig{
+		{{Rules: []StackGroupingRule{{Group: "g", Function: "f"}}}},
+		{{Name: "a"}},
+		{{Name: "a", Rules: []StackGroupingRule{{Function: "f"}}}},
+		{{Name: "a", Rules: []StackGroupingRule{{Group: "g", Function: "("}}}},
+		{
+			{Name: "a", Rules: []StackGroupingRule{{Group: "g", Function: "f"}}},
+			{Name: "a", Rules: []StackGroupingRule{{Group: "g", Function: "f"}}},
+		},
+	} {
+		_, err = compileStackGroupings(invalid)
+		require.Error(t, err)
+	}
+}
+
+func Test_QueryTree_StackGroupingConfig(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	var err error
+	reader.stackGroupings, err = compileStackGroupings([]StackGroupingConfig{{
+		Name:    "runtime",
+		Rules:   []StackGroupingRule{{Group: "runtime", Function: `runtime\..*`}},
+		Default: "other",
+	}})
+	require.NoError(t, err)
+
+	tree, err := queryTestTree(reader, blocks, `{service_name=~".+", __type__="cpu"}`, new(querybackendv1.TreeQuery))
+	require.NoError(t, err)
+	grouped, err := queryTestTree(reader, blocks, `{service_name=~".+", __type__="cpu"}`,
+		&querybackendv1.TreeQuery{StackGrouping: "runtime"})
+	require.NoError(t, err)
+	require.Equal(t, tree.Total(), grouped.Total())
+	grouped.IterateStacks(func(_ string, _ int64, stack []string) {
+		for _, frame := range stack {
+			require.Contains(t, []string{"runtime", "other"}, frame)
+		}
+	})
+}
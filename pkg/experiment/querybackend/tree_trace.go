@@ -0,0 +1,109 @@
+package querybackend
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+// TreeTraceHandler returns the HTTP handler that invokes a tree query and
+// writes the resulting tree in the Chrome trace event format, which can be
+// opened in the Perfetto UI or chrome://tracing. The request body is an
+// InvokeRequest in the protobuf JSON format, with a single tree query;
+// the tenants are taken from the request context, as in TreeJSONHandler.
+//
+// nil is returned, unless the endpoint is enabled in the configuration.
+func (q *QueryBackend) TreeTraceHandler() http.Handler {
+	if !q.config.TreeTraceExport {
+		return nil
+	}
+	return http.HandlerFunc(q.serveTreeTrace)
+}
+
+func (q *QueryBackend) serveTreeTrace(w http.ResponseWriter, r *http.Request) {
+	tree, report, ok := q.invokeTreeQuery(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = writeTreeTrace(w, tree, report.GetSampleTypes())
+}
+
+// treeTrace is a trace in the Chrome trace event format.
+type treeTrace struct {
+	TraceEvents []treeTraceEvent `json:"traceEvents"`
+	OtherData   map[string]any   `json:"otherData,omitempty"`
+}
+
+// treeTraceEvent is a complete event ("X"): the timestamp
+// and the duration are in microseconds.
+type treeTraceEvent struct {
+	Name      string         `json:"name"`
+	Phase     string         `json:"ph"`
+	Timestamp float64        `json:"ts"`
+	Duration  float64        `json:"dur"`
+	PID       int            `json:"pid"`
+	TID       int            `json:"tid"`
+	Args      treeTraceValue `json:"args"`
+}
+
+type treeTraceValue struct {
+	Self  int64 `json:"self"`
+	Total int64 `json:"total"`
+}
+
+// writeTreeTrace writes the tree as a trace, where each node is an event
+// spanning its total value, and the events of the children are laid out
+// one after another within the event of the parent, so that the trace
+// viewer renders the tree as a flame chart. If the values of the tree are
+// in nanoseconds, the durations are the actual time; otherwise, a unit of
+// the value is represented as a microsecond. The nodes with non-positive
+// values, e.g. of delta trees, are not included.
+func writeTreeTrace(w io.Writer, tree *model.Tree, sampleTypes []*querybackendv1.TreeSampleType) error {
+	scale := 1.0
+	unit := treeTraceUnit(sampleTypes)
+	if unit == "nanoseconds" {
+		scale = 1e-3
+	}
+	t := treeTrace{
+		TraceEvents: []treeTraceEvent{},
+		OtherData:   map[string]any{"unit": unit},
+	}
+	var add func(nodes []*model.TreeNode, offset int64)
+	add = func(nodes []*model.TreeNode, offset int64) {
+		for _, n := range nodes {
+			if n.Total <= 0 {
+				continue
+			}
+			t.TraceEvents = append(t.TraceEvents, treeTraceEvent{
+				Name:      n.Name,
+				Phase:     "X",
+				Timestamp: float64(offset) * scale,
+				Duration:  float64(n.Total) * scale,
+				PID:       1,
+				TID:       1,
+				Args:      treeTraceValue{Self: n.Self, Total: n.Total},
+			})
+			add(n.Children, offset)
+			offset += n.Total
+		}
+	}
+	add(tree.Nodes(), 0)
+	return json.NewEncoder(w).Encode(t)
+}
+
+// treeTraceUnit returns the unit of the values, if all
+// the sample types have the same one, or an empty string.
+func treeTraceUnit(sampleTypes []*querybackendv1.TreeSampleType) string {
+	var unit string
+	for i, t := range sampleTypes {
+		if i > 0 && t.Unit != unit {
+			return ""
+		}
+		unit = t.Unit
+	}
+	return unit
+}
@@ -0,0 +1,83 @@
+package querybackend
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+	"github.com/grafana/pyroscope/pkg/tenant"
+)
+
+func Test_WriteTreeTrace(t *testing.T) {
+	tree := new(model.Tree)
+	tree.InsertStack(2000, "main", "a")
+	tree.InsertStack(1000, "main", "b", "c")
+	tree.InsertStack(500, "main")
+
+	var buf bytes.Buffer
+	require.NoError(t, writeTreeTrace(&buf, tree, []*querybackendv1.TreeSampleType{{Type: "cpu", Unit: "nanoseconds"}}))
+	var actual treeTrace
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &actual))
+	event := func(name string, ts, dur float64, self, total int64) treeTraceEvent {
+		return treeTraceEvent{
+			Name:      name,
+			Phase:     "X",
+			Timestamp: ts,
+			Duration:  dur,
+			PID:       1,
+			TID:       1,
+			Args:      treeTraceValue{Self: self, Total: total},
+		}
+	}
+	// The events of the children are laid out within the parent one.
+	require.Equal(t, []treeTraceEvent{
+		event("main", 0, 3.5, 500, 3500),
+		event("a", 0, 2, 2000, 2000),
+		event("b", 2, 1, 0, 1000),
+		event("c", 2, 1, 1000, 1000),
+	}, actual.TraceEvents)
+	require.Equal(t, "nanoseconds", actual.OtherData["unit"])
+
+	// The values of other units are not scaled.
+	buf.Reset()
+	require.NoError(t, writeTreeTrace(&buf, tree, []*querybackendv1.TreeSampleType{{Type: "alloc_space", Unit: "bytes"}}))
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &actual))
+	require.Equal(t, 3500.0, actual.TraceEvents[0].Duration)
+}
+
+func Test_TreeTraceHandler(t *testing.T) {
+	require.Nil(t, newTestQueryBackend(t, Config{}, new(testBlockReader)).TreeTraceHandler())
+
+	b := newTestQueryBackend(t, Config{TreeTraceExport: true}, new(testBlockReader))
+	h := b.TreeTraceHandler()
+	require.NotNil(t, h)
+
+	serve := func(req *querybackendv1.InvokeRequest) *httptest.ResponseRecorder {
+		body, err := protojson.Marshal(req)
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/query-backend/export/tree-trace", bytes.NewReader(body))
+		h.ServeHTTP(w, r.WithContext(tenant.InjectTenantID(r.Context(), "tenant")))
+		return w
+	}
+
+	req := newTestTreeRequest("a", "b")
+	w := serve(req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var actual treeTrace
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &actual))
+	// main, a, b
+	require.Len(t, actual.TraceEvents, 3)
+	require.Equal(t, "main", actual.TraceEvents[0].Name)
+	require.Equal(t, 2.0, actual.TraceEvents[0].Duration)
+
+	req.Query[0].QueryType = querybackendv1.QueryType_QUERY_LABEL_NAMES
+	require.Equal(t, http.StatusBadRequest, serve(req).Code)
+}
@@ -0,0 +1,311 @@
+package querybackend
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+	"github.com/grafana/pyroscope/pkg/phlaredb/symdb"
+)
+
+// valueExpression is an arithmetic expression over the sample value
+// columns, e.g. "alloc_space / alloc_objects". The columns are referred
+// to by the sample type of the profile. The expression supports +, -,
+// *, / operators, parentheses, unary minus, and numeric constants.
+type valueExpression struct {
+	// Columns referred to in the expression, in the order of appearance.
+	columns []string
+	root    valueExprNode
+}
+
+// valueExprNode evaluates the expression given the column values;
+// false is returned if the result is undefined, e.g. on division by zero.
+type valueExprNode func(values []float64) (float64, bool)
+
+var errInvalidValueExpression = errors.New("invalid value expression")
+
+func parseValueExpression(s string) (*valueExpression, error) {
+	p := valueExprParser{input: s}
+	e := new(valueExpression)
+	p.expr = e
+	p.next()
+	root, err := p.parseSum()
+	if err == nil && p.tok.kind != tokEOF {
+		err = p.errorf("unexpected %q", p.tok.text)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(e.columns) == 0 {
+		return nil, fmt.Errorf("%w: no value columns referenced", errInvalidValueExpression)
+	}
+	e.root = root
+	return e, nil
+}
+
+// eval evaluates the expression. The values are given in the order
+// of the columns. If the result is undefined, or can't be represented
+// as an integer, false is returned.
+func (e *valueExpression) eval(values []float64) (int64, bool) {
+	v, ok := e.root(values)
+	if !ok || math.IsNaN(v) || math.IsInf(v, 0) || math.Abs(v) >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(math.Round(v)), true
+}
+
+func (e *valueExpression) column(name string) int {
+	for i, c := range e.columns {
+		if c == name {
+			return i
+		}
+	}
+	e.columns = append(e.columns, name)
+	return len(e.columns) - 1
+}
+
+type valueExprTokenKind int
+
+const (
+	tokEOF valueExprTokenKind = iota
+	tokNumber
+	tokIdent
+	tokOperator
+)
+
+type valueExprToken struct {
+	kind valueExprTokenKind
+	text string
+	pos  int
+}
+
+type valueExprParser struct {
+	input string
+	pos   int
+	tok   valueExprToken
+	expr  *valueExpression
+}
+
+func (p *valueExprParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w at position %d: %s", errInvalidValueExpression, p.tok.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *valueExprParser) next() {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+	start := p.pos
+	if p.pos == len(p.input) {
+		p.tok = valueExprToken{kind: tokEOF, pos: start}
+		return
+	}
+	c := p.input[p.pos]
+	switch {
+	case isIdentByte(c) && !isDigit(c):
+		for p.pos < len(p.input) && isIdentByte(p.input[p.pos]) {
+			p.pos++
+		}
+		p.tok = valueExprToken{kind: tokIdent, text: p.input[start:p.pos], pos: start}
+	case isDigit(c) || c == '.':
+		for p.pos < len(p.input) && (isDigit(p.input[p.pos]) || p.input[p.pos] == '.') {
+			p.pos++
+		}
+		p.tok = valueExprToken{kind: tokNumber, text: p.input[start:p.pos], pos: start}
+	default:
+		p.pos++
+		p.tok = valueExprToken{kind: tokOperator, text: p.input[start:p.pos], pos: start}
+	}
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isIdentByte(c byte) bool {
+	return c == '_' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func (p *valueExprParser) isOperator(ops string) bool {
+	return p.tok.kind == tokOperator && strings.Contains(ops, p.tok.text)
+}
+
+// sum := product (("+" | "-") product)*
+func (p *valueExprParser) parseSum() (valueExprNode, error) {
+	lhs, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("+-") {
+		op := p.tok.text
+		p.next()
+		rhs, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		lhs = binaryValueExprNode(op, lhs, rhs)
+	}
+	return lhs, nil
+}
+
+// product := unary (("*" | "/") unary)*
+func (p *valueExprParser) parseProduct() (valueExprNode, error) {
+	lhs, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("*/") {
+		op := p.tok.text
+		p.next()
+		rhs, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		lhs = binaryValueExprNode(op, lhs, rhs)
+	}
+	return lhs, nil
+}
+
+// unary := "-" unary | number | column | "(" sum ")"
+func (p *valueExprParser) parseUnary() (valueExprNode, error) {
+	switch tok := p.tok; tok.kind {
+	case tokNumber:
+		v, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, p.errorf("invalid number %q", tok.text)
+		}
+		p.next()
+		return func([]float64) (float64, bool) { return v, true }, nil
+	case tokIdent:
+		i := p.expr.column(tok.text)
+		p.next()
+		return func(values []float64) (float64, bool) { return values[i], true }, nil
+	case tokOperator:
+		switch tok.text {
+		case "-":
+			p.next()
+			x, err := p.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			return func(values []float64) (float64, bool) {
+				v, ok := x(values)
+				return -v, ok
+			}, nil
+		case "(":
+			p.next()
+			x, err := p.parseSum()
+			if err != nil {
+				return nil, err
+			}
+			if !p.isOperator(")") {
+				return nil, p.errorf("missing closing parenthesis")
+			}
+			p.next()
+			return x, nil
+		}
+		return nil, p.errorf("unexpected %q", tok.text)
+	default:
+		return nil, p.errorf("unexpected end of expression")
+	}
+}
+
+func binaryValueExprNode(op string, lhs, rhs valueExprNode) valueExprNode {
+	return func(values []float64) (float64, bool) {
+		a, ok := lhs(values)
+		if !ok {
+			return 0, false
+		}
+		b, ok := rhs(values)
+		if !ok {
+			return 0, false
+		}
+		switch op {
+		case "+":
+			return a + b, true
+		case "-":
+			return a - b, true
+		case "*":
+			return a * b, true
+		default:
+			if b == 0 {
+				return 0, false
+			}
+			return a / b, true
+		}
+	}
+}
+
+// treeValueExpression returns the value expression of the query, either
+// given explicitly, or as a value combination; nil, if there is none.
+func treeValueExpression(query *querybackendv1.TreeQuery) (*valueExpression, error) {
+	if s := query.GetValueExpression(); s != "" {
+		return parseValueExpression(s)
+	}
+	if c := query.GetValueCombination(); c != nil {
+		return valueCombinationExpression(c)
+	}
+	return nil, nil
+}
+
+// resolveExpressionTree resolves a tree for each of the columns referenced
+// in the expression, and returns the tree of the expression values, along
+// with the operand trees. The transform function is applied to the operand
+// trees. The values of the expression are not additive, therefore the tree
+// aggregator evaluates the expression once again over the merged operands.
+func resolveExpressionTree(
+	q *queryContext,
+	expr *valueExpression,
+	rules []*relabel.Config,
+	transform func(*model.Tree) *model.Tree,
+	opts ...symdb.ResolverOption,
+) (*model.Tree, *multiValueTree, float64, error) {
+	columns := newMultiValueTree(len(expr.columns))
+	var unsymbolized float64
+	for i, column := range expr.columns {
+		matcher := &labels.Matcher{Type: labels.MatchEqual, Name: model.LabelNameType, Value: column}
+		resolved, u, err := resolveTree(q.withMatchers(matcher), rules, opts...)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		columns.addTree(i, transform(resolved))
+		unsymbolized = max(unsymbolized, u)
+	}
+	return expr.tree(columns), columns, unsymbolized, nil
+}
+
+// tree builds the tree of the expression values evaluated per stack
+// trace of the operand tree. Stack traces the expression is undefined
+// for, e.g. because of division by zero, are not included into the tree.
+func (e *valueExpression) tree(columns *multiValueTree) *model.Tree {
+	tree := new(model.Tree)
+	if columns == nil {
+		return tree
+	}
+	values := make([]float64, len(e.columns))
+	stack := make([]string, 0, 64)
+	var walk func(*multiValueNode)
+	walk = func(n *multiValueNode) {
+		for _, c := range n.children {
+			stack = append(stack, c.name)
+			var sampled bool
+			for i, v := range c.self {
+				values[i] = float64(v)
+				sampled = sampled || v != 0
+			}
+			if sampled {
+				if v, ok := e.eval(values); ok {
+					tree.InsertStack(v, stack...)
+				}
+			}
+			walk(c)
+			stack = stack[:len(stack)-1]
+		}
+	}
+	walk(&columns.root)
+	return tree
+}
@@ -0,0 +1,117 @@
+package querybackend
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+func Test_ValueExpression(t *testing.T) {
+	for _, tc := range []struct {
+		expr     string
+		values   []float64
+		columns  []string
+		expected int64
+		defined  bool
+	}{
+		{expr: "a", values: []float64{3}, columns: []string{"a"}, expected: 3, defined: true},
+		{expr: "a / b", values: []float64{10, 4}, columns: []string{"a", "b"}, expected: 3, defined: true},
+		{expr: "a / b", values: []float64{10, 0}, columns: []string{"a", "b"}, defined: false},
+		{expr: "a + b * 2", values: []float64{1, 2}, columns: []string{"a", "b"}, expected: 5, defined: true},
+		{expr: "(a + b) * 2", values: []float64{1, 2}, columns: []string{"a", "b"}, expected: 6, defined: true},
+		{expr: "a - b - a", values: []float64{1, 2}, columns: []string{"a", "b"}, expected: -2, defined: true},
+		{expr: "-a * 1.5", values: []float64{2}, columns: []string{"a"}, expected: -3, defined: true},
+		{expr: "a / (b - b)", values: []float64{1, 2}, columns: []string{"a", "b"}, defined: false},
+		{expr: "alloc_space/alloc_objects", values: []float64{1024, 2}, columns: []string{"alloc_space", "alloc_objects"}, expected: 512, defined: true},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			e, err := parseValueExpression(tc.expr)
+			require.NoError(t, err)
+			require.Equal(t, tc.columns, e.columns)
+			v, ok := e.eval(tc.values)
+			require.Equal(t, tc.defined, ok)
+			require.Equal(t, tc.expected, v)
+		})
+	}
+
+	for _, expr := range []string{"", "1 + 2", "a +", "(a", "a)", "a b", "a % b", "1..2 * a"} {
+		_, err := parseValueExpression(expr)
+		require.ErrorIs(t, err, errInvalidValueExpression, expr)
+	}
+}
+
+func Test_QueryTree_ValueExpression(t *testing.T) {
+	reader, blocks := newTestBlockReader(t)
+	queryTree := func(selector string, query *querybackendv1.TreeQuery) (*model.Tree, error) {
+		return queryTestTree(reader, blocks, selector, query)
+	}
+
+	space, err := queryTree(`{service_name=~".+", __type__="alloc_space"}`, new(querybackendv1.TreeQuery))
+	require.NoError(t, err)
+	require.Positive(t, space.Total())
+	identity, err := queryTree(`{service_name=~".+"}`, &querybackendv1.TreeQuery{ValueExpression: "alloc_space"})
+	require.NoError(t, err)
+	require.Equal(t, space.String(), identity.String())
+
+	avg, err := queryTree(`{service_name=~".+"}`, &querybackendv1.TreeQuery{ValueExpression: "alloc_space / alloc_objects"})
+	require.NoError(t, err)
+	require.Positive(t, avg.Total())
+	require.Less(t, avg.Total(), space.Total())
+
+	undefined, err := queryTree(`{service_name=~".+"}`, &querybackendv1.TreeQuery{ValueExpression: "alloc_space / 0"})
+	require.NoError(t, err)
+	require.Zero(t, undefined.Total())
+
+	_, err = queryTree(`{service_name=~".+"}`, &querybackendv1.TreeQuery{ValueExpression: "alloc_space /"})
+	require.ErrorContains(t, err, errInvalidValueExpression.Error())
+	_, err = queryTree(`{service_name=~".+"}`, &querybackendv1.TreeQuery{
+		ValueExpression: "alloc_space",
+		ProfileTypes:    []string{"memory:alloc_space:bytes:space:bytes"},
+	})
+	require.Error(t, err)
+}
+
+func Test_TreeAggregator_ValueExpression(t *testing.T) {
+	query := &querybackendv1.TreeQuery{ValueExpression: "a / b"}
+	report := func(a, b int64) *querybackendv1.Report {
+		expr, err := treeValueExpression(query)
+		require.NoError(t, err)
+		operands := newMultiValueTree(len(expr.columns))
+		for i, v := range []int64{a, b} {
+			tree := new(model.Tree)
+			tree.InsertStack(v, "main", "f")
+			operands.addTree(i, tree)
+		}
+		return &querybackendv1.Report{Tree: &querybackendv1.TreeReport{
+			Query:          query,
+			Tree:           expr.tree(operands).Bytes(-1),
+			MultiValueTree: operands.proto(0),
+		}}
+	}
+	aggregate := func(options *querybackendv1.InvokeOptions) *querybackendv1.TreeReport {
+		a := newTreeAggregator(&querybackendv1.InvokeRequest{
+			Query:   []*querybackendv1.Query{{QueryType: querybackendv1.QueryType_QUERY_TREE, Tree: query}},
+			Options: options,
+		})
+		require.NoError(t, a.aggregate(report(1, 1)))
+		require.NoError(t, a.aggregate(report(3, 1)))
+		return a.build().Tree
+	}
+
+	// The expression is evaluated over the merged operands:
+	// (1 + 3) / (1 + 1), rather than 1/1 + 3/1.
+	r := aggregate(nil)
+	expected := new(model.Tree)
+	expected.InsertStack(2, "main", "f")
+	require.Equal(t, expected.String(), model.MustUnmarshalTree(r.Tree).String())
+	require.Nil(t, r.MultiValueTree)
+
+	// The partial reports carry the merged operands.
+	r = aggregate(&querybackendv1.InvokeOptions{Partial: true})
+	require.Zero(t, model.MustUnmarshalTree(r.Tree).Total())
+	require.Len(t, r.MultiValueTree.Nodes, 2)
+	require.Equal(t, []int64{4, 2}, r.MultiValueTree.Nodes[1].Self)
+}
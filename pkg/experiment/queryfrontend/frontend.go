@@ -0,0 +1,183 @@
+package queryfrontend
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	metastoreclient "github.com/grafana/pyroscope/pkg/experiment/metastore/client"
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend"
+	querybackendclient "github.com/grafana/pyroscope/pkg/experiment/querybackend/client"
+	"github.com/grafana/pyroscope/pkg/util"
+	"github.com/grafana/pyroscope/pkg/util/validation"
+)
+
+type Config struct {
+	TreeCacheSize           int           `yaml:"tree_cache_size"`
+	TreeCacheMaxAge         time.Duration `yaml:"tree_cache_max_age"`
+	TreeCacheRefreshTimeout time.Duration `yaml:"tree_cache_refresh_timeout"`
+
+	// Region is the name of the local region in the federation. If remote
+	// regions are configured, the queries are dispatched to all the regions,
+	// and the responses are merged.
+	Region     string         `yaml:"region"`
+	Federation []RegionConfig `yaml:"federation"`
+}
+
+func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
+	f.IntVar(&cfg.TreeCacheSize, "query-frontend.tree-cache-size", 0,
+		"Maximum number of the tree reports cached and served in the stale-while-revalidate mode: "+
+			"once a report is older than the max age, it is served stale while the query is re-run "+
+			"in background. 0 to disable.")
+	f.DurationVar(&cfg.TreeCacheMaxAge, "query-frontend.tree-cache-max-age", 30*time.Second,
+		"Age after which a cached tree report is refreshed. The queries which time ranges are "+
+			"within the same interval of the max age share the cached report.")
+	f.DurationVar(&cfg.TreeCacheRefreshTimeout, "query-frontend.tree-cache-refresh-timeout", time.Minute,
+		"Timeout of the background refreshes of the cached tree reports. 0 to disable.")
+	f.StringVar(&cfg.Region, "query-frontend.region", "",
+		"Name of the local region in the federation. Required if remote regions are configured.")
+}
+
+func (cfg *Config) Validate() error {
+	if cfg.TreeCacheSize < 0 {
+		return fmt.Errorf("query-frontend.tree-cache-size must be non-negative")
+	}
+	if cfg.TreeCacheSize > 0 && cfg.TreeCacheMaxAge <= 0 {
+		return fmt.Errorf("query-frontend.tree-cache-max-age must be positive")
+	}
+	if len(cfg.Federation) > 0 && cfg.Region == "" {
+		return fmt.Errorf("query-frontend.region is required if the federation is configured")
+	}
+	for i := range cfg.Federation {
+		if err := cfg.Federation[i].Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type Limits interface {
+	DefaultQueryLookback(tenantID string) time.Duration
+}
+
+// QueryFrontend executes the queries with the query backend,
+// against the blocks listed in the metastore.
+type QueryFrontend struct {
+	logger log.Logger
+	limits Limits
+	// The queries are planned and executed in the local
+	// region, or in all the regions of the federation.
+	handler querybackend.QueryHandler
+	// Optional.
+	trees *TreeCache
+
+	defaultTimeRangeQueries *prometheus.CounterVec
+}
+
+func New(
+	config Config,
+	logger log.Logger,
+	limits Limits,
+	metastore *metastoreclient.Client,
+	backend *querybackendclient.Client,
+	reg prometheus.Registerer,
+	regions ...querybackend.Region,
+) (*QueryFrontend, error) {
+	q := &QueryFrontend{
+		logger: logger,
+		limits: limits,
+		defaultTimeRangeQueries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "pyroscope",
+			Name:      "query_frontend_default_time_range_queries_total",
+			Help:      "The total number of queries that were executed with the default time range.",
+		}, []string{"tenant"}),
+	}
+	local := NewRegion(logger, RegionConfig{Name: config.Region}, metastore, backend)
+	q.handler = local.Handler
+	if len(regions) > 0 {
+		f, err := querybackend.NewFederation(logger, append([]querybackend.Region{local}, regions...)...)
+		if err != nil {
+			return nil, err
+		}
+		q.handler = f
+	}
+	if config.TreeCacheSize > 0 {
+		var err error
+		q.trees, err = NewTreeCache(logger, config.TreeCacheSize, config.TreeCacheMaxAge, config.TreeCacheRefreshTimeout)
+		if err != nil {
+			return nil, err
+		}
+	}
+	util.Register(reg, q.defaultTimeRangeQueries)
+	return q, nil
+}
+
+// Query executes the query against the blocks matching the time range
+// and the label selector. If no time range is specified, the default
+// time range applies. If the tree cache is enabled, the tree reports
+// may be served from the cache, see TreeCache.
+func (q *QueryFrontend) Query(
+	ctx context.Context,
+	startTime, endTime int64,
+	tenants []string,
+	labelSelector string,
+	query *querybackendv1.Query,
+) (*querybackendv1.Report, error) {
+	startTime, endTime = q.DefaultTimeRange(tenants, startTime, endTime)
+	if q.trees == nil {
+		return q.query(ctx, startTime, endTime, tenants, labelSelector, query)
+	}
+	return q.trees.Query(ctx, startTime, endTime, tenants, labelSelector, query,
+		func(ctx context.Context) (*querybackendv1.Report, error) {
+			return q.query(ctx, startTime, endTime, tenants, labelSelector, query)
+		})
+}
+
+func (q *QueryFrontend) query(
+	ctx context.Context,
+	startTime, endTime int64,
+	tenants []string,
+	labelSelector string,
+	query *querybackendv1.Query,
+) (*querybackendv1.Report, error) {
+	resp, err := q.handler.Invoke(ctx, &querybackendv1.InvokeRequest{
+		Tenant:        tenants,
+		StartTime:     startTime,
+		EndTime:       endTime,
+		LabelSelector: labelSelector,
+		Query:         []*querybackendv1.Query{query},
+		// The heads are only read if the query backend is configured to.
+		Options: &querybackendv1.InvokeOptions{ReadHeads: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return findReport(querybackend.QueryReportType(query.QueryType), resp.Reports), nil
+}
+
+// DefaultTimeRange returns the default time range of the tenants, if
+// the query does not specify one. Otherwise, or if no default lookback
+// is configured, the time range is returned as is. Timestamps are in
+// milliseconds.
+func (q *QueryFrontend) DefaultTimeRange(tenants []string, startTime, endTime int64) (int64, int64) {
+	return q.withDefaultTimeRange(tenants, startTime, endTime, time.Now())
+}
+
+func (q *QueryFrontend) withDefaultTimeRange(tenants []string, startTime, endTime int64, now time.Time) (int64, int64) {
+	if startTime != 0 || endTime != 0 {
+		return startTime, endTime
+	}
+	lookback := validation.SmallestPositiveNonZeroDurationPerTenant(tenants, q.limits.DefaultQueryLookback)
+	if lookback <= 0 {
+		return startTime, endTime
+	}
+	for _, tenant := range tenants {
+		q.defaultTimeRangeQueries.WithLabelValues(tenant).Inc()
+	}
+	return now.Add(-lookback).UnixMilli(), now.UnixMilli()
+}
@@ -0,0 +1,93 @@
+package queryfrontend
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-kit/log"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	metastoreclient "github.com/grafana/pyroscope/pkg/experiment/metastore/client"
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend"
+	querybackendclient "github.com/grafana/pyroscope/pkg/experiment/querybackend/client"
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend/queryplan"
+)
+
+// RegionConfig is a remote region of the federation the queries are
+// dispatched to: the queries are planned against the blocks listed in the
+// metastore of the region, and executed by the query backend of the region.
+type RegionConfig struct {
+	Name                string        `yaml:"name"`
+	MetastoreAddress    string        `yaml:"metastore_address"`
+	QueryBackendAddress string        `yaml:"query_backend_address"`
+	Timeout             time.Duration `yaml:"timeout"`
+}
+
+func (cfg *RegionConfig) Validate() error {
+	if cfg.Name == "" {
+		return fmt.Errorf("query-frontend federation: region name is required")
+	}
+	if cfg.MetastoreAddress == "" {
+		return fmt.Errorf("query-frontend federation: region %s: metastore address is required", cfg.Name)
+	}
+	if cfg.QueryBackendAddress == "" {
+		return fmt.Errorf("query-frontend federation: region %s: query backend address is required", cfg.Name)
+	}
+	if cfg.Timeout < 0 {
+		return fmt.Errorf("query-frontend federation: region %s: timeout must be non-negative", cfg.Name)
+	}
+	return nil
+}
+
+// NewRegion creates the member of the federation that executes the
+// queries with the clients of the region.
+func NewRegion(
+	logger log.Logger,
+	config RegionConfig,
+	metastore *metastoreclient.Client,
+	backend *querybackendclient.Client,
+) querybackend.Region {
+	return querybackend.Region{
+		Name:    config.Name,
+		Timeout: config.Timeout,
+		Handler: &regionHandler{
+			logger:    log.With(logger, "region", config.Name),
+			metastore: metastore,
+			backend:   backend,
+		},
+	}
+}
+
+// regionHandler plans the queries against the blocks listed in the
+// metastore, and executes them with the query backend. The query plan
+// of the request, if any, is ignored.
+type regionHandler struct {
+	logger    log.Logger
+	metastore *metastoreclient.Client
+	backend   *querybackendclient.Client
+}
+
+func (r *regionHandler) Invoke(
+	ctx context.Context,
+	req *querybackendv1.InvokeRequest,
+) (*querybackendv1.InvokeResponse, error) {
+	blocks, err := ListMetadata(ctx, r.metastore, r.logger, req.Tenant, req.StartTime, req.EndTime, req.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+	if len(blocks) == 0 {
+		return new(querybackendv1.InvokeResponse), nil
+	}
+	// Randomize the order of blocks to avoid hotspots.
+	xrand.Shuffle(len(blocks), func(i, j int) {
+		blocks[i], blocks[j] = blocks[j], blocks[i]
+	})
+	// TODO: Params.
+	p := queryplan.Build(blocks, 2, 10)
+	req = req.CloneVT()
+	req.QueryPlan = p.Proto()
+	// The response is streamed in chunks, if the chunk size is
+	// set in the request options or in the query backend config.
+	return querybackend.InvokeChunked(ctx, r.backend, req)
+}
@@ -0,0 +1,32 @@
+package queryfrontend
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Config_Federation(t *testing.T) {
+	region := RegionConfig{
+		Name:                "eu",
+		MetastoreAddress:    "metastore.eu:9095",
+		QueryBackendAddress: "query-backend.eu:9095",
+	}
+	cfg := Config{Region: "us", Federation: []RegionConfig{region}}
+	require.NoError(t, cfg.Validate())
+
+	cfg.Region = ""
+	require.Error(t, cfg.Validate())
+
+	for _, invalid := range []func(*RegionConfig){
+		func(r *RegionConfig) { r.Name = "" },
+		func(r *RegionConfig) { r.MetastoreAddress = "" },
+		func(r *RegionConfig) { r.QueryBackendAddress = "" },
+		func(r *RegionConfig) { r.Timeout = -1 },
+	} {
+		r := region
+		invalid(&r)
+		cfg = Config{Region: "us", Federation: []RegionConfig{r}}
+		require.Error(t, cfg.Validate())
+	}
+}
@@ -15,9 +15,6 @@ import (
 	metastorev1 "github.com/grafana/pyroscope/api/gen/proto/go/metastore/v1"
 	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
 	metastoreclient "github.com/grafana/pyroscope/pkg/experiment/metastore/client"
-	"github.com/grafana/pyroscope/pkg/experiment/querybackend"
-	querybackendclient "github.com/grafana/pyroscope/pkg/experiment/querybackend/client"
-	"github.com/grafana/pyroscope/pkg/experiment/querybackend/queryplan"
 	phlaremodel "github.com/grafana/pyroscope/pkg/model"
 )
 
@@ -90,44 +87,6 @@ func printStats(logger log.Logger, blocks []*metastorev1.BlockMeta) {
 
 var xrand = rand.New(rand.NewSource(4349676827832284783))
 
-func Query(
-	ctx context.Context,
-	startTime, endTime int64,
-	tenants []string,
-	labelSelector string,
-	q *querybackendv1.Query,
-	mc *metastoreclient.Client,
-	qc *querybackendclient.Client,
-	logger log.Logger,
-) (*querybackendv1.Report, error) {
-	blocks, err := ListMetadata(ctx, mc, logger, tenants, startTime, endTime, labelSelector)
-	if err != nil {
-		return nil, err
-	}
-	if len(blocks) == 0 {
-		return nil, nil
-	}
-	// Randomize the order of blocks to avoid hotspots.
-	xrand.Shuffle(len(blocks), func(i, j int) {
-		blocks[i], blocks[j] = blocks[j], blocks[i]
-	})
-	// TODO: Params.
-	p := queryplan.Build(blocks, 2, 10)
-	resp, err := qc.Invoke(ctx, &querybackendv1.InvokeRequest{
-		Tenant:        tenants,
-		StartTime:     startTime,
-		EndTime:       endTime,
-		LabelSelector: labelSelector,
-		Options:       &querybackendv1.InvokeOptions{},
-		QueryPlan:     p.Proto(),
-		Query:         []*querybackendv1.Query{q},
-	})
-	if err != nil {
-		return nil, err
-	}
-	return findReport(querybackend.QueryReportType(q.QueryType), resp.Reports), nil
-}
-
 func BuildLabelSelectorFromMatchers(matchers []string) (string, error) {
 	parsed, err := parseMatchers(matchers)
 	if err != nil {
@@ -0,0 +1,42 @@
+package queryfrontend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/pyroscope/pkg/validation"
+)
+
+func Test_withDefaultTimeRange(t *testing.T) {
+	now := time.UnixMilli(1_700_000_000_000)
+	q, err := New(Config{}, log.NewNopLogger(), validation.MockLimits{DefaultQueryLookbackValue: time.Hour}, nil, nil, nil)
+	require.NoError(t, err)
+
+	t.Run("time range is specified", func(t *testing.T) {
+		start, end := q.withDefaultTimeRange([]string{"a"}, 10, 20, now)
+		assert.Equal(t, int64(10), start)
+		assert.Equal(t, int64(20), end)
+		assert.Zero(t, testutil.ToFloat64(q.defaultTimeRangeQueries.WithLabelValues("a")))
+	})
+
+	t.Run("time range is not specified", func(t *testing.T) {
+		start, end := q.withDefaultTimeRange([]string{"b"}, 0, 0, now)
+		assert.Equal(t, now.Add(-time.Hour).UnixMilli(), start)
+		assert.Equal(t, now.UnixMilli(), end)
+		assert.Equal(t, float64(1), testutil.ToFloat64(q.defaultTimeRangeQueries.WithLabelValues("b")))
+	})
+
+	t.Run("default lookback is disabled", func(t *testing.T) {
+		q, err := New(Config{}, log.NewNopLogger(), validation.MockLimits{}, nil, nil, nil)
+		require.NoError(t, err)
+		start, end := q.withDefaultTimeRange([]string{"c"}, 0, 0, now)
+		assert.Zero(t, start)
+		assert.Zero(t, end)
+		assert.Zero(t, testutil.ToFloat64(q.defaultTimeRangeQueries.WithLabelValues("c")))
+	})
+}
@@ -0,0 +1,172 @@
+package queryfrontend
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	lru "github.com/hashicorp/golang-lru/v2"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+)
+
+// TreeCache holds the last known reports of the tree queries, and serves
+// them in the stale-while-revalidate mode: if the cached report is older
+// than the max age, it is returned immediately, flagged stale, and the
+// query is re-run in the background to refresh the cache. Clients poll for
+// the fresh report by repeating the query.
+//
+// The time range of a query is aligned to the max age: the queries which
+// time ranges are within the same interval share the entry. The report of
+// a relative time range, e.g. the last hour of an auto-refreshing dashboard
+// panel, moves to the next interval eventually: if there is no entry for the
+// time range, the report of the previous interval is served stale, while
+// the query is run in the background.
+type TreeCache struct {
+	logger         log.Logger
+	maxAge         time.Duration
+	refreshTimeout time.Duration
+	now            func() time.Time
+
+	mu         sync.Mutex
+	entries    *lru.Cache[uint64, *treeCacheEntry]
+	refreshing map[uint64]struct{}
+}
+
+type treeCacheEntry struct {
+	report  *querybackendv1.Report
+	updated time.Time
+}
+
+// NewTreeCache creates a cache holding up to size reports. The max age
+// must be positive. The background refreshes are canceled after the
+// refresh timeout, if it is positive.
+func NewTreeCache(logger log.Logger, size int, maxAge, refreshTimeout time.Duration) (*TreeCache, error) {
+	if maxAge <= 0 {
+		return nil, fmt.Errorf("tree cache max age must be positive")
+	}
+	entries, err := lru.New[uint64, *treeCacheEntry](size)
+	if err != nil {
+		return nil, err
+	}
+	return &TreeCache{
+		logger:         logger,
+		maxAge:         maxAge,
+		refreshTimeout: refreshTimeout,
+		now:            time.Now,
+		entries:        entries,
+		refreshing:     make(map[uint64]struct{}),
+	}, nil
+}
+
+// Query returns the report of the query function, or the cached report of
+// the query, if any. Queries other than QUERY_TREE are not cached.
+func (c *TreeCache) Query(
+	ctx context.Context,
+	startTime, endTime int64,
+	tenants []string,
+	labelSelector string,
+	q *querybackendv1.Query,
+	query func(context.Context) (*querybackendv1.Report, error),
+) (*querybackendv1.Report, error) {
+	if q.QueryType != querybackendv1.QueryType_QUERY_TREE {
+		return query(ctx)
+	}
+	interval := max(1, c.maxAge.Milliseconds())
+	start, end := startTime/interval, endTime/interval
+	key, err := treeCacheKey(start, end, tenants, labelSelector, q)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	e, ok := c.entries.Get(key)
+	if ok && c.now().Sub(e.updated) <= c.maxAge {
+		c.mu.Unlock()
+		return e.report.CloneVT(), nil
+	}
+	if !ok {
+		// The time range of the previous interval.
+		prev, err := treeCacheKey(start-1, end-1, tenants, labelSelector, q)
+		if err != nil {
+			c.mu.Unlock()
+			return nil, err
+		}
+		e, ok = c.entries.Get(prev)
+	}
+	if !ok {
+		c.mu.Unlock()
+		return c.refresh(ctx, key, query)
+	}
+	report := e.report.CloneVT()
+	if _, refreshing := c.refreshing[key]; !refreshing {
+		c.refreshing[key] = struct{}{}
+		go c.refreshInBackground(context.WithoutCancel(ctx), key, query)
+	}
+	c.mu.Unlock()
+	report.Tree.Stale = true
+	return report, nil
+}
+
+func (c *TreeCache) refreshInBackground(
+	ctx context.Context,
+	key uint64,
+	query func(context.Context) (*querybackendv1.Report, error),
+) {
+	defer func() {
+		c.mu.Lock()
+		delete(c.refreshing, key)
+		c.mu.Unlock()
+	}()
+	if c.refreshTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.refreshTimeout)
+		defer cancel()
+	}
+	if _, err := c.refresh(ctx, key, query); err != nil {
+		_ = level.Warn(c.logger).Log("msg", "failed to refresh cached tree report", "err", err)
+	}
+}
+
+func (c *TreeCache) refresh(
+	ctx context.Context,
+	key uint64,
+	query func(context.Context) (*querybackendv1.Report, error),
+) (*querybackendv1.Report, error) {
+	report, err := query(ctx)
+	if err != nil || report.GetTree() == nil {
+		return report, err
+	}
+	c.mu.Lock()
+	c.entries.Add(key, &treeCacheEntry{
+		report:  report.CloneVT(),
+		updated: c.now(),
+	})
+	c.mu.Unlock()
+	return report, nil
+}
+
+func treeCacheKey(
+	start, end int64,
+	tenants []string,
+	labelSelector string,
+	q *querybackendv1.Query,
+) (uint64, error) {
+	b, err := q.MarshalVT()
+	if err != nil {
+		return 0, err
+	}
+	h := xxhash.New()
+	_, _ = h.WriteString(strconv.FormatInt(start, 10))
+	_, _ = h.WriteString("\x00" + strconv.FormatInt(end, 10))
+	_, _ = h.WriteString("\x00" + strings.Join(tenants, ","))
+	_, _ = h.WriteString("\x00" + labelSelector)
+	_, _ = h.WriteString("\x00")
+	_, _ = h.Write(b)
+	return h.Sum64(), nil
+}
@@ -0,0 +1,109 @@
+package queryfrontend
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+)
+
+func Test_TreeCache(t *testing.T) {
+	c, err := NewTreeCache(log.NewNopLogger(), 10, time.Minute, 0)
+	require.NoError(t, err)
+	now := time.UnixMilli(1_700_000_000_000)
+	c.now = func() time.Time { return now }
+
+	q := &querybackendv1.Query{
+		QueryType: querybackendv1.QueryType_QUERY_TREE,
+		Tree:      new(querybackendv1.TreeQuery),
+	}
+	refreshed := make(chan struct{}, 1)
+	var version int
+	var fail bool
+	query := func(startTime, endTime int64) (*querybackendv1.Report, error) {
+		return c.Query(context.Background(), startTime, endTime, []string{"t"}, "{}", q,
+			func(context.Context) (*querybackendv1.Report, error) {
+				defer func() { refreshed <- struct{}{} }()
+				if fail {
+					return nil, errors.New("failed")
+				}
+				version++
+				return &querybackendv1.Report{Tree: &querybackendv1.TreeReport{Tree: []byte{byte(version)}}}, nil
+			})
+	}
+	expect := func(r *querybackendv1.Report, err error, version byte, stale bool) {
+		t.Helper()
+		require.NoError(t, err)
+		require.Equal(t, []byte{version}, r.Tree.Tree)
+		require.Equal(t, stale, r.Tree.Stale)
+	}
+	isRefreshing := func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return len(c.refreshing) > 0
+	}
+	const (
+		minute = int64(time.Minute / time.Millisecond)
+		hour   = 60 * minute
+	)
+
+	// The miss is queried synchronously.
+	r, err := query(0, hour)
+	<-refreshed
+	expect(r, err, 1, false)
+
+	// The fresh report of the time range
+	// within the interval is served.
+	r, err = query(10, hour+10)
+	expect(r, err, 1, false)
+
+	// The stale report is served and refreshed in the background.
+	now = now.Add(2 * time.Minute)
+	r, err = query(20, hour+20)
+	expect(r, err, 1, true)
+	<-refreshed
+	require.Eventually(t, func() bool { return !isRefreshing() }, time.Second, time.Millisecond)
+	r, err = query(30, hour+30)
+	expect(r, err, 2, false)
+
+	// The failed refresh is retried.
+	now = now.Add(2 * time.Minute)
+	fail = true
+	r, err = query(40, hour+40)
+	expect(r, err, 2, true)
+	<-refreshed
+	require.Eventually(t, func() bool { return !isRefreshing() }, time.Second, time.Millisecond)
+	fail = false
+	r, err = query(40, hour+40)
+	expect(r, err, 2, true)
+	<-refreshed
+	require.Eventually(t, func() bool { return !isRefreshing() }, time.Second, time.Millisecond)
+
+	// Once the time range moves to the next interval, the
+	// report of the previous one is served stale.
+	r, err = query(minute, hour+minute)
+	expect(r, err, 3, true)
+	<-refreshed
+	require.Eventually(t, func() bool { return !isRefreshing() }, time.Second, time.Millisecond)
+	r, err = query(minute, hour+minute)
+	expect(r, err, 4, false)
+
+	// The report of a time range of another interval is not served.
+	r, err = query(10*minute, hour)
+	<-refreshed
+	expect(r, err, 5, false)
+
+	// Other queries are not cached.
+	q = &querybackendv1.Query{QueryType: querybackendv1.QueryType_QUERY_TIME_SERIES}
+	r, err = query(10*minute, hour)
+	<-refreshed
+	expect(r, err, 6, false)
+
+	_, err = NewTreeCache(log.NewNopLogger(), 10, 0, 0)
+	require.Error(t, err)
+}
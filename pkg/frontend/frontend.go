@@ -29,6 +29,7 @@ import (
 
 	"github.com/grafana/dskit/tenant"
 
+	"github.com/grafana/pyroscope/pkg/experiment/queryfrontend"
 	"github.com/grafana/pyroscope/pkg/frontend/frontendpb"
 	"github.com/grafana/pyroscope/pkg/querier/stats"
 	"github.com/grafana/pyroscope/pkg/scheduler/schedulerdiscovery"
@@ -94,6 +95,13 @@ type Frontend struct {
 	schedulerWorkersWatcher *services.FailureWatcher
 	requests                *requestsInProgress
 	frontendpb.UnimplementedFrontendForQuerierServer
+
+	// If set, the queries supported are executed with the query
+	// backend instead of the queriers.
+	queryBackend *queryfrontend.QueryFrontend
+	// Services the query backend depends on, e.g. the clients
+	// of the remote regions, if any.
+	queryBackendServices *services.Manager
 }
 
 type Limits interface {
@@ -175,7 +183,26 @@ func NewFrontend(cfg Config, limits Limits, log log.Logger, reg prometheus.Regis
 	return f, nil
 }
 
+// EnableQueryBackend makes the frontend execute the queries supported
+// with the query backend. It must be called before the frontend starts.
+// The services given are started and stopped along with the frontend.
+func (f *Frontend) EnableQueryBackend(q *queryfrontend.QueryFrontend, subservices ...services.Service) error {
+	f.queryBackend = q
+	if len(subservices) == 0 {
+		return nil
+	}
+	var err error
+	f.queryBackendServices, err = services.NewManager(subservices...)
+	return err
+}
+
 func (f *Frontend) starting(ctx context.Context) error {
+	if f.queryBackendServices != nil {
+		f.schedulerWorkersWatcher.WatchManager(f.queryBackendServices)
+		if err := services.StartManagerAndAwaitHealthy(ctx, f.queryBackendServices); err != nil {
+			return errors.Wrap(err, "failed to start query backend services")
+		}
+	}
 	f.schedulerWorkersWatcher.WatchService(f.schedulerWorkers)
 
 	return errors.Wrap(services.StartAndAwaitRunning(ctx, f.schedulerWorkers), "failed to start frontend scheduler workers")
@@ -191,7 +218,13 @@ func (f *Frontend) running(ctx context.Context) error {
 }
 
 func (f *Frontend) stopping(_ error) error {
-	return errors.Wrap(services.StopAndAwaitTerminated(context.Background(), f.schedulerWorkers), "failed to stop frontend scheduler workers")
+	err := errors.Wrap(services.StopAndAwaitTerminated(context.Background(), f.schedulerWorkers), "failed to stop frontend scheduler workers")
+	if f.queryBackendServices != nil {
+		if stopErr := services.StopManagerAndAwaitStopped(context.Background(), f.queryBackendServices); stopErr != nil && err == nil {
+			err = errors.Wrap(stopErr, "failed to stop query backend services")
+		}
+	}
+	return err
 }
 
 // allow to test the frontend without the need of a real roundertripper
@@ -12,6 +12,9 @@ import (
 
 	querierv1 "github.com/grafana/pyroscope/api/gen/proto/go/querier/v1"
 	"github.com/grafana/pyroscope/api/gen/proto/go/querier/v1/querierv1connect"
+	querybackendv1 "github.com/grafana/pyroscope/api/gen/proto/go/querybackend/v1"
+	"github.com/grafana/pyroscope/pkg/experiment/querybackend"
+	"github.com/grafana/pyroscope/pkg/experiment/queryfrontend"
 	phlaremodel "github.com/grafana/pyroscope/pkg/model"
 	"github.com/grafana/pyroscope/pkg/util/connectgrpc"
 	validationutil "github.com/grafana/pyroscope/pkg/util/validation"
@@ -52,6 +55,9 @@ func (f *Frontend) selectMergeStacktracesTree(ctx context.Context,
 	if err != nil {
 		return nil, connect.NewError(connect.CodeInvalidArgument, err)
 	}
+	if f.queryBackend != nil {
+		return f.selectMergeStacktracesTreeQueryBackend(ctx, tenantIDs, c.Msg)
+	}
 
 	validated, err := validation.ValidateRangeRequest(f.limits, tenantIDs, model.Interval{Start: model.Time(c.Msg.Start), End: model.Time(c.Msg.End)}, model.Now())
 	if err != nil {
@@ -107,3 +113,45 @@ func (f *Frontend) selectMergeStacktracesTree(ctx context.Context,
 
 	return m.Tree(), nil
 }
+
+// selectMergeStacktracesTreeQueryBackend executes the query with the query
+// backend. Unlike the queriers, the query backend does not need the time
+// range to be split: the query plan covers the blocks of the whole range.
+// Queries that do not specify the time range cover the default lookback.
+func (f *Frontend) selectMergeStacktracesTreeQueryBackend(
+	ctx context.Context,
+	tenantIDs []string,
+	req *querierv1.SelectMergeStacktracesRequest,
+) (*phlaremodel.Tree, error) {
+	start, end := f.queryBackend.DefaultTimeRange(tenantIDs, req.Start, req.End)
+	validated, err := validation.ValidateRangeRequest(f.limits, tenantIDs, model.Interval{Start: model.Time(start), End: model.Time(end)}, model.Now())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	if validated.IsEmpty {
+		return new(phlaremodel.Tree), nil
+	}
+	maxNodes, err := validation.ValidateMaxNodes(f.limits, tenantIDs, req.GetMaxNodes())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	labelSelector, err := queryfrontend.BuildLabelSelectorWithProfileType(req.LabelSelector, req.ProfileTypeID)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	report, err := f.queryBackend.Query(ctx,
+		int64(validated.Start), int64(validated.End),
+		tenantIDs, labelSelector,
+		&querybackendv1.Query{
+			QueryType: querybackendv1.QueryType_QUERY_TREE,
+			Tree:      &querybackendv1.TreeQuery{MaxNodes: maxNodes},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if report == nil {
+		return new(phlaremodel.Tree), nil
+	}
+	return querybackend.UnmarshalReportTree(report.Tree)
+}
@@ -543,6 +543,22 @@ func (m *mockScheduler) FrontendLoop(ctx context.Context, frontend *connect.Bidi
 	}
 }
 
+func TestFrontendQueryBackendServices(t *testing.T) {
+	cfg := Config{}
+	flagext.DefaultValues(&cfg)
+	cfg.SchedulerAddress = "localhost:0"
+	f, err := NewFrontend(cfg, validation.MockLimits{MaxQueryParallelismValue: 1}, log.NewNopLogger(), nil)
+	require.NoError(t, err)
+	svc := services.NewIdleService(nil, nil)
+	require.NoError(t, f.EnableQueryBackend(nil, svc))
+
+	// The services run along with the frontend.
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), f))
+	require.Equal(t, services.Running, svc.State())
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), f))
+	require.Equal(t, services.Terminated, svc.State())
+}
+
 func TestConfig_Validate(t *testing.T) {
 	tests := map[string]struct {
 		setup       func(cfg *Config)
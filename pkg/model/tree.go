@@ -57,8 +57,28 @@ func (t *Tree) Total() (v int64) {
 	return v
 }
 
+// checkedTotal returns the tree total, and false, if
+// the total overflows a 64-bit signed integer.
+func (t *Tree) checkedTotal() (int64, bool) {
+	var v int64
+	for _, n := range t.root {
+		var ok bool
+		if v, ok = addInt64(v, n.total); !ok {
+			return 0, false
+		}
+	}
+	return v, true
+}
+
+func addInt64(a, b int64) (int64, bool) {
+	s := a + b
+	return s, (s > a) == (b > 0)
+}
+
+// InsertStack adds the value to the stack trace given root-first.
+// The value may be negative, e.g. in delta profiles.
 func (t *Tree) InsertStack(v int64, stack ...string) {
-	if v <= 0 {
+	if v == 0 {
 		return
 	}
 	r := &node{children: t.root}
@@ -107,7 +127,7 @@ func (t *Tree) IterateStacks(cb func(name string, self int64, stack []string)) {
 		n := nodes[0]
 		self := n.self
 		label := n.name
-		if self > 0 {
+		if self != 0 {
 			current := n
 			stack = stack[:0]
 			for current != nil && current.parent != nil {
@@ -121,6 +141,16 @@ func (t *Tree) IterateStacks(cb func(name string, self int64, stack []string)) {
 	}
 }
 
+// Inverted returns a copy of the tree built leaf-first: the roots are the
+// functions with non-zero self values, and the children are their callers.
+func (t *Tree) Inverted() *Tree {
+	inverted := new(Tree)
+	t.IterateStacks(func(_ string, self int64, stack []string) {
+		inverted.InsertStack(self, stack...)
+	})
+	return inverted
+}
+
 // Default Depth First Search slice capacity. The value should be equal
 // to the number of all the siblings of the tree leaf ascendants.
 //
@@ -129,11 +159,13 @@ func (t *Tree) IterateStacks(cb func(name string, self int64, stack []string)) {
 const defaultDFSSize = 128
 
 func (t *Tree) Merge(src *Tree) {
-	if t.Total() == 0 && src.Total() > 0 {
+	// Note that the tree total may be zero or negative,
+	// if the tree includes negative values.
+	if len(t.root) == 0 {
 		*t = *src
 		return
 	}
-	if src.Total() == 0 {
+	if len(src.root) == 0 {
 		return
 	}
 
@@ -229,6 +261,221 @@ func (t *Tree) Fix() {
 	t.root = r.children
 }
 
+// FilterNodes removes the nodes with total values outside of the range
+// [minValue, maxValue], unless they have descendants within the range.
+// The values of the removed nodes are accounted in the "other" child of
+// the parent node, therefore the remaining node totals do not change.
+// If maxValue is zero, the range has no upper bound.
+func (t *Tree) FilterNodes(minValue, maxValue int64) {
+	if len(t.root) == 0 {
+		return
+	}
+	r := &node{children: t.root}
+	for _, n := range r.children {
+		n.parent = r
+	}
+	filterNodes(r, minValue, maxValue)
+	t.root = r.children
+}
+
+// filterNodes filters the children of the node, and reports
+// whether the node or any of its descendants is within the range.
+func filterNodes(n *node, minValue, maxValue int64) bool {
+	var found bool
+	var other int64
+	j := 0
+	for _, c := range n.children {
+		if filterNodes(c, minValue, maxValue) {
+			n.children[j] = c
+			j++
+			found = true
+			continue
+		}
+		other += c.total
+	}
+	n.children = n.children[:j]
+	if other != 0 {
+		o := n.insert(truncatedNodeName)
+		o.self += other
+		o.total += other
+	}
+	return found || (n.total >= minValue && (maxValue == 0 || n.total <= maxValue))
+}
+
+// LimitDepth removes the nodes below the given depth: the values
+// of the removed nodes are accounted in the self values of their
+// ancestors at the maximum depth. The roots are at depth 1.
+func (t *Tree) LimitDepth(maxDepth int) {
+	if maxDepth <= 0 {
+		return
+	}
+	nodes := t.root
+	for depth := 1; len(nodes) > 0; depth++ {
+		if depth == maxDepth {
+			for _, n := range nodes {
+				n.self = n.total
+				n.children = nil
+			}
+			return
+		}
+		next := make([]*node, 0, len(nodes))
+		for _, n := range nodes {
+			next = append(next, n.children...)
+		}
+		nodes = next
+	}
+}
+
+// DropZeroNodes removes the nodes with zero self values that have no
+// descendants with non-zero values. Note that a node with zero total
+// is retained, if its descendants have values cancelling each other
+// out, as it is the case in delta profiles. Node totals do not change.
+func (t *Tree) DropZeroNodes() {
+	t.root = dropZeroNodes(t.root)
+}
+
+func dropZeroNodes(nodes []*node) []*node {
+	j := 0
+	for _, n := range nodes {
+		n.children = dropZeroNodes(n.children)
+		if n.self != 0 || len(n.children) > 0 {
+			nodes[j] = n
+			j++
+		}
+	}
+	clear(nodes[j:])
+	return nodes[:j]
+}
+
+// HottestPath removes all the nodes but the ones of the hottest path:
+// the root with the highest total value, and, at each level, the child
+// with the highest total value. Ties are broken by the node name. The
+// node totals do not change: the self value of a node on the path is
+// the difference between its total and the total of its child.
+func (t *Tree) HottestPath() {
+	n := hottestNode(t.root)
+	if n == nil {
+		return
+	}
+	t.root = []*node{n}
+	for {
+		c := hottestNode(n.children)
+		if c == nil {
+			n.self = n.total
+			return
+		}
+		n.children = []*node{c}
+		n.self = n.total - c.total
+		n = c
+	}
+}
+
+func hottestNode(nodes []*node) *node {
+	var h *node
+	for _, n := range nodes {
+		if h == nil || n.total > h.total || (n.total == h.total && n.name < h.name) {
+			h = n
+		}
+	}
+	return h
+}
+
+// LimitFunctions retains the given number of functions with the highest
+// total values: the frames of the remaining functions are replaced with
+// the "other" node, and consecutive "other" frames are merged. The value
+// of a function is only counted once per stack trace, regardless of the
+// number of its frames. The tree total does not change.
+func (t *Tree) LimitFunctions(maxFunctions int) {
+	if maxFunctions <= 0 {
+		return
+	}
+	totals := make(map[string]int64)
+	seen := make(map[string]struct{})
+	t.IterateStacks(func(_ string, self int64, stack []string) {
+		clear(seen)
+		for _, name := range stack {
+			if _, ok := seen[name]; !ok {
+				seen[name] = struct{}{}
+				totals[name] += self
+			}
+		}
+	})
+	delete(totals, truncatedNodeName)
+	if len(totals) <= maxFunctions {
+		return
+	}
+	names := make([]string, 0, len(totals))
+	for name := range totals {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := abs(totals[names[i]]), abs(totals[names[j]])
+		if a != b {
+			return a > b
+		}
+		return names[i] < names[j]
+	})
+	retained := make(map[string]struct{}, maxFunctions)
+	for _, name := range names[:maxFunctions] {
+		retained[name] = struct{}{}
+	}
+	limited := new(Tree)
+	folded := make([]string, 0, 64)
+	t.IterateStacks(func(_ string, self int64, stack []string) {
+		folded = folded[:0]
+		// The stack starts at the leaf.
+		for i := len(stack) - 1; i >= 0; i-- {
+			name := stack[i]
+			if _, ok := retained[name]; !ok {
+				name = truncatedNodeName
+			}
+			if name == truncatedNodeName && len(folded) > 0 && folded[len(folded)-1] == truncatedNodeName {
+				continue
+			}
+			folded = append(folded, name)
+		}
+		limited.InsertStack(self, folded...)
+	})
+	t.root = limited.root
+}
+
+// TreeNode is the exported representation of a tree node,
+// intended for debugging purposes, e.g. JSON serialization.
+type TreeNode struct {
+	Name     string      `json:"name"`
+	Self     int64       `json:"self"`
+	Total    int64       `json:"total"`
+	Children []*TreeNode `json:"children,omitempty"`
+}
+
+// Nodes returns a copy of the tree roots and their descendants.
+func (t *Tree) Nodes() []*TreeNode {
+	return exportNodes(t.root)
+}
+
+func exportNodes(nodes []*node) []*TreeNode {
+	if len(nodes) == 0 {
+		return nil
+	}
+	exported := make([]*TreeNode, len(nodes))
+	for i, n := range nodes {
+		exported[i] = &TreeNode{
+			Name:     n.name,
+			Self:     n.self,
+			Total:    n.total,
+			Children: exportNodes(n.children),
+		}
+	}
+	return exported
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
 func (n *node) String() string {
 	return fmt.Sprintf("{%s: self %d total %d}", n.name, n.self, n.total)
 }
@@ -249,8 +496,9 @@ func (n *node) insert(name string) *node {
 	return child
 }
 
-// minValue returns the minimum "total" value a node in a tree has to have to show up in
-// the resulting flamegraph
+// minValue returns the minimum magnitude of the "total" value a node in a tree has to
+// have to show up in the resulting flamegraph. Negative values, e.g. in delta profiles,
+// are as significant as positive ones of the same magnitude.
 func (t *Tree) minValue(maxNodes int64) int64 {
 	if maxNodes < 1 {
 		return 0
@@ -269,13 +517,13 @@ func (t *Tree) minValue(maxNodes int64) int64 {
 		last := len(nodes) - 1
 		n, nodes = nodes[last], nodes[:last]
 		if len(h) >= int(maxNodes) {
-			if n.total > h[0] {
+			if abs(n.total) > h[0] {
 				h = minheap.Pop(h)
 			} else {
 				continue
 			}
 		}
-		h = minheap.Push(h, n.total)
+		h = minheap.Push(h, abs(n.total))
 		nodes = append(nodes, n.children...)
 	}
 
@@ -286,6 +534,11 @@ func (t *Tree) minValue(maxNodes int64) int64 {
 	return h[0]
 }
 
+// Size reports the number of nodes the tree consists of.
+func (t *Tree) Size() int64 {
+	return t.size(make([]*node, 0, defaultDFSSize))
+}
+
 // size reports number of nodes the tree consists of.
 // Provided buffer used for DFS traversal.
 func (t *Tree) size(buf []*node) int64 {
@@ -305,6 +558,18 @@ const truncatedNodeName = "other"
 
 var truncatedNodeNameBytes = []byte(truncatedNodeName)
 
+// Tree serialization format versions. The version is not stored
+// in the serialized tree and must be known to the decoder.
+const (
+	// TreeFormatV1 stores node names inline.
+	TreeFormatV1 = 1
+	// TreeFormatV2 stores the table of unique node names
+	// followed by the nodes referencing the names by index.
+	TreeFormatV2 = 2
+
+	TreeFormatLatest = TreeFormatV2
+)
+
 // Bytes returns marshaled tree byte representation; the number of nodes
 // is limited to maxNodes. The function modifies the tree: truncated nodes
 // are removed from the tree in place.
@@ -314,15 +579,91 @@ func (t *Tree) Bytes(maxNodes int64) []byte {
 	return buf.Bytes()
 }
 
+// BytesVersion is like Bytes, but the tree is serialized in the
+// format of the given version.
+func (t *Tree) BytesVersion(maxNodes int64, version int) []byte {
+	var buf bytes.Buffer
+	_ = t.MarshalTruncateVersion(&buf, maxNodes, version)
+	return buf.Bytes()
+}
+
 // MarshalTruncate writes tree byte representation to the writer provider,
 // the number of nodes is limited to maxNodes. The function modifies
 // the tree: truncated nodes are removed from the tree.
 func (t *Tree) MarshalTruncate(w io.Writer, maxNodes int64) (err error) {
+	vw := varint.NewWriter()
+	return t.truncate(maxNodes, func(n *node) error {
+		if _, err = vw.Write(w, uint64(len(n.name))); err != nil {
+			return err
+		}
+		if _, err = w.Write(unsafeStringBytes(n.name)); err != nil {
+			return err
+		}
+		if _, err = vw.Write(w, uint64(n.self)); err != nil {
+			return err
+		}
+		_, err = vw.Write(w, uint64(len(n.children)))
+		return err
+	})
+}
+
+// MarshalTruncateVersion is like MarshalTruncate, but the tree
+// is serialized in the format of the given version.
+func (t *Tree) MarshalTruncateVersion(w io.Writer, maxNodes int64, version int) (err error) {
+	switch version {
+	case TreeFormatV1:
+		return t.MarshalTruncate(w, maxNodes)
+	case TreeFormatV2:
+	default:
+		return fmt.Errorf("unsupported tree format version %d", version)
+	}
+	var nodes []*node
+	names := make(map[string]uint64)
+	table := make([]string, 0, 64)
+	if err = t.truncate(maxNodes, func(n *node) error {
+		if _, ok := names[n.name]; !ok {
+			names[n.name] = uint64(len(table))
+			table = append(table, n.name)
+		}
+		nodes = append(nodes, n)
+		return nil
+	}); err != nil || len(nodes) == 0 {
+		return err
+	}
+	vw := varint.NewWriter()
+	if _, err = vw.Write(w, uint64(len(table))); err != nil {
+		return err
+	}
+	for _, name := range table {
+		if _, err = vw.Write(w, uint64(len(name))); err != nil {
+			return err
+		}
+		if _, err = w.Write(unsafeStringBytes(name)); err != nil {
+			return err
+		}
+	}
+	for _, n := range nodes {
+		if _, err = vw.Write(w, names[n.name]); err != nil {
+			return err
+		}
+		if _, err = vw.Write(w, uint64(n.self)); err != nil {
+			return err
+		}
+		if _, err = vw.Write(w, uint64(len(n.children))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// truncate removes the nodes that do not fit into maxNodes, replacing
+// them with the "other" node, and calls fn for each of the remaining
+// nodes, including the virtual root, in the order of serialization.
+func (t *Tree) truncate(maxNodes int64, fn func(*node) error) error {
 	if len(t.root) == 0 {
 		return nil
 	}
 
-	vw := varint.NewWriter()
 	minVal := t.minValue(maxNodes)
 	nodes := make([]*node, 1, defaultDFSSize)
 	nodes[0] = &node{children: t.root} // Virtual root node.
@@ -331,20 +672,11 @@ func (t *Tree) MarshalTruncate(w io.Writer, maxNodes int64) (err error) {
 	for len(nodes) > 0 {
 		last := len(nodes) - 1
 		n, nodes = nodes[last], nodes[:last]
-		if _, _ = vw.Write(w, uint64(len(n.name))); err != nil {
-			return err
-		}
-		if _, _ = w.Write(unsafeStringBytes(n.name)); err != nil {
-			return err
-		}
-		if _, err = vw.Write(w, uint64(n.self)); err != nil {
-			return err
-		}
 
 		var other int64
 		var j int
 		for _, cn := range n.children {
-			if cn.total >= minVal || cn.name == truncatedNodeName {
+			if abs(cn.total) >= minVal || cn.name == truncatedNodeName {
 				n.children[j] = cn
 				j++
 			} else {
@@ -353,7 +685,7 @@ func (t *Tree) MarshalTruncate(w io.Writer, maxNodes int64) (err error) {
 		}
 
 		n.children = n.children[:j]
-		if other > 0 {
+		if other != 0 {
 			o := n.insert(truncatedNodeName)
 			o.total += other
 			o.self += other
@@ -362,7 +694,7 @@ func (t *Tree) MarshalTruncate(w io.Writer, maxNodes int64) (err error) {
 		if len(n.children) > 0 {
 			nodes = append(nodes, n.children...)
 		}
-		if _, err = vw.Write(w, uint64(len(n.children))); err != nil {
+		if err := fn(n); err != nil {
 			return err
 		}
 	}
@@ -374,6 +706,20 @@ var errMalformedTreeBytes = fmt.Errorf("malformed tree bytes")
 
 const estimateBytesPerNode = 16 // Chosen empirically.
 
+// minBytesPerNode is the size of a node with an empty name and
+// no children: name length, value, and the number of children.
+const minBytesPerNode = 3
+
+func internString(names map[string]string, b []byte) string {
+	// The lookup does not allocate.
+	if s, ok := names[string(b)]; ok {
+		return s
+	}
+	s := string(b)
+	names[s] = s
+	return s
+}
+
 func MustUnmarshalTree(b []byte) *Tree {
 	if len(b) == 0 {
 		return new(Tree)
@@ -386,10 +732,69 @@ func MustUnmarshalTree(b []byte) *Tree {
 }
 
 func UnmarshalTree(b []byte) (*Tree, error) {
-	t := new(Tree)
+	return unmarshalTree(b, nil)
+}
+
+// UnmarshalTreeVersion decodes the tree serialized
+// in the format of the given version.
+func UnmarshalTreeVersion(b []byte, version int) (*Tree, error) {
+	return unmarshalTreeVersion(b, nil, version)
+}
+
+// unmarshalTree decodes the tree. If the names table is provided,
+// node names are interned: identical names share the same storage.
+func unmarshalTree(b []byte, names map[string]string) (*Tree, error) {
 	if len(b) < 2 {
-		return t, nil
+		return new(Tree), nil
+	}
+	return unmarshalTreeNodes(b, names, nil)
+}
+
+func unmarshalTreeVersion(b []byte, names map[string]string, version int) (*Tree, error) {
+	switch version {
+	case TreeFormatV1:
+		return unmarshalTree(b, names)
+	case TreeFormatV2:
+	default:
+		return nil, fmt.Errorf("unsupported tree format version %d", version)
+	}
+	if len(b) == 0 {
+		return new(Tree), nil
+	}
+	table, offset, err := unmarshalTreeNames(b, names)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalTreeNodes(b[offset:], names, table)
+}
+
+func unmarshalTreeNames(b []byte, names map[string]string) ([]string, int, error) {
+	n, offset := dvarint.Uvarint(b)
+	// Each name takes at least one byte.
+	if offset <= 0 || n > uint64(len(b)-offset) {
+		return nil, 0, errMalformedTreeBytes
+	}
+	table := make([]string, n)
+	for i := range table {
+		nameLen, o := dvarint.Uvarint(b[offset:])
+		if o <= 0 || nameLen > uint64(len(b)-offset-o) {
+			return nil, 0, errMalformedTreeBytes
+		}
+		offset += o
+		if names != nil {
+			table[i] = internString(names, b[offset:offset+int(nameLen)])
+		} else {
+			table[i] = string(b[offset : offset+int(nameLen)])
+		}
+		offset += int(nameLen)
 	}
+	return table, offset, nil
+}
+
+// unmarshalTreeNodes decodes the tree nodes. If the table is provided,
+// nodes reference names by index (v2), otherwise names are inline (v1).
+func unmarshalTreeNodes(b []byte, names map[string]string, table []string) (*Tree, error) {
+	t := new(Tree)
 	size := estimateBytesPerNode
 	if e := len(b) / estimateBytesPerNode; e > estimateBytesPerNode {
 		size = e
@@ -403,22 +808,9 @@ func UnmarshalTree(b []byte) (*Tree, error) {
 
 	for len(parents) > 0 {
 		parent, parents = parents[len(parents)-1], parents[:len(parents)-1]
-		nameLen, o := dvarint.Uvarint(b[offset:])
-		if o < 0 {
-			return nil, errMalformedTreeBytes
-		}
-		offset += o
-		// Note that we allocate a string, instead of referencing b's capacity.
-		name := string(b[offset : offset+int(nameLen)])
-		offset += int(nameLen)
-		value, o := dvarint.Uvarint(b[offset:])
-		if o < 0 {
-			return nil, errMalformedTreeBytes
-		}
-		offset += o
-		childrenLen, o := dvarint.Uvarint(b[offset:])
-		if o < 0 {
-			return nil, errMalformedTreeBytes
+		name, value, childrenLen, o, err := readTreeNode(b[offset:], names, table, len(parents))
+		if err != nil {
+			return nil, err
 		}
 		offset += o
 
@@ -437,8 +829,57 @@ func UnmarshalTree(b []byte) (*Tree, error) {
 		}
 	}
 
-	// Remove the virtual root.
+	// Remove the virtual root. Note that the
+	// stack traces must not include the root node.
+	root.children[0].parent = nil
 	t.root = root.children[0].children
 
 	return t, nil
 }
+
+// readTreeNode decodes the node at the beginning of b, and returns its
+// name, value, the number of children, and the number of bytes read.
+// If the table is provided, the node references the name by index (v2),
+// otherwise the name is inline (v1). pending is the number of the nodes
+// announced, but not decoded yet.
+func readTreeNode(b []byte, names map[string]string, table []string, pending int) (name string, value, children uint64, offset int, err error) {
+	if table != nil {
+		x, o := dvarint.Uvarint(b)
+		if o <= 0 || x >= uint64(len(table)) {
+			return "", 0, 0, 0, errMalformedTreeBytes
+		}
+		offset += o
+		name = table[x]
+	} else {
+		nameLen, o := dvarint.Uvarint(b)
+		if o <= 0 || nameLen > uint64(len(b)-o) {
+			return "", 0, 0, 0, errMalformedTreeBytes
+		}
+		offset += o
+		// Note that we allocate a string, instead of referencing b's capacity.
+		if names != nil {
+			name = internString(names, b[offset:offset+int(nameLen)])
+		} else {
+			name = string(b[offset : offset+int(nameLen)])
+		}
+		offset += int(nameLen)
+	}
+	// Negative values are encoded in two's complement.
+	value, o := dvarint.Uvarint(b[offset:])
+	if o <= 0 {
+		return "", 0, 0, 0, errMalformedTreeBytes
+	}
+	offset += o
+	children, o = dvarint.Uvarint(b[offset:])
+	if o <= 0 {
+		return "", 0, 0, 0, errMalformedTreeBytes
+	}
+	offset += o
+	// Each of the children takes at least minBytesPerNode bytes:
+	// the number of children can't exceed the number of nodes that
+	// fit the remaining bytes, minus the nodes already announced.
+	if capacity := (len(b)-offset)/minBytesPerNode - pending; capacity < 0 || children > uint64(capacity) {
+		return "", 0, 0, 0, errMalformedTreeBytes
+	}
+	return name, value, children, offset, nil
+}
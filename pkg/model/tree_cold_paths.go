@@ -0,0 +1,67 @@
+package model
+
+import "sort"
+
+// ColdPaths returns the tree of the cold paths of the baseline: the nodes
+// absent in the tree, or which total value is at most maxRatio of the
+// baseline node total, provided that the value decreased by at least
+// minDelta. The value of a node is the value of the baseline missing in
+// the tree: the excess of the baseline self value over the self value of
+// the node with the same path. Only the cold nodes and their ancestors
+// are retained: the missing values of the other nodes are accounted in
+// their parents.
+func (t *Tree) ColdPaths(baseline *Tree, maxRatio float64, minDelta int64) *Tree {
+	c := coldThreshold{maxRatio: maxRatio, minDelta: max(minDelta, 1)}
+	root := new(node)
+	root.children, _ = c.nodes(root, t.root, baseline.root)
+	for _, n := range root.children {
+		n.parent = nil
+	}
+	return &Tree{root: root.children}
+}
+
+type coldThreshold struct {
+	maxRatio float64
+	minDelta int64
+}
+
+func (c coldThreshold) cold(current, baseline int64) bool {
+	return baseline-current >= c.minDelta && float64(current) <= c.maxRatio*float64(baseline)
+}
+
+// nodes returns the cold path nodes of the baseline siblings, and
+// the sum of the missing values of the other ones.
+func (c coldThreshold) nodes(parent *node, current, baseline []*node) ([]*node, int64) {
+	x, y := siblingsByName(current), siblingsByName(baseline)
+	names := make([]string, 0, len(y))
+	for name := range y {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var retained []*node
+	var folded int64
+	for _, name := range names {
+		b := y[name]
+		n := &node{parent: parent, name: name, self: b.self}
+		var total int64
+		var children []*node
+		if v, ok := x[name]; ok {
+			n.self = max(b.self-v.self, 0)
+			total = v.total
+			children = v.children
+		}
+		var other int64
+		n.children, other = c.nodes(n, children, b.children)
+		n.self += other
+		n.total = n.self
+		for _, child := range n.children {
+			n.total += child.total
+		}
+		if len(n.children) > 0 || c.cold(total, b.total) {
+			retained = append(retained, n)
+		} else {
+			folded += n.total
+		}
+	}
+	return retained, folded
+}
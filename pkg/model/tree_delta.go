@@ -0,0 +1,76 @@
+package model
+
+import "sort"
+
+// Delta returns the tree of the differences between the tree and the
+// baseline: the value of a node is the change of the value relative
+// to the baseline node with the same path. Only the nodes which total
+// value changed significantly, and their ancestors, are retained: the
+// values of the other nodes are accounted in their parents. A change
+// is significant if its magnitude is at least minDelta, and at least
+// minPercent percent of the baseline total of the node; a node absent
+// in the baseline is significant if the former is true.
+func (t *Tree) Delta(baseline *Tree, minDelta int64, minPercent float64) *Tree {
+	d := deltaThreshold{minDelta: max(minDelta, 1), minPercent: minPercent}
+	root := new(node)
+	root.children, _ = d.nodes(root, t.root, baseline.root)
+	for _, n := range root.children {
+		n.parent = nil
+	}
+	return &Tree{root: root.children}
+}
+
+type deltaThreshold struct {
+	minDelta   int64
+	minPercent float64
+}
+
+func (d deltaThreshold) significant(delta, baseline int64) bool {
+	if abs(delta) < d.minDelta {
+		return false
+	}
+	return baseline == 0 || float64(abs(delta))*100 >= d.minPercent*float64(abs(baseline))
+}
+
+// nodes returns the significant delta nodes of the siblings, and the
+// sum of the deltas of the insignificant ones.
+func (d deltaThreshold) nodes(parent *node, current, baseline []*node) ([]*node, int64) {
+	x, y := siblingsByName(current), siblingsByName(baseline)
+	names := make([]string, 0, len(x)+len(y))
+	for name := range x {
+		names = append(names, name)
+	}
+	for name := range y {
+		if _, ok := x[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var retained []*node
+	var folded int64
+	for _, name := range names {
+		n := &node{parent: parent, name: name}
+		var c, b []*node
+		if v, ok := x[name]; ok {
+			n.self += v.self
+			n.total += v.total
+			c = v.children
+		}
+		var baselineTotal int64
+		if v, ok := y[name]; ok {
+			n.self -= v.self
+			n.total -= v.total
+			baselineTotal = v.total
+			b = v.children
+		}
+		var other int64
+		n.children, other = d.nodes(n, c, b)
+		n.self += other
+		if len(n.children) > 0 || d.significant(n.total, baselineTotal) {
+			retained = append(retained, n)
+		} else {
+			folded += n.total
+		}
+	}
+	return retained, folded
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TreesEqual decodes the serialized trees and reports whether they have
+// the same nodes with the same values, regardless of the serialization
+// details, such as the order of the sibling nodes. Sibling nodes with
+// the same name are merged. If the trees differ, a description of the
+// first mismatch is returned.
+func TreesEqual(a, b []byte) (bool, string) {
+	x, err := UnmarshalTree(a)
+	if err != nil {
+		return false, fmt.Sprintf("failed to decode the first tree: %v", err)
+	}
+	y, err := UnmarshalTree(b)
+	if err != nil {
+		return false, fmt.Sprintf("failed to decode the second tree: %v", err)
+	}
+	if d := diffNodes(nil, x.root, y.root); d != "" {
+		return false, d
+	}
+	return true, ""
+}
+
+// diffNodes compares the sibling nodes and their descendants
+// in the order of the node names, and describes the first mismatch.
+func diffNodes(path []string, a, b []*node) string {
+	x, y := siblingsByName(a), siblingsByName(b)
+	names := make([]string, 0, len(x)+len(y))
+	for name := range x {
+		names = append(names, name)
+	}
+	for name := range y {
+		if _, ok := x[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := append(path, name)
+		na, nb := x[name], y[name]
+		switch {
+		case na == nil:
+			return fmt.Sprintf("%s: missing in the first tree", strings.Join(p, ";"))
+		case nb == nil:
+			return fmt.Sprintf("%s: missing in the second tree", strings.Join(p, ";"))
+		case na.self != nb.self:
+			return fmt.Sprintf("%s: self %d != %d", strings.Join(p, ";"), na.self, nb.self)
+		case na.total != nb.total:
+			return fmt.Sprintf("%s: total %d != %d", strings.Join(p, ";"), na.total, nb.total)
+		}
+		if d := diffNodes(p, na.children, nb.children); d != "" {
+			return d
+		}
+	}
+	return ""
+}
+
+func siblingsByName(nodes []*node) map[string]*node {
+	m := make(map[string]*node, len(nodes))
+	for _, n := range nodes {
+		x, ok := m[n.name]
+		if !ok {
+			m[n.name] = n
+			continue
+		}
+		m[n.name] = &node{
+			name:     n.name,
+			self:     x.self + n.self,
+			total:    x.total + n.total,
+			children: append(x.children[:len(x.children):len(x.children)], n.children...),
+		}
+	}
+	return m
+}
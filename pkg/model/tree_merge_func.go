@@ -0,0 +1,36 @@
+package model
+
+// MergeFunc merges the source tree into the tree, combining the self
+// values of the nodes with the same call path with the function given,
+// instead of adding them up. Zero self values are considered absent:
+// the function is only called if both of the values are present. The
+// node totals are recomputed as the sum of the node self value and the
+// totals of its children.
+func (t *Tree) MergeFunc(src *Tree, combine func(a, b int64) int64) {
+	if len(t.root) == 0 {
+		*t = *src
+		return
+	}
+	if len(src.root) == 0 {
+		return
+	}
+	dst := &node{children: t.root}
+	mergeNodesFunc(dst, &node{children: src.root}, combine)
+	t.root = dst.children
+}
+
+func mergeNodesFunc(dst, src *node, combine func(a, b int64) int64) {
+	switch {
+	case dst.self == 0:
+		dst.self = src.self
+	case src.self != 0:
+		dst.self = combine(dst.self, src.self)
+	}
+	for _, c := range src.children {
+		mergeNodesFunc(dst.insert(c.name), c, combine)
+	}
+	dst.total = dst.self
+	for _, c := range dst.children {
+		dst.total += c.total
+	}
+}
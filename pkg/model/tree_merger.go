@@ -1,41 +1,149 @@
 package model
 
 import (
+	"errors"
 	"sync"
 )
 
+// ErrTreeTotalOverflow indicates that the total of the merged
+// tree can't be represented as a 64-bit signed integer.
+var ErrTreeTotalOverflow = errors.New("tree total overflow")
+
 type TreeMerger struct {
 	mu sync.Mutex
 	t  *Tree
+
+	intern  bool
+	check   bool
+	combine func(a, b int64) int64
+	names   func(string) string
+	sm      sync.Mutex
+	strings map[string]string
+}
+
+type TreeMergerOption func(*TreeMerger)
+
+// WithTreeMergerStringInterning enables interning of node names decoded
+// in MergeTreeBytes: identical names share the same storage, which reduces
+// heap usage when many trees with the same functions are merged. Note that
+// decoding is serialized when interning is enabled.
+//
+// The strings table is released when the resulting tree is retrieved.
+func WithTreeMergerStringInterning(enabled bool) TreeMergerOption {
+	return func(m *TreeMerger) {
+		m.intern = enabled
+	}
+}
+
+// WithTreeMergerOverflowCheck enables the check of the tree totals in
+// MergeTreeBytes: a tree is rejected with ErrTreeTotalOverflow, if its
+// total overflows, or if merging it would overflow the resulting tree
+// total. A partial tree with an overflowed total, e.g. because of a
+// malformed profile, would otherwise silently corrupt the result.
+func WithTreeMergerOverflowCheck(enabled bool) TreeMergerOption {
+	return func(m *TreeMerger) {
+		m.check = enabled
+	}
 }
 
-func NewTreeMerger() *TreeMerger {
-	return new(TreeMerger)
+// WithTreeMergerValueFunc makes the merger combine the node self values
+// with the function given, instead of adding them up: see Tree.MergeFunc.
+// The overflow check only applies to the totals of the merged trees.
+func WithTreeMergerValueFunc(combine func(a, b int64) int64) TreeMergerOption {
+	return func(m *TreeMerger) {
+		m.combine = combine
+	}
+}
+
+// WithTreeMergerNodeNames makes the merger format the node names of the
+// trees decoded in MergeTreeBytes with the function given, before they
+// are merged: see Tree.FormatNodeNames.
+func WithTreeMergerNodeNames(fn func(string) string) TreeMergerOption {
+	return func(m *TreeMerger) {
+		m.names = fn
+	}
+}
+
+func NewTreeMerger(opts ...TreeMergerOption) *TreeMerger {
+	m := new(TreeMerger)
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
 }
 
 func (m *TreeMerger) MergeTree(t *Tree) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	if m.t != nil {
-		m.t.Merge(t)
-	} else {
+	m.merge(t)
+}
+
+func (m *TreeMerger) merge(t *Tree) {
+	switch {
+	case m.t == nil:
 		m.t = t
+	case m.combine != nil:
+		m.t.MergeFunc(t, m.combine)
+	default:
+		m.t.Merge(t)
 	}
 }
 
 func (m *TreeMerger) MergeTreeBytes(b []byte) error {
+	return m.MergeTreeBytesVersion(b, TreeFormatV1)
+}
+
+// MergeTreeBytesVersion merges the tree serialized
+// in the format of the given version.
+func (m *TreeMerger) MergeTreeBytesVersion(b []byte, version int) error {
 	// TODO(kolesnikovae): Ideally, we should not have
 	// the intermediate tree t but update m.t reading
 	// raw bytes b directly.
-	t, err := UnmarshalTree(b)
+	t, err := m.unmarshal(b, version)
 	if err != nil {
 		return err
 	}
-	m.MergeTree(t)
+	if m.names != nil {
+		t.FormatNodeNames(m.names)
+	}
+	if !m.check {
+		m.MergeTree(t)
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	total, ok := t.checkedTotal()
+	if !ok {
+		return ErrTreeTotalOverflow
+	}
+	if m.t == nil {
+		m.t = t
+		return nil
+	}
+	// The total of the merged tree is known to fit.
+	if _, ok = addInt64(m.t.Total(), total); !ok && m.combine == nil {
+		return ErrTreeTotalOverflow
+	}
+	m.merge(t)
 	return nil
 }
 
+func (m *TreeMerger) unmarshal(b []byte, version int) (*Tree, error) {
+	if !m.intern {
+		return UnmarshalTreeVersion(b, version)
+	}
+	m.sm.Lock()
+	defer m.sm.Unlock()
+	if m.strings == nil {
+		m.strings = make(map[string]string)
+	}
+	return unmarshalTreeVersion(b, m.strings, version)
+}
+
 func (m *TreeMerger) Tree() *Tree {
+	m.sm.Lock()
+	m.strings = nil
+	m.sm.Unlock()
 	if m.t == nil {
 		return new(Tree)
 	}
@@ -0,0 +1,165 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TreeMerger_StringInterning(t *testing.T) {
+	trees := treeMergerTestTrees(8, 16)
+	m := NewTreeMerger()
+	mi := NewTreeMerger(WithTreeMergerStringInterning(true))
+	for _, b := range trees {
+		require.NoError(t, m.MergeTreeBytes(b))
+		require.NoError(t, mi.MergeTreeBytes(b))
+	}
+	require.Equal(t, m.Tree().String(), mi.Tree().String())
+	require.Nil(t, mi.strings)
+
+	// Identical names must share the same storage.
+	x := NewTreeMerger(WithTreeMergerStringInterning(true))
+	a, err := x.unmarshal(trees[0], TreeFormatV1)
+	require.NoError(t, err)
+	b, err := x.unmarshal(trees[0], TreeFormatV1)
+	require.NoError(t, err)
+	require.Equal(t, a.root[0].name, b.root[0].name)
+	require.Equal(t, unsafe.StringData(a.root[0].name), unsafe.StringData(b.root[0].name))
+}
+
+func Benchmark_TreeMerger_MergeTreeBytes(b *testing.B) {
+	trees := treeMergerTestTrees(512, 64)
+	for _, intern := range []bool{false, true} {
+		b.Run(fmt.Sprintf("interning=%v", intern), func(b *testing.B) {
+			b.ReportAllocs()
+			var heap uint64
+			for i := 0; i < b.N; i++ {
+				m := NewTreeMerger(WithTreeMergerStringInterning(intern))
+				for _, t := range trees {
+					_ = m.MergeTreeBytes(t)
+				}
+				heap += heapInUse()
+				runtime.KeepAlive(m)
+			}
+			b.ReportMetric(float64(heap)/float64(b.N), "heap-bytes/op")
+		})
+	}
+}
+
+func heapInUse() uint64 {
+	runtime.GC()
+	var s runtime.MemStats
+	runtime.ReadMemStats(&s)
+	return s.HeapInuse
+}
+
+// treeMergerTestTrees generates n trees sharing the same
+// function names, each with a different set of stacks.
+func treeMergerTestTrees(n, functions int) [][]byte {
+	names := make([]string, functions)
+	for i := range names {
+		names[i] = fmt.Sprintf("github.com/grafana/pyroscope/pkg/library.function_%d", i)
+	}
+	trees := make([][]byte, n)
+	stack := make([]string, 0, 8)
+	for i := range trees {
+		t := new(Tree)
+		for j := 0; j < functions; j++ {
+			stack = stack[:0]
+			for k := 0; k < 8; k++ {
+				stack = append(stack, names[(i+j*k)%functions])
+			}
+			t.InsertStack(int64(j+1), stack...)
+		}
+		trees[i] = t.Bytes(-1)
+	}
+	return trees
+}
+
+func Test_TreeMerger_MergeTreeBytes_Malformed(t *testing.T) {
+	tree := new(Tree)
+	tree.InsertStack(1, "a", "b", "c")
+	tree.InsertStack(2, "a", "b1")
+	b := tree.Bytes(-1)
+
+	for i := 2; i < len(b); i++ {
+		m := NewTreeMerger()
+		require.Error(t, m.MergeTreeBytes(b[:i]), "truncated at %d", i)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		input []byte
+	}{
+		{"name length exceeds input", []byte{0x7f, 'a', 0, 0}},
+		{"children count exceeds input", []byte{0, 0, 0xff, 0xff, 0xff, 0xff, 0x0f}},
+		// The varint does not fit into 64 bits. Note that the values
+		// of 64 bits are valid: negative values are encoded in two's
+		// complement, see Test_TreeMerger_MergeTreeBytes_NegativeValue.
+		{"value overflows", []byte{0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x02, 0}},
+	} {
+		m := NewTreeMerger()
+		require.Error(t, m.MergeTreeBytes(tc.input), tc.name)
+	}
+}
+
+func Test_TreeMerger_MergeTreeBytes_NegativeValue(t *testing.T) {
+	// The root, and its child "a" with the value of -1.
+	input := []byte{0, 0, 1, 1, 'a', 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01, 0}
+	tree := new(Tree)
+	tree.InsertStack(-1, "a")
+	require.Equal(t, input, tree.Bytes(-1))
+
+	m := NewTreeMerger()
+	require.NoError(t, m.MergeTreeBytes(input))
+	require.Equal(t, []*TreeNode{{Name: "a", Self: -1, Total: -1}}, m.Tree().Nodes())
+}
+
+func Fuzz_TreeMerger_MergeTreeBytes(f *testing.F) {
+	tree := new(Tree)
+	tree.InsertStack(1, "a", "b", "c")
+	tree.InsertStack(2, "a", "b1")
+	tree.InsertStack(3, "a1")
+	f.Add(tree.Bytes(-1))
+	f.Add(tree.Bytes(2))
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, intern := range []bool{false, true} {
+			m := NewTreeMerger(WithTreeMergerStringInterning(intern))
+			if err := m.MergeTreeBytes(data); err != nil {
+				return
+			}
+			_ = m.Tree().Bytes(-1)
+		}
+	})
+}
+
+func Test_TreeMerger_OverflowCheck(t *testing.T) {
+	tree := func(stacks ...int64) []byte {
+		x := new(Tree)
+		for i, v := range stacks {
+			x.InsertStack(v, fmt.Sprint("f", i))
+		}
+		return x.Bytes(-1)
+	}
+
+	m := NewTreeMerger(WithTreeMergerOverflowCheck(true))
+	require.NoError(t, m.MergeTreeBytes(tree(math.MaxInt64-1)))
+	require.ErrorIs(t, m.MergeTreeBytes(tree(2)), ErrTreeTotalOverflow)
+	// Negative values bring the total back into the range.
+	require.NoError(t, m.MergeTreeBytes(tree(-1, 2)))
+	require.Equal(t, int64(math.MaxInt64), m.Tree().Total())
+
+	m = NewTreeMerger(WithTreeMergerOverflowCheck(true))
+	require.ErrorIs(t, m.MergeTreeBytes(tree(math.MaxInt64, 1)), ErrTreeTotalOverflow)
+	require.True(t, m.IsEmpty())
+
+	// The check is disabled by default.
+	m = NewTreeMerger()
+	require.NoError(t, m.MergeTreeBytes(tree(math.MaxInt64, 1)))
+}
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// TreeNodeIDs returns the IDs of the nodes of the serialized tree, in the
+// order of serialization, including the virtual root, which ID is zero.
+// The ID of a node is derived from its path, the names of the nodes from
+// the root: the same logical node has the same ID in different trees,
+// regardless of the order of the siblings, or truncation of the tree.
+func TreeNodeIDs(b []byte, version int) ([]uint64, error) {
+	var table []string
+	switch version {
+	case TreeFormatV1:
+		if len(b) < 2 {
+			return nil, nil
+		}
+	case TreeFormatV2:
+		if len(b) == 0 {
+			return nil, nil
+		}
+		var offset int
+		var err error
+		if table, offset, err = unmarshalTreeNames(b, nil); err != nil {
+			return nil, err
+		}
+		b = b[offset:]
+	default:
+		return nil, fmt.Errorf("unsupported tree format version %d", version)
+	}
+
+	names := make(map[string]string)
+	ids := make([]uint64, 0, len(b)/estimateBytesPerNode)
+	buf := make([]byte, 0, 64)
+	// The IDs of the parents of the nodes pending.
+	parents := make([]uint64, 1, 64)
+	var offset int
+	for len(parents) > 0 {
+		parent := parents[len(parents)-1]
+		parents = parents[:len(parents)-1]
+		name, _, children, o, err := readTreeNode(b[offset:], names, table, len(parents))
+		if err != nil {
+			return nil, err
+		}
+		offset += o
+		var id uint64
+		if len(ids) > 0 {
+			buf = binary.LittleEndian.AppendUint64(buf[:0], parent)
+			buf = append(buf, name...)
+			id = xxhash.Sum64(buf)
+		}
+		ids = append(ids, id)
+		for i := uint64(0); i < children; i++ {
+			parents = append(parents, id)
+		}
+	}
+	return ids, nil
+}
+
+// Subtree removes the nodes of the tree, except for the node with the
+// given ID, see TreeNodeIDs, its descendants, and its ancestors. The
+// ancestors have no self values: their totals are the total of the node.
+// Subtree returns the depth of the node, or zero, if there is no such
+// node: the tree is empty then. The roots are at depth 1.
+func (t *Tree) Subtree(id uint64) int {
+	path := findNodePath(t.root, 0, id, nil, make([]byte, 0, 64))
+	if len(path) == 0 {
+		t.root = nil
+		return 0
+	}
+	n := path[len(path)-1]
+	for i := len(path) - 2; i >= 0; i-- {
+		path[i].children = []*node{path[i+1]}
+		path[i].self = 0
+		path[i].total = n.total
+	}
+	t.root = []*node{path[0]}
+	return len(path)
+}
+
+// findNodePath returns the path from the root to the node
+// with the given ID, or nil, if there is no such node.
+func findNodePath(nodes []*node, parent, id uint64, path []*node, buf []byte) []*node {
+	for _, n := range nodes {
+		buf = binary.LittleEndian.AppendUint64(buf[:0], parent)
+		buf = append(buf, n.name...)
+		nid := xxhash.Sum64(buf)
+		if nid == id {
+			return append(path, n)
+		}
+		if p := findNodePath(n.children, nid, id, append(path, n), buf); p != nil {
+			return p
+		}
+	}
+	return nil
+}
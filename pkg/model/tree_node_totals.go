@@ -0,0 +1,56 @@
+package model
+
+import "fmt"
+
+// TreeNodeTotals returns the total values of the nodes of the serialized
+// tree, in the order of serialization, including the virtual root, see
+// TreeNodeIDs. The total of the virtual root is the total of the tree.
+func TreeNodeTotals(b []byte, version int) ([]int64, error) {
+	var table []string
+	switch version {
+	case TreeFormatV1:
+		if len(b) < 2 {
+			return nil, nil
+		}
+	case TreeFormatV2:
+		if len(b) == 0 {
+			return nil, nil
+		}
+		var offset int
+		var err error
+		if table, offset, err = unmarshalTreeNames(b, nil); err != nil {
+			return nil, err
+		}
+		b = b[offset:]
+	default:
+		return nil, fmt.Errorf("unsupported tree format version %d", version)
+	}
+
+	totals := make([]int64, 0, len(b)/estimateBytesPerNode)
+	// The indices of the parents of the nodes; the
+	// parent of a node precedes it in the order.
+	var parents []int
+	// The indices of the parents of the nodes pending.
+	pending := make([]int, 1, 64)
+	pending[0] = -1
+	var offset int
+	for len(pending) > 0 {
+		parent := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		_, value, children, o, err := readTreeNode(b[offset:], nil, table, len(pending))
+		if err != nil {
+			return nil, err
+		}
+		offset += o
+		i := len(totals)
+		totals = append(totals, int64(value))
+		parents = append(parents, parent)
+		for c := uint64(0); c < children; c++ {
+			pending = append(pending, i)
+		}
+	}
+	for i := len(totals) - 1; i > 0; i-- {
+		totals[parents[i]] += totals[i]
+	}
+	return totals, nil
+}
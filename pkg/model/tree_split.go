@@ -0,0 +1,90 @@
+package model
+
+// Split partitions the tree into trees of no more than maxNodes nodes
+// each, which give the tree back, if merged. A tree holds a part of the
+// subtrees, along with the copies of their ancestors: the copies have no
+// self value, therefore the nodes are only accounted once. A tree might
+// exceed the limit by the number of the ancestors, if the tree is deeper
+// than maxNodes. The source tree is not modified.
+//
+// The tree is returned as is, if it has no more than maxNodes nodes.
+func (t *Tree) Split(maxNodes int64) []*Tree {
+	if maxNodes <= 0 || t.Size() <= maxNodes {
+		return []*Tree{t}
+	}
+	type entry struct {
+		node  *node
+		depth int
+	}
+	var (
+		trees []*Tree
+		cur   *Tree
+		size  int64
+		// The ancestors of the node visited in the source
+		// tree, and their copies in the current tree.
+		src  = make([]*node, 0, defaultDFSSize)
+		path = make([]*node, 0, defaultDFSSize)
+	)
+	attach := func(name string, self int64) {
+		c := &node{name: name, self: self}
+		if len(path) == 0 {
+			cur.root = append(cur.root, c)
+		} else {
+			p := path[len(path)-1]
+			c.parent = p
+			p.children = append(p.children, c)
+		}
+		path = append(path, c)
+		size++
+	}
+	nodes := make([]entry, 0, defaultDFSSize)
+	for i := len(t.root) - 1; i >= 0; i-- {
+		nodes = append(nodes, entry{node: t.root[i]})
+	}
+	// The nodes are visited depth-first, in the order of the siblings:
+	// the nodes are appended to the trees in the order of the names.
+	var e entry
+	for len(nodes) > 0 {
+		e, nodes = nodes[len(nodes)-1], nodes[:len(nodes)-1]
+		src = append(src[:e.depth], e.node)
+		path = path[:e.depth]
+		if cur == nil || size >= maxNodes {
+			cur = new(Tree)
+			trees = append(trees, cur)
+			size = 0
+			path = path[:0]
+			for _, a := range src[:e.depth] {
+				attach(a.name, 0)
+			}
+		}
+		attach(e.node.name, e.node.self)
+		for i := len(e.node.children) - 1; i >= 0; i-- {
+			nodes = append(nodes, entry{node: e.node.children[i], depth: e.depth + 1})
+		}
+	}
+	for _, x := range trees {
+		x.updateTotals()
+	}
+	return trees
+}
+
+// updateTotals sets the total value of each node to the sum
+// of its self value and the totals of the children.
+func (t *Tree) updateTotals() {
+	order := make([]*node, 0, defaultDFSSize)
+	nodes := append(make([]*node, 0, defaultDFSSize), t.root...)
+	var n *node
+	for len(nodes) > 0 {
+		n, nodes = nodes[len(nodes)-1], nodes[:len(nodes)-1]
+		order = append(order, n)
+		nodes = append(nodes, n.children...)
+	}
+	// The descendants of a node follow it.
+	for i := len(order) - 1; i >= 0; i-- {
+		n = order[i]
+		n.total = n.self
+		for _, c := range n.children {
+			n.total += c.total
+		}
+	}
+}
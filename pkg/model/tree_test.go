@@ -2,7 +2,10 @@ package model
 
 import (
 	"bytes"
+	"fmt"
 	"math"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -250,6 +253,20 @@ func Test_Tree_MarshalUnmarshal(t *testing.T) {
 		require.Equal(t, expected.String(), actual.String())
 	})
 
+	t.Run("stacks do not include the root", func(t *testing.T) {
+		expected := new(Tree)
+		expected.InsertStack(1, "a", "b", "c")
+		expected.InsertStack(2, "a", "b1")
+		actual, err := UnmarshalTree(expected.Bytes(-1))
+		require.NoError(t, err)
+
+		var expectedCollapsed, actualCollapsed bytes.Buffer
+		expected.WriteCollapsed(&expectedCollapsed)
+		actual.WriteCollapsed(&actualCollapsed)
+		require.Equal(t, "a;b1 2\na;b;c 1\n", actualCollapsed.String())
+		require.Equal(t, expectedCollapsed.String(), actualCollapsed.String())
+	})
+
 	t.Run("truncation", func(t *testing.T) {
 		fullTree := newTree([]stacktraces{
 			{locations: []string{"c", "b", "a"}, value: 1},
@@ -279,6 +296,337 @@ func Test_Tree_MarshalUnmarshal(t *testing.T) {
 	})
 }
 
+func Test_Tree_MarshalUnmarshalVersion(t *testing.T) {
+	newTestTree := func() *Tree {
+		return newTree([]stacktraces{
+			{locations: []string{"c", "b", "a"}, value: 1},
+			{locations: []string{"c", "b", "a"}, value: 1},
+			{locations: []string{"c1", "b", "a"}, value: 1},
+			{locations: []string{"c", "b1", "a"}, value: 1},
+			{locations: []string{"c1", "b1", "a"}, value: 1},
+			{locations: []string{"c", "b", "a1"}, value: 1},
+			{locations: []string{"c1", "b", "a1"}, value: 1},
+			{locations: []string{"c", "b1", "a1"}, value: 1},
+			{locations: []string{"c1", "b1", "a1"}, value: 1},
+		})
+	}
+
+	t.Run("versions are interchangeable", func(t *testing.T) {
+		for _, maxNodes := range []int64{-1, 3} {
+			v1, err := UnmarshalTreeVersion(newTestTree().BytesVersion(maxNodes, TreeFormatV1), TreeFormatV1)
+			require.NoError(t, err)
+			v2, err := UnmarshalTreeVersion(newTestTree().BytesVersion(maxNodes, TreeFormatV2), TreeFormatV2)
+			require.NoError(t, err)
+			require.Equal(t, v1.String(), v2.String())
+		}
+	})
+
+	t.Run("v1 is the default format", func(t *testing.T) {
+		require.Equal(t, newTestTree().Bytes(-1), newTestTree().BytesVersion(-1, TreeFormatV1))
+	})
+
+	t.Run("v2 stores each name once", func(t *testing.T) {
+		v1 := newTestTree().BytesVersion(-1, TreeFormatV1)
+		v2 := newTestTree().BytesVersion(-1, TreeFormatV2)
+		require.Less(t, len(v2), len(v1))
+	})
+
+	t.Run("empty tree", func(t *testing.T) {
+		b := new(Tree).BytesVersion(-1, TreeFormatV2)
+		require.Empty(t, b)
+		actual, err := UnmarshalTreeVersion(b, TreeFormatV2)
+		require.NoError(t, err)
+		require.Equal(t, new(Tree).String(), actual.String())
+	})
+
+	t.Run("malformed v2 input", func(t *testing.T) {
+		b := newTestTree().BytesVersion(-1, TreeFormatV2)
+		for i := 1; i < len(b); i++ {
+			_, err := UnmarshalTreeVersion(b[:i], TreeFormatV2)
+			require.Error(t, err, "truncated at %d", i)
+		}
+		// Name index out of the table bounds.
+		_, err := UnmarshalTreeVersion([]byte{1, 1, 'a', 1, 0, 0}, TreeFormatV2)
+		require.ErrorIs(t, err, errMalformedTreeBytes)
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.Error(t, newTestTree().MarshalTruncateVersion(&buf, -1, 3))
+		_, err := UnmarshalTreeVersion(buf.Bytes(), 3)
+		require.Error(t, err)
+	})
+}
+
+func Test_Tree_Inverted(t *testing.T) {
+	tree := new(Tree)
+	tree.InsertStack(1, "a", "b", "c")
+	tree.InsertStack(2, "a", "d", "c")
+	tree.InsertStack(3, "a", "b")
+	tree.InsertStack(4, "e")
+
+	expected := new(Tree)
+	expected.InsertStack(1, "c", "b", "a")
+	expected.InsertStack(2, "c", "d", "a")
+	expected.InsertStack(3, "b", "a")
+	expected.InsertStack(4, "e")
+
+	inverted := tree.Inverted()
+	require.Equal(t, expected.String(), inverted.String())
+	require.Equal(t, tree.Total(), inverted.Total())
+	require.Equal(t, tree.String(), inverted.Inverted().String())
+}
+
+func Test_Tree_FilterNodes(t *testing.T) {
+	newTestTree := func() *Tree {
+		tree := new(Tree)
+		tree.InsertStack(100, "a", "b", "c")
+		tree.InsertStack(10, "a", "b", "d")
+		tree.InsertStack(5, "a", "e")
+		tree.InsertStack(1, "a", "f", "g")
+		tree.InsertStack(20, "h")
+		return tree
+	}
+
+	for _, tc := range []struct {
+		name     string
+		min, max int64
+		expected []string
+	}{
+		{
+			name:     "no bounds",
+			expected: []string{"a;b;c 100", "a;b;d 10", "a;e 5", "a;f;g 1", "h 20"},
+		},
+		{
+			name:     "lower bound",
+			min:      10,
+			expected: []string{"a;b;c 100", "a;b;d 10", "a;other 6", "h 20"},
+		},
+		{
+			name:     "upper bound",
+			max:      50,
+			expected: []string{"a;b;d 10", "a;b;other 100", "a;e 5", "a;f;g 1", "h 20"},
+		},
+		{
+			name:     "range",
+			min:      5,
+			max:      20,
+			expected: []string{"a;b;d 10", "a;b;other 100", "a;e 5", "a;other 1", "h 20"},
+		},
+		{
+			name:     "nothing within range",
+			min:      1000,
+			expected: []string{"other 136"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tree := newTestTree()
+			tree.FilterNodes(tc.min, tc.max)
+			require.Equal(t, newTestTree().Total(), tree.Total())
+			var actual []string
+			tree.IterateStacks(func(_ string, self int64, stack []string) {
+				slices.Reverse(stack)
+				actual = append(actual, fmt.Sprintf("%s %d", strings.Join(stack, ";"), self))
+			})
+			slices.Sort(actual)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func Test_Tree_LimitDepth(t *testing.T) {
+	newTestTree := func() *Tree {
+		tree := new(Tree)
+		tree.InsertStack(100, "a", "b", "c")
+		tree.InsertStack(10, "a", "b", "d", "e")
+		tree.InsertStack(5, "a", "b")
+		tree.InsertStack(1, "a")
+		tree.InsertStack(20, "f")
+		return tree
+	}
+
+	for _, tc := range []struct {
+		depth    int
+		expected []string
+	}{
+		{depth: 0, expected: []string{"a 1", "a;b 5", "a;b;c 100", "a;b;d;e 10", "f 20"}},
+		{depth: 1, expected: []string{"a 116", "f 20"}},
+		{depth: 2, expected: []string{"a 1", "a;b 115", "f 20"}},
+		{depth: 3, expected: []string{"a 1", "a;b 5", "a;b;c 100", "a;b;d 10", "f 20"}},
+		{depth: 10, expected: []string{"a 1", "a;b 5", "a;b;c 100", "a;b;d;e 10", "f 20"}},
+	} {
+		t.Run(fmt.Sprint(tc.depth), func(t *testing.T) {
+			tree := newTestTree()
+			tree.LimitDepth(tc.depth)
+			require.Equal(t, newTestTree().Total(), tree.Total())
+			var actual []string
+			tree.IterateStacks(func(_ string, self int64, stack []string) {
+				slices.Reverse(stack)
+				actual = append(actual, fmt.Sprintf("%s %d", strings.Join(stack, ";"), self))
+			})
+			slices.Sort(actual)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func Test_Tree_HottestPath(t *testing.T) {
+	tree := new(Tree)
+	tree.InsertStack(100, "a", "b", "c")
+	tree.InsertStack(60, "a", "d", "e")
+	tree.InsertStack(50, "a", "d", "f")
+	tree.InsertStack(5, "a", "b")
+	tree.InsertStack(20, "g")
+	tree.HottestPath()
+
+	var actual []string
+	tree.IterateStacks(func(_ string, self int64, stack []string) {
+		slices.Reverse(stack)
+		actual = append(actual, fmt.Sprintf("%s %d", strings.Join(stack, ";"), self))
+	})
+	slices.Sort(actual)
+	require.Equal(t, []string{"a 105", "a;d 50", "a;d;e 60"}, actual)
+	require.EqualValues(t, 215, tree.Total())
+
+	empty := new(Tree)
+	empty.HottestPath()
+	require.Zero(t, empty.Total())
+}
+
+func Test_Tree_LimitFunctions(t *testing.T) {
+	newTestTree := func() *Tree {
+		tree := new(Tree)
+		tree.InsertStack(10, "main", "a", "b")
+		tree.InsertStack(5, "main", "c", "d", "b")
+		tree.InsertStack(3, "main", "a", "a", "e")
+		tree.InsertStack(1, "main", "f")
+		return tree
+	}
+
+	for _, tc := range []struct {
+		max      int
+		expected []string
+	}{
+		{max: 0, expected: []string{"main;a;a;e 3", "main;a;b 10", "main;c;d;b 5", "main;f 1"}},
+		{max: 1, expected: []string{"main;other 19"}},
+		{max: 2, expected: []string{"main;other 4", "main;other;b 15"}},
+		{max: 3, expected: []string{"main;a;a;other 3", "main;a;b 10", "main;other 1", "main;other;b 5"}},
+		{max: 10, expected: []string{"main;a;a;e 3", "main;a;b 10", "main;c;d;b 5", "main;f 1"}},
+	} {
+		t.Run(fmt.Sprint(tc.max), func(t *testing.T) {
+			tree := newTestTree()
+			tree.LimitFunctions(tc.max)
+			require.Equal(t, newTestTree().Total(), tree.Total())
+			var actual []string
+			tree.IterateStacks(func(_ string, self int64, stack []string) {
+				slices.Reverse(stack)
+				actual = append(actual, fmt.Sprintf("%s %d", strings.Join(stack, ";"), self))
+			})
+			slices.Sort(actual)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func Test_Tree_NegativeValues(t *testing.T) {
+	collapsed := func(tree *Tree) []string {
+		var actual []string
+		tree.IterateStacks(func(_ string, self int64, stack []string) {
+			slices.Reverse(stack)
+			actual = append(actual, fmt.Sprintf("%s %d", strings.Join(stack, ";"), self))
+		})
+		slices.Sort(actual)
+		return actual
+	}
+
+	for _, tc := range []struct {
+		desc     string
+		stacks   map[string]int64
+		maxNodes int64
+		expected []string
+	}{
+		{
+			desc:     "no truncation",
+			stacks:   map[string]int64{"a;b": 10, "a;c": -8, "f": 5},
+			maxNodes: -1,
+			expected: []string{"a;b 10", "a;c -8", "f 5"},
+		},
+		{
+			desc:     "negative values are as significant as positive ones",
+			stacks:   map[string]int64{"a;b": 10, "a;c": -8, "a;d": 2, "a;e": 1, "f": 5},
+			maxNodes: 4,
+			expected: []string{"a;b 10", "a;c -8", "a;other 3", "f 5"},
+		},
+		{
+			desc:     "negative other",
+			stacks:   map[string]int64{"a;b": 10, "a;c": -1, "a;d": -2},
+			maxNodes: 2,
+			expected: []string{"a;b 10", "a;other -3"},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			for _, version := range []int{TreeFormatV1, TreeFormatV2} {
+				tree := new(Tree)
+				for stack, v := range tc.stacks {
+					tree.InsertStack(v, strings.Split(stack, ";")...)
+				}
+				total := tree.Total()
+				actual, err := UnmarshalTreeVersion(tree.BytesVersion(tc.maxNodes, version), version)
+				require.NoError(t, err)
+				require.Equal(t, total, actual.Total())
+				require.Equal(t, tc.expected, collapsed(actual))
+			}
+		})
+	}
+
+	t.Run("merge", func(t *testing.T) {
+		tree := new(Tree)
+		tree.InsertStack(-1, "a", "b")
+		src := new(Tree)
+		src.InsertStack(1, "a", "c")
+		tree.Merge(src)
+		require.Equal(t, int64(0), tree.Total())
+		require.Equal(t, []string{"a;b -1", "a;c 1"}, collapsed(tree))
+	})
+
+	t.Run("drop zero nodes", func(t *testing.T) {
+		tree := new(Tree)
+		tree.InsertStack(1, "a", "b")
+		tree.InsertStack(-1, "a", "c")
+		tree.InsertStack(3, "a", "b", "z")
+		tree.InsertStack(-3, "a", "b", "z")
+		tree.InsertStack(2, "x", "y")
+		tree.InsertStack(-2, "x", "y")
+		tree.DropZeroNodes()
+
+		// The node is retained, as its children
+		// are not zero, despite the zero total.
+		expected := []*TreeNode{
+			{Name: "a", Self: 0, Total: 0, Children: []*TreeNode{
+				{Name: "b", Self: 1, Total: 1},
+				{Name: "c", Self: -1, Total: -1},
+			}},
+		}
+		require.Equal(t, expected, tree.Nodes())
+	})
+}
+
+func Test_Tree_Nodes(t *testing.T) {
+	tree := new(Tree)
+	tree.InsertStack(3, "a", "b")
+	tree.InsertStack(1, "a")
+	tree.InsertStack(2, "c")
+
+	expected := []*TreeNode{
+		{Name: "a", Self: 1, Total: 4, Children: []*TreeNode{
+			{Name: "b", Self: 3, Total: 3},
+		}},
+		{Name: "c", Self: 2, Total: 2},
+	}
+	require.Equal(t, expected, tree.Nodes())
+	require.Nil(t, new(Tree).Nodes())
+}
+
 func Test_FormatNames(t *testing.T) {
 	x := newTree([]stacktraces{
 		{locations: []string{"c0", "b0", "a0"}, value: 3},
@@ -390,3 +738,292 @@ func stackToTree(stack stacktraces) *Tree {
 	t.root = []*node{current}
 	return t
 }
+
+func Test_Tree_Size(t *testing.T) {
+	tree := new(Tree)
+	require.Zero(t, tree.Size())
+	tree.InsertStack(1, "a", "b")
+	tree.InsertStack(1, "a", "c")
+	tree.InsertStack(1, "d")
+	require.Equal(t, int64(4), tree.Size())
+}
+
+func Test_TreesEqual(t *testing.T) {
+	a := new(Tree)
+	a.InsertStack(1, "a", "b")
+	a.InsertStack(2, "a", "c")
+	a.InsertStack(3, "d")
+	b := new(Tree)
+	b.InsertStack(3, "d")
+	b.InsertStack(2, "a", "c")
+	b.InsertStack(1, "a", "b")
+	// The order of the siblings does not matter.
+	b.root[0].children[0], b.root[0].children[1] = b.root[0].children[1], b.root[0].children[0]
+	b.root[0], b.root[1] = b.root[1], b.root[0]
+
+	equal, diff := TreesEqual(a.Bytes(-1), b.Bytes(-1))
+	require.True(t, equal, diff)
+	require.Empty(t, diff)
+
+	equal, diff = TreesEqual(nil, new(Tree).Bytes(-1))
+	require.True(t, equal, diff)
+
+	b.InsertStack(1, "a", "c")
+	equal, diff = TreesEqual(a.Bytes(-1), b.Bytes(-1))
+	require.False(t, equal)
+	require.Equal(t, "a: total 3 != 4", diff)
+
+	b = new(Tree)
+	b.InsertStack(1, "a", "b")
+	b.InsertStack(2, "a", "e")
+	b.InsertStack(3, "d")
+	equal, diff = TreesEqual(a.Bytes(-1), b.Bytes(-1))
+	require.False(t, equal)
+	require.Equal(t, "a;c: missing in the second tree", diff)
+
+	equal, diff = TreesEqual(a.Bytes(-1), []byte{0xff, 0xff})
+	require.False(t, equal)
+	require.Contains(t, diff, "second tree")
+}
+
+func Test_TreeNodeIDs(t *testing.T) {
+	stacks := func(tree *Tree) map[string]uint64 {
+		ids, err := TreeNodeIDs(tree.Bytes(-1), TreeFormatV1)
+		require.NoError(t, err)
+		// The nodes are serialized in the reverse order of the
+		// siblings, depth-first; the root comes first.
+		paths := make(map[string]uint64)
+		var i int
+		var walk func(string, []*node)
+		walk = func(path string, nodes []*node) {
+			for j := len(nodes) - 1; j >= 0; j-- {
+				i++
+				p := path + ";" + nodes[j].name
+				paths[p] = ids[i]
+				walk(p, nodes[j].children)
+			}
+		}
+		require.Zero(t, ids[0])
+		walk("", tree.root)
+		require.Len(t, ids, i+1)
+		return paths
+	}
+
+	a := new(Tree)
+	a.InsertStack(1, "a", "b")
+	a.InsertStack(2, "a", "c")
+	a.InsertStack(10, "d", "a")
+	b := new(Tree)
+	b.InsertStack(5, "a", "c")
+	b.InsertStack(7, "d", "a")
+
+	x, y := stacks(a), stacks(b)
+	for p, id := range y {
+		require.Equal(t, x[p], id, p)
+	}
+	require.NotEqual(t, x[";a"], x[";d;a"])
+	require.Len(t, x, 5)
+
+	for _, version := range []int{TreeFormatV1, TreeFormatV2} {
+		ids, err := TreeNodeIDs(a.BytesVersion(-1, version), version)
+		require.NoError(t, err)
+		require.ElementsMatch(t, append([]uint64{0}, mapValues(x)...), ids)
+	}
+
+	ids, err := TreeNodeIDs(nil, TreeFormatV1)
+	require.NoError(t, err)
+	require.Empty(t, ids)
+	_, err = TreeNodeIDs([]byte{0xff, 0xff}, TreeFormatV1)
+	require.Error(t, err)
+}
+
+func Test_TreeNodeTotals(t *testing.T) {
+	tree := new(Tree)
+	tree.InsertStack(1, "a", "b")
+	tree.InsertStack(2, "a", "c")
+	tree.InsertStack(10, "d", "a")
+	tree.InsertStack(4, "a")
+	for _, version := range []int{TreeFormatV1, TreeFormatV2} {
+		b := tree.BytesVersion(-1, version)
+		totals, err := TreeNodeTotals(b, version)
+		require.NoError(t, err)
+		// Siblings are serialized in the reverse order.
+		require.Equal(t, []int64{17, 10, 10, 7, 2, 1}, totals)
+	}
+
+	totals, err := TreeNodeTotals(nil, TreeFormatV1)
+	require.NoError(t, err)
+	require.Empty(t, totals)
+	_, err = TreeNodeTotals([]byte{0xff, 0xff}, TreeFormatV1)
+	require.Error(t, err)
+}
+
+func Test_Tree_Subtree(t *testing.T) {
+	newTestTree := func() *Tree {
+		tree := new(Tree)
+		tree.InsertStack(5, "a")
+		tree.InsertStack(1, "a", "b", "c")
+		tree.InsertStack(2, "a", "b", "d", "e")
+		tree.InsertStack(3, "a", "f")
+		tree.InsertStack(10, "g")
+		return tree
+	}
+
+	tree := newTestTree()
+	ids, err := TreeNodeIDs(tree.Bytes(-1), TreeFormatV1)
+	require.NoError(t, err)
+	// The nodes are serialized in the reverse order of the siblings,
+	// depth-first: root, g, a, f, b, d, e, c.
+	require.Len(t, ids, 8)
+
+	require.Equal(t, 2, tree.Subtree(ids[4]))
+	expected := new(Tree)
+	expected.InsertStack(1, "a", "b", "c")
+	expected.InsertStack(2, "a", "b", "d", "e")
+	require.Equal(t, expected.String(), tree.String())
+	// The IDs of the retained nodes do not change.
+	subtreeIDs, err := TreeNodeIDs(tree.Bytes(-1), TreeFormatV1)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{ids[0], ids[2], ids[4], ids[5], ids[6], ids[7]}, subtreeIDs)
+
+	tree = newTestTree()
+	require.Equal(t, 1, tree.Subtree(ids[1]))
+	expected = new(Tree)
+	expected.InsertStack(10, "g")
+	require.Equal(t, expected.String(), tree.String())
+
+	tree = newTestTree()
+	require.Zero(t, tree.Subtree(42))
+	require.Zero(t, tree.Total())
+}
+
+func Test_Tree_Split(t *testing.T) {
+	tree := new(Tree)
+	tree.InsertStack(5, "a")
+	tree.InsertStack(1, "a", "b", "c")
+	tree.InsertStack(2, "a", "b", "d", "e")
+	tree.InsertStack(3, "a", "f")
+	tree.InsertStack(-4, "a", "g")
+	tree.InsertStack(10, "h")
+	expected := tree.String()
+
+	require.Equal(t, []*Tree{tree}, tree.Split(0))
+	require.Equal(t, []*Tree{tree}, tree.Split(tree.Size()))
+	for maxNodes := int64(1); maxNodes < tree.Size(); maxNodes++ {
+		trees := tree.Split(maxNodes)
+		require.Greater(t, len(trees), 1)
+		merged := new(Tree)
+		for _, x := range trees {
+			// The ancestors are copied to each of the trees.
+			require.LessOrEqual(t, x.Size(), maxNodes+3)
+			merged.Merge(MustUnmarshalTree(x.Bytes(-1)))
+		}
+		require.Equal(t, expected, merged.String(), maxNodes)
+	}
+	// The source tree is not modified.
+	require.Equal(t, expected, tree.String())
+
+	first := new(Tree)
+	first.InsertStack(5, "a")
+	first.InsertStack(1, "a", "b", "c")
+	require.Equal(t, first.String(), tree.Split(3)[0].String())
+}
+
+func mapValues(m map[string]uint64) []uint64 {
+	values := make([]uint64, 0, len(m))
+	for _, v := range m {
+		values = append(values, v)
+	}
+	return values
+}
+
+func Test_Tree_Delta(t *testing.T) {
+	baseline := new(Tree)
+	baseline.InsertStack(100, "a", "b")
+	baseline.InsertStack(100, "a", "c")
+	baseline.InsertStack(10, "d")
+
+	current := new(Tree)
+	current.InsertStack(150, "a", "b")
+	current.InsertStack(102, "a", "c")
+	current.InsertStack(5, "e")
+
+	expected := `.
+├── a: self 2 total 52
+│   └── b: self 50 total 50
+├── d: self -10 total -10
+└── e: self 5 total 5
+`
+	require.Equal(t, expected, current.Delta(baseline, 5, 0).String())
+
+	// c has changed by 2%, d has changed by 100%.
+	require.Equal(t, expected, current.Delta(baseline, 0, 5).String())
+
+	expected = `.
+└── a: self 2 total 52
+    └── b: self 50 total 50
+`
+	require.Equal(t, expected, current.Delta(baseline, 20, 0).String())
+
+	require.Empty(t, current.Delta(current, 0, 0).String()[2:])
+}
+
+func Test_Tree_ColdPaths(t *testing.T) {
+	baseline := new(Tree)
+	baseline.InsertStack(100, "a", "b")
+	baseline.InsertStack(100, "a", "c")
+	baseline.InsertStack(40, "a", "c", "d")
+	baseline.InsertStack(10, "e")
+
+	current := new(Tree)
+	current.InsertStack(150, "a", "b")
+	current.InsertStack(90, "a", "c")
+	current.InsertStack(5, "e")
+	current.InsertStack(50, "f")
+
+	// d is absent, e has halved; c is not cold, as
+	// its total is 64% of the baseline: the missing
+	// values are accounted in c.
+	expected := `.
+├── a: self 0 total 50
+│   └── c: self 10 total 50
+│       └── d: self 40 total 40
+└── e: self 5 total 5
+`
+	require.Equal(t, expected, current.ColdPaths(baseline, 0.5, 0).String())
+
+	expected = `.
+└── a: self 0 total 50
+    └── c: self 10 total 50
+        └── d: self 40 total 40
+`
+	require.Equal(t, expected, current.ColdPaths(baseline, 0, 0).String())
+	require.Equal(t, expected, current.ColdPaths(baseline, 0.5, 10).String())
+
+	require.Empty(t, current.ColdPaths(current, 1, 0).String()[2:])
+}
+
+func Test_Tree_MergeFunc(t *testing.T) {
+	a := new(Tree)
+	a.InsertStack(10, "a", "b")
+	a.InsertStack(5, "a")
+	a.InsertStack(3, "c")
+
+	b := new(Tree)
+	b.InsertStack(7, "a", "b")
+	b.InsertStack(20, "a", "d")
+	b.InsertStack(4, "c")
+
+	a.MergeFunc(b, func(x, y int64) int64 { return max(x, y) })
+	expected := `.
+├── a: self 5 total 35
+│   ├── b: self 10 total 10
+│   └── d: self 20 total 20
+└── c: self 4 total 4
+`
+	require.Equal(t, expected, a.String())
+
+	empty := new(Tree)
+	empty.MergeFunc(b, func(x, y int64) int64 { return max(x, y) })
+	require.Equal(t, b.String(), empty.String())
+}
@@ -39,6 +39,7 @@ import (
 	"github.com/grafana/pyroscope/pkg/compactor"
 	"github.com/grafana/pyroscope/pkg/distributor"
 	"github.com/grafana/pyroscope/pkg/embedded/grafana"
+	"github.com/grafana/pyroscope/pkg/experiment/queryfrontend"
 	"github.com/grafana/pyroscope/pkg/frontend"
 	"github.com/grafana/pyroscope/pkg/ingester"
 	objstoreclient "github.com/grafana/pyroscope/pkg/objstore/client"
@@ -116,6 +117,37 @@ func (f *Phlare) initQueryFrontend() (services.Service, error) {
 	if err != nil {
 		return nil, err
 	}
+	if f.Cfg.v2Experiment {
+		if err = f.Cfg.QueryFrontendV2.Validate(); err != nil {
+			return nil, err
+		}
+		logger := log.With(f.logger, "component", "query-frontend")
+		regions, clients, err := f.federationRegions(logger)
+		if err != nil {
+			return nil, err
+		}
+		q, err := queryfrontend.New(
+			f.Cfg.QueryFrontendV2,
+			logger,
+			f.Overrides,
+			f.metastoreClient,
+			f.queryBackendClient,
+			f.reg,
+			regions...,
+		)
+		if err == nil {
+			// The clients of the regions are stopped along with the frontend.
+			subservices := make([]services.Service, len(clients))
+			for i, c := range clients {
+				subservices[i] = c.Service()
+			}
+			err = frontendSvc.EnableQueryBackend(q, subservices...)
+		}
+		if err != nil {
+			closeFederationClients(clients)
+			return nil, err
+		}
+	}
 
 	f.API.RegisterPyroscopeHandlers(frontendSvc)
 	f.API.RegisterQueryFrontend(frontendSvc)
@@ -1,6 +1,8 @@
 package phlare
 
 import (
+	"io"
+
 	"github.com/go-kit/log"
 	"github.com/grafana/dskit/ring"
 	"github.com/grafana/dskit/services"
@@ -14,6 +16,7 @@ import (
 	metastoreclient "github.com/grafana/pyroscope/pkg/experiment/metastore/client"
 	"github.com/grafana/pyroscope/pkg/experiment/querybackend"
 	querybackendclient "github.com/grafana/pyroscope/pkg/experiment/querybackend/client"
+	"github.com/grafana/pyroscope/pkg/experiment/queryfrontend"
 	"github.com/grafana/pyroscope/pkg/util/health"
 )
 
@@ -115,10 +118,15 @@ func (f *Phlare) initMetastoreClient() (services.Service, error) {
 	if err := f.Cfg.Metastore.Validate(); err != nil {
 		return nil, err
 	}
+	var opts []metastoreclient.Option
+	if f.Cfg.Metastore.FollowerReads {
+		opts = append(opts, metastoreclient.WithFollowerReads(f.Cfg.Metastore.FollowerReadLease))
+	}
 	mc, err := metastoreclient.New(
 		f.Cfg.Metastore.Address,
 		f.logger,
 		f.Cfg.Metastore.GRPCClientConfig,
+		opts...,
 	)
 	if err != nil {
 		return nil, err
@@ -132,12 +140,17 @@ func (f *Phlare) initQueryBackend() (services.Service, error) {
 		return nil, err
 	}
 	logger := log.With(f.logger, "component", "query-backend")
+	reader := querybackend.NewBlockReader(f.logger, f.storageBucket, f.reg, f.Cfg.QueryBackend)
+	if f.Cfg.QueryBackend.ReadHeads {
+		reader.SetHeadSource(f.segmentWriterClient)
+	}
 	b, err := querybackend.New(
 		f.Cfg.QueryBackend,
 		logger,
 		f.reg,
 		f.queryBackendClient,
-		querybackend.NewBlockReader(f.logger, f.storageBucket),
+		reader,
+		f.metastoreClient,
 	)
 	if err != nil {
 		return nil, err
@@ -161,6 +174,43 @@ func (f *Phlare) initQueryBackendClient() (services.Service, error) {
 	return c.Service(), nil
 }
 
+// federationClient is a client of a remote region of the federation.
+type federationClient interface {
+	Service() services.Service
+	io.Closer
+}
+
+// federationRegions creates the clients of the remote regions of the
+// query frontend federation. The clients are configured as the local ones.
+// The services of the clients are to be run along with the query frontend;
+// if they are never started, the clients must be closed.
+func (f *Phlare) federationRegions(logger log.Logger) ([]querybackend.Region, []federationClient, error) {
+	regions := make([]querybackend.Region, 0, len(f.Cfg.QueryFrontendV2.Federation))
+	clients := make([]federationClient, 0, 2*len(f.Cfg.QueryFrontendV2.Federation))
+	for _, r := range f.Cfg.QueryFrontendV2.Federation {
+		mc, err := metastoreclient.New(r.MetastoreAddress, f.logger, f.Cfg.Metastore.GRPCClientConfig)
+		if err != nil {
+			closeFederationClients(clients)
+			return nil, nil, err
+		}
+		clients = append(clients, mc)
+		qc, err := querybackendclient.New(r.QueryBackendAddress, f.Cfg.QueryBackend.GRPCClientConfig)
+		if err != nil {
+			closeFederationClients(clients)
+			return nil, nil, err
+		}
+		clients = append(clients, qc)
+		regions = append(regions, queryfrontend.NewRegion(logger, r, mc, qc))
+	}
+	return regions, clients, nil
+}
+
+func closeFederationClients(clients []federationClient) {
+	for _, c := range clients {
+		_ = c.Close()
+	}
+}
+
 func (f *Phlare) initHealthService() (services.Service, error) {
 	healthService := health.NewGRPCHealthService()
 	grpc_health_v1.RegisterHealthServer(f.Server.GRPC, healthService)
@@ -51,6 +51,7 @@ import (
 	metastoreclient "github.com/grafana/pyroscope/pkg/experiment/metastore/client"
 	"github.com/grafana/pyroscope/pkg/experiment/querybackend"
 	querybackendclient "github.com/grafana/pyroscope/pkg/experiment/querybackend/client"
+	"github.com/grafana/pyroscope/pkg/experiment/queryfrontend"
 	"github.com/grafana/pyroscope/pkg/frontend"
 	"github.com/grafana/pyroscope/pkg/ingester"
 	phlareobj "github.com/grafana/pyroscope/pkg/objstore"
@@ -112,6 +113,7 @@ type Config struct {
 	SegmentWriter    segmentwriter.Config    `yaml:"segment_writer" doc:"hidden"`
 	Metastore        metastore.Config        `yaml:"metastore" doc:"hidden"`
 	QueryBackend     querybackend.Config     `yaml:"query_backend" doc:"hidden"`
+	QueryFrontendV2  queryfrontend.Config    `yaml:"query_frontend_v2" doc:"hidden"`
 	CompactionWorker compactionworker.Config `yaml:"compaction_worker" doc:"hidden"`
 }
 
@@ -193,6 +195,7 @@ func (c *Config) registerServerFlagsWithChangedDefaultValues(fs *flag.FlagSet) {
 		c.Metastore.RegisterFlags(throwaway)
 		c.SegmentWriter.RegisterFlags(throwaway)
 		c.QueryBackend.RegisterFlags(throwaway)
+		c.QueryFrontendV2.RegisterFlags(throwaway)
 		c.CompactionWorker.RegisterFlags(throwaway)
 		c.LimitsConfig.WritePathOverrides.RegisterFlags(throwaway)
 	}
@@ -396,7 +399,7 @@ func (f *Phlare) setupModuleManager() error {
 			SegmentWriter:       {Overrides, API, MemberlistKV, Storage, UsageReport, MetastoreClient},
 			Metastore:           {Overrides, API, HealthService, MetastoreClient},
 			CompactionWorker:    {Overrides, API, Storage, Overrides, MetastoreClient},
-			QueryBackend:        {Overrides, API, Storage, Overrides, QueryBackendClient},
+			QueryBackend:        {Overrides, API, Storage, Overrides, QueryBackendClient, MetastoreClient},
 			SegmentWriterRing:   {Overrides, API, MemberlistKV},
 			SegmentWriterClient: {SegmentWriterRing},
 			HealthService:       {API},
@@ -408,6 +411,9 @@ func (f *Phlare) setupModuleManager() error {
 		deps[All] = append(deps[All], SegmentWriter, Metastore, CompactionWorker, QueryBackend, HealthService)
 		deps[QueryFrontend] = append(deps[QueryFrontend], MetastoreClient, QueryBackendClient)
 		deps[Distributor] = append(deps[Distributor], SegmentWriterClient)
+		if f.Cfg.QueryBackend.ReadHeads {
+			deps[QueryBackend] = append(deps[QueryBackend], SegmentWriterClient)
+		}
 
 		mm.RegisterModule(SegmentWriter, f.initSegmentWriter)
 		mm.RegisterModule(SegmentWriterRing, f.initSegmentWriterRing, modules.UserInvisibleModule)
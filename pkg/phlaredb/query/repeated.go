@@ -45,11 +45,32 @@ func NewRepeatedRowIterator[T any](
 	rows iter.Iterator[T],
 	rowGroups []parquet.RowGroup,
 	columns ...int,
+) iter.Iterator[RepeatedRow[T]] {
+	return NewRepeatedRowIteratorWithPrefetch(ctx, rows, rowGroups, nil, columns...)
+}
+
+// ColumnChunkPrefetcher fetches column chunks ahead of time.
+type ColumnChunkPrefetcher interface {
+	// PrefetchColumnChunk starts fetching the column chunk of the
+	// row group in the background. The call must not block.
+	PrefetchColumnChunk(rowGroup, column int)
+}
+
+// NewRepeatedRowIteratorWithPrefetch is like NewRepeatedRowIterator, but
+// once the iterator proceeds to a row group, the column chunks of the next
+// one are prefetched. The row group indices passed to the prefetcher are
+// the indices in the rowGroups slice.
+func NewRepeatedRowIteratorWithPrefetch[T any](
+	ctx context.Context,
+	rows iter.Iterator[T],
+	rowGroups []parquet.RowGroup,
+	prefetcher ColumnChunkPrefetcher,
+	columns ...int,
 ) iter.Iterator[RepeatedRow[T]] {
 	rows, rowNumbers := iter.Tee(rows)
 	return &repeatedRowIterator[T]{
 		rows: rows,
-		columns: NewMultiColumnIterator(ctx,
+		columns: newMultiColumnIterator(ctx,
 			WrapWithRowNumber(rowNumbers),
 			// Batch size specifies how many rows to be read
 			// from a column at once. Note that the batched rows
@@ -57,6 +78,7 @@ func NewRepeatedRowIterator[T any](
 			// they were read from.
 			4,
 			rowGroups,
+			prefetcher,
 			columns...,
 		),
 	}
@@ -120,6 +142,17 @@ func NewMultiColumnIterator(
 	batchSize int,
 	rowGroups []parquet.RowGroup,
 	columns ...int,
+) iter.Iterator[[][]parquet.Value] {
+	return newMultiColumnIterator(ctx, rows, batchSize, rowGroups, nil, columns...)
+}
+
+func newMultiColumnIterator(
+	ctx context.Context,
+	rows iter.Iterator[int64],
+	batchSize int,
+	rowGroups []parquet.RowGroup,
+	prefetcher ColumnChunkPrefetcher,
+	columns ...int,
 ) iter.Iterator[[][]parquet.Value] {
 	m := multiColumnIterator{
 		c: make([]iter.Iterator[[]parquet.Value], len(columns)),
@@ -131,7 +164,7 @@ func NewMultiColumnIterator(
 	}
 	for i, column := range columns {
 		m.c[i] = iter.NewAsyncBatchIterator[[]parquet.Value](
-			NewRepeatedRowColumnIterator(ctx, m.r[i], rowGroups, column),
+			newRepeatedRowColumnIterator(ctx, m.r[i], rowGroups, prefetcher, column),
 			batchSize,
 			CloneParquetValues,
 			ReleaseParquetValues,
@@ -181,6 +214,11 @@ type repeatedRowColumnIterator struct {
 	column   int
 	readSize int
 
+	prefetcher ColumnChunkPrefetcher
+	// Index of the first row group in rgs within the original slice.
+	rgIndex  int
+	rgsTotal int
+
 	pages parquet.Pages
 	page  parquet.Page
 
@@ -200,12 +238,24 @@ type repeatedRowColumnIterator struct {
 }
 
 func NewRepeatedRowColumnIterator(ctx context.Context, rows iter.Iterator[int64], rgs []parquet.RowGroup, column int) iter.Iterator[[]parquet.Value] {
+	return newRepeatedRowColumnIterator(ctx, rows, rgs, nil, column)
+}
+
+func newRepeatedRowColumnIterator(
+	ctx context.Context,
+	rows iter.Iterator[int64],
+	rgs []parquet.RowGroup,
+	prefetcher ColumnChunkPrefetcher,
+	column int,
+) iter.Iterator[[]parquet.Value] {
 	r := repeatedRowColumnIterator{
-		rows:     rows,
-		rgs:      rgs,
-		column:   column,
-		vit:      getRepeatedValuePageIteratorFromPool(),
-		readSize: repeatedRowColumnIteratorReadSize,
+		rows:       rows,
+		rgs:        rgs,
+		rgsTotal:   len(rgs),
+		column:     column,
+		prefetcher: prefetcher,
+		vit:        getRepeatedValuePageIteratorFromPool(),
+		readSize:   repeatedRowColumnIteratorReadSize,
 	}
 	if len(rgs) == 0 {
 		return iter.NewEmptyIterator[[]parquet.Value]()
@@ -269,6 +319,10 @@ func (x *repeatedRowColumnIterator) seekRowGroup(rn int64) bool {
 			continue
 		}
 		x.rgs = x.rgs[i+1:]
+		x.rgIndex += i + 1
+		if x.prefetcher != nil && x.rgIndex < x.rgsTotal {
+			x.prefetcher.PrefetchColumnChunk(x.rgIndex, x.column)
+		}
 		return x.openChunk(rg)
 	}
 	return false
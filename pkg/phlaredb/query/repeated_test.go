@@ -3,6 +3,7 @@ package query
 import (
 	"context"
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -300,6 +301,50 @@ func Test_RepeatedRowIterator_Cancellation(t *testing.T) {
 	assert.NoError(t, it.Close())
 }
 
+type testPrefetcher struct {
+	m     sync.Mutex
+	calls [][2]int
+}
+
+func (p *testPrefetcher) PrefetchColumnChunk(rowGroup, column int) {
+	p.m.Lock()
+	defer p.m.Unlock()
+	p.calls = append(p.calls, [2]int{rowGroup, column})
+}
+
+func Test_RepeatedRowIterator_Prefetch(t *testing.T) {
+	var groups []parquet.RowGroup
+	for _, rg := range [][]repeatedTestRow{
+		{{[]int64{1}}, {[]int64{2}}},
+		{{[]int64{3}}, {[]int64{4}}},
+		{{[]int64{5}}, {[]int64{6}}},
+		{{[]int64{7}}, {[]int64{8}}},
+	} {
+		buffer := parquet.NewBuffer()
+		for _, row := range rg {
+			require.NoError(t, buffer.Write(row))
+		}
+		groups = append(groups, buffer)
+	}
+
+	// The second row group is skipped, but prefetched, as
+	// the iterator can not know it ahead of time.
+	rows := iter.NewSliceIterator([]testRowGetter{{0}, {1}, {5}, {7}})
+	p := new(testPrefetcher)
+	actual := readRepeatedRowIterator(t,
+		NewRepeatedRowIteratorWithPrefetch(context.Background(), rows, groups, p, 0))
+	expected := []RepeatedRow[testRowGetter]{
+		{testRowGetter{0}, [][]parquet.Value{{parquet.ValueOf(1)}}},
+		{testRowGetter{1}, [][]parquet.Value{{parquet.ValueOf(2)}}},
+		{testRowGetter{5}, [][]parquet.Value{{parquet.ValueOf(6)}}},
+		{testRowGetter{7}, [][]parquet.Value{{parquet.ValueOf(8)}}},
+	}
+	if diff := cmp.Diff(expected, actual, int64ParquetComparer()); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+	assert.Equal(t, [][2]int{{1, 0}, {3, 0}}, p.calls)
+}
+
 type multiColumnItem struct {
 	X int64
 	Y int64
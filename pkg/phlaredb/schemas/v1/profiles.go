@@ -122,21 +122,66 @@ type SampleColumns struct {
 	StacktraceID parquet.LeafColumn
 	Value        parquet.LeafColumn
 	SpanID       parquet.LeafColumn
+
+	projection SampleColumnSet
 }
 
+// SampleColumnSet is a set of the sample columns to be projected.
+type SampleColumnSet uint8
+
+const (
+	SampleStacktraceIDColumn SampleColumnSet = 1 << iota
+	SampleValueColumn
+	SampleSpanIDColumn
+
+	SampleStacktraceColumns = SampleStacktraceIDColumn | SampleValueColumn
+	AllSampleColumns        = SampleStacktraceColumns | SampleSpanIDColumn
+)
+
+// Resolve resolves all the sample columns.
 func (c *SampleColumns) Resolve(schema *parquet.Schema) error {
+	return c.ResolveColumns(schema, AllSampleColumns)
+}
+
+// ResolveColumns only resolves the sample columns of the set: the other
+// ones are not projected by Indices. The span ID column is optional.
+func (c *SampleColumns) ResolveColumns(schema *parquet.Schema, set SampleColumnSet) error {
+	*c = SampleColumns{projection: set}
 	var err error
-	if c.StacktraceID, err = ResolveColumnByPath(schema, sampleStacktraceIDColumnPath); err != nil {
-		return err
+	if set&SampleStacktraceIDColumn != 0 {
+		if c.StacktraceID, err = ResolveColumnByPath(schema, sampleStacktraceIDColumnPath); err != nil {
+			return err
+		}
 	}
-	if c.Value, err = ResolveColumnByPath(schema, SampleValueColumnPath); err != nil {
-		return err
+	if set&SampleValueColumn != 0 {
+		if c.Value, err = ResolveColumnByPath(schema, SampleValueColumnPath); err != nil {
+			return err
+		}
+	}
+	if set&SampleSpanIDColumn != 0 {
+		if c.SpanID, err = ResolveColumnByPath(schema, sampleSpanIDColumnPath); err != nil {
+			c.projection &^= SampleSpanIDColumn
+		}
 	}
-	// Optional.
-	c.SpanID, _ = ResolveColumnByPath(schema, sampleSpanIDColumnPath)
 	return nil
 }
 
+// Indices returns the indices of the projected columns, in the
+// order of the fields: stack trace ID, value, and span ID.
+func (c *SampleColumns) Indices() []int {
+	indices := make([]int, 0, 3)
+	if c.projection&SampleStacktraceIDColumn != 0 {
+		indices = append(indices, c.StacktraceID.ColumnIndex)
+	}
+	if c.projection&SampleValueColumn != 0 {
+		indices = append(indices, c.Value.ColumnIndex)
+	}
+	if c.projection&SampleSpanIDColumn != 0 {
+		indices = append(indices, c.SpanID.ColumnIndex)
+	}
+	return indices
+}
+
 func (c *SampleColumns) HasSpanID() bool {
 	return c.SpanID.Node != nil
 }
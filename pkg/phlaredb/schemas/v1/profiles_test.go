@@ -580,3 +580,21 @@ func TestColumnCount(t *testing.T) {
 	}
 
 }
+
+func TestSampleColumnsProjection(t *testing.T) {
+	var all SampleColumns
+	require.NoError(t, all.Resolve(ProfilesSchema))
+	require.True(t, all.HasSpanID())
+	require.Equal(t, []int{all.StacktraceID.ColumnIndex, all.Value.ColumnIndex, all.SpanID.ColumnIndex}, all.Indices())
+
+	var values SampleColumns
+	require.NoError(t, values.ResolveColumns(ProfilesSchema, SampleValueColumn))
+	require.Equal(t, []int{all.Value.ColumnIndex}, values.Indices())
+	require.False(t, values.HasSpanID())
+
+	// The span ID column is optional.
+	var samples SampleColumns
+	require.NoError(t, samples.ResolveColumns(DownsampledProfilesSchema, AllSampleColumns))
+	require.False(t, samples.HasSpanID())
+	require.Len(t, samples.Indices(), 2)
+}
@@ -276,6 +276,9 @@ func (r *Reader) lookupFile(name string) (block.File, error) {
 	return f, nil
 }
 
+// FormatVersion returns the format version of the symbols.
+func (r *Reader) FormatVersion() FormatVersion { return r.index.Header.Version }
+
 func (r *Reader) Close() error {
 	if r == nil {
 		return nil
@@ -296,6 +299,36 @@ func (r *Reader) Partition(ctx context.Context, partition uint64) (PartitionRead
 	return p, nil
 }
 
+// PartitionAddresses is like Partition, but only the stack traces and
+// the locations of the partition are fetched: the partition is reported
+// as unsymbolized, and the frames are named after the addresses. This
+// bounds the memory needed to resolve the stack traces of the blocks
+// which symbols are too large to be loaded.
+func (r *Reader) PartitionAddresses(ctx context.Context, partition uint64) (PartitionReader, error) {
+	p, ok := r.partitionsMap[partition]
+	if !ok {
+		return nil, ErrPartitionNotFound
+	}
+	a := &addressPartition{partition: p}
+	if err := a.tx().fetch(ctx); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Verify fetches all the partitions, verifying the checksums
+// of their data, and releases them. ErrInvalidCRC is returned,
+// if the data of a partition does not match its checksum.
+func (r *Reader) Verify(ctx context.Context) error {
+	for _, p := range r.partitions {
+		if err := p.fetch(ctx); err != nil {
+			return err
+		}
+		p.Release()
+	}
+	return nil
+}
+
 func (r *Reader) partition(ctx context.Context, partition uint64) (*partition, error) {
 	p, ok := r.partitionsMap[partition]
 	if !ok {
@@ -315,6 +348,11 @@ type partition struct {
 	mappings    table[schemav1.InMemoryMapping]
 	functions   table[schemav1.InMemoryFunction]
 	strings     table[string]
+
+	// The partition is immutable, therefore the symbolization
+	// is only checked once.
+	symbolizationOnce sync.Once
+	symbolization     symbolization
 }
 
 type table[T any] interface {
@@ -344,6 +382,33 @@ func (p *partition) tx() *fetchTx {
 	return &tx
 }
 
+// addressPartition is a partition of which only the stack
+// traces and the locations are fetched.
+type addressPartition struct{ *partition }
+
+func (p *addressPartition) tx() *fetchTx {
+	tx := make(fetchTx, 0, len(p.stacktraces)+1)
+	for _, c := range p.stacktraces {
+		tx.append(c)
+	}
+	if p.reader.index.Header.Version > FormatV1 {
+		tx.append(p.locations)
+	}
+	return &tx
+}
+
+func (p *addressPartition) Release() {
+	p.tx().release()
+}
+
+func (p *addressPartition) Symbols() *Symbols {
+	return &Symbols{
+		Stacktraces:   p.partition,
+		Locations:     p.locations.slice(),
+		symbolization: symbolizationMissing,
+	}
+}
+
 // Format V1.
 func (p *partition) initEmptyTables(*PartitionHeader) {
 	p.locations = emptyTable[schemav1.InMemoryLocation]{}
@@ -417,12 +482,22 @@ func (p *partition) initTables(h *PartitionHeader) (err error) {
 }
 
 func (p *partition) Symbols() *Symbols {
+	locations := p.locations.slice()
+	var s symbolization
+	if len(locations) > 0 {
+		// The locations are only available once fetched.
+		p.symbolizationOnce.Do(func() {
+			p.symbolization = locationsSymbolization(locations)
+		})
+		s = p.symbolization
+	}
 	return &Symbols{
-		Stacktraces: p,
-		Locations:   p.locations.slice(),
-		Mappings:    p.mappings.slice(),
-		Functions:   p.functions.slice(),
-		Strings:     p.strings.slice(),
+		Stacktraces:   p,
+		Locations:     locations,
+		Mappings:      p.mappings.slice(),
+		Functions:     p.functions.slice(),
+		Strings:       p.strings.slice(),
+		symbolization: s,
 	}
 }
 
@@ -76,6 +76,9 @@ func (w *writerV3) newFile(path string) (f *fileWriter, err error) {
 }
 
 func writePartitionV3(w *writerOffset, e *encodersV3, p *PartitionWriter) (err error) {
+	// The partition may be written more than once, e.g.,
+	// if a snapshot of the head is taken before the flush.
+	p.header.Stacktraces = p.header.Stacktraces[:0]
 	if p.header.V3.Strings, err = writeSymbolsBlock(w, p.strings.slice, e.stringsEncoder); err != nil {
 		return err
 	}
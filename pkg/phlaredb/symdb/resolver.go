@@ -2,11 +2,14 @@ package symdb
 
 import (
 	"context"
+	"errors"
+	"regexp"
 	"runtime"
 	"sync"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/parquet-go/parquet-go"
+	"go.uber.org/atomic"
 	"golang.org/x/sync/errgroup"
 
 	googlev1 "github.com/grafana/pyroscope/api/gen/proto/go/google/v1"
@@ -35,8 +38,30 @@ type Resolver struct {
 	m sync.RWMutex
 	p map[uint64]*lazyPartition
 
-	maxNodes int64
-	sts      *typesv1.StackTraceSelector
+	maxNodes   int64
+	sts        *typesv1.StackTraceSelector
+	epsilon    float64
+	seed       uint64
+	shallow    bool
+	entryPoint *regexp.Regexp
+	maxDepth   int
+	addresses  bool
+
+	unsymbolized atomic.Bool
+	// The number of stack traces resolved,
+	// and the number of them lacking symbols.
+	stacks             atomic.Int64
+	unsymbolizedStacks atomic.Int64
+	// The number of stack traces truncated
+	// because of the maximum depth.
+	truncatedStacks atomic.Int64
+
+	// The calls in progress hold the read lock: the
+	// resolver is only released once they complete.
+	life     sync.RWMutex
+	released bool
+	release  sync.Once
+	done     chan struct{}
 }
 
 type ResolverOption func(*Resolver)
@@ -57,6 +82,59 @@ func WithResolverMaxNodes(n int64) ResolverOption {
 	}
 }
 
+// WithResolverApproximation enables approximate tree building: only the
+// nodes that are likely to be among the top max nodes are retained while
+// the tree is being built. The estimated node values exceed the true ones
+// by at most epsilon times the total value, with high probability.
+// The option only takes effect if the maximum number of nodes is set.
+func WithResolverApproximation(epsilon float64) ResolverOption {
+	return func(r *Resolver) {
+		r.epsilon = epsilon
+	}
+}
+
+// WithResolverSeed specifies the seed of the hash functions used in
+// approximate tree building. For the given seed, the resulting tree
+// only depends on the samples. The default seed is zero.
+func WithResolverSeed(seed uint64) ResolverOption {
+	return func(r *Resolver) {
+		r.seed = seed
+	}
+}
+
+// WithResolverShallow enables shallow tree building: stack traces are
+// truncated below the first call out of the entry point, and the values
+// of the truncated frames are accounted in the last retained frame. The
+// entry point is the first frame that matches the expression; if the
+// expression is nil, or none of the frames match, it is the root.
+func WithResolverShallow(entryPoint *regexp.Regexp) ResolverOption {
+	return func(r *Resolver) {
+		r.shallow = true
+		r.entryPoint = entryPoint
+	}
+}
+
+// WithResolverMaxDepth limits the depth of the stack traces resolved
+// into the tree: locations beyond the depth are truncated, and the values
+// of the truncated frames are accounted in the last retained frame. The
+// limit protects the resolver from degenerate stack traces, and is not
+// expected to affect regular profiles. Zero means no limit.
+func WithResolverMaxDepth(n int) ResolverOption {
+	return func(r *Resolver) {
+		r.maxDepth = n
+	}
+}
+
+// WithResolverAddresses makes the resolver only fetch the stack traces
+// and the locations of the partitions, if the symbols reader supports
+// it: the frames are named after the location addresses, as in the
+// partitions that have not been symbolized.
+func WithResolverAddresses() ResolverOption {
+	return func(r *Resolver) {
+		r.addresses = true
+	}
+}
+
 // WithResolverStackTraceSelector specifies the stack trace selector.
 // Only stack traces that belong to the callSite (have the prefix provided)
 // will be selected. If empty, the filter is ignored.
@@ -67,6 +145,12 @@ func WithResolverStackTraceSelector(sts *typesv1.StackTraceSelector) ResolverOpt
 	}
 }
 
+// addressReader is implemented by the symbols readers that can
+// fetch the partitions for address-only resolution.
+type addressReader interface {
+	PartitionAddresses(ctx context.Context, partition uint64) (PartitionReader, error)
+}
+
 type lazyPartition struct {
 	id uint64
 
@@ -82,7 +166,11 @@ type lazyPartition struct {
 
 func (p *lazyPartition) fetch(ctx context.Context) error {
 	p.fetchOnce.Do(func() {
-		p.reader, p.err = p.resolver.s.Partition(ctx, p.id)
+		if a, ok := p.resolver.s.(addressReader); ok && p.resolver.addresses {
+			p.reader, p.err = a.PartitionAddresses(ctx, p.id)
+		} else {
+			p.reader, p.err = p.resolver.s.Partition(ctx, p.id)
+		}
 		if p.err == nil && p.resolver.sts != nil {
 			p.selection = SelectStackTraces(p.reader.Symbols(), p.resolver.sts)
 		}
@@ -102,11 +190,52 @@ func NewResolver(ctx context.Context, s SymbolsReader, opts ...ResolverOption) *
 	r.span, r.ctx = opentracing.StartSpanFromContext(ctx, "NewResolver")
 	r.ctx, r.cancel = context.WithCancel(r.ctx)
 	r.g, r.ctx = errgroup.WithContext(r.ctx)
+	r.done = make(chan struct{})
 	return &r
 }
 
+// ErrResolverReleased is returned by the calls made
+// after the resolver release has been initiated.
+var ErrResolverReleased = errors.New("resolver released")
+
+// Release cancels the resolver and waits for all the calls in progress
+// to complete, before the resources associated with it are released.
+// It is safe to call Release multiple times, and after ReleaseAsync.
 func (r *Resolver) Release() {
-	r.cancel()
+	<-r.ReleaseAsync()
+}
+
+// ReleaseAsync is like Release, but it does not wait. The returned
+// channel is closed once the resolver is released. The calls made after
+// ReleaseAsync have no effect, or fail with ErrResolverReleased: this
+// allows the caller to abandon the resolver, if a call in progress does
+// not complete in time, e.g. when the query context expires.
+func (r *Resolver) ReleaseAsync() <-chan struct{} {
+	r.release.Do(func() {
+		r.cancel()
+		go func() {
+			r.life.Lock()
+			r.released = true
+			r.life.Unlock()
+			r.releaseResources()
+			close(r.done)
+		}()
+	})
+	return r.done
+}
+
+// acquire reports whether the resolver can be used: if so,
+// the caller must call r.life.RUnlock once the call completes.
+func (r *Resolver) acquire() bool {
+	r.life.RLock()
+	if r.released {
+		r.life.RUnlock()
+		return false
+	}
+	return true
+}
+
+func (r *Resolver) releaseResources() {
 	// Wait for all partitions to be fetched / canceled.
 	if err := r.g.Wait(); err != nil {
 		r.span.SetTag("error", err)
@@ -171,6 +300,10 @@ func (r *Resolver) AddSamplesWithSpanSelectorFromParquetRow(partition uint64, st
 }
 
 func (r *Resolver) withPartitionSamples(partition uint64, fn func(*SampleAppender)) {
+	if !r.acquire() {
+		return
+	}
+	defer r.life.RUnlock()
 	p := r.partition(partition)
 	p.m.Lock()
 	defer p.m.Unlock()
@@ -178,6 +311,10 @@ func (r *Resolver) withPartitionSamples(partition uint64, fn func(*SampleAppende
 }
 
 func (r *Resolver) CallSiteValues(values *CallSiteValues, partition uint64, samples schemav1.Samples) error {
+	if !r.acquire() {
+		return ErrResolverReleased
+	}
+	defer r.life.RUnlock()
 	p := r.partition(partition)
 	if err := p.fetch(r.ctx); err != nil {
 		return err
@@ -189,6 +326,10 @@ func (r *Resolver) CallSiteValues(values *CallSiteValues, partition uint64, samp
 }
 
 func (r *Resolver) CallSiteValuesParquet(values *CallSiteValues, partition uint64, stacktraceID, value []parquet.Value) error {
+	if !r.acquire() {
+		return ErrResolverReleased
+	}
+	defer r.life.RUnlock()
 	p := r.partition(partition)
 	if err := p.fetch(r.ctx); err != nil {
 		return err
@@ -230,15 +371,42 @@ func (r *Resolver) partition(partition uint64) *lazyPartition {
 }
 
 func (r *Resolver) Tree() (*model.Tree, error) {
+	if !r.acquire() {
+		return nil, ErrResolverReleased
+	}
+	defer r.life.RUnlock()
 	span, ctx := opentracing.StartSpanFromContext(r.ctx, "Resolver.Tree")
 	defer span.Finish()
 	var lock sync.Mutex
 	tree := new(model.Tree)
 	err := r.withSymbols(ctx, func(symbols *Symbols, appender *SampleAppender) error {
-		resolved, err := symbols.Tree(ctx, appender, r.maxNodes)
+		if r.maxDepth > 0 {
+			symbols = depthLimitedSymbols(symbols, r.maxDepth, &r.truncatedStacks)
+		}
+		var resolved *model.Tree
+		var err error
+		switch {
+		case r.shallow && !symbols.unsymbolized():
+			resolved, err = buildTreeShallow(ctx, symbols, appender.Samples(), r.entryPoint, r.maxNodes)
+		case r.shallow:
+			// Frames of unsymbolized partitions are named after
+			// the addresses, and never match the entry point.
+			if resolved, err = symbols.Tree(ctx, appender, 0); err == nil {
+				resolved.LimitDepth(2)
+			}
+		case r.approximate(symbols):
+			resolved, err = buildTreeApproximate(ctx, symbols, appender.Samples(), r.maxNodes, r.epsilon, r.seed)
+		default:
+			resolved, err = symbols.Tree(ctx, appender, r.maxNodes)
+		}
 		if err != nil {
 			return err
 		}
+		r.stacks.Add(int64(appender.Len()))
+		if symbols.unsymbolized() {
+			r.unsymbolized.Store(true)
+			r.unsymbolizedStacks.Add(int64(appender.Len()))
+		}
 		lock.Lock()
 		tree.Merge(resolved)
 		lock.Unlock()
@@ -247,7 +415,35 @@ func (r *Resolver) Tree() (*model.Tree, error) {
 	return tree, err
 }
 
+func (r *Resolver) approximate(symbols *Symbols) bool {
+	return r.epsilon > 0 && r.maxNodes > 0 && !symbols.unsymbolized()
+}
+
+// Unsymbolized reports whether any of the partitions the tree was built
+// from lacks symbols: such nodes are named after the location addresses.
+// The method must be called after Tree.
+func (r *Resolver) Unsymbolized() bool { return r.unsymbolized.Load() }
+
+// UnsymbolizedFraction returns the fraction of the stack traces
+// the tree was built from that belong to unsymbolized partitions.
+// The method must be called after Tree.
+func (r *Resolver) UnsymbolizedFraction() float64 {
+	n := r.stacks.Load()
+	if n == 0 {
+		return 0
+	}
+	return float64(r.unsymbolizedStacks.Load()) / float64(n)
+}
+
+// TruncatedStacks returns the number of the stack traces truncated
+// because of the maximum depth. The method must be called after Tree.
+func (r *Resolver) TruncatedStacks() int64 { return r.truncatedStacks.Load() }
+
 func (r *Resolver) Pprof() (*googlev1.Profile, error) {
+	if !r.acquire() {
+		return nil, ErrResolverReleased
+	}
+	defer r.life.RUnlock()
 	span, ctx := opentracing.StartSpanFromContext(r.ctx, "Resolver.Pprof")
 	defer span.Finish()
 	var lock sync.Mutex
@@ -268,6 +464,14 @@ func (r *Resolver) Pprof() (*googlev1.Profile, error) {
 }
 
 func (r *Resolver) withSymbols(ctx context.Context, fn func(*Symbols, *SampleAppender) error) error {
+	return r.withPartitions(ctx, func(p *lazyPartition) error {
+		return fn(p.reader.Symbols(), p.samples)
+	})
+}
+
+// withPartitions calls the function for each of the partitions,
+// once the partition is fetched.
+func (r *Resolver) withPartitions(ctx context.Context, fn func(*lazyPartition) error) error {
 	g, _ := errgroup.WithContext(ctx)
 	g.SetLimit(r.c)
 	for _, p := range r.p {
@@ -276,7 +480,7 @@ func (r *Resolver) withSymbols(ctx context.Context, fn func(*Symbols, *SampleApp
 			if err := p.fetch(ctx); err != nil {
 				return err
 			}
-			return fn(p.reader.Symbols(), p.samples)
+			return fn(p)
 		}))
 	}
 	return g.Wait()
@@ -298,3 +502,24 @@ func (r *Symbols) Tree(
 ) (*model.Tree, error) {
 	return buildTree(ctx, r, appender, maxNodes)
 }
+
+// unsymbolized reports whether none of the locations has line
+// information, which is the case for profiles that have not been
+// symbolized yet. An empty location table is considered unsymbolized.
+// The result is computed once and cached: the symbols must not be
+// modified afterwards.
+func (r *Symbols) unsymbolized() bool {
+	if r.symbolization == symbolizationUnknown {
+		r.symbolization = locationsSymbolization(r.Locations)
+	}
+	return r.symbolization == symbolizationMissing
+}
+
+func locationsSymbolization(locations []schemav1.InMemoryLocation) symbolization {
+	for i := range locations {
+		if len(locations[i].Line) > 0 {
+			return symbolizationPresent
+		}
+	}
+	return symbolizationMissing
+}
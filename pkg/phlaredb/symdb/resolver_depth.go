@@ -0,0 +1,77 @@
+package symdb
+
+import (
+	"context"
+
+	"go.uber.org/atomic"
+
+	"github.com/grafana/pyroscope/pkg/iter"
+)
+
+// depthLimitedSymbols returns a copy of the symbols, which stack trace
+// resolver truncates stack traces deeper than maxDepth locations: only
+// the maxDepth locations closest to the root are retained. The number
+// of truncated stack traces is added to the counter.
+func depthLimitedSymbols(symbols *Symbols, maxDepth int, truncated *atomic.Int64) *Symbols {
+	c := *symbols
+	s := &depthLimitedStacktraces{
+		StacktraceResolver: symbols.Stacktraces,
+		maxDepth:           maxDepth,
+		truncated:          truncated,
+	}
+	c.Stacktraces = s
+	if ranges, ok := symbols.Stacktraces.(StacktraceIDRangeIterator); ok {
+		c.Stacktraces = &depthLimitedRangeStacktraces{depthLimitedStacktraces: s, ranges: ranges}
+	}
+	return &c
+}
+
+type depthLimitedStacktraces struct {
+	StacktraceResolver
+	maxDepth  int
+	truncated *atomic.Int64
+}
+
+func (s *depthLimitedStacktraces) ResolveStacktraceLocations(ctx context.Context, dst StacktraceInserter, stacktraces []uint32) error {
+	return s.StacktraceResolver.ResolveStacktraceLocations(ctx, &depthLimitedInserter{dst: dst, s: s}, stacktraces)
+}
+
+type depthLimitedInserter struct {
+	dst StacktraceInserter
+	s   *depthLimitedStacktraces
+}
+
+func (i *depthLimitedInserter) InsertStacktrace(stacktraceID uint32, locations []int32) {
+	// Locations are ordered from the leaf to the root.
+	if n := len(locations); n > i.s.maxDepth {
+		locations = locations[n-i.s.maxDepth:]
+		i.s.truncated.Inc()
+	}
+	i.dst.InsertStacktrace(stacktraceID, locations)
+}
+
+// depthLimitedRangeStacktraces additionally limits the depth of the
+// stack traces resolved from the parent pointer trees.
+type depthLimitedRangeStacktraces struct {
+	*depthLimitedStacktraces
+	ranges StacktraceIDRangeIterator
+}
+
+func (s *depthLimitedRangeStacktraces) SplitStacktraceIDRanges(appender *SampleAppender) iter.Iterator[*StacktraceIDRange] {
+	return &depthLimitedRanges{
+		Iterator: s.ranges.SplitStacktraceIDRanges(appender),
+		s:        s.depthLimitedStacktraces,
+	}
+}
+
+type depthLimitedRanges struct {
+	iter.Iterator[*StacktraceIDRange]
+	s *depthLimitedStacktraces
+}
+
+func (r *depthLimitedRanges) At() *StacktraceIDRange {
+	sr := r.Iterator.At()
+	sr.maxDepth = r.s.maxDepth
+	sr.truncated = r.s.truncated
+	return sr
+}
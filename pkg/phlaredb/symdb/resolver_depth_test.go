@@ -0,0 +1,83 @@
+package symdb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	profilev1 "github.com/grafana/pyroscope/api/gen/proto/go/google/v1"
+)
+
+func Test_Resolver_ResolveTree_MaxDepth(t *testing.T) {
+	p := &profilev1.Profile{
+		Sample: []*profilev1.Sample{
+			{LocationId: []uint64{4, 3, 2, 1}, Value: []int64{1}},
+			{LocationId: []uint64{5, 3, 2, 1}, Value: []int64{1}},
+			{LocationId: []uint64{6, 5, 3, 2, 1}, Value: []int64{1}},
+			{LocationId: []uint64{8, 7, 3, 2, 1}, Value: []int64{1}},
+			{LocationId: []uint64{9, 8, 7, 3, 2, 1}, Value: []int64{1}},
+		},
+		StringTable: []string{
+			"", "a", "b", "c", "f", "f1", "f2", "f3", "f4", "f5",
+		},
+	}
+	names := uint64(len(p.StringTable))
+	for i := uint64(1); i < names; i++ {
+		p.Location = append(p.Location, &profilev1.Location{
+			Id: i, Line: []*profilev1.Line{{FunctionId: i}},
+		})
+		p.Function = append(p.Function, &profilev1.Function{
+			Id: i, Name: int64(i),
+		})
+	}
+
+	const expected = `.
+└── a: self 0 total 5
+    └── b: self 0 total 5
+        └── c: self 0 total 5
+            ├── f: self 1 total 1
+            ├── f1: self 2 total 2
+            └── f3: self 2 total 2
+`
+
+	s := newMemSuite(t, nil)
+	const partition = 0
+	samples := s.db.WriteProfileSymbols(partition, p)[partition].Samples
+
+	t.Run("stack traces", func(t *testing.T) {
+		r := NewResolver(context.Background(), s.db, WithResolverMaxDepth(4))
+		defer r.Release()
+		r.AddSamples(partition, samples)
+		resolved, err := r.Tree()
+		require.NoError(t, err)
+		require.Equal(t, expected, resolved.String())
+		require.Equal(t, int64(3), r.TruncatedStacks())
+	})
+
+	t.Run("parent pointer trees", func(t *testing.T) {
+		var truncated atomic.Int64
+		symbols := depthLimitedSymbols(s.db.partitions[partition].Symbols(), 4, &truncated)
+		iterator, ok := symbols.Stacktraces.(StacktraceIDRangeIterator)
+		require.True(t, ok)
+		appender := NewSampleAppender()
+		appender.AppendMany(samples.StacktraceIDs, samples.Values)
+		resolved, err := buildTreeFromParentPointerTrees(context.Background(),
+			iterator.SplitStacktraceIDRanges(appender), symbols, 0)
+		require.NoError(t, err)
+		require.Equal(t, expected, resolved.String())
+		require.Equal(t, int64(3), truncated.Load())
+	})
+
+	t.Run("no limit", func(t *testing.T) {
+		r := NewResolver(context.Background(), s.db)
+		defer r.Release()
+		r.AddSamples(partition, samples)
+		resolved, err := r.Tree()
+		require.NoError(t, err)
+		require.NotEqual(t, expected, resolved.String())
+		require.Equal(t, int64(5), resolved.Total())
+		require.Zero(t, r.TruncatedStacks())
+	})
+}
@@ -0,0 +1,70 @@
+package symdb
+
+import (
+	"sync"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+// Stacks resolves the stack traces of the samples added into the frame
+// names, named as in the tree: the stack is given root first. The
+// function is called once per stack trace of a partition; the calls
+// for different partitions may be concurrent. The stack slice must not
+// be retained by the function.
+func (r *Resolver) Stacks(fn func(partition uint64, stacktraceID uint32, stack []string)) error {
+	if !r.acquire() {
+		return ErrResolverReleased
+	}
+	defer r.life.RUnlock()
+	span, ctx := opentracing.StartSpanFromContext(r.ctx, "Resolver.Stacks")
+	defer span.Finish()
+	var m sync.Mutex
+	return r.withPartitions(ctx, func(p *lazyPartition) error {
+		symbols := p.reader.Symbols()
+		if r.maxDepth > 0 {
+			symbols = depthLimitedSymbols(symbols, r.maxDepth, &r.truncatedStacks)
+		}
+		samples := p.samples.Samples()
+		r.stacks.Add(int64(samples.Len()))
+		s := stackSymbols{symbols: symbols, fn: func(id uint32, stack []string) {
+			m.Lock()
+			defer m.Unlock()
+			fn(p.id, id, stack)
+		}}
+		if symbols.unsymbolized() {
+			r.unsymbolized.Store(true)
+			r.unsymbolizedStacks.Add(int64(samples.Len()))
+			s.names = make(map[int32]string)
+		}
+		return symbols.Stacktraces.ResolveStacktraceLocations(ctx, &s, samples.StacktraceIDs)
+	})
+}
+
+type stackSymbols struct {
+	symbols *Symbols
+	fn      func(stacktraceID uint32, stack []string)
+	stack   []string
+	// Set if the partition is unsymbolized: the frames
+	// are named after the location addresses.
+	names map[int32]string
+}
+
+func (r *stackSymbols) InsertStacktrace(stacktraceID uint32, locations []int32) {
+	r.stack = r.stack[:0]
+	// Locations are ordered from the leaf to the root,
+	// while the stack is expected to start at the root.
+	for i := len(locations) - 1; i >= 0; i-- {
+		if r.names != nil {
+			r.stack = append(r.stack, addressName(r.symbols, r.names, locations[i]))
+			continue
+		}
+		lines := r.symbols.Locations[locations[i]].Line
+		for j := len(lines) - 1; j >= 0; j-- {
+			f := r.symbols.Functions[lines[j].FunctionId]
+			r.stack = append(r.stack, r.symbols.Strings[f.Name])
+		}
+	}
+	if len(r.stack) > 0 {
+		r.fn(stacktraceID, r.stack)
+	}
+}
@@ -0,0 +1,55 @@
+package symdb
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	profilev1 "github.com/grafana/pyroscope/api/gen/proto/go/google/v1"
+)
+
+func Test_Resolver_Stacks(t *testing.T) {
+	p := &profilev1.Profile{
+		Sample: []*profilev1.Sample{
+			{LocationId: []uint64{2, 1}, Value: []int64{1}},
+			// The locations differ, but the functions are the same.
+			{LocationId: []uint64{3, 1}, Value: []int64{2}},
+			{LocationId: []uint64{4, 1}, Value: []int64{4}},
+		},
+		StringTable: []string{"", "a", "b", "c"},
+		Function: []*profilev1.Function{
+			{Id: 1, Name: 1},
+			{Id: 2, Name: 2},
+			{Id: 3, Name: 3},
+		},
+		Location: []*profilev1.Location{
+			{Id: 1, Address: 0x1, Line: []*profilev1.Line{{FunctionId: 1}}},
+			{Id: 2, Address: 0x2, Line: []*profilev1.Line{{FunctionId: 2}}},
+			{Id: 3, Address: 0x3, Line: []*profilev1.Line{{FunctionId: 2}}},
+			// The first line is the inlined callee.
+			{Id: 4, Address: 0x4, Line: []*profilev1.Line{{FunctionId: 3}, {FunctionId: 2}}},
+		},
+	}
+
+	s := newMemSuite(t, nil)
+	const partition = 0
+	samples := s.db.WriteProfileSymbols(partition, p)[partition].Samples
+
+	r := NewResolver(context.Background(), s.db)
+	defer r.Release()
+	r.AddSamples(partition, samples)
+	stacks := make(map[uint32]string)
+	require.NoError(t, r.Stacks(func(pt uint64, id uint32, stack []string) {
+		require.Equal(t, uint64(partition), pt)
+		stacks[id] = strings.Join(stack, ";")
+	}))
+	require.Len(t, stacks, 3)
+	counts := make(map[string]int)
+	for _, stack := range stacks {
+		counts[stack]++
+	}
+	require.Equal(t, map[string]int{"a;b": 2, "a;b;c": 1}, counts)
+	require.Zero(t, r.UnsymbolizedFraction())
+}
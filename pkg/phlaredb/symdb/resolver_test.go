@@ -34,6 +34,24 @@ func Test_Resolver_Unreleased_Failed_Partition(t *testing.T) {
 	r.Release()
 }
 
+func Test_Resolver_ReleaseAsync(t *testing.T) {
+	s := newBlockSuite(t, [][]string{{"testdata/profile.pb.gz"}})
+	defer s.teardown()
+
+	r := NewResolver(context.Background(), s.reader)
+	r.AddSamples(0, s.indexed[0][0].Samples)
+	<-r.ReleaseAsync()
+	// The calls made after the release have no effect.
+	r.AddSamples(0, s.indexed[0][0].Samples)
+	_, err := r.Tree()
+	require.ErrorIs(t, err, ErrResolverReleased)
+	_, err = r.Pprof()
+	require.ErrorIs(t, err, ErrResolverReleased)
+	// The resolver can be released multiple times.
+	r.Release()
+	<-r.ReleaseAsync()
+}
+
 func Test_Resolver_Error_Propagation(t *testing.T) {
 	m := new(mockSymbolsReader)
 	m.On("Partition", mock.Anything, mock.Anything).Return(nil, io.EOF).Once()
@@ -2,6 +2,7 @@ package symdb
 
 import (
 	"context"
+	"strconv"
 	"sync"
 
 	"golang.org/x/sync/errgroup"
@@ -19,6 +20,20 @@ func buildTree(
 	appender *SampleAppender,
 	maxNodes int64,
 ) (*model.Tree, error) {
+	if symbols.unsymbolized() {
+		// The partition has not been symbolized yet: none of the
+		// locations has line information, and the only thing we
+		// can use to identify the frames is the address.
+		return buildTreeUnsymbolized(ctx, symbols, appender.Samples())
+	}
+	if appender.Len() == 1 && maxNodes <= 0 {
+		// Degenerate case: all the samples belong to a single stack
+		// trace, which is typical for synthetic profiles and micro
+		// benchmarks. The resulting tree is a chain of nodes, and
+		// there is no need in the intermediate stack trace tree.
+		// The chain is only truncated by the general path.
+		return buildTreeSingleStack(ctx, symbols, appender.Samples())
+	}
 	// If the number of samples is large (> 128K) and the StacktraceResolver
 	// implements the range iterator, we will be building the tree based on
 	// the parent pointer tree of the partition (a copy of). The only exception
@@ -33,7 +48,15 @@ func buildTree(
 	// Otherwise, use the basic approach: resolve each stack trace
 	// and insert them into the new tree one by one. The method
 	// performs best on small sample sets.
-	samples := appender.Samples()
+	return buildTreeFromStacktraces(ctx, symbols, appender.Samples(), maxNodes)
+}
+
+func buildTreeFromStacktraces(
+	ctx context.Context,
+	symbols *Symbols,
+	samples schemav1.Samples,
+	maxNodes int64,
+) (*model.Tree, error) {
 	t := treeSymbolsFromPool()
 	defer t.reset()
 	t.init(symbols, samples)
@@ -49,6 +72,90 @@ func shouldCopyTree(appender *SampleAppender, maxNodes int64) bool {
 	return appender.Len() > copyThreshold && !expensiveTruncation
 }
 
+func buildTreeSingleStack(ctx context.Context, symbols *Symbols, samples schemav1.Samples) (*model.Tree, error) {
+	t := singleStackSymbols{symbols: symbols, value: int64(samples.Values[0])}
+	if err := symbols.Stacktraces.ResolveStacktraceLocations(ctx, &t, samples.StacktraceIDs); err != nil {
+		return nil, err
+	}
+	return &t.tree, nil
+}
+
+type singleStackSymbols struct {
+	symbols *Symbols
+	value   int64
+	tree    model.Tree
+}
+
+func (r *singleStackSymbols) InsertStacktrace(_ uint32, locations []int32) {
+	// Locations are ordered from the leaf to the root,
+	// while the tree expects the stack to start at the root.
+	stack := make([]string, 0, len(locations))
+	for i := len(locations) - 1; i >= 0; i-- {
+		lines := r.symbols.Locations[locations[i]].Line
+		for j := len(lines) - 1; j >= 0; j-- {
+			f := r.symbols.Functions[lines[j].FunctionId]
+			stack = append(stack, r.symbols.Strings[f.Name])
+		}
+	}
+	if len(stack) > 0 {
+		r.tree.InsertStack(r.value, stack...)
+	}
+}
+
+func buildTreeUnsymbolized(ctx context.Context, symbols *Symbols, samples schemav1.Samples) (*model.Tree, error) {
+	t := addressSymbols{
+		symbols: symbols,
+		samples: samples,
+		names:   make(map[int32]string),
+	}
+	if err := symbols.Stacktraces.ResolveStacktraceLocations(ctx, &t, samples.StacktraceIDs); err != nil {
+		return nil, err
+	}
+	return &t.tree, nil
+}
+
+// unknownLocationName is used for locations that are
+// referenced by stack traces, but missing in the table.
+const unknownLocationName = "unknown"
+
+type addressSymbols struct {
+	symbols *Symbols
+	samples schemav1.Samples
+	names   map[int32]string
+	stack   []string
+	tree    model.Tree
+	cur     int
+}
+
+func (r *addressSymbols) InsertStacktrace(_ uint32, locations []int32) {
+	r.stack = r.stack[:0]
+	for i := len(locations) - 1; i >= 0; i-- {
+		r.stack = append(r.stack, r.name(locations[i]))
+	}
+	if len(r.stack) > 0 {
+		r.tree.InsertStack(int64(r.samples.Values[r.cur]), r.stack...)
+	}
+	r.cur++
+}
+
+func (r *addressSymbols) name(location int32) string {
+	return addressName(r.symbols, r.names, location)
+}
+
+// addressName returns the name of the frame of an unsymbolized
+// location, caching it in the map given.
+func addressName(symbols *Symbols, names map[int32]string, location int32) string {
+	name, ok := names[location]
+	if !ok {
+		name = unknownLocationName
+		if int(location) < len(symbols.Locations) {
+			name = "0x" + strconv.FormatUint(symbols.Locations[location].Address, 16)
+		}
+		names[location] = name
+	}
+	return name
+}
+
 type treeSymbols struct {
 	symbols *Symbols
 	samples *schemav1.Samples
@@ -0,0 +1,266 @@
+package symdb
+
+import (
+	"container/heap"
+	"context"
+	"math"
+
+	"github.com/grafana/pyroscope/pkg/model"
+	schemav1 "github.com/grafana/pyroscope/pkg/phlaredb/schemas/v1"
+)
+
+// buildTreeApproximate builds the tree retaining only the nodes that are
+// likely to be among the top maxNodes nodes by total value.
+//
+// The stack traces are resolved twice. In the first pass, the total value
+// of every call path (a node of the resulting tree) is estimated with a
+// count-min sketch, and the call paths with the highest estimates are
+// tracked in a bounded heap. In the second pass, stack traces are inserted
+// into the tree up to the first call path whose estimate is lower than the
+// smallest tracked one; the rest of the stack is accounted as "other".
+//
+// The estimated values never underestimate the true ones, and exceed them
+// by at most epsilon * total with high probability; therefore the nodes
+// with the true values above the threshold are always retained, and some
+// of the nodes close to the threshold may be replaced with others. The tree
+// values are exact: the sketch is only used to decide which nodes to keep.
+func buildTreeApproximate(
+	ctx context.Context,
+	symbols *Symbols,
+	samples schemav1.Samples,
+	maxNodes int64,
+	epsilon float64,
+	seed uint64,
+) (*model.Tree, error) {
+	c := &callPathCounter{
+		symbols: symbols,
+		samples: samples,
+		seed:    seed,
+		sketch:  newCountMinSketch(epsilon, approximationErrorProbability),
+		top:     newTopCallPaths(int(maxNodes)),
+	}
+	if err := symbols.Stacktraces.ResolveStacktraceLocations(ctx, c, samples.StacktraceIDs); err != nil {
+		return nil, err
+	}
+	t := &approximateTreeSymbols{
+		symbols:   symbols,
+		samples:   samples,
+		seed:      seed,
+		sketch:    c.sketch,
+		threshold: c.top.threshold(),
+		tree:      model.NewStacktraceTree(int(maxNodes) * 2),
+	}
+	if err := symbols.Stacktraces.ResolveStacktraceLocations(ctx, t, samples.StacktraceIDs); err != nil {
+		return nil, err
+	}
+	// The tree is not truncated once again: the "other" nodes
+	// would compete with the retained ones for the place.
+	return t.tree.Tree(0, symbols.Strings), nil
+}
+
+// The probability that the estimated value of a call
+// path exceeds the error bound (delta).
+const approximationErrorProbability = 0.01
+
+// callPathNames appends the function names of
+// the stack trace, starting from the root.
+func callPathNames(dst []int32, symbols *Symbols, locations []int32) []int32 {
+	for i := len(locations) - 1; i >= 0; i-- {
+		lines := symbols.Locations[locations[i]].Line
+		for j := len(lines) - 1; j >= 0; j-- {
+			dst = append(dst, int32(symbols.Functions[lines[j].FunctionId].Name))
+		}
+	}
+	return dst
+}
+
+// callPathHash returns the hash of the call path
+// extended with the function name given. The hash
+// of the empty call path is the seed.
+func callPathHash(h uint64, name int32) uint64 {
+	h ^= uint64(uint32(name))
+	h *= 0x9e3779b97f4a7c15
+	return h ^ (h >> 29)
+}
+
+type callPathCounter struct {
+	symbols *Symbols
+	samples schemav1.Samples
+	seed    uint64
+	sketch  *countMinSketch
+	top     *topCallPaths
+	names   []int32
+	cur     int
+}
+
+func (c *callPathCounter) InsertStacktrace(_ uint32, locations []int32) {
+	v := int64(c.samples.Values[c.cur])
+	c.cur++
+	c.names = callPathNames(c.names[:0], c.symbols, locations)
+	h := c.seed
+	for _, name := range c.names {
+		h = callPathHash(h, name)
+		c.top.observe(h, c.sketch.add(h, v))
+	}
+}
+
+type approximateTreeSymbols struct {
+	symbols   *Symbols
+	samples   schemav1.Samples
+	seed      uint64
+	sketch    *countMinSketch
+	threshold int64
+	tree      *model.StacktraceTree
+	names     []int32
+	stack     []int32
+	cur       int
+}
+
+func (r *approximateTreeSymbols) InsertStacktrace(_ uint32, locations []int32) {
+	v := int64(r.samples.Values[r.cur])
+	r.cur++
+	r.names = callPathNames(r.names[:0], r.symbols, locations)
+	h := r.seed
+	n := 0
+	for ; n < len(r.names); n++ {
+		h = callPathHash(h, r.names[n])
+		if r.sketch.estimate(h) < r.threshold {
+			break
+		}
+	}
+	// The tree expects the stack to start at the leaf.
+	r.stack = r.stack[:0]
+	if n < len(r.names) {
+		r.stack = append(r.stack, sentinel)
+	}
+	for i := n - 1; i >= 0; i-- {
+		r.stack = append(r.stack, r.names[i])
+	}
+	if len(r.stack) > 0 {
+		r.tree.Insert(r.stack, v)
+	}
+}
+
+// The maximum width of the count-min sketch row;
+// limits the memory footprint at small error bounds.
+const maxCountMinSketchWidth = 1 << 20
+
+// countMinSketch estimates the sum of values added for a key. The
+// estimate is never lower than the true value, and with probability
+// 1-delta exceeds it by no more than epsilon times the sum of all
+// the values added.
+type countMinSketch struct {
+	width uint64
+	rows  [][]int64
+}
+
+func newCountMinSketch(epsilon, delta float64) *countMinSketch {
+	width := uint64(math.Ceil(math.E / epsilon))
+	if width > maxCountMinSketchWidth {
+		width = maxCountMinSketchWidth
+	}
+	depth := int(math.Ceil(math.Log(1 / delta)))
+	s := &countMinSketch{
+		width: width,
+		rows:  make([][]int64, depth),
+	}
+	for i := range s.rows {
+		s.rows[i] = make([]int64, width)
+	}
+	return s
+}
+
+func (s *countMinSketch) index(h uint64, row int) uint64 {
+	// Double hashing: the row hash functions are
+	// derived from the two halves of the key hash.
+	return (h&math.MaxUint32 + uint64(row)*(h>>32|1)) % s.width
+}
+
+// add adds the value to the key and returns the updated estimate.
+func (s *countMinSketch) add(h uint64, v int64) int64 {
+	e := int64(math.MaxInt64)
+	for i, row := range s.rows {
+		j := s.index(h, i)
+		row[j] += v
+		e = min(e, row[j])
+	}
+	return e
+}
+
+func (s *countMinSketch) estimate(h uint64) int64 {
+	e := int64(math.MaxInt64)
+	for i, row := range s.rows {
+		e = min(e, row[s.index(h, i)])
+	}
+	return e
+}
+
+// topCallPaths tracks k call paths with the highest estimates.
+type topCallPaths struct {
+	k       int
+	entries []callPathEstimate
+	index   map[uint64]int
+}
+
+type callPathEstimate struct {
+	hash     uint64
+	estimate int64
+}
+
+func newTopCallPaths(k int) *topCallPaths {
+	return &topCallPaths{
+		k:       k,
+		entries: make([]callPathEstimate, 0, k),
+		index:   make(map[uint64]int, k),
+	}
+}
+
+func (t *topCallPaths) observe(h uint64, estimate int64) {
+	if i, ok := t.index[h]; ok {
+		t.entries[i].estimate = estimate
+		heap.Fix(t, i)
+		return
+	}
+	if len(t.entries) < t.k {
+		heap.Push(t, callPathEstimate{hash: h, estimate: estimate})
+		return
+	}
+	if estimate > t.entries[0].estimate {
+		delete(t.index, t.entries[0].hash)
+		t.entries[0] = callPathEstimate{hash: h, estimate: estimate}
+		t.index[h] = 0
+		heap.Fix(t, 0)
+	}
+}
+
+// threshold returns the lowest estimate of the top call paths,
+// or zero, if the number of call paths does not exceed k.
+func (t *topCallPaths) threshold() int64 {
+	if len(t.entries) < t.k {
+		return 0
+	}
+	return t.entries[0].estimate
+}
+
+func (t *topCallPaths) Len() int           { return len(t.entries) }
+func (t *topCallPaths) Less(i, j int) bool { return t.entries[i].estimate < t.entries[j].estimate }
+
+func (t *topCallPaths) Swap(i, j int) {
+	t.entries[i], t.entries[j] = t.entries[j], t.entries[i]
+	t.index[t.entries[i].hash] = i
+	t.index[t.entries[j].hash] = j
+}
+
+func (t *topCallPaths) Push(x any) {
+	e := x.(callPathEstimate)
+	t.index[e.hash] = len(t.entries)
+	t.entries = append(t.entries, e)
+}
+
+func (t *topCallPaths) Pop() any {
+	last := len(t.entries) - 1
+	e := t.entries[last]
+	t.entries = t.entries[:last]
+	delete(t.index, e.hash)
+	return e
+}
@@ -0,0 +1,133 @@
+package symdb
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+func Test_Resolver_ResolveTree_Approximate(t *testing.T) {
+	s := newMemSuite(t, [][]string{{"testdata/profile.pb.gz"}})
+	samples := s.indexed[0][0].Samples
+
+	resolve := func(options ...ResolverOption) *model.Tree {
+		r := NewResolver(context.Background(), s.db, options...)
+		defer r.Release()
+		r.AddSamples(0, samples)
+		resolved, err := r.Tree()
+		require.NoError(t, err)
+		return resolved
+	}
+
+	const maxNodes = 128
+	exact := treeNodeTotals(resolve())
+	paths := make([]string, 0, len(exact))
+	for path := range exact {
+		paths = append(paths, path)
+	}
+	slices.SortFunc(paths, func(a, b string) int {
+		return int(exact[b] - exact[a])
+	})
+	top := paths[:maxNodes]
+
+	for _, tc := range []struct {
+		epsilon   float64
+		minRecall float64
+	}{
+		{epsilon: 0.01, minRecall: 0.85},
+		{epsilon: 0.001, minRecall: 0.95},
+	} {
+		approximate := resolve(WithResolverMaxNodes(maxNodes), WithResolverApproximation(tc.epsilon))
+		require.Equal(t, exact[""], approximate.Total())
+		actual := treeNodeTotals(approximate)
+		var found int
+		for _, path := range top {
+			if v, ok := actual[path]; ok {
+				found++
+				// Retained node values are exact.
+				assert.Equal(t, exact[path], v, path)
+			}
+		}
+		recall := float64(found) / maxNodes
+		t.Logf("epsilon %v: recall of the top %d nodes: %.4f", tc.epsilon, maxNodes, recall)
+		require.GreaterOrEqual(t, recall, tc.minRecall)
+	}
+}
+
+func Test_Resolver_ResolveTree_Approximate_Seed(t *testing.T) {
+	s := newMemSuite(t, [][]string{{"testdata/profile.pb.gz"}})
+	samples := s.indexed[0][0].Samples
+
+	resolve := func(seed uint64) string {
+		r := NewResolver(context.Background(), s.db,
+			WithResolverMaxNodes(64),
+			// A coarse sketch makes the result sensitive to collisions.
+			WithResolverApproximation(0.05),
+			WithResolverSeed(seed))
+		defer r.Release()
+		r.AddSamples(0, samples)
+		resolved, err := r.Tree()
+		require.NoError(t, err)
+		return resolved.String()
+	}
+
+	for _, seed := range []uint64{0, 42} {
+		expected := resolve(seed)
+		for i := 0; i < 3; i++ {
+			require.Equal(t, expected, resolve(seed))
+		}
+	}
+}
+
+// treeNodeTotals returns total values of the tree nodes by the call
+// path; nodes truncated to "other" are omitted. The total value of
+// the tree is stored under the empty path.
+func treeNodeTotals(t *model.Tree) map[string]int64 {
+	totals := map[string]int64{"": t.Total()}
+	t.IterateStacks(func(_ string, self int64, stack []string) {
+		slices.Reverse(stack)
+		for i := range stack {
+			if stack[i] == "other" {
+				break
+			}
+			totals[strings.Join(stack[:i+1], ";")] += self
+		}
+	})
+	return totals
+}
+
+func Test_countMinSketch(t *testing.T) {
+	s := newCountMinSketch(0.01, 0.01)
+	var total int64
+	for i := uint64(1); i <= 1000; i++ {
+		h := callPathHash(0, int32(i))
+		s.add(h, int64(i))
+		total += int64(i)
+	}
+	for i := uint64(1); i <= 1000; i++ {
+		e := s.estimate(callPathHash(0, int32(i)))
+		require.GreaterOrEqual(t, e, int64(i))
+		require.LessOrEqual(t, e, int64(i)+total/100)
+	}
+}
+
+func Test_topCallPaths(t *testing.T) {
+	top := newTopCallPaths(2)
+	require.Zero(t, top.threshold())
+	top.observe(1, 10)
+	top.observe(2, 5)
+	require.Equal(t, int64(5), top.threshold())
+	top.observe(3, 1)
+	require.Equal(t, int64(5), top.threshold())
+	top.observe(2, 20)
+	require.Equal(t, int64(10), top.threshold())
+	top.observe(3, 15)
+	require.Equal(t, int64(15), top.threshold())
+	require.Len(t, top.index, 2)
+}
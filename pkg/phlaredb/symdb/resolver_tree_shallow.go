@@ -0,0 +1,82 @@
+package symdb
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/grafana/pyroscope/pkg/model"
+	schemav1 "github.com/grafana/pyroscope/pkg/phlaredb/schemas/v1"
+)
+
+// buildTreeShallow builds the tree of the top-level calls: stack traces
+// are truncated below the first call out of the entry point, and the
+// values of the truncated frames are accounted in the self value of the
+// last retained one. The entry point is the first frame that matches the
+// expression; if the expression is not set, or none of the frames match,
+// the entry point is the root.
+//
+// The tree is built directly from the truncated stack traces, therefore
+// the cost of the query does not depend on the stack trace depth.
+func buildTreeShallow(
+	ctx context.Context,
+	symbols *Symbols,
+	samples schemav1.Samples,
+	entryPoint *regexp.Regexp,
+	maxNodes int64,
+) (*model.Tree, error) {
+	t := &shallowTreeSymbols{
+		symbols:    symbols,
+		samples:    samples,
+		entryPoint: entryPoint,
+		matches:    make(map[int32]bool),
+		// The tree is expected to be much smaller than the sample set.
+		tree: model.NewStacktraceTree(min(samples.Len(), 1<<10)),
+	}
+	if err := symbols.Stacktraces.ResolveStacktraceLocations(ctx, t, samples.StacktraceIDs); err != nil {
+		return nil, err
+	}
+	return t.tree.Tree(maxNodes, symbols.Strings), nil
+}
+
+type shallowTreeSymbols struct {
+	symbols    *Symbols
+	samples    schemav1.Samples
+	entryPoint *regexp.Regexp
+	matches    map[int32]bool // Entry point matches by function name.
+	tree       *model.StacktraceTree
+	names      []int32
+	stack      []int32
+	cur        int
+}
+
+func (r *shallowTreeSymbols) InsertStacktrace(_ uint32, locations []int32) {
+	v := int64(r.samples.Values[r.cur])
+	r.cur++
+	r.names = callPathNames(r.names[:0], r.symbols, locations)
+	n := min(r.entryPointIndex()+2, len(r.names))
+	// The tree expects the stack to start at the leaf.
+	r.stack = r.stack[:0]
+	for i := n - 1; i >= 0; i-- {
+		r.stack = append(r.stack, r.names[i])
+	}
+	if len(r.stack) > 0 {
+		r.tree.Insert(r.stack, v)
+	}
+}
+
+func (r *shallowTreeSymbols) entryPointIndex() int {
+	if r.entryPoint == nil {
+		return 0
+	}
+	for i, name := range r.names {
+		m, ok := r.matches[name]
+		if !ok {
+			m = r.entryPoint.MatchString(r.symbols.Strings[name])
+			r.matches[name] = m
+		}
+		if m {
+			return i
+		}
+	}
+	return 0
+}
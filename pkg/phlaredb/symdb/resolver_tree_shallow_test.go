@@ -0,0 +1,66 @@
+package symdb
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/pyroscope/pkg/model"
+)
+
+func Test_Resolver_ResolveTree_Shallow(t *testing.T) {
+	s := newMemSuite(t, [][]string{{"testdata/profile.pb.gz"}})
+	samples := s.indexed[0][0].Samples
+
+	resolve := func(options ...ResolverOption) *model.Tree {
+		r := NewResolver(context.Background(), s.db, options...)
+		defer r.Release()
+		r.AddSamples(0, samples)
+		resolved, err := r.Tree()
+		require.NoError(t, err)
+		return resolved
+	}
+
+	full := resolve()
+	// shallow truncates the stack traces of the full tree.
+	shallow := func(entryPoint *regexp.Regexp) *model.Tree {
+		expected := new(model.Tree)
+		full.IterateStacks(func(_ string, self int64, stack []string) {
+			root := slices.Clone(stack)
+			slices.Reverse(root)
+			n := 0
+			if entryPoint != nil {
+				for i, name := range root {
+					if entryPoint.MatchString(name) {
+						n = i
+						break
+					}
+				}
+			}
+			expected.InsertStack(self, root[:min(n+2, len(root))]...)
+		})
+		return expected
+	}
+
+	t.Run("top-level calls", func(t *testing.T) {
+		actual := resolve(WithResolverShallow(nil))
+		require.Equal(t, full.Total(), actual.Total())
+		require.Equal(t, shallow(nil).String(), actual.String())
+		limited := resolve()
+		limited.LimitDepth(2)
+		require.Equal(t, limited.String(), actual.String())
+	})
+
+	t.Run("entry point", func(t *testing.T) {
+		nodes := full.Nodes()
+		require.NotEmpty(t, nodes)
+		require.NotEmpty(t, nodes[0].Children)
+		entryPoint := regexp.MustCompile("^" + regexp.QuoteMeta(nodes[0].Children[0].Name) + "$")
+		actual := resolve(WithResolverShallow(entryPoint))
+		require.Equal(t, full.Total(), actual.Total())
+		require.Equal(t, shallow(entryPoint).String(), actual.String())
+	})
+}
@@ -2,6 +2,7 @@ package symdb
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -164,3 +165,90 @@ func Test_buildTreeFromParentPointerTrees(t *testing.T) {
 
 	require.Equal(t, expectedTree, resolved.String())
 }
+
+func Test_buildTree_SingleStack(t *testing.T) {
+	s := newMemSuite(t, [][]string{{"testdata/profile.pb.gz"}})
+	samples := s.indexed[0][0].Samples
+	symbols := s.db.partitions[0].Symbols()
+	for i := 0; i < samples.Len() && i < 32; i++ {
+		sample := v1.Samples{
+			StacktraceIDs: []uint32{samples.StacktraceIDs[i]},
+			Values:        []uint64{samples.Values[i]},
+		}
+		for _, maxNodes := range []int64{0, 1, 4} {
+			expected, err := buildTreeFromStacktraces(context.Background(), symbols, sample.Clone(), maxNodes)
+			require.NoError(t, err)
+			appender := NewSampleAppender()
+			appender.AppendMany(sample.StacktraceIDs, sample.Values)
+			actual, err := buildTree(context.Background(), symbols, appender, maxNodes)
+			require.NoError(t, err)
+			require.Equal(t, expected.String(), actual.String())
+		}
+	}
+}
+
+func Benchmark_Resolver_ResolveTree_SingleStack(b *testing.B) {
+	s := newMemSuite(b, [][]string{{"testdata/profile.pb.gz"}})
+	// All the samples refer to the same stack trace,
+	// which is the most common one in the profile.
+	samples := s.indexed[0][0].Samples.Clone()
+	var top int
+	for i, v := range samples.Values {
+		if v > samples.Values[top] {
+			top = i
+		}
+	}
+	for i := range samples.StacktraceIDs {
+		samples.StacktraceIDs[i] = samples.StacktraceIDs[top]
+	}
+	b.Run("0", benchmarkResolverResolveTree(s.db, samples, 0))
+	b.Run("1K", benchmarkResolverResolveTree(s.db, samples, 1<<10))
+}
+
+func Test_buildTree_Unsymbolized(t *testing.T) {
+	s := newMemSuite(t, [][]string{{"testdata/profile.pb.gz"}})
+	samples := s.indexed[0][0].Samples
+	symbolized := s.db.partitions[0].Symbols()
+	require.False(t, symbolized.unsymbolized())
+
+	// Symbols section of a profile that has not been symbolized:
+	// locations only have addresses.
+	symbols := &Symbols{
+		Stacktraces: symbolized.Stacktraces,
+		Locations:   make([]v1.InMemoryLocation, len(symbolized.Locations)),
+	}
+	for i, loc := range symbolized.Locations {
+		symbols.Locations[i] = v1.InMemoryLocation{Id: loc.Id, Address: loc.Address}
+	}
+	require.True(t, symbols.unsymbolized())
+
+	for _, n := range []int{1, samples.Len()} {
+		appender := NewSampleAppender()
+		appender.AppendMany(samples.StacktraceIDs[:n], samples.Values[:n])
+		tree, err := buildTree(context.Background(), symbols, appender, 0)
+		require.NoError(t, err)
+		var total uint64
+		for _, v := range samples.Values[:n] {
+			total += v
+		}
+		require.Equal(t, int64(total), tree.Total())
+		tree.IterateStacks(func(_ string, _ int64, stack []string) {
+			for _, name := range stack {
+				require.True(t, strings.HasPrefix(name, "0x"), name)
+			}
+		})
+	}
+
+	t.Run("empty location table", func(t *testing.T) {
+		symbols := &Symbols{Stacktraces: symbolized.Stacktraces}
+		appender := NewSampleAppender()
+		appender.AppendMany(samples.StacktraceIDs, samples.Values)
+		tree, err := buildTree(context.Background(), symbols, appender, 0)
+		require.NoError(t, err)
+		tree.IterateStacks(func(_ string, _ int64, stack []string) {
+			for _, name := range stack {
+				require.Equal(t, unknownLocationName, name)
+			}
+		})
+	})
+}
@@ -1,6 +1,8 @@
 package symdb
 
 import (
+	"go.uber.org/atomic"
+
 	schemav1 "github.com/grafana/pyroscope/pkg/phlaredb/schemas/v1"
 )
 
@@ -20,11 +22,20 @@ type StacktraceIDRange struct {
 	schemav1.Samples
 	// TODO(kolesnikovae): use SampleAppender instead of Samples.
 	//  This will allow to avoid copying the samples.
+
+	// If positive, the values of the stack traces deeper than
+	// maxDepth are set to their ancestors at the depth.
+	maxDepth  int
+	truncated *atomic.Int64
 }
 
 // SetNodeValues sets the values of the provided Samples to the matching
 // parent pointer tree nodes.
 func (r *StacktraceIDRange) SetNodeValues(dst []Node) {
+	if r.maxDepth > 0 {
+		r.setNodeValuesMaxDepth(dst)
+		return
+	}
 	for i := 0; i < len(r.IDs); i++ {
 		x := r.StacktraceIDs[i]
 		v := int64(r.Values[i])
@@ -34,6 +45,33 @@ func (r *StacktraceIDRange) SetNodeValues(dst []Node) {
 	}
 }
 
+func (r *StacktraceIDRange) setNodeValuesMaxDepth(dst []Node) {
+	// Parents precede their children in the tree,
+	// and the root node (0) has no parent.
+	depth := make([]int32, len(dst))
+	for i := 1; i < len(dst); i++ {
+		depth[i] = depth[dst[i].Parent] + 1
+	}
+	var truncated int64
+	for i := 0; i < len(r.IDs); i++ {
+		x := int32(r.StacktraceIDs[i])
+		v := int64(r.Values[i])
+		if x <= 0 || v <= 0 {
+			continue
+		}
+		if depth[x] > int32(r.maxDepth) {
+			for depth[x] > int32(r.maxDepth) {
+				x = dst[x].Parent
+			}
+			truncated++
+		}
+		dst[x].Value += v
+	}
+	if r.truncated != nil {
+		r.truncated.Add(truncated)
+	}
+}
+
 // Offset returns the lowest identifier of the range.
 // Identifiers are relative to the range offset.
 func (r *StacktraceIDRange) Offset() uint32 { return r.m * r.chunk }
@@ -29,8 +29,19 @@ type Symbols struct {
 	Mappings    []schemav1.InMemoryMapping
 	Functions   []schemav1.InMemoryFunction
 	Strings     []string
+
+	// symbolization caches the result of unsymbolized.
+	symbolization symbolization
 }
 
+type symbolization int8
+
+const (
+	symbolizationUnknown symbolization = iota
+	symbolizationPresent
+	symbolizationMissing
+)
+
 type PartitionStats struct {
 	StacktracesTotal int
 	MaxStacktraceID  int
@@ -10,6 +10,7 @@ import (
 	"github.com/prometheus/common/model"
 	"gopkg.in/yaml.v3"
 
+	"github.com/grafana/pyroscope/pkg/distributor/recording"
 	writepath "github.com/grafana/pyroscope/pkg/distributor/write_path"
 	"github.com/grafana/pyroscope/pkg/phlaredb/block"
 )
@@ -45,6 +46,10 @@ type Limits struct {
 	// Distributor per-app usage breakdown.
 	DistributorUsageGroups *UsageGroupConfig `yaml:"distributor_usage_groups" json:"distributor_usage_groups"`
 
+	// Profile recording rules evaluated by the distributor.
+	RecordingRules    recording.Rules `yaml:"recording_rules" json:"recording_rules" doc:"description=Profile recording rules of the tenant. The values of the samples matching a rule are exported as the pyroscope_recording_rule_sample_values_total metric."`
+	MaxRecordingRules int             `yaml:"max_recording_rules" json:"max_recording_rules"`
+
 	// Distributor aggregation.
 	DistributorAggregationWindow model.Duration `yaml:"distributor_aggregation_window" json:"distributor_aggregation_window"`
 	DistributorAggregationPeriod model.Duration `yaml:"distributor_aggregation_period" json:"distributor_aggregation_period"`
@@ -65,6 +70,7 @@ type Limits struct {
 
 	// Querier enforced limits.
 	MaxQueryLookback           model.Duration `yaml:"max_query_lookback" json:"max_query_lookback"`
+	DefaultQueryLookback       model.Duration `yaml:"default_query_lookback" json:"default_query_lookback"`
 	MaxQueryLength             model.Duration `yaml:"max_query_length" json:"max_query_length"`
 	MaxQueryParallelism        int            `yaml:"max_query_parallelism" json:"max_query_parallelism"`
 	QueryAnalysisEnabled       bool           `yaml:"query_analysis_enabled" json:"query_analysis_enabled"`
@@ -123,6 +129,8 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&l.MaxSessionsPerSeries, "validation.max-sessions-per-series", 0, "Maximum number of sessions per series. 0 to disable.")
 	f.BoolVar(&l.EnforceLabelsOrder, "validation.enforce-labels-order", false, "Enforce labels order optimization.")
 
+	f.IntVar(&l.MaxRecordingRules, "distributor.max-recording-rules", 10, "Maximum number of profile recording rules per tenant. 0 to disable.")
+
 	f.IntVar(&l.MaxLocalSeriesPerTenant, "ingester.max-local-series-per-tenant", 0, "Maximum number of active series of profiles per tenant, per ingester. 0 to disable.")
 	f.IntVar(&l.MaxGlobalSeriesPerTenant, "ingester.max-global-series-per-tenant", 5000, "Maximum number of active series of profiles per tenant, across the cluster. 0 to disable. When the global limit is enabled, each ingester is configured with a dynamic local limit based on the replication factor and the current number of healthy ingesters, and is kept updated whenever the number of ingesters change.")
 
@@ -132,6 +140,9 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	_ = l.MaxQueryLookback.Set("7d")
 	f.Var(&l.MaxQueryLookback, "querier.max-query-lookback", "Limit how far back in profiling data can be queried, up until lookback duration ago. This limit is enforced in the query frontend. If the requested time range is outside the allowed range, the request will not fail, but will be modified to only query data within the allowed time range. 0 to disable, default to 7d.")
 
+	_ = l.DefaultQueryLookback.Set("1h")
+	f.Var(&l.DefaultQueryLookback, "querier.default-query-lookback", "Lookback period of queries that do not specify the time range: such queries cover the last lookback duration. 0 to disable.")
+
 	f.IntVar(&l.StoreGatewayTenantShardSize, "store-gateway.tenant-shard-size", 0, "The tenant's shard size, used when store-gateway sharding is enabled. Value of 0 disables shuffle sharding for the tenant, that is all tenant blocks are sharded across all store-gateway replicas.")
 
 	_ = l.QuerySplitDuration.Set("0s")
@@ -204,6 +215,10 @@ func (l *Limits) Validate() error {
 		}
 	}
 
+	if err := l.RecordingRules.Validate(l.MaxRecordingRules); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -315,6 +330,11 @@ func (o *Overrides) EnforceLabelsOrder(tenantID string) bool {
 	return o.getOverridesForTenant(tenantID).EnforceLabelsOrder
 }
 
+// RecordingRules returns the profile recording rules of the tenant.
+func (o *Overrides) RecordingRules(tenantID string) recording.Rules {
+	return o.getOverridesForTenant(tenantID).RecordingRules
+}
+
 func (o *Overrides) DistributorAggregationWindow(tenantID string) model.Duration {
 	return o.getOverridesForTenant(tenantID).DistributorAggregationWindow
 }
@@ -351,6 +371,12 @@ func (o *Overrides) MaxQueryLookback(tenantID string) time.Duration {
 	return time.Duration(o.getOverridesForTenant(tenantID).MaxQueryLookback)
 }
 
+// DefaultQueryLookback returns the lookback period
+// of queries that do not specify the time range.
+func (o *Overrides) DefaultQueryLookback(tenantID string) time.Duration {
+	return time.Duration(o.getOverridesForTenant(tenantID).DefaultQueryLookback)
+}
+
 // MaxFlameGraphNodesDefault returns the max flame graph nodes used by default.
 func (o *Overrides) MaxFlameGraphNodesDefault(tenantID string) int {
 	return o.getOverridesForTenant(tenantID).MaxFlameGraphNodesDefault
@@ -9,6 +9,7 @@ type MockLimits struct {
 	MaxQueryParallelismValue        int
 	MaxQueryLengthValue             time.Duration
 	MaxQueryLookbackValue           time.Duration
+	DefaultQueryLookbackValue       time.Duration
 	QueryAnalysisEnabledValue       bool
 	QueryAnalysisSeriesEnabledValue bool
 	MaxLabelNameLengthValue         int
@@ -37,6 +38,7 @@ func (m MockLimits) QuerySplitDuration(string) time.Duration        { return m.Q
 func (m MockLimits) MaxQueryParallelism(string) int                 { return m.MaxQueryParallelismValue }
 func (m MockLimits) MaxQueryLength(tenantID string) time.Duration   { return m.MaxQueryLengthValue }
 func (m MockLimits) MaxQueryLookback(tenantID string) time.Duration { return m.MaxQueryLookbackValue }
+func (m MockLimits) DefaultQueryLookback(string) time.Duration      { return m.DefaultQueryLookbackValue }
 func (m MockLimits) QueryAnalysisEnabled(tenantID string) bool      { return m.QueryAnalysisEnabledValue }
 func (m MockLimits) QueryAnalysisSeriesEnabled(tenantID string) bool {
 	return m.QueryAnalysisSeriesEnabledValue